@@ -81,6 +81,7 @@ pub struct Config {
     pub kubernetes_cluster_id: String,
     pub kubernetes_cluster_name: Option<String>,
     pub vtap_group_id_request: String,
+    pub enrollment_token: String,
     pub controller_domain_name: Vec<String>,
     #[serde(skip)]
     pub agent_mode: RunningMode,
@@ -237,6 +238,7 @@ impl Default for Config {
             kubernetes_cluster_id: "".into(),
             kubernetes_cluster_name: Default::default(),
             vtap_group_id_request: "".into(),
+            enrollment_token: "".into(),
             controller_domain_name: vec![],
             agent_mode: Default::default(),
             override_os_hostname: None,
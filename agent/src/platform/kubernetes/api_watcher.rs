@@ -25,7 +25,7 @@ use std::{
         Arc, Condvar, Mutex,
     },
     thread,
-    time::{Duration, SystemTime},
+    time::{Duration, Instant, SystemTime},
 };
 
 use arc_swap::access::Access;
@@ -65,9 +65,13 @@ use public::proto::{
  *     否则，发送一个内容为空的心跳数据。发送心跳数据后，得到
  *     triso回复消息的版本号与当前版本不一致，说明triso没收到
  *     最新数据，此时进行一次全量同步。
+ *     triso支持增量同步时，有更新只发送上次同步后变化的对象及
+ *     其所基于的版本号，triso版本号不一致时同样进行一次全量同步，
+ *     此外每隔FULL_SYNC_INTERVAL进行一次全量同步兜底。
  */
 
 const PB_VERSION_INFO: &str = "*version.Info";
+const FULL_SYNC_INTERVAL: Duration = Duration::from_secs(600);
 
 struct Context {
     config: PlatformAccess,
@@ -87,6 +91,12 @@ impl fmt::Display for WatcherKey {
     }
 }
 
+struct SyncState {
+    watcher_versions: HashMap<WatcherKey, u64>,
+    incremental_supported: bool,
+    last_full_sync: Instant,
+}
+
 pub struct ApiWatcher {
     context: Arc<Context>,
     thread: Mutex<Option<thread::JoinHandle<()>>>,
@@ -554,7 +564,7 @@ impl ApiWatcher {
         Ok((watchers, task_handles))
     }
 
-    fn debug_k8s_request(request: &KubernetesApiSyncRequest, full_sync: bool) {
+    fn debug_k8s_request(request: &KubernetesApiSyncRequest) {
         let mut map = HashMap::new();
         for entry in request.entries.iter() {
             *map.entry(entry.r#type().to_string()).or_insert(0) += 1;
@@ -563,11 +573,40 @@ impl ApiWatcher {
             .into_iter()
             .map(|(k, v)| format!("resource: {} len: {}", k, v))
             .collect::<Vec<_>>();
-        if full_sync {
-            debug!("full sync: {:?}", resource_summary);
+        if request.incremental() {
+            debug!(
+                "incremental sync based on v{}: {:?}",
+                request.base_version(),
+                resource_summary
+            );
         } else {
-            debug!("incremental sync {:?}", resource_summary);
+            debug!("full sync: {:?}", resource_summary);
+        }
+    }
+
+    fn full_entries(
+        apiserver_version: &Arc<Mutex<Info>>,
+        resource_watchers: &Arc<Mutex<HashMap<WatcherKey, GenericResourceWatcher>>>,
+    ) -> Vec<KubernetesApiInfo> {
+        let mut total_entries = vec![];
+        if let Some(i) = Self::parse_apiserver_version(apiserver_version.lock().unwrap().deref()) {
+            total_entries.push(i);
+        }
+        let resource_watchers_guard = resource_watchers.lock().unwrap();
+        for watcher in resource_watchers_guard.values() {
+            // 全量数据已包含之前的变化，先清空再获取全量
+            watcher.take_deltas();
+            let kind = watcher.pb_name();
+            for (uid, entry) in watcher.keyed_entries() {
+                total_entries.push(KubernetesApiInfo {
+                    r#type: Some(kind.to_owned()),
+                    compressed_info: Some(entry),
+                    uid: Some(uid),
+                    ..Default::default()
+                });
+            }
         }
+        total_entries
     }
 
     fn process(
@@ -575,7 +614,7 @@ impl ApiWatcher {
         apiserver_version: &Arc<Mutex<Info>>,
         session: &Arc<Session>,
         err_msgs: &Arc<Mutex<Vec<String>>>,
-        watcher_versions: &mut HashMap<WatcherKey, u64>,
+        state: &mut SyncState,
         resource_watchers: &Arc<Mutex<HashMap<WatcherKey, GenericResourceWatcher>>>,
         exception_handler: &ExceptionHandler,
         agent_id: &Arc<RwLock<AgentId>>,
@@ -587,7 +626,7 @@ impl ApiWatcher {
         {
             let mut err_msgs_guard = err_msgs.lock().unwrap();
             let resource_watchers_guard = resource_watchers.lock().unwrap();
-            for (resource, watcher_version) in watcher_versions.iter_mut() {
+            for (resource, watcher_version) in state.watcher_versions.iter_mut() {
                 if let Some(watcher) = resource_watchers_guard.get(resource) {
                     if !watcher.ready() {
                         err_msgs_guard.push(format!("{} watcher is not ready", resource));
@@ -615,7 +654,9 @@ impl ApiWatcher {
         }
 
         let mut total_entries = vec![];
-        let mut pb_version = Some(version.load(Ordering::SeqCst));
+        let mut incremental = false;
+        let base_version = version.load(Ordering::SeqCst);
+        let mut pb_version = Some(base_version);
         if has_update {
             version.fetch_add(1, Ordering::SeqCst);
             info!(
@@ -624,21 +665,16 @@ impl ApiWatcher {
                 updated_versions.join("; ")
             );
             pb_version = Some(version.load(Ordering::SeqCst));
-            if let Some(i) =
-                Self::parse_apiserver_version(apiserver_version.lock().unwrap().deref())
-            {
-                total_entries.push(i);
-            }
-            let resource_watchers_guard = resource_watchers.lock().unwrap();
-            for watcher in resource_watchers_guard.values() {
-                let kind = watcher.pb_name();
-                for entry in watcher.entries() {
-                    total_entries.push(KubernetesApiInfo {
-                        r#type: Some(kind.to_owned()),
-                        compressed_info: Some(entry),
-                        info: None,
-                    });
+            incremental = state.incremental_supported
+                && state.last_full_sync.elapsed() < FULL_SYNC_INTERVAL;
+            if incremental {
+                let resource_watchers_guard = resource_watchers.lock().unwrap();
+                for watcher in resource_watchers_guard.values() {
+                    total_entries.append(&mut watcher.take_deltas());
                 }
+            } else {
+                total_entries = Self::full_entries(apiserver_version, resource_watchers);
+                state.last_full_sync = Instant::now();
             }
         }
         let mut msg = {
@@ -658,11 +694,17 @@ impl ApiWatcher {
                         .join(";"),
                 ),
                 entries: total_entries,
+                incremental: Some(incremental),
+                base_version: if incremental {
+                    Some(base_version)
+                } else {
+                    None
+                },
             }
         };
 
         if log_enabled!(Level::Debug) {
-            Self::debug_k8s_request(&msg, false);
+            Self::debug_k8s_request(&msg);
         }
 
         match context
@@ -670,12 +712,14 @@ impl ApiWatcher {
             .block_on(session.grpc_kubernetes_api_sync_with_statsd(msg.clone()))
         {
             Ok(resp) => {
-                if has_update {
+                let resp = resp.into_inner();
+                state.incremental_supported = resp.incremental_supported();
+                if has_update && !incremental {
                     // 已经发过全量了，不用管返回
                     // 等待下一次timeout
                     return;
                 }
-                let resp = resp.into_inner();
+                // 增量数据所基于的版本与接收端不一致时，接收端返回其当前version，需要全量同步
                 if resp.version() == version.load(Ordering::SeqCst) {
                     // 接收端返回之前的version，如果相等，不需要全量同步
                     return;
@@ -691,28 +735,13 @@ impl ApiWatcher {
         }
 
         // 发送一次全量
-        let mut total_entries = vec![];
-
-        if let Some(i) = Self::parse_apiserver_version(apiserver_version.lock().unwrap().deref()) {
-            total_entries.push(i);
-        }
-        let resource_watchers_guard = resource_watchers.lock().unwrap();
-        for watcher in resource_watchers_guard.values() {
-            let kind = watcher.pb_name();
-            for entry in watcher.entries() {
-                total_entries.push(KubernetesApiInfo {
-                    r#type: Some(kind.to_owned()),
-                    compressed_info: Some(entry),
-                    info: None,
-                });
-            }
-        }
-        drop(resource_watchers_guard);
-
-        msg.entries = total_entries;
+        msg.entries = Self::full_entries(apiserver_version, resource_watchers);
+        msg.incremental = Some(false);
+        msg.base_version = None;
+        state.last_full_sync = Instant::now();
 
         if log_enabled!(Level::Debug) {
-            Self::debug_k8s_request(&msg, true);
+            Self::debug_k8s_request(&msg);
         }
 
         if let Err(e) = context
@@ -735,7 +764,7 @@ impl ApiWatcher {
                 encoder.write_all(info.as_slice()).unwrap();
                 encoder.reset(vec![]).ok()
             },
-            info: None,
+            ..Default::default()
         })
     }
 
@@ -784,6 +813,7 @@ impl ApiWatcher {
                         source_ip: Some(agent_id.read().ip.to_string()),
                         error_msg: Some(e.to_string()),
                         entries: vec![],
+                        ..Default::default()
                     };
                     if let Err(e) = context
                         .runtime
@@ -804,9 +834,13 @@ impl ApiWatcher {
         };
         info!("kubernetes api watcher running");
 
-        let mut watcher_versions = HashMap::new();
+        let mut state = SyncState {
+            watcher_versions: HashMap::new(),
+            incremental_supported: false,
+            last_full_sync: Instant::now(),
+        };
         for resource in resource_watchers.keys() {
-            watcher_versions.insert(resource.clone(), 0);
+            state.watcher_versions.insert(resource.clone(), 0);
         }
 
         *watchers.lock().unwrap() = resource_watchers;
@@ -839,7 +873,7 @@ impl ApiWatcher {
                 &apiserver_version,
                 &session,
                 &err_msgs,
-                &mut watcher_versions,
+                &mut state,
                 &resource_watchers,
                 &exception_handler,
                 &agent_id,
@@ -854,7 +888,7 @@ impl ApiWatcher {
                 &apiserver_version,
                 &session,
                 &err_msgs,
-                &mut watcher_versions,
+                &mut state,
                 &resource_watchers,
                 &exception_handler,
                 &agent_id,
@@ -137,3 +137,276 @@ pub mod kruise {
         }
     }
 }
+
+pub mod batch {
+    use super::*;
+
+    use k8s_openapi::api::batch::v1beta1::JobTemplateSpec;
+
+    // CronJob 在 kubernetes 1.21 之后才进入 batch/v1，当前 k8s-openapi 启用的 v1_19 中没有该版本
+    #[derive(CustomResource, Clone, Debug, Serialize, Deserialize, JsonSchema)]
+    #[kube(group = "batch", version = "v1", kind = "CronJob", namespaced)]
+    #[serde(rename_all = "camelCase")]
+    pub struct CronJobSpec {
+        pub job_template: JobTemplateSpec,
+    }
+
+    impl Trimmable for CronJob {
+        fn trim(mut self) -> Self {
+            let name = if let Some(name) = self.metadata.name.as_ref() {
+                name
+            } else {
+                ""
+            };
+            let mut cj = Self::new(name, self.spec);
+            cj.metadata = ObjectMeta {
+                uid: self.metadata.uid.take(),
+                name: self.metadata.name.take(),
+                namespace: self.metadata.namespace.take(),
+                labels: self.metadata.labels.take(),
+                ..Default::default()
+            };
+            cj
+        }
+    }
+}
+
+pub mod argo {
+    use super::*;
+
+    use k8s_openapi::{
+        api::core::v1::PodTemplateSpec, apimachinery::pkg::apis::meta::v1::LabelSelector,
+    };
+
+    #[derive(CustomResource, Clone, Debug, Serialize, Deserialize, JsonSchema)]
+    #[kube(
+        group = "argoproj.io",
+        version = "v1alpha1",
+        kind = "Rollout",
+        namespaced
+    )]
+    #[serde(rename_all = "camelCase")]
+    pub struct RolloutSpec {
+        pub replicas: Option<i32>,
+        pub selector: Option<LabelSelector>,
+        pub template: Option<PodTemplateSpec>,
+    }
+
+    impl Trimmable for Rollout {
+        fn trim(mut self) -> Self {
+            let name = if let Some(name) = self.metadata.name.as_ref() {
+                name
+            } else {
+                ""
+            };
+            let mut ro = Self::new(name, self.spec);
+            ro.metadata = ObjectMeta {
+                uid: self.metadata.uid.take(),
+                name: self.metadata.name.take(),
+                namespace: self.metadata.namespace.take(),
+                labels: self.metadata.labels.take(),
+                ..Default::default()
+            };
+            ro
+        }
+    }
+}
+
+pub mod gateway {
+    use super::*;
+
+    #[derive(Clone, Debug, Serialize, Deserialize, JsonSchema)]
+    #[serde(rename_all = "camelCase")]
+    pub struct Listener {
+        pub name: String,
+        pub hostname: Option<String>,
+        pub port: i32,
+        pub protocol: String,
+    }
+
+    #[derive(Clone, Debug, Serialize, Deserialize, JsonSchema)]
+    #[serde(rename_all = "camelCase")]
+    pub struct ParentReference {
+        pub group: Option<String>,
+        pub kind: Option<String>,
+        pub namespace: Option<String>,
+        pub name: String,
+        pub section_name: Option<String>,
+    }
+
+    #[derive(Clone, Debug, Serialize, Deserialize, JsonSchema)]
+    #[serde(rename_all = "camelCase")]
+    pub struct BackendRef {
+        pub group: Option<String>,
+        pub kind: Option<String>,
+        pub namespace: Option<String>,
+        pub name: String,
+        pub port: Option<i32>,
+    }
+
+    #[derive(Clone, Debug, Serialize, Deserialize, JsonSchema)]
+    #[serde(rename_all = "camelCase")]
+    pub struct HTTPPathMatch {
+        #[serde(rename = "type")]
+        pub type_: Option<String>,
+        pub value: Option<String>,
+    }
+
+    #[derive(Clone, Debug, Serialize, Deserialize, JsonSchema)]
+    #[serde(rename_all = "camelCase")]
+    pub struct HTTPRouteMatch {
+        pub path: Option<HTTPPathMatch>,
+    }
+
+    #[derive(Clone, Debug, Serialize, Deserialize, JsonSchema)]
+    #[serde(rename_all = "camelCase")]
+    pub struct HTTPRouteRule {
+        pub matches: Option<Vec<HTTPRouteMatch>>,
+        pub backend_refs: Option<Vec<BackendRef>>,
+    }
+
+    #[derive(Clone, Debug, Serialize, Deserialize, JsonSchema)]
+    #[serde(rename_all = "camelCase")]
+    pub struct GRPCMethodMatch {
+        #[serde(rename = "type")]
+        pub type_: Option<String>,
+        pub service: Option<String>,
+        pub method: Option<String>,
+    }
+
+    #[derive(Clone, Debug, Serialize, Deserialize, JsonSchema)]
+    #[serde(rename_all = "camelCase")]
+    pub struct GRPCRouteMatch {
+        pub method: Option<GRPCMethodMatch>,
+    }
+
+    #[derive(Clone, Debug, Serialize, Deserialize, JsonSchema)]
+    #[serde(rename_all = "camelCase")]
+    pub struct GRPCRouteRule {
+        pub matches: Option<Vec<GRPCRouteMatch>>,
+        pub backend_refs: Option<Vec<BackendRef>>,
+    }
+
+    // 只保留解析网关路由需要的字段，未定义的字段在反序列化时已丢弃
+    fn trim_metadata(metadata: &mut ObjectMeta) -> ObjectMeta {
+        ObjectMeta {
+            uid: metadata.uid.take(),
+            name: metadata.name.take(),
+            namespace: metadata.namespace.take(),
+            ..Default::default()
+        }
+    }
+
+    // gateway.networking.k8s.io/v1 从 Gateway API v1.0 开始提供，GRPCRoute 从 v1.1 开始提供
+    pub mod v1 {
+        use super::*;
+
+        #[derive(CustomResource, Clone, Debug, Serialize, Deserialize, JsonSchema)]
+        #[kube(
+            group = "gateway.networking.k8s.io",
+            version = "v1",
+            kind = "Gateway",
+            namespaced
+        )]
+        #[serde(rename_all = "camelCase")]
+        pub struct GatewaySpec {
+            pub gateway_class_name: String,
+            pub listeners: Vec<Listener>,
+        }
+
+        #[derive(CustomResource, Clone, Debug, Serialize, Deserialize, JsonSchema)]
+        #[kube(
+            group = "gateway.networking.k8s.io",
+            version = "v1",
+            kind = "HTTPRoute",
+            namespaced
+        )]
+        #[serde(rename_all = "camelCase")]
+        pub struct HTTPRouteSpec {
+            pub parent_refs: Option<Vec<ParentReference>>,
+            pub hostnames: Option<Vec<String>>,
+            pub rules: Option<Vec<HTTPRouteRule>>,
+        }
+
+        #[derive(CustomResource, Clone, Debug, Serialize, Deserialize, JsonSchema)]
+        #[kube(
+            group = "gateway.networking.k8s.io",
+            version = "v1",
+            kind = "GRPCRoute",
+            namespaced
+        )]
+        #[serde(rename_all = "camelCase")]
+        pub struct GRPCRouteSpec {
+            pub parent_refs: Option<Vec<ParentReference>>,
+            pub hostnames: Option<Vec<String>>,
+            pub rules: Option<Vec<GRPCRouteRule>>,
+        }
+
+        impl Trimmable for Gateway {
+            fn trim(mut self) -> Self {
+                self.metadata = trim_metadata(&mut self.metadata);
+                self
+            }
+        }
+
+        impl Trimmable for HTTPRoute {
+            fn trim(mut self) -> Self {
+                self.metadata = trim_metadata(&mut self.metadata);
+                self
+            }
+        }
+
+        impl Trimmable for GRPCRoute {
+            fn trim(mut self) -> Self {
+                self.metadata = trim_metadata(&mut self.metadata);
+                self
+            }
+        }
+    }
+
+    // Gateway API v1.0 之前的版本只提供 v1beta1
+    pub mod v1beta1 {
+        use super::*;
+
+        #[derive(CustomResource, Clone, Debug, Serialize, Deserialize, JsonSchema)]
+        #[kube(
+            group = "gateway.networking.k8s.io",
+            version = "v1beta1",
+            kind = "Gateway",
+            namespaced
+        )]
+        #[serde(rename_all = "camelCase")]
+        pub struct GatewaySpec {
+            pub gateway_class_name: String,
+            pub listeners: Vec<Listener>,
+        }
+
+        #[derive(CustomResource, Clone, Debug, Serialize, Deserialize, JsonSchema)]
+        #[kube(
+            group = "gateway.networking.k8s.io",
+            version = "v1beta1",
+            kind = "HTTPRoute",
+            namespaced
+        )]
+        #[serde(rename_all = "camelCase")]
+        pub struct HTTPRouteSpec {
+            pub parent_refs: Option<Vec<ParentReference>>,
+            pub hostnames: Option<Vec<String>>,
+            pub rules: Option<Vec<HTTPRouteRule>>,
+        }
+
+        impl Trimmable for Gateway {
+            fn trim(mut self) -> Self {
+                self.metadata = trim_metadata(&mut self.metadata);
+                self
+            }
+        }
+
+        impl Trimmable for HTTPRoute {
+            fn trim(mut self) -> Self {
+                self.metadata = trim_metadata(&mut self.metadata);
+                self
+            }
+        }
+    }
+}
@@ -34,8 +34,12 @@ use k8s_openapi::{
             DaemonSet, DaemonSetSpec, Deployment, DeploymentSpec, ReplicaSet, ReplicaSetSpec,
             StatefulSet, StatefulSetSpec,
         },
+        batch::{
+            v1::{Job, JobSpec},
+            v1beta1::CronJob as V1beta1CronJob,
+        },
         core::v1::{
-            Container, ContainerStatus, Namespace, Node, NodeSpec, NodeStatus, Pod, PodSpec,
+            Container, ContainerStatus, Event, Namespace, Node, NodeSpec, NodeStatus, Pod, PodSpec,
             PodStatus, ReplicationController, ReplicationControllerSpec, Service, ServiceSpec,
         },
         extensions, networking,
@@ -54,12 +58,19 @@ use serde::ser::Serialize;
 use tokio::{runtime::Handle, sync::Mutex, task::JoinHandle, time};
 
 use super::crd::{
+    argo::Rollout,
+    batch::CronJob,
+    gateway::{
+        v1::{GRPCRoute, Gateway, HTTPRoute},
+        v1beta1::{Gateway as V1beta1Gateway, HTTPRoute as V1beta1HTTPRoute},
+    },
     kruise::{CloneSet, StatefulSet as KruiseStatefulSet},
     pingan::ServiceRule,
 };
 use crate::utils::stats::{
     self, Countable, Counter, CounterType, CounterValue, RefCountable, StatsOption,
 };
+use public::proto::common::{KubernetesApiInfo, KubernetesWatchType};
 
 const REFRESH_INTERVAL: Duration = Duration::from_secs(3600);
 const SLEEP_INTERVAL: Duration = Duration::from_secs(5);
@@ -72,6 +83,9 @@ pub trait Watcher {
     fn start(&self) -> Option<JoinHandle<()>>;
     fn error(&self) -> Option<String>;
     fn entries(&self) -> Vec<Vec<u8>>;
+    fn keyed_entries(&self) -> Vec<(String, Vec<u8>)>;
+    // 取出上次调用后变化的对象，用于增量同步
+    fn take_deltas(&self) -> Vec<KubernetesApiInfo>;
     fn pb_name(&self) -> &str;
     fn version(&self) -> u64;
     fn ready(&self) -> bool;
@@ -93,11 +107,21 @@ pub enum GenericResourceWatcher {
     V1beta1Ingress(ResourceWatcher<networking::v1beta1::Ingress>),
     ExtV1beta1Ingress(ResourceWatcher<extensions::v1beta1::Ingress>),
     Route(ResourceWatcher<Route>),
+    Event(ResourceWatcher<Event>),
+    Job(ResourceWatcher<Job>),
+    CronJob(ResourceWatcher<CronJob>),
+    V1beta1CronJob(ResourceWatcher<V1beta1CronJob>),
 
     // CRDs
     ServiceRule(ResourceWatcher<ServiceRule>),
     CloneSet(ResourceWatcher<CloneSet>),
     KruiseStatefulSet(ResourceWatcher<KruiseStatefulSet>),
+    Rollout(ResourceWatcher<Rollout>),
+    Gateway(ResourceWatcher<Gateway>),
+    V1beta1Gateway(ResourceWatcher<V1beta1Gateway>),
+    HTTPRoute(ResourceWatcher<HTTPRoute>),
+    V1beta1HTTPRoute(ResourceWatcher<V1beta1HTTPRoute>),
+    GRPCRoute(ResourceWatcher<GRPCRoute>),
 }
 
 #[derive(Clone, Copy, Debug, PartialEq, Eq)]
@@ -362,6 +386,15 @@ pub fn supported_resources() -> Vec<Resource> {
             }],
             selected_gv: None,
         },
+        Resource {
+            name: "events",
+            pb_name: "*v1.Event",
+            group_versions: vec![GroupVersion {
+                group: "core",
+                version: "v1",
+            }],
+            selected_gv: None,
+        },
         Resource {
             name: "clonesets",
             pb_name: "*v1.CloneSet",
@@ -371,6 +404,78 @@ pub fn supported_resources() -> Vec<Resource> {
             }],
             selected_gv: None,
         },
+        Resource {
+            name: "jobs",
+            pb_name: "*v1.Job",
+            group_versions: vec![GroupVersion {
+                group: "batch",
+                version: "v1",
+            }],
+            selected_gv: None,
+        },
+        Resource {
+            name: "cronjobs",
+            pb_name: "*v1.CronJob",
+            group_versions: vec![
+                GroupVersion {
+                    group: "batch",
+                    version: "v1",
+                },
+                GroupVersion {
+                    group: "batch",
+                    version: "v1beta1",
+                },
+            ],
+            selected_gv: None,
+        },
+        Resource {
+            name: "rollouts",
+            pb_name: "*v1.Rollout",
+            group_versions: vec![GroupVersion {
+                group: "argoproj.io",
+                version: "v1alpha1",
+            }],
+            selected_gv: None,
+        },
+        Resource {
+            name: "gateways",
+            pb_name: "*v1.Gateway",
+            group_versions: vec![
+                GroupVersion {
+                    group: "gateway.networking.k8s.io",
+                    version: "v1",
+                },
+                GroupVersion {
+                    group: "gateway.networking.k8s.io",
+                    version: "v1beta1",
+                },
+            ],
+            selected_gv: None,
+        },
+        Resource {
+            name: "httproutes",
+            pb_name: "*v1.HTTPRoute",
+            group_versions: vec![
+                GroupVersion {
+                    group: "gateway.networking.k8s.io",
+                    version: "v1",
+                },
+                GroupVersion {
+                    group: "gateway.networking.k8s.io",
+                    version: "v1beta1",
+                },
+            ],
+            selected_gv: None,
+        },
+        Resource {
+            name: "grpcroutes",
+            pb_name: "*v1.GRPCRoute",
+            group_versions: vec![GroupVersion {
+                group: "gateway.networking.k8s.io",
+                version: "v1",
+            }],
+            selected_gv: None,
+        },
     ]
 }
 
@@ -440,11 +545,45 @@ pub struct WatcherConfig {
     pub max_memory: u64,
 }
 
+#[derive(Clone, Debug)]
+struct Change {
+    watch_type: KubernetesWatchType,
+    resource_version: String,
+}
+
+// 对象被多次修改时只保留最后一次变化，新增后的修改仍然视为新增
+fn record_change(
+    changes: &mut HashMap<String, Change>,
+    uid: String,
+    watch_type: KubernetesWatchType,
+    resource_version: Option<String>,
+) {
+    let resource_version = resource_version.unwrap_or_default();
+    match changes.entry(uid) {
+        Entry::Occupied(mut o) => {
+            let change = o.get_mut();
+            if !(change.watch_type == KubernetesWatchType::KwAdded
+                && watch_type == KubernetesWatchType::KwModified)
+            {
+                change.watch_type = watch_type;
+            }
+            change.resource_version = resource_version;
+        }
+        Entry::Vacant(o) => {
+            o.insert(Change {
+                watch_type,
+                resource_version,
+            });
+        }
+    }
+}
+
 // 发生错误，需要重新构造实例
 #[derive(Clone)]
 pub struct ResourceWatcher<K> {
     api: Api<K>,
     entries: Arc<Mutex<HashMap<String, Vec<u8>>>>,
+    changes: Arc<Mutex<HashMap<String, Change>>>,
     err_msg: Arc<Mutex<Option<String>>>,
     kind: Resource,
     version: Arc<AtomicU64>,
@@ -458,6 +597,7 @@ pub struct ResourceWatcher<K> {
 
 struct Context<K> {
     entries: Arc<Mutex<HashMap<String, Vec<u8>>>>,
+    changes: Arc<Mutex<HashMap<String, Change>>>,
     version: Arc<AtomicU64>,
     api: Api<K>,
     kind: Resource,
@@ -477,6 +617,7 @@ where
     fn start(&self) -> Option<JoinHandle<()>> {
         let ctx = Context {
             entries: self.entries.clone(),
+            changes: self.changes.clone(),
             version: self.version.clone(),
             kind: self.kind.clone(),
             err_msg: self.err_msg.clone(),
@@ -513,6 +654,38 @@ where
             .collect::<Vec<_>>()
     }
 
+    fn keyed_entries(&self) -> Vec<(String, Vec<u8>)> {
+        self.entries
+            .blocking_lock()
+            .iter()
+            .map(|(uid, entry)| (uid.clone(), entry.clone()))
+            .collect::<Vec<_>>()
+    }
+
+    fn take_deltas(&self) -> Vec<KubernetesApiInfo> {
+        let entries = self.entries.blocking_lock();
+        let mut changes = self.changes.blocking_lock();
+        changes
+            .drain()
+            .map(|(uid, change)| {
+                let (watch_type, compressed_info) = match entries.get(&uid) {
+                    Some(entry) if change.watch_type != KubernetesWatchType::KwDeleted => {
+                        (change.watch_type, Some(entry.clone()))
+                    }
+                    _ => (KubernetesWatchType::KwDeleted, None),
+                };
+                KubernetesApiInfo {
+                    r#type: Some(self.kind.pb_name.to_owned()),
+                    info: None,
+                    compressed_info,
+                    uid: Some(uid),
+                    resource_version: Some(change.resource_version),
+                    watch_type: Some(watch_type as i32),
+                }
+            })
+            .collect::<Vec<_>>()
+    }
+
     fn ready(&self) -> bool {
         self.ready.load(Ordering::Relaxed)
     }
@@ -532,6 +705,7 @@ where
         Self {
             api,
             entries: Arc::new(Mutex::new(HashMap::new())),
+            changes: Arc::new(Mutex::new(HashMap::new())),
             version: Arc::new(AtomicU64::new(0)),
             kind,
             err_msg: Arc::new(Mutex::new(None)),
@@ -669,6 +843,7 @@ where
             ctx.kind, ctx.config.list_limit,
         );
         let mut all_entries = HashMap::new();
+        let mut resource_versions = HashMap::new();
         let mut total_count = 0;
         let mut total_bytes = 0;
         let mut params = ListParams::default().limit(ctx.config.list_limit);
@@ -700,6 +875,7 @@ where
                         if object.meta().uid.as_ref().is_none() {
                             continue;
                         }
+                        let resource_version = object.resource_version();
                         let mut trim_object = object.trim();
                         match serde_json::to_vec(&trim_object) {
                             Ok(serialized_object) => {
@@ -719,10 +895,11 @@ where
                                     }
                                 };
                                 total_bytes += compressed_object.len();
-                                all_entries.insert(
-                                    trim_object.meta_mut().uid.take().unwrap(),
-                                    compressed_object,
-                                );
+                                let uid = trim_object.meta_mut().uid.take().unwrap();
+                                if let Some(v) = resource_version {
+                                    resource_versions.insert(uid.clone(), v);
+                                }
+                                all_entries.insert(uid, compressed_object);
                             }
                             Err(e) => warn!(
                                 "failed serialized resource {} UID({}) to json Err: {}",
@@ -742,7 +919,32 @@ where
                                 ctx.kind, total_count, total_bytes
                             );
                             if !all_entries.is_empty() {
-                                *ctx.entries.lock().await = all_entries;
+                                let mut entries = ctx.entries.lock().await;
+                                let mut changes = ctx.changes.lock().await;
+                                for (uid, entry) in all_entries.iter() {
+                                    let watch_type = match entries.get(uid) {
+                                        Some(old) if old == entry => continue,
+                                        Some(_) => KubernetesWatchType::KwModified,
+                                        None => KubernetesWatchType::KwAdded,
+                                    };
+                                    record_change(
+                                        &mut changes,
+                                        uid.clone(),
+                                        watch_type,
+                                        resource_versions.remove(uid),
+                                    );
+                                }
+                                for uid in entries.keys() {
+                                    if !all_entries.contains_key(uid) {
+                                        record_change(
+                                            &mut changes,
+                                            uid.clone(),
+                                            KubernetesWatchType::KwDeleted,
+                                            None,
+                                        );
+                                    }
+                                }
+                                *entries = all_entries;
                                 ctx.version.fetch_add(1, Ordering::SeqCst);
                             }
                             ctx.resource_version = object_list.metadata.resource_version.take();
@@ -773,7 +975,15 @@ where
     ) {
         match event {
             WatchEvent::Added(object) | WatchEvent::Modified(object) => {
-                Self::insert_object(encoder, object, &ctx.entries, &ctx.version, &ctx.kind).await;
+                Self::insert_object(
+                    encoder,
+                    object,
+                    &ctx.entries,
+                    &ctx.changes,
+                    &ctx.version,
+                    &ctx.kind,
+                )
+                .await;
                 ctx.stats_counter
                     .watch_applied
                     .fetch_add(1, Ordering::Relaxed);
@@ -781,7 +991,14 @@ where
             WatchEvent::Deleted(mut object) => {
                 if let Some(uid) = object.meta_mut().uid.take() {
                     // 只有删除时检查是否需要更新版本号，其余消息直接更新map内容
-                    if ctx.entries.lock().await.remove(&uid).is_some() {
+                    let mut entries = ctx.entries.lock().await;
+                    if entries.remove(&uid).is_some() {
+                        record_change(
+                            &mut *ctx.changes.lock().await,
+                            uid,
+                            KubernetesWatchType::KwDeleted,
+                            object.resource_version(),
+                        );
                         ctx.version.fetch_add(1, Ordering::SeqCst);
                     }
                     ctx.stats_counter
@@ -797,11 +1014,13 @@ where
         encoder: &mut ZlibEncoder<Vec<u8>>,
         object: K,
         entries: &Arc<Mutex<HashMap<String, Vec<u8>>>>,
+        changes: &Arc<Mutex<HashMap<String, Change>>>,
         version: &Arc<AtomicU64>,
         kind: &Resource,
     ) {
         let uid = object.meta().uid.clone();
         if let Some(uid) = uid {
+            let resource_version = object.resource_version();
             let trim_object = object.trim();
             let serialized_object = serde_json::to_vec(&trim_object);
             match serialized_object {
@@ -819,15 +1038,23 @@ where
                         }
                     };
                     let mut entries = entries.lock().await;
-                    match entries.entry(uid) {
+                    let watch_type = match entries.entry(uid.clone()) {
                         Entry::Occupied(o) if o.get() == &compressed_object => return,
                         Entry::Occupied(mut o) => {
                             o.insert(compressed_object);
+                            KubernetesWatchType::KwModified
                         }
                         Entry::Vacant(o) => {
                             o.insert(compressed_object);
+                            KubernetesWatchType::KwAdded
                         }
-                    }
+                    };
+                    record_change(
+                        &mut *changes.lock().await,
+                        uid,
+                        watch_type,
+                        resource_version,
+                    );
                     version.fetch_add(1, Ordering::SeqCst);
                 }
                 Err(e) => debug!(
@@ -1129,6 +1356,66 @@ impl Trimmable for Namespace {
     }
 }
 
+impl Trimmable for Event {
+    fn trim(mut self) -> Self {
+        let mut trim_event = Event::default();
+        trim_event.metadata = ObjectMeta {
+            uid: self.metadata.uid.take(),
+            name: self.metadata.name.take(),
+            namespace: self.metadata.namespace.take(),
+            ..Default::default()
+        };
+        trim_event.involved_object = self.involved_object;
+        trim_event.reason = self.reason.take();
+        trim_event.message = self.message.take();
+        trim_event.type_ = self.type_.take();
+        trim_event.count = self.count.take();
+        trim_event.first_timestamp = self.first_timestamp.take();
+        trim_event.last_timestamp = self.last_timestamp.take();
+        trim_event.event_time = self.event_time.take();
+        trim_event
+    }
+}
+
+impl Trimmable for Job {
+    fn trim(mut self) -> Self {
+        let mut trim_job = Job::default();
+        trim_job.metadata = ObjectMeta {
+            uid: self.metadata.uid.take(),
+            name: self.metadata.name.take(),
+            namespace: self.metadata.namespace.take(),
+            owner_references: self.metadata.owner_references.take(),
+            labels: self.metadata.labels.take(),
+            ..Default::default()
+        };
+
+        if let Some(job_spec) = self.spec.take() {
+            trim_job.spec = Some(JobSpec {
+                parallelism: job_spec.parallelism,
+                selector: job_spec.selector,
+                template: job_spec.template,
+                ..Default::default()
+            });
+        }
+        trim_job
+    }
+}
+
+impl Trimmable for V1beta1CronJob {
+    fn trim(mut self) -> Self {
+        let mut trim_cj = V1beta1CronJob::default();
+        trim_cj.metadata = ObjectMeta {
+            uid: self.metadata.uid.take(),
+            name: self.metadata.name.take(),
+            namespace: self.metadata.namespace.take(),
+            labels: self.metadata.labels.take(),
+            ..Default::default()
+        };
+        trim_cj.spec = self.spec.take();
+        trim_cj
+    }
+}
+
 pub struct ResourceWatcherFactory {
     client: Client,
     runtime: Handle,
@@ -1306,12 +1593,120 @@ impl ResourceWatcherFactory {
                 namespace,
                 config,
             )),
+            "events" => GenericResourceWatcher::Event(self.new_watcher_inner(
+                resource,
+                stats_collector,
+                namespace,
+                config,
+            )),
             "clonesets" => GenericResourceWatcher::CloneSet(self.new_watcher_inner(
                 resource,
                 stats_collector,
                 namespace,
                 config,
             )),
+            "jobs" => GenericResourceWatcher::Job(self.new_watcher_inner(
+                resource,
+                stats_collector,
+                namespace,
+                config,
+            )),
+            "cronjobs" => match resource.selected_gv.as_ref().unwrap() {
+                GroupVersion {
+                    group: "batch",
+                    version: "v1",
+                } => GenericResourceWatcher::CronJob(self.new_watcher_inner(
+                    resource,
+                    stats_collector,
+                    namespace,
+                    config,
+                )),
+                GroupVersion {
+                    group: "batch",
+                    version: "v1beta1",
+                } => GenericResourceWatcher::V1beta1CronJob(self.new_watcher_inner(
+                    resource,
+                    stats_collector,
+                    namespace,
+                    config,
+                )),
+                _ => {
+                    warn!(
+                        "unsupported resource {} group version {}",
+                        resource.name,
+                        resource.selected_gv.as_ref().unwrap()
+                    );
+                    return None;
+                }
+            },
+            "rollouts" => GenericResourceWatcher::Rollout(self.new_watcher_inner(
+                resource,
+                stats_collector,
+                namespace,
+                config,
+            )),
+            "gateways" => match resource.selected_gv.as_ref().unwrap() {
+                GroupVersion {
+                    group: "gateway.networking.k8s.io",
+                    version: "v1",
+                } => GenericResourceWatcher::Gateway(self.new_watcher_inner(
+                    resource,
+                    stats_collector,
+                    namespace,
+                    config,
+                )),
+                GroupVersion {
+                    group: "gateway.networking.k8s.io",
+                    version: "v1beta1",
+                } => GenericResourceWatcher::V1beta1Gateway(self.new_watcher_inner(
+                    resource,
+                    stats_collector,
+                    namespace,
+                    config,
+                )),
+                _ => {
+                    warn!(
+                        "unsupported resource {} group version {}",
+                        resource.name,
+                        resource.selected_gv.as_ref().unwrap()
+                    );
+                    return None;
+                }
+            },
+            "httproutes" => match resource.selected_gv.as_ref().unwrap() {
+                GroupVersion {
+                    group: "gateway.networking.k8s.io",
+                    version: "v1",
+                } => GenericResourceWatcher::HTTPRoute(self.new_watcher_inner(
+                    resource,
+                    stats_collector,
+                    namespace,
+                    config,
+                )),
+                GroupVersion {
+                    group: "gateway.networking.k8s.io",
+                    version: "v1beta1",
+                } => GenericResourceWatcher::V1beta1HTTPRoute(self.new_watcher_inner(
+                    resource,
+                    stats_collector,
+                    namespace,
+                    config,
+                )),
+                _ => {
+                    warn!(
+                        "unsupported resource {} group version {}",
+                        resource.name,
+                        resource.selected_gv.as_ref().unwrap()
+                    );
+                    return None;
+                }
+            },
+            "grpcroutes" => GenericResourceWatcher::GRPCRoute(self.new_watcher_inner(
+                resource,
+                stats_collector,
+                namespace,
+                config,
+            )),
             _ => {
                 warn!("unsupported resource {}", resource.name);
                 return None;
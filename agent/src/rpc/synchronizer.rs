@@ -87,6 +87,7 @@ pub struct StaticConfig {
 
     pub tap_mode: tp::TapMode,
     pub vtap_group_id_request: String,
+    pub enrollment_token: String,
     pub controller_ip: String,
 
     pub env: RuntimeEnvironment,
@@ -113,6 +114,7 @@ impl Default for StaticConfig {
             boot_time: SystemTime::now(),
             tap_mode: Default::default(),
             vtap_group_id_request: Default::default(),
+            enrollment_token: Default::default(),
             controller_ip: Default::default(),
             env: Default::default(),
             kubernetes_cluster_id: Default::default(),
@@ -461,6 +463,7 @@ impl Synchronizer {
         agent_id: AgentId,
         controller_ip: String,
         vtap_group_id_request: String,
+        enrollment_token: String,
         kubernetes_cluster_id: String,
         kubernetes_cluster_name: Option<String>,
         override_os_hostname: Option<String>,
@@ -475,6 +478,7 @@ impl Synchronizer {
                 boot_time: SystemTime::now(),
                 tap_mode: tp::TapMode::Local,
                 vtap_group_id_request,
+                enrollment_token,
                 controller_ip,
                 env: RuntimeEnvironment::new(),
                 kubernetes_cluster_id,
@@ -597,6 +601,7 @@ impl Synchronizer {
             os: Some(static_config.env.os.clone()),
             kernel_version: Some(static_config.env.kernel_version.clone()),
             vtap_group_id_request: Some(static_config.vtap_group_id_request.clone()),
+            enrollment_token: Some(static_config.enrollment_token.clone()),
             kubernetes_cluster_id: Some(static_config.kubernetes_cluster_id.clone()),
             kubernetes_cluster_name: static_config.kubernetes_cluster_name.clone(),
             kubernetes_force_watch: Some(running_in_only_watch_k8s_mode()),
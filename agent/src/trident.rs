@@ -479,6 +479,7 @@ impl Trident {
             agent_id,
             config_handler.static_config.controller_ips[0].clone(),
             config_handler.static_config.vtap_group_id_request.clone(),
+            config_handler.static_config.enrollment_token.clone(),
             config_handler.static_config.kubernetes_cluster_id.clone(),
             config_handler.static_config.kubernetes_cluster_name.clone(),
             config_handler.static_config.override_os_hostname.clone(),
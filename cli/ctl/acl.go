@@ -0,0 +1,323 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ctl
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	simplejson "github.com/bitly/go-simplejson"
+	"github.com/spf13/cobra"
+
+	"github.com/deepflowio/deepflow/cli/ctl/common"
+	"github.com/deepflowio/deepflow/cli/ctl/common/jsonparser"
+)
+
+type aclFlags struct {
+	application string
+	disabled    bool
+	tapType     int
+	srcGroupIDs []int
+	dstGroupIDs []int
+	protocol    int
+	srcPorts    string
+	dstPorts    string
+	vlan        int
+}
+
+type resourceGroupFlags struct {
+	business string
+	vpcID    int
+	ips      []string
+	vmIDs    []int
+}
+
+var aclApplications = map[string]int{"pcap": 4, "npb": 6}
+
+var resourceGroupBusinessIDs = map[string]int{"npb": 1, "pcap": -3}
+
+func RegisterACLCommand() *cobra.Command {
+	acl := &cobra.Command{
+		Use:   "acl",
+		Short: "acls matching the flows of npb and pcap policies",
+		Run: func(cmd *cobra.Command, args []string) {
+			fmt.Printf("please run with 'list | create | delete | group'.\n")
+		},
+	}
+
+	list := &cobra.Command{
+		Use:     "list",
+		Short:   "list acls",
+		Example: "deepflow-ctl acl list",
+		Run: func(cmd *cobra.Command, args []string) {
+			listACL(cmd)
+		},
+	}
+	var flags aclFlags
+	create := &cobra.Command{
+		Use:   "create <name>",
+		Short: "create acl, empty conditions match any",
+		Example: "deepflow-ctl acl create web --application npb --src-group-ids 1,2 --protocol 6 --dst-ports 80,443,8000-8080\n" +
+			"deepflow-ctl acl create dns --application pcap --protocol 17 --dst-ports 53",
+		Run: func(cmd *cobra.Command, args []string) {
+			createACL(cmd, args, &flags)
+		},
+	}
+	create.Flags().StringVar(&flags.application, "application", "", "npb or pcap, groups of the acl should be of the same business")
+	create.Flags().BoolVar(&flags.disabled, "disabled", false, "create the acl disabled")
+	create.Flags().IntVar(&flags.tapType, "tap-type", 3, "value of tap type")
+	create.Flags().IntSliceVar(&flags.srcGroupIDs, "src-group-ids", nil, "ids of source resource groups")
+	create.Flags().IntSliceVar(&flags.dstGroupIDs, "dst-group-ids", nil, "ids of destination resource groups")
+	create.Flags().IntVar(&flags.protocol, "protocol", -1, "ip protocol number, such as 6 (TCP), 17 (UDP), -1 means any")
+	create.Flags().StringVar(&flags.srcPorts, "src-ports", "", "source ports, such as 80,443,8000-8080")
+	create.Flags().StringVar(&flags.dstPorts, "dst-ports", "", "destination ports, such as 80,443,8000-8080")
+	create.Flags().IntVar(&flags.vlan, "vlan", 0, "vlan id, 0 means any")
+	create.MarkFlagRequired("application")
+	aclDelete := &cobra.Command{
+		Use:     "delete <name>",
+		Short:   "delete acl",
+		Example: "deepflow-ctl acl delete web",
+		Run: func(cmd *cobra.Command, args []string) {
+			deleteByName(cmd, args, "/v1/acls/", "acl")
+		},
+	}
+
+	acl.AddCommand(list)
+	acl.AddCommand(create)
+	acl.AddCommand(aclDelete)
+	acl.AddCommand(registerResourceGroupCommand())
+	return acl
+}
+
+func registerResourceGroupCommand() *cobra.Command {
+	group := &cobra.Command{
+		Use:   "group",
+		Short: "resource groups used as source or destination of acls",
+		Run: func(cmd *cobra.Command, args []string) {
+			fmt.Printf("please run with 'list | create | delete'.\n")
+		},
+	}
+
+	list := &cobra.Command{
+		Use:     "list",
+		Short:   "list resource groups",
+		Example: "deepflow-ctl acl group list",
+		Run: func(cmd *cobra.Command, args []string) {
+			listResourceGroup(cmd)
+		},
+	}
+	var flags resourceGroupFlags
+	create := &cobra.Command{
+		Use:   "create <name>",
+		Short: "create ip group, or vm group if vpc id is specified",
+		Example: "deepflow-ctl acl group create office --business npb --ips 10.1.0.0/16,10.2.0.1-10.2.0.9\n" +
+			"deepflow-ctl acl group create db --business pcap --vpc-id 1 --vm-ids 3,4",
+		Run: func(cmd *cobra.Command, args []string) {
+			createResourceGroup(cmd, args, &flags)
+		},
+	}
+	create.Flags().StringVar(&flags.business, "business", "npb", "npb or pcap")
+	create.Flags().IntVar(&flags.vpcID, "vpc-id", 0, "vpc id of vm group")
+	create.Flags().StringSliceVar(&flags.ips, "ips", nil, "ips of ip group, such as 10.1.1.1, 10.1.0.0/16 or 10.1.1.1-10.1.1.9")
+	create.Flags().IntSliceVar(&flags.vmIDs, "vm-ids", nil, "vm ids of vm group, empty means all vms of the vpc")
+	groupDelete := &cobra.Command{
+		Use:     "delete <name>",
+		Short:   "delete resource group",
+		Example: "deepflow-ctl acl group delete office",
+		Run: func(cmd *cobra.Command, args []string) {
+			deleteByName(cmd, args, "/v1/resource-groups/", "resource group")
+		},
+	}
+
+	group.AddCommand(list)
+	group.AddCommand(create)
+	group.AddCommand(groupDelete)
+	return group
+}
+
+func listACL(cmd *cobra.Command) {
+	server := common.GetServerInfo(cmd)
+	url := fmt.Sprintf("http://%s:%d/v1/acls/", server.IP, server.Port)
+	response, err := common.CURLPerform("GET", url, nil, "")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return
+	}
+
+	data := response.Get("DATA")
+	nameMaxSize := jsonparser.GetTheMaxSizeOfAttr(data, "NAME")
+	cmdFormat := "%-6s %-*s %-5s %-11s %-8s %s\n"
+	fmt.Printf(cmdFormat, "ID", nameMaxSize, "NAME", "STATE", "APPLICATION", "TAP_TYPE", "CONDITIONS")
+	for i := range data.MustArray() {
+		d := data.GetIndex(i)
+		conditions := []string{}
+		for _, key := range []string{"SRC_GROUP_IDS", "DST_GROUP_IDS"} {
+			if ids := d.Get(key).MustArray(); len(ids) > 0 {
+				conditions = append(conditions, fmt.Sprintf("%s=%v", key, ids))
+			}
+		}
+		if protocol, err := d.Get("PROTOCOL").Int(); err == nil {
+			conditions = append(conditions, fmt.Sprintf("PROTOCOL=%d", protocol))
+		}
+		for _, key := range []string{"SRC_PORTS", "DST_PORTS"} {
+			if ports := d.Get(key).MustString(); ports != "" {
+				conditions = append(conditions, fmt.Sprintf("%s=%s", key, ports))
+			}
+		}
+		if vlan := d.Get("VLAN").MustInt(); vlan != 0 {
+			conditions = append(conditions, fmt.Sprintf("VLAN=%d", vlan))
+		}
+		fmt.Printf(cmdFormat,
+			fmt.Sprint(d.Get("ID").MustInt()),
+			nameMaxSize, d.Get("NAME").MustString(),
+			fmt.Sprint(d.Get("STATE").MustInt()),
+			fmt.Sprint(d.Get("APPLICATION").MustInt()),
+			fmt.Sprint(d.Get("TAP_TYPE").MustInt()),
+			strings.Join(conditions, " "),
+		)
+	}
+}
+
+func createACL(cmd *cobra.Command, args []string, flags *aclFlags) {
+	if len(args) == 0 {
+		fmt.Fprintf(os.Stderr, "must specify name.\nExample: %s\n", cmd.Example)
+		return
+	}
+	application, ok := aclApplications[flags.application]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "application (%s) invalid, should be npb or pcap\n", flags.application)
+		return
+	}
+
+	state := 1
+	if flags.disabled {
+		state = 0
+	}
+	body := map[string]interface{}{
+		"NAME":          args[0],
+		"STATE":         state,
+		"APPLICATION":   application,
+		"TAP_TYPE":      flags.tapType,
+		"SRC_GROUP_IDS": flags.srcGroupIDs,
+		"DST_GROUP_IDS": flags.dstGroupIDs,
+		"SRC_PORTS":     flags.srcPorts,
+		"DST_PORTS":     flags.dstPorts,
+		"VLAN":          flags.vlan,
+	}
+	if flags.protocol >= 0 {
+		body["PROTOCOL"] = flags.protocol
+	}
+	server := common.GetServerInfo(cmd)
+	url := fmt.Sprintf("http://%s:%d/v1/acls/", server.IP, server.Port)
+	if _, err := common.CURLPerform("POST", url, body, ""); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+}
+
+func listResourceGroup(cmd *cobra.Command) {
+	server := common.GetServerInfo(cmd)
+	url := fmt.Sprintf("http://%s:%d/v1/resource-groups/", server.IP, server.Port)
+	response, err := common.CURLPerform("GET", url, nil, "")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return
+	}
+
+	data := response.Get("DATA")
+	nameMaxSize := jsonparser.GetTheMaxSizeOfAttr(data, "NAME")
+	cmdFormat := "%-6s %-*s %-11s %-4s %s\n"
+	fmt.Printf(cmdFormat, "ID", nameMaxSize, "NAME", "BUSINESS_ID", "TYPE", "MEMBERS")
+	for i := range data.MustArray() {
+		d := data.GetIndex(i)
+		members := strings.Join(d.Get("IPS").MustStringArray(), ",")
+		groupType := "ip"
+		if d.Get("TYPE").MustInt() == 1 {
+			groupType = "vm"
+			members = fmt.Sprintf("VPC_ID=%d VM_IDS=%v", d.Get("VPC_ID").MustInt(), d.Get("VM_IDS").MustArray())
+		}
+		fmt.Printf(cmdFormat,
+			fmt.Sprint(d.Get("ID").MustInt()),
+			nameMaxSize, d.Get("NAME").MustString(),
+			fmt.Sprint(d.Get("BUSINESS_ID").MustInt()),
+			groupType,
+			members,
+		)
+	}
+}
+
+func createResourceGroup(cmd *cobra.Command, args []string, flags *resourceGroupFlags) {
+	if len(args) == 0 {
+		fmt.Fprintf(os.Stderr, "must specify name.\nExample: %s\n", cmd.Example)
+		return
+	}
+	businessID, ok := resourceGroupBusinessIDs[flags.business]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "business (%s) invalid, should be npb or pcap\n", flags.business)
+		return
+	}
+
+	body := map[string]interface{}{
+		"NAME":        args[0],
+		"BUSINESS_ID": businessID,
+		"TYPE":        2,
+		"IPS":         flags.ips,
+	}
+	if flags.vpcID != 0 {
+		body["TYPE"] = 1
+		body["VPC_ID"] = flags.vpcID
+		body["VM_IDS"] = flags.vmIDs
+	}
+	server := common.GetServerInfo(cmd)
+	url := fmt.Sprintf("http://%s:%d/v1/resource-groups/", server.IP, server.Port)
+	if _, err := common.CURLPerform("POST", url, body, ""); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+}
+
+// deleteByName deletes the resource of the path, the lcuuid is looked up by name
+func deleteByName(cmd *cobra.Command, args []string, path, kind string) {
+	if len(args) == 0 {
+		fmt.Fprintf(os.Stderr, "must specify name.\nExample: %s\n", cmd.Example)
+		return
+	}
+
+	server := common.GetServerInfo(cmd)
+	item, err := getByName(server, path, kind, args[0])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return
+	}
+	deleteURL := fmt.Sprintf("http://%s:%d%s%s/", server.IP, server.Port, path, item.Get("LCUUID").MustString())
+	if _, err := common.CURLPerform("DELETE", deleteURL, nil, ""); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+}
+
+// getByName returns the resource of the path with the name
+func getByName(server *common.Server, path, kind, name string) (*simplejson.Json, error) {
+	getURL := fmt.Sprintf("http://%s:%d%s?name=%s", server.IP, server.Port, path, url.QueryEscape(name))
+	response, err := common.CURLPerform("GET", getURL, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	if len(response.Get("DATA").MustArray()) == 0 {
+		return nil, fmt.Errorf("%s (%s) not found", kind, name)
+	}
+	return response.Get("DATA").GetIndex(0), nil
+}
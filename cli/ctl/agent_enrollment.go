@@ -0,0 +1,252 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ctl
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/deepflowio/deepflow/cli/ctl/common"
+	"github.com/deepflowio/deepflow/cli/ctl/common/jsonparser"
+)
+
+func RegisterAgentEnrollmentCommand() *cobra.Command {
+	enrollment := &cobra.Command{
+		Use:   "agent-enrollment",
+		Short: "agent enrollment token and registration approval commands",
+		Run: func(cmd *cobra.Command, args []string) {
+			fmt.Printf("please run with 'token | pending | approve | reject'.\n")
+		},
+	}
+
+	token := &cobra.Command{
+		Use:   "token",
+		Short: "enrollment token operation commands",
+		Run: func(cmd *cobra.Command, args []string) {
+			fmt.Printf("please run with 'list | create | delete'.\n")
+		},
+	}
+	tokenList := &cobra.Command{
+		Use:     "list",
+		Short:   "list enrollment tokens",
+		Example: "deepflow-ctl agent-enrollment token list",
+		Run: func(cmd *cobra.Command, args []string) {
+			listEnrollmentToken(cmd)
+		},
+	}
+	var groupID, expiredAt string
+	var maxUses int
+	tokenCreate := &cobra.Command{
+		Use:     "create <name>",
+		Short:   "create enrollment token, agents registered by it join the agent group",
+		Example: "deepflow-ctl agent-enrollment token create prod-nodes --group-id g-1yhIguXABC --max-uses 100 --expired-at '2024-01-01 00:00:00'",
+		Run: func(cmd *cobra.Command, args []string) {
+			createEnrollmentToken(cmd, args, groupID, maxUses, expiredAt)
+		},
+	}
+	tokenCreate.Flags().StringVar(&groupID, "group-id", "", "agent group id, such as g-1yhIguXABC")
+	tokenCreate.Flags().IntVar(&maxUses, "max-uses", 0, "max number of agents registered by the token, 0 means unlimited")
+	tokenCreate.Flags().StringVar(&expiredAt, "expired-at", "", "format: 2006-01-02 15:04:05, empty means never expire")
+	tokenCreate.MarkFlagRequired("group-id")
+	tokenDelete := &cobra.Command{
+		Use:     "delete <name>",
+		Short:   "delete enrollment token",
+		Example: "deepflow-ctl agent-enrollment token delete prod-nodes",
+		Run: func(cmd *cobra.Command, args []string) {
+			deleteEnrollmentToken(cmd, args)
+		},
+	}
+	token.AddCommand(tokenList)
+	token.AddCommand(tokenCreate)
+	token.AddCommand(tokenDelete)
+
+	pending := &cobra.Command{
+		Use:     "pending",
+		Short:   "list agents waiting for registration approval",
+		Example: "deepflow-ctl agent-enrollment pending",
+		Run: func(cmd *cobra.Command, args []string) {
+			listPendingAgent(cmd)
+		},
+	}
+	var approveGroupLcuuid string
+	approve := &cobra.Command{
+		Use:     "approve <name | lcuuid>",
+		Short:   "approve registration of a pending agent",
+		Example: "deepflow-ctl agent-enrollment approve node-1",
+		Run: func(cmd *cobra.Command, args []string) {
+			reviewPendingAgent(cmd, args, "approve", approveGroupLcuuid)
+		},
+	}
+	approve.Flags().StringVar(&approveGroupLcuuid, "group-lcuuid", "", "move the agent to the agent group")
+	reject := &cobra.Command{
+		Use:     "reject <name | lcuuid>",
+		Short:   "reject registration of a pending agent, the agent is disabled",
+		Example: "deepflow-ctl agent-enrollment reject node-1",
+		Run: func(cmd *cobra.Command, args []string) {
+			reviewPendingAgent(cmd, args, "reject", "")
+		},
+	}
+
+	enrollment.AddCommand(token)
+	enrollment.AddCommand(pending)
+	enrollment.AddCommand(approve)
+	enrollment.AddCommand(reject)
+	return enrollment
+}
+
+func listEnrollmentToken(cmd *cobra.Command) {
+	server := common.GetServerInfo(cmd)
+	url := fmt.Sprintf("http://%s:%d/v1/vtap-enrollment-tokens/", server.IP, server.Port)
+	response, err := common.CURLPerform("GET", url, nil, "")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return
+	}
+
+	data := response.Get("DATA")
+	var (
+		nameMaxSize  = jsonparser.GetTheMaxSizeOfAttr(data, "NAME")
+		groupMaxSize = jsonparser.GetTheMaxSizeOfAttr(data, "VTAP_GROUP_NAME")
+	)
+	cmdFormat := "%-*s %-*s %-12s %-8s %-10s %-19s\n"
+	fmt.Printf(cmdFormat, nameMaxSize, "NAME", groupMaxSize, "VTAP_GROUP_NAME", "TOKEN_PREFIX", "MAX_USES", "USED_COUNT", "EXPIRED_AT")
+	for i := range data.MustArray() {
+		d := data.GetIndex(i)
+		fmt.Printf(cmdFormat,
+			nameMaxSize, d.Get("NAME").MustString(),
+			groupMaxSize, d.Get("VTAP_GROUP_NAME").MustString(),
+			d.Get("TOKEN_PREFIX").MustString(),
+			fmt.Sprint(d.Get("MAX_USES").MustInt()),
+			fmt.Sprint(d.Get("USED_COUNT").MustInt()),
+			d.Get("EXPIRED_AT").MustString(),
+		)
+	}
+}
+
+func createEnrollmentToken(cmd *cobra.Command, args []string, groupID string, maxUses int, expiredAt string) {
+	if len(args) == 0 {
+		fmt.Fprintf(os.Stderr, "must specify name.\nExample: %s\n", cmd.Example)
+		return
+	}
+
+	server := common.GetServerInfo(cmd)
+	url := fmt.Sprintf("http://%s:%d/v1/vtap-enrollment-tokens/", server.IP, server.Port)
+	body := map[string]interface{}{
+		"NAME":          args[0],
+		"VTAP_GROUP_ID": groupID,
+		"MAX_USES":      maxUses,
+		"EXPIRED_AT":    expiredAt,
+	}
+	response, err := common.CURLPerform("POST", url, body, "")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return
+	}
+	fmt.Printf("enrollment token (it cannot be retrieved again, set it as enrollment-token in agent config):\n%s\n",
+		response.Get("DATA").Get("TOKEN").MustString())
+}
+
+func deleteEnrollmentToken(cmd *cobra.Command, args []string) {
+	if len(args) == 0 {
+		fmt.Fprintf(os.Stderr, "must specify name.\nExample: %s\n", cmd.Example)
+		return
+	}
+
+	server := common.GetServerInfo(cmd)
+	url := fmt.Sprintf("http://%s:%d/v1/vtap-enrollment-tokens/?name=%s", server.IP, server.Port, args[0])
+	response, err := common.CURLPerform("GET", url, nil, "")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return
+	}
+	if len(response.Get("DATA").MustArray()) == 0 {
+		fmt.Fprintf(os.Stderr, "enrollment token (%s) not found\n", args[0])
+		return
+	}
+	lcuuid := response.Get("DATA").GetIndex(0).Get("LCUUID").MustString()
+	url = fmt.Sprintf("http://%s:%d/v1/vtap-enrollment-tokens/%s/", server.IP, server.Port, lcuuid)
+	if _, err := common.CURLPerform("DELETE", url, nil, ""); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+}
+
+func listPendingAgent(cmd *cobra.Command) {
+	server := common.GetServerInfo(cmd)
+	url := fmt.Sprintf("http://%s:%d/v1/vtap-registrations/pending/", server.IP, server.Port)
+	response, err := common.CURLPerform("GET", url, nil, "")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return
+	}
+
+	data := response.Get("DATA")
+	var (
+		nameMaxSize   = jsonparser.GetTheMaxSizeOfAttr(data, "NAME")
+		ctrlIPMaxSize = jsonparser.GetTheMaxSizeOfAttr(data, "CTRL_IP")
+		groupMaxSize  = jsonparser.GetTheMaxSizeOfAttr(data, "VTAP_GROUP_NAME")
+	)
+	cmdFormat := "%-*s %-*s %-17s %-*s %s\n"
+	fmt.Printf(cmdFormat, nameMaxSize, "NAME", ctrlIPMaxSize, "CTRL_IP", "CTRL_MAC", groupMaxSize, "VTAP_GROUP_NAME", "LCUUID")
+	for i := range data.MustArray() {
+		d := data.GetIndex(i)
+		fmt.Printf(cmdFormat,
+			nameMaxSize, d.Get("NAME").MustString(),
+			ctrlIPMaxSize, d.Get("CTRL_IP").MustString(),
+			d.Get("CTRL_MAC").MustString(),
+			groupMaxSize, d.Get("VTAP_GROUP_NAME").MustString(),
+			d.Get("LCUUID").MustString(),
+		)
+	}
+}
+
+func reviewPendingAgent(cmd *cobra.Command, args []string, action, groupLcuuid string) {
+	if len(args) == 0 {
+		fmt.Fprintf(os.Stderr, "must specify name or lcuuid.\nExample: %s\n", cmd.Example)
+		return
+	}
+
+	server := common.GetServerInfo(cmd)
+	url := fmt.Sprintf("http://%s:%d/v1/vtap-registrations/pending/", server.IP, server.Port)
+	response, err := common.CURLPerform("GET", url, nil, "")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return
+	}
+	lcuuid := ""
+	for i := range response.Get("DATA").MustArray() {
+		d := response.Get("DATA").GetIndex(i)
+		if d.Get("NAME").MustString() == args[0] || d.Get("LCUUID").MustString() == args[0] {
+			lcuuid = d.Get("LCUUID").MustString()
+			break
+		}
+	}
+	if lcuuid == "" {
+		fmt.Fprintf(os.Stderr, "pending agent (%s) not found\n", args[0])
+		return
+	}
+
+	url = fmt.Sprintf("http://%s:%d/v1/vtap-registrations/%s/%s/", server.IP, server.Port, lcuuid, action)
+	var body map[string]interface{}
+	if groupLcuuid != "" {
+		body = map[string]interface{}{"VTAP_GROUP_LCUUID": groupLcuuid}
+	}
+	if _, err := common.CURLPerform("POST", url, body, ""); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+}
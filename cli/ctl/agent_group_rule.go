@@ -0,0 +1,234 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ctl
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	simplejson "github.com/bitly/go-simplejson"
+	"github.com/spf13/cobra"
+
+	"github.com/deepflowio/deepflow/cli/ctl/common"
+	"github.com/deepflowio/deepflow/cli/ctl/common/jsonparser"
+)
+
+type agentGroupRuleFlags struct {
+	groupLcuuid   string
+	priority      int
+	disabled      bool
+	clusterIDs    []string
+	regions       []string
+	azs           []string
+	hostnameRegex string
+	ipCIDRs       []string
+	podNodeLabels []string
+	agentTypes    []int
+	dryRun        bool
+}
+
+func RegisterAgentGroupRuleCommand() *cobra.Command {
+	rule := &cobra.Command{
+		Use:   "agent-group-rule",
+		Short: "rules of assigning agents to agent groups automatically",
+		Run: func(cmd *cobra.Command, args []string) {
+			fmt.Printf("please run with 'list | create | delete | preview'.\n")
+		},
+	}
+
+	list := &cobra.Command{
+		Use:     "list",
+		Short:   "list agent group rules",
+		Example: "deepflow-ctl agent-group-rule list",
+		Run: func(cmd *cobra.Command, args []string) {
+			listAgentGroupRule(cmd)
+		},
+	}
+	var flags agentGroupRuleFlags
+	create := &cobra.Command{
+		Use:   "create <name>",
+		Short: "create agent group rule, conditions are ANDed and values of the same condition are ORed",
+		Example: "deepflow-ctl agent-group-rule create prod-k8s --group-lcuuid xxx --cluster-ids d-1yhIguXABC --pod-node-labels env:prod\n" +
+			"deepflow-ctl agent-group-rule create office --group-lcuuid xxx --ip-cidrs 10.1.0.0/16 --dry-run",
+		Run: func(cmd *cobra.Command, args []string) {
+			createAgentGroupRule(cmd, args, &flags)
+		},
+	}
+	create.Flags().StringVar(&flags.groupLcuuid, "group-lcuuid", "", "lcuuid of the agent group which matched agents are assigned to")
+	create.Flags().IntVar(&flags.priority, "priority", 100, "rules are matched in ascending order of priority")
+	create.Flags().BoolVar(&flags.disabled, "disabled", false, "create the rule disabled")
+	create.Flags().StringSliceVar(&flags.clusterIDs, "cluster-ids", nil, "kubernetes cluster ids")
+	create.Flags().StringSliceVar(&flags.regions, "regions", nil, "region lcuuids")
+	create.Flags().StringSliceVar(&flags.azs, "azs", nil, "az lcuuids")
+	create.Flags().StringVar(&flags.hostnameRegex, "hostname-regex", "", "regex of agent name or host name")
+	create.Flags().StringSliceVar(&flags.ipCIDRs, "ip-cidrs", nil, "cidrs of agent ctrl ip")
+	create.Flags().StringSliceVar(&flags.podNodeLabels, "pod-node-labels", nil, "key:value, all of them should match")
+	create.Flags().IntSliceVar(&flags.agentTypes, "agent-types", nil, "agent types, such as 7 (K8S_BM), 8 (K8S_VM)")
+	create.Flags().BoolVar(&flags.dryRun, "dry-run", false, "only show agents which will be moved, the rule is not created")
+	create.MarkFlagRequired("group-lcuuid")
+	ruleDelete := &cobra.Command{
+		Use:     "delete <name>",
+		Short:   "delete agent group rule",
+		Example: "deepflow-ctl agent-group-rule delete prod-k8s",
+		Run: func(cmd *cobra.Command, args []string) {
+			deleteAgentGroupRule(cmd, args)
+		},
+	}
+	preview := &cobra.Command{
+		Use:     "preview",
+		Short:   "show agents which will be moved by the current rules",
+		Example: "deepflow-ctl agent-group-rule preview",
+		Run: func(cmd *cobra.Command, args []string) {
+			server := common.GetServerInfo(cmd)
+			url := fmt.Sprintf("http://%s:%d/v1/vtap-group-rules/preview/", server.IP, server.Port)
+			response, err := common.CURLPerform("GET", url, nil, "")
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return
+			}
+			printAgentGroupMoves(response.Get("DATA"))
+		},
+	}
+
+	rule.AddCommand(list)
+	rule.AddCommand(create)
+	rule.AddCommand(ruleDelete)
+	rule.AddCommand(preview)
+	return rule
+}
+
+func listAgentGroupRule(cmd *cobra.Command) {
+	server := common.GetServerInfo(cmd)
+	url := fmt.Sprintf("http://%s:%d/v1/vtap-group-rules/", server.IP, server.Port)
+	response, err := common.CURLPerform("GET", url, nil, "")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return
+	}
+
+	data := response.Get("DATA")
+	var (
+		nameMaxSize  = jsonparser.GetTheMaxSizeOfAttr(data, "NAME")
+		groupMaxSize = jsonparser.GetTheMaxSizeOfAttr(data, "VTAP_GROUP_NAME")
+	)
+	cmdFormat := "%-*s %-8s %-7s %-*s %s\n"
+	fmt.Printf(cmdFormat, nameMaxSize, "NAME", "PRIORITY", "ENABLED", groupMaxSize, "VTAP_GROUP_NAME", "CONDITIONS")
+	for i := range data.MustArray() {
+		d := data.GetIndex(i)
+		conditions := []string{}
+		for _, key := range []string{"KUBERNETES_CLUSTER_IDS", "REGIONS", "AZS", "IP_CIDRS", "POD_NODE_LABELS"} {
+			if values := d.Get(key).MustStringArray(); len(values) > 0 {
+				conditions = append(conditions, fmt.Sprintf("%s=%s", key, strings.Join(values, ",")))
+			}
+		}
+		if regex := d.Get("HOSTNAME_REGEX").MustString(); regex != "" {
+			conditions = append(conditions, fmt.Sprintf("HOSTNAME_REGEX=%s", regex))
+		}
+		if types := d.Get("VTAP_TYPES").MustArray(); len(types) > 0 {
+			conditions = append(conditions, fmt.Sprintf("VTAP_TYPES=%v", types))
+		}
+		fmt.Printf(cmdFormat,
+			nameMaxSize, d.Get("NAME").MustString(),
+			fmt.Sprint(d.Get("PRIORITY").MustInt()),
+			fmt.Sprint(d.Get("ENABLED").MustBool()),
+			groupMaxSize, d.Get("VTAP_GROUP_NAME").MustString(),
+			strings.Join(conditions, " "),
+		)
+	}
+}
+
+func createAgentGroupRule(cmd *cobra.Command, args []string, flags *agentGroupRuleFlags) {
+	if len(args) == 0 {
+		fmt.Fprintf(os.Stderr, "must specify name.\nExample: %s\n", cmd.Example)
+		return
+	}
+
+	server := common.GetServerInfo(cmd)
+	body := map[string]interface{}{
+		"NAME":                   args[0],
+		"PRIORITY":               flags.priority,
+		"ENABLED":                !flags.disabled,
+		"VTAP_GROUP_LCUUID":      flags.groupLcuuid,
+		"KUBERNETES_CLUSTER_IDS": flags.clusterIDs,
+		"REGIONS":                flags.regions,
+		"AZS":                    flags.azs,
+		"HOSTNAME_REGEX":         flags.hostnameRegex,
+		"IP_CIDRS":               flags.ipCIDRs,
+		"POD_NODE_LABELS":        flags.podNodeLabels,
+		"VTAP_TYPES":             flags.agentTypes,
+	}
+	if flags.dryRun {
+		url := fmt.Sprintf("http://%s:%d/v1/vtap-group-rules/preview/", server.IP, server.Port)
+		response, err := common.CURLPerform("POST", url, body, "")
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return
+		}
+		printAgentGroupMoves(response.Get("DATA"))
+		return
+	}
+	url := fmt.Sprintf("http://%s:%d/v1/vtap-group-rules/", server.IP, server.Port)
+	if _, err := common.CURLPerform("POST", url, body, ""); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+}
+
+func deleteAgentGroupRule(cmd *cobra.Command, args []string) {
+	if len(args) == 0 {
+		fmt.Fprintf(os.Stderr, "must specify name.\nExample: %s\n", cmd.Example)
+		return
+	}
+
+	server := common.GetServerInfo(cmd)
+	url := fmt.Sprintf("http://%s:%d/v1/vtap-group-rules/?name=%s", server.IP, server.Port, args[0])
+	response, err := common.CURLPerform("GET", url, nil, "")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return
+	}
+	if len(response.Get("DATA").MustArray()) == 0 {
+		fmt.Fprintf(os.Stderr, "agent group rule (%s) not found\n", args[0])
+		return
+	}
+	lcuuid := response.Get("DATA").GetIndex(0).Get("LCUUID").MustString()
+	url = fmt.Sprintf("http://%s:%d/v1/vtap-group-rules/%s/", server.IP, server.Port, lcuuid)
+	if _, err := common.CURLPerform("DELETE", url, nil, ""); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+}
+
+func printAgentGroupMoves(data *simplejson.Json) {
+	var (
+		nameMaxSize  = jsonparser.GetTheMaxSizeOfAttr(data, "VTAP_NAME")
+		fromMaxSize  = jsonparser.GetTheMaxSizeOfAttr(data, "FROM_VTAP_GROUP_NAME")
+		toMaxSize    = jsonparser.GetTheMaxSizeOfAttr(data, "TO_VTAP_GROUP_NAME")
+		cmdFormat    = "%-*s %-*s %-*s %s\n"
+		agentMoveLen = len(data.MustArray())
+	)
+	fmt.Printf(cmdFormat, nameMaxSize, "VTAP_NAME", fromMaxSize, "FROM_VTAP_GROUP_NAME", toMaxSize, "TO_VTAP_GROUP_NAME", "RULE_NAME")
+	for i := 0; i < agentMoveLen; i++ {
+		d := data.GetIndex(i)
+		fmt.Printf(cmdFormat,
+			nameMaxSize, d.Get("VTAP_NAME").MustString(),
+			fromMaxSize, d.Get("FROM_VTAP_GROUP_NAME").MustString(),
+			toMaxSize, d.Get("TO_VTAP_GROUP_NAME").MustString(),
+			d.Get("RULE_NAME").MustString(),
+		)
+	}
+	fmt.Printf("%d agent(s) will be moved\n", agentMoveLen)
+}
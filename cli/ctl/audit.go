@@ -0,0 +1,120 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ctl
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/deepflowio/deepflow/cli/ctl/common"
+	"github.com/deepflowio/deepflow/cli/ctl/common/jsonparser"
+	"github.com/spf13/cobra"
+)
+
+func RegisterAuditCommand() *cobra.Command {
+	audit := &cobra.Command{
+		Use:   "audit",
+		Short: "audit log operation commands",
+		Run: func(cmd *cobra.Command, args []string) {
+			fmt.Printf("please run with 'list'.\n")
+		},
+	}
+
+	var actor, resource, result string
+	var since time.Duration
+	var limit int
+	var detail bool
+	list := &cobra.Command{
+		Use:     "list",
+		Short:   "list audit logs of create, update and delete requests",
+		Example: "deepflow-ctl audit list --actor admin-key --since 24h\ndeepflow-ctl audit list --resource <lcuuid> --detail",
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := listAuditLog(cmd, actor, resource, result, since, limit, detail); err != nil {
+				fmt.Println(err)
+			}
+		},
+	}
+	list.Flags().StringVarP(&actor, "actor", "", "", "filter by api key name, jwt user or web user")
+	list.Flags().StringVarP(&resource, "resource", "", "", "filter by lcuuid or name of the changed resource")
+	list.Flags().StringVarP(&result, "result", "", "", "filter by result: SUCCESS | FAIL")
+	list.Flags().DurationVarP(&since, "since", "", 0, "only show logs in the duration, such as 1h, 24h")
+	list.Flags().IntVarP(&limit, "limit", "", 100, "max number of logs")
+	list.Flags().BoolVarP(&detail, "detail", "", false, "show request body and diff of each log")
+
+	audit.AddCommand(list)
+	return audit
+}
+
+func listAuditLog(cmd *cobra.Command, actor, resource, result string, since time.Duration, limit int, detail bool) error {
+	values := url.Values{}
+	if actor != "" {
+		values.Set("actor", actor)
+	}
+	if resource != "" {
+		values.Set("resource_lcuuid", resource)
+	}
+	if result != "" {
+		values.Set("result", result)
+	}
+	if since > 0 {
+		values.Set("start_time", time.Now().Add(-since).Format("2006-01-02 15:04:05"))
+	}
+	values.Set("limit", strconv.Itoa(limit))
+
+	server := common.GetServerInfo(cmd)
+	url := fmt.Sprintf("http://%s:%d/v1/audit-logs/?%s", server.IP, server.Port, values.Encode())
+	response, err := common.CURLPerform("GET", url, nil, "")
+	if err != nil {
+		return err
+	}
+
+	data := response.Get("DATA")
+	var (
+		actorMaxSize    = jsonparser.GetTheMaxSizeOfAttr(data, "ACTOR")
+		methodMaxSize   = jsonparser.GetTheMaxSizeOfAttr(data, "METHOD")
+		routeMaxSize    = jsonparser.GetTheMaxSizeOfAttr(data, "ROUTE")
+		resourceMaxSize = jsonparser.GetTheMaxSizeOfAttr(data, "RESOURCE_LCUUID")
+	)
+	cmdFormat := "%-19s %-*s %-*s %-*s %-*s %-7s %s\n"
+	fmt.Printf(cmdFormat, "CREATED_AT", actorMaxSize, "ACTOR", methodMaxSize, "METHOD", routeMaxSize, "ROUTE",
+		resourceMaxSize, "RESOURCE_LCUUID", "RESULT", "DESCRIPTION")
+	for i := range data.MustArray() {
+		d := data.GetIndex(i)
+		fmt.Printf(cmdFormat,
+			d.Get("CREATED_AT").MustString(),
+			actorMaxSize, d.Get("ACTOR").MustString(),
+			methodMaxSize, d.Get("METHOD").MustString(),
+			routeMaxSize, d.Get("ROUTE").MustString(),
+			resourceMaxSize, d.Get("RESOURCE_LCUUID").MustString(),
+			d.Get("RESULT").MustString(),
+			d.Get("DESCRIPTION").MustString(),
+		)
+		if !detail {
+			continue
+		}
+		for _, key := range []string{"REQUEST_BODY", "DIFF"} {
+			if value := d.Get(key).Interface(); value != nil {
+				b, _ := json.Marshal(value)
+				fmt.Printf("  %s: %s\n", key, b)
+			}
+		}
+	}
+	return nil
+}
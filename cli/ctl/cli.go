@@ -41,7 +41,10 @@ func Execute(version string) {
 	root.PersistentFlags().Uint32P("api-port", "", 30417, "deepflow-server service node port")
 	root.PersistentFlags().Uint32P("rpc-port", "", 30035, "deepflow-server service grpc port")
 	root.PersistentFlags().Uint32P("svc-port", "", 20417, "deepflow-server service http port")
+	root.PersistentFlags().StringP("api-key", "", os.Getenv("DEEPFLOW_API_KEY"), "deepflow-server api key, env DEEPFLOW_API_KEY is used by default")
 	root.ParseFlags(os.Args[1:])
+	apiKey, _ := root.PersistentFlags().GetString("api-key")
+	common.SetAPIKey(apiKey)
 
 	// support output version
 	if outputVersion {
@@ -53,6 +56,11 @@ func Execute(version string) {
 	root.AddCommand(RegisterAgentUpgradeCommand())
 	root.AddCommand(RegisterAgentGroupCommand())
 	root.AddCommand(RegisterAgentGroupConfigCommand())
+	root.AddCommand(RegisterAgentEnrollmentCommand())
+	root.AddCommand(RegisterAgentGroupRuleCommand())
+	root.AddCommand(RegisterACLCommand())
+	root.AddCommand(RegisterNpbCommand())
+	root.AddCommand(RegisterPolicyCommand())
 	root.AddCommand(RegisterDomainCommand())
 	root.AddCommand(RegisterSubDomainCommand())
 	root.AddCommand(RegisterGenesisCommand())
@@ -63,6 +71,7 @@ func Execute(version string) {
 	root.AddCommand(RegisterServerCommand())
 	root.AddCommand(RegisterRepoCommand())
 	root.AddCommand(RegisterPluginCommand())
+	root.AddCommand(RegisterAuditCommand())
 	root.AddCommand(RegisterPrometheusCacheCommand())
 	root.AddCommand(RegisterPromQLCommand())
 
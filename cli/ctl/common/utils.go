@@ -37,6 +37,22 @@ import (
 // Filter query string parameters
 type Filter map[string]interface{}
 
+var apiKey string
+
+// SetAPIKey sets the api key sent with every request to deepflow-server
+func SetAPIKey(key string) {
+	apiKey = key
+}
+
+func setCommonHeader(req *http.Request) {
+	req.Header.Set("Accept", "application/json, text/plain")
+	req.Header.Set("X-User-Id", "1")
+	req.Header.Set("X-User-Type", "1")
+	if apiKey != "" {
+		req.Header.Set("X-Api-Key", apiKey)
+	}
+}
+
 // 功能：调用其他模块API并获取返回结果
 func CURLPerform(method string, url string, body map[string]interface{}, strBody string) (*simplejson.Json, error) {
 	var err error
@@ -57,9 +73,7 @@ func CURLPerform(method string, url string, body map[string]interface{}, strBody
 		return nil, err
 	}
 	req.Header.Set("Content-Type", contentType)
-	req.Header.Set("Accept", "application/json, text/plain")
-	req.Header.Set("X-User-Id", "1")
-	req.Header.Set("X-User-Type", "1")
+	setCommonHeader(req)
 
 	return parseResponse(req)
 }
@@ -101,9 +115,7 @@ func CURLPostFormData(url, contentType string, body *bytes.Buffer) (*simplejson.
 		return nil, err
 	}
 	req.Header.Set("Content-Type", contentType)
-	req.Header.Set("Accept", "application/json, text/plain")
-	req.Header.Set("X-User-Id", "1")
-	req.Header.Set("X-User-Type", "1")
+	setCommonHeader(req)
 	req.Close = true
 
 	return parseResponse(req)
@@ -122,9 +134,7 @@ func CURLResponseRawJson(method string, url string) (*simplejson.Json, error) {
 	if err != nil {
 		return errResponse, err
 	}
-	req.Header.Set("Accept", "application/json, text/plain")
-	req.Header.Set("X-User-Id", "1")
-	req.Header.Set("X-User-Type", "1")
+	setCommonHeader(req)
 
 	resp, err := client.Do(req)
 	if err != nil {
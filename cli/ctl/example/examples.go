@@ -27,6 +27,9 @@ var YamlDomainAliYun []byte
 //go:embed domain_aws.yaml
 var YamlDomainAws []byte
 
+//go:embed domain_azure.yaml
+var YamlDomainAzure []byte
+
 //go:embed domain_baidubce.yaml
 var YamlDomainBaiduBce []byte
 
@@ -42,6 +45,9 @@ var YamlDomainHuawei []byte
 //go:embed domain_kubernetes.yaml
 var YamlDomainKubernetes []byte
 
+//go:embed domain_openstack.yaml
+var YamlDomainOpenStack []byte
+
 //go:embed domain_qingcloud.yaml
 var YamlDomainQingCloud []byte
 
@@ -51,5 +57,8 @@ var YamlDomainTencent []byte
 //go:embed sub_domain_create.yaml
 var YamlSubDomain []byte
 
+//go:embed domain_vsphere.yaml
+var YamlDomainVSphere []byte
+
 //go:embed vtap_update.yaml
 var YamlVtapUpdateConfig []byte
@@ -0,0 +1,384 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ctl
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/deepflowio/deepflow/cli/ctl/common"
+	"github.com/deepflowio/deepflow/cli/ctl/common/jsonparser"
+)
+
+type npbPolicyFlags struct {
+	aclName      string
+	tunnelName   string
+	vni          int
+	drop         bool
+	disabled     bool
+	payloadSlice int
+	agentIDs     []int
+}
+
+var npbTunnelTypes = map[string]int{"vxlan": 0, "erspan": 1}
+
+func RegisterNpbCommand() *cobra.Command {
+	npb := &cobra.Command{
+		Use:   "npb",
+		Short: "npb tunnels, npb policies and pcap policies",
+		Run: func(cmd *cobra.Command, args []string) {
+			fmt.Printf("please run with 'tunnel | policy | pcap-policy'.\n")
+		},
+	}
+
+	npb.AddCommand(registerNpbTunnelCommand())
+	npb.AddCommand(registerNpbPolicyCommand())
+	npb.AddCommand(registerPcapPolicyCommand())
+	return npb
+}
+
+func registerNpbTunnelCommand() *cobra.Command {
+	tunnel := &cobra.Command{
+		Use:   "tunnel",
+		Short: "targets which packets of npb policies are distributed to",
+		Run: func(cmd *cobra.Command, args []string) {
+			fmt.Printf("please run with 'list | create | delete'.\n")
+		},
+	}
+
+	list := &cobra.Command{
+		Use:     "list",
+		Short:   "list npb tunnels",
+		Example: "deepflow-ctl npb tunnel list",
+		Run: func(cmd *cobra.Command, args []string) {
+			listNpbTunnel(cmd)
+		},
+	}
+	var ip, tunnelType string
+	create := &cobra.Command{
+		Use:     "create <name>",
+		Short:   "create npb tunnel",
+		Example: "deepflow-ctl npb tunnel create probe-1 --ip 10.1.1.1 --type vxlan",
+		Run: func(cmd *cobra.Command, args []string) {
+			createNpbTunnel(cmd, args, ip, tunnelType)
+		},
+	}
+	create.Flags().StringVar(&ip, "ip", "", "ip of the tunnel endpoint")
+	create.Flags().StringVar(&tunnelType, "type", "vxlan", "vxlan or erspan")
+	create.MarkFlagRequired("ip")
+	tunnelDelete := &cobra.Command{
+		Use:     "delete <name>",
+		Short:   "delete npb tunnel",
+		Example: "deepflow-ctl npb tunnel delete probe-1",
+		Run: func(cmd *cobra.Command, args []string) {
+			deleteByName(cmd, args, "/v1/npb-tunnels/", "npb tunnel")
+		},
+	}
+
+	tunnel.AddCommand(list)
+	tunnel.AddCommand(create)
+	tunnel.AddCommand(tunnelDelete)
+	return tunnel
+}
+
+func registerNpbPolicyCommand() *cobra.Command {
+	policy := &cobra.Command{
+		Use:   "policy",
+		Short: "distribute packets matching an npb acl to an npb tunnel",
+		Run: func(cmd *cobra.Command, args []string) {
+			fmt.Printf("please run with 'list | create | delete'.\n")
+		},
+	}
+
+	list := &cobra.Command{
+		Use:     "list",
+		Short:   "list npb policies",
+		Example: "deepflow-ctl npb policy list",
+		Run: func(cmd *cobra.Command, args []string) {
+			listNpbPolicy(cmd)
+		},
+	}
+	var flags npbPolicyFlags
+	create := &cobra.Command{
+		Use:     "create <name>",
+		Short:   "create npb policy",
+		Example: "deepflow-ctl npb policy create web-to-probe --acl web --tunnel probe-1 --vni 100 --agent-ids 1,2",
+		Run: func(cmd *cobra.Command, args []string) {
+			createNpbPolicy(cmd, args, &flags)
+		},
+	}
+	create.Flags().StringVar(&flags.aclName, "acl", "", "name of the npb acl")
+	create.Flags().StringVar(&flags.tunnelName, "tunnel", "", "name of the npb tunnel")
+	create.Flags().IntVar(&flags.vni, "vni", 0, "vni of VXLAN tunnel or session id of ERSPAN tunnel")
+	create.Flags().BoolVar(&flags.drop, "drop", false, "drop the matched packets instead of distributing")
+	create.Flags().BoolVar(&flags.disabled, "disabled", false, "create the policy disabled")
+	create.Flags().IntVar(&flags.payloadSlice, "payload-slice", -1, "bytes of payload to distribute, -1 means the whole packet")
+	create.Flags().IntSliceVar(&flags.agentIDs, "agent-ids", nil, "ids of agents applying the policy, empty means all agents")
+	create.MarkFlagRequired("acl")
+	create.MarkFlagRequired("tunnel")
+	policyDelete := &cobra.Command{
+		Use:     "delete <name>",
+		Short:   "delete npb policy",
+		Example: "deepflow-ctl npb policy delete web-to-probe",
+		Run: func(cmd *cobra.Command, args []string) {
+			deleteByName(cmd, args, "/v1/npb-policies/", "npb policy")
+		},
+	}
+
+	policy.AddCommand(list)
+	policy.AddCommand(create)
+	policy.AddCommand(policyDelete)
+	return policy
+}
+
+func registerPcapPolicyCommand() *cobra.Command {
+	policy := &cobra.Command{
+		Use:   "pcap-policy",
+		Short: "store packets matching a pcap acl",
+		Run: func(cmd *cobra.Command, args []string) {
+			fmt.Printf("please run with 'list | create | delete'.\n")
+		},
+	}
+
+	list := &cobra.Command{
+		Use:     "list",
+		Short:   "list pcap policies",
+		Example: "deepflow-ctl npb pcap-policy list",
+		Run: func(cmd *cobra.Command, args []string) {
+			listPcapPolicy(cmd)
+		},
+	}
+	var flags npbPolicyFlags
+	create := &cobra.Command{
+		Use:     "create <name>",
+		Short:   "create pcap policy",
+		Example: "deepflow-ctl npb pcap-policy create dns --acl dns --payload-slice 128",
+		Run: func(cmd *cobra.Command, args []string) {
+			createPcapPolicy(cmd, args, &flags)
+		},
+	}
+	create.Flags().StringVar(&flags.aclName, "acl", "", "name of the pcap acl")
+	create.Flags().BoolVar(&flags.disabled, "disabled", false, "create the policy disabled")
+	create.Flags().IntVar(&flags.payloadSlice, "payload-slice", -1, "bytes of payload to store, -1 means the whole packet")
+	create.Flags().IntSliceVar(&flags.agentIDs, "agent-ids", nil, "ids of agents applying the policy, empty means all agents")
+	create.MarkFlagRequired("acl")
+	policyDelete := &cobra.Command{
+		Use:     "delete <name>",
+		Short:   "delete pcap policy",
+		Example: "deepflow-ctl npb pcap-policy delete dns",
+		Run: func(cmd *cobra.Command, args []string) {
+			deleteByName(cmd, args, "/v1/pcap-policies/", "pcap policy")
+		},
+	}
+
+	policy.AddCommand(list)
+	policy.AddCommand(create)
+	policy.AddCommand(policyDelete)
+	return policy
+}
+
+func listNpbTunnel(cmd *cobra.Command) {
+	server := common.GetServerInfo(cmd)
+	url := fmt.Sprintf("http://%s:%d/v1/npb-tunnels/", server.IP, server.Port)
+	response, err := common.CURLPerform("GET", url, nil, "")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return
+	}
+
+	data := response.Get("DATA")
+	var (
+		nameMaxSize = jsonparser.GetTheMaxSizeOfAttr(data, "NAME")
+		ipMaxSize   = jsonparser.GetTheMaxSizeOfAttr(data, "IP")
+	)
+	cmdFormat := "%-6s %-*s %-*s %s\n"
+	fmt.Printf(cmdFormat, "ID", nameMaxSize, "NAME", ipMaxSize, "IP", "TYPE")
+	for i := range data.MustArray() {
+		d := data.GetIndex(i)
+		tunnelType := "vxlan"
+		if d.Get("TYPE").MustInt() == npbTunnelTypes["erspan"] {
+			tunnelType = "erspan"
+		}
+		fmt.Printf(cmdFormat,
+			fmt.Sprint(d.Get("ID").MustInt()),
+			nameMaxSize, d.Get("NAME").MustString(),
+			ipMaxSize, d.Get("IP").MustString(),
+			tunnelType,
+		)
+	}
+}
+
+func createNpbTunnel(cmd *cobra.Command, args []string, ip, tunnelType string) {
+	if len(args) == 0 {
+		fmt.Fprintf(os.Stderr, "must specify name.\nExample: %s\n", cmd.Example)
+		return
+	}
+	t, ok := npbTunnelTypes[tunnelType]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "type (%s) invalid, should be vxlan or erspan\n", tunnelType)
+		return
+	}
+
+	server := common.GetServerInfo(cmd)
+	body := map[string]interface{}{
+		"NAME": args[0],
+		"IP":   ip,
+		"TYPE": t,
+	}
+	url := fmt.Sprintf("http://%s:%d/v1/npb-tunnels/", server.IP, server.Port)
+	if _, err := common.CURLPerform("POST", url, body, ""); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+}
+
+func listNpbPolicy(cmd *cobra.Command) {
+	server := common.GetServerInfo(cmd)
+	url := fmt.Sprintf("http://%s:%d/v1/npb-policies/", server.IP, server.Port)
+	response, err := common.CURLPerform("GET", url, nil, "")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return
+	}
+
+	data := response.Get("DATA")
+	var (
+		nameMaxSize   = jsonparser.GetTheMaxSizeOfAttr(data, "NAME")
+		aclMaxSize    = jsonparser.GetTheMaxSizeOfAttr(data, "ACL_NAME")
+		tunnelMaxSize = jsonparser.GetTheMaxSizeOfAttr(data, "NPB_TUNNEL_NAME")
+	)
+	cmdFormat := "%-*s %-5s %-*s %-*s %-8s %-10s %-7s %s\n"
+	fmt.Printf(cmdFormat, nameMaxSize, "NAME", "STATE", aclMaxSize, "ACL_NAME", tunnelMaxSize, "NPB_TUNNEL_NAME",
+		"VNI", "DISTRIBUTE", "ACL_GID", "VTAP_IDS")
+	for i := range data.MustArray() {
+		d := data.GetIndex(i)
+		fmt.Printf(cmdFormat,
+			nameMaxSize, d.Get("NAME").MustString(),
+			fmt.Sprint(d.Get("STATE").MustInt()),
+			aclMaxSize, d.Get("ACL_NAME").MustString(),
+			tunnelMaxSize, d.Get("NPB_TUNNEL_NAME").MustString(),
+			fmt.Sprint(d.Get("VNI").MustInt()),
+			fmt.Sprint(d.Get("DISTRIBUTE").MustInt()),
+			fmt.Sprint(d.Get("POLICY_ACL_GROUP_ID").MustInt()),
+			fmt.Sprint(d.Get("VTAP_IDS").MustArray()),
+		)
+	}
+}
+
+func createNpbPolicy(cmd *cobra.Command, args []string, flags *npbPolicyFlags) {
+	if len(args) == 0 {
+		fmt.Fprintf(os.Stderr, "must specify name.\nExample: %s\n", cmd.Example)
+		return
+	}
+
+	server := common.GetServerInfo(cmd)
+	body, err := getPolicyBody(server, args[0], flags)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return
+	}
+	tunnel, err := getByName(server, "/v1/npb-tunnels/", "npb tunnel", flags.tunnelName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return
+	}
+	distribute := 1
+	if flags.drop {
+		distribute = 0
+	}
+	body["NPB_TUNNEL_ID"] = tunnel.Get("ID").MustInt()
+	body["VNI"] = flags.vni
+	body["DISTRIBUTE"] = distribute
+	url := fmt.Sprintf("http://%s:%d/v1/npb-policies/", server.IP, server.Port)
+	if _, err := common.CURLPerform("POST", url, body, ""); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+}
+
+func listPcapPolicy(cmd *cobra.Command) {
+	server := common.GetServerInfo(cmd)
+	url := fmt.Sprintf("http://%s:%d/v1/pcap-policies/", server.IP, server.Port)
+	response, err := common.CURLPerform("GET", url, nil, "")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return
+	}
+
+	data := response.Get("DATA")
+	var (
+		nameMaxSize = jsonparser.GetTheMaxSizeOfAttr(data, "NAME")
+		aclMaxSize  = jsonparser.GetTheMaxSizeOfAttr(data, "ACL_NAME")
+	)
+	cmdFormat := "%-*s %-5s %-*s %-13s %-7s %s\n"
+	fmt.Printf(cmdFormat, nameMaxSize, "NAME", "STATE", aclMaxSize, "ACL_NAME", "PAYLOAD_SLICE", "ACL_GID", "VTAP_IDS")
+	for i := range data.MustArray() {
+		d := data.GetIndex(i)
+		payloadSlice := "all"
+		if slice, err := d.Get("PAYLOAD_SLICE").Int(); err == nil {
+			payloadSlice = fmt.Sprint(slice)
+		}
+		fmt.Printf(cmdFormat,
+			nameMaxSize, d.Get("NAME").MustString(),
+			fmt.Sprint(d.Get("STATE").MustInt()),
+			aclMaxSize, d.Get("ACL_NAME").MustString(),
+			payloadSlice,
+			fmt.Sprint(d.Get("POLICY_ACL_GROUP_ID").MustInt()),
+			fmt.Sprint(d.Get("VTAP_IDS").MustArray()),
+		)
+	}
+}
+
+func createPcapPolicy(cmd *cobra.Command, args []string, flags *npbPolicyFlags) {
+	if len(args) == 0 {
+		fmt.Fprintf(os.Stderr, "must specify name.\nExample: %s\n", cmd.Example)
+		return
+	}
+
+	server := common.GetServerInfo(cmd)
+	body, err := getPolicyBody(server, args[0], flags)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return
+	}
+	url := fmt.Sprintf("http://%s:%d/v1/pcap-policies/", server.IP, server.Port)
+	if _, err := common.CURLPerform("POST", url, body, ""); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+}
+
+// getPolicyBody returns the attributes shared by npb and pcap policies
+func getPolicyBody(server *common.Server, name string, flags *npbPolicyFlags) (map[string]interface{}, error) {
+	acl, err := getByName(server, "/v1/acls/", "acl", flags.aclName)
+	if err != nil {
+		return nil, err
+	}
+	state := 1
+	if flags.disabled {
+		state = 0
+	}
+	body := map[string]interface{}{
+		"NAME":     name,
+		"STATE":    state,
+		"ACL_ID":   acl.Get("ID").MustInt(),
+		"VTAP_IDS": flags.agentIDs,
+	}
+	if flags.payloadSlice >= 0 {
+		body["PAYLOAD_SLICE"] = flags.payloadSlice
+	}
+	return body, nil
+}
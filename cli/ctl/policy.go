@@ -0,0 +1,147 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ctl
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	simplejson "github.com/bitly/go-simplejson"
+	"github.com/spf13/cobra"
+
+	"github.com/deepflowio/deepflow/cli/ctl/common"
+)
+
+type policySimulateFlags struct {
+	srcIP    string
+	dstIP    string
+	srcPort  int
+	dstPort  int
+	protocol int
+	tapType  int
+	agentID  int
+	verbose  bool
+}
+
+func RegisterPolicyCommand() *cobra.Command {
+	policy := &cobra.Command{
+		Use:   "policy",
+		Short: "flow acl policy debug tools",
+		Run: func(cmd *cobra.Command, args []string) {
+			fmt.Printf("please run with 'simulate'.\n")
+		},
+	}
+
+	var flags policySimulateFlags
+	simulate := &cobra.Command{
+		Use:   "simulate",
+		Short: "simulate policy matching of a flow with current platform data, resource groups and acls",
+		Example: "deepflow-ctl policy simulate --src-ip 10.1.1.1 --dst-ip 10.2.2.2 --protocol 6 --dst-port 80\n" +
+			"deepflow-ctl policy simulate --src-ip 10.1.1.1 --dst-ip 10.2.2.2 --protocol 17 --dst-port 53 --agent-id 3 -v",
+		Run: func(cmd *cobra.Command, args []string) {
+			simulatePolicy(cmd, &flags)
+		},
+	}
+	simulate.Flags().StringVar(&flags.srcIP, "src-ip", "", "source ip of the flow")
+	simulate.Flags().StringVar(&flags.dstIP, "dst-ip", "", "destination ip of the flow")
+	simulate.Flags().IntVar(&flags.srcPort, "src-port", 0, "source port of the flow")
+	simulate.Flags().IntVar(&flags.dstPort, "dst-port", 0, "destination port of the flow")
+	simulate.Flags().IntVar(&flags.protocol, "protocol", 0, "ip protocol number, such as 6 (TCP), 17 (UDP)")
+	simulate.Flags().IntVar(&flags.tapType, "tap-type", 3, "tap type of the flow")
+	simulate.Flags().IntVar(&flags.agentID, "agent-id", 0, "simulate with the policy of this agent, 0 means the policy of analyzers")
+	simulate.Flags().BoolVarP(&flags.verbose, "verbose", "v", false, "show why each acl matches or not")
+	simulate.MarkFlagRequired("src-ip")
+	simulate.MarkFlagRequired("dst-ip")
+
+	policy.AddCommand(simulate)
+	return policy
+}
+
+func simulatePolicy(cmd *cobra.Command, flags *policySimulateFlags) {
+	server := common.GetServerInfo(cmd)
+	body := map[string]interface{}{
+		"VTAP_ID":  flags.agentID,
+		"SRC_IP":   flags.srcIP,
+		"DST_IP":   flags.dstIP,
+		"SRC_PORT": flags.srcPort,
+		"DST_PORT": flags.dstPort,
+		"PROTOCOL": flags.protocol,
+		"TAP_TYPE": flags.tapType,
+	}
+	url := fmt.Sprintf("http://%s:%d/v1/policy-simulation/", server.IP, server.Port)
+	response, err := common.CURLPerform("POST", url, body, "")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return
+	}
+
+	data := response.Get("DATA")
+	fmt.Printf("SOURCE: %s\n", data.Get("SOURCE").MustString())
+	printSimulateEndpoint("SRC", data.Get("SRC"))
+	printSimulateEndpoint("DST", data.Get("DST"))
+	fmt.Printf("ACL_ID: %d\n", data.Get("ACL_ID").MustInt())
+	fmt.Printf("ACTION_FLAGS: %s\n", data.Get("ACTION_FLAGS").MustString())
+	actions := data.Get("ACTIONS")
+	cmdFormat := "  %-9s %-16s %-10s %-9s %-8s %-13s %s\n"
+	fmt.Printf(cmdFormat, "TYPE", "TUNNEL_IP", "TUNNEL_ID", "IP_ID", "TAP_SIDE", "PAYLOAD_SLICE", "ACL_GIDS")
+	for i := range actions.MustArray() {
+		a := actions.GetIndex(i)
+		fmt.Printf(cmdFormat,
+			a.Get("TUNNEL_TYPE").MustString(),
+			a.Get("TUNNEL_IP").MustString(),
+			fmt.Sprint(a.Get("TUNNEL_ID").MustInt()),
+			fmt.Sprint(a.Get("TUNNEL_IP_ID").MustInt()),
+			a.Get("TAP_SIDE").MustString(),
+			fmt.Sprint(a.Get("PAYLOAD_SLICE").MustInt()),
+			fmt.Sprint(a.Get("ACL_GIDS").MustArray()),
+		)
+	}
+
+	fmt.Println("ACLS:")
+	acls := data.Get("ACLS")
+	for i := range acls.MustArray() {
+		a := acls.GetIndex(i)
+		matched := a.Get("MATCHED").MustBool()
+		if !matched && !flags.verbose {
+			continue
+		}
+		result := "not matched"
+		if matched {
+			result = fmt.Sprintf("matched (%s)", a.Get("DIRECTION").MustString())
+		}
+		fmt.Printf("  %d %s protocol %d: %s\n", a.Get("ID").MustInt(), a.Get("NAME").MustString(),
+			a.Get("PROTOCOL").MustInt(), result)
+		for _, reason := range a.Get("REASONS").MustStringArray() {
+			fmt.Printf("    - %s\n", reason)
+		}
+	}
+}
+
+func printSimulateEndpoint(name string, endpoint *simplejson.Json) {
+	groups := endpoint.Get("RESOURCE_GROUPS")
+	groupNames := make([]string, 0, len(groups.MustArray()))
+	for i := range groups.MustArray() {
+		g := groups.GetIndex(i)
+		groupNames = append(groupNames, fmt.Sprintf("%d(%s)", g.Get("ID").MustInt(), g.Get("NAME").MustString()))
+	}
+	fmt.Printf("%s: %s L3_EPC_ID=%d L2_EPC_ID=%d IS_DEVICE=%t IS_VIP=%t RESOURCE_GROUPS=[%s]\n",
+		name, endpoint.Get("IP").MustString(),
+		endpoint.Get("L3_EPC_ID").MustInt(), endpoint.Get("L2_EPC_ID").MustInt(),
+		endpoint.Get("IS_DEVICE").MustBool(), endpoint.Get("IS_VIP").MustBool(),
+		strings.Join(groupNames, ", "))
+}
@@ -0,0 +1,92 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package aws
+
+import (
+	"context"
+	"fmt"
+
+	awssdk "github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+const (
+	ROLE_SESSION_NAME = "deepflow-controller"
+)
+
+// 每个账号使用独立的凭证采集资源，id 在每次采集时通过 GetCallerIdentity 获取
+type awsAccount struct {
+	id         string
+	roleARN    string
+	credential awsconfig.LoadOptionsFunc
+}
+
+// 未配置访问密钥时使用默认凭证链，依次尝试环境变量、共享配置文件、IRSA 及实例配置文件
+func defaultCredential(o *awsconfig.LoadOptions) error {
+	return nil
+}
+
+// 使用 source 凭证扮演角色，获取的临时凭证在过期前自动刷新
+func (a *Aws) newAssumeRoleCredential(source awsconfig.LoadOptionsFunc, roleARN string) (awsconfig.LoadOptionsFunc, error) {
+	sourceConfig, err := awsconfig.LoadDefaultConfig(context.TODO(), source, awsconfig.WithRegion(a.apiDefaultRegion), awsconfig.WithHTTPClient(a.httpClient))
+	if err != nil {
+		return nil, err
+	}
+	provider := stscreds.NewAssumeRoleProvider(sts.NewFromConfig(sourceConfig), roleARN, func(o *stscreds.AssumeRoleOptions) {
+		o.RoleSessionName = ROLE_SESSION_NAME
+		if a.externalID != "" {
+			o.ExternalID = awssdk.String(a.externalID)
+		}
+	})
+	return awsconfig.WithCredentialsProvider(awssdk.NewCredentialsCache(provider)), nil
+}
+
+func (a *Aws) getAccountID(account awsAccount) (string, error) {
+	clientConfig, err := awsconfig.LoadDefaultConfig(context.TODO(), account.credential, awsconfig.WithRegion(a.apiDefaultRegion), awsconfig.WithHTTPClient(a.httpClient))
+	if err != nil {
+		return "", err
+	}
+	result, err := sts.NewFromConfig(clientConfig).GetCallerIdentity(context.TODO(), &sts.GetCallerIdentityInput{})
+	if err != nil {
+		if account.roleARN != "" {
+			return "", fmt.Errorf("assume role (%s) failed: %s", account.roleARN, err.Error())
+		}
+		return "", err
+	}
+	return a.getStringPointerValue(result.Account), nil
+}
+
+// 切换当前采集使用的账号
+func (a *Aws) switchAccount(account awsAccount) {
+	a.accountID = account.id
+	a.credential = account.credential
+}
+
+func (a *Aws) isMainAccount() bool {
+	return a.accountID == a.accounts[0].id
+}
+
+// 资源所属账号标签，通过 RAM 共享的资源（如子网）在使用方账号中也可查询到，此时以资源所有者账号为准
+func (a *Aws) getAccountCloudTags(ownerID *string) string {
+	accountID := a.getStringPointerValue(ownerID)
+	if accountID == "" {
+		accountID = a.accountID
+	}
+	return "account_id:" + accountID
+}
@@ -18,6 +18,8 @@ package aws
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"sort"
 	"strings"
 	"time"
@@ -42,21 +44,26 @@ const (
 )
 
 type Aws struct {
-	name                 string
-	lcuuid               string
-	regionUUID           string
-	uuidGenerate         string
-	apiDefaultRegion     string
-	includeRegions       []string
-	excludeRegions       []string
-	httpClient           *http.BuildableClient
-	azLcuuidMap          map[string]int
-	vpcOrSubnetToRouter  map[string]string
-	vmIDToPrivateIP      map[string]string
-	vpcIDToLcuuid        map[string]string
-	publicIPToVinterface map[string]model.VInterface
-	credential           awsconfig.LoadOptionsFunc
-	ec2Client            *ec2.Client
+	name                       string
+	lcuuid                     string
+	regionUUID                 string
+	uuidGenerate               string
+	apiDefaultRegion           string
+	includeRegions             []string
+	excludeRegions             []string
+	httpClient                 *http.BuildableClient
+	azLcuuidMap                map[string]int
+	vpcOrSubnetToRouter        map[string]string
+	vmIDToPrivateIP            map[string]string
+	vpcIDToLcuuid              map[string]string
+	publicIPToVinterface       map[string]model.VInterface
+	ipToNetworkInterface       map[string]types.NetworkInterface
+	networkInterfaceIDToExists map[string]bool
+	externalID                 string
+	accounts                   []awsAccount
+	accountID                  string                    // 当前采集的账号
+	credential                 awsconfig.LoadOptionsFunc // 当前采集账号的凭证
+	ec2Client                  *ec2.Client
 }
 
 type awsRegion struct {
@@ -77,22 +84,21 @@ func NewAws(domain mysql.Domain, cfg cloudconfig.CloudConfig) (*Aws, error) {
 		return nil, err
 	}
 
-	secretID, err := config.Get("secret_id").String()
-	if err != nil {
-		log.Error("secret_id must be specified")
-		return nil, err
-	}
-
-	secretKey, err := config.Get("secret_key").String()
-	if err != nil {
-		log.Error("secret_key must be specified")
+	secretID := config.Get("secret_id").MustString()
+	secretKey := config.Get("secret_key").MustString()
+	if (secretID == "") != (secretKey == "") {
+		err = errors.New("secret_id and secret_key must be specified together")
+		log.Error(err)
 		return nil, err
 	}
-
-	decryptSecretKey, err := common.DecryptSecretKey(secretKey)
-	if err != nil {
-		log.Error("decrypt secret_key failed (%s)", err.Error())
-		return nil, err
+	var baseCredential awsconfig.LoadOptionsFunc = defaultCredential
+	if secretID != "" {
+		decryptSecretKey, err := common.DecryptSecretKey(secretKey)
+		if err != nil {
+			log.Error("decrypt secret_key failed (%s)", err.Error())
+			return nil, err
+		}
+		baseCredential = awsconfig.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(secretID, decryptSecretKey, ""))
 	}
 
 	excludeRegionsStr := config.Get("exclude_regions").MustString()
@@ -111,7 +117,7 @@ func NewAws(domain mysql.Domain, cfg cloudconfig.CloudConfig) (*Aws, error) {
 
 	httpClient := http.NewBuildableClient().WithTimeout(time.Second * time.Duration(cfg.HTTPTimeout))
 
-	return &Aws{
+	aws := &Aws{
 		// TODO: display_name后期需要修改为uuid_generate
 		name:             domain.Name,
 		lcuuid:           domain.Lcuuid,
@@ -121,7 +127,7 @@ func NewAws(domain mysql.Domain, cfg cloudconfig.CloudConfig) (*Aws, error) {
 		httpClient:       httpClient,
 		apiDefaultRegion: cfg.AWSRegionName,
 		regionUUID:       config.Get("region_uuid").MustString(),
-		credential:       awsconfig.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(secretID, decryptSecretKey, "")),
+		externalID:       config.Get("external_id").MustString(),
 
 		// 以下属性为获取资源所用的关联关系
 		azLcuuidMap:          map[string]int{},
@@ -129,17 +135,53 @@ func NewAws(domain mysql.Domain, cfg cloudconfig.CloudConfig) (*Aws, error) {
 		vmIDToPrivateIP:      map[string]string{},
 		vpcIDToLcuuid:        map[string]string{},
 		publicIPToVinterface: map[string]model.VInterface{},
-	}, nil
+	}
+
+	// 主账号，配置 role_arn 时使用访问密钥或默认凭证链扮演该角色
+	mainAccount := awsAccount{credential: baseCredential}
+	if roleARN := config.Get("role_arn").MustString(); roleARN != "" {
+		mainAccount.roleARN = roleARN
+		mainAccount.credential, err = aws.newAssumeRoleCredential(baseCredential, roleARN)
+		if err != nil {
+			log.Errorf("assume role (%s) config failed (%s)", roleARN, err.Error())
+			return nil, err
+		}
+	}
+	aws.accounts = append(aws.accounts, mainAccount)
+
+	// 成员账号，使用主账号凭证扮演各成员账号中的角色，多个角色 ARN 之间以英文逗号分隔
+	for _, roleARN := range strings.Split(config.Get("member_role_arns").MustString(), ",") {
+		roleARN = strings.TrimSpace(roleARN)
+		if roleARN == "" {
+			continue
+		}
+		credential, err := aws.newAssumeRoleCredential(mainAccount.credential, roleARN)
+		if err != nil {
+			log.Errorf("assume role (%s) config failed (%s)", roleARN, err.Error())
+			return nil, err
+		}
+		aws.accounts = append(aws.accounts, awsAccount{roleARN: roleARN, credential: credential})
+	}
+	aws.switchAccount(mainAccount)
+	return aws, nil
 }
 
 func (a *Aws) CheckAuth() error {
-	awsClientConfig, err := awsconfig.LoadDefaultConfig(context.TODO(), a.credential, awsconfig.WithRegion(a.apiDefaultRegion), awsconfig.WithHTTPClient(a.httpClient))
-	if err != nil {
-		log.Error("client config failed (%s)", err.Error())
-		return err
+	for _, account := range a.accounts {
+		awsClientConfig, err := awsconfig.LoadDefaultConfig(context.TODO(), account.credential, awsconfig.WithRegion(a.apiDefaultRegion), awsconfig.WithHTTPClient(a.httpClient))
+		if err != nil {
+			log.Error("client config failed (%s)", err.Error())
+			return err
+		}
+		_, err = ec2.NewFromConfig(awsClientConfig).DescribeRegions(context.TODO(), &ec2.DescribeRegionsInput{})
+		if err != nil {
+			if account.roleARN != "" {
+				return fmt.Errorf("assume role (%s) failed: %s", account.roleARN, err.Error())
+			}
+			return err
+		}
 	}
-	_, err = ec2.NewFromConfig(awsClientConfig).DescribeRegions(context.TODO(), &ec2.DescribeRegionsInput{})
-	return err
+	return nil
 }
 
 func (a *Aws) getRegionLcuuid(lcuuid string) string {
@@ -192,9 +234,40 @@ func (a *Aws) ClearDebugLog() {}
 func (a *Aws) GetCloudData() (model.Resource, error) {
 	var resource model.Resource
 
+	for i := range a.accounts {
+		accountID, err := a.getAccountID(a.accounts[i])
+		if err != nil {
+			log.Errorf("get account id failed (%s)", err.Error())
+			return model.Resource{}, err
+		}
+		a.accounts[i].id = accountID
+		a.switchAccount(a.accounts[i])
+
+		log.Infof("account (%s) collect starting", accountID)
+		err = a.getAccountData(&resource)
+		if err != nil {
+			return model.Resource{}, err
+		}
+		log.Infof("account (%s) collect complete", accountID)
+	}
+	a.switchAccount(a.accounts[0])
+
+	// 同一区域及可用区可能存在于多个账号中；通过 RAM 共享的 VPC、子网及其接口，以及跨账号的对等连接会在每个相关账号中重复采集
+	resource.Regions = uniqueByLcuuid(resource.Regions, func(r model.Region) string { return r.Lcuuid })
+	resource.AZs = uniqueByLcuuid(resource.AZs, func(az model.AZ) string { return az.Lcuuid })
+	resource.VPCs = uniqueByLcuuid(resource.VPCs, func(v model.VPC) string { return v.Lcuuid })
+	resource.PeerConnections = uniqueByLcuuid(resource.PeerConnections, func(p model.PeerConnection) string { return p.Lcuuid })
+	resource.Networks = uniqueByLcuuid(resource.Networks, func(n model.Network) string { return n.Lcuuid })
+	resource.Subnets = uniqueByLcuuid(resource.Subnets, func(s model.Subnet) string { return s.Lcuuid })
+	resource.VInterfaces = uniqueByLcuuid(resource.VInterfaces, func(v model.VInterface) string { return v.Lcuuid })
+	resource.IPs = uniqueByLcuuid(resource.IPs, func(ip model.IP) string { return ip.Lcuuid })
+	return resource, nil
+}
+
+func (a *Aws) getAccountData(resource *model.Resource) error {
 	regionList, err := a.getRegions()
 	if err != nil {
-		return model.Resource{}, err
+		return err
 	}
 
 	for _, region := range regionList {
@@ -203,7 +276,7 @@ func (a *Aws) GetCloudData() (model.Resource, error) {
 		clientConfig, err := awsconfig.LoadDefaultConfig(context.TODO(), a.credential, awsconfig.WithRegion(region.name), awsconfig.WithHTTPClient(a.httpClient))
 		if err != nil {
 			log.Error("client config failed (%s)", err.Error())
-			return model.Resource{}, err
+			return err
 		}
 		a.ec2Client = ec2.NewFromConfig(clientConfig)
 
@@ -213,7 +286,7 @@ func (a *Aws) GetCloudData() (model.Resource, error) {
 
 		vpcs, err := a.getVPCs(region)
 		if err != nil {
-			return model.Resource{}, err
+			return err
 		}
 		if len(vpcs) > 0 {
 			regionFlag = true
@@ -222,7 +295,7 @@ func (a *Aws) GetCloudData() (model.Resource, error) {
 
 		peerConnections, err := a.getPeerConnections(region)
 		if err != nil {
-			return model.Resource{}, err
+			return err
 		}
 		if len(peerConnections) > 0 {
 			regionFlag = true
@@ -231,7 +304,7 @@ func (a *Aws) GetCloudData() (model.Resource, error) {
 
 		natGateways, natVinterfaces, natIPs, err := a.getNatGateways(region)
 		if err != nil {
-			return model.Resource{}, err
+			return err
 		}
 		if len(natGateways) > 0 || len(natVinterfaces) > 0 || len(natIPs) > 0 {
 			regionFlag = true
@@ -242,7 +315,7 @@ func (a *Aws) GetCloudData() (model.Resource, error) {
 
 		routers, routerTables, err := a.getRouterAndTables(region)
 		if err != nil {
-			return model.Resource{}, err
+			return err
 		}
 		if len(routers) > 0 || len(routerTables) > 0 {
 			regionFlag = true
@@ -252,7 +325,7 @@ func (a *Aws) GetCloudData() (model.Resource, error) {
 
 		networks, subnets, netVinterfaces, err := a.getNetworks(region)
 		if err != nil {
-			return model.Resource{}, err
+			return err
 		}
 		if len(networks) > 0 || len(netVinterfaces) > 0 {
 			regionFlag = true
@@ -263,7 +336,7 @@ func (a *Aws) GetCloudData() (model.Resource, error) {
 
 		sgs, sgRules, err := a.getSecurityGroups(region)
 		if err != nil {
-			return model.Resource{}, err
+			return err
 		}
 		if len(sgs) > 0 || len(sgRules) > 0 {
 			regionFlag = true
@@ -273,7 +346,7 @@ func (a *Aws) GetCloudData() (model.Resource, error) {
 
 		vms, vmSGs, err := a.getVMs(region)
 		if err != nil {
-			return model.Resource{}, err
+			return err
 		}
 		if len(vms) > 0 || len(vmSGs) > 0 {
 			regionFlag = true
@@ -283,7 +356,7 @@ func (a *Aws) GetCloudData() (model.Resource, error) {
 
 		vinterfaces, ips, vNatRules, err := a.getVInterfacesAndIPs(region)
 		if err != nil {
-			return model.Resource{}, err
+			return err
 		}
 		if len(vinterfaces) > 0 || len(ips) > 0 || len(vNatRules) > 0 {
 			regionFlag = true
@@ -292,9 +365,31 @@ func (a *Aws) GetCloudData() (model.Resource, error) {
 			resource.NATRules = append(resource.NATRules, vNatRules...)
 		}
 
+		rdsInstances, rdsVInterfaces, rdsIPs, err := a.getRDSInstances(region)
+		if err != nil {
+			return err
+		}
+		if len(rdsInstances) > 0 || len(rdsVInterfaces) > 0 || len(rdsIPs) > 0 {
+			regionFlag = true
+			resource.RDSInstances = append(resource.RDSInstances, rdsInstances...)
+			resource.VInterfaces = append(resource.VInterfaces, rdsVInterfaces...)
+			resource.IPs = append(resource.IPs, rdsIPs...)
+		}
+
+		redisInstances, redisVInterfaces, redisIPs, err := a.getRedisInstances(region)
+		if err != nil {
+			return err
+		}
+		if len(redisInstances) > 0 || len(redisVInterfaces) > 0 || len(redisIPs) > 0 {
+			regionFlag = true
+			resource.RedisInstances = append(resource.RedisInstances, redisInstances...)
+			resource.VInterfaces = append(resource.VInterfaces, redisVInterfaces...)
+			resource.IPs = append(resource.IPs, redisIPs...)
+		}
+
 		lbs, lbListeners, lbTargetServers, err := a.getLoadBalances(region)
 		if err != nil {
-			return model.Resource{}, err
+			return err
 		}
 		if len(lbs) > 0 || len(lbListeners) > 0 || len(lbTargetServers) > 0 {
 			regionFlag = true
@@ -305,7 +400,7 @@ func (a *Aws) GetCloudData() (model.Resource, error) {
 
 		fIPs, err := a.getFloatingIPs()
 		if err != nil {
-			return model.Resource{}, err
+			return err
 		}
 		if len(fIPs) > 0 {
 			regionFlag = true
@@ -315,7 +410,7 @@ func (a *Aws) GetCloudData() (model.Resource, error) {
 		// 附属容器集群
 		sDomains, err := a.getSubDomains(region)
 		if err != nil {
-			return resource, err
+			return err
 		}
 		if len(sDomains) > 0 {
 			regionFlag = true
@@ -331,7 +426,7 @@ func (a *Aws) GetCloudData() (model.Resource, error) {
 
 		azs, err := a.getAZs(region)
 		if err != nil {
-			return model.Resource{}, err
+			return err
 		}
 		if len(azs) > 0 {
 			resource.AZs = append(resource.AZs, azs...)
@@ -340,5 +435,19 @@ func (a *Aws) GetCloudData() (model.Resource, error) {
 		log.Infof("region (%s) collect complete", region.name)
 	}
 
-	return resource, nil
+	return nil
+}
+
+func uniqueByLcuuid[T any](items []T, getLcuuid func(T) string) []T {
+	var retItems []T
+	lcuuidToExists := map[string]bool{}
+	for _, item := range items {
+		lcuuid := getLcuuid(item)
+		if lcuuidToExists[lcuuid] {
+			continue
+		}
+		lcuuidToExists[lcuuid] = true
+		retItems = append(retItems, item)
+	}
+	return retItems
 }
@@ -0,0 +1,441 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/agiledragon/gomonkey/v2"
+	awssdk "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/eks"
+	"github.com/aws/aws-sdk-go-v2/service/elasticache"
+	"github.com/aws/aws-sdk-go-v2/service/elasticloadbalancing"
+	"github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2"
+	"github.com/aws/aws-sdk-go-v2/service/rds"
+	uuid "github.com/satori/go.uuid"
+	. "github.com/smartystreets/goconvey/convey"
+
+	cloudconfig "github.com/deepflowio/deepflow/server/controller/cloud/config"
+	"github.com/deepflowio/deepflow/server/controller/cloud/model"
+	"github.com/deepflowio/deepflow/server/controller/common"
+	"github.com/deepflowio/deepflow/server/controller/db/mysql"
+)
+
+const (
+	testMainAccountID   = "111111111111"
+	testMemberAccountID = "222222222222"
+	testServiceAccount  = "333333333333"
+)
+
+// 读取当前采集账号下录制的接口返回，文件不存在时返回空结果
+func loadTestOutput[T any](t *testing.T, a *Aws, name string) (*T, error) {
+	output := new(T)
+	content, err := os.ReadFile(filepath.Join("testfiles", a.accountID, name+".json"))
+	if os.IsNotExist(err) {
+		return output, nil
+	}
+	if err != nil {
+		t.Fatalf("read test file %s failed: %v", name, err)
+	}
+	if err = json.Unmarshal(content, output); err != nil {
+		t.Fatalf("unmarshal test file %s failed: %v", name, err)
+	}
+	return output, nil
+}
+
+// 账号 id 取自角色 ARN，未配置角色的主账号为 testMainAccountID；各服务接口按当前采集账号返回 testfiles 中的数据
+func patchTestAPIs(t *testing.T, a *Aws) *gomonkey.Patches {
+	patches := gomonkey.ApplyPrivateMethod(reflect.TypeOf(a), "getAccountID", func(_ *Aws, account awsAccount) (string, error) {
+		if account.roleARN == "" {
+			return testMainAccountID, nil
+		}
+		return strings.Split(account.roleARN, ":")[4], nil
+	})
+
+	ec2Client := reflect.TypeOf(&ec2.Client{})
+	patches.ApplyMethod(ec2Client, "DescribeRegions", func(_ *ec2.Client, _ context.Context, _ *ec2.DescribeRegionsInput, _ ...func(*ec2.Options)) (*ec2.DescribeRegionsOutput, error) {
+		return loadTestOutput[ec2.DescribeRegionsOutput](t, a, "ec2_DescribeRegions")
+	})
+	patches.ApplyMethod(ec2Client, "DescribeAvailabilityZones", func(_ *ec2.Client, _ context.Context, _ *ec2.DescribeAvailabilityZonesInput, _ ...func(*ec2.Options)) (*ec2.DescribeAvailabilityZonesOutput, error) {
+		return loadTestOutput[ec2.DescribeAvailabilityZonesOutput](t, a, "ec2_DescribeAvailabilityZones")
+	})
+	patches.ApplyMethod(ec2Client, "DescribeVpcs", func(_ *ec2.Client, _ context.Context, _ *ec2.DescribeVpcsInput, _ ...func(*ec2.Options)) (*ec2.DescribeVpcsOutput, error) {
+		return loadTestOutput[ec2.DescribeVpcsOutput](t, a, "ec2_DescribeVpcs")
+	})
+	patches.ApplyMethod(ec2Client, "DescribeVpcPeeringConnections", func(_ *ec2.Client, _ context.Context, _ *ec2.DescribeVpcPeeringConnectionsInput, _ ...func(*ec2.Options)) (*ec2.DescribeVpcPeeringConnectionsOutput, error) {
+		return loadTestOutput[ec2.DescribeVpcPeeringConnectionsOutput](t, a, "ec2_DescribeVpcPeeringConnections")
+	})
+	patches.ApplyMethod(ec2Client, "DescribeNatGateways", func(_ *ec2.Client, _ context.Context, _ *ec2.DescribeNatGatewaysInput, _ ...func(*ec2.Options)) (*ec2.DescribeNatGatewaysOutput, error) {
+		return loadTestOutput[ec2.DescribeNatGatewaysOutput](t, a, "ec2_DescribeNatGateways")
+	})
+	patches.ApplyMethod(ec2Client, "DescribeRouteTables", func(_ *ec2.Client, _ context.Context, _ *ec2.DescribeRouteTablesInput, _ ...func(*ec2.Options)) (*ec2.DescribeRouteTablesOutput, error) {
+		return loadTestOutput[ec2.DescribeRouteTablesOutput](t, a, "ec2_DescribeRouteTables")
+	})
+	patches.ApplyMethod(ec2Client, "DescribeSubnets", func(_ *ec2.Client, _ context.Context, _ *ec2.DescribeSubnetsInput, _ ...func(*ec2.Options)) (*ec2.DescribeSubnetsOutput, error) {
+		return loadTestOutput[ec2.DescribeSubnetsOutput](t, a, "ec2_DescribeSubnets")
+	})
+	patches.ApplyMethod(ec2Client, "DescribeSecurityGroups", func(_ *ec2.Client, _ context.Context, _ *ec2.DescribeSecurityGroupsInput, _ ...func(*ec2.Options)) (*ec2.DescribeSecurityGroupsOutput, error) {
+		return loadTestOutput[ec2.DescribeSecurityGroupsOutput](t, a, "ec2_DescribeSecurityGroups")
+	})
+	patches.ApplyMethod(ec2Client, "DescribeInstances", func(_ *ec2.Client, _ context.Context, _ *ec2.DescribeInstancesInput, _ ...func(*ec2.Options)) (*ec2.DescribeInstancesOutput, error) {
+		return loadTestOutput[ec2.DescribeInstancesOutput](t, a, "ec2_DescribeInstances")
+	})
+	patches.ApplyMethod(ec2Client, "DescribeNetworkInterfaces", func(_ *ec2.Client, _ context.Context, _ *ec2.DescribeNetworkInterfacesInput, _ ...func(*ec2.Options)) (*ec2.DescribeNetworkInterfacesOutput, error) {
+		return loadTestOutput[ec2.DescribeNetworkInterfacesOutput](t, a, "ec2_DescribeNetworkInterfaces")
+	})
+
+	rdsClient := reflect.TypeOf(&rds.Client{})
+	patches.ApplyMethod(rdsClient, "DescribeDBInstances", func(_ *rds.Client, _ context.Context, _ *rds.DescribeDBInstancesInput, _ ...func(*rds.Options)) (*rds.DescribeDBInstancesOutput, error) {
+		return loadTestOutput[rds.DescribeDBInstancesOutput](t, a, "rds_DescribeDBInstances")
+	})
+	patches.ApplyMethod(rdsClient, "DescribeDBClusters", func(_ *rds.Client, _ context.Context, _ *rds.DescribeDBClustersInput, _ ...func(*rds.Options)) (*rds.DescribeDBClustersOutput, error) {
+		return loadTestOutput[rds.DescribeDBClustersOutput](t, a, "rds_DescribeDBClusters")
+	})
+
+	cacheClient := reflect.TypeOf(&elasticache.Client{})
+	patches.ApplyMethod(cacheClient, "DescribeCacheSubnetGroups", func(_ *elasticache.Client, _ context.Context, _ *elasticache.DescribeCacheSubnetGroupsInput, _ ...func(*elasticache.Options)) (*elasticache.DescribeCacheSubnetGroupsOutput, error) {
+		return loadTestOutput[elasticache.DescribeCacheSubnetGroupsOutput](t, a, "elasticache_DescribeCacheSubnetGroups")
+	})
+	patches.ApplyMethod(cacheClient, "DescribeReplicationGroups", func(_ *elasticache.Client, _ context.Context, _ *elasticache.DescribeReplicationGroupsInput, _ ...func(*elasticache.Options)) (*elasticache.DescribeReplicationGroupsOutput, error) {
+		return loadTestOutput[elasticache.DescribeReplicationGroupsOutput](t, a, "elasticache_DescribeReplicationGroups")
+	})
+	patches.ApplyMethod(cacheClient, "DescribeCacheClusters", func(_ *elasticache.Client, _ context.Context, _ *elasticache.DescribeCacheClustersInput, _ ...func(*elasticache.Options)) (*elasticache.DescribeCacheClustersOutput, error) {
+		return loadTestOutput[elasticache.DescribeCacheClustersOutput](t, a, "elasticache_DescribeCacheClusters")
+	})
+
+	patches.ApplyMethod(reflect.TypeOf(&elasticloadbalancing.Client{}), "DescribeLoadBalancers", func(_ *elasticloadbalancing.Client, _ context.Context, _ *elasticloadbalancing.DescribeLoadBalancersInput, _ ...func(*elasticloadbalancing.Options)) (*elasticloadbalancing.DescribeLoadBalancersOutput, error) {
+		return loadTestOutput[elasticloadbalancing.DescribeLoadBalancersOutput](t, a, "elb_DescribeLoadBalancers")
+	})
+	elbv2Client := reflect.TypeOf(&elasticloadbalancingv2.Client{})
+	patches.ApplyMethod(elbv2Client, "DescribeLoadBalancers", func(_ *elasticloadbalancingv2.Client, _ context.Context, _ *elasticloadbalancingv2.DescribeLoadBalancersInput, _ ...func(*elasticloadbalancingv2.Options)) (*elasticloadbalancingv2.DescribeLoadBalancersOutput, error) {
+		return loadTestOutput[elasticloadbalancingv2.DescribeLoadBalancersOutput](t, a, "elbv2_DescribeLoadBalancers")
+	})
+	patches.ApplyMethod(elbv2Client, "DescribeListeners", func(_ *elasticloadbalancingv2.Client, _ context.Context, _ *elasticloadbalancingv2.DescribeListenersInput, _ ...func(*elasticloadbalancingv2.Options)) (*elasticloadbalancingv2.DescribeListenersOutput, error) {
+		return loadTestOutput[elasticloadbalancingv2.DescribeListenersOutput](t, a, "elbv2_DescribeListeners")
+	})
+
+	patches.ApplyMethod(reflect.TypeOf(&eks.Client{}), "ListClusters", func(_ *eks.Client, _ context.Context, _ *eks.ListClustersInput, _ ...func(*eks.Options)) (*eks.ListClustersOutput, error) {
+		return loadTestOutput[eks.ListClustersOutput](t, a, "eks_ListClusters")
+	})
+	return patches
+}
+
+func TestNewAwsAccounts(t *testing.T) {
+	cfg := cloudconfig.CloudConfig{AWSRegionName: REGION_NAME, HTTPTimeout: 30}
+
+	Convey("TestNewAwsAccounts", t, func() {
+		Convey("default credential chain with member accounts", func() {
+			aws, err := NewAws(mysql.Domain{Name: "aws", Config: `{
+				"role_arn": "arn:aws:iam::111111111111:role/deepflow",
+				"external_id": "deepflow-external-id",
+				"member_role_arns": "arn:aws:iam::222222222222:role/deepflow, arn:aws:iam::333333333333:role/deepflow,"
+			}`}, cfg)
+			So(err, ShouldBeNil)
+			So(len(aws.accounts), ShouldEqual, 3)
+			So(aws.accounts[0].roleARN, ShouldEqual, "arn:aws:iam::111111111111:role/deepflow")
+			So(aws.accounts[2].roleARN, ShouldEqual, "arn:aws:iam::333333333333:role/deepflow")
+			So(aws.externalID, ShouldEqual, "deepflow-external-id")
+			So(aws.credential, ShouldNotBeNil)
+		})
+
+		Convey("secret_id without secret_key", func() {
+			_, err := NewAws(mysql.Domain{Name: "aws", Config: `{"secret_id": "xxx"}`}, cfg)
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
+
+func TestGetCloudDataOfAccounts(t *testing.T) {
+	Convey("TestGetCloudDataOfAccounts", t, func() {
+		aws, err := NewAws(mysql.Domain{Name: "aws", Config: `{
+			"member_role_arns": "arn:aws-cn:iam::222222222222:role/deepflow"
+		}`}, cloudconfig.CloudConfig{AWSRegionName: REGION_NAME, HTTPTimeout: 5})
+		So(err, ShouldBeNil)
+		patches := patchTestAPIs(t, aws)
+		defer patches.Reset()
+
+		resource, err := aws.GetCloudData()
+		So(err, ShouldBeNil)
+		So(aws.accountID, ShouldEqual, testMainAccountID)
+
+		mainTags := "account_id:" + testMainAccountID
+		memberTags := "account_id:" + testMemberAccountID
+		Convey("resources are tagged with their account", func() {
+			vmTags := map[string]string{}
+			for _, vm := range resource.VMs {
+				vmTags[vm.Label] = vm.CloudTags
+			}
+			So(vmTags, ShouldResemble, map[string]string{"i-0a1b2c3d4e5f61111": mainTags, "i-0a1b2c3d4e5f62222": memberTags})
+
+			// 共享的 VPC 及子网属于所有者账号
+			vpcTags := map[string]string{}
+			for _, vpc := range resource.VPCs {
+				vpcTags[vpc.Label] = vpc.CloudTags
+			}
+			So(vpcTags, ShouldResemble, map[string]string{"vpc-0a1b2c3d4e5f61111": mainTags, "vpc-0a1b2c3d4e5f62222": memberTags})
+			networkTags := map[string]string{}
+			for _, network := range resource.Networks {
+				networkTags[network.Lcuuid] = network.CloudTags
+			}
+			So(networkTags, ShouldResemble, map[string]string{
+				common.GetUUID("subnet-0a1b2c3d4e5f61111", uuid.Nil): mainTags,
+				common.GetUUID("subnet-0a1b2c3d4e5f62222", uuid.Nil): memberTags,
+			})
+			for _, subnet := range resource.Subnets {
+				So(subnet.CloudTags, ShouldEqual, networkTags[subnet.NetworkLcuuid])
+			}
+
+			So(len(resource.NATGateways), ShouldEqual, 1)
+			So(resource.NATGateways[0].CloudTags, ShouldEqual, mainTags)
+			So(len(resource.LBs), ShouldEqual, 1)
+			So(resource.LBs[0].CloudTags, ShouldEqual, mainTags)
+			So(len(resource.RDSInstances), ShouldEqual, 1)
+			So(resource.RDSInstances[0].CloudTags, ShouldEqual, memberTags)
+		})
+
+		Convey("resources shared between accounts are deduplicated", func() {
+			So(len(resource.Regions), ShouldEqual, 1)
+			So(len(resource.AZs), ShouldEqual, 2)
+			So(len(resource.VPCs), ShouldEqual, 2)
+			So(len(resource.PeerConnections), ShouldEqual, 1)
+			So(len(resource.Networks), ShouldEqual, 2)
+			So(len(resource.Subnets), ShouldEqual, 2)
+			So(resource.VInterfaces, ShouldHaveLength, 4)
+			So(resource.IPs, ShouldHaveLength, 4)
+			lcuuidToExists := map[string]bool{}
+			for _, vif := range resource.VInterfaces {
+				So(lcuuidToExists[vif.Lcuuid], ShouldBeFalse)
+				lcuuidToExists[vif.Lcuuid] = true
+			}
+		})
+	})
+}
+
+func TestUniqueByLcuuid(t *testing.T) {
+	Convey("TestUniqueByLcuuid", t, func() {
+		vpcs := []model.VPC{{Lcuuid: "a", Name: "first"}, {Lcuuid: "b"}, {Lcuuid: "a", Name: "second"}}
+		vpcs = uniqueByLcuuid(vpcs, func(v model.VPC) string { return v.Lcuuid })
+		So(vpcs, ShouldResemble, []model.VPC{{Lcuuid: "a", Name: "first"}, {Lcuuid: "b"}})
+	})
+}
+
+// 使用 testServiceAccount 中的托管服务数据，访问地址均为 IP，解析时不依赖 DNS
+func newTestServiceAws(t *testing.T) (*Aws, awsRegion, *gomonkey.Patches) {
+	aws, err := NewAws(mysql.Domain{Name: "aws", Config: `{}`}, cloudconfig.CloudConfig{AWSRegionName: REGION_NAME, HTTPTimeout: 5})
+	if err != nil {
+		t.Fatalf("new aws failed: %v", err)
+	}
+	aws.switchAccount(awsAccount{id: testServiceAccount, credential: defaultCredential})
+	aws.ec2Client = ec2.NewFromConfig(awssdk.Config{Region: REGION_NAME})
+	aws.azLcuuidMap = map[string]int{}
+	return aws, awsRegion{name: REGION_NAME, lcuuid: common.GetUUID(REGION_NAME, uuid.Nil)}, patchTestAPIs(t, aws)
+}
+
+func vinterfacesOfDevice(vinterfaces []model.VInterface, deviceLcuuid string) []model.VInterface {
+	var retVInterfaces []model.VInterface
+	for _, vif := range vinterfaces {
+		if vif.DeviceLcuuid == deviceLcuuid {
+			retVInterfaces = append(retVInterfaces, vif)
+		}
+	}
+	return retVInterfaces
+}
+
+func ipsOfVInterfaces(ips []model.IP, vinterfaces []model.VInterface) []string {
+	vinterfaceLcuuids := map[string]bool{}
+	for _, vif := range vinterfaces {
+		vinterfaceLcuuids[vif.Lcuuid] = true
+	}
+	var retIPs []string
+	for _, ip := range ips {
+		if vinterfaceLcuuids[ip.VInterfaceLcuuid] {
+			retIPs = append(retIPs, ip.IP)
+		}
+	}
+	return retIPs
+}
+
+func TestGetVInterfacesAndIPs(t *testing.T) {
+	Convey("TestGetVInterfacesAndIPs", t, func() {
+		aws, region, patches := newTestServiceAws(t)
+		defer patches.Reset()
+
+		vinterfaces, ips, natRules, err := aws.getVInterfacesAndIPs(region)
+		So(err, ShouldBeNil)
+
+		// 仅挂载到云服务器的网卡生成接口，托管服务及未挂载的网卡只用于按 IP 查找
+		vmLcuuid := common.GetUUID("i-0c1d2e3f4a5b63333", uuid.Nil)
+		lanLcuuid := common.GetUUID("eni-0c1d2e3f4a5b60001", uuid.Nil)
+		networkLcuuid := common.GetUUID("subnet-0c1d2e3f4a5b63001", uuid.Nil)
+		So(vinterfaces, ShouldHaveLength, 2)
+		So(vinterfaces[0], ShouldResemble, model.VInterface{
+			Lcuuid:        lanLcuuid,
+			Type:          common.VIF_TYPE_LAN,
+			Mac:           "02:3c:4d:5e:00:01",
+			DeviceLcuuid:  vmLcuuid,
+			DeviceType:    common.VIF_DEVICE_TYPE_VM,
+			NetworkLcuuid: networkLcuuid,
+			VPCLcuuid:     common.GetUUID("vpc-0c1d2e3f4a5b63333", uuid.Nil),
+			RegionLcuuid:  region.lcuuid,
+		})
+		So(vinterfaces[1].Type, ShouldEqual, common.VIF_TYPE_WAN)
+		So(vinterfaces[1].Mac, ShouldEqual, "ff:3c:4d:5e:00:01")
+		So(vinterfaces[1].NetworkLcuuid, ShouldEqual, common.NETWORK_ISP_LCUUID)
+
+		So(ips, ShouldHaveLength, 2)
+		So(ips[0].IP, ShouldEqual, "10.3.1.10")
+		So(ips[0].VInterfaceLcuuid, ShouldEqual, lanLcuuid)
+		So(ips[0].SubnetLcuuid, ShouldEqual, common.GetUUID(networkLcuuid, uuid.Nil))
+		So(ips[1].IP, ShouldEqual, "52.80.3.10")
+		So(ips[1].VInterfaceLcuuid, ShouldEqual, vinterfaces[1].Lcuuid)
+
+		So(natRules, ShouldHaveLength, 1)
+		So(natRules[0].FloatingIP, ShouldEqual, "52.80.3.10")
+		So(natRules[0].FixedIP, ShouldEqual, "10.3.1.10")
+		So(aws.publicIPToVinterface, ShouldContainKey, "52.80.3.10")
+
+		So(aws.ipToNetworkInterface, ShouldContainKey, "10.3.1.99")
+		So(aws.ipToNetworkInterface, ShouldContainKey, "52.80.3.24")
+		So(aws.getStringPointerValue(aws.ipToNetworkInterface["10.3.2.41"].NetworkInterfaceId), ShouldEqual, "eni-0c1d2e3f4a5b60041")
+	})
+}
+
+func TestGetRDSInstances(t *testing.T) {
+	Convey("TestGetRDSInstances", t, func() {
+		aws, region, patches := newTestServiceAws(t)
+		defer patches.Reset()
+
+		_, _, _, err := aws.getVInterfacesAndIPs(region)
+		So(err, ShouldBeNil)
+		rdsInstances, vinterfaces, ips, err := aws.getRDSInstances(region)
+		So(err, ShouldBeNil)
+
+		// 不在 VPC 中的实例被忽略，无实例的 Serverless 集群作为一个实例同步
+		nameToRDS := map[string]model.RDSInstance{}
+		for _, instance := range rdsInstances {
+			nameToRDS[instance.Name] = instance
+		}
+		So(nameToRDS, ShouldHaveLength, 4)
+		So(nameToRDS, ShouldNotContainKey, "classic-mysql")
+		for _, instance := range rdsInstances {
+			So(instance.CloudTags, ShouldEqual, "account_id:"+testServiceAccount)
+			So(instance.VPCLcuuid, ShouldEqual, common.GetUUID("vpc-0c1d2e3f4a5b63333", uuid.Nil))
+			So(instance.RegionLcuuid, ShouldEqual, region.lcuuid)
+		}
+
+		writer := nameToRDS["aurora-1-writer"]
+		So(writer.Lcuuid, ShouldEqual, common.GetUUID("arn:aws-cn:rds:cn-north-1:333333333333:db:aurora-1-writer", uuid.Nil))
+		So(writer.Type, ShouldEqual, common.RDS_TYPE_MYSQL)
+		So(writer.Version, ShouldEqual, "aurora-mysql 8.0.mysql_aurora.3.04.0")
+		So(writer.Series, ShouldEqual, common.RDS_SERIES_HA)
+		So(writer.Model, ShouldEqual, common.RDS_MODEL_PRIMARY)
+		So(writer.State, ShouldEqual, common.RDS_STATE_RUNNING)
+		So(writer.AZLcuuid, ShouldEqual, common.GetUUID("cn-north-1a", uuid.Nil))
+		So(ipsOfVInterfaces(ips, vinterfacesOfDevice(vinterfaces, writer.Lcuuid)), ShouldResemble, []string{"10.3.1.21"})
+
+		reader := nameToRDS["aurora-1-reader"]
+		So(reader.Model, ShouldEqual, common.RDS_MODEL_READONLY)
+		So(reader.State, ShouldEqual, common.RDS_STATE_RUNNING)
+		readerVInterfaces := vinterfacesOfDevice(vinterfaces, reader.Lcuuid)
+		So(readerVInterfaces, ShouldHaveLength, 1)
+		So(readerVInterfaces[0].Lcuuid, ShouldEqual, common.GetUUID("eni-0c1d2e3f4a5b60022", uuid.Nil))
+		So(readerVInterfaces[0].DeviceType, ShouldEqual, common.VIF_DEVICE_TYPE_RDS_INSTANCE)
+		So(readerVInterfaces[0].NetworkLcuuid, ShouldEqual, common.GetUUID("subnet-0c1d2e3f4a5b63002", uuid.Nil))
+
+		// 公网访问地址通过网卡的公网 IP 关联，同时生成 WAN 接口
+		public := nameToRDS["pg-public"]
+		So(public.Type, ShouldEqual, common.RDS_TYPE_PSQL)
+		So(public.Series, ShouldEqual, common.RDS_SERIES_HA)
+		So(public.Model, ShouldEqual, common.RDS_MODEL_PRIMARY)
+		publicVInterfaces := vinterfacesOfDevice(vinterfaces, public.Lcuuid)
+		So(publicVInterfaces, ShouldHaveLength, 2)
+		So(publicVInterfaces[0].Type, ShouldEqual, common.VIF_TYPE_LAN)
+		So(publicVInterfaces[1].Type, ShouldEqual, common.VIF_TYPE_WAN)
+		So(publicVInterfaces[1].NetworkLcuuid, ShouldEqual, common.NETWORK_ISP_LCUUID)
+		So(ipsOfVInterfaces(ips, publicVInterfaces), ShouldResemble, []string{"10.3.1.24", "52.80.3.24"})
+
+		serverless := nameToRDS["serverless-1"]
+		So(serverless.Lcuuid, ShouldEqual, common.GetUUID("arn:aws-cn:rds:cn-north-1:333333333333:cluster:serverless-1", uuid.Nil))
+		So(serverless.Type, ShouldEqual, common.RDS_TYPE_PSQL)
+		So(serverless.Series, ShouldEqual, common.RDS_SERIES_HA)
+		So(serverless.AZLcuuid, ShouldEqual, common.GetUUID("cn-north-1a", uuid.Nil))
+		So(ipsOfVInterfaces(ips, vinterfacesOfDevice(vinterfaces, serverless.Lcuuid)), ShouldResemble, []string{"10.3.1.23"})
+
+		So(vinterfaces, ShouldHaveLength, 5)
+		So(ips, ShouldHaveLength, 5)
+	})
+}
+
+func TestGetRedisInstances(t *testing.T) {
+	Convey("TestGetRedisInstances", t, func() {
+		aws, region, patches := newTestServiceAws(t)
+		defer patches.Reset()
+
+		_, _, _, err := aws.getVInterfacesAndIPs(region)
+		So(err, ShouldBeNil)
+		redisInstances, vinterfaces, ips, err := aws.getRedisInstances(region)
+		So(err, ShouldBeNil)
+
+		// 没有缓存集群的复制组及不在 VPC 中的集群被忽略
+		So(redisInstances, ShouldHaveLength, 2)
+		for _, redis := range redisInstances {
+			So(redis.CloudTags, ShouldEqual, "account_id:"+testServiceAccount)
+			So(redis.VPCLcuuid, ShouldEqual, common.GetUUID("vpc-0c1d2e3f4a5b63333", uuid.Nil))
+		}
+
+		// 复制组中各节点的接口均关联到复制组
+		group := redisInstances[0]
+		So(group.Lcuuid, ShouldEqual, common.GetUUID("arn:aws-cn:elasticache:cn-north-1:333333333333:replicationgroup:redis-1", uuid.Nil))
+		So(group.Name, ShouldEqual, "redis-1")
+		So(group.Version, ShouldEqual, "Redis 7.0.7")
+		So(group.InternalHost, ShouldEqual, "redis-1.abcdef.ng.0001.cnn1.cache.amazonaws.com.cn")
+		So(group.AZLcuuid, ShouldEqual, common.GetUUID("cn-north-1a", uuid.Nil))
+		groupVInterfaces := vinterfacesOfDevice(vinterfaces, group.Lcuuid)
+		So(groupVInterfaces, ShouldHaveLength, 2)
+		for _, vif := range groupVInterfaces {
+			So(vif.DeviceType, ShouldEqual, common.VIF_DEVICE_TYPE_REDIS_INSTANCE)
+		}
+		So(ipsOfVInterfaces(ips, groupVInterfaces), ShouldResemble, []string{"10.3.1.31", "10.3.2.32"})
+
+		// 跨可用区的 Memcached 集群取第一个节点所在可用区
+		memcached := redisInstances[1]
+		So(memcached.Name, ShouldEqual, "memcached-1")
+		So(memcached.Version, ShouldEqual, "Memcached 1.6.17")
+		So(memcached.InternalHost, ShouldEqual, "memcached-1.abcdef.cfg.cnn1.cache.amazonaws.com.cn")
+		So(memcached.AZLcuuid, ShouldEqual, common.GetUUID("cn-north-1b", uuid.Nil))
+		So(ipsOfVInterfaces(ips, vinterfacesOfDevice(vinterfaces, memcached.Lcuuid)), ShouldResemble, []string{"10.3.2.41"})
+
+		So(vinterfaces, ShouldHaveLength, 3)
+		So(ips, ShouldHaveLength, 3)
+	})
+}
+
+func TestGetRDSType(t *testing.T) {
+	Convey("TestGetRDSType", t, func() {
+		So(getRDSType("aurora-mysql"), ShouldEqual, common.RDS_TYPE_MYSQL)
+		So(getRDSType("aurora-postgresql"), ShouldEqual, common.RDS_TYPE_PSQL)
+		So(getRDSType("sqlserver-se"), ShouldEqual, common.RDS_TYPE_SQL_SERVER)
+		So(getRDSType("oracle-ee"), ShouldEqual, common.RDS_UNKNOWN)
+	})
+}
@@ -0,0 +1,200 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package aws
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/elasticache"
+	"github.com/aws/aws-sdk-go-v2/service/elasticache/types"
+	"github.com/deepflowio/deepflow/server/controller/cloud/model"
+	"github.com/deepflowio/deepflow/server/controller/common"
+	uuid "github.com/satori/go.uuid"
+)
+
+// 需在 getVInterfacesAndIPs 之后调用
+// 复制组作为一个缓存实例，复制组中各节点的接口均关联到该实例；未加入复制组的集群（如 Memcached）单独作为一个实例
+func (a *Aws) getRedisInstances(region awsRegion) ([]model.RedisInstance, []model.VInterface, []model.IP, error) {
+	log.Debug("get redis_instances starting")
+	var redisInstances []model.RedisInstance
+	var vinterfaces []model.VInterface
+	var ips []model.IP
+
+	cacheClientConfig, _ := config.LoadDefaultConfig(context.TODO(), a.credential, config.WithRegion(region.name), config.WithHTTPClient(a.httpClient))
+	cacheClient := elasticache.NewFromConfig(cacheClientConfig)
+
+	subnetGroupNameToVPCID := map[string]string{}
+	var marker string
+	var maxRecords int32 = 100
+	for {
+		input := &elasticache.DescribeCacheSubnetGroupsInput{MaxRecords: &maxRecords}
+		if marker != "" {
+			input.Marker = &marker
+		}
+		result, err := cacheClient.DescribeCacheSubnetGroups(context.TODO(), input)
+		if err != nil {
+			log.Errorf("cache subnet group request aws api error: (%s)", err.Error())
+			return []model.RedisInstance{}, []model.VInterface{}, []model.IP{}, err
+		}
+		for _, sGroup := range result.CacheSubnetGroups {
+			subnetGroupNameToVPCID[a.getStringPointerValue(sGroup.CacheSubnetGroupName)] = a.getStringPointerValue(sGroup.VpcId)
+		}
+		if result.Marker == nil {
+			break
+		}
+		marker = *result.Marker
+	}
+
+	var retReplicationGroups []types.ReplicationGroup
+	marker = ""
+	for {
+		input := &elasticache.DescribeReplicationGroupsInput{MaxRecords: &maxRecords}
+		if marker != "" {
+			input.Marker = &marker
+		}
+		result, err := cacheClient.DescribeReplicationGroups(context.TODO(), input)
+		if err != nil {
+			log.Errorf("cache replication group request aws api error: (%s)", err.Error())
+			return []model.RedisInstance{}, []model.VInterface{}, []model.IP{}, err
+		}
+		retReplicationGroups = append(retReplicationGroups, result.ReplicationGroups...)
+		if result.Marker == nil {
+			break
+		}
+		marker = *result.Marker
+	}
+
+	var retCacheClusters []types.CacheCluster
+	marker = ""
+	showCacheNodeInfo := true
+	for {
+		input := &elasticache.DescribeCacheClustersInput{MaxRecords: &maxRecords, ShowCacheNodeInfo: &showCacheNodeInfo}
+		if marker != "" {
+			input.Marker = &marker
+		}
+		result, err := cacheClient.DescribeCacheClusters(context.TODO(), input)
+		if err != nil {
+			log.Errorf("cache cluster request aws api error: (%s)", err.Error())
+			return []model.RedisInstance{}, []model.VInterface{}, []model.IP{}, err
+		}
+		retCacheClusters = append(retCacheClusters, result.CacheClusters...)
+		if result.Marker == nil {
+			break
+		}
+		marker = *result.Marker
+	}
+
+	groupIDToClusters := map[string][]types.CacheCluster{}
+	for _, cluster := range retCacheClusters {
+		groupID := a.getStringPointerValue(cluster.ReplicationGroupId)
+		if groupID == "" {
+			continue
+		}
+		groupIDToClusters[groupID] = append(groupIDToClusters[groupID], cluster)
+	}
+
+	for _, rGroup := range retReplicationGroups {
+		groupID := a.getStringPointerValue(rGroup.ReplicationGroupId)
+		clusters := groupIDToClusters[groupID]
+		if len(clusters) == 0 {
+			log.Infof("replication group (%s) has no cache cluster", groupID)
+			continue
+		}
+		internalHost := ""
+		if rGroup.ConfigurationEndpoint != nil {
+			internalHost = a.getStringPointerValue(rGroup.ConfigurationEndpoint.Address)
+		} else if len(rGroup.NodeGroups) > 0 && rGroup.NodeGroups[0].PrimaryEndpoint != nil {
+			internalHost = a.getStringPointerValue(rGroup.NodeGroups[0].PrimaryEndpoint.Address)
+		}
+		redisInstance, redisVInterfaces, redisIPs, ok := a.formatRedisInstance(region, a.getStringPointerValue(rGroup.ARN), groupID, internalHost, clusters, subnetGroupNameToVPCID)
+		if !ok {
+			continue
+		}
+		redisInstances = append(redisInstances, redisInstance)
+		vinterfaces = append(vinterfaces, redisVInterfaces...)
+		ips = append(ips, redisIPs...)
+	}
+
+	for _, cluster := range retCacheClusters {
+		if cluster.ReplicationGroupId != nil {
+			continue
+		}
+		internalHost := ""
+		if cluster.ConfigurationEndpoint != nil {
+			internalHost = a.getStringPointerValue(cluster.ConfigurationEndpoint.Address)
+		} else if len(cluster.CacheNodes) > 0 && cluster.CacheNodes[0].Endpoint != nil {
+			internalHost = a.getStringPointerValue(cluster.CacheNodes[0].Endpoint.Address)
+		}
+		redisInstance, redisVInterfaces, redisIPs, ok := a.formatRedisInstance(region, a.getStringPointerValue(cluster.ARN), a.getStringPointerValue(cluster.CacheClusterId), internalHost, []types.CacheCluster{cluster}, subnetGroupNameToVPCID)
+		if !ok {
+			continue
+		}
+		redisInstances = append(redisInstances, redisInstance)
+		vinterfaces = append(vinterfaces, redisVInterfaces...)
+		ips = append(ips, redisIPs...)
+	}
+	log.Debug("get redis_instances complete")
+	return redisInstances, vinterfaces, ips, nil
+}
+
+func (a *Aws) formatRedisInstance(region awsRegion, arn, name, internalHost string, clusters []types.CacheCluster, subnetGroupNameToVPCID map[string]string) (model.RedisInstance, []model.VInterface, []model.IP, bool) {
+	var vinterfaces []model.VInterface
+	var ips []model.IP
+
+	firstCluster := clusters[0]
+	vpcID := subnetGroupNameToVPCID[a.getStringPointerValue(firstCluster.CacheSubnetGroupName)]
+	if vpcID == "" {
+		log.Infof("cache instance (%s) not in vpc", name)
+		return model.RedisInstance{}, nil, nil, false
+	}
+	redisLcuuid := common.GetUUID(arn, uuid.Nil)
+
+	for _, cluster := range clusters {
+		for _, node := range cluster.CacheNodes {
+			if node.Endpoint == nil {
+				continue
+			}
+			nodeVInterfaces, nodeIPs, _ := a.getManagedServiceVInterfacesAndIPs(region, a.getStringPointerValue(node.Endpoint.Address), redisLcuuid, common.VIF_DEVICE_TYPE_REDIS_INSTANCE)
+			vinterfaces = append(vinterfaces, nodeVInterfaces...)
+			ips = append(ips, nodeIPs...)
+		}
+	}
+
+	version := "Redis " + a.getStringPointerValue(firstCluster.EngineVersion)
+	if a.getStringPointerValue(firstCluster.Engine) == "memcached" {
+		version = "Memcached " + a.getStringPointerValue(firstCluster.EngineVersion)
+	}
+	// 跨可用区部署的 Memcached 集群可用区为 Multiple，取第一个节点所在可用区
+	azName := a.getStringPointerValue(firstCluster.PreferredAvailabilityZone)
+	if azName == "Multiple" && len(firstCluster.CacheNodes) > 0 {
+		azName = a.getStringPointerValue(firstCluster.CacheNodes[0].CustomerAvailabilityZone)
+	}
+	azLcuuid := common.GetUUID(azName, uuid.Nil)
+	a.azLcuuidMap[azLcuuid] = 0
+	return model.RedisInstance{
+		Lcuuid:       redisLcuuid,
+		Name:         name,
+		Label:        name,
+		CloudTags:    a.getAccountCloudTags(nil),
+		Version:      version,
+		InternalHost: internalHost,
+		VPCLcuuid:    common.GetUUID(vpcID, uuid.Nil),
+		AZLcuuid:     azLcuuid,
+		RegionLcuuid: a.getRegionLcuuid(region.lcuuid),
+	}, vinterfaces, ips, true
+}
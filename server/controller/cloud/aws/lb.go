@@ -72,6 +72,7 @@ func (a *Aws) getLoadBalances(region awsRegion) ([]model.LB, []model.LBListener,
 		lbs = append(lbs, model.LB{
 			Lcuuid:       lbLcuuid,
 			Name:         a.getStringPointerValue(lData.LoadBalancerName),
+			CloudTags:    a.getAccountCloudTags(nil),
 			Model:        lbModel,
 			VPCLcuuid:    vpcLcuuid,
 			RegionLcuuid: a.getRegionLcuuid(region.lcuuid),
@@ -156,6 +157,7 @@ func (a *Aws) getLoadBalances(region awsRegion) ([]model.LB, []model.LBListener,
 		lbs = append(lbs, model.LB{
 			Lcuuid:       v2LBLcuuid,
 			Name:         v2LBName,
+			CloudTags:    a.getAccountCloudTags(nil),
 			Model:        v2LBModel,
 			VPCLcuuid:    v2VPCLcuuid,
 			RegionLcuuid: a.getRegionLcuuid(region.lcuuid),
@@ -77,6 +77,7 @@ func (a *Aws) getNatGateways(region awsRegion) ([]model.NATGateway, []model.VInt
 			Lcuuid:       natGatewayLcuuid,
 			Name:         natGatewayName,
 			Label:        natGatewayID,
+			CloudTags:    a.getAccountCloudTags(nil),
 			FloatingIPs:  strings.Join(floatingIPs, ","),
 			VPCLcuuid:    vpcLcuuid,
 			RegionLcuuid: a.getRegionLcuuid(region.lcuuid),
@@ -67,6 +67,7 @@ func (a *Aws) getNetworks(region awsRegion) ([]model.Network, []model.Subnet, []
 		networks = append(networks, model.Network{
 			Lcuuid:         networkLcuuid,
 			Name:           networkName,
+			CloudTags:      a.getAccountCloudTags(nData.OwnerId),
 			SegmentationID: 1,
 			VPCLcuuid:      vpcLcuuid,
 			Shared:         false,
@@ -80,6 +81,7 @@ func (a *Aws) getNetworks(region awsRegion) ([]model.Network, []model.Subnet, []
 		subnets = append(subnets, model.Subnet{
 			Lcuuid:        common.GetUUID(networkLcuuid, uuid.Nil),
 			Name:          networkName,
+			CloudTags:     a.getAccountCloudTags(nData.OwnerId),
 			CIDR:          a.getStringPointerValue(nData.CidrBlock),
 			VPCLcuuid:     vpcLcuuid,
 			NetworkLcuuid: networkLcuuid,
@@ -0,0 +1,194 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package aws
+
+import (
+	"context"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/rds"
+	"github.com/aws/aws-sdk-go-v2/service/rds/types"
+	"github.com/deepflowio/deepflow/server/controller/cloud/model"
+	"github.com/deepflowio/deepflow/server/controller/common"
+	uuid "github.com/satori/go.uuid"
+)
+
+// 需在 getVInterfacesAndIPs 之后调用，RDS 实例的接口通过访问地址解析的 IP 关联网卡获取
+func (a *Aws) getRDSInstances(region awsRegion) ([]model.RDSInstance, []model.VInterface, []model.IP, error) {
+	log.Debug("get rds_instances starting")
+	var rdsInstances []model.RDSInstance
+	var vinterfaces []model.VInterface
+	var ips []model.IP
+
+	rdsClientConfig, _ := config.LoadDefaultConfig(context.TODO(), a.credential, config.WithRegion(region.name), config.WithHTTPClient(a.httpClient))
+	rdsClient := rds.NewFromConfig(rdsClientConfig)
+
+	var retInstances []types.DBInstance
+	var marker string
+	var maxRecords int32 = 100
+	for {
+		input := &rds.DescribeDBInstancesInput{MaxRecords: &maxRecords}
+		if marker != "" {
+			input.Marker = &marker
+		}
+		result, err := rdsClient.DescribeDBInstances(context.TODO(), input)
+		if err != nil {
+			log.Errorf("rds instance request aws api error: (%s)", err.Error())
+			return []model.RDSInstance{}, []model.VInterface{}, []model.IP{}, err
+		}
+		retInstances = append(retInstances, result.DBInstances...)
+		if result.Marker == nil {
+			break
+		}
+		marker = *result.Marker
+	}
+
+	// Aurora 集群中写实例为主实例，其余为只读实例
+	var retClusters []types.DBCluster
+	marker = ""
+	for {
+		input := &rds.DescribeDBClustersInput{MaxRecords: &maxRecords}
+		if marker != "" {
+			input.Marker = &marker
+		}
+		result, err := rdsClient.DescribeDBClusters(context.TODO(), input)
+		if err != nil {
+			log.Errorf("rds cluster request aws api error: (%s)", err.Error())
+			return []model.RDSInstance{}, []model.VInterface{}, []model.IP{}, err
+		}
+		retClusters = append(retClusters, result.DBClusters...)
+		if result.Marker == nil {
+			break
+		}
+		marker = *result.Marker
+	}
+	instanceIDToIsWriter := map[string]bool{}
+	for _, cluster := range retClusters {
+		for _, member := range cluster.DBClusterMembers {
+			instanceIDToIsWriter[a.getStringPointerValue(member.DBInstanceIdentifier)] = member.IsClusterWriter
+		}
+	}
+
+	for _, ins := range retInstances {
+		instanceID := a.getStringPointerValue(ins.DBInstanceIdentifier)
+		if ins.DBSubnetGroup == nil || ins.DBSubnetGroup.VpcId == nil {
+			log.Infof("rds instance (%s) not in vpc", instanceID)
+			continue
+		}
+		rdsLcuuid := common.GetUUID(a.getStringPointerValue(ins.DBInstanceArn), uuid.Nil)
+		engine := a.getStringPointerValue(ins.Engine)
+
+		rdsSeries := common.RDS_SERIES_BASIC
+		rdsModel := common.RDS_MODEL_PRIMARY
+		if ins.MultiAZ || ins.DBClusterIdentifier != nil {
+			rdsSeries = common.RDS_SERIES_HA
+		}
+		if isWriter, ok := instanceIDToIsWriter[instanceID]; (ok && !isWriter) || ins.ReadReplicaSourceDBInstanceIdentifier != nil {
+			rdsModel = common.RDS_MODEL_READONLY
+		}
+
+		azLcuuid := common.GetUUID(a.getStringPointerValue(ins.AvailabilityZone), uuid.Nil)
+		rdsInstances = append(rdsInstances, model.RDSInstance{
+			Lcuuid:       rdsLcuuid,
+			Name:         instanceID,
+			Label:        instanceID,
+			CloudTags:    a.getAccountCloudTags(nil),
+			State:        a.getRDSState(a.getStringPointerValue(ins.DBInstanceStatus)),
+			Type:         getRDSType(engine),
+			Version:      engine + " " + a.getStringPointerValue(ins.EngineVersion),
+			Series:       rdsSeries,
+			Model:        rdsModel,
+			VPCLcuuid:    common.GetUUID(*ins.DBSubnetGroup.VpcId, uuid.Nil),
+			AZLcuuid:     azLcuuid,
+			RegionLcuuid: a.getRegionLcuuid(region.lcuuid),
+		})
+		a.azLcuuidMap[azLcuuid] = 0
+
+		if ins.Endpoint != nil {
+			rdsVInterfaces, rdsIPs, _ := a.getManagedServiceVInterfacesAndIPs(region, a.getStringPointerValue(ins.Endpoint.Address), rdsLcuuid, common.VIF_DEVICE_TYPE_RDS_INSTANCE)
+			vinterfaces = append(vinterfaces, rdsVInterfaces...)
+			ips = append(ips, rdsIPs...)
+		}
+	}
+
+	// Aurora Serverless v1 集群没有实例，仅能通过集群访问地址访问，集群作为一个 RDS 实例同步
+	for _, cluster := range retClusters {
+		if len(cluster.DBClusterMembers) > 0 {
+			continue
+		}
+		clusterID := a.getStringPointerValue(cluster.DBClusterIdentifier)
+		rdsLcuuid := common.GetUUID(a.getStringPointerValue(cluster.DBClusterArn), uuid.Nil)
+		rdsVInterfaces, rdsIPs, vpcID := a.getManagedServiceVInterfacesAndIPs(region, a.getStringPointerValue(cluster.Endpoint), rdsLcuuid, common.VIF_DEVICE_TYPE_RDS_INSTANCE)
+		if vpcID == "" {
+			log.Infof("rds cluster (%s) vpc not found", clusterID)
+			continue
+		}
+		engine := a.getStringPointerValue(cluster.Engine)
+		azLcuuid := ""
+		if len(cluster.AvailabilityZones) > 0 {
+			azLcuuid = common.GetUUID(cluster.AvailabilityZones[0], uuid.Nil)
+			a.azLcuuidMap[azLcuuid] = 0
+		}
+		rdsInstances = append(rdsInstances, model.RDSInstance{
+			Lcuuid:       rdsLcuuid,
+			Name:         clusterID,
+			Label:        clusterID,
+			CloudTags:    a.getAccountCloudTags(nil),
+			State:        a.getRDSState(a.getStringPointerValue(cluster.Status)),
+			Type:         getRDSType(engine),
+			Version:      engine + " " + a.getStringPointerValue(cluster.EngineVersion),
+			Series:       common.RDS_SERIES_HA,
+			Model:        common.RDS_MODEL_PRIMARY,
+			VPCLcuuid:    common.GetUUID(vpcID, uuid.Nil),
+			AZLcuuid:     azLcuuid,
+			RegionLcuuid: a.getRegionLcuuid(region.lcuuid),
+		})
+		vinterfaces = append(vinterfaces, rdsVInterfaces...)
+		ips = append(ips, rdsIPs...)
+	}
+	log.Debug("get rds_instances complete")
+	return rdsInstances, vinterfaces, ips, nil
+}
+
+// 备份、修改配置等过程中实例仍可访问
+func (a *Aws) getRDSState(status string) int {
+	switch status {
+	case "available", "backing-up", "modifying", "storage-optimization", "configuring-enhanced-monitoring", "maintenance":
+		return common.RDS_STATE_RUNNING
+	case "restore-error", "inaccessible-encryption-credentials-recoverable":
+		return common.RDS_STATE_RESTORING
+	default:
+		return common.RDS_UNKNOWN
+	}
+}
+
+// Aurora 引擎与对应的开源数据库兼容
+func getRDSType(engine string) int {
+	switch {
+	case engine == "mysql" || strings.HasPrefix(engine, "aurora-mysql") || engine == "aurora":
+		return common.RDS_TYPE_MYSQL
+	case engine == "postgres" || strings.HasPrefix(engine, "aurora-postgresql"):
+		return common.RDS_TYPE_PSQL
+	case engine == "mariadb":
+		return common.RDS_TYPE_MARIADB
+	case strings.HasPrefix(engine, "sqlserver"):
+		return common.RDS_TYPE_SQL_SERVER
+	default:
+		return common.RDS_UNKNOWN
+	}
+}
@@ -80,8 +80,13 @@ func (a *Aws) getSubDomains(region awsRegion) ([]model.SubDomain, error) {
 			"pod_net_ipv6_cidr_max_mask": common.K8S_POD_IPV6_NETMASK,
 		}
 		configJson, _ := json.Marshal(config)
+		// 不同账号中的集群可能同名
+		lcuuid := common.GetUUID(name, uuid.Nil)
+		if !a.isMainAccount() {
+			lcuuid = common.GetUUID(a.accountID+"_"+name, uuid.Nil)
+		}
 		retSubDomains = append(retSubDomains, model.SubDomain{
-			Lcuuid:      common.GetUUID(name, uuid.Nil),
+			Lcuuid:      lcuuid,
 			Name:        name,
 			DisplayName: name,
 			ClusterID:   name,
@@ -18,6 +18,7 @@ package aws
 
 import (
 	"context"
+	"net"
 
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
@@ -30,6 +31,8 @@ import (
 func (a *Aws) getVInterfacesAndIPs(region awsRegion) ([]model.VInterface, []model.IP, []model.NATRule, error) {
 	log.Debug("get vinterfaces,ips starting")
 	a.publicIPToVinterface = map[string]model.VInterface{}
+	a.ipToNetworkInterface = map[string]types.NetworkInterface{}
+	a.networkInterfaceIDToExists = map[string]bool{}
 	var vinterfaces []model.VInterface
 	var ips []model.IP
 	var vNatRules []model.NATRule
@@ -57,6 +60,14 @@ func (a *Aws) getVInterfacesAndIPs(region awsRegion) ([]model.VInterface, []mode
 	}
 
 	for _, vData := range retVinterfaces {
+		// 托管服务（RDS、ElastiCache 等）的网卡不关联云服务器，通过 IP 查找
+		for _, ip := range vData.PrivateIpAddresses {
+			a.ipToNetworkInterface[a.getStringPointerValue(ip.PrivateIpAddress)] = vData
+		}
+		if vData.Association != nil && vData.Association.PublicIp != nil {
+			a.ipToNetworkInterface[*vData.Association.PublicIp] = vData
+		}
+
 		mac := a.getStringPointerValue(vData.MacAddress)
 		if vData.Attachment == nil {
 			log.Debugf("vinterface (%s) not binding device", mac)
@@ -136,3 +147,96 @@ func (a *Aws) getVInterfacesAndIPs(region awsRegion) ([]model.VInterface, []mode
 	log.Debug("get vinterfaces,ips complete")
 	return vinterfaces, ips, vNatRules, nil
 }
+
+// 解析托管服务的访问地址，根据解析到的 IP 查找对应网卡，生成设备的接口及 IP
+// 访问地址开启公网访问时在 VPC 外解析为公网 IP，同样可以查找到网卡
+func (a *Aws) getManagedServiceVInterfacesAndIPs(region awsRegion, host, deviceLcuuid string, deviceType int) ([]model.VInterface, []model.IP, string) {
+	var vinterfaces []model.VInterface
+	var ips []model.IP
+	var vpcID string
+
+	for _, hostIP := range a.lookupHostIPs(host) {
+		vData, ok := a.ipToNetworkInterface[hostIP]
+		if !ok {
+			log.Debugf("host (%s) ip (%s) network interface not found", host, hostIP)
+			continue
+		}
+		vpcID = a.getStringPointerValue(vData.VpcId)
+		networkInterfaceID := a.getStringPointerValue(vData.NetworkInterfaceId)
+		if a.networkInterfaceIDToExists[networkInterfaceID] {
+			continue
+		}
+		a.networkInterfaceIDToExists[networkInterfaceID] = true
+
+		mac := a.getStringPointerValue(vData.MacAddress)
+		vinterfaceLcuuid := common.GetUUID(networkInterfaceID, uuid.Nil)
+		networkLcuuid := common.GetUUID(a.getStringPointerValue(vData.SubnetId), uuid.Nil)
+		vpcLcuuid := common.GetUUID(vpcID, uuid.Nil)
+		vinterfaces = append(vinterfaces, model.VInterface{
+			Lcuuid:        vinterfaceLcuuid,
+			Type:          common.VIF_TYPE_LAN,
+			Mac:           mac,
+			DeviceLcuuid:  deviceLcuuid,
+			DeviceType:    deviceType,
+			NetworkLcuuid: networkLcuuid,
+			VPCLcuuid:     vpcLcuuid,
+			RegionLcuuid:  a.getRegionLcuuid(region.lcuuid),
+		})
+		for _, ip := range vData.PrivateIpAddresses {
+			privateIP := a.getStringPointerValue(ip.PrivateIpAddress)
+			netPrivateIP, err := netaddr.ParseIP(privateIP)
+			if err != nil || !netPrivateIP.Is4() {
+				log.Infof("ip (%s) not support", privateIP)
+				continue
+			}
+			ips = append(ips, model.IP{
+				Lcuuid:           common.GetUUID(vinterfaceLcuuid+privateIP, uuid.Nil),
+				VInterfaceLcuuid: vinterfaceLcuuid,
+				IP:               privateIP,
+				SubnetLcuuid:     common.GetUUID(networkLcuuid, uuid.Nil),
+				RegionLcuuid:     a.getRegionLcuuid(region.lcuuid),
+			})
+		}
+
+		if vData.Association == nil || len(mac) < 2 {
+			continue
+		}
+		publicIP := a.getStringPointerValue(vData.Association.PublicIp)
+		netPublicIP, err := netaddr.ParseIP(publicIP)
+		if err != nil || !netPublicIP.Is4() {
+			continue
+		}
+		wanVInterfaceLcuuid := common.GetUUID(vinterfaceLcuuid, uuid.Nil)
+		vinterfaces = append(vinterfaces, model.VInterface{
+			Lcuuid:        wanVInterfaceLcuuid,
+			Type:          common.VIF_TYPE_WAN,
+			Mac:           "ff" + mac[2:],
+			DeviceLcuuid:  deviceLcuuid,
+			DeviceType:    deviceType,
+			NetworkLcuuid: common.NETWORK_ISP_LCUUID,
+			VPCLcuuid:     vpcLcuuid,
+			RegionLcuuid:  a.getRegionLcuuid(region.lcuuid),
+		})
+		ips = append(ips, model.IP{
+			Lcuuid:           common.GetUUID(vinterfaceLcuuid+publicIP, uuid.Nil),
+			VInterfaceLcuuid: wanVInterfaceLcuuid,
+			IP:               publicIP,
+			RegionLcuuid:     a.getRegionLcuuid(region.lcuuid),
+		})
+	}
+	return vinterfaces, ips, vpcID
+}
+
+func (a *Aws) lookupHostIPs(host string) []string {
+	if host == "" {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), a.httpClient.GetTimeout())
+	defer cancel()
+	addrs, err := net.DefaultResolver.LookupHost(ctx, host)
+	if err != nil {
+		log.Infof("lookup host (%s) failed (%s)", host, err.Error())
+		return nil
+	}
+	return addrs
+}
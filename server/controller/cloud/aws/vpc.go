@@ -64,6 +64,7 @@ func (a *Aws) getVPCs(region awsRegion) ([]model.VPC, error) {
 			Name:         vpcName,
 			CIDR:         a.getStringPointerValue(vData.CidrBlock),
 			Label:        vpcID,
+			CloudTags:    a.getAccountCloudTags(vData.OwnerId),
 			RegionLcuuid: a.getRegionLcuuid(region.lcuuid),
 		})
 		a.vpcIDToLcuuid[vpcID] = vpcLcuuid
@@ -0,0 +1,298 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package azure
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bitly/go-simplejson"
+	"github.com/op/go-logging"
+
+	cloudcommon "github.com/deepflowio/deepflow/server/controller/cloud/common"
+	"github.com/deepflowio/deepflow/server/controller/cloud/config"
+	"github.com/deepflowio/deepflow/server/controller/cloud/model"
+	"github.com/deepflowio/deepflow/server/controller/common"
+	"github.com/deepflowio/deepflow/server/controller/db/mysql"
+	"github.com/deepflowio/deepflow/server/controller/statsd"
+)
+
+var log = logging.MustGetLogger("cloud.azure")
+
+const (
+	API_VERSION_RESOURCE     = "2021-04-01"
+	API_VERSION_SUBSCRIPTION = "2020-01-01"
+	API_VERSION_NETWORK      = "2023-04-01"
+	API_VERSION_COMPUTE      = "2023-03-01"
+	// 规模集实例网卡仅支持该版本
+	API_VERSION_VMSS_NETWORK = "2018-10-01"
+	API_VERSION_AKS          = "2023-05-01"
+)
+
+type Azure struct {
+	lcuuid         string
+	lcuuidGenerate string
+	name           string
+	httpTimeout    int
+	config         *Config
+	token          *Token             // 缓存访问令牌，快过期时重新申请
+	toolDataSet    *ToolDataSet       // 处理资源数据时，构建的需要提供给其他资源使用的工具数据
+	cloudStatsd    statsd.CloudStatsd // 性能监控
+	debugger       *cloudcommon.Debugger
+}
+
+func NewAzure(domain mysql.Domain, globalCloudCfg config.CloudConfig) (*Azure, error) {
+	conf := &Config{}
+	err := conf.LoadFromString(domain.Config)
+	if err != nil {
+		return nil, err
+	}
+	return &Azure{
+		lcuuid: domain.Lcuuid,
+		// TODO: display_name后期需要修改为uuid_generate
+		lcuuidGenerate: domain.DisplayName,
+		name:           domain.Name,
+		httpTimeout:    globalCloudCfg.HTTPTimeout,
+		config:         conf,
+		debugger:       cloudcommon.NewDebugger(domain.Name),
+	}, nil
+}
+
+func (a *Azure) ClearDebugLog() {
+	a.debugger.Clear()
+}
+
+func (a *Azure) CheckAuth() error {
+	_, err := a.createToken()
+	return err
+}
+
+func (a *Azure) GetCloudData() (model.Resource, error) {
+	a.cloudStatsd = statsd.NewCloudStatsd()
+	a.toolDataSet = NewToolDataSet()
+	var resource model.Resource
+
+	subscriptions, err := a.getSubscriptions()
+	if err != nil {
+		return resource, err
+	}
+
+	for _, subscription := range subscriptions {
+		log.Infof("get subscription (%s) resources", subscription.name)
+		if err = a.getLocations(subscription); err != nil {
+			return resource, err
+		}
+		if err = a.getResourceGroups(subscription); err != nil {
+			return resource, err
+		}
+
+		vpcs, networks, subnets, err := a.getVPCs(subscription)
+		if err != nil {
+			return resource, err
+		}
+		resource.VPCs = append(resource.VPCs, vpcs...)
+		resource.Networks = append(resource.Networks, networks...)
+		resource.Subnets = append(resource.Subnets, subnets...)
+
+		if err = a.getPublicIPs(subscription); err != nil {
+			return resource, err
+		}
+		nics, err := a.getNetworkInterfaces(subscription)
+		if err != nil {
+			return resource, err
+		}
+
+		vms, vmssNICs, err := a.getVMs(subscription)
+		if err != nil {
+			return resource, err
+		}
+		resource.VMs = append(resource.VMs, vms...)
+
+		vifs, ips, natRules := a.getVInterfaces(append(nics, vmssNICs...))
+		resource.VInterfaces = append(resource.VInterfaces, vifs...)
+		resource.IPs = append(resource.IPs, ips...)
+		resource.NATRules = append(resource.NATRules, natRules...)
+
+		sgs, sgRules, vmSGs, err := a.getSecurityGroups(subscription)
+		if err != nil {
+			return resource, err
+		}
+		resource.SecurityGroups = append(resource.SecurityGroups, sgs...)
+		resource.SecurityGroupRules = append(resource.SecurityGroupRules, sgRules...)
+		resource.VMSecurityGroups = append(resource.VMSecurityGroups, vmSGs...)
+
+		lbs, listeners, targetServers, lbVIFs, lbIPs, err := a.getLBs(subscription)
+		if err != nil {
+			return resource, err
+		}
+		resource.LBs = append(resource.LBs, lbs...)
+		resource.LBListeners = append(resource.LBListeners, listeners...)
+		resource.LBTargetServers = append(resource.LBTargetServers, targetServers...)
+		resource.VInterfaces = append(resource.VInterfaces, lbVIFs...)
+		resource.IPs = append(resource.IPs, lbIPs...)
+
+		natGateways, natVIFs, natIPs, err := a.getNATGateways(subscription)
+		if err != nil {
+			return resource, err
+		}
+		resource.NATGateways = append(resource.NATGateways, natGateways...)
+		resource.VInterfaces = append(resource.VInterfaces, natVIFs...)
+		resource.IPs = append(resource.IPs, natIPs...)
+
+		// 附属容器集群
+		subDomains, err := a.getSubDomains(subscription)
+		if err != nil {
+			return resource, err
+		}
+		resource.SubDomains = append(resource.SubDomains, subDomains...)
+	}
+	// 对等连接两端的虚拟网络可能属于不同订阅，全部订阅的虚拟网络获取完成后处理
+	resource.PeerConnections = a.getPeerConnections()
+
+	log.Debugf("region resource num info: %v", a.toolDataSet.regionLcuuidToResourceNum)
+	log.Debugf("az resource num info: %v", a.toolDataSet.azLcuuidToResourceNum)
+	resource.Regions = cloudcommon.EliminateEmptyRegions(a.toolDataSet.regions, a.toolDataSet.regionLcuuidToResourceNum)
+	resource.AZs = cloudcommon.EliminateEmptyAZs(a.toolDataSet.azs, a.toolDataSet.azLcuuidToResourceNum)
+
+	a.cloudStatsd.RefreshResCount(resource)
+	statsd.MetaStatsd.RegisterStatsdTable(a)
+
+	a.debugger.Refresh()
+	return resource, nil
+}
+
+func (a *Azure) GetStatter() statsd.StatsdStatter {
+	globalTags := map[string]string{
+		"domain_name": a.name,
+		"domain":      a.lcuuid,
+		"platform":    common.AZURE_EN,
+	}
+
+	return statsd.StatsdStatter{
+		GlobalTags: globalTags,
+		Element:    statsd.GetCloudStatsd(a.cloudStatsd),
+	}
+}
+
+func (a *Azure) doRequest(req *http.Request) (*simplejson.Json, error) {
+	client := &http.Client{Timeout: time.Duration(a.httpTimeout) * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	jResp, jErr := simplejson.NewJson(body)
+	if resp.StatusCode != http.StatusOK {
+		// 资源管理接口及认证接口的错误信息格式不同
+		msg := resp.Status
+		if jErr == nil {
+			if m := jResp.GetPath("error", "message").MustString(); m != "" {
+				msg = fmt.Sprintf("%s, %s", msg, m)
+			} else if m := jResp.Get("error_description").MustString(); m != "" {
+				msg = fmt.Sprintf("%s, %s", msg, m)
+			}
+		}
+		return nil, fmt.Errorf("request url: %s failed: %s", req.URL.Path, msg)
+	}
+	if jErr != nil {
+		return nil, fmt.Errorf("request url: %s, JSONiz failed: %v", req.URL.Path, jErr)
+	}
+	return jResp, nil
+}
+
+// path 为资源管理地址之后的路径，分页数据通过 nextLink 获取
+func (a *Azure) getRawData(resultKey, path, apiVersion string) (jsonList []*simplejson.Json, err error) {
+	statsdAPIStartTime := time.Now()
+
+	separator := "?"
+	if strings.Contains(path, "?") {
+		separator = "&"
+	}
+	url := fmt.Sprintf("%s%s%sapi-version=%s", a.config.ManagementURL, path, separator, apiVersion)
+	nextURL := url
+	for nextURL != "" {
+		token, err := a.getToken()
+		if err != nil {
+			return []*simplejson.Json{}, err
+		}
+		req, err := http.NewRequest(http.MethodGet, nextURL, nil)
+		if err != nil {
+			return []*simplejson.Json{}, err
+		}
+		req.Header.Set("Authorization", "Bearer "+token.accessToken)
+		req.Header.Set("Accept", "application/json")
+		resp, err := a.doRequest(req)
+		if err != nil {
+			log.Errorf("get %s failed: %v", resultKey, err)
+			return []*simplejson.Json{}, err
+		}
+
+		jData := resp.Get("value")
+		for i := range jData.MustArray() {
+			jsonList = append(jsonList, jData.GetIndex(i))
+		}
+
+		lastURL := nextURL
+		nextURL = resp.Get("nextLink").MustString()
+		if nextURL == lastURL {
+			break
+		}
+	}
+
+	a.cloudStatsd.RefreshAPICost(resultKey, statsdAPIStartTime)
+	a.cloudStatsd.RefreshAPICount(resultKey, len(jsonList))
+
+	a.debugger.WriteJson(resultKey, url, jsonList)
+	return
+}
+
+// 资源 ID 大小写不敏感，统一转为小写后生成 lcuuid 及作为索引
+func (a *Azure) getLcuuid(id string) string {
+	return common.GenerateUUID(strings.ToLower(id))
+}
+
+// 检查资源必需的属性，并按照资源组及区域过滤
+func (a *Azure) checkResource(resourceType string, jResource *simplejson.Json) bool {
+	if !cloudcommon.CheckJsonAttributes(jResource, []string{"id", "name", "location"}) {
+		log.Infof("exclude %s: %s, missing attr", resourceType, jResource.Get("id").MustString())
+		return false
+	}
+	id := jResource.Get("id").MustString()
+	if len(a.config.ResourceGroups) > 0 && !common.Contains(a.config.ResourceGroups, getResourceGroup(id)) {
+		return false
+	}
+	return a.isRegionIncluded(jResource.Get("location").MustString())
+}
+
+// 资源 ID 格式为 /subscriptions/<订阅ID>/resourceGroups/<资源组名称>/providers/...
+func getResourceGroup(id string) string {
+	parts := strings.Split(strings.ToLower(id), "/")
+	for i := 0; i < len(parts)-1; i++ {
+		if parts[i] == "resourcegroups" {
+			return parts[i+1]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,206 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package azure
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	cloudcommon "github.com/deepflowio/deepflow/server/controller/cloud/common"
+	"github.com/deepflowio/deepflow/server/controller/cloud/config"
+	"github.com/deepflowio/deepflow/server/controller/common"
+	"github.com/deepflowio/deepflow/server/controller/statsd"
+	statsdconfig "github.com/deepflowio/deepflow/server/controller/statsd/config"
+)
+
+const (
+	testTenantID     = "11111111-1111-1111-1111-111111111111"
+	testClientID     = "22222222-2222-2222-2222-222222222222"
+	testClientSecret = "client-secret"
+	testToken        = "eyJ0eXAiOiJKV1QiLCJhbGciOiJSUzI1NiJ9.test-token"
+	testSubscription = "/subscriptions/00000000-0000-0000-0000-000000000001"
+	testCompute      = testSubscription + "/resourceGroups/rg-1/providers/Microsoft.Compute"
+)
+
+// 请求路径与录制的接口返回文件的对应关系
+var testRoutes = map[string]string{
+	"/subscriptions":                "subscriptions.json",
+	testSubscription + "/locations": "locations.json",
+	testSubscription + "/providers/Microsoft.Network/virtualNetworks":          "virtualNetworks.json",
+	testSubscription + "/providers/Microsoft.Network/publicIPAddresses":        "publicIPAddresses.json",
+	testSubscription + "/providers/Microsoft.Network/networkInterfaces":        "networkInterfaces.json",
+	testSubscription + "/providers/Microsoft.Compute/virtualMachines":          "virtualMachines.json",
+	testSubscription + "/providers/Microsoft.Compute/virtualMachineScaleSets":  "virtualMachineScaleSets.json",
+	testCompute + "/virtualMachineScaleSets/vmss-1/networkInterfaces":          "vmssNetworkInterfaces.json",
+	testCompute + "/virtualMachineScaleSets/vmss-1/virtualMachines":            "vmssVirtualMachines.json",
+	testSubscription + "/providers/Microsoft.Network/networkSecurityGroups":    "networkSecurityGroups.json",
+	testSubscription + "/providers/Microsoft.Network/loadBalancers":            "loadBalancers.json",
+	testSubscription + "/providers/Microsoft.Network/natGateways":              "natGateways.json",
+	testSubscription + "/providers/Microsoft.ContainerService/managedClusters": "managedClusters.json",
+}
+
+func newTestServer(t *testing.T) *httptest.Server {
+	var srv *httptest.Server
+	writeFile := func(w http.ResponseWriter, name string) {
+		content, err := os.ReadFile(filepath.Join("testfiles", name))
+		if err != nil {
+			t.Fatalf("read test file %s failed: %v", name, err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(strings.ReplaceAll(string(content), "{{ENDPOINT}}", srv.URL)))
+	}
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/"+testTenantID+"/oauth2/v2.0/token" {
+			r.ParseForm()
+			if r.Method != http.MethodPost || r.PostForm.Get("client_id") != testClientID || r.PostForm.Get("client_secret") != testClientSecret {
+				w.WriteHeader(http.StatusUnauthorized)
+				w.Write([]byte(`{"error": "invalid_client", "error_description": "AADSTS7000215: Invalid client secret provided."}`))
+				return
+			}
+			writeFile(w, "token.json")
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer "+testToken || r.URL.Query().Get("api-version") == "" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		name, ok := testRoutes[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error": {"code": "ResourceNotFound", "message": "not found"}}`))
+			return
+		}
+		if page := r.URL.Query().Get("page"); page != "" {
+			name = strings.TrimSuffix(name, ".json") + "_page_" + page + ".json"
+		}
+		writeFile(w, name)
+	}))
+	return srv
+}
+
+func newTestAzure(url, secret string) *Azure {
+	return &Azure{
+		lcuuid:         "azure-lcuuid",
+		lcuuidGenerate: "test_azure",
+		name:           "test_azure",
+		httpTimeout:    30,
+		config: &Config{
+			AuthType:       AUTH_TYPE_SERVICE_PRINCIPAL,
+			TenantID:       testTenantID,
+			ClientID:       testClientID,
+			ClientSecret:   secret,
+			LoginURL:       url,
+			ManagementURL:  url,
+			ExcludeRegions: []string{"West US"},
+		},
+		debugger: cloudcommon.NewDebugger("test_azure"),
+	}
+}
+
+func TestAzure(t *testing.T) {
+	config.SetCloudGlobalConfig(config.CloudConfig{})
+	statsd.NewStatsdMonitor(statsdconfig.StatsdConfig{})
+	srv := newTestServer(t)
+	defer srv.Close()
+
+	Convey("TestAzureCheckAuth", t, func() {
+		So(newTestAzure(srv.URL, testClientSecret).CheckAuth(), ShouldBeNil)
+		err := newTestAzure(srv.URL, "wrong").CheckAuth()
+		So(err, ShouldNotBeNil)
+		So(err.Error(), ShouldContainSubstring, "AADSTS7000215")
+	})
+
+	Convey("TestAzureGetCloudData", t, func() {
+		azure := newTestAzure(srv.URL, testClientSecret)
+		data, err := azure.GetCloudData()
+		So(err, ShouldBeNil)
+
+		Convey("azureResource number should be equal", func() {
+			// West US 被排除
+			So(len(data.Regions), ShouldEqual, 1)
+			// 可用区 1、2 及未开启可用区资源所属的默认可用区
+			So(len(data.AZs), ShouldEqual, 3)
+			// 第二页的 vnet-2 及 AKS 自动创建的虚拟网络
+			So(len(data.VPCs), ShouldEqual, 3)
+			So(len(data.Networks), ShouldEqual, 4)
+			// 双栈子网对应两个网段
+			So(len(data.Subnets), ShouldEqual, 5)
+			So(len(data.PeerConnections), ShouldEqual, 1)
+			// 灵活模式规模集的实例已包含在虚拟机列表中
+			So(len(data.VMs), ShouldEqual, 3)
+			// 三个云服务器网卡、一个公网接口、两个负载均衡器前端及一个 NAT 网关接口，私有终结点网卡不同步
+			So(len(data.VInterfaces), ShouldEqual, 7)
+			So(len(data.IPs), ShouldEqual, 8)
+			So(len(data.NATRules), ShouldEqual, 1)
+			So(len(data.SecurityGroups), ShouldEqual, 2)
+			So(len(data.SecurityGroupRules), ShouldEqual, 4)
+			So(len(data.VMSecurityGroups), ShouldEqual, 2)
+			So(len(data.LBs), ShouldEqual, 1)
+			So(len(data.LBListeners), ShouldEqual, 2)
+			So(len(data.LBTargetServers), ShouldEqual, 6)
+			// 未关联子网的 NAT 网关不同步
+			So(len(data.NATGateways), ShouldEqual, 1)
+			So(len(data.SubDomains), ShouldEqual, 1)
+		})
+
+		Convey("azureResource relations should be correct", func() {
+			vnet1Lcuuid := common.GenerateUUID(strings.ToLower(testSubscription + "/resourceGroups/rg-1/providers/Microsoft.Network/virtualNetworks/vnet-1"))
+			vnet2Lcuuid := common.GenerateUUID(strings.ToLower(testSubscription + "/resourceGroups/rg-1/providers/Microsoft.Network/virtualNetworks/vnet-2"))
+			for _, vm := range data.VMs {
+				switch vm.Name {
+				case "vm-1":
+					So(vm.Lcuuid, ShouldEqual, "6c0f9d1e-0000-0000-0000-000000000001")
+					So(vm.VPCLcuuid, ShouldEqual, vnet1Lcuuid)
+					So(vm.State, ShouldEqual, common.VM_STATE_RUNNING)
+					So(vm.CloudTags, ShouldEqual, "env:prod, team:infra")
+					So(vm.CreatedAt.IsZero(), ShouldBeFalse)
+				case "vm-2":
+					So(vm.State, ShouldEqual, common.VM_STATE_STOPPED)
+				case "vmss-1_0":
+					So(vm.VPCLcuuid, ShouldEqual, vnet2Lcuuid)
+					So(vm.CloudTags, ShouldEqual, "role:worker")
+				}
+			}
+			for _, vif := range data.VInterfaces {
+				if vif.Name == "vm-1-nic" {
+					So(vif.Mac, ShouldEqual, "00:0d:3a:12:34:56")
+				}
+			}
+			So(data.LBs[0].Model, ShouldEqual, common.LB_MODEL_EXTERNAL)
+			So(data.LBs[0].VIP, ShouldEqual, "10.0.1.100,20.0.0.2")
+			So(data.LBs[0].VPCLcuuid, ShouldEqual, vnet1Lcuuid)
+			So(data.NATGateways[0].FloatingIPs, ShouldEqual, "20.0.0.3")
+			So(data.PeerConnections[0].LocalVPCLcuuid, ShouldEqual, vnet1Lcuuid)
+			So(data.PeerConnections[0].RemoteVPCLcuuid, ShouldEqual, vnet2Lcuuid)
+			So(data.SubDomains[0].ClusterID, ShouldEqual, "aks-1")
+			So(data.SubDomains[0].VpcUUID, ShouldNotEqual, vnet1Lcuuid)
+
+			tsTypes := map[int]int{}
+			for _, ts := range data.LBTargetServers {
+				tsTypes[ts.Type]++
+			}
+			So(tsTypes[common.LB_SERVER_TYPE_VM], ShouldEqual, 4)
+			So(tsTypes[common.LB_SERVER_TYPE_IP], ShouldEqual, 2)
+		})
+	})
+}
@@ -0,0 +1,135 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package azure
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/bitly/go-simplejson"
+
+	"github.com/deepflowio/deepflow/server/controller/common"
+)
+
+const (
+	AUTH_TYPE_SERVICE_PRINCIPAL = "service_principal"
+	AUTH_TYPE_MANAGED_IDENTITY  = "managed_identity"
+
+	CLOUD_NAME_PUBLIC        = "AzureCloud"
+	CLOUD_NAME_CHINA         = "AzureChinaCloud"
+	CLOUD_NAME_US_GOVERNMENT = "AzureUSGovernment"
+)
+
+// 各云环境的认证及资源管理地址
+var CLOUD_NAME_TO_ENDPOINTS = map[string][2]string{
+	CLOUD_NAME_PUBLIC:        {"https://login.microsoftonline.com", "https://management.azure.com"},
+	CLOUD_NAME_CHINA:         {"https://login.chinacloudapi.cn", "https://management.chinacloudapi.cn"},
+	CLOUD_NAME_US_GOVERNMENT: {"https://login.microsoftonline.us", "https://management.usgovcloudapi.net"},
+}
+
+type Config struct {
+	RegionLcuuid    string
+	AuthType        string
+	TenantID        string
+	ClientID        string // 服务主体的应用ID，使用托管标识时为用户分配的托管标识的客户端ID，可为空
+	ClientSecret    string
+	LoginURL        string
+	ManagementURL   string
+	SubscriptionIDs []string // 为空时同步账号有权限的全部订阅
+	ResourceGroups  []string // 为空时同步全部资源组
+	ExcludeRegions  []string
+	IncludeRegions  []string
+}
+
+func (c *Config) LoadFromString(sConf string) (err error) {
+	jConf, err := simplejson.NewJson([]byte(sConf))
+	if err != nil {
+		log.Errorf("convert config string: %s to json failed: %v", sConf, err)
+		return
+	}
+	c.RegionLcuuid = jConf.Get("region_uuid").MustString()
+	c.AuthType = jConf.Get("auth_type").MustString()
+	if c.AuthType == "" {
+		c.AuthType = AUTH_TYPE_SERVICE_PRINCIPAL
+	}
+	c.ClientID = jConf.Get("client_id").MustString()
+	switch c.AuthType {
+	case AUTH_TYPE_SERVICE_PRINCIPAL:
+		c.TenantID, err = jConf.Get("tenant_id").String()
+		if err != nil {
+			log.Error("tenant_id must be specified")
+			return
+		}
+		if c.ClientID == "" {
+			err = errors.New("client_id must be specified")
+			log.Error(err)
+			return
+		}
+		var secret string
+		secret, err = jConf.Get("secret_key").String()
+		if err != nil {
+			log.Error("secret_key must be specified")
+			return
+		}
+		c.ClientSecret, err = common.DecryptSecretKey(secret)
+		if err != nil {
+			log.Error("decrypt secret_key failed")
+			return
+		}
+	case AUTH_TYPE_MANAGED_IDENTITY:
+	default:
+		err = errors.New("auth_type must be service_principal or managed_identity")
+		log.Error(err)
+		return
+	}
+
+	cloudName := jConf.Get("cloud_name").MustString()
+	if cloudName == "" {
+		cloudName = CLOUD_NAME_PUBLIC
+	}
+	endpoints, ok := CLOUD_NAME_TO_ENDPOINTS[cloudName]
+	if !ok {
+		err = errors.New("cloud_name not supported: " + cloudName)
+		log.Error(err)
+		return
+	}
+	// Azure Stack 等私有部署环境可以直接指定地址
+	c.LoginURL = strings.TrimSuffix(jConf.Get("login_url").MustString(), "/")
+	if c.LoginURL == "" {
+		c.LoginURL = endpoints[0]
+	}
+	c.ManagementURL = strings.TrimSuffix(jConf.Get("management_url").MustString(), "/")
+	if c.ManagementURL == "" {
+		c.ManagementURL = endpoints[1]
+	}
+
+	c.SubscriptionIDs = splitConfigList(jConf.Get("subscription_ids").MustString())
+	c.ResourceGroups = splitConfigList(strings.ToLower(jConf.Get("resource_groups").MustString()))
+	c.ExcludeRegions = splitConfigList(jConf.Get("exclude_regions").MustString())
+	c.IncludeRegions = splitConfigList(jConf.Get("include_regions").MustString())
+	return
+}
+
+func splitConfigList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,236 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package azure
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bitly/go-simplejson"
+
+	"github.com/deepflowio/deepflow/server/controller/cloud/model"
+	"github.com/deepflowio/deepflow/server/controller/common"
+)
+
+// 负载均衡规则对应监听器，后端池中的网卡 IP 配置对应云服务器类型的后端主机，IP 地址对应 IP 类型的后端主机
+func (a *Azure) getLBs(subscription Subscription) ([]model.LB, []model.LBListener, []model.LBTargetServer, []model.VInterface, []model.IP, error) {
+	log.Debug("get lbs starting")
+	var lbs []model.LB
+	var lbListeners []model.LBListener
+	var lbTargetServers []model.LBTargetServer
+	var vinterfaces []model.VInterface
+	var ips []model.IP
+
+	jLBs, err := a.getRawData("loadBalancers", fmt.Sprintf("/subscriptions/%s/providers/Microsoft.Network/loadBalancers", subscription.id), API_VERSION_NETWORK)
+	if err != nil {
+		return nil, nil, nil, nil, nil, err
+	}
+	for i := range jLBs {
+		jl := jLBs[i]
+		if !a.checkResource("lb", jl) {
+			continue
+		}
+		lbID := strings.ToLower(jl.Get("id").MustString())
+		lbLcuuid := a.getLcuuid(lbID)
+		regionLcuuid := a.getRegionLcuuid(jl.Get("location").MustString())
+		jProps := jl.Get("properties")
+
+		// 后端池
+		poolIDToIPConfigIDs := map[string][]string{}
+		poolIDToIPs := map[string][]string{}
+		vpcLcuuid := ""
+		jPools := jProps.Get("backendAddressPools")
+		for j := range jPools.MustArray() {
+			jp := jPools.GetIndex(j)
+			poolID := strings.ToLower(jp.Get("id").MustString())
+			jIPConfigs := jp.GetPath("properties", "backendIPConfigurations")
+			for k := range jIPConfigs.MustArray() {
+				ipConfigID := strings.ToLower(jIPConfigs.GetIndex(k).Get("id").MustString())
+				poolIDToIPConfigIDs[poolID] = append(poolIDToIPConfigIDs[poolID], ipConfigID)
+				if ipConfig, ok := a.toolDataSet.ipConfigIDToIPConfig[ipConfigID]; ok && vpcLcuuid == "" {
+					vpcLcuuid = ipConfig.vpcLcuuid
+				}
+			}
+			jAddresses := jp.GetPath("properties", "loadBalancerBackendAddresses")
+			for k := range jAddresses.MustArray() {
+				ja := jAddresses.GetIndex(k).Get("properties")
+				// 通过网卡添加的后端地址已在 backendIPConfigurations 中返回
+				if _, ok := ja.CheckGet("networkInterfaceIPConfiguration"); ok {
+					continue
+				}
+				if ip := ja.Get("ipAddress").MustString(); ip != "" {
+					poolIDToIPs[poolID] = append(poolIDToIPs[poolID], ip)
+				}
+				if vnetLcuuid, ok := a.toolDataSet.vnetIDToVPCLcuuid[strings.ToLower(ja.GetPath("virtualNetwork", "id").MustString())]; ok && vpcLcuuid == "" {
+					vpcLcuuid = vnetLcuuid
+				}
+			}
+		}
+
+		// 前端 IP 配置，内网前端所在虚拟网络优先作为负载均衡器所属 VPC
+		frontendIDToIP := map[string]string{}
+		var frontendVInterfaces []model.VInterface
+		var frontendIPs []model.IP
+		var vips []string
+		lbModel := common.LB_MODEL_INTERNAL
+		jFrontends := jProps.Get("frontendIPConfigurations")
+		for j := range jFrontends.MustArray() {
+			jf := jFrontends.GetIndex(j)
+			frontendID := strings.ToLower(jf.Get("id").MustString())
+			vinterfaceLcuuid := a.getLcuuid(frontendID)
+			if privateIP := jf.GetPath("properties", "privateIPAddress").MustString(); privateIP != "" {
+				subnet, ok := a.toolDataSet.subnetIDToSubnet[strings.ToLower(jf.GetPath("properties", "subnet", "id").MustString())]
+				if !ok {
+					continue
+				}
+				vpcLcuuid = subnet.vpcLcuuid
+				frontendIDToIP[frontendID] = privateIP
+				vips = append(vips, privateIP)
+				frontendVInterfaces = append(frontendVInterfaces, model.VInterface{
+					Lcuuid:        vinterfaceLcuuid,
+					Type:          common.VIF_TYPE_LAN,
+					Mac:           common.VIF_DEFAULT_MAC,
+					DeviceLcuuid:  lbLcuuid,
+					DeviceType:    common.VIF_DEVICE_TYPE_LB,
+					NetworkLcuuid: subnet.networkLcuuid,
+					VPCLcuuid:     subnet.vpcLcuuid,
+					RegionLcuuid:  regionLcuuid,
+				})
+				frontendIPs = append(frontendIPs, model.IP{
+					Lcuuid:           common.GenerateUUID(vinterfaceLcuuid + privateIP),
+					VInterfaceLcuuid: vinterfaceLcuuid,
+					IP:               privateIP,
+					SubnetLcuuid:     subnet.getSubnetLcuuid(privateIP),
+					RegionLcuuid:     regionLcuuid,
+				})
+			} else if publicIP, ok := a.toolDataSet.publicIPIDToIP[strings.ToLower(jf.GetPath("properties", "publicIPAddress", "id").MustString())]; ok {
+				lbModel = common.LB_MODEL_EXTERNAL
+				frontendIDToIP[frontendID] = publicIP
+				vips = append(vips, publicIP)
+				frontendVInterfaces = append(frontendVInterfaces, model.VInterface{
+					Lcuuid:        vinterfaceLcuuid,
+					Type:          common.VIF_TYPE_WAN,
+					Mac:           common.VIF_DEFAULT_MAC,
+					DeviceLcuuid:  lbLcuuid,
+					DeviceType:    common.VIF_DEVICE_TYPE_LB,
+					NetworkLcuuid: common.NETWORK_ISP_LCUUID,
+					RegionLcuuid:  regionLcuuid,
+				})
+				frontendIPs = append(frontendIPs, model.IP{
+					Lcuuid:           common.GenerateUUID(vinterfaceLcuuid + publicIP),
+					VInterfaceLcuuid: vinterfaceLcuuid,
+					IP:               publicIP,
+					RegionLcuuid:     regionLcuuid,
+				})
+			}
+		}
+		if vpcLcuuid == "" {
+			log.Infof("exclude lb: %s, vpc not found", lbID)
+			continue
+		}
+		for j := range frontendVInterfaces {
+			frontendVInterfaces[j].VPCLcuuid = vpcLcuuid
+		}
+		vinterfaces = append(vinterfaces, frontendVInterfaces...)
+		ips = append(ips, frontendIPs...)
+
+		lbs = append(lbs, model.LB{
+			Lcuuid:       lbLcuuid,
+			Name:         jl.Get("name").MustString(),
+			Label:        jProps.Get("resourceGuid").MustString(),
+			Model:        lbModel,
+			VIP:          strings.Join(vips, ","),
+			VPCLcuuid:    vpcLcuuid,
+			RegionLcuuid: regionLcuuid,
+		})
+		a.toolDataSet.regionLcuuidToResourceNum[regionLcuuid]++
+
+		jRules := jProps.Get("loadBalancingRules")
+		for j := range jRules.MustArray() {
+			jr := jRules.GetIndex(j)
+			frontendIP, ok := frontendIDToIP[strings.ToLower(jr.GetPath("properties", "frontendIPConfiguration", "id").MustString())]
+			if !ok {
+				continue
+			}
+			listenerLcuuid := a.getLcuuid(jr.Get("id").MustString())
+			protocol := strings.ToUpper(jr.GetPath("properties", "protocol").MustString())
+			port := jr.GetPath("properties", "frontendPort").MustInt()
+			lbListeners = append(lbListeners, model.LBListener{
+				Lcuuid:   listenerLcuuid,
+				LBLcuuid: lbLcuuid,
+				Name:     jr.Get("name").MustString(),
+				IPs:      frontendIP,
+				Protocol: protocol,
+				Port:     port,
+			})
+
+			backendPort := jr.GetPath("properties", "backendPort").MustInt()
+			if backendPort == 0 {
+				backendPort = port
+			}
+			for _, poolID := range getRulePoolIDs(jr) {
+				for _, ipConfigID := range poolIDToIPConfigIDs[poolID] {
+					ipConfig, ok := a.toolDataSet.ipConfigIDToIPConfig[ipConfigID]
+					if !ok {
+						continue
+					}
+					lbTargetServers = append(lbTargetServers, model.LBTargetServer{
+						Lcuuid:           common.GenerateUUID(listenerLcuuid + ipConfigID),
+						LBLcuuid:         lbLcuuid,
+						LBListenerLcuuid: listenerLcuuid,
+						Type:             common.LB_SERVER_TYPE_VM,
+						IP:               ipConfig.ip,
+						VMLcuuid:         ipConfig.vmLcuuid,
+						Protocol:         protocol,
+						Port:             backendPort,
+						VPCLcuuid:        ipConfig.vpcLcuuid,
+					})
+				}
+				for _, ip := range poolIDToIPs[poolID] {
+					lbTargetServers = append(lbTargetServers, model.LBTargetServer{
+						Lcuuid:           common.GenerateUUID(listenerLcuuid + ip),
+						LBLcuuid:         lbLcuuid,
+						LBListenerLcuuid: listenerLcuuid,
+						Type:             common.LB_SERVER_TYPE_IP,
+						IP:               ip,
+						Protocol:         protocol,
+						Port:             backendPort,
+						VPCLcuuid:        vpcLcuuid,
+					})
+				}
+			}
+		}
+	}
+	log.Debug("get lbs complete")
+	return lbs, lbListeners, lbTargetServers, vinterfaces, ips, nil
+}
+
+// 标准负载均衡器的规则可关联多个后端池
+func getRulePoolIDs(jRule *simplejson.Json) []string {
+	var poolIDs []string
+	if poolID := jRule.GetPath("properties", "backendAddressPool", "id").MustString(); poolID != "" {
+		poolIDs = append(poolIDs, strings.ToLower(poolID))
+	}
+	jPools := jRule.GetPath("properties", "backendAddressPools")
+	for i := range jPools.MustArray() {
+		poolID := strings.ToLower(jPools.GetIndex(i).Get("id").MustString())
+		if poolID != "" && !common.Contains(poolIDs, poolID) {
+			poolIDs = append(poolIDs, poolID)
+		}
+	}
+	return poolIDs
+}
@@ -0,0 +1,102 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package azure
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/deepflowio/deepflow/server/controller/cloud/model"
+	"github.com/deepflowio/deepflow/server/controller/common"
+)
+
+// 未关联子网的 NAT 网关不生效，不同步
+func (a *Azure) getNATGateways(subscription Subscription) ([]model.NATGateway, []model.VInterface, []model.IP, error) {
+	log.Debug("get nat_gateways starting")
+	var natGateways []model.NATGateway
+	var vinterfaces []model.VInterface
+	var ips []model.IP
+
+	jNATGateways, err := a.getRawData("natGateways", fmt.Sprintf("/subscriptions/%s/providers/Microsoft.Network/natGateways", subscription.id), API_VERSION_NETWORK)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	for i := range jNATGateways {
+		jn := jNATGateways[i]
+		if !a.checkResource("nat_gateway", jn) {
+			continue
+		}
+		natID := strings.ToLower(jn.Get("id").MustString())
+		vpcLcuuid := ""
+		jSubnets := jn.GetPath("properties", "subnets")
+		for j := range jSubnets.MustArray() {
+			if subnet, ok := a.toolDataSet.subnetIDToSubnet[strings.ToLower(jSubnets.GetIndex(j).Get("id").MustString())]; ok {
+				vpcLcuuid = subnet.vpcLcuuid
+				break
+			}
+		}
+		if vpcLcuuid == "" {
+			log.Infof("exclude nat_gateway: %s, vpc not found", natID)
+			continue
+		}
+
+		var floatingIPs []string
+		jPublicIPs := jn.GetPath("properties", "publicIpAddresses")
+		for j := range jPublicIPs.MustArray() {
+			if ip, ok := a.toolDataSet.publicIPIDToIP[strings.ToLower(jPublicIPs.GetIndex(j).Get("id").MustString())]; ok {
+				floatingIPs = append(floatingIPs, ip)
+			}
+		}
+
+		natLcuuid := a.getLcuuid(natID)
+		regionLcuuid := a.getRegionLcuuid(jn.Get("location").MustString())
+		natGateways = append(natGateways, model.NATGateway{
+			Lcuuid:       natLcuuid,
+			Name:         jn.Get("name").MustString(),
+			Label:        jn.GetPath("properties", "resourceGuid").MustString(),
+			FloatingIPs:  strings.Join(floatingIPs, ","),
+			VPCLcuuid:    vpcLcuuid,
+			RegionLcuuid: regionLcuuid,
+		})
+		a.toolDataSet.regionLcuuidToResourceNum[regionLcuuid]++
+
+		if len(floatingIPs) == 0 {
+			continue
+		}
+		vinterfaceLcuuid := common.GenerateUUID(natLcuuid)
+		vinterfaces = append(vinterfaces, model.VInterface{
+			Lcuuid:        vinterfaceLcuuid,
+			Type:          common.VIF_TYPE_WAN,
+			Mac:           common.VIF_DEFAULT_MAC,
+			DeviceLcuuid:  natLcuuid,
+			DeviceType:    common.VIF_DEVICE_TYPE_NAT_GATEWAY,
+			NetworkLcuuid: common.NETWORK_ISP_LCUUID,
+			VPCLcuuid:     vpcLcuuid,
+			RegionLcuuid:  regionLcuuid,
+		})
+		for _, ip := range floatingIPs {
+			ips = append(ips, model.IP{
+				Lcuuid:           common.GenerateUUID(vinterfaceLcuuid + ip),
+				VInterfaceLcuuid: vinterfaceLcuuid,
+				IP:               ip,
+				RegionLcuuid:     regionLcuuid,
+			})
+		}
+	}
+	log.Debug("get nat_gateways complete")
+	return natGateways, vinterfaces, ips, nil
+}
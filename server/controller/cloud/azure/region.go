@@ -0,0 +1,154 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package azure
+
+import (
+	"fmt"
+	"strings"
+
+	cloudcommon "github.com/deepflowio/deepflow/server/controller/cloud/common"
+	"github.com/deepflowio/deepflow/server/controller/cloud/model"
+	"github.com/deepflowio/deepflow/server/controller/common"
+)
+
+type Subscription struct {
+	id   string
+	name string
+}
+
+func (a *Azure) getSubscriptions() ([]Subscription, error) {
+	jSubscriptions, err := a.getRawData("subscriptions", "/subscriptions", API_VERSION_SUBSCRIPTION)
+	if err != nil {
+		return nil, err
+	}
+
+	var subscriptions []Subscription
+	for i := range jSubscriptions {
+		js := jSubscriptions[i]
+		if !cloudcommon.CheckJsonAttributes(js, []string{"subscriptionId", "displayName", "state"}) {
+			continue
+		}
+		id := js.Get("subscriptionId").MustString()
+		if len(a.config.SubscriptionIDs) > 0 && !common.Contains(a.config.SubscriptionIDs, id) {
+			continue
+		}
+		// 禁用及已删除的订阅无法读取资源
+		if state := js.Get("state").MustString(); state != "Enabled" && state != "PastDue" && state != "Warned" {
+			log.Infof("exclude subscription: %s, state is %s", id, state)
+			continue
+		}
+		subscriptions = append(subscriptions, Subscription{id: id, name: js.Get("displayName").MustString()})
+	}
+	if len(a.config.SubscriptionIDs) > 0 && len(subscriptions) != len(a.config.SubscriptionIDs) {
+		log.Warningf("only %d of subscriptions (%s) are available", len(subscriptions), strings.Join(a.config.SubscriptionIDs, ","))
+	}
+	if len(subscriptions) == 0 {
+		return nil, fmt.Errorf("no available subscription found")
+	}
+	return subscriptions, nil
+}
+
+func (a *Azure) getLocations(subscription Subscription) error {
+	jLocations, err := a.getRawData("locations", fmt.Sprintf("/subscriptions/%s/locations", subscription.id), API_VERSION_SUBSCRIPTION)
+	if err != nil {
+		return err
+	}
+	for i := range jLocations {
+		jl := jLocations[i]
+		if !cloudcommon.CheckJsonAttributes(jl, []string{"name", "displayName"}) {
+			continue
+		}
+		a.toolDataSet.locationToDisplayName[jl.Get("name").MustString()] = jl.Get("displayName").MustString()
+	}
+	return nil
+}
+
+// 配置的资源组不存在时仅打印日志，不影响其他资源组同步
+func (a *Azure) getResourceGroups(subscription Subscription) error {
+	if len(a.config.ResourceGroups) == 0 {
+		return nil
+	}
+	jGroups, err := a.getRawData("resourcegroups", fmt.Sprintf("/subscriptions/%s/resourcegroups", subscription.id), API_VERSION_RESOURCE)
+	if err != nil {
+		return err
+	}
+	groups := map[string]bool{}
+	for i := range jGroups {
+		groups[strings.ToLower(jGroups[i].Get("name").MustString())] = true
+	}
+	for _, group := range a.config.ResourceGroups {
+		if !groups[group] {
+			log.Infof("resource group (%s) not found in subscription (%s)", group, subscription.name)
+		}
+	}
+	return nil
+}
+
+func (a *Azure) isRegionIncluded(location string) bool {
+	displayName := a.toolDataSet.locationToDisplayName[location]
+	if len(a.config.IncludeRegions) > 0 &&
+		!common.Contains(a.config.IncludeRegions, location) && !common.Contains(a.config.IncludeRegions, displayName) {
+		return false
+	}
+	if common.Contains(a.config.ExcludeRegions, location) || (displayName != "" && common.Contains(a.config.ExcludeRegions, displayName)) {
+		return false
+	}
+	return true
+}
+
+func (a *Azure) getRegionLcuuid(location string) string {
+	if a.config.RegionLcuuid != "" {
+		return a.config.RegionLcuuid
+	}
+	lcuuid := common.GenerateUUID(location + "_" + a.lcuuidGenerate)
+	// 页面指定区域时，资源均属于指定区域
+	if !a.toolDataSet.regionLcuuidToExists[lcuuid] {
+		a.toolDataSet.regionLcuuidToExists[lcuuid] = true
+		a.toolDataSet.regions = append(a.toolDataSet.regions, model.Region{
+			Lcuuid: lcuuid,
+			Label:  location,
+			Name:   a.getLocationName(location),
+		})
+	}
+	return lcuuid
+}
+
+func (a *Azure) getLocationName(location string) string {
+	if displayName, ok := a.toolDataSet.locationToDisplayName[location]; ok {
+		return displayName
+	}
+	return location
+}
+
+// 未开启可用区的资源属于区域的默认可用区
+func (a *Azure) getAZLcuuid(location, zone string) string {
+	lcuuid := common.GenerateUUID(location + "_" + zone + "_" + a.lcuuidGenerate)
+	if !a.toolDataSet.azLcuuidToExists[lcuuid] {
+		a.toolDataSet.azLcuuidToExists[lcuuid] = true
+		name := a.getLocationName(location)
+		if zone != "" {
+			name = fmt.Sprintf("%s %s", name, zone)
+		}
+		a.toolDataSet.azs = append(a.toolDataSet.azs, model.AZ{
+			Lcuuid:       lcuuid,
+			Label:        zone,
+			Name:         name,
+			RegionLcuuid: a.getRegionLcuuid(location),
+		})
+	}
+	return lcuuid
+}
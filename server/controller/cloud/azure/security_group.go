@@ -0,0 +1,210 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package azure
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bitly/go-simplejson"
+
+	cloudcommon "github.com/deepflowio/deepflow/server/controller/cloud/common"
+	"github.com/deepflowio/deepflow/server/controller/cloud/model"
+	"github.com/deepflowio/deepflow/server/controller/common"
+)
+
+// 网络安全组可关联网卡及子网，需在网卡处理完成后获取
+func (a *Azure) getSecurityGroups(subscription Subscription) ([]model.SecurityGroup, []model.SecurityGroupRule, []model.VMSecurityGroup, error) {
+	log.Debug("get security_groups starting")
+	var securityGroups []model.SecurityGroup
+	var securityGroupRules []model.SecurityGroupRule
+	var vmSecurityGroups []model.VMSecurityGroup
+
+	jNSGs, err := a.getRawData("networkSecurityGroups", fmt.Sprintf("/subscriptions/%s/providers/Microsoft.Network/networkSecurityGroups", subscription.id), API_VERSION_NETWORK)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	nsgIDToLcuuid := map[string]string{}
+	for i := range jNSGs {
+		jn := jNSGs[i]
+		if !a.checkResource("security_group", jn) {
+			continue
+		}
+		nsgID := strings.ToLower(jn.Get("id").MustString())
+		sgLcuuid := a.getLcuuid(nsgID)
+		nsgIDToLcuuid[nsgID] = sgLcuuid
+		regionLcuuid := a.getRegionLcuuid(jn.Get("location").MustString())
+		securityGroups = append(securityGroups, model.SecurityGroup{
+			Lcuuid:       sgLcuuid,
+			Name:         jn.Get("name").MustString(),
+			Label:        jn.GetPath("properties", "resourceGuid").MustString(),
+			RegionLcuuid: regionLcuuid,
+		})
+		a.toolDataSet.regionLcuuidToResourceNum[regionLcuuid]++
+
+		// 自定义规则优先级为 100-4096，默认规则优先级为 65000 及以上
+		for _, key := range []string{"securityRules", "defaultSecurityRules"} {
+			jRules := jn.GetPath("properties", key)
+			for j := range jRules.MustArray() {
+				securityGroupRules = append(securityGroupRules, a.formatSecurityGroupRules(sgLcuuid, jRules.GetIndex(j))...)
+			}
+		}
+	}
+
+	for vmLcuuid, nsgIDs := range a.toolDataSet.vmLcuuidToSecurityGroupIDs {
+		priority := 0
+		for _, nsgID := range nsgIDs {
+			sgLcuuid, ok := nsgIDToLcuuid[nsgID]
+			if !ok {
+				continue
+			}
+			vmSecurityGroups = append(vmSecurityGroups, model.VMSecurityGroup{
+				Lcuuid:              common.GenerateUUID(vmLcuuid + sgLcuuid),
+				VMLcuuid:            vmLcuuid,
+				SecurityGroupLcuuid: sgLcuuid,
+				Priority:            priority,
+			})
+			priority++
+		}
+	}
+	log.Debug("get security_groups complete")
+	return securityGroups, securityGroupRules, vmSecurityGroups, nil
+}
+
+// 规则中的地址前缀可能同时包含 IPv4 及 IPv6 地址，按照地址类型拆分为多条规则
+func (a *Azure) formatSecurityGroupRules(sgLcuuid string, jRule *simplejson.Json) []model.SecurityGroupRule {
+	if !cloudcommon.CheckJsonAttributes(jRule, []string{"id", "properties"}) {
+		return nil
+	}
+	ruleID := strings.ToLower(jRule.Get("id").MustString())
+	jProps := jRule.Get("properties")
+
+	direction := common.SECURITY_GROUP_RULE_INGRESS
+	if jProps.Get("direction").MustString() == "Outbound" {
+		direction = common.SECURITY_GROUP_RULE_EGRESS
+	}
+	action := common.SECURITY_GROUP_RULE_ACCEPT
+	if jProps.Get("access").MustString() == "Deny" {
+		action = common.SECURITY_GROUP_RULE_DROP
+	}
+	protocol := strings.ToUpper(jProps.Get("protocol").MustString())
+	if protocol == "" || protocol == "*" {
+		protocol = "ALL"
+	}
+
+	sourcePorts := getRuleValues(jProps, "sourcePortRange", "sourcePortRanges")
+	destinationPorts := getRuleValues(jProps, "destinationPortRange", "destinationPortRanges")
+	sourcePrefixes := getRuleValues(jProps, "sourceAddressPrefix", "sourceAddressPrefixes")
+	destinationPrefixes := getRuleValues(jProps, "destinationAddressPrefix", "destinationAddressPrefixes")
+	// 入方向本端为目的端，出方向本端为源端
+	localPorts, remotePorts := destinationPorts, sourcePorts
+	localPrefixes, remotePrefixes := destinationPrefixes, sourcePrefixes
+	if direction == common.SECURITY_GROUP_RULE_EGRESS {
+		localPorts, remotePorts = sourcePorts, destinationPorts
+		localPrefixes, remotePrefixes = sourcePrefixes, destinationPrefixes
+	}
+
+	// 仅包含服务标签及任意地址的规则只生成 IPv4 规则
+	etherTypes := []int{}
+	hasIPv4, hasIPv6 := getPrefixIPVersions(append(append([]string{}, sourcePrefixes...), destinationPrefixes...))
+	if hasIPv4 || !hasIPv6 {
+		etherTypes = append(etherTypes, common.SECURITY_GROUP_RULE_IPV4)
+	}
+	if hasIPv6 {
+		etherTypes = append(etherTypes, common.SECURITY_GROUP_RULE_IPV6)
+	}
+
+	var rules []model.SecurityGroupRule
+	for _, etherType := range etherTypes {
+		local := formatRulePrefixes(localPrefixes, etherType)
+		remote := formatRulePrefixes(remotePrefixes, etherType)
+		if local == "" || remote == "" {
+			continue
+		}
+		lcuuid := ruleID
+		if etherType == common.SECURITY_GROUP_RULE_IPV6 {
+			lcuuid += "_ipv6"
+		}
+		rules = append(rules, model.SecurityGroupRule{
+			Lcuuid:              common.GenerateUUID(lcuuid),
+			SecurityGroupLcuuid: sgLcuuid,
+			Direction:           direction,
+			EtherType:           etherType,
+			Protocol:            protocol,
+			LocalPortRange:      formatRulePorts(localPorts),
+			RemotePortRange:     formatRulePorts(remotePorts),
+			Local:               local,
+			Remote:              remote,
+			Action:              action,
+			Priority:            jProps.Get("priority").MustInt(),
+		})
+	}
+	return rules
+}
+
+func getRuleValues(jProps *simplejson.Json, singleKey, multiKey string) []string {
+	values := jProps.Get(multiKey).MustStringArray()
+	if value := jProps.Get(singleKey).MustString(); value != "" {
+		values = append([]string{value}, values...)
+	}
+	return values
+}
+
+// 端口范围为 * 时表示全部端口
+func formatRulePorts(ports []string) string {
+	if len(ports) == 0 || common.Contains(ports, "*") {
+		return "0-65535"
+	}
+	return strings.Join(ports, ",")
+}
+
+func getPrefixIPVersions(prefixes []string) (hasIPv4, hasIPv6 bool) {
+	for _, prefix := range prefixes {
+		if strings.Contains(prefix, ":") {
+			hasIPv6 = true
+		} else if strings.Contains(prefix, ".") {
+			hasIPv4 = true
+		}
+	}
+	return
+}
+
+// 服务标签（如 VirtualNetwork、AzureLoadBalancer）同时适用于 IPv4 及 IPv6，* 及 Internet 表示任意地址
+func formatRulePrefixes(prefixes []string, etherType int) string {
+	anyCIDR := common.SECURITY_GROUP_RULE_IPV4_CIDR
+	if etherType == common.SECURITY_GROUP_RULE_IPV6 {
+		anyCIDR = common.SECURITY_GROUP_RULE_IPV6_CIDR
+	}
+	var values []string
+	for _, prefix := range prefixes {
+		switch {
+		case prefix == "*" || prefix == "Internet":
+			return anyCIDR
+		case strings.Contains(prefix, ":"):
+			if etherType == common.SECURITY_GROUP_RULE_IPV6 {
+				values = append(values, prefix)
+			}
+		case strings.Contains(prefix, "."):
+			if etherType == common.SECURITY_GROUP_RULE_IPV4 {
+				values = append(values, prefix)
+			}
+		default:
+			values = append(values, prefix)
+		}
+	}
+	return strings.Join(values, ",")
+}
@@ -0,0 +1,92 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package azure
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/bitly/go-simplejson"
+
+	"github.com/deepflowio/deepflow/server/controller/cloud/model"
+	"github.com/deepflowio/deepflow/server/controller/common"
+)
+
+// AKS 集群作为附属容器集群同步
+func (a *Azure) getSubDomains(subscription Subscription) ([]model.SubDomain, error) {
+	log.Debug("get sub_domains starting")
+	var subDomains []model.SubDomain
+
+	jClusters, err := a.getRawData("managedClusters", fmt.Sprintf("/subscriptions/%s/providers/Microsoft.ContainerService/managedClusters", subscription.id), API_VERSION_AKS)
+	if err != nil {
+		return nil, err
+	}
+	for i := range jClusters {
+		jc := jClusters[i]
+		if !a.checkResource("sub_domain", jc) {
+			continue
+		}
+		clusterID := strings.ToLower(jc.Get("id").MustString())
+		name := jc.Get("name").MustString()
+		vpcLcuuid := a.getClusterVPCLcuuid(jc)
+		if vpcLcuuid == "" {
+			log.Infof("cluster (%s) vpc not found", clusterID)
+			continue
+		}
+		config := map[string]interface{}{
+			"cluster_id":                 name,
+			"region_uuid":                a.getRegionLcuuid(jc.Get("location").MustString()),
+			"vpc_uuid":                   vpcLcuuid,
+			"port_name_regex":            common.DEFAULT_PORT_NAME_REGEX,
+			"pod_net_ipv4_cidr_max_mask": common.K8S_POD_IPV4_NETMASK,
+			"pod_net_ipv6_cidr_max_mask": common.K8S_POD_IPV6_NETMASK,
+		}
+		configJson, _ := json.Marshal(config)
+		subDomains = append(subDomains, model.SubDomain{
+			Lcuuid:      a.getLcuuid(clusterID),
+			Name:        name,
+			DisplayName: name,
+			ClusterID:   name,
+			VpcUUID:     vpcLcuuid,
+			Config:      string(configJson),
+		})
+	}
+	log.Debug("get sub_domains complete")
+	return subDomains, nil
+}
+
+// 节点池使用自定义子网时为子网所在虚拟网络，否则为节点资源组中自动创建的虚拟网络
+func (a *Azure) getClusterVPCLcuuid(jCluster *simplejson.Json) string {
+	jPools := jCluster.GetPath("properties", "agentPoolProfiles")
+	for i := range jPools.MustArray() {
+		subnetID := strings.ToLower(jPools.GetIndex(i).Get("vnetSubnetID").MustString())
+		if subnet, ok := a.toolDataSet.subnetIDToSubnet[subnetID]; ok {
+			return subnet.vpcLcuuid
+		}
+	}
+	nodeResourceGroup := strings.ToLower(jCluster.GetPath("properties", "nodeResourceGroup").MustString())
+	if nodeResourceGroup == "" {
+		return ""
+	}
+	for _, vnetID := range a.toolDataSet.vnetIDs {
+		if getResourceGroup(vnetID) == nodeResourceGroup {
+			return a.toolDataSet.vnetIDToVPCLcuuid[vnetID]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,100 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package azure
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 虚拟机实例元数据服务中获取托管标识令牌的地址
+var IMDS_TOKEN_URL = "http://169.254.169.254/metadata/identity/oauth2/token"
+
+type Token struct {
+	accessToken string
+	expiresAt   time.Time
+}
+
+// 预留 5 分钟，避免请求过程中令牌过期
+func (t *Token) isExpired() bool {
+	return time.Now().Add(5 * time.Minute).After(t.expiresAt)
+}
+
+func (a *Azure) getToken() (*Token, error) {
+	if a.token != nil && !a.token.isExpired() {
+		return a.token, nil
+	}
+	token, err := a.createToken()
+	if err != nil {
+		return nil, err
+	}
+	a.token = token
+	return token, nil
+}
+
+func (a *Azure) createToken() (*Token, error) {
+	var req *http.Request
+	var err error
+	if a.config.AuthType == AUTH_TYPE_MANAGED_IDENTITY {
+		params := url.Values{}
+		params.Set("api-version", "2018-02-01")
+		params.Set("resource", a.config.ManagementURL+"/")
+		if a.config.ClientID != "" {
+			params.Set("client_id", a.config.ClientID)
+		}
+		req, err = http.NewRequest(http.MethodGet, IMDS_TOKEN_URL+"?"+params.Encode(), nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Metadata", "true")
+	} else {
+		form := url.Values{}
+		form.Set("grant_type", "client_credentials")
+		form.Set("client_id", a.config.ClientID)
+		form.Set("client_secret", a.config.ClientSecret)
+		form.Set("scope", a.config.ManagementURL+"/.default")
+		tokenURL := fmt.Sprintf("%s/%s/oauth2/v2.0/token", a.config.LoginURL, a.config.TenantID)
+		req, err = http.NewRequest(http.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+
+	jResp, err := a.doRequest(req)
+	if err != nil {
+		log.Errorf("get azure token (%s) failed: %v", a.config.AuthType, err)
+		return nil, err
+	}
+	accessToken := jResp.Get("access_token").MustString()
+	if accessToken == "" {
+		return nil, fmt.Errorf("no access_token in response of %s", req.URL.Host)
+	}
+	// 实例元数据服务返回的 expires_in 为字符串
+	expiresIn, err := jResp.Get("expires_in").Int()
+	if err != nil {
+		expiresIn, _ = strconv.Atoi(jResp.Get("expires_in").MustString())
+	}
+	return &Token{
+		accessToken: accessToken,
+		expiresAt:   time.Now().Add(time.Duration(expiresIn) * time.Second),
+	}, nil
+}
@@ -0,0 +1,104 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package azure
+
+import (
+	"github.com/bitly/go-simplejson"
+	"inet.af/netaddr"
+
+	"github.com/deepflowio/deepflow/server/controller/cloud/model"
+)
+
+// 资源 ID 均为小写
+type ToolDataSet struct {
+	locationToDisplayName      map[string]string
+	regions                    []model.Region
+	regionLcuuidToExists       map[string]bool
+	azs                        []model.AZ
+	azLcuuidToExists           map[string]bool
+	vnetIDToVPCLcuuid          map[string]string
+	vpcLcuuidToRegionLcuuid    map[string]string
+	vnetIDToPeerings           map[string][]*simplejson.Json
+	vnetIDs                    []string
+	subnetIDToSubnet           map[string]*Subnet
+	publicIPIDToIP             map[string]string
+	nicIDToNIC                 map[string]*simplejson.Json
+	vmIDToLcuuid               map[string]string
+	vmLcuuidToRegionLcuuid     map[string]string
+	vmLcuuidToVPCLcuuid        map[string]string
+	vmLcuuidToSecurityGroupIDs map[string][]string
+	ipConfigIDToIPConfig       map[string]IPConfig
+	regionLcuuidToResourceNum  map[string]int
+	azLcuuidToResourceNum      map[string]int
+}
+
+func NewToolDataSet() *ToolDataSet {
+	return &ToolDataSet{
+		locationToDisplayName:      make(map[string]string),
+		regionLcuuidToExists:       make(map[string]bool),
+		azLcuuidToExists:           make(map[string]bool),
+		vnetIDToVPCLcuuid:          make(map[string]string),
+		vpcLcuuidToRegionLcuuid:    make(map[string]string),
+		vnetIDToPeerings:           make(map[string][]*simplejson.Json),
+		subnetIDToSubnet:           make(map[string]*Subnet),
+		publicIPIDToIP:             make(map[string]string),
+		nicIDToNIC:                 make(map[string]*simplejson.Json),
+		vmIDToLcuuid:               make(map[string]string),
+		vmLcuuidToRegionLcuuid:     make(map[string]string),
+		vmLcuuidToVPCLcuuid:        make(map[string]string),
+		vmLcuuidToSecurityGroupIDs: make(map[string][]string),
+		ipConfigIDToIPConfig:       make(map[string]IPConfig),
+		regionLcuuidToResourceNum:  make(map[string]int),
+		azLcuuidToResourceNum:      make(map[string]int),
+	}
+}
+
+type SubnetCIDR struct {
+	prefix netaddr.IPPrefix
+	lcuuid string
+}
+
+// 虚拟网络的子网，对应一个网络及其下的一个或多个网段（双栈子网）
+type Subnet struct {
+	networkLcuuid string
+	vpcLcuuid     string
+	regionLcuuid  string
+	nsgID         string
+	cidrs         []SubnetCIDR
+}
+
+// 根据 IP 查找所属网段，未找到时使用第一个网段
+func (s *Subnet) getSubnetLcuuid(ip string) string {
+	if len(s.cidrs) == 0 {
+		return ""
+	}
+	if netIP, err := netaddr.ParseIP(ip); err == nil {
+		for _, c := range s.cidrs {
+			if c.prefix.Contains(netIP) {
+				return c.lcuuid
+			}
+		}
+	}
+	return s.cidrs[0].lcuuid
+}
+
+// 网卡 IP 配置，负载均衡器后端池通过 IP 配置关联云服务器
+type IPConfig struct {
+	ip        string
+	vmLcuuid  string
+	vpcLcuuid string
+}
@@ -0,0 +1,160 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package azure
+
+import (
+	"strings"
+
+	"github.com/bitly/go-simplejson"
+
+	"github.com/deepflowio/deepflow/server/controller/cloud/model"
+	"github.com/deepflowio/deepflow/server/controller/common"
+)
+
+// 仅同步云服务器的网卡，公网 IP 生成 WAN 接口及 DNAT 规则
+func (a *Azure) getVInterfaces(nics []*simplejson.Json) ([]model.VInterface, []model.IP, []model.NATRule) {
+	log.Debug("get vinterfaces starting")
+	var vinterfaces []model.VInterface
+	var ips []model.IP
+	var natRules []model.NATRule
+
+	for _, jn := range nics {
+		nicID := strings.ToLower(jn.Get("id").MustString())
+		vmLcuuid, ok := a.toolDataSet.vmIDToLcuuid[strings.ToLower(jn.GetPath("properties", "virtualMachine", "id").MustString())]
+		if !ok {
+			log.Debugf("exclude vinterface: %s, vm not found", nicID)
+			continue
+		}
+		mac := formatMac(jn.GetPath("properties", "macAddress").MustString())
+		if mac == "" {
+			log.Debugf("exclude vinterface: %s, no mac", nicID)
+			continue
+		}
+		vpcLcuuid := a.toolDataSet.vmLcuuidToVPCLcuuid[vmLcuuid]
+		regionLcuuid := a.toolDataSet.vmLcuuidToRegionLcuuid[vmLcuuid]
+		vinterfaceLcuuid := a.getLcuuid(nicID)
+		a.addVMSecurityGroup(vmLcuuid, jn.GetPath("properties", "networkSecurityGroup", "id").MustString())
+
+		var networkLcuuid, wanVInterfaceLcuuid string
+		jIPConfigs := jn.GetPath("properties", "ipConfigurations")
+		for i := range jIPConfigs.MustArray() {
+			jc := jIPConfigs.GetIndex(i)
+			subnet, ok := a.toolDataSet.subnetIDToSubnet[strings.ToLower(jc.GetPath("properties", "subnet", "id").MustString())]
+			if !ok {
+				continue
+			}
+			// 同一网卡的 IP 配置只能属于同一虚拟网络，网卡所属网络以第一个 IP 配置为准
+			if networkLcuuid == "" {
+				networkLcuuid = subnet.networkLcuuid
+			}
+			a.addVMSecurityGroup(vmLcuuid, subnet.nsgID)
+
+			privateIP := jc.GetPath("properties", "privateIPAddress").MustString()
+			if privateIP == "" {
+				continue
+			}
+			ips = append(ips, model.IP{
+				Lcuuid:           common.GenerateUUID(vinterfaceLcuuid + privateIP),
+				VInterfaceLcuuid: vinterfaceLcuuid,
+				IP:               privateIP,
+				SubnetLcuuid:     subnet.getSubnetLcuuid(privateIP),
+				RegionLcuuid:     regionLcuuid,
+			})
+			a.toolDataSet.ipConfigIDToIPConfig[strings.ToLower(jc.Get("id").MustString())] = IPConfig{
+				ip:        privateIP,
+				vmLcuuid:  vmLcuuid,
+				vpcLcuuid: vpcLcuuid,
+			}
+
+			publicIP, ok := a.toolDataSet.publicIPIDToIP[strings.ToLower(jc.GetPath("properties", "publicIPAddress", "id").MustString())]
+			if !ok {
+				continue
+			}
+			if wanVInterfaceLcuuid == "" {
+				wanVInterfaceLcuuid = common.GenerateUUID(vinterfaceLcuuid)
+				vinterfaces = append(vinterfaces, model.VInterface{
+					Lcuuid:        wanVInterfaceLcuuid,
+					Type:          common.VIF_TYPE_WAN,
+					Mac:           "ff" + mac[2:],
+					DeviceLcuuid:  vmLcuuid,
+					DeviceType:    common.VIF_DEVICE_TYPE_VM,
+					NetworkLcuuid: common.NETWORK_ISP_LCUUID,
+					VPCLcuuid:     vpcLcuuid,
+					RegionLcuuid:  regionLcuuid,
+				})
+			}
+			ips = append(ips, model.IP{
+				Lcuuid:           common.GenerateUUID(vinterfaceLcuuid + publicIP),
+				VInterfaceLcuuid: wanVInterfaceLcuuid,
+				IP:               publicIP,
+				RegionLcuuid:     regionLcuuid,
+			})
+			natRules = append(natRules, model.NATRule{
+				Lcuuid:           common.GenerateUUID(publicIP + vinterfaceLcuuid + privateIP),
+				Type:             "DNAT",
+				Protocol:         "ALL",
+				FloatingIP:       publicIP,
+				FixedIP:          privateIP,
+				VInterfaceLcuuid: vinterfaceLcuuid,
+			})
+		}
+		if networkLcuuid == "" {
+			log.Debugf("exclude vinterface: %s, network not found", nicID)
+			continue
+		}
+		vinterfaces = append(vinterfaces, model.VInterface{
+			Lcuuid:        vinterfaceLcuuid,
+			Name:          jn.Get("name").MustString(),
+			Type:          common.VIF_TYPE_LAN,
+			Mac:           mac,
+			DeviceLcuuid:  vmLcuuid,
+			DeviceType:    common.VIF_DEVICE_TYPE_VM,
+			NetworkLcuuid: networkLcuuid,
+			VPCLcuuid:     vpcLcuuid,
+			RegionLcuuid:  regionLcuuid,
+		})
+	}
+	log.Debug("get vinterfaces complete")
+	return vinterfaces, ips, natRules
+}
+
+// 网卡安全组优先于子网安全组
+func (a *Azure) addVMSecurityGroup(vmLcuuid, nsgID string) {
+	if nsgID == "" {
+		return
+	}
+	nsgID = strings.ToLower(nsgID)
+	if !common.Contains(a.toolDataSet.vmLcuuidToSecurityGroupIDs[vmLcuuid], nsgID) {
+		a.toolDataSet.vmLcuuidToSecurityGroupIDs[vmLcuuid] = append(a.toolDataSet.vmLcuuidToSecurityGroupIDs[vmLcuuid], nsgID)
+	}
+}
+
+// MAC 地址格式为 00-0D-3A-12-34-56，转换为 00:0d:3a:12:34:56
+func formatMac(mac string) string {
+	mac = strings.ToLower(strings.ReplaceAll(mac, "-", ":"))
+	if len(mac) == 12 && !strings.Contains(mac, ":") {
+		parts := make([]string, 0, 6)
+		for i := 0; i < 12; i += 2 {
+			parts = append(parts, mac[i:i+2])
+		}
+		mac = strings.Join(parts, ":")
+	}
+	if len(mac) != 17 {
+		return ""
+	}
+	return mac
+}
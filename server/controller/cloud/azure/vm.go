@@ -0,0 +1,219 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package azure
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bitly/go-simplejson"
+
+	"github.com/deepflowio/deepflow/server/controller/cloud/model"
+	"github.com/deepflowio/deepflow/server/controller/common"
+)
+
+// 电源状态，转换中的状态按照目标状态处理
+var POWER_STATE_CONVERTION = map[string]int{
+	"PowerState/running":      common.VM_STATE_RUNNING,
+	"PowerState/starting":     common.VM_STATE_RUNNING,
+	"PowerState/stopped":      common.VM_STATE_STOPPED,
+	"PowerState/stopping":     common.VM_STATE_STOPPED,
+	"PowerState/deallocated":  common.VM_STATE_STOPPED,
+	"PowerState/deallocating": common.VM_STATE_STOPPED,
+}
+
+func (a *Azure) getNetworkInterfaces(subscription Subscription) ([]*simplejson.Json, error) {
+	jNICs, err := a.getRawData("networkInterfaces", fmt.Sprintf("/subscriptions/%s/providers/Microsoft.Network/networkInterfaces", subscription.id), API_VERSION_NETWORK)
+	if err != nil {
+		return nil, err
+	}
+	var nics []*simplejson.Json
+	for i := range jNICs {
+		jn := jNICs[i]
+		if !a.checkResource("vinterface", jn) {
+			continue
+		}
+		a.toolDataSet.nicIDToNIC[strings.ToLower(jn.Get("id").MustString())] = jn
+		nics = append(nics, jn)
+	}
+	return nics, nil
+}
+
+// 虚拟机规模集（统一模式）的实例及网卡不在虚拟机及网卡列表中返回，需要单独获取
+func (a *Azure) getVMs(subscription Subscription) ([]model.VM, []*simplejson.Json, error) {
+	log.Debug("get vms starting")
+	var vms []model.VM
+	var vmssNICs []*simplejson.Json
+
+	jVMs, err := a.getRawData("virtualMachines", fmt.Sprintf("/subscriptions/%s/providers/Microsoft.Compute/virtualMachines?statusOnly=true", subscription.id), API_VERSION_COMPUTE)
+	if err != nil {
+		return nil, nil, err
+	}
+	for i := range jVMs {
+		jv := jVMs[i]
+		if !a.checkResource("vm", jv) {
+			continue
+		}
+		if vm, ok := a.formatVM(jv, jv.Get("tags")); ok {
+			vms = append(vms, vm)
+		}
+	}
+
+	jVMSSs, err := a.getRawData("virtualMachineScaleSets", fmt.Sprintf("/subscriptions/%s/providers/Microsoft.Compute/virtualMachineScaleSets", subscription.id), API_VERSION_COMPUTE)
+	if err != nil {
+		return nil, nil, err
+	}
+	for i := range jVMSSs {
+		jvmss := jVMSSs[i]
+		if !a.checkResource("vmss", jvmss) {
+			continue
+		}
+		// 灵活模式的实例与普通虚拟机相同，已在虚拟机列表中返回
+		if jvmss.GetPath("properties", "orchestrationMode").MustString() == "Flexible" {
+			continue
+		}
+		vmssID := jvmss.Get("id").MustString()
+		jNICs, err := a.getRawData("vmssNetworkInterfaces", vmssID+"/networkInterfaces", API_VERSION_VMSS_NETWORK)
+		if err != nil {
+			return nil, nil, err
+		}
+		for j := range jNICs {
+			a.toolDataSet.nicIDToNIC[strings.ToLower(jNICs[j].Get("id").MustString())] = jNICs[j]
+		}
+		vmssNICs = append(vmssNICs, jNICs...)
+
+		jInstances, err := a.getRawData("vmssVirtualMachines", vmssID+"/virtualMachines?$expand=instanceView", API_VERSION_COMPUTE)
+		if err != nil {
+			return nil, nil, err
+		}
+		for j := range jInstances {
+			ji := jInstances[j]
+			if _, ok := ji.CheckGet("location"); !ok {
+				ji.Set("location", jvmss.Get("location").MustString())
+			}
+			if vm, ok := a.formatVM(ji, jvmss.Get("tags")); ok {
+				vms = append(vms, vm)
+			}
+		}
+	}
+	log.Debug("get vms complete")
+	return vms, vmssNICs, nil
+}
+
+func (a *Azure) formatVM(jVM, jTags *simplejson.Json) (model.VM, bool) {
+	id := strings.ToLower(jVM.Get("id").MustString())
+	name := jVM.Get("name").MustString()
+	location := jVM.Get("location").MustString()
+
+	vpcLcuuid := a.getVMVPCLcuuid(jVM)
+	if vpcLcuuid == "" {
+		log.Infof("exclude vm: %s, vpc not found", id)
+		return model.VM{}, false
+	}
+
+	lcuuid := strings.ToLower(jVM.GetPath("properties", "vmId").MustString())
+	if lcuuid == "" {
+		lcuuid = a.getLcuuid(id)
+	}
+	state := common.VM_STATE_EXCEPTION
+	jStatuses := jVM.GetPath("properties", "instanceView", "statuses")
+	for i := range jStatuses.MustArray() {
+		if s, ok := POWER_STATE_CONVERTION[jStatuses.GetIndex(i).Get("code").MustString()]; ok {
+			state = s
+			break
+		}
+	}
+	createdAt, _ := time.Parse(time.RFC3339, jVM.GetPath("properties", "timeCreated").MustString())
+	zone := ""
+	if zones := jVM.Get("zones").MustStringArray(); len(zones) > 0 {
+		zone = zones[0]
+	}
+	azLcuuid := a.getAZLcuuid(location, zone)
+	regionLcuuid := a.getRegionLcuuid(location)
+
+	a.toolDataSet.vmIDToLcuuid[id] = lcuuid
+	a.toolDataSet.vmLcuuidToRegionLcuuid[lcuuid] = regionLcuuid
+	a.toolDataSet.vmLcuuidToVPCLcuuid[lcuuid] = vpcLcuuid
+	a.toolDataSet.azLcuuidToResourceNum[azLcuuid]++
+	a.toolDataSet.regionLcuuidToResourceNum[regionLcuuid]++
+	return model.VM{
+		Lcuuid:       lcuuid,
+		Name:         name,
+		Label:        lcuuid,
+		HType:        common.VM_HTYPE_VM_C,
+		State:        state,
+		CloudTags:    formatCloudTags(jTags),
+		CreatedAt:    createdAt,
+		VPCLcuuid:    vpcLcuuid,
+		AZLcuuid:     azLcuuid,
+		RegionLcuuid: regionLcuuid,
+	}, true
+}
+
+// 云服务器所属 VPC 为主网卡主 IP 配置所在子网的虚拟网络
+func (a *Azure) getVMVPCLcuuid(jVM *simplejson.Json) string {
+	jNICRefs := jVM.GetPath("properties", "networkProfile", "networkInterfaces")
+	nicID := ""
+	for i := range jNICRefs.MustArray() {
+		jr := jNICRefs.GetIndex(i)
+		if i == 0 || jr.GetPath("properties", "primary").MustBool() {
+			nicID = strings.ToLower(jr.Get("id").MustString())
+		}
+	}
+	jNIC, ok := a.toolDataSet.nicIDToNIC[nicID]
+	if !ok {
+		return ""
+	}
+	jIPConfig := getPrimaryIPConfig(jNIC)
+	if jIPConfig == nil {
+		return ""
+	}
+	subnet, ok := a.toolDataSet.subnetIDToSubnet[strings.ToLower(jIPConfig.GetPath("properties", "subnet", "id").MustString())]
+	if !ok {
+		return ""
+	}
+	return subnet.vpcLcuuid
+}
+
+func getPrimaryIPConfig(jNIC *simplejson.Json) *simplejson.Json {
+	jIPConfigs := jNIC.GetPath("properties", "ipConfigurations")
+	var primary *simplejson.Json
+	for i := range jIPConfigs.MustArray() {
+		jc := jIPConfigs.GetIndex(i)
+		if i == 0 || jc.GetPath("properties", "primary").MustBool() {
+			primary = jc
+		}
+	}
+	return primary
+}
+
+// 标签格式为 k1:v1, k2:v2
+func formatCloudTags(jTags *simplejson.Json) string {
+	tags := jTags.MustMap()
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	cloudTags := make([]string, 0, len(keys))
+	for _, k := range keys {
+		cloudTags = append(cloudTags, fmt.Sprintf("%s:%v", k, tags[k]))
+	}
+	return strings.Join(cloudTags, ", ")
+}
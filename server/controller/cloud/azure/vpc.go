@@ -0,0 +1,185 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package azure
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"inet.af/netaddr"
+
+	cloudcommon "github.com/deepflowio/deepflow/server/controller/cloud/common"
+	"github.com/deepflowio/deepflow/server/controller/cloud/model"
+	"github.com/deepflowio/deepflow/server/controller/common"
+)
+
+// 虚拟网络对应 VPC，其下的子网对应网络
+func (a *Azure) getVPCs(subscription Subscription) ([]model.VPC, []model.Network, []model.Subnet, error) {
+	log.Debug("get vpcs starting")
+	var vpcs []model.VPC
+	var networks []model.Network
+	var subnets []model.Subnet
+
+	jVNets, err := a.getRawData("virtualNetworks", fmt.Sprintf("/subscriptions/%s/providers/Microsoft.Network/virtualNetworks", subscription.id), API_VERSION_NETWORK)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	for i := range jVNets {
+		jv := jVNets[i]
+		if !a.checkResource("vpc", jv) {
+			continue
+		}
+		vnetID := strings.ToLower(jv.Get("id").MustString())
+		vpcLcuuid := a.getLcuuid(vnetID)
+		regionLcuuid := a.getRegionLcuuid(jv.Get("location").MustString())
+		vpcs = append(vpcs, model.VPC{
+			Lcuuid:       vpcLcuuid,
+			Name:         jv.Get("name").MustString(),
+			Label:        jv.GetPath("properties", "resourceGuid").MustString(),
+			CIDR:         strings.Join(jv.GetPath("properties", "addressSpace", "addressPrefixes").MustStringArray(), ","),
+			RegionLcuuid: regionLcuuid,
+		})
+		a.toolDataSet.vnetIDToVPCLcuuid[vnetID] = vpcLcuuid
+		a.toolDataSet.vpcLcuuidToRegionLcuuid[vpcLcuuid] = regionLcuuid
+		a.toolDataSet.vnetIDs = append(a.toolDataSet.vnetIDs, vnetID)
+		a.toolDataSet.regionLcuuidToResourceNum[regionLcuuid]++
+
+		jPeerings := jv.GetPath("properties", "virtualNetworkPeerings")
+		for j := range jPeerings.MustArray() {
+			a.toolDataSet.vnetIDToPeerings[vnetID] = append(a.toolDataSet.vnetIDToPeerings[vnetID], jPeerings.GetIndex(j))
+		}
+
+		jSubnets := jv.GetPath("properties", "subnets")
+		for j := range jSubnets.MustArray() {
+			js := jSubnets.GetIndex(j)
+			if !cloudcommon.CheckJsonAttributes(js, []string{"id", "name"}) {
+				continue
+			}
+			subnetID := strings.ToLower(js.Get("id").MustString())
+			networkLcuuid := a.getLcuuid(subnetID)
+			name := js.Get("name").MustString()
+			networks = append(networks, model.Network{
+				Lcuuid:         networkLcuuid,
+				Name:           name,
+				SegmentationID: 1,
+				VPCLcuuid:      vpcLcuuid,
+				NetType:        common.NETWORK_TYPE_LAN,
+				RegionLcuuid:   regionLcuuid,
+			})
+
+			subnet := &Subnet{
+				networkLcuuid: networkLcuuid,
+				vpcLcuuid:     vpcLcuuid,
+				regionLcuuid:  regionLcuuid,
+				nsgID:         strings.ToLower(js.GetPath("properties", "networkSecurityGroup", "id").MustString()),
+			}
+			// 双栈子网使用 addressPrefixes 返回多个网段
+			cidrs := js.GetPath("properties", "addressPrefixes").MustStringArray()
+			if prefix := js.GetPath("properties", "addressPrefix").MustString(); prefix != "" {
+				cidrs = append([]string{prefix}, cidrs...)
+			}
+			for _, cidr := range cidrs {
+				prefix, err := netaddr.ParseIPPrefix(cidr)
+				if err != nil || subnet.hasCIDR(prefix) {
+					continue
+				}
+				subnetLcuuid := common.GenerateUUID(networkLcuuid + cidr)
+				if len(subnet.cidrs) == 0 {
+					subnetLcuuid = common.GenerateUUID(networkLcuuid)
+				}
+				subnet.cidrs = append(subnet.cidrs, SubnetCIDR{prefix: prefix, lcuuid: subnetLcuuid})
+				subnets = append(subnets, model.Subnet{
+					Lcuuid:        subnetLcuuid,
+					Name:          name,
+					CIDR:          cidr,
+					VPCLcuuid:     vpcLcuuid,
+					NetworkLcuuid: networkLcuuid,
+				})
+			}
+			a.toolDataSet.subnetIDToSubnet[subnetID] = subnet
+		}
+	}
+	log.Debug("get vpcs complete")
+	return vpcs, networks, subnets, nil
+}
+
+func (s *Subnet) hasCIDR(prefix netaddr.IPPrefix) bool {
+	for _, c := range s.cidrs {
+		if c.prefix == prefix {
+			return true
+		}
+	}
+	return false
+}
+
+// 对等连接在两端的虚拟网络中各有一条记录，两端虚拟网络均同步时才生成
+func (a *Azure) getPeerConnections() []model.PeerConnection {
+	log.Debug("get peer connections starting")
+	var peerConnections []model.PeerConnection
+
+	pairToExists := map[string]bool{}
+	for _, vnetID := range a.toolDataSet.vnetIDs {
+		for _, jp := range a.toolDataSet.vnetIDToPeerings[vnetID] {
+			if jp.GetPath("properties", "peeringState").MustString() != "Connected" {
+				continue
+			}
+			remoteVNetID := strings.ToLower(jp.GetPath("properties", "remoteVirtualNetwork", "id").MustString())
+			remoteVPCLcuuid, ok := a.toolDataSet.vnetIDToVPCLcuuid[remoteVNetID]
+			if !ok {
+				log.Debugf("peering (%s) remote vnet (%s) not found", jp.Get("id").MustString(), remoteVNetID)
+				continue
+			}
+			pair := []string{vnetID, remoteVNetID}
+			sort.Strings(pair)
+			pairKey := strings.Join(pair, ",")
+			if pairToExists[pairKey] {
+				continue
+			}
+			pairToExists[pairKey] = true
+
+			localVPCLcuuid := a.toolDataSet.vnetIDToVPCLcuuid[vnetID]
+			peerConnections = append(peerConnections, model.PeerConnection{
+				Lcuuid:             common.GenerateUUID(pairKey),
+				Name:               jp.Get("name").MustString(),
+				LocalVPCLcuuid:     localVPCLcuuid,
+				RemoteVPCLcuuid:    remoteVPCLcuuid,
+				LocalRegionLcuuid:  a.toolDataSet.vpcLcuuidToRegionLcuuid[localVPCLcuuid],
+				RemoteRegionLcuuid: a.toolDataSet.vpcLcuuidToRegionLcuuid[remoteVPCLcuuid],
+			})
+		}
+	}
+	log.Debug("get peer connections complete")
+	return peerConnections
+}
+
+// 公网 IP 仅用于补充网卡、负载均衡器及 NAT 网关的公网地址
+func (a *Azure) getPublicIPs(subscription Subscription) error {
+	jPublicIPs, err := a.getRawData("publicIPAddresses", fmt.Sprintf("/subscriptions/%s/providers/Microsoft.Network/publicIPAddresses", subscription.id), API_VERSION_NETWORK)
+	if err != nil {
+		return err
+	}
+	for i := range jPublicIPs {
+		jp := jPublicIPs[i]
+		ip := jp.GetPath("properties", "ipAddress").MustString()
+		if ip == "" {
+			continue
+		}
+		a.toolDataSet.publicIPIDToIP[strings.ToLower(jp.Get("id").MustString())] = ip
+	}
+	return nil
+}
@@ -116,9 +116,22 @@ func (c *Cloud) GetResource() model.Resource {
 		cResource = c.appendAddtionalResourcesData(cResource)
 		cResource = c.appendResourceProcess(cResource)
 	}
+	c.setSubDomainRevisions(cResource)
 	return cResource
 }
 
+// 附属容器集群的数据合并完成后再计算数据版本及变化
+func (c *Cloud) setSubDomainRevisions(resource model.Resource) {
+	for lcuuid, subDomainResource := range resource.SubDomainResources {
+		kubernetesGatherTask, ok := c.kubernetesGatherTaskMap[lcuuid]
+		if !ok || !subDomainResource.Verified {
+			continue
+		}
+		kubernetesGatherTask.setSubDomainRevision(&subDomainResource)
+		resource.SubDomainResources[lcuuid] = subDomainResource
+	}
+}
+
 func (c *Cloud) GetKubernetesGatherTaskMap() map[string]*KubernetesGatherTask {
 	return c.kubernetesGatherTaskMap
 }
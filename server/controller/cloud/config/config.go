@@ -21,6 +21,7 @@ var CONF *CloudConfig
 type CloudConfig struct {
 	CloudGatherInterval      uint32 `default:"30" yaml:"cloud_gather_interval"`
 	KubernetesGatherInterval uint32 `default:"30" yaml:"kubernetes_gather_interval"`
+	KubernetesResyncInterval uint32 `default:"600" yaml:"kubernetes_resync_interval"`
 	AliyunRegionName         string `default:"cn-beijing" yaml:"aliyun_region_name"`
 	AWSRegionName            string `default:"cn-north-1" yaml:"aws_region_name"`
 	GenesisDefaultVpcName    string `default:"default_vpc" yaml:"genesis_default_vpc"`
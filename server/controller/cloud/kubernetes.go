@@ -125,6 +125,7 @@ func (c *Cloud) getKubernetesData() model.Resource {
 		PodIngressRules:        kubernetesGatherResource.PodIngressRules,
 		PodIngressRuleBackends: kubernetesGatherResource.PodIngressRuleBackends,
 		PrometheusTargets:      kubernetesGatherResource.PrometheusTargets,
+		K8sEvents:              kubernetesGatherResource.K8sEvents,
 		IPs:                    ips,
 		VMs:                    vms,
 		Regions:                regions,
@@ -0,0 +1,120 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kubernetes_gather
+
+import (
+	"time"
+
+	"github.com/deepflowio/deepflow/server/controller/cloud/model"
+	"github.com/deepflowio/deepflow/server/controller/common"
+
+	"github.com/bitly/go-simplejson"
+	uuid "github.com/satori/go.uuid"
+)
+
+// 需在 getReplicaSetsAndReplicaSetControllers 之后调用，ReplicaSet 的事件关联到其所属的工作负载
+func (k *KubernetesGather) getK8sEvents() ([]model.K8sEvent, error) {
+	log.Debug("get k8s events starting")
+	k8sEvents := []model.K8sEvent{}
+	for _, e := range k.k8sInfo["*v1.Event"] {
+		eData, err := simplejson.NewJson([]byte(e))
+		if err != nil {
+			log.Errorf("k8s event initialization simplejson error: (%s)", err.Error())
+			return k8sEvents, err
+		}
+		metaData, ok := eData.CheckGet("metadata")
+		if !ok {
+			log.Info("k8s event metadata not found")
+			continue
+		}
+		uID := metaData.Get("uid").MustString()
+		if uID == "" {
+			log.Info("k8s event uid not found")
+			continue
+		}
+		reason := eData.Get("reason").MustString()
+		if reason == "" {
+			log.Debugf("k8s event (%s) reason not found", uID)
+			continue
+		}
+		involvedObject, ok := eData.CheckGet("involvedObject")
+		if !ok {
+			log.Infof("k8s event (%s) involved object not found", uID)
+			continue
+		}
+		kind := involvedObject.Get("kind").MustString()
+		objectName := involvedObject.Get("name").MustString()
+		if kind == "" || objectName == "" {
+			log.Infof("k8s event (%s) involved object kind or name not found", uID)
+			continue
+		}
+		lastTime, ok := getK8sEventTime(eData)
+		if !ok {
+			log.Infof("k8s event (%s) timestamp not found", uID)
+			continue
+		}
+		objectLcuuid := involvedObject.Get("uid").MustString()
+		if pgLcuuid, ok := k.rsLcuuidToPodGroupLcuuid[objectLcuuid]; ok {
+			objectLcuuid = pgLcuuid
+		} else if pgLcuuid, ok := k.jobLcuuidToPodGroupLcuuid[objectLcuuid]; ok {
+			objectLcuuid = pgLcuuid
+		}
+		namespace := involvedObject.Get("namespace").MustString()
+		if namespace == "" {
+			namespace = metaData.Get("namespace").MustString()
+		}
+		eventType := eData.Get("type").MustString()
+		if eventType == "" {
+			eventType = "Normal"
+		}
+		k8sEvents = append(k8sEvents, model.K8sEvent{
+			Lcuuid:               uID,
+			Type:                 eventType,
+			Reason:               reason,
+			Message:              eData.Get("message").MustString(),
+			Count:                eData.Get("count").MustInt(1),
+			InvolvedObjectKind:   kind,
+			InvolvedObjectName:   objectName,
+			InvolvedObjectLcuuid: objectLcuuid,
+			PodNamespaceLcuuid:   k.namespaceToLcuuid[namespace],
+			PodClusterLcuuid:     common.GetUUID(k.UuidGenerate, uuid.Nil),
+			VPCLcuuid:            k.VPCUuid,
+			AZLcuuid:             k.azLcuuid,
+			RegionLcuuid:         k.RegionUuid,
+			LastTime:             lastTime,
+		})
+	}
+	log.Debug("get k8s events complete")
+	return k8sEvents, nil
+}
+
+// lastTimestamp 为秒级精度，新版本的事件可能只有 eventTime
+func getK8sEventTime(eData *simplejson.Json) (time.Time, bool) {
+	for _, key := range []string{"lastTimestamp", "eventTime", "firstTimestamp"} {
+		timeString := eData.Get(key).MustString()
+		if timeString == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, timeString)
+		if err != nil {
+			log.Debugf("k8s event %s (%s) parse error: (%s)", key, timeString, err.Error())
+			continue
+		}
+		return t, true
+	}
+	return time.Time{}, false
+}
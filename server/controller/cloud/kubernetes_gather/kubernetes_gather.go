@@ -19,12 +19,15 @@ package kubernetes_gather
 import (
 	"github.com/deepflowio/deepflow/server/controller/cloud/config"
 	"github.com/deepflowio/deepflow/server/controller/cloud/kubernetes_gather/model"
+	cloudmodel "github.com/deepflowio/deepflow/server/controller/cloud/model"
 	"github.com/deepflowio/deepflow/server/controller/common"
 	"github.com/deepflowio/deepflow/server/controller/db/mysql"
 	"github.com/deepflowio/deepflow/server/controller/genesis"
 	"github.com/deepflowio/deepflow/server/controller/statsd"
 
+	"reflect"
 	"regexp"
+	"time"
 
 	simplejson "github.com/bitly/go-simplejson"
 	mapset "github.com/deckarep/golang-set"
@@ -49,6 +52,7 @@ type KubernetesGather struct {
 	PodNetIPv4CIDRMaxMask        int
 	PodNetIPv6CIDRMaxMask        int
 	customTagLenMax              int
+	resyncInterval               time.Duration
 	isSubDomain                  bool
 	azLcuuid                     string
 	podGroupLcuuids              mapset.Set
@@ -58,6 +62,7 @@ type KubernetesGather struct {
 	nodeIPToLcuuid               map[string]string
 	namespaceToLcuuid            map[string]string
 	rsLcuuidToPodGroupLcuuid     map[string]string
+	jobLcuuidToPodGroupLcuuid    map[string]string
 	serviceLcuuidToIngressLcuuid map[string]string
 	k8sInfo                      map[string][]string
 	nsLabelToGroupLcuuids        map[string]mapset.Set
@@ -65,6 +70,15 @@ type KubernetesGather struct {
 	namespaceToExLabels          map[string]map[string]interface{}
 	nsServiceNameToService       map[string]map[string]map[string]int
 	cloudStatsd                  statsd.CloudStatsd
+
+	// kubernetes数据未变化时复用上次构建的结果；只有 pod 及 event 变化时，
+	// 在上次构建的结果上只处理变化的 pod，其他资源变化时全量重新构建
+	k8sVersion  uint64
+	resyncAt    time.Time // 上次全量构建的时间
+	resource    model.KubernetesGatherResource
+	lastK8sInfo map[string][]string
+	podNodes    []cloudmodel.PodNode // 上次全量构建的容器节点，不包含 pod 抽象出的节点
+	podEntries  map[string]*podEntry // key: pod原始数据
 }
 
 // 使用结构体代替python中的元组
@@ -150,6 +164,7 @@ func NewKubernetesGather(domain *mysql.Domain, subDomain *mysql.SubDomain, cfg c
 		// 以下属性为获取资源所用的关联关系
 		azLcuuid:                     "",
 		customTagLenMax:              cfg.CustomTagLenMax,
+		resyncInterval:               time.Duration(cfg.KubernetesResyncInterval) * time.Second,
 		isSubDomain:                  isSubDomain,
 		podGroupLcuuids:              mapset.NewSet(),
 		nodeNetworkLcuuidCIDRs:       networkLcuuidCIDRs{},
@@ -158,6 +173,7 @@ func NewKubernetesGather(domain *mysql.Domain, subDomain *mysql.SubDomain, cfg c
 		nodeIPToLcuuid:               map[string]string{},
 		namespaceToLcuuid:            map[string]string{},
 		rsLcuuidToPodGroupLcuuid:     map[string]string{},
+		jobLcuuidToPodGroupLcuuid:    map[string]string{},
 		serviceLcuuidToIngressLcuuid: map[string]string{},
 		k8sInfo:                      map[string][]string{},
 		nsLabelToGroupLcuuids:        map[string]mapset.Set{},
@@ -172,10 +188,18 @@ func NewKubernetesGather(domain *mysql.Domain, subDomain *mysql.SubDomain, cfg c
 	}
 }
 
-func (k *KubernetesGather) getKubernetesInfo() (map[string][]string, error) {
-	kData, err := genesis.GenesisService.GetKubernetesResponse(k.ClusterID)
+// 返回数据的version，数据未变化且无需重新构建时返回的数据为空
+func (k *KubernetesGather) getKubernetesInfo() (map[string][]string, uint64, error) {
+	var lastVersion uint64
+	if time.Since(k.resyncAt) < k.resyncInterval {
+		lastVersion = k.k8sVersion
+	}
+	kData, version, err := genesis.GenesisService.GetKubernetesResponseWithVersion(k.ClusterID, lastVersion)
 	if err != nil {
-		return map[string][]string{}, err
+		return map[string][]string{}, 0, err
+	}
+	if lastVersion != 0 && version == lastVersion {
+		return kData, version, nil
 	}
 
 	for key, v := range kData {
@@ -185,7 +209,7 @@ func (k *KubernetesGather) getKubernetesInfo() (map[string][]string, error) {
 		k.cloudStatsd.APICount[key] = []int{len(v)}
 
 	}
-	return kData, nil
+	return kData, version, nil
 }
 
 func (k *KubernetesGather) GetStatter() statsd.StatsdStatter {
@@ -201,21 +225,27 @@ func (k *KubernetesGather) GetStatter() statsd.StatsdStatter {
 	}
 }
 
-func (k *KubernetesGather) GetKubernetesGatherData() (model.KubernetesGatherResource, error) {
-	// 任务循环的是同一个实例，所以这里要对关联关系进行初始化
-	k.azLcuuid = ""
-	k.k8sInfo = nil
+// 全量构建前初始化关联关系，增量构建时沿用上次全量构建的关联关系
+func (k *KubernetesGather) resetRelations() {
 	k.podNetworkLcuuidCIDRs = networkLcuuidCIDRs{}
 	k.nodeNetworkLcuuidCIDRs = networkLcuuidCIDRs{}
 	k.podGroupLcuuids = mapset.NewSet()
 	k.nodeIPToLcuuid = map[string]string{}
 	k.namespaceToLcuuid = map[string]string{}
 	k.rsLcuuidToPodGroupLcuuid = map[string]string{}
+	k.jobLcuuidToPodGroupLcuuid = map[string]string{}
 	k.serviceLcuuidToIngressLcuuid = map[string]string{}
 	k.nsLabelToGroupLcuuids = map[string]mapset.Set{}
 	k.pgLcuuidTopodTargetPorts = map[string]map[string]int{}
 	k.namespaceToExLabels = map[string]map[string]interface{}{}
 	k.nsServiceNameToService = map[string]map[string]map[string]int{}
+	k.podEntries = map[string]*podEntry{}
+}
+
+func (k *KubernetesGather) GetKubernetesGatherData() (model.KubernetesGatherResource, error) {
+	// 任务循环的是同一个实例，所以这里要对关联关系进行初始化
+	k.azLcuuid = ""
+	k.k8sInfo = nil
 	k.cloudStatsd.APICount = map[string][]int{}
 	k.cloudStatsd.APICost = map[string][]int{}
 	k.cloudStatsd.ResCount = map[string][]int{}
@@ -235,7 +265,7 @@ func (k *KubernetesGather) GetKubernetesGatherData() (model.KubernetesGatherReso
 		return model.KubernetesGatherResource{}, err
 	}
 
-	k8sInfo, err := k.getKubernetesInfo()
+	k8sInfo, k8sVersion, err := k.getKubernetesInfo()
 	if err != nil {
 		log.Warning(err.Error())
 		return model.KubernetesGatherResource{
@@ -250,6 +280,27 @@ func (k *KubernetesGather) GetKubernetesGatherData() (model.KubernetesGatherReso
 		return model.KubernetesGatherResource{}, err
 	}
 
+	if k.k8sVersion != 0 && k8sVersion == k.k8sVersion && len(k8sInfo) == 0 {
+		log.Debugf("kubernetes gather (%s) data version (%d) not changed", k.Name, k8sVersion)
+		resource := k.resource
+		if !reflect.DeepEqual(resource.PrometheusTargets, prometheusTargets) {
+			resource.PrometheusTargets = prometheusTargets
+			resource.Revision++
+		}
+		return k.completeResource(resource), nil
+	}
+
+	if k.canBuildIncrementally(k8sInfo, region, az, vpc) {
+		resource, err := k.buildIncrementally(prometheusTargets)
+		if err != nil {
+			return model.KubernetesGatherResource{}, err
+		}
+		k.k8sVersion = k8sVersion
+		k.lastK8sInfo = k8sInfo
+		return k.completeResource(resource), nil
+	}
+	k.resetRelations()
+
 	podCluster, err := k.getPodCluster()
 	if err != nil {
 		return model.KubernetesGatherResource{}, err
@@ -293,6 +344,14 @@ func (k *KubernetesGather) GetKubernetesGatherData() (model.KubernetesGatherReso
 	if err != nil {
 		return model.KubernetesGatherResource{}, err
 	}
+	routes, routeRules, routeRuleBackends, err := k.getPodGatewayRoutes()
+	if err != nil {
+		return model.KubernetesGatherResource{}, err
+	}
+	ingresses = append(ingresses, routes...)
+	ingressRules = append(ingressRules, routeRules...)
+	ingressRuleBackends = append(ingressRuleBackends, routeRuleBackends...)
+
 	for index, s := range podServices {
 		if ingressLcuuid, ok := k.serviceLcuuidToIngressLcuuid[s.Lcuuid]; ok {
 			podServices[index].PodIngressLcuuid = ingressLcuuid
@@ -303,13 +362,19 @@ func (k *KubernetesGather) GetKubernetesGatherData() (model.KubernetesGatherReso
 	if err != nil {
 		return model.KubernetesGatherResource{}, err
 	}
-	podNodes = append(podNodes, abstractNodes...)
+	k.podNodes = podNodes
+	podNodes = append(podNodes[:len(podNodes):len(podNodes)], abstractNodes...)
 
 	nodeSubnets, podSubnets, nodeVInterfaces, podVInterfaces, nodeIPs, podIPs, err := k.getVInterfacesAndIPs()
 	if err != nil {
 		return model.KubernetesGatherResource{}, err
 	}
 
+	k8sEvents, err := k.getK8sEvents()
+	if err != nil {
+		return model.KubernetesGatherResource{}, err
+	}
+
 	resource := model.KubernetesGatherResource{
 		Region:                 region,
 		AZ:                     az,
@@ -339,13 +404,113 @@ func (k *KubernetesGather) GetKubernetesGatherData() (model.KubernetesGatherReso
 		PodGroups:              podGroups,
 		Pods:                   pods,
 		PrometheusTargets:      prometheusTargets,
+		K8sEvents:              k8sEvents,
+		Revision:               k.resource.Revision + 1,
+	}
+	k.k8sVersion = k8sVersion
+	k.resyncAt = time.Now()
+	k.lastK8sInfo = k8sInfo
+	return k.completeResource(resource), nil
+}
+
+// 距上次全量构建未超过 resync 时间，且除 pod 及 event 外的资源均未变化时，可以增量构建；
+// 变化的 pod 不能参与 pod group 的构建
+func (k *KubernetesGather) canBuildIncrementally(k8sInfo map[string][]string, region cloudmodel.Region, az cloudmodel.AZ, vpc cloudmodel.VPC) bool {
+	if k.lastK8sInfo == nil || time.Since(k.resyncAt) >= k.resyncInterval {
+		return false
+	}
+	if !reflect.DeepEqual(region, k.resource.Region) || !reflect.DeepEqual(az, k.resource.AZ) || !reflect.DeepEqual(vpc, k.resource.VPC) {
+		return false
+	}
+	for _, info := range []map[string][]string{k8sInfo, k.lastK8sInfo} {
+		for eType := range info {
+			if eType == "*v1.Pod" || eType == "*v1.Event" {
+				continue
+			}
+			if !isSameEntries(k8sInfo[eType], k.lastK8sInfo[eType]) {
+				log.Debugf("kubernetes gather (%s) %s changed", k.Name, eType)
+				return false
+			}
+		}
+	}
+
+	currentPods := make(map[string]bool, len(k8sInfo["*v1.Pod"]))
+	for _, p := range k8sInfo["*v1.Pod"] {
+		currentPods[p] = true
+		if _, ok := k.podEntries[p]; ok {
+			continue
+		}
+		entry, err := k.parsePod(p)
+		if err != nil || entry.inPodGroups {
+			return false
+		}
+		k.podEntries[p] = entry
+	}
+	for p, entry := range k.podEntries {
+		if !currentPods[p] && entry.inPodGroups {
+			return false
+		}
+	}
+	return true
+}
+
+// 在上次构建的结果上更新 pod 及依赖 pod 的资源，其他资源沿用上次构建的结果
+func (k *KubernetesGather) buildIncrementally(prometheusTargets []cloudmodel.PrometheusTarget) (model.KubernetesGatherResource, error) {
+	log.Infof("kubernetes gather (%s) build incrementally", k.Name)
+	pods, abstractNodes, err := k.getPods()
+	if err != nil {
+		return model.KubernetesGatherResource{}, err
+	}
+
+	nodeSubnets, podSubnets, nodeVInterfaces, podVInterfaces, nodeIPs, podIPs, err := k.getVInterfacesAndIPs()
+	if err != nil {
+		return model.KubernetesGatherResource{}, err
+	}
+
+	k8sEvents, err := k.getK8sEvents()
+	if err != nil {
+		return model.KubernetesGatherResource{}, err
+	}
+
+	resource := k.resource
+	resource.PodNodes = append(k.podNodes[:len(k.podNodes):len(k.podNodes)], abstractNodes...)
+	resource.Pods = pods
+	resource.PodSubnets = podSubnets
+	resource.PodVInterfaces = podVInterfaces
+	resource.PodIPs = podIPs
+	resource.PodNodeSubnets = nodeSubnets
+	resource.PodNodeVInterfaces = nodeVInterfaces
+	resource.PodNodeIPs = nodeIPs
+	resource.PrometheusTargets = prometheusTargets
+	resource.K8sEvents = k8sEvents
+	resource.Revision++
+	return resource, nil
+}
+
+func isSameEntries(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	counts := make(map[string]int, len(a))
+	for _, e := range a {
+		counts[e]++
 	}
+	for _, e := range b {
+		if counts[e] == 0 {
+			return false
+		}
+		counts[e]--
+	}
+	return true
+}
 
+func (k *KubernetesGather) completeResource(resource model.KubernetesGatherResource) model.KubernetesGatherResource {
+	k.resource = resource
 	k.cloudStatsd.APICost["PrometheusTarget"] = []int{0}
-	k.cloudStatsd.APICount["PrometheusTarget"] = []int{len(prometheusTargets)}
+	k.cloudStatsd.APICount["PrometheusTarget"] = []int{len(resource.PrometheusTargets)}
 	k.cloudStatsd.ResCount = statsd.GetResCount(resource)
 	statsd.MetaStatsd.RegisterStatsdTable(k)
-	return resource, nil
+	return resource
 }
 
 func (k *KubernetesGather) CheckAuth() error {
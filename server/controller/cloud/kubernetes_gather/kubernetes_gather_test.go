@@ -24,6 +24,7 @@ import (
 	"testing"
 
 	"github.com/agiledragon/gomonkey/v2"
+	simplejson "github.com/bitly/go-simplejson"
 	. "github.com/smartystreets/goconvey/convey"
 
 	cloudconfig "github.com/deepflowio/deepflow/server/controller/cloud/config"
@@ -32,6 +33,8 @@ import (
 	"github.com/deepflowio/deepflow/server/controller/config"
 	"github.com/deepflowio/deepflow/server/controller/db/mysql"
 	"github.com/deepflowio/deepflow/server/controller/genesis"
+	"github.com/deepflowio/deepflow/server/controller/statsd"
+	statsdconfig "github.com/deepflowio/deepflow/server/controller/statsd/config"
 )
 
 func TestKubernetes(t *testing.T) {
@@ -123,3 +126,197 @@ func TestKubernetes(t *testing.T) {
 		})
 	})
 }
+
+func TestKubernetesWorkloadsAndGatewayRoutes(t *testing.T) {
+	Convey("TestKubernetesWorkloadsAndGatewayRoutes", t, func() {
+		k8s := NewKubernetesGather(&mysql.Domain{Name: "test_k8s", DisplayName: "test_k8s", Config: "{}"}, nil, cloudconfig.CloudConfig{}, false)
+
+		var kData struct {
+			Resources map[string][]string `json:"resources"`
+		}
+		kJsonData, _ := ioutil.ReadFile("./testfiles/kubernetes-gateway-job-info.json")
+		So(json.Unmarshal(kJsonData, &kData), ShouldBeNil)
+		k8s.k8sInfo = kData.Resources
+
+		_, err := k8s.getPodNamespaces()
+		So(err, ShouldBeNil)
+		podGroups, err := k8s.getPodGroups()
+		So(err, ShouldBeNil)
+		replicaSets, _, err := k8s.getReplicaSetsAndReplicaSetControllers()
+		So(err, ShouldBeNil)
+		_, _, _, _, _, _, _, err = k8s.getPodServices()
+		So(err, ShouldBeNil)
+		ingresses, ingressRules, ingressRuleBackends, err := k8s.getPodGatewayRoutes()
+		So(err, ShouldBeNil)
+		pods, _, err := k8s.getPods()
+		So(err, ShouldBeNil)
+
+		Convey("jobs, cronjobs and rollouts should be pod groups", func() {
+			pgTypes := map[string]int{}
+			for _, pg := range podGroups {
+				pgTypes[pg.Lcuuid] = pg.Type
+			}
+			So(pgTypes, ShouldResemble, map[string]int{
+				"rollout-uid-1": common.POD_GROUP_ROLLOUT,
+				"cronjob-uid-1": common.POD_GROUP_CRONJOB,
+				"job-uid-2":     common.POD_GROUP_JOB,
+			})
+			So(len(replicaSets), ShouldEqual, 1)
+			So(replicaSets[0].PodGroupLcuuid, ShouldEqual, "rollout-uid-1")
+
+			podToGroup := map[string]string{}
+			for _, pod := range pods {
+				podToGroup[pod.Lcuuid] = pod.PodGroupLcuuid
+			}
+			So(podToGroup, ShouldResemble, map[string]string{
+				"pod-uid-1": "rollout-uid-1",
+				"pod-uid-2": "cronjob-uid-1",
+				"pod-uid-3": "job-uid-2",
+			})
+		})
+
+		Convey("gateway routes should be ingresses", func() {
+			So(len(ingresses), ShouldEqual, 3)
+			So(len(ingressRules), ShouldEqual, 4)
+			So(len(ingressRuleBackends), ShouldEqual, 6)
+
+			ruleHosts := map[string][]string{}
+			for _, rule := range ingressRules {
+				ruleHosts[rule.PodIngressLcuuid] = append(ruleHosts[rule.PodIngressLcuuid], rule.Protocol+" "+rule.Host)
+			}
+			So(ruleHosts["httproute-uid-1"], ShouldResemble, []string{"HTTP api.example.com"})
+			So(ruleHosts["httproute-uid-2"], ShouldResemble, []string{"HTTP shop.example.com"})
+			So(ruleHosts["grpcroute-uid-1"], ShouldResemble, []string{"GRPC shop.example.com", "GRPC www.example.com"})
+
+			paths := []string{}
+			for _, backend := range ingressRuleBackends {
+				if backend.PodIngressLcuuid == "grpcroute-uid-1" {
+					So(backend.PodServiceLcuuid, ShouldEqual, "svc-uid-2")
+					So(backend.Port, ShouldEqual, 9090)
+					paths = append(paths, backend.Path)
+				}
+			}
+			So(paths, ShouldResemble, []string{"/shop.Cart/Get", "/shop.Cart/Get"})
+			So(k8s.serviceLcuuidToIngressLcuuid["svc-uid-1"], ShouldEqual, "httproute-uid-1")
+		})
+
+		Convey("events of replicasets and jobs should be related to pod groups", func() {
+			k8s.k8sInfo["*v1.Event"] = []string{
+				`{"metadata":{"uid":"event-uid-1","namespace":"shop"},"reason":"ScalingReplicaSet","type":"Normal","count":3,` +
+					`"involvedObject":{"kind":"ReplicaSet","name":"web-7d9c8b","uid":"rs-uid-1"},"lastTimestamp":"2023-06-01T10:00:00Z"}`,
+				`{"metadata":{"uid":"event-uid-2","namespace":"shop"},"reason":"Completed",` +
+					`"involvedObject":{"kind":"Job","name":"report-28190","uid":"job-uid-1"},"eventTime":"2023-06-01T10:00:01.123456Z"}`,
+				`{"metadata":{"uid":"event-uid-3","namespace":"shop"},"reason":"BackOff","type":"Warning",` +
+					`"involvedObject":{"kind":"Pod","name":"web-7d9c8b-abcde","uid":"pod-uid-1"},"firstTimestamp":"2023-06-01T10:00:02Z"}`,
+				`{"metadata":{"uid":"event-uid-4","namespace":"shop"},"reason":"NoTime",` +
+					`"involvedObject":{"kind":"Pod","name":"web-7d9c8b-abcde","uid":"pod-uid-1"}}`,
+			}
+			events, err := k8s.getK8sEvents()
+			So(err, ShouldBeNil)
+			So(len(events), ShouldEqual, 3)
+
+			So(events[0].InvolvedObjectLcuuid, ShouldEqual, "rollout-uid-1")
+			So(events[0].Count, ShouldEqual, 3)
+			So(events[0].PodNamespaceLcuuid, ShouldEqual, "ns-uid-1")
+			So(events[1].InvolvedObjectLcuuid, ShouldEqual, "cronjob-uid-1")
+			So(events[1].Type, ShouldEqual, "Normal")
+			So(events[1].Count, ShouldEqual, 1)
+			So(events[1].LastTime.UnixMilli(), ShouldEqual, 1685613601123)
+			So(events[2].InvolvedObjectLcuuid, ShouldEqual, "pod-uid-1")
+			So(events[2].LastTime.Unix(), ShouldEqual, 1685613602)
+		})
+	})
+}
+
+func TestKubernetesIncrementalBuild(t *testing.T) {
+	Convey("TestKubernetesIncrementalBuild", t, func() {
+		statsd.NewStatsdMonitor(statsdconfig.StatsdConfig{})
+		k8s := NewKubernetesGather(&mysql.Domain{
+			Name:        "test_k8s",
+			DisplayName: "test_k8s",
+			ClusterID:   "d-01LMvvfQPZ",
+			Config:      fmt.Sprintf(`{"region_uuid": "%s"}`, common.DEFAULT_REGION),
+		}, nil, cloudconfig.CloudConfig{KubernetesResyncInterval: 600}, false)
+
+		var kData struct {
+			Resources map[string][]string `json:"resources"`
+		}
+		kJsonData, _ := ioutil.ReadFile("./testfiles/kubernetes-info.json")
+		So(json.Unmarshal(kJsonData, &kData), ShouldBeNil)
+		k8sInfo := kData.Resources
+		k8sVersion := uint64(1)
+		k8sInfoPatch := gomonkey.ApplyPrivateMethod(reflect.TypeOf(k8s), "getKubernetesInfo", func(_ *KubernetesGather) (map[string][]string, uint64, error) {
+			return k8sInfo, k8sVersion, nil
+		})
+		defer k8sInfoPatch.Reset()
+
+		g := genesis.NewGenesis(&config.ControllerConfig{})
+		vJsonData, _ := ioutil.ReadFile("./testfiles/vinterfaces.json")
+		var vData genesis.GenesisSyncData
+		json.Unmarshal(vJsonData, &vData)
+		vinterfacesInfoPatch := gomonkey.ApplyMethod(reflect.TypeOf(g), "GetGenesisSyncResponse", func(_ *genesis.Genesis) (genesis.GenesisSyncData, error) {
+			return vData, nil
+		})
+		defer vinterfacesInfoPatch.Reset()
+		prometheusTargetInfoPatch := gomonkey.ApplyMethod(reflect.TypeOf(g), "GetPrometheusResponse", func(_ *genesis.Genesis, _ string) ([]model.PrometheusTarget, error) {
+			return []model.PrometheusTarget{}, nil
+		})
+		defer prometheusTargetInfoPatch.Reset()
+
+		full, err := k8s.GetKubernetesGatherData()
+		So(err, ShouldBeNil)
+		So(len(full.Pods), ShouldEqual, 15)
+		resyncAt := k8s.resyncAt
+		entries := map[string]*podEntry{}
+		for p, e := range k8s.podEntries {
+			entries[p] = e
+		}
+
+		// 修改一个 pod 的 label
+		pods := append([]string{}, k8sInfo["*v1.Pod"]...)
+		changedPod, _ := simplejson.NewJson([]byte(pods[4]))
+		changedPod.SetPath([]string{"metadata", "labels", "incremental"}, "true")
+		changedPodBytes, _ := changedPod.MarshalJSON()
+		pods[4] = string(changedPodBytes)
+		k8sInfo = map[string][]string{}
+		for key, value := range kData.Resources {
+			k8sInfo[key] = value
+		}
+		k8sInfo["*v1.Pod"] = pods
+		k8sVersion = 2
+
+		Convey("only the changed pod should be parsed again", func() {
+			incremental, err := k8s.GetKubernetesGatherData()
+			So(err, ShouldBeNil)
+			So(k8s.resyncAt.Equal(resyncAt), ShouldBeTrue)
+			So(incremental.Revision, ShouldEqual, full.Revision+1)
+			So(len(incremental.Pods), ShouldEqual, len(full.Pods))
+
+			reparsed := []string{}
+			for p, e := range k8s.podEntries {
+				if entries[p] != e {
+					reparsed = append(reparsed, p)
+				}
+			}
+			So(reparsed, ShouldResemble, []string{pods[4]})
+			for _, pod := range incremental.Pods {
+				if pod.Lcuuid == "9abe25c6-e19e-441a-a398-13dede30b062" {
+					So(pod.Label, ShouldContainSubstring, "incremental:true")
+				} else {
+					So(pod.Label, ShouldNotContainSubstring, "incremental")
+				}
+			}
+			So(incremental.PodGroups, ShouldResemble, full.PodGroups)
+			So(incremental.PodServices, ShouldResemble, full.PodServices)
+			So(incremental.PodNodes, ShouldResemble, full.PodNodes)
+			So(len(incremental.PodIPs), ShouldEqual, len(full.PodIPs))
+		})
+
+		Convey("changes of other resources should be built fully", func() {
+			k8sInfo["*v1.Service"] = k8sInfo["*v1.Service"][1:]
+			_, err := k8s.GetKubernetesGatherData()
+			So(err, ShouldBeNil)
+			So(k8s.resyncAt, ShouldHappenAfter, resyncAt)
+		})
+	})
+}
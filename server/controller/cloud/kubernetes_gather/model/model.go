@@ -24,6 +24,7 @@ import (
 type KubernetesGatherResource struct {
 	ErrorState             int
 	ErrorMessage           string
+	Revision               uint64 // 数据内容变化时递增
 	Region                 model.Region
 	AZ                     model.AZ
 	VPC                    model.VPC
@@ -52,6 +53,7 @@ type KubernetesGatherResource struct {
 	PodVInterfaces         []model.VInterface
 	PodIPs                 []model.IP
 	PrometheusTargets      []model.PrometheusTarget
+	K8sEvents              []model.K8sEvent
 }
 
 type KubernetesGatherBasicInfo struct {
@@ -30,199 +30,49 @@ import (
 	uuid "github.com/satori/go.uuid"
 )
 
+var podGroupTypes = map[string]bool{
+	"CloneSet":              false,
+	"DaemonSet":             false,
+	"Deployment":            false,
+	"InPlaceSet":            false,
+	"Job":                   false,
+	"ReplicaSet":            false,
+	"StatefulSet":           false,
+	"ReplicationController": false,
+}
+
+// pod 原始数据的解析结果，原始数据未变化时可直接复用
+type podEntry struct {
+	pod         *model.Pod // 为空表示该 pod 被忽略
+	ips         []string
+	inPodGroups bool // pod 是否参与 pod group 的构建，参见 getPodGroups
+}
+
+// 增量构建时，pod 依赖的其他资源均未变化，只解析原始数据变化的 pod
 func (k *KubernetesGather) getPods() (pods []model.Pod, nodes []model.PodNode, err error) {
 	log.Debug("get pods starting")
-	podTypesMap := map[string]bool{
-		"CloneSet":              false,
-		"DaemonSet":             false,
-		"Deployment":            false,
-		"InPlaceSet":            false,
-		"ReplicaSet":            false,
-		"StatefulSet":           false,
-		"ReplicationController": false,
-	}
 	abstractNodes := map[string]int{}
+	podEntries := make(map[string]*podEntry, len(k.k8sInfo["*v1.Pod"]))
 	for _, p := range k.k8sInfo["*v1.Pod"] {
-		pData, pErr := simplejson.NewJson([]byte(p))
-		if pErr != nil {
-			err = pErr
-			log.Errorf("pod initialization simplejson error: (%s)", pErr.Error())
-			return
-		}
-
-		envString := expand.GetPodENV(pData, k.customTagLenMax)
-
-		metaData, ok := pData.CheckGet("metadata")
+		entry, ok := k.podEntries[p]
 		if !ok {
-			log.Info("pod metadata not found")
-			continue
-		}
-		uID := metaData.Get("uid").MustString()
-		if uID == "" {
-			log.Info("pod uid not found")
-			continue
-		}
-		name := metaData.Get("name").MustString()
-		if name == "" {
-			log.Infof("pod (%s) name not found", uID)
-			continue
-		}
-		namespace := metaData.Get("namespace").MustString()
-		namespaceLcuuid, ok := k.namespaceToLcuuid[namespace]
-		if !ok {
-			log.Infof("pod (%s) namespace not found", name)
-			continue
-		}
-
-		podGroups := metaData.Get("ownerReferences")
-		if len(podGroups.MustArray()) == 0 {
-			providerType := metaData.Get("labels").Get("virtual-kubelet.io/provider-cluster-type").MustString()
-			if providerType != "serverless" && providerType != "proprietary" {
-				log.Debugf("pod (%s) type (%s) ownerReferences not found or sci cluster type not support", name, providerType)
-				continue
+			entry, err = k.parsePod(p)
+			if err != nil {
+				return
 			}
-			abstractPGType := metaData.Get("labels").Get("virtual-kubelet.io/provider-workload-type").MustString()
-			if abstractPGType == "" {
-				if _, ok := metaData.Get("labels").CheckGet("statefulset.kubernetes.io/pod-name"); ok {
-					abstractPGType = "StatefulSet"
-				} else {
-					abstractPGType = "Deployment"
-				}
-			}
-			resourceName := metaData.Get("labels").Get("virtual-kubelet.io/provider-resource-name").MustString()
-			if resourceName == "" {
-				log.Debugf("sci pod (%s) not found provider resource name", name)
-				continue
-			}
-			abstractPGName := resourceName
-			targetIndex := strings.LastIndex(resourceName, "-")
-			if targetIndex != -1 {
-				abstractPGName = resourceName[:targetIndex]
-			}
-			uid := common.GetUUID(namespace+abstractPGName, uuid.Nil)
-			// 适配 serverless pod, 需要抽象出一个对应的 node
-			podGroups, _ = simplejson.NewJson([]byte(fmt.Sprintf(`[{"uid": "%s","kind": "%s"}]`, uid, abstractPGType)))
-		}
-		ID := podGroups.GetIndex(0).Get("uid").MustString()
-		if ID == "" {
-			log.Infof("pod (%s) pod group not found", name)
-			continue
 		}
-		kind := podGroups.GetIndex(0).Get("kind").MustString()
-		if _, ok := podTypesMap[kind]; !ok {
-			log.Infof("pod group (%s) type (%s) not support", name, kind)
+		podEntries[p] = entry
+		if entry.pod == nil {
 			continue
 		}
-		hostIP := pData.Get("status").Get("hostIP").MustString()
-
-		podRSLcuuid := ""
-		podGroupLcuuid := ""
-		podLcuuid := ""
-		if gLcuuid, ok := k.rsLcuuidToPodGroupLcuuid[ID]; ok {
-			podRSLcuuid = ID
-			podGroupLcuuid = gLcuuid
-		} else {
-			if !k.podGroupLcuuids.Contains(ID) {
-				log.Debugf("pod (%s) pod group not found", name)
-				continue
-			}
-			podGroupLcuuid = ID
-		}
-		if kind == "StatefulSet" {
-			generate_name := metaData.Get("generate_name").MustString()
-			serialNumber := strings.TrimLeft(name, generate_name)
-			podLcuuid = common.GetUUID(ID+serialNumber, uuid.Nil)
-		} else {
-			podLcuuid = uID
-		}
-		conditions := pData.Get("status").Get("conditions")
-		conditionStatus := []string{}
-		for i := range conditions.MustArray() {
-			cData := conditions.GetIndex(i).MustMap()
-			cType := cData["type"].(string)
-			if cType == "Ready" {
-				cStatus := cData["status"].(string)
-				conditionStatus = append(conditionStatus, cStatus)
-			}
-		}
-		status := 0
-		if len(conditionStatus) != 0 && conditionStatus[0] == "True" {
-			status = common.POD_STATE_RUNNING
-		} else {
-			status = common.POD_STATE_EXCEPTION
-		}
-		var created time.Time
-		cTime := metaData.Get("creationTimestamp").MustString()
-		if cTime != "" {
-			localTime, err := time.Parse(time.RFC3339, cTime)
-			if err == nil {
-				created = localTime.Local()
-			}
-		}
-		labels := metaData.Get("labels").MustMap()
-		if exLabels, ok := k.namespaceToExLabels[namespace]; ok {
-			for exK, exV := range exLabels {
-				labels[exK] = exV
-			}
-		}
-		labelSlice := cloudcommon.StringInterfaceMapKVs(labels, ":", 0)
-		labelString := strings.Join(labelSlice, ", ")
-
-		annotations := metaData.Get("annotations")
-
-		annotationString := expand.GetAnnotation(annotations, k.customTagLenMax)
-
-		containerIDs := []string{}
-		containerStatuses := pData.GetPath("status", "containerStatuses")
-		for c := range containerStatuses.MustArray() {
-			containerID := containerStatuses.GetIndex(c).Get("containerID").MustString()
-			if containerID == "" {
-				continue
-			}
-			cIndex := strings.Index(containerID, "://")
-			if cIndex != -1 {
-				containerID = containerID[cIndex+3:]
-			}
-			containerIDs = append(containerIDs, containerID)
-		}
-		sort.Strings(containerIDs)
-
-		pod := model.Pod{
-			Lcuuid:              podLcuuid,
-			Name:                name,
-			State:               status,
-			VPCLcuuid:           k.VPCUuid,
-			ENV:                 envString,
-			Label:               labelString,
-			Annotation:          annotationString,
-			ContainerIDs:        strings.Join(containerIDs, ", "),
-			PodReplicaSetLcuuid: podRSLcuuid,
-			PodNodeLcuuid:       k.nodeIPToLcuuid[hostIP],
-			PodGroupLcuuid:      podGroupLcuuid,
-			PodNamespaceLcuuid:  namespaceLcuuid,
-			CreatedAt:           created,
-			AZLcuuid:            k.azLcuuid,
-			RegionLcuuid:        k.RegionUuid,
-			PodClusterLcuuid:    common.GetUUID(k.UuidGenerate, uuid.Nil),
-		}
-		pods = append(pods, pod)
-		podIP := pData.Get("status").Get("podIP").MustString()
-		podIPs := []string{podIP}
-		if podNetworks, ok := pData.Get("status").CheckGet("podNetworks"); ok {
-			for i := range podNetworks.MustArray() {
-				port := podNetworks.GetIndex(i).MustMap()
-				podIPs = append(podIPs, port["ip"].([]string)...)
-			}
-		}
-		for _, ip := range podIPs {
-			if ip == "" {
-				continue
-			}
+		pods = append(pods, *entry.pod)
+		for _, ip := range entry.ips {
 			if _, ok := k.nodeIPToLcuuid[ip]; !ok {
-				k.podIPToLcuuid[ip] = podLcuuid
+				k.podIPToLcuuid[ip] = entry.pod.Lcuuid
 			}
 		}
 	}
+	k.podEntries = podEntries
 
 	for nodeIP := range abstractNodes {
 		podClusterLcuuid := common.GetUUID(k.UuidGenerate, uuid.Nil)
@@ -242,3 +92,196 @@ func (k *KubernetesGather) getPods() (pods []model.Pod, nodes []model.PodNode, e
 	log.Debug("get pods complete")
 	return
 }
+
+func (k *KubernetesGather) parsePod(p string) (*podEntry, error) {
+	entry := &podEntry{}
+	pData, pErr := simplejson.NewJson([]byte(p))
+	if pErr != nil {
+		log.Errorf("pod initialization simplejson error: (%s)", pErr.Error())
+		return nil, pErr
+	}
+
+	envString := expand.GetPodENV(pData, k.customTagLenMax)
+
+	metaData, ok := pData.CheckGet("metadata")
+	if !ok {
+		log.Info("pod metadata not found")
+		return entry, nil
+	}
+	entry.inPodGroups = isPodInPodGroups(metaData)
+	uID := metaData.Get("uid").MustString()
+	if uID == "" {
+		log.Info("pod uid not found")
+		return entry, nil
+	}
+	name := metaData.Get("name").MustString()
+	if name == "" {
+		log.Infof("pod (%s) name not found", uID)
+		return entry, nil
+	}
+	namespace := metaData.Get("namespace").MustString()
+	namespaceLcuuid, ok := k.namespaceToLcuuid[namespace]
+	if !ok {
+		log.Infof("pod (%s) namespace not found", name)
+		return entry, nil
+	}
+
+	podGroups := metaData.Get("ownerReferences")
+	if len(podGroups.MustArray()) == 0 {
+		providerType := metaData.Get("labels").Get("virtual-kubelet.io/provider-cluster-type").MustString()
+		if providerType != "serverless" && providerType != "proprietary" {
+			log.Debugf("pod (%s) type (%s) ownerReferences not found or sci cluster type not support", name, providerType)
+			return entry, nil
+		}
+		abstractPGType := metaData.Get("labels").Get("virtual-kubelet.io/provider-workload-type").MustString()
+		if abstractPGType == "" {
+			if _, ok := metaData.Get("labels").CheckGet("statefulset.kubernetes.io/pod-name"); ok {
+				abstractPGType = "StatefulSet"
+			} else {
+				abstractPGType = "Deployment"
+			}
+		}
+		resourceName := metaData.Get("labels").Get("virtual-kubelet.io/provider-resource-name").MustString()
+		if resourceName == "" {
+			log.Debugf("sci pod (%s) not found provider resource name", name)
+			return entry, nil
+		}
+		abstractPGName := resourceName
+		targetIndex := strings.LastIndex(resourceName, "-")
+		if targetIndex != -1 {
+			abstractPGName = resourceName[:targetIndex]
+		}
+		uid := common.GetUUID(namespace+abstractPGName, uuid.Nil)
+		// 适配 serverless pod, 需要抽象出一个对应的 node
+		podGroups, _ = simplejson.NewJson([]byte(fmt.Sprintf(`[{"uid": "%s","kind": "%s"}]`, uid, abstractPGType)))
+	}
+	ID := podGroups.GetIndex(0).Get("uid").MustString()
+	if ID == "" {
+		log.Infof("pod (%s) pod group not found", name)
+		return entry, nil
+	}
+	kind := podGroups.GetIndex(0).Get("kind").MustString()
+	if _, ok := podGroupTypes[kind]; !ok {
+		log.Infof("pod group (%s) type (%s) not support", name, kind)
+		return entry, nil
+	}
+	hostIP := pData.Get("status").Get("hostIP").MustString()
+
+	podRSLcuuid := ""
+	podGroupLcuuid := ""
+	podLcuuid := ""
+	if gLcuuid, ok := k.rsLcuuidToPodGroupLcuuid[ID]; ok {
+		podRSLcuuid = ID
+		podGroupLcuuid = gLcuuid
+	} else if gLcuuid, ok := k.jobLcuuidToPodGroupLcuuid[ID]; ok {
+		podGroupLcuuid = gLcuuid
+	} else {
+		if !k.podGroupLcuuids.Contains(ID) {
+			log.Debugf("pod (%s) pod group not found", name)
+			return entry, nil
+		}
+		podGroupLcuuid = ID
+	}
+	if kind == "StatefulSet" {
+		generate_name := metaData.Get("generate_name").MustString()
+		serialNumber := strings.TrimLeft(name, generate_name)
+		podLcuuid = common.GetUUID(ID+serialNumber, uuid.Nil)
+	} else {
+		podLcuuid = uID
+	}
+	conditions := pData.Get("status").Get("conditions")
+	conditionStatus := []string{}
+	for i := range conditions.MustArray() {
+		cData := conditions.GetIndex(i).MustMap()
+		cType := cData["type"].(string)
+		if cType == "Ready" {
+			cStatus := cData["status"].(string)
+			conditionStatus = append(conditionStatus, cStatus)
+		}
+	}
+	status := 0
+	if len(conditionStatus) != 0 && conditionStatus[0] == "True" {
+		status = common.POD_STATE_RUNNING
+	} else {
+		status = common.POD_STATE_EXCEPTION
+	}
+	var created time.Time
+	cTime := metaData.Get("creationTimestamp").MustString()
+	if cTime != "" {
+		localTime, err := time.Parse(time.RFC3339, cTime)
+		if err == nil {
+			created = localTime.Local()
+		}
+	}
+	labels := metaData.Get("labels").MustMap()
+	if exLabels, ok := k.namespaceToExLabels[namespace]; ok {
+		for exK, exV := range exLabels {
+			labels[exK] = exV
+		}
+	}
+	labelSlice := cloudcommon.StringInterfaceMapKVs(labels, ":", 0)
+	labelString := strings.Join(labelSlice, ", ")
+
+	annotations := metaData.Get("annotations")
+
+	annotationString := expand.GetAnnotation(annotations, k.customTagLenMax)
+
+	containerIDs := []string{}
+	containerStatuses := pData.GetPath("status", "containerStatuses")
+	for c := range containerStatuses.MustArray() {
+		containerID := containerStatuses.GetIndex(c).Get("containerID").MustString()
+		if containerID == "" {
+			continue
+		}
+		cIndex := strings.Index(containerID, "://")
+		if cIndex != -1 {
+			containerID = containerID[cIndex+3:]
+		}
+		containerIDs = append(containerIDs, containerID)
+	}
+	sort.Strings(containerIDs)
+
+	pod := model.Pod{
+		Lcuuid:              podLcuuid,
+		Name:                name,
+		State:               status,
+		VPCLcuuid:           k.VPCUuid,
+		ENV:                 envString,
+		Label:               labelString,
+		Annotation:          annotationString,
+		ContainerIDs:        strings.Join(containerIDs, ", "),
+		PodReplicaSetLcuuid: podRSLcuuid,
+		PodNodeLcuuid:       k.nodeIPToLcuuid[hostIP],
+		PodGroupLcuuid:      podGroupLcuuid,
+		PodNamespaceLcuuid:  namespaceLcuuid,
+		CreatedAt:           created,
+		AZLcuuid:            k.azLcuuid,
+		RegionLcuuid:        k.RegionUuid,
+		PodClusterLcuuid:    common.GetUUID(k.UuidGenerate, uuid.Nil),
+	}
+	entry.pod = &pod
+	podIP := pData.Get("status").Get("podIP").MustString()
+	podIPs := []string{podIP}
+	if podNetworks, ok := pData.Get("status").CheckGet("podNetworks"); ok {
+		for i := range podNetworks.MustArray() {
+			port := podNetworks.GetIndex(i).MustMap()
+			podIPs = append(podIPs, port["ip"].([]string)...)
+		}
+	}
+	for _, ip := range podIPs {
+		if ip == "" {
+			continue
+		}
+		entry.ips = append(entry.ips, ip)
+	}
+	return entry, nil
+}
+
+// InPlaceSet 的 pod 及 serverless pod 会抽象出 pod group
+func isPodInPodGroups(metaData *simplejson.Json) bool {
+	if metaData.Get("ownerReferences").GetIndex(0).Get("kind").MustString() == "InPlaceSet" {
+		return true
+	}
+	providerType := metaData.Get("labels").Get("virtual-kubelet.io/provider-cluster-type").MustString()
+	return providerType == "serverless" || providerType == "proprietary"
+}
@@ -0,0 +1,240 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kubernetes_gather
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/deepflowio/deepflow/server/controller/cloud/model"
+	"github.com/deepflowio/deepflow/server/controller/common"
+
+	"github.com/bitly/go-simplejson"
+	uuid "github.com/satori/go.uuid"
+)
+
+// Gateway API 的 HTTPRoute/GRPCRoute 作为 ingress 同步
+// 路由未指定 hostnames 时使用所挂载 Gateway 监听器的 hostname
+func (k *KubernetesGather) getPodGatewayRoutes() (ingresses []model.PodIngress, ingressRules []model.PodIngressRule, ingressRuleBackends []model.PodIngressRuleBackend, err error) {
+	log.Debug("get gateway routes starting")
+	gatewayToListenerHosts := map[string]map[string]string{}
+	for _, g := range k.k8sInfo["*v1.Gateway"] {
+		gData, gErr := simplejson.NewJson([]byte(g))
+		if gErr != nil {
+			err = gErr
+			log.Errorf("gateway initialization simplejson error: (%s)", gErr.Error())
+			return
+		}
+		metaData, ok := gData.CheckGet("metadata")
+		if !ok {
+			log.Info("gateway metadata not found")
+			continue
+		}
+		listenerHosts := map[string]string{}
+		listeners := gData.Get("spec").Get("listeners")
+		for i := range listeners.MustArray() {
+			listener := listeners.GetIndex(i)
+			listenerHosts[listener.Get("name").MustString()] = listener.Get("hostname").MustString()
+		}
+		gatewayToListenerHosts[metaData.Get("namespace").MustString()+"/"+metaData.Get("name").MustString()] = listenerHosts
+	}
+
+	routeProtocols := [2]string{"HTTP", "GRPC"}
+	routeInfos := [2][]string{}
+	routeInfos[0] = k.k8sInfo["*v1.HTTPRoute"]
+	routeInfos[1] = k.k8sInfo["*v1.GRPCRoute"]
+	for t, routeInfo := range routeInfos {
+		for _, r := range routeInfo {
+			rData, rErr := simplejson.NewJson([]byte(r))
+			if rErr != nil {
+				err = rErr
+				log.Errorf("gateway route initialization simplejson error: (%s)", rErr.Error())
+				return
+			}
+			metaData, ok := rData.CheckGet("metadata")
+			if !ok {
+				log.Info("gateway route metadata not found")
+				continue
+			}
+			uID := metaData.Get("uid").MustString()
+			if uID == "" {
+				log.Info("gateway route uid not found")
+				continue
+			}
+			name := metaData.Get("name").MustString()
+			if name == "" {
+				log.Infof("gateway route (%s) name not found", uID)
+				continue
+			}
+			namespace := metaData.Get("namespace").MustString()
+			namespaceLcuuid, ok := k.namespaceToLcuuid[namespace]
+			if !ok {
+				log.Infof("gateway route (%s) namespace not found", name)
+				continue
+			}
+			ingress := model.PodIngress{
+				Lcuuid:             uID,
+				Name:               name,
+				PodNamespaceLcuuid: namespaceLcuuid,
+				AZLcuuid:           k.azLcuuid,
+				RegionLcuuid:       k.RegionUuid,
+				PodClusterLcuuid:   common.GetUUID(k.UuidGenerate, uuid.Nil),
+			}
+			ingresses = append(ingresses, ingress)
+
+			spec := rData.Get("spec")
+			hosts := spec.Get("hostnames").MustStringArray()
+			if len(hosts) == 0 {
+				hosts = k.getGatewayRouteParentHosts(spec.Get("parentRefs"), namespace, gatewayToListenerHosts)
+			}
+			if len(hosts) == 0 {
+				hosts = []string{""}
+			}
+
+			rules := spec.Get("rules")
+			for hIndex, host := range hosts {
+				ruleLcuuid := common.GetUUID(uID+host+"_"+strconv.Itoa(hIndex), uuid.Nil)
+				ingressRule := model.PodIngressRule{
+					Lcuuid:           ruleLcuuid,
+					Host:             host,
+					Protocol:         routeProtocols[t],
+					PodIngressLcuuid: uID,
+				}
+				ingressRules = append(ingressRules, ingressRule)
+				for rIndex := range rules.MustArray() {
+					rule := rules.GetIndex(rIndex)
+					var paths []string
+					if t == 0 {
+						paths = getHTTPRouteRulePaths(rule)
+					} else {
+						paths = getGRPCRouteRulePaths(rule)
+					}
+					backendRefs := rule.Get("backendRefs")
+					for b := range backendRefs.MustArray() {
+						backendRef := backendRefs.GetIndex(b)
+						if kind := backendRef.Get("kind").MustString(); kind != "" && kind != "Service" {
+							log.Debugf("gateway route (%s) backend kind (%s) not support", name, kind)
+							continue
+						}
+						serviceName := backendRef.Get("name").MustString()
+						serviceNamespace := backendRef.Get("namespace").MustString()
+						if serviceNamespace == "" {
+							serviceNamespace = namespace
+						}
+						service, ok := k.nsServiceNameToService[serviceNamespace+serviceName]
+						if !ok {
+							log.Infof("gateway route backend service (%s) not found", serviceName)
+							continue
+						}
+						serviceLcuuid := ""
+						for key := range service {
+							serviceLcuuid = key
+							break
+						}
+						if ingressLcuuid, ok := k.serviceLcuuidToIngressLcuuid[serviceLcuuid]; ok && ingressLcuuid != uID {
+							log.Infof("ingress (%s) is already associated with the service (%s), and ingress (%s) cannot be associated", ingressLcuuid, serviceLcuuid, uID)
+						} else {
+							k.serviceLcuuidToIngressLcuuid[serviceLcuuid] = uID
+						}
+						port := backendRef.Get("port").MustInt()
+						if port == 0 {
+							log.Infof("gateway route (%s) backend service (%s) no port", uID, serviceName)
+							continue
+						}
+						key := serviceName + "_" + strconv.Itoa(port)
+						for _, path := range paths {
+							ingressRuleBackend := model.PodIngressRuleBackend{
+								Lcuuid:               common.GetUUID(ruleLcuuid+key+path, uuid.Nil),
+								Path:                 path,
+								Port:                 port,
+								PodServiceLcuuid:     serviceLcuuid,
+								PodIngressRuleLcuuid: ruleLcuuid,
+								PodIngressLcuuid:     uID,
+							}
+							ingressRuleBackends = append(ingressRuleBackends, ingressRuleBackend)
+						}
+					}
+				}
+			}
+		}
+	}
+	log.Debug("get gateway routes complete")
+	return
+}
+
+func (k *KubernetesGather) getGatewayRouteParentHosts(parentRefs *simplejson.Json, namespace string, gatewayToListenerHosts map[string]map[string]string) []string {
+	var hosts []string
+	hostSet := map[string]bool{}
+	for p := range parentRefs.MustArray() {
+		parentRef := parentRefs.GetIndex(p)
+		if kind := parentRef.Get("kind").MustString(); kind != "" && kind != "Gateway" {
+			continue
+		}
+		parentNamespace := parentRef.Get("namespace").MustString()
+		if parentNamespace == "" {
+			parentNamespace = namespace
+		}
+		listenerHosts, ok := gatewayToListenerHosts[parentNamespace+"/"+parentRef.Get("name").MustString()]
+		if !ok {
+			continue
+		}
+		sectionName := parentRef.Get("sectionName").MustString()
+		for listenerName, host := range listenerHosts {
+			if host == "" || hostSet[host] || (sectionName != "" && sectionName != listenerName) {
+				continue
+			}
+			hostSet[host] = true
+			hosts = append(hosts, host)
+		}
+	}
+	// 监听器无序，排序保证 rule lcuuid 稳定
+	sort.Strings(hosts)
+	return hosts
+}
+
+// 未指定匹配条件时默认匹配 "/"
+func getHTTPRouteRulePaths(rule *simplejson.Json) []string {
+	var paths []string
+	matches := rule.Get("matches")
+	for m := range matches.MustArray() {
+		if path := matches.GetIndex(m).Get("path").Get("value").MustString(); path != "" {
+			paths = append(paths, path)
+		}
+	}
+	if len(paths) == 0 {
+		paths = []string{"/"}
+	}
+	return paths
+}
+
+// gRPC 请求路径格式为 /{service}/{method}
+func getGRPCRouteRulePaths(rule *simplejson.Json) []string {
+	var paths []string
+	matches := rule.Get("matches")
+	for m := range matches.MustArray() {
+		method := matches.GetIndex(m).Get("method")
+		service := method.Get("service").MustString()
+		if service == "" {
+			continue
+		}
+		paths = append(paths, "/"+service+"/"+method.Get("method").MustString())
+	}
+	if len(paths) == 0 {
+		paths = []string{"/"}
+	}
+	return paths
+}
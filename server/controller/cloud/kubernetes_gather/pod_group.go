@@ -30,12 +30,16 @@ import (
 
 func (k *KubernetesGather) getPodGroups() (podGroups []model.PodGroup, err error) {
 	log.Debug("get podgroups starting")
-	podControllers := [5][]string{}
+	// CronJob 需要在 Job 之前处理，CronJob 创建的 Job 归属到 CronJob
+	podControllers := [8][]string{}
 	podControllers[0] = k.k8sInfo["*v1.Deployment"]
 	podControllers[1] = k.k8sInfo["*v1.StatefulSet"]
 	podControllers[2] = k.k8sInfo["*v1.DaemonSet"]
 	podControllers[3] = k.k8sInfo["*v1.CloneSet"]
 	podControllers[4] = k.k8sInfo["*v1.Pod"]
+	podControllers[5] = k.k8sInfo["*v1.Rollout"]
+	podControllers[6] = k.k8sInfo["*v1.CronJob"]
+	podControllers[7] = k.k8sInfo["*v1.Job"]
 	pgNameToTypeID := map[string]int{
 		"deployment":            common.POD_GROUP_DEPLOYMENT,
 		"statefulset":           common.POD_GROUP_STATEFULSET,
@@ -43,6 +47,9 @@ func (k *KubernetesGather) getPodGroups() (podGroups []model.PodGroup, err error
 		"daemonset":             common.POD_GROUP_DAEMON_SET,
 		"replicationcontroller": common.POD_GROUP_RC,
 		"cloneset":              common.POD_GROUP_CLONESET,
+		"job":                   common.POD_GROUP_JOB,
+		"cronjob":               common.POD_GROUP_CRONJOB,
+		"rollout":               common.POD_GROUP_ROLLOUT,
 	}
 	for t, podController := range podControllers {
 		for _, c := range podController {
@@ -81,6 +88,7 @@ func (k *KubernetesGather) getPodGroups() (podGroups []model.PodGroup, err error
 			serviceType := common.POD_GROUP_STATEFULSET
 			label := "statefulset:" + namespace + ":" + name
 			replicas := cData.Get("spec").Get("replicas").MustInt()
+			podTemplate := cData.GetPath("spec", "template")
 			switch t {
 			case 0:
 				serviceType = common.POD_GROUP_DEPLOYMENT
@@ -137,6 +145,26 @@ func (k *KubernetesGather) getPodGroups() (podGroups []model.PodGroup, err error
 					label = typeName + ":" + namespace + ":" + abstractPGName
 					name = abstractPGName
 				}
+			case 5:
+				serviceType = common.POD_GROUP_ROLLOUT
+				label = "rollout:" + namespace + ":" + name
+			case 6:
+				replicas = 0
+				podTemplate = cData.GetPath("spec", "jobTemplate", "spec", "template")
+				serviceType = common.POD_GROUP_CRONJOB
+				label = "cronjob:" + namespace + ":" + name
+			case 7:
+				ownerReference := metaData.Get("ownerReferences").GetIndex(0)
+				if ownerReference.Get("kind").MustString() == "CronJob" {
+					cronJobLcuuid := ownerReference.Get("uid").MustString()
+					if k.podGroupLcuuids.Contains(cronJobLcuuid) {
+						k.jobLcuuidToPodGroupLcuuid[uID] = cronJobLcuuid
+						continue
+					}
+				}
+				replicas = cData.Get("spec").Get("parallelism").MustInt()
+				serviceType = common.POD_GROUP_JOB
+				label = "job:" + namespace + ":" + name
 			}
 
 			_, ok = k.nsLabelToGroupLcuuids[namespace+label]
@@ -147,7 +175,7 @@ func (k *KubernetesGather) getPodGroups() (podGroups []model.PodGroup, err error
 				groupIDsSet.Add(uID)
 				k.nsLabelToGroupLcuuids[namespace+label] = groupIDsSet
 			}
-			mLabels := cData.GetPath("spec", "template", "metadata", "labels").MustMap()
+			mLabels := podTemplate.GetPath("metadata", "labels").MustMap()
 			for key, v := range mLabels {
 				vString, ok := v.(string)
 				if !ok {
@@ -181,7 +209,7 @@ func (k *KubernetesGather) getPodGroups() (podGroups []model.PodGroup, err error
 			}
 			labelSlice := cloudcommon.StringInterfaceMapKVs(labels, ":", 0)
 			labelString := strings.Join(labelSlice, ", ")
-			containers := cData.Get("spec").Get("template").Get("spec").Get("containers")
+			containers := podTemplate.Get("spec").Get("containers")
 			for i := range containers.MustArray() {
 				container := containers.GetIndex(i)
 				cPorts, ok := container.CheckGet("ports")
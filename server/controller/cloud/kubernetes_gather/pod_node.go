@@ -107,6 +107,7 @@ func (k *KubernetesGather) getPodNodes() (podNodes []model.PodNode, nodeNetwork,
 			IP:               nodeIP,
 			VCPUNum:          cpuNum,
 			MemTotal:         memory,
+			Label:            strings.Join(cloudcommon.StringInterfaceMapKVs(labels, ":", 0), ", "),
 			VPCLcuuid:        k.VPCUuid,
 			AZLcuuid:         k.azLcuuid,
 			RegionLcuuid:     k.RegionUuid,
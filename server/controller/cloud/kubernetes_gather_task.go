@@ -18,11 +18,14 @@ package cloud
 
 import (
 	"context"
+	"reflect"
+	"sync"
 	"time"
 
 	"github.com/deepflowio/deepflow/server/controller/cloud/config"
 	"github.com/deepflowio/deepflow/server/controller/cloud/kubernetes_gather"
 	kmodel "github.com/deepflowio/deepflow/server/controller/cloud/kubernetes_gather/model"
+	"github.com/deepflowio/deepflow/server/controller/cloud/model"
 	"github.com/deepflowio/deepflow/server/controller/common"
 	"github.com/deepflowio/deepflow/server/controller/db/mysql"
 )
@@ -35,6 +38,16 @@ type KubernetesGatherTask struct {
 	resource         kmodel.KubernetesGatherResource
 	basicInfo        kmodel.KubernetesGatherBasicInfo
 	SubDomainConfig  string // 附属容器集群配置字段config
+
+	revisionMutex     sync.Mutex
+	subDomainRevision subDomainRevision
+}
+
+// 附属容器集群的数据由kubernetes_gather数据及云平台数据共同生成，记录上次生成的数据用于计算版本及变化
+type subDomainRevision struct {
+	resource model.SubDomainResource
+	revision uint64
+	delta    *model.SubDomainResourceDelta
 }
 
 func NewKubernetesGatherTask(
@@ -78,6 +91,93 @@ func (k *KubernetesGatherTask) GetResource() kmodel.KubernetesGatherResource {
 	return k.resource
 }
 
+// 附属容器集群的数据未变化时，数据版本不变；数据变化时版本递增，并计算相对上一版本的变化
+func (k *KubernetesGatherTask) setSubDomainRevision(resource *model.SubDomainResource) {
+	k.revisionMutex.Lock()
+	defer k.revisionMutex.Unlock()
+
+	last := k.subDomainRevision
+	if last.revision != 0 && isSameSubDomainResource(&last.resource, resource) {
+		resource.Revision = last.revision
+		resource.Delta = last.delta
+		return
+	}
+	current := subDomainRevision{resource: *resource, revision: last.revision + 1}
+	if last.revision != 0 {
+		if delta, ok := diffSubDomainResource(&last.resource, resource); ok {
+			delta.BaseRevision = last.revision
+			current.delta = delta
+		}
+	}
+	k.subDomainRevision = current
+	resource.Revision = current.revision
+	resource.Delta = current.delta
+}
+
+func isSameSubDomainResource(last, current *model.SubDomainResource) bool {
+	lastValue := reflect.ValueOf(last).Elem()
+	currentValue := reflect.ValueOf(current).Elem()
+	for i := 0; i < currentValue.NumField(); i++ {
+		if currentValue.Field(i).Kind() != reflect.Slice {
+			continue
+		}
+		if !reflect.DeepEqual(lastValue.Field(i).Interface(), currentValue.Field(i).Interface()) {
+			return false
+		}
+	}
+	return true
+}
+
+// 以 lcuuid 为 key 对比各类资源，得到新增或变化的资源及删除的资源；K8sEvents 每次全量处理，不计算变化；
+// 存在 lcuuid 重复的资源时无法对比，返回 false
+func diffSubDomainResource(last, current *model.SubDomainResource) (*model.SubDomainResourceDelta, bool) {
+	delta := &model.SubDomainResourceDelta{}
+	lastValue := reflect.ValueOf(last).Elem()
+	currentValue := reflect.ValueOf(current).Elem()
+	changedValue := reflect.ValueOf(&delta.Changed).Elem()
+	deletedValue := reflect.ValueOf(&delta.Deleted).Elem()
+	for i := 0; i < currentValue.NumField(); i++ {
+		field := currentValue.Type().Field(i)
+		if field.Type.Kind() != reflect.Slice || field.Name == "K8sEvents" {
+			continue
+		}
+		lastItems := lastValue.Field(i)
+		lcuuidToLastItem := make(map[string]reflect.Value, lastItems.Len())
+		for j := 0; j < lastItems.Len(); j++ {
+			lcuuid := lastItems.Index(j).FieldByName("Lcuuid").String()
+			if _, ok := lcuuidToLastItem[lcuuid]; ok {
+				return nil, false
+			}
+			lcuuidToLastItem[lcuuid] = lastItems.Index(j)
+		}
+
+		currentItems := currentValue.Field(i)
+		currentLcuuids := make(map[string]struct{}, currentItems.Len())
+		changed := reflect.MakeSlice(field.Type, 0, 0)
+		for j := 0; j < currentItems.Len(); j++ {
+			item := currentItems.Index(j)
+			lcuuid := item.FieldByName("Lcuuid").String()
+			if _, ok := currentLcuuids[lcuuid]; ok {
+				return nil, false
+			}
+			currentLcuuids[lcuuid] = struct{}{}
+			lastItem, ok := lcuuidToLastItem[lcuuid]
+			if !ok || !reflect.DeepEqual(lastItem.Interface(), item.Interface()) {
+				changed = reflect.Append(changed, item)
+			}
+		}
+		deleted := reflect.MakeSlice(field.Type, 0, 0)
+		for j := 0; j < lastItems.Len(); j++ {
+			if _, ok := currentLcuuids[lastItems.Index(j).FieldByName("Lcuuid").String()]; !ok {
+				deleted = reflect.Append(deleted, lastItems.Index(j))
+			}
+		}
+		changedValue.Field(i).Set(changed)
+		deletedValue.Field(i).Set(deleted)
+	}
+	return delta, true
+}
+
 func (k *KubernetesGatherTask) Start() {
 	go func() {
 		k.run()
@@ -0,0 +1,81 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cloud
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/deepflowio/deepflow/server/controller/cloud/model"
+)
+
+func TestSetSubDomainRevision(t *testing.T) {
+	newResource := func() model.SubDomainResource {
+		return model.SubDomainResource{
+			Verified:    true,
+			PodClusters: []model.PodCluster{{Lcuuid: "cluster-1", Name: "cluster"}},
+			PodNodes:    []model.PodNode{{Lcuuid: "node-1", Name: "node"}},
+			Pods: []model.Pod{
+				{Lcuuid: "pod-1", Name: "pod-1", Label: "app:a"},
+				{Lcuuid: "pod-2", Name: "pod-2", Label: "app:b"},
+			},
+			IPs:       []model.IP{{Lcuuid: "ip-1", IP: "10.0.0.1"}, {Lcuuid: "ip-2", IP: "10.0.0.2"}},
+			K8sEvents: []model.K8sEvent{{Lcuuid: "event-1"}},
+		}
+	}
+	k := &KubernetesGatherTask{}
+
+	first := newResource()
+	k.setSubDomainRevision(&first)
+	assert.Equal(t, uint64(1), first.Revision)
+	assert.Nil(t, first.Delta)
+
+	unchanged := newResource()
+	k.setSubDomainRevision(&unchanged)
+	assert.Equal(t, uint64(1), unchanged.Revision)
+
+	changed := newResource()
+	changed.Pods[1].Label = "app:c"
+	k.setSubDomainRevision(&changed)
+	assert.Equal(t, uint64(2), changed.Revision)
+	assert.NotNil(t, changed.Delta)
+	assert.Equal(t, uint64(1), changed.Delta.BaseRevision)
+	assert.Equal(t, []model.Pod{changed.Pods[1]}, changed.Delta.Changed.Pods)
+	assert.Empty(t, changed.Delta.Changed.PodClusters)
+	assert.Empty(t, changed.Delta.Changed.PodNodes)
+	assert.Empty(t, changed.Delta.Changed.IPs)
+	assert.Empty(t, changed.Delta.Changed.K8sEvents)
+	assert.Empty(t, changed.Delta.Deleted.Pods)
+
+	deleted := newResource()
+	deleted.Pods[1].Label = "app:c"
+	deleted.IPs = deleted.IPs[:1]
+	k.setSubDomainRevision(&deleted)
+	assert.Equal(t, uint64(3), deleted.Revision)
+	assert.Equal(t, uint64(2), deleted.Delta.BaseRevision)
+	assert.Empty(t, deleted.Delta.Changed.Pods)
+	assert.Empty(t, deleted.Delta.Changed.IPs)
+	assert.Equal(t, []model.IP{{Lcuuid: "ip-2", IP: "10.0.0.2"}}, deleted.Delta.Deleted.IPs)
+
+	// lcuuid 重复时无法计算变化，需全量对比
+	duplicated := newResource()
+	duplicated.Pods[1].Lcuuid = "pod-1"
+	k.setSubDomainRevision(&duplicated)
+	assert.Equal(t, uint64(4), duplicated.Revision)
+	assert.Nil(t, duplicated.Delta)
+}
@@ -71,6 +71,7 @@ type VPC struct {
 	Lcuuid       string `json:"lcuuid" binding:"required"`
 	Name         string `json:"name" binding:"required"`
 	Label        string `json:"label"`
+	CloudTags    string `json:"cloud_tags"`
 	TunnelID     int    `json:"tunnel_id"`
 	CIDR         string `json:"cidr"`
 	RegionLcuuid string `json:"region_lcuuid" binding:"required"`
@@ -80,6 +81,7 @@ type Network struct {
 	Lcuuid          string `json:"lcuuid" binding:"required"`
 	Name            string `json:"name" binding:"required"`
 	Label           string `json:"label"`
+	CloudTags       string `json:"cloud_tags"`
 	SegmentationID  int    `json:"segmentation_id"`
 	TunnelID        int    `json:"tunnel_id"`
 	Shared          bool   `json:"shared"`
@@ -96,6 +98,7 @@ type Subnet struct {
 	Lcuuid          string `json:"lcuuid" binding:"required"`
 	Name            string `json:"name" binding:"required"`
 	Label           string `json:"label"`
+	CloudTags       string `json:"cloud_tags"`
 	CIDR            string `json:"cidr" binding:"required"`
 	GatewayIP       string `json:"gateway_ip"`
 	NetworkLcuuid   string `json:"network_lcuuid" binding:"required"`
@@ -209,6 +212,7 @@ type LB struct {
 	Lcuuid       string `json:"lcuuid" binding:"required"`
 	Name         string `json:"name" binding:"required"`
 	Label        string `json:"label"`
+	CloudTags    string `json:"cloud_tags"`
 	Model        int    `json:"model" binding:"required"`
 	VIP          string `json:"vip"`
 	VPCLcuuid    string `json:"vpc_lcuuid" binding:"required"`
@@ -248,6 +252,7 @@ type NATGateway struct {
 	Lcuuid       string `json:"lcuuid" binding:"required"`
 	Name         string `json:"name" binding:"required"`
 	Label        string `json:"label" binding:"required"`
+	CloudTags    string `json:"cloud_tags"`
 	FloatingIPs  string `json:"floating_ips"`
 	VPCLcuuid    string `json:"vpc_lcuuid" binding:"required"`
 	RegionLcuuid string `json:"region_lcuuid" binding:"required"`
@@ -292,6 +297,7 @@ type RedisInstance struct {
 	Lcuuid       string `json:"lcuuid" binding:"required"`
 	Name         string `json:"name" binding:"required"`
 	Label        string `json:"label"`
+	CloudTags    string `json:"cloud_tags"`
 	State        int    `json:"state"`
 	Version      string `json:"version" binding:"required"`
 	InternalHost string `json:"internal_host"`
@@ -305,6 +311,7 @@ type RDSInstance struct {
 	Lcuuid       string `json:"lcuuid" binding:"required"`
 	Name         string `json:"name" binding:"required"`
 	Label        string `json:"label"`
+	CloudTags    string `json:"cloud_tags"`
 	State        int    `json:"state"`
 	Type         int    `json:"type" binding:"required"`
 	Version      string `json:"version" binding:"required"`
@@ -345,6 +352,7 @@ type PodNode struct {
 	IP               string `json:"ip" binding:"required"`
 	VCPUNum          int    `json:"vcpu_num"`
 	MemTotal         int    `json:"memory_total"`
+	Label            string `json:"label"`
 	PodClusterLcuuid string `json:"pod_cluster_lcuuid" binding:"required"`
 	VPCLcuuid        string `json:"vpc_lcuuid" binding:"required"`
 	AZLcuuid         string `json:"az_lcuuid" binding:"required"`
@@ -500,11 +508,32 @@ type PrometheusTarget struct {
 	HonorLabelsConfig bool   `json:"honor_labels_config" binding:"required"`
 }
 
+// Kubernetes event, not a resource, converted to a resource event by recorder
+type K8sEvent struct {
+	Lcuuid               string    `json:"lcuuid" binding:"required"`
+	Type                 string    `json:"type" binding:"required"`
+	Reason               string    `json:"reason" binding:"required"`
+	Message              string    `json:"message"`
+	Count                int       `json:"count"`
+	InvolvedObjectKind   string    `json:"involved_object_kind" binding:"required"`
+	InvolvedObjectName   string    `json:"involved_object_name" binding:"required"`
+	InvolvedObjectLcuuid string    `json:"involved_object_lcuuid"`
+	PodNamespaceLcuuid   string    `json:"pod_namespace_lcuuid"`
+	PodClusterLcuuid     string    `json:"pod_cluster_lcuuid" binding:"required"`
+	VPCLcuuid            string    `json:"vpc_lcuuid"`
+	AZLcuuid             string    `json:"az_lcuuid"`
+	RegionLcuuid         string    `json:"region_lcuuid"`
+	SubDomainLcuuid      string    `json:"sub_domain_lcuuid"`
+	LastTime             time.Time `json:"last_time" binding:"required"`
+}
+
 type SubDomainResource struct {
 	Verified               bool `json:"verified"`
 	ErrorState             int
 	ErrorMessage           string
 	SyncAt                 time.Time
+	Revision               uint64                  // 数据内容变化时递增，0表示未知
+	Delta                  *SubDomainResourceDelta // 相对上一版本的变化，为空时需全量对比
 	Networks               []Network
 	Subnets                []Subnet
 	VInterfaces            []VInterface
@@ -524,6 +553,14 @@ type SubDomainResource struct {
 	Pods                   []Pod
 	Processes              []Process
 	PrometheusTargets      []PrometheusTarget
+	K8sEvents              []K8sEvent
+}
+
+// 附属容器集群数据相对 BaseRevision 版本的变化，不包含 K8sEvents
+type SubDomainResourceDelta struct {
+	BaseRevision uint64
+	Changed      SubDomainResource // 新增或变化的资源
+	Deleted      SubDomainResource // 删除的资源
 }
 
 type Resource struct {
@@ -576,6 +613,7 @@ type Resource struct {
 	PodIngressRuleBackends []PodIngressRuleBackend
 	Processes              []Process
 	PrometheusTargets      []PrometheusTarget
+	K8sEvents              []K8sEvent
 	SubDomainResources     map[string]SubDomainResource
 }
 
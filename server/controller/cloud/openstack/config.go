@@ -0,0 +1,100 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openstack
+
+import (
+	"strings"
+
+	"github.com/bitly/go-simplejson"
+
+	"github.com/deepflowio/deepflow/server/controller/common"
+)
+
+const (
+	DEFAULT_DOMAIN_NAME   = "Default"
+	DEFAULT_ENDPOINT_TYPE = "public"
+)
+
+type Config struct {
+	RegionLcuuid      string
+	URL               string // keystone v3 地址，例如 http://keystone:5000/v3
+	UserName          string
+	Password          string
+	UserDomainName    string
+	ProjectName       string
+	ProjectDomainName string
+	EndpointType      string // 使用 catalog 中哪种 interface 的 endpoint：public、internal、admin
+	ExcludeRegions    []string
+	IncludeRegions    []string
+}
+
+func (c *Config) LoadFromString(sConf string) (err error) {
+	jConf, err := simplejson.NewJson([]byte(sConf))
+	if err != nil {
+		log.Errorf("convert config string: %s to json failed: %v", sConf, err)
+		return
+	}
+	c.RegionLcuuid = jConf.Get("region_uuid").MustString()
+	c.URL, err = jConf.Get("url").String()
+	if err != nil {
+		log.Error("url must be specified")
+		return
+	}
+	c.URL = strings.TrimSuffix(c.URL, "/")
+	c.UserName, err = jConf.Get("username").String()
+	if err != nil {
+		log.Error("username must be specified")
+		return
+	}
+	pswd, err := jConf.Get("password").String()
+	if err != nil {
+		log.Error("password must be specified")
+		return
+	}
+	dpswd, err := common.DecryptSecretKey(pswd)
+	if err != nil {
+		log.Error("decrypt password failed")
+		return
+	}
+	c.Password = dpswd
+	c.ProjectName, err = jConf.Get("project_name").String()
+	if err != nil {
+		log.Error("project_name must be specified")
+		return
+	}
+	c.UserDomainName = jConf.Get("user_domain_name").MustString()
+	if c.UserDomainName == "" {
+		c.UserDomainName = DEFAULT_DOMAIN_NAME
+	}
+	c.ProjectDomainName = jConf.Get("project_domain_name").MustString()
+	if c.ProjectDomainName == "" {
+		c.ProjectDomainName = DEFAULT_DOMAIN_NAME
+	}
+	c.EndpointType = jConf.Get("endpoint_type").MustString()
+	if c.EndpointType == "" {
+		c.EndpointType = DEFAULT_ENDPOINT_TYPE
+	}
+	eRegions := jConf.Get("exclude_regions").MustString()
+	if eRegions != "" {
+		c.ExcludeRegions = strings.Split(eRegions, ",")
+	}
+	iRegions := jConf.Get("include_regions").MustString()
+	if iRegions != "" {
+		c.IncludeRegions = strings.Split(iRegions, ",")
+	}
+	return
+}
@@ -0,0 +1,65 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openstack
+
+import (
+	"fmt"
+
+	cloudcommon "github.com/deepflowio/deepflow/server/controller/cloud/common"
+	"github.com/deepflowio/deepflow/server/controller/cloud/model"
+)
+
+// 需在 getVMs 之后调用，仅同步绑定到虚拟机的浮动IP，绑定到负载均衡器的浮动IP在负载均衡器中处理
+func (o *OpenStack) getFloatingIPs(region Region) ([]model.FloatingIP, error) {
+	jFIPs, err := o.getRawData(fmt.Sprintf("%s/floatingips", region.networkURL), "floatingips")
+	if err != nil {
+		return nil, err
+	}
+
+	var fIPs []model.FloatingIP
+	requiredAttrs := []string{"id", "floating_ip_address", "floating_network_id", "port_id"}
+	for i := range jFIPs {
+		jFIP := jFIPs[i]
+		ip := jFIP.Get("floating_ip_address").MustString()
+		if !cloudcommon.CheckJsonAttributes(jFIP, requiredAttrs) {
+			log.Infof("exclude floating_ip: %s, missing attr", ip)
+			continue
+		}
+		portID := jFIP.Get("port_id").MustString()
+		if portID == "" {
+			log.Debugf("exclude floating_ip: %s, not associated", ip)
+			continue
+		}
+		o.toolDataSet.portIDToFloatingIP[portID] = ip
+		vmLcuuid := o.toolDataSet.portIDToVMLcuuid[portID]
+		if !o.toolDataSet.vmLcuuidToExists[vmLcuuid] {
+			continue
+		}
+		fIPs = append(
+			fIPs,
+			model.FloatingIP{
+				Lcuuid:        jFIP.Get("id").MustString(),
+				IP:            ip,
+				VMLcuuid:      vmLcuuid,
+				NetworkLcuuid: jFIP.Get("floating_network_id").MustString(),
+				VPCLcuuid:     o.toolDataSet.vmLcuuidToVPCLcuuid[vmLcuuid],
+				RegionLcuuid:  region.lcuuid,
+			},
+		)
+	}
+	return fIPs, nil
+}
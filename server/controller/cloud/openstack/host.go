@@ -0,0 +1,84 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openstack
+
+import (
+	"fmt"
+	"strings"
+
+	cloudcommon "github.com/deepflowio/deepflow/server/controller/cloud/common"
+	"github.com/deepflowio/deepflow/server/controller/cloud/model"
+	"github.com/deepflowio/deepflow/server/controller/common"
+)
+
+func getHostHType(hypervisorType string) int {
+	switch strings.ToLower(hypervisorType) {
+	case "vmware vcenter server":
+		return common.HOST_HTYPE_ESXI
+	case "hyperv":
+		return common.HOST_HTYPE_HYPER_V
+	default:
+		return common.HOST_HTYPE_KVM
+	}
+}
+
+func (o *OpenStack) getHosts(region Region) ([]model.Host, error) {
+	jHypervisors, err := o.getRawData(fmt.Sprintf("%s/os-hypervisors/detail", region.computeURL), "hypervisors")
+	if err != nil {
+		return nil, err
+	}
+
+	var hosts []model.Host
+	requiredAttrs := []string{"hypervisor_hostname", "host_ip"}
+	for i := range jHypervisors {
+		jh := jHypervisors[i]
+		hostname := jh.Get("hypervisor_hostname").MustString()
+		if !cloudcommon.CheckJsonAttributes(jh, requiredAttrs) {
+			log.Infof("exclude host: %s, missing attr", hostname)
+			continue
+		}
+		// 虚拟机的 OS-EXT-SRV-ATTR:host 及可用区中的主机均为 nova-compute 服务的 host
+		serviceHost := jh.Get("service").Get("host").MustString()
+		if serviceHost == "" {
+			serviceHost = hostname
+		}
+		azLcuuid, ok := o.toolDataSet.hostNameToAZLcuuid[serviceHost]
+		if !ok {
+			log.Infof("exclude host: %s, missing az info", hostname)
+			continue
+		}
+		ip := jh.Get("host_ip").MustString()
+		hosts = append(
+			hosts,
+			model.Host{
+				Lcuuid:       common.GenerateUUID(ip + "_" + o.lcuuidGenerate),
+				Name:         hostname,
+				IP:           ip,
+				Type:         common.HOST_TYPE_VM,
+				HType:        getHostHType(jh.Get("hypervisor_type").MustString()),
+				VCPUNum:      jh.Get("vcpus").MustInt(),
+				MemTotal:     jh.Get("memory_mb").MustInt(),
+				AZLcuuid:     azLcuuid,
+				RegionLcuuid: region.lcuuid,
+			},
+		)
+		o.toolDataSet.hostNameToIP[serviceHost] = ip
+		o.toolDataSet.azLcuuidToResourceNum[azLcuuid]++
+		o.toolDataSet.regionLcuuidToResourceNum[region.lcuuid]++
+	}
+	return hosts, nil
+}
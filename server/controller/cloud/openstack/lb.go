@@ -0,0 +1,195 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openstack
+
+import (
+	"fmt"
+	"strings"
+
+	cloudcommon "github.com/deepflowio/deepflow/server/controller/cloud/common"
+	"github.com/deepflowio/deepflow/server/controller/cloud/model"
+	"github.com/deepflowio/deepflow/server/controller/common"
+)
+
+// 需在 getFloatingIPs 之后调用，未部署 octavia 的区域不同步负载均衡器
+func (o *OpenStack) getLBs(region Region) (
+	lbs []model.LB, lbListeners []model.LBListener, lbTargetServers []model.LBTargetServer, vifs []model.VInterface, ips []model.IP, err error,
+) {
+	if region.lbURL == "" {
+		return
+	}
+	jLBs, err := o.getRawData(fmt.Sprintf("%s/lbaas/loadbalancers", region.lbURL), "loadbalancers")
+	if err != nil {
+		return nil, nil, nil, nil, nil, err
+	}
+
+	requiredAttrs := []string{"id", "name", "vip_address", "vip_port_id", "vip_network_id", "vip_subnet_id"}
+	for i := range jLBs {
+		jLB := jLBs[i]
+		name := jLB.Get("name").MustString()
+		if !cloudcommon.CheckJsonAttributes(jLB, requiredAttrs) {
+			log.Infof("exclude lb: %s, missing attr", name)
+			continue
+		}
+		id := jLB.Get("id").MustString()
+		networkID := jLB.Get("vip_network_id").MustString()
+		vpcLcuuid, ok := o.toolDataSet.networkIDToVPCLcuuid[networkID]
+		if !ok {
+			log.Infof("exclude lb: %s, missing network info", id)
+			continue
+		}
+		if name == "" {
+			name = id
+		}
+		vip := jLB.Get("vip_address").MustString()
+		lb := model.LB{
+			Lcuuid:       id,
+			Name:         name,
+			Model:        cloudcommon.LB_MODEL_INTERNAL,
+			VIP:          vip,
+			VPCLcuuid:    vpcLcuuid,
+			RegionLcuuid: region.lcuuid,
+		}
+		portID := jLB.Get("vip_port_id").MustString()
+		if floatingIP, ok := o.toolDataSet.portIDToFloatingIP[portID]; ok {
+			lb.Model = cloudcommon.LB_MODEL_EXTERNAL
+			lb.VIP = strings.Join([]string{vip, floatingIP}, common.STRINGS_JOIN_COMMA)
+		}
+		lbs = append(lbs, lb)
+		o.toolDataSet.lbLcuuidToVPCLcuuid[id] = vpcLcuuid
+		o.toolDataSet.lbLcuuidToIP[id] = vip
+		o.toolDataSet.regionLcuuidToResourceNum[region.lcuuid]++
+
+		mac, ok := o.toolDataSet.portIDToMac[portID]
+		if !ok {
+			mac = common.VIF_DEFAULT_MAC
+		}
+		vifs = append(
+			vifs,
+			model.VInterface{
+				Lcuuid:        portID,
+				Type:          common.VIF_TYPE_LAN,
+				Mac:           mac,
+				DeviceType:    common.VIF_DEVICE_TYPE_LB,
+				DeviceLcuuid:  id,
+				NetworkLcuuid: networkID,
+				VPCLcuuid:     vpcLcuuid,
+				RegionLcuuid:  region.lcuuid,
+			},
+		)
+		ips = append(
+			ips,
+			model.IP{
+				Lcuuid:           common.GenerateUUID(portID + vip),
+				VInterfaceLcuuid: portID,
+				IP:               vip,
+				SubnetLcuuid:     jLB.Get("vip_subnet_id").MustString(),
+				RegionLcuuid:     region.lcuuid,
+			},
+		)
+	}
+
+	lbListeners, lbTargetServers, err = o.getListenersAndTargetServers(region)
+	if err != nil {
+		return nil, nil, nil, nil, nil, err
+	}
+	return
+}
+
+func (o *OpenStack) getListenersAndTargetServers(region Region) (lbListeners []model.LBListener, lbTargetServers []model.LBTargetServer, err error) {
+	jLs, err := o.getRawData(fmt.Sprintf("%s/lbaas/listeners", region.lbURL), "listeners")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	listenerRequiredAttrs := []string{"id", "name", "loadbalancers", "protocol_port", "protocol"}
+	tsRequiredAttrs := []string{"id", "protocol_port", "subnet_id", "address"}
+	for i := range jLs {
+		jL := jLs[i]
+		name := jL.Get("name").MustString()
+		if !cloudcommon.CheckJsonAttributes(jL, listenerRequiredAttrs) {
+			log.Infof("exclude lb_listener: %s, missing attr", name)
+			continue
+		}
+		listenerID := jL.Get("id").MustString()
+		var lbLcuuid string
+		jLBs := jL.Get("loadbalancers")
+		for j := range jLBs.MustArray() {
+			if id := jLBs.GetIndex(j).Get("id").MustString(); id != "" {
+				lbLcuuid = id
+				break
+			}
+		}
+		if _, ok := o.toolDataSet.lbLcuuidToVPCLcuuid[lbLcuuid]; !ok {
+			log.Infof("exclude lb_listener: %s, missing lb info", listenerID)
+			continue
+		}
+		if name == "" {
+			name = listenerID
+		}
+		protocol := jL.Get("protocol").MustString()
+		if strings.Contains(protocol, "HTTPS") {
+			protocol = "HTTPS"
+		}
+		lbListeners = append(
+			lbListeners,
+			model.LBListener{
+				Lcuuid:   listenerID,
+				Name:     name,
+				LBLcuuid: lbLcuuid,
+				IPs:      o.toolDataSet.lbLcuuidToIP[lbLcuuid],
+				Protocol: protocol,
+				Port:     jL.Get("protocol_port").MustInt(),
+			},
+		)
+
+		poolID := jL.Get("default_pool_id").MustString()
+		if poolID == "" {
+			continue
+		}
+		jTSs, err := o.getRawData(fmt.Sprintf("%s/lbaas/pools/%s/members", region.lbURL, poolID), "members")
+		if err != nil {
+			return nil, nil, err
+		}
+		for j := range jTSs {
+			jTS := jTSs[j]
+			tsID := jTS.Get("id").MustString()
+			if !cloudcommon.CheckJsonAttributes(jTS, tsRequiredAttrs) {
+				log.Infof("exclude lb_target_server: %s, missing attr", tsID)
+				continue
+			}
+			ip := jTS.Get("address").MustString()
+			targetServer := model.LBTargetServer{
+				Lcuuid:           common.GenerateUUID(listenerID + tsID),
+				LBLcuuid:         lbLcuuid,
+				LBListenerLcuuid: listenerID,
+				Type:             common.LB_SERVER_TYPE_IP,
+				IP:               ip,
+				Port:             jTS.Get("protocol_port").MustInt(),
+				Protocol:         protocol,
+				VPCLcuuid:        o.toolDataSet.lbLcuuidToVPCLcuuid[lbLcuuid],
+			}
+			vmLcuuid := o.toolDataSet.keyToVMLcuuid[SubnetIPKey{jTS.Get("subnet_id").MustString(), ip}]
+			if o.toolDataSet.vmLcuuidToExists[vmLcuuid] {
+				targetServer.Type = common.LB_SERVER_TYPE_VM
+				targetServer.VMLcuuid = vmLcuuid
+			}
+			lbTargetServers = append(lbTargetServers, targetServer)
+		}
+	}
+	return
+}
@@ -0,0 +1,153 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openstack
+
+import (
+	"fmt"
+
+	"github.com/bitly/go-simplejson"
+
+	cloudcommon "github.com/deepflowio/deepflow/server/controller/cloud/common"
+	"github.com/deepflowio/deepflow/server/controller/cloud/model"
+	"github.com/deepflowio/deepflow/server/controller/common"
+)
+
+func getProjectID(jData *simplejson.Json) string {
+	projectID := jData.Get("project_id").MustString()
+	if projectID == "" {
+		projectID = jData.Get("tenant_id").MustString()
+	}
+	return projectID
+}
+
+// 需在 getVPCs 之后调用，未连接路由器的网络归属到所在项目的默认 VPC，返回新建的项目默认 VPC
+func (o *OpenStack) getNetworks(region Region) ([]model.Network, []model.Subnet, []model.VPC, error) {
+	jNetworks, err := o.getRawData(fmt.Sprintf("%s/networks", region.networkURL), "networks")
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	var networks []model.Network
+	var vpcs []model.VPC
+	for i := range jNetworks {
+		jn := jNetworks[i]
+		name := jn.Get("name").MustString()
+		if !cloudcommon.CheckJsonAttributes(jn, []string{"id", "name"}) {
+			log.Infof("exclude network: %s, missing attr", name)
+			continue
+		}
+		id := jn.Get("id").MustString()
+		vpcLcuuid, ok := o.toolDataSet.networkIDToVPCLcuuid[id]
+		if !ok {
+			var vpc model.VPC
+			vpc, ok = o.getProjectVPC(region, getProjectID(jn))
+			if !ok {
+				vpcs = append(vpcs, vpc)
+			}
+			vpcLcuuid = vpc.Lcuuid
+			o.toolDataSet.networkIDToVPCLcuuid[id] = vpcLcuuid
+		}
+		external := jn.Get("router:external").MustBool()
+		netType := common.NETWORK_TYPE_LAN
+		if external {
+			netType = common.NETWORK_TYPE_WAN
+		}
+		segmentationID := jn.Get("provider:segmentation_id").MustInt()
+		networks = append(
+			networks,
+			model.Network{
+				Lcuuid:         id,
+				Name:           name,
+				SegmentationID: segmentationID,
+				TunnelID:       segmentationID,
+				Shared:         jn.Get("shared").MustBool(),
+				External:       external,
+				NetType:        netType,
+				VPCLcuuid:      vpcLcuuid,
+				RegionLcuuid:   region.lcuuid,
+			},
+		)
+		o.toolDataSet.networkIDToExternal[id] = external
+		o.toolDataSet.regionLcuuidToResourceNum[region.lcuuid]++
+	}
+
+	subnets, err := o.getSubnets(region)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return networks, subnets, vpcs, nil
+}
+
+// 返回项目默认 VPC 及其是否已经创建
+func (o *OpenStack) getProjectVPC(region Region, projectID string) (model.VPC, bool) {
+	lcuuid := common.GenerateUUID(projectID + "_" + region.name + "_" + o.lcuuidGenerate)
+	name, ok := o.toolDataSet.projectIDToName[projectID]
+	if !ok {
+		name = projectID
+	}
+	vpc := model.VPC{
+		Lcuuid:       lcuuid,
+		Name:         name,
+		RegionLcuuid: region.lcuuid,
+	}
+	if o.toolDataSet.projectVPCLcuuidToExists[lcuuid] {
+		return vpc, true
+	}
+	o.toolDataSet.projectVPCLcuuidToExists[lcuuid] = true
+	o.toolDataSet.regionLcuuidToResourceNum[region.lcuuid]++
+	return vpc, false
+}
+
+func (o *OpenStack) getSubnets(region Region) ([]model.Subnet, error) {
+	jSubnets, err := o.getRawData(fmt.Sprintf("%s/subnets", region.networkURL), "subnets")
+	if err != nil {
+		return nil, err
+	}
+
+	var subnets []model.Subnet
+	requiredAttrs := []string{"id", "name", "cidr", "network_id"}
+	for i := range jSubnets {
+		js := jSubnets[i]
+		name := js.Get("name").MustString()
+		if !cloudcommon.CheckJsonAttributes(js, requiredAttrs) {
+			log.Infof("exclude subnet: %s, missing attr", name)
+			continue
+		}
+		id := js.Get("id").MustString()
+		networkID := js.Get("network_id").MustString()
+		vpcLcuuid, ok := o.toolDataSet.networkIDToVPCLcuuid[networkID]
+		if !ok {
+			log.Infof("exclude subnet: %s, missing network info", id)
+			continue
+		}
+		if name == "" {
+			name = id
+		}
+		subnets = append(
+			subnets,
+			model.Subnet{
+				Lcuuid:        id,
+				Name:          name,
+				CIDR:          js.Get("cidr").MustString(),
+				GatewayIP:     js.Get("gateway_ip").MustString(),
+				NetworkLcuuid: networkID,
+				VPCLcuuid:     vpcLcuuid,
+			},
+		)
+	}
+	return subnets, nil
+}
@@ -0,0 +1,230 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openstack
+
+import (
+	"time"
+
+	"github.com/bitly/go-simplejson"
+	"github.com/op/go-logging"
+
+	cloudcommon "github.com/deepflowio/deepflow/server/controller/cloud/common"
+	"github.com/deepflowio/deepflow/server/controller/cloud/config"
+	"github.com/deepflowio/deepflow/server/controller/cloud/model"
+	"github.com/deepflowio/deepflow/server/controller/common"
+	"github.com/deepflowio/deepflow/server/controller/db/mysql"
+	"github.com/deepflowio/deepflow/server/controller/statsd"
+)
+
+var log = logging.MustGetLogger("cloud.openstack")
+
+type OpenStack struct {
+	lcuuid         string
+	lcuuidGenerate string
+	name           string
+	httpTimeout    int
+	config         *Config
+	token          *Token             // 缓存 token 及服务目录，快过期时重新申请
+	regions        []Region           // 服务目录中需要同步的区域
+	toolDataSet    *ToolDataSet       // 处理资源数据时，构建的需要提供给其他资源使用的工具数据
+	cloudStatsd    statsd.CloudStatsd // 性能监控
+	debugger       *cloudcommon.Debugger
+}
+
+func NewOpenStack(domain mysql.Domain, globalCloudCfg config.CloudConfig) (*OpenStack, error) {
+	conf := &Config{}
+	err := conf.LoadFromString(domain.Config)
+	if err != nil {
+		return nil, err
+	}
+	return &OpenStack{
+		lcuuid: domain.Lcuuid,
+		// TODO: display_name后期需要修改为uuid_generate
+		lcuuidGenerate: domain.DisplayName,
+		name:           domain.Name,
+		httpTimeout:    globalCloudCfg.HTTPTimeout,
+		config:         conf,
+		debugger:       cloudcommon.NewDebugger(domain.Name),
+	}, nil
+}
+
+func (o *OpenStack) ClearDebugLog() {
+	o.debugger.Clear()
+}
+
+func (o *OpenStack) CheckAuth() error {
+	_, err := o.createToken()
+	return err
+}
+
+func (o *OpenStack) GetCloudData() (model.Resource, error) {
+	o.cloudStatsd = statsd.NewCloudStatsd()
+	o.toolDataSet = NewToolDataSet()
+	var resource model.Resource
+	token, err := o.getToken()
+	if err != nil {
+		return resource, err
+	}
+
+	regions, err := o.getRegions(token)
+	if err != nil {
+		return resource, err
+	}
+	o.getProjects()
+
+	var azs []model.AZ
+	for _, region := range o.regions {
+		log.Infof("get region (%s) resources", region.name)
+		rAZs, err := o.getAZs(region)
+		if err != nil {
+			return resource, err
+		}
+		azs = append(azs, rAZs...)
+
+		hosts, err := o.getHosts(region)
+		if err != nil {
+			return resource, err
+		}
+		resource.Hosts = append(resource.Hosts, hosts...)
+
+		vpcs, vrouters, routingTables, err := o.getVPCs(region)
+		if err != nil {
+			return resource, err
+		}
+		resource.VPCs = append(resource.VPCs, vpcs...)
+		resource.VRouters = append(resource.VRouters, vrouters...)
+		resource.RoutingTables = append(resource.RoutingTables, routingTables...)
+
+		networks, subnets, vpcs, err := o.getNetworks(region)
+		if err != nil {
+			return resource, err
+		}
+		resource.Networks = append(resource.Networks, networks...)
+		resource.Subnets = append(resource.Subnets, subnets...)
+		resource.VPCs = append(resource.VPCs, vpcs...)
+
+		dhcpPorts, vifs, ips, err := o.getVInterfaces(region)
+		if err != nil {
+			return resource, err
+		}
+		resource.DHCPPorts = append(resource.DHCPPorts, dhcpPorts...)
+		resource.VInterfaces = append(resource.VInterfaces, vifs...)
+		resource.IPs = append(resource.IPs, ips...)
+
+		vms, err := o.getVMs(region)
+		if err != nil {
+			return resource, err
+		}
+		resource.VMs = append(resource.VMs, vms...)
+
+		fIPs, err := o.getFloatingIPs(region)
+		if err != nil {
+			return resource, err
+		}
+		resource.FloatingIPs = append(resource.FloatingIPs, fIPs...)
+
+		sgs, sgRules, vmSGs, err := o.getSecurityGroups(region)
+		if err != nil {
+			return resource, err
+		}
+		resource.SecurityGroups = append(resource.SecurityGroups, sgs...)
+		resource.SecurityGroupRules = append(resource.SecurityGroupRules, sgRules...)
+		resource.VMSecurityGroups = append(resource.VMSecurityGroups, vmSGs...)
+
+		lbs, listeners, targetServers, vifs, ips, err := o.getLBs(region)
+		if err != nil {
+			return resource, err
+		}
+		resource.LBs = append(resource.LBs, lbs...)
+		resource.LBListeners = append(resource.LBListeners, listeners...)
+		resource.LBTargetServers = append(resource.LBTargetServers, targetServers...)
+		resource.VInterfaces = append(resource.VInterfaces, vifs...)
+		resource.IPs = append(resource.IPs, ips...)
+	}
+
+	log.Debugf("region resource num info: %v", o.toolDataSet.regionLcuuidToResourceNum)
+	log.Debugf("az resource num info: %v", o.toolDataSet.azLcuuidToResourceNum)
+	resource.Regions = cloudcommon.EliminateEmptyRegions(regions, o.toolDataSet.regionLcuuidToResourceNum)
+	resource.AZs = cloudcommon.EliminateEmptyAZs(azs, o.toolDataSet.azLcuuidToResourceNum)
+
+	o.cloudStatsd.RefreshResCount(resource)
+	statsd.MetaStatsd.RegisterStatsdTable(o)
+
+	o.debugger.Refresh()
+	return resource, nil
+}
+
+func (o *OpenStack) GetStatter() statsd.StatsdStatter {
+	globalTags := map[string]string{
+		"domain_name": o.name,
+		"domain":      o.lcuuid,
+		"platform":    common.OPENSTACK_EN,
+	}
+
+	return statsd.StatsdStatter{
+		GlobalTags: globalTags,
+		Element:    statsd.GetCloudStatsd(o.cloudStatsd),
+	}
+}
+
+// 分页数据通过 <resultKey>_links 中的 next 链接获取，nova、neutron 及 octavia 均支持
+func (o *OpenStack) getRawData(url, resultKey string) (jsonList []*simplejson.Json, err error) {
+	statsdAPIStartTime := time.Now()
+	statsdAPIDataCount := 0
+
+	token, err := o.getToken()
+	if err != nil {
+		return
+	}
+	nextURL := url
+	for nextURL != "" {
+		resp, err := cloudcommon.RequestGet(nextURL, token.token, time.Duration(o.httpTimeout))
+		if err != nil {
+			return []*simplejson.Json{}, err
+		}
+
+		jData := resp.Get(resultKey)
+		curCount := len(jData.MustArray())
+		for i := 0; i < curCount; i++ {
+			jsonList = append(jsonList, jData.GetIndex(i))
+		}
+		statsdAPIDataCount += curCount
+		if curCount == 0 {
+			break
+		}
+
+		lastURL := nextURL
+		nextURL = ""
+		jLinks := resp.Get(resultKey + "_links")
+		for i := range jLinks.MustArray() {
+			jLink := jLinks.GetIndex(i)
+			if jLink.Get("rel").MustString() == "next" {
+				nextURL = jLink.Get("href").MustString()
+				break
+			}
+		}
+		if nextURL == lastURL {
+			break
+		}
+	}
+
+	o.cloudStatsd.RefreshAPICost(resultKey, statsdAPIStartTime)
+	o.cloudStatsd.RefreshAPICount(resultKey, statsdAPIDataCount)
+
+	o.debugger.WriteJson(resultKey, url, jsonList)
+	return
+}
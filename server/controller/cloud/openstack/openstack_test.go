@@ -0,0 +1,200 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openstack
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	cloudcommon "github.com/deepflowio/deepflow/server/controller/cloud/common"
+	"github.com/deepflowio/deepflow/server/controller/cloud/config"
+	"github.com/deepflowio/deepflow/server/controller/common"
+	"github.com/deepflowio/deepflow/server/controller/statsd"
+	statsdconfig "github.com/deepflowio/deepflow/server/controller/statsd/config"
+)
+
+const (
+	testUserName = "admin"
+	testPassword = "password"
+	testToken    = "gAAAAABtest-token"
+)
+
+// 请求路径与录制的接口返回文件的对应关系
+var testRoutes = map[string]string{
+	"/identity/v3/projects":                        "projects.json",
+	"/compute/v2.1/os-availability-zone/detail":    "availability_zones.json",
+	"/compute/v2.1/os-hypervisors/detail":          "hypervisors.json",
+	"/compute/v2.1/servers/detail":                 "servers.json",
+	"/network/v2.0/routers":                        "routers.json",
+	"/network/v2.0/networks":                       "networks.json",
+	"/network/v2.0/subnets":                        "subnets.json",
+	"/network/v2.0/ports":                          "ports.json",
+	"/network/v2.0/floatingips":                    "floatingips.json",
+	"/network/v2.0/security-groups":                "security_groups.json",
+	"/load-balancer/v2/lbaas/loadbalancers":        "loadbalancers.json",
+	"/load-balancer/v2/lbaas/listeners":            "listeners.json",
+	"/load-balancer/v2/lbaas/pools/pool-1/members": "members.json",
+}
+
+func newTestServer(t *testing.T) *httptest.Server {
+	var srv *httptest.Server
+	writeFile := func(w http.ResponseWriter, status int, name string) {
+		content, err := os.ReadFile(filepath.Join("testfiles", name))
+		if err != nil {
+			t.Fatalf("read test file %s failed: %v", name, err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		w.Write([]byte(strings.ReplaceAll(string(content), "{{ENDPOINT}}", srv.URL)))
+	}
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/identity/v3/auth/tokens" {
+			var body struct {
+				Auth struct {
+					Identity struct {
+						Password struct {
+							User struct {
+								Name     string `json:"name"`
+								Password string `json:"password"`
+							} `json:"user"`
+						} `json:"password"`
+					} `json:"identity"`
+				} `json:"auth"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			user := body.Auth.Identity.Password.User
+			if r.Method != http.MethodPost || user.Name != testUserName || user.Password != testPassword {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Header().Set("X-Subject-Token", testToken)
+			writeFile(w, http.StatusCreated, "auth_tokens.json")
+			return
+		}
+		if r.Header.Get("X-Auth-Token") != testToken {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		name, ok := testRoutes[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if marker := r.URL.Query().Get("marker"); marker != "" {
+			name = strings.TrimSuffix(name, ".json") + "_marker_" + marker + ".json"
+		}
+		writeFile(w, http.StatusOK, name)
+	}))
+	return srv
+}
+
+func newTestOpenStack(url, password string) *OpenStack {
+	return &OpenStack{
+		lcuuid:         "openstack-lcuuid",
+		lcuuidGenerate: "test_openstack",
+		name:           "test_openstack",
+		httpTimeout:    30,
+		config: &Config{
+			URL:               url + "/identity/v3",
+			UserName:          testUserName,
+			Password:          password,
+			UserDomainName:    DEFAULT_DOMAIN_NAME,
+			ProjectName:       "admin",
+			ProjectDomainName: DEFAULT_DOMAIN_NAME,
+			EndpointType:      DEFAULT_ENDPOINT_TYPE,
+		},
+		debugger: cloudcommon.NewDebugger("test_openstack"),
+	}
+}
+
+func TestOpenStack(t *testing.T) {
+	config.SetCloudGlobalConfig(config.CloudConfig{})
+	statsd.NewStatsdMonitor(statsdconfig.StatsdConfig{})
+	srv := newTestServer(t)
+	defer srv.Close()
+
+	Convey("TestOpenStackCheckAuth", t, func() {
+		So(newTestOpenStack(srv.URL, testPassword).CheckAuth(), ShouldBeNil)
+		So(newTestOpenStack(srv.URL, "wrong").CheckAuth(), ShouldNotBeNil)
+	})
+
+	Convey("TestOpenStackGetCloudData", t, func() {
+		openstack := newTestOpenStack(srv.URL, testPassword)
+		data, err := openstack.GetCloudData()
+		So(err, ShouldBeNil)
+
+		Convey("openstackResource number should be equal", func() {
+			// RegionTwo 缺少 network 服务，不同步
+			So(len(data.Regions), ShouldEqual, 1)
+			// internal 可用区不同步
+			So(len(data.AZs), ShouldEqual, 1)
+			// 不属于任何可用区的宿主机不同步
+			So(len(data.Hosts), ShouldEqual, 1)
+			// 一个路由器 VPC，两个项目 VPC
+			So(len(data.VPCs), ShouldEqual, 3)
+			So(len(data.VRouters), ShouldEqual, 1)
+			So(len(data.RoutingTables), ShouldEqual, 1)
+			So(len(data.Networks), ShouldEqual, 3)
+			So(len(data.Subnets), ShouldEqual, 3)
+			So(len(data.DHCPPorts), ShouldEqual, 1)
+			// 两个路由器接口、一个 DHCP 接口、两个云服务器接口以及一个负载均衡器接口
+			So(len(data.VInterfaces), ShouldEqual, 6)
+			So(len(data.IPs), ShouldEqual, 6)
+			// 分页获取的云服务器没有网卡，不同步
+			So(len(data.VMs), ShouldEqual, 2)
+			So(len(data.FloatingIPs), ShouldEqual, 1)
+			So(len(data.SecurityGroups), ShouldEqual, 1)
+			So(len(data.SecurityGroupRules), ShouldEqual, 6)
+			So(len(data.VMSecurityGroups), ShouldEqual, 2)
+			So(len(data.LBs), ShouldEqual, 1)
+			So(len(data.LBListeners), ShouldEqual, 1)
+			So(len(data.LBTargetServers), ShouldEqual, 2)
+		})
+
+		Convey("openstackResource relations should be correct", func() {
+			routerVPCLcuuid := common.GenerateUUID("router-1")
+			for _, vm := range data.VMs {
+				switch vm.Label {
+				case "vm-1":
+					So(vm.VPCLcuuid, ShouldEqual, routerVPCLcuuid)
+					So(vm.LaunchServer, ShouldEqual, "10.0.0.11")
+					So(vm.State, ShouldEqual, common.VM_STATE_RUNNING)
+				case "vm-2":
+					So(vm.VPCLcuuid, ShouldNotEqual, routerVPCLcuuid)
+					So(vm.State, ShouldEqual, common.VM_STATE_STOPPED)
+				}
+			}
+			So(data.LBs[0].VPCLcuuid, ShouldEqual, routerVPCLcuuid)
+			So(data.LBs[0].Model, ShouldEqual, common.LB_MODEL_EXTERNAL)
+			So(data.LBs[0].VIP, ShouldEqual, "192.168.1.100,172.16.0.21")
+
+			tsTypes := map[int]int{}
+			for _, ts := range data.LBTargetServers {
+				tsTypes[ts.Type]++
+			}
+			So(tsTypes[common.LB_SERVER_TYPE_VM], ShouldEqual, 1)
+			So(tsTypes[common.LB_SERVER_TYPE_IP], ShouldEqual, 1)
+		})
+	})
+}
@@ -0,0 +1,150 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openstack
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	cloudcommon "github.com/deepflowio/deepflow/server/controller/cloud/common"
+	"github.com/deepflowio/deepflow/server/controller/cloud/model"
+	"github.com/deepflowio/deepflow/server/controller/common"
+)
+
+type Region struct {
+	name       string
+	lcuuid     string
+	computeURL string
+	networkURL string
+	lbURL      string // 未部署 octavia 时为空
+}
+
+func (o *OpenStack) getRegions(token *Token) ([]model.Region, error) {
+	names := []string{}
+	for name := range token.catalog {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var regions []model.Region
+	o.regions = []Region{}
+	for _, name := range names {
+		if len(o.config.IncludeRegions) > 0 && !common.Contains(o.config.IncludeRegions, name) {
+			log.Infof("exclude region: %s, not included", name)
+			continue
+		}
+		if common.Contains(o.config.ExcludeRegions, name) {
+			log.Infof("exclude region: %s", name)
+			continue
+		}
+		endpoints := token.catalog[name]
+		if endpoints[SERVICE_TYPE_COMPUTE] == "" || endpoints[SERVICE_TYPE_NETWORK] == "" {
+			log.Infof("exclude region: %s, missing compute or network endpoint", name)
+			continue
+		}
+
+		region := Region{
+			name:       name,
+			lcuuid:     o.getRegionLcuuid(name),
+			computeURL: endpoints[SERVICE_TYPE_COMPUTE],
+			networkURL: joinEndpoint(endpoints[SERVICE_TYPE_NETWORK], "v2.0"),
+		}
+		if endpoints[SERVICE_TYPE_LOAD_BALANCER] != "" {
+			region.lbURL = joinEndpoint(endpoints[SERVICE_TYPE_LOAD_BALANCER], "v2")
+		}
+		o.regions = append(o.regions, region)
+
+		// 页面指定区域时，资源均属于指定区域
+		if o.config.RegionLcuuid == "" {
+			regions = append(regions, model.Region{Lcuuid: region.lcuuid, Name: name})
+		}
+	}
+	if len(o.regions) == 0 {
+		return nil, fmt.Errorf("no region found in catalog of endpoint type (%s)", o.config.EndpointType)
+	}
+	return regions, nil
+}
+
+func (o *OpenStack) getRegionLcuuid(name string) string {
+	if o.config.RegionLcuuid != "" {
+		return o.config.RegionLcuuid
+	}
+	return common.GenerateUUID(name + "_" + o.lcuuidGenerate)
+}
+
+// 项目名称仅用于命名项目默认 VPC，非管理员账号无权限获取时使用项目ID
+func (o *OpenStack) getProjects() {
+	jProjects, err := o.getRawData(fmt.Sprintf("%s/projects", o.config.URL), "projects")
+	if err != nil {
+		log.Warningf("get projects failed, project id is used as name: %v", err)
+		return
+	}
+	for i := range jProjects {
+		jp := jProjects[i]
+		if !cloudcommon.CheckJsonAttributes(jp, []string{"id", "name"}) {
+			continue
+		}
+		o.toolDataSet.projectIDToName[jp.Get("id").MustString()] = jp.Get("name").MustString()
+	}
+}
+
+func (o *OpenStack) getAZLcuuid(region Region, zoneName string) string {
+	return common.GenerateUUID(region.name + "_" + zoneName + "_" + o.lcuuidGenerate)
+}
+
+func (o *OpenStack) getAZs(region Region) ([]model.AZ, error) {
+	jAZs, err := o.getRawData(fmt.Sprintf("%s/os-availability-zone/detail", region.computeURL), "availabilityZoneInfo")
+	if err != nil {
+		return nil, err
+	}
+
+	var azs []model.AZ
+	for i := range jAZs {
+		ja := jAZs[i]
+		zname := ja.Get("zoneName").MustString()
+		if !cloudcommon.CheckJsonAttributes(ja, []string{"zoneName"}) {
+			log.Infof("exclude az: %s, missing attr", zname)
+			continue
+		}
+		// 控制节点服务所在的可用区
+		if zname == "internal" {
+			continue
+		}
+		lcuuid := o.getAZLcuuid(region, zname)
+		azs = append(
+			azs,
+			model.AZ{
+				Lcuuid:       lcuuid,
+				Name:         zname,
+				RegionLcuuid: region.lcuuid,
+			},
+		)
+		for host := range ja.Get("hosts").MustMap() {
+			o.toolDataSet.hostNameToAZLcuuid[host] = lcuuid
+		}
+	}
+	return azs, nil
+}
+
+// catalog 中 network、load-balancer 的 endpoint 一般不带版本号
+func joinEndpoint(url, version string) string {
+	if strings.HasSuffix(url, "/"+version) {
+		return url
+	}
+	return url + "/" + version
+}
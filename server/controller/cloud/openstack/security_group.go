@@ -0,0 +1,174 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openstack
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/bitly/go-simplejson"
+
+	cloudcommon "github.com/deepflowio/deepflow/server/controller/cloud/common"
+	"github.com/deepflowio/deepflow/server/controller/cloud/model"
+	"github.com/deepflowio/deepflow/server/controller/common"
+)
+
+// 需在 getVMs 之后调用，虚拟机与安全组的关联关系来自虚拟机端口
+func (o *OpenStack) getSecurityGroups(region Region) ([]model.SecurityGroup, []model.SecurityGroupRule, []model.VMSecurityGroup, error) {
+	jSecurityGroups, err := o.getRawData(fmt.Sprintf("%s/security-groups", region.networkURL), "security_groups")
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	var securityGroups []model.SecurityGroup
+	var sgRules []model.SecurityGroupRule
+	for i := range jSecurityGroups {
+		jSG := jSecurityGroups[i]
+		name := jSG.Get("name").MustString()
+		if !cloudcommon.CheckJsonAttributes(jSG, []string{"id", "name"}) {
+			log.Infof("exclude security_group: %s, missing attr", name)
+			continue
+		}
+		id := jSG.Get("id").MustString()
+		securityGroups = append(
+			securityGroups,
+			model.SecurityGroup{
+				Lcuuid:       id,
+				Name:         name,
+				RegionLcuuid: region.lcuuid,
+			},
+		)
+		o.toolDataSet.securityGroupIDToExists[id] = true
+		o.toolDataSet.regionLcuuidToResourceNum[region.lcuuid]++
+		sgRules = append(sgRules, o.formatSecurityGroupRules(jSG.Get("security_group_rules"), id)...)
+	}
+
+	var vmSGs []model.VMSecurityGroup
+	for vmLcuuid, sgIDs := range o.toolDataSet.vmLcuuidToSecurityGroupIDs {
+		if !o.toolDataSet.vmLcuuidToExists[vmLcuuid] {
+			continue
+		}
+		for priority, sgID := range sgIDs {
+			if !o.toolDataSet.securityGroupIDToExists[sgID] {
+				continue
+			}
+			vmSGs = append(
+				vmSGs,
+				model.VMSecurityGroup{
+					Lcuuid:              common.GenerateUUID(vmLcuuid + sgID),
+					VMLcuuid:            vmLcuuid,
+					SecurityGroupLcuuid: sgID,
+					Priority:            priority,
+				},
+			)
+		}
+	}
+	return securityGroups, sgRules, vmSGs, nil
+}
+
+func (o *OpenStack) formatSecurityGroupRules(jRules *simplejson.Json, sgLcuuid string) []model.SecurityGroupRule {
+	var rules []model.SecurityGroupRule
+	var ingressPriority, egressPriority int
+	requiredAttrs := []string{"id", "direction", "ethertype"}
+	for i := range jRules.MustArray() {
+		jRule := jRules.GetIndex(i)
+		id := jRule.Get("id").MustString()
+		if !cloudcommon.CheckJsonAttributes(jRule, requiredAttrs) {
+			log.Infof("exclude security_group_rule: %s, missing attr", id)
+			continue
+		}
+		rule := model.SecurityGroupRule{
+			Lcuuid:              id,
+			SecurityGroupLcuuid: sgLcuuid,
+			LocalPortRange:      cloudcommon.PORT_RANGE_ALL,
+			Action:              cloudcommon.SECURITY_GROUP_RULE_ACCEPT,
+		}
+
+		var local, remote string
+		if jRule.Get("ethertype").MustString() == "IPv6" {
+			rule.EtherType = cloudcommon.SECURITY_GROUP_IPV6
+			local = cloudcommon.SUBNET_DEFAULT_CIDR_IPV6
+			remote = cloudcommon.SUBNET_DEFAULT_CIDR_IPV6
+		} else {
+			rule.EtherType = cloudcommon.SECURITY_GROUP_IPV4
+			local = cloudcommon.SUBNET_DEFAULT_CIDR_IPV4
+			remote = cloudcommon.SUBNET_DEFAULT_CIDR_IPV4
+		}
+
+		remoteGID := jRule.Get("remote_group_id").MustString()
+		remoteIP := jRule.Get("remote_ip_prefix").MustString()
+		if remoteIP != "" {
+			remote = remoteIP
+		} else if remoteGID != "" {
+			remote = remoteGID
+		}
+
+		if jRule.Get("direction").MustString() == "ingress" {
+			rule.Direction = cloudcommon.SECURITY_GROUP_RULE_INGRESS
+			rule.Priority = ingressPriority
+			local, remote = remote, local
+			ingressPriority++
+		} else {
+			rule.Direction = cloudcommon.SECURITY_GROUP_RULE_EGRESS
+			rule.Priority = egressPriority
+			egressPriority++
+		}
+		rule.Local = local
+		rule.Remote = remote
+
+		protocol := jRule.Get("protocol").MustString()
+		if protocol != "" {
+			rule.Protocol = strings.ToUpper(protocol)
+		} else {
+			rule.Protocol = cloudcommon.PROTOCOL_ALL
+		}
+
+		minPort := jRule.Get("port_range_min").MustInt()
+		maxPort := jRule.Get("port_range_max").MustInt()
+		if minPort != 0 && maxPort != 0 {
+			rule.RemotePortRange = fmt.Sprintf("%d-%d", minPort, maxPort)
+		} else {
+			rule.RemotePortRange = cloudcommon.PORT_RANGE_ALL
+		}
+
+		rules = append(rules, rule)
+	}
+
+	// 未匹配任何规则的流量被拒绝
+	directions := []int{cloudcommon.SECURITY_GROUP_RULE_EGRESS, cloudcommon.SECURITY_GROUP_RULE_INGRESS}
+	etherTypeToRemote := map[int]string{cloudcommon.SECURITY_GROUP_IPV4: cloudcommon.SUBNET_DEFAULT_CIDR_IPV4, cloudcommon.SECURITY_GROUP_IPV6: cloudcommon.SUBNET_DEFAULT_CIDR_IPV6}
+	for _, direction := range directions {
+		for etherType, remote := range etherTypeToRemote {
+			rule := model.SecurityGroupRule{
+				Lcuuid:              common.GenerateUUID(sgLcuuid + strconv.Itoa(direction) + remote),
+				SecurityGroupLcuuid: sgLcuuid,
+				Action:              cloudcommon.SECURITY_GROUP_RULE_DROP,
+				Direction:           direction,
+				EtherType:           etherType,
+				Protocol:            cloudcommon.PROTOCOL_ALL,
+				Local:               remote,
+				Remote:              remote,
+				LocalPortRange:      cloudcommon.PORT_RANGE_ALL,
+				RemotePortRange:     cloudcommon.PORT_RANGE_ALL,
+				Priority:            1000,
+			}
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
@@ -0,0 +1,125 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openstack
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	cloudcommon "github.com/deepflowio/deepflow/server/controller/cloud/common"
+)
+
+const (
+	SERVICE_TYPE_COMPUTE       = "compute"
+	SERVICE_TYPE_NETWORK       = "network"
+	SERVICE_TYPE_LOAD_BALANCER = "load-balancer"
+)
+
+type Token struct {
+	token     string
+	expiresAt time.Time
+	// region -> service type -> endpoint url
+	catalog map[string]map[string]string
+}
+
+// 离失效时间小于5m时重新申请token
+func (t *Token) isExpired() bool {
+	return time.Until(t.expiresAt) < 5*time.Minute
+}
+
+func (o *OpenStack) getToken() (*Token, error) {
+	if o.token == nil || o.token.isExpired() {
+		token, err := o.createToken()
+		if err != nil {
+			return nil, err
+		}
+		o.token = token
+	}
+	return o.token, nil
+}
+
+// keystone v3 password 认证，scope 为配置的项目，返回 token 及服务目录
+func (o *OpenStack) createToken() (*Token, error) {
+	authBody := map[string]interface{}{
+		"auth": map[string]interface{}{
+			"identity": map[string]interface{}{
+				"methods": []string{"password"},
+				"password": map[string]interface{}{
+					"user": map[string]interface{}{
+						"domain": map[string]interface{}{
+							"name": o.config.UserDomainName,
+						},
+						"name":     o.config.UserName,
+						"password": o.config.Password,
+					},
+				},
+			},
+			"scope": map[string]interface{}{
+				"project": map[string]interface{}{
+					"domain": map[string]interface{}{
+						"name": o.config.ProjectDomainName,
+					},
+					"name": o.config.ProjectName,
+				},
+			},
+		},
+	}
+	resp, err := cloudcommon.RequestPost(fmt.Sprintf("%s/auth/tokens", o.config.URL), time.Duration(o.httpTimeout), authBody)
+	if err != nil {
+		return nil, err
+	}
+	token := &Token{
+		token:   resp.Get("X-Subject-Token").MustString(),
+		catalog: make(map[string]map[string]string),
+	}
+	if token.token == "" {
+		return nil, errors.New("keystone response has no X-Subject-Token")
+	}
+	expiresAt := resp.Get("token").Get("expires_at").MustString()
+	token.expiresAt, err = time.Parse(time.RFC3339, expiresAt)
+	if err != nil {
+		log.Errorf("parse token expires_at (%s) failed: %v", expiresAt, err)
+		token.expiresAt = time.Now().Add(time.Hour)
+	}
+
+	jServices := resp.Get("token").Get("catalog")
+	for i := range jServices.MustArray() {
+		jService := jServices.GetIndex(i)
+		sType := jService.Get("type").MustString()
+		if sType != SERVICE_TYPE_COMPUTE && sType != SERVICE_TYPE_NETWORK && sType != SERVICE_TYPE_LOAD_BALANCER {
+			continue
+		}
+		jEndpoints := jService.Get("endpoints")
+		for j := range jEndpoints.MustArray() {
+			jEndpoint := jEndpoints.GetIndex(j)
+			if jEndpoint.Get("interface").MustString() != o.config.EndpointType {
+				continue
+			}
+			region := jEndpoint.Get("region_id").MustString()
+			if region == "" {
+				region = jEndpoint.Get("region").MustString()
+			}
+			if _, ok := token.catalog[region]; !ok {
+				token.catalog[region] = make(map[string]string)
+			}
+			token.catalog[region][sType] = strings.TrimSuffix(jEndpoint.Get("url").MustString(), "/")
+		}
+	}
+	return token, nil
+}
@@ -0,0 +1,66 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openstack
+
+type ToolDataSet struct {
+	projectIDToName            map[string]string
+	hostNameToIP               map[string]string
+	hostNameToAZLcuuid         map[string]string
+	networkIDToVPCLcuuid       map[string]string
+	networkIDToExternal        map[string]bool
+	projectVPCLcuuidToExists   map[string]bool
+	portIDToMac                map[string]string
+	portIDToFloatingIP         map[string]string
+	portIDToVMLcuuid           map[string]string
+	vmLcuuidToExists           map[string]bool
+	vmLcuuidToVPCLcuuid        map[string]string
+	vmLcuuidToSecurityGroupIDs map[string][]string
+	keyToVMLcuuid              map[SubnetIPKey]string
+	securityGroupIDToExists    map[string]bool
+	lbLcuuidToVPCLcuuid        map[string]string
+	lbLcuuidToIP               map[string]string
+	regionLcuuidToResourceNum  map[string]int
+	azLcuuidToResourceNum      map[string]int
+}
+
+func NewToolDataSet() *ToolDataSet {
+	return &ToolDataSet{
+		projectIDToName:            make(map[string]string),
+		hostNameToIP:               make(map[string]string),
+		hostNameToAZLcuuid:         make(map[string]string),
+		networkIDToVPCLcuuid:       make(map[string]string),
+		networkIDToExternal:        make(map[string]bool),
+		projectVPCLcuuidToExists:   make(map[string]bool),
+		portIDToMac:                make(map[string]string),
+		portIDToFloatingIP:         make(map[string]string),
+		portIDToVMLcuuid:           make(map[string]string),
+		vmLcuuidToExists:           make(map[string]bool),
+		vmLcuuidToVPCLcuuid:        make(map[string]string),
+		vmLcuuidToSecurityGroupIDs: make(map[string][]string),
+		keyToVMLcuuid:              make(map[SubnetIPKey]string),
+		securityGroupIDToExists:    make(map[string]bool),
+		lbLcuuidToVPCLcuuid:        make(map[string]string),
+		lbLcuuidToIP:               make(map[string]string),
+		regionLcuuidToResourceNum:  make(map[string]int),
+		azLcuuidToResourceNum:      make(map[string]int),
+	}
+}
+
+type SubnetIPKey struct {
+	SubnetLcuuid string
+	IP           string
+}
@@ -0,0 +1,162 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openstack
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bitly/go-simplejson"
+
+	cloudcommon "github.com/deepflowio/deepflow/server/controller/cloud/common"
+	"github.com/deepflowio/deepflow/server/controller/cloud/model"
+	"github.com/deepflowio/deepflow/server/controller/common"
+)
+
+const (
+	DEVICE_OWNER_VM_PRE                  = "compute:"
+	DEVICE_OWNER_ROUTER_GW               = "network:router_gateway"
+	DEVICE_OWNER_ROUTER_IFACE            = "network:router_interface"
+	DEVICE_OWNER_ROUTER_IFACE_DVR        = "network:router_interface_distributed"
+	DEVICE_OWNER_ROUTER_IFACE_HA         = "network:ha_router_replicated_interface"
+	DEVICE_OWNER_DHCP                    = "network:dhcp"
+	DEVICE_OWNER_ROUTER_IFACE_URL_FILTER = "device_owner=network:router_interface&device_owner=network:router_interface_distributed&device_owner=network:ha_router_replicated_interface"
+)
+
+var routerInterfaceDeviceOwners = []string{DEVICE_OWNER_ROUTER_IFACE, DEVICE_OWNER_ROUTER_IFACE_DVR, DEVICE_OWNER_ROUTER_IFACE_HA}
+
+// 需在 getNetworks 之后调用，负载均衡器及浮动IP的端口在各自的资源中处理
+func (o *OpenStack) getVInterfaces(region Region) ([]model.DHCPPort, []model.VInterface, []model.IP, error) {
+	jPorts, err := o.getRawData(fmt.Sprintf("%s/ports", region.networkURL), "ports")
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	var dhcpPorts []model.DHCPPort
+	var vifs []model.VInterface
+	var ips []model.IP
+	requiredAttrs := []string{"id", "mac_address", "network_id", "device_id", "device_owner"}
+	for i := range jPorts {
+		jPort := jPorts[i]
+		mac := jPort.Get("mac_address").MustString()
+		if !cloudcommon.CheckJsonAttributes(jPort, requiredAttrs) {
+			log.Infof("exclude vinterface: %s, missing attr", mac)
+			continue
+		}
+		id := jPort.Get("id").MustString()
+		o.toolDataSet.portIDToMac[id] = mac
+		networkID := jPort.Get("network_id").MustString()
+		vpcLcuuid, ok := o.toolDataSet.networkIDToVPCLcuuid[networkID]
+		if !ok {
+			log.Infof("exclude vinterface: %s, missing network info", mac)
+			continue
+		}
+
+		deviceID := jPort.Get("device_id").MustString()
+		deviceOwner := jPort.Get("device_owner").MustString()
+		var deviceType int
+		switch {
+		case strings.HasPrefix(deviceOwner, DEVICE_OWNER_VM_PRE):
+			if deviceID == "" {
+				log.Infof("exclude vinterface: %s, missing vm info", mac)
+				continue
+			}
+			deviceType = common.VIF_DEVICE_TYPE_VM
+			o.formatVMRelatedToolDataSet(jPort, id, deviceID, vpcLcuuid)
+		case deviceOwner == DEVICE_OWNER_ROUTER_GW || common.Contains(routerInterfaceDeviceOwners, deviceOwner):
+			deviceType = common.VIF_DEVICE_TYPE_VROUTER
+		case deviceOwner == DEVICE_OWNER_DHCP:
+			deviceType = common.VIF_DEVICE_TYPE_DHCP_PORT
+			deviceID = id
+			dhcpPort := model.DHCPPort{
+				Lcuuid:       id,
+				Name:         fmt.Sprintf("%s_DHCP", networkID),
+				VPCLcuuid:    vpcLcuuid,
+				AZLcuuid:     o.toolDataSet.hostNameToAZLcuuid[jPort.Get("binding:host_id").MustString()],
+				RegionLcuuid: region.lcuuid,
+			}
+			dhcpPorts = append(dhcpPorts, dhcpPort)
+			if dhcpPort.AZLcuuid != "" {
+				o.toolDataSet.azLcuuidToResourceNum[dhcpPort.AZLcuuid]++
+			}
+		default:
+			log.Debugf("exclude vinterface: %s, %s", mac, deviceOwner)
+			continue
+		}
+
+		vifType := common.VIF_TYPE_LAN
+		if o.toolDataSet.networkIDToExternal[networkID] {
+			vifType = common.VIF_TYPE_WAN
+		}
+		vif := model.VInterface{
+			Lcuuid:        id,
+			Name:          jPort.Get("name").MustString(),
+			Type:          vifType,
+			Mac:           mac,
+			DeviceLcuuid:  deviceID,
+			DeviceType:    deviceType,
+			NetworkLcuuid: networkID,
+			VPCLcuuid:     vpcLcuuid,
+			RegionLcuuid:  region.lcuuid,
+		}
+		vifs = append(vifs, vif)
+		ips = append(ips, o.formatIPs(jPort, vif)...)
+	}
+	return dhcpPorts, vifs, ips, nil
+}
+
+// 虚拟机所属 VPC 取其第一个端口所在网络的 VPC
+func (o *OpenStack) formatVMRelatedToolDataSet(jPort *simplejson.Json, portID, vmLcuuid, vpcLcuuid string) {
+	o.toolDataSet.portIDToVMLcuuid[portID] = vmLcuuid
+	if _, ok := o.toolDataSet.vmLcuuidToVPCLcuuid[vmLcuuid]; !ok {
+		o.toolDataSet.vmLcuuidToVPCLcuuid[vmLcuuid] = vpcLcuuid
+	}
+	jSGs := jPort.Get("security_groups")
+	for i := range jSGs.MustArray() {
+		sgID := jSGs.GetIndex(i).MustString()
+		if sgID != "" && !common.Contains(o.toolDataSet.vmLcuuidToSecurityGroupIDs[vmLcuuid], sgID) {
+			o.toolDataSet.vmLcuuidToSecurityGroupIDs[vmLcuuid] = append(o.toolDataSet.vmLcuuidToSecurityGroupIDs[vmLcuuid], sgID)
+		}
+	}
+	jIPs := jPort.Get("fixed_ips")
+	for i := range jIPs.MustArray() {
+		jIP := jIPs.GetIndex(i)
+		o.toolDataSet.keyToVMLcuuid[SubnetIPKey{jIP.Get("subnet_id").MustString(), jIP.Get("ip_address").MustString()}] = vmLcuuid
+	}
+}
+
+func (o *OpenStack) formatIPs(jPort *simplejson.Json, vif model.VInterface) (ips []model.IP) {
+	jIPs := jPort.Get("fixed_ips")
+	for i := range jIPs.MustArray() {
+		jIP := jIPs.GetIndex(i)
+		if !cloudcommon.CheckJsonAttributes(jIP, []string{"subnet_id", "ip_address"}) {
+			continue
+		}
+		ipAddr := jIP.Get("ip_address").MustString()
+		ips = append(
+			ips,
+			model.IP{
+				Lcuuid:           common.GenerateUUID(vif.Lcuuid + ipAddr),
+				VInterfaceLcuuid: vif.Lcuuid,
+				IP:               ipAddr,
+				SubnetLcuuid:     jIP.Get("subnet_id").MustString(),
+				RegionLcuuid:     vif.RegionLcuuid,
+			},
+		)
+	}
+	return
+}
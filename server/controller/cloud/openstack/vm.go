@@ -0,0 +1,89 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openstack
+
+import (
+	"fmt"
+	"time"
+
+	cloudcommon "github.com/deepflowio/deepflow/server/controller/cloud/common"
+	"github.com/deepflowio/deepflow/server/controller/cloud/model"
+	"github.com/deepflowio/deepflow/server/controller/common"
+)
+
+var STATE_CONVERTION = map[string]int{
+	"ACTIVE":    common.VM_STATE_RUNNING,
+	"SHUTOFF":   common.VM_STATE_STOPPED,
+	"STOPPED":   common.VM_STATE_STOPPED,
+	"SUSPENDED": common.VM_STATE_STOPPED,
+	"PAUSED":    common.VM_STATE_STOPPED,
+}
+
+// 需在 getVInterfaces 之后调用，虚拟机的 VPC 由其端口所在网络确定
+func (o *OpenStack) getVMs(region Region) ([]model.VM, error) {
+	jVMs, err := o.getRawData(fmt.Sprintf("%s/servers/detail?all_tenants=1", region.computeURL), "servers")
+	if err != nil {
+		return nil, err
+	}
+
+	var vms []model.VM
+	requiredAttrs := []string{"id", "name", "status", "OS-EXT-AZ:availability_zone"}
+	for i := range jVMs {
+		jVM := jVMs[i]
+		name := jVM.Get("name").MustString()
+		if !cloudcommon.CheckJsonAttributes(jVM, requiredAttrs) {
+			log.Infof("exclude vm: %s, missing attr", name)
+			continue
+		}
+		id := jVM.Get("id").MustString()
+		vpcLcuuid, ok := o.toolDataSet.vmLcuuidToVPCLcuuid[id]
+		if !ok {
+			log.Infof("exclude vm: %s, missing vpc info", id)
+			continue
+		}
+		state, ok := STATE_CONVERTION[jVM.Get("status").MustString()]
+		if !ok {
+			state = common.VM_STATE_EXCEPTION
+		}
+		azLcuuid := o.getAZLcuuid(region, jVM.Get("OS-EXT-AZ:availability_zone").MustString())
+		vm := model.VM{
+			Lcuuid:       id,
+			Name:         name,
+			Label:        id,
+			HType:        common.VM_HTYPE_VM_C,
+			State:        state,
+			LaunchServer: o.toolDataSet.hostNameToIP[jVM.Get("OS-EXT-SRV-ATTR:host").MustString()],
+			VPCLcuuid:    vpcLcuuid,
+			AZLcuuid:     azLcuuid,
+			RegionLcuuid: region.lcuuid,
+		}
+		created := jVM.Get("created").MustString()
+		if created != "" {
+			createdAt, err := time.Parse(time.RFC3339, created)
+			if err != nil {
+				log.Errorf("parse created failed: %s", created)
+			} else {
+				vm.CreatedAt = createdAt
+			}
+		}
+		vms = append(vms, vm)
+		o.toolDataSet.vmLcuuidToExists[id] = true
+		o.toolDataSet.azLcuuidToResourceNum[azLcuuid]++
+		o.toolDataSet.regionLcuuidToResourceNum[region.lcuuid]++
+	}
+	return vms, nil
+}
@@ -0,0 +1,121 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openstack
+
+import (
+	"fmt"
+
+	"github.com/bitly/go-simplejson"
+
+	cloudcommon "github.com/deepflowio/deepflow/server/controller/cloud/common"
+	"github.com/deepflowio/deepflow/server/controller/cloud/model"
+	"github.com/deepflowio/deepflow/server/controller/common"
+)
+
+// 每个路由器对应一个 VPC，连接到路由器的网络属于该 VPC，未连接路由器的网络属于项目默认 VPC
+func (o *OpenStack) getVPCs(region Region) ([]model.VPC, []model.VRouter, []model.RoutingTable, error) {
+	jRouters, err := o.getRawData(fmt.Sprintf("%s/routers", region.networkURL), "routers")
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	var vpcs []model.VPC
+	var vrouters []model.VRouter
+	var routingTables []model.RoutingTable
+	routerIDToVPCLcuuid := make(map[string]string)
+	for i := range jRouters {
+		jr := jRouters[i]
+		name := jr.Get("name").MustString()
+		if !cloudcommon.CheckJsonAttributes(jr, []string{"id", "name"}) {
+			log.Infof("exclude router: %s, missing attr", name)
+			continue
+		}
+		id := jr.Get("id").MustString()
+		if name == "" {
+			name = id
+		}
+		vpcLcuuid := common.GenerateUUID(id)
+		vpcs = append(
+			vpcs,
+			model.VPC{
+				Lcuuid:       vpcLcuuid,
+				Name:         name,
+				RegionLcuuid: region.lcuuid,
+			},
+		)
+		vrouters = append(
+			vrouters,
+			model.VRouter{
+				Lcuuid:       id,
+				Name:         name,
+				VPCLcuuid:    vpcLcuuid,
+				RegionLcuuid: region.lcuuid,
+			},
+		)
+		routerIDToVPCLcuuid[id] = vpcLcuuid
+		o.toolDataSet.regionLcuuidToResourceNum[region.lcuuid]++
+		routingTables = append(routingTables, o.formatRoutingTables(jr, id)...)
+	}
+
+	jPorts, err := o.getRawData(fmt.Sprintf("%s/ports?%s", region.networkURL, DEVICE_OWNER_ROUTER_IFACE_URL_FILTER), "ports")
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	// 网络连接多个路由器时，取ID最小的路由器，保证每次同步结果一致
+	networkIDToRouterID := make(map[string]string)
+	for i := range jPorts {
+		jPort := jPorts[i]
+		if !common.Contains(routerInterfaceDeviceOwners, jPort.Get("device_owner").MustString()) {
+			continue
+		}
+		routerID := jPort.Get("device_id").MustString()
+		if _, ok := routerIDToVPCLcuuid[routerID]; !ok {
+			continue
+		}
+		networkID := jPort.Get("network_id").MustString()
+		if curRouterID, ok := networkIDToRouterID[networkID]; !ok || routerID < curRouterID {
+			networkIDToRouterID[networkID] = routerID
+		}
+	}
+	for networkID, routerID := range networkIDToRouterID {
+		o.toolDataSet.networkIDToVPCLcuuid[networkID] = routerIDToVPCLcuuid[routerID]
+	}
+	return vpcs, vrouters, routingTables, nil
+}
+
+func (o *OpenStack) formatRoutingTables(jRouter *simplejson.Json, routerID string) (routingTables []model.RoutingTable) {
+	jRTs := jRouter.Get("routes")
+	for i := range jRTs.MustArray() {
+		jRT := jRTs.GetIndex(i)
+		if !cloudcommon.CheckJsonAttributes(jRT, []string{"destination", "nexthop"}) {
+			continue
+		}
+		destination := jRT.Get("destination").MustString()
+		nexthop := jRT.Get("nexthop").MustString()
+		routingTables = append(
+			routingTables,
+			model.RoutingTable{
+				Lcuuid:        common.GenerateUUID(routerID + destination + nexthop),
+				VRouterLcuuid: routerID,
+				Destination:   destination,
+				NexthopType:   common.ROUTING_TABLE_TYPE_IP,
+				Nexthop:       nexthop,
+			},
+		)
+	}
+	return
+}
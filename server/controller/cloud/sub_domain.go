@@ -94,6 +94,9 @@ func (c *Cloud) getSubDomainData(cResource model.Resource) map[string]model.SubD
 		// prometheusTargets
 		prometheusTargets := c.getSubDomainPrometheusTargets(lcuuid, &kubernetesGatherResource)
 
+		// k8sEvents
+		k8sEvents := c.getSubDomainK8sEvents(lcuuid, &kubernetesGatherResource, azLcuuid)
+
 		// 生成SubDomainResource
 		subDomainResource := model.SubDomainResource{
 			Verified:               true,
@@ -118,6 +121,7 @@ func (c *Cloud) getSubDomainData(cResource model.Resource) map[string]model.SubD
 			VInterfaces:            vinterfaces,
 			IPs:                    ips,
 			PrometheusTargets:      prometheusTargets,
+			K8sEvents:              k8sEvents,
 		}
 		subDomainResources[lcuuid] = subDomainResource
 	}
@@ -642,3 +646,18 @@ func (c *Cloud) getSubDomainPrometheusTargets(
 
 	return retPrometheusTargets
 }
+
+func (c *Cloud) getSubDomainK8sEvents(
+	subDomainLcuuid string, resource *kubernetes_model.KubernetesGatherResource, azLcuuid string,
+) []model.K8sEvent {
+	var retK8sEvents []model.K8sEvent
+
+	// 遍历K8sEvents，更新az和subDomain信息
+	for _, e := range resource.K8sEvents {
+		e.AZLcuuid = azLcuuid
+		e.SubDomainLcuuid = subDomainLcuuid
+		retK8sEvents = append(retK8sEvents, e)
+	}
+
+	return retK8sEvents
+}
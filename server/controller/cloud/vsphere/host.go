@@ -0,0 +1,100 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vsphere
+
+import (
+	"context"
+	"net"
+
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/vim25/mo"
+
+	"github.com/deepflowio/deepflow/server/controller/cloud/model"
+	"github.com/deepflowio/deepflow/server/controller/common"
+)
+
+func (v *VSphere) getHosts(ctx context.Context, client *govmomi.Client, dc Datacenter) ([]model.Host, error) {
+	var hostSystems []mo.HostSystem
+	props := []string{"name", "summary.hardware", "config.network.vnic", "config.network.portgroup"}
+	err := v.getRawData(ctx, client, dc.ref, "HostSystem", props, &hostSystems)
+	if err != nil {
+		return nil, err
+	}
+
+	var hosts []model.Host
+	for _, hs := range hostSystems {
+		azLcuuid, ok := v.toolDataSet.hostRefToAZLcuuid[hs.Self.Value]
+		if !ok {
+			log.Infof("exclude host: %s, not found az", hs.Name)
+			continue
+		}
+		ip := getHostIP(hs)
+		if ip == "" {
+			log.Infof("exclude host: %s, not found ip", hs.Name)
+			continue
+		}
+
+		var vcpuNum, memTotal int
+		if hs.Summary.Hardware != nil {
+			vcpuNum = int(hs.Summary.Hardware.NumCpuThreads)
+			memTotal = int(hs.Summary.Hardware.MemorySize / 1024 / 1024)
+		}
+		hosts = append(
+			hosts,
+			model.Host{
+				Lcuuid:       common.GenerateUUID(hs.Self.Value + "_" + v.lcuuidGenerate),
+				Name:         hs.Name,
+				IP:           ip,
+				Type:         common.HOST_TYPE_VM,
+				HType:        common.HOST_HTYPE_ESXI,
+				VCPUNum:      vcpuNum,
+				MemTotal:     memTotal,
+				AZLcuuid:     azLcuuid,
+				RegionLcuuid: dc.regionLcuuid,
+			},
+		)
+		v.toolDataSet.hostRefToIP[hs.Self.Value] = ip
+		v.toolDataSet.azLcuuidToResourceNum[azLcuuid]++
+		v.toolDataSet.regionLcuuidToResourceNum[dc.regionLcuuid]++
+
+		if hs.Config == nil || hs.Config.Network == nil {
+			continue
+		}
+		for _, pg := range hs.Config.Network.Portgroup {
+			key := PortGroupKey{DatacenterRef: dc.ref.Value, Name: pg.Spec.Name}
+			if _, ok := v.toolDataSet.portGroupKeyToVlanID[key]; !ok {
+				v.toolDataSet.portGroupKeyToVlanID[key] = int(pg.Spec.VlanId)
+			}
+		}
+	}
+	return hosts, nil
+}
+
+// 使用第一个配置了地址的 VMkernel 网卡的地址，断开连接的宿主机无配置信息，名称为 IP 时使用名称
+func getHostIP(hs mo.HostSystem) string {
+	if hs.Config != nil && hs.Config.Network != nil {
+		for _, vnic := range hs.Config.Network.Vnic {
+			if vnic.Spec.Ip != nil && vnic.Spec.Ip.IpAddress != "" {
+				return vnic.Spec.Ip.IpAddress
+			}
+		}
+	}
+	if net.ParseIP(hs.Name) != nil {
+		return hs.Name
+	}
+	return ""
+}
@@ -0,0 +1,140 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vsphere
+
+import (
+	"context"
+
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+
+	cloudcommon "github.com/deepflowio/deepflow/server/controller/cloud/common"
+	"github.com/deepflowio/deepflow/server/controller/cloud/model"
+	"github.com/deepflowio/deepflow/server/controller/common"
+)
+
+const (
+	NETWORK_TYPE_DV_PORT_GROUP = "DistributedVirtualPortgroup"
+	// 低版本 vCenter 的上联端口组没有 config.uplink 属性，仅通过系统标签区分
+	DV_UPLINK_PORT_GROUP_TAG = "SYSTEM/DVS.UPLINKPG"
+)
+
+// 标准端口组、分布式端口组及 NSX 不透明网络均作为网络，分布式交换机上联端口组不同步
+func (v *VSphere) getNetworks(ctx context.Context, client *govmomi.Client, dc Datacenter) ([]model.Network, error) {
+	var dvPortGroups []mo.DistributedVirtualPortgroup
+	err := v.getRawData(ctx, client, dc.ref, NETWORK_TYPE_DV_PORT_GROUP, []string{"name", "key", "config", "tag"}, &dvPortGroups)
+	if err != nil {
+		return nil, err
+	}
+	dvPortGroupRefToVlanID := make(map[string]int)
+	dvUplinkRefs := make(map[string]bool)
+	for _, pg := range dvPortGroups {
+		if isDVUplinkPortGroup(pg) {
+			dvUplinkRefs[pg.Self.Value] = true
+			continue
+		}
+		v.toolDataSet.dvPortGroupKeyToRef[pg.Key] = pg.Self.Value
+		dvPortGroupRefToVlanID[pg.Self.Value] = getDVPortGroupVlanID(pg)
+	}
+
+	var nets []mo.Network
+	err = v.getRawData(ctx, client, dc.ref, "Network", []string{"name"}, &nets)
+	if err != nil {
+		return nil, err
+	}
+
+	var networks []model.Network
+	for _, net := range nets {
+		if dvUplinkRefs[net.Self.Value] {
+			continue
+		}
+		var vlanID int
+		if net.Self.Type == NETWORK_TYPE_DV_PORT_GROUP {
+			vlanID = dvPortGroupRefToVlanID[net.Self.Value]
+		} else {
+			vlanID = v.toolDataSet.portGroupKeyToVlanID[PortGroupKey{DatacenterRef: dc.ref.Value, Name: net.Name}]
+		}
+
+		lcuuid := common.GenerateUUID(net.Self.Value + "_" + v.lcuuidGenerate)
+		networks = append(
+			networks,
+			model.Network{
+				Lcuuid:         lcuuid,
+				Name:           net.Name,
+				Label:          net.Self.Value,
+				SegmentationID: vlanID,
+				Shared:         false,
+				External:       false,
+				NetType:        common.NETWORK_TYPE_LAN,
+				VPCLcuuid:      dc.vpcLcuuid,
+				RegionLcuuid:   dc.regionLcuuid,
+			},
+		)
+		v.toolDataSet.networkRefToLcuuid[net.Self.Value] = lcuuid
+		v.toolDataSet.regionLcuuidToResourceNum[dc.regionLcuuid]++
+	}
+	return networks, nil
+}
+
+func isDVUplinkPortGroup(pg mo.DistributedVirtualPortgroup) bool {
+	if pg.Config.Uplink != nil && *pg.Config.Uplink {
+		return true
+	}
+	for _, tag := range pg.Tag {
+		if tag.Key == DV_UPLINK_PORT_GROUP_TAG {
+			return true
+		}
+	}
+	return false
+}
+
+// 仅 VLAN 类型的端口组有 VLAN ID，trunk 及 PVLAN 类型不记录
+func getDVPortGroupVlanID(pg mo.DistributedVirtualPortgroup) int {
+	setting, ok := pg.Config.DefaultPortConfig.(*types.VMwareDVSPortSetting)
+	if !ok || setting.Vlan == nil {
+		return 0
+	}
+	if spec, ok := setting.Vlan.(*types.VmwareDistributedVirtualSwitchVlanIdSpec); ok {
+		return int(spec.VlanId)
+	}
+	return 0
+}
+
+// 子网由虚拟机网卡上报的地址及前缀生成，VMware Tools 未上报前缀时使用默认子网
+func (v *VSphere) getSubnetLcuuid(dc Datacenter, networkLcuuid, cidr string) (string, *model.Subnet) {
+	lcuuid := common.GenerateUUID(networkLcuuid + "_" + cidr)
+	if _, ok := v.toolDataSet.subnetLcuuidToSubnet[lcuuid]; ok {
+		return lcuuid, nil
+	}
+	subnet := model.Subnet{
+		Lcuuid:        lcuuid,
+		Name:          cidr,
+		CIDR:          cidr,
+		NetworkLcuuid: networkLcuuid,
+		VPCLcuuid:     dc.vpcLcuuid,
+	}
+	v.toolDataSet.subnetLcuuidToSubnet[lcuuid] = subnet
+	return lcuuid, &subnet
+}
+
+func getDefaultCIDR(ip string) string {
+	if cloudcommon.GenerateIPMask(ip) == common.IPV6_MAX_MASK {
+		return cloudcommon.SUBNET_DEFAULT_CIDR_IPV6
+	}
+	return cloudcommon.SUBNET_DEFAULT_CIDR_IPV4
+}
@@ -0,0 +1,119 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vsphere
+
+import (
+	"context"
+	"errors"
+
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+
+	"github.com/deepflowio/deepflow/server/controller/cloud/model"
+	"github.com/deepflowio/deepflow/server/controller/common"
+)
+
+// 数据中心作为区域，其下的资源属于同一个 VPC
+type Datacenter struct {
+	name         string
+	ref          types.ManagedObjectReference
+	regionLcuuid string
+	vpcLcuuid    string
+}
+
+func (v *VSphere) getDatacenters(ctx context.Context, client *govmomi.Client) ([]Datacenter, []model.Region, error) {
+	var dcs []mo.Datacenter
+	err := v.getRawData(ctx, client, client.ServiceContent.RootFolder, "Datacenter", []string{"name"}, &dcs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var datacenters []Datacenter
+	var regions []model.Region
+	for _, dc := range dcs {
+		if len(v.config.IncludeDatacenters) > 0 && !common.Contains(v.config.IncludeDatacenters, dc.Name) {
+			log.Infof("exclude datacenter: %s, not included", dc.Name)
+			continue
+		}
+		if common.Contains(v.config.ExcludeDatacenters, dc.Name) {
+			log.Infof("exclude datacenter: %s", dc.Name)
+			continue
+		}
+
+		datacenter := Datacenter{
+			name:         dc.Name,
+			ref:          dc.Self,
+			regionLcuuid: v.getRegionLcuuid(dc.Name),
+			vpcLcuuid:    common.GenerateUUID(dc.Self.Value + "_" + v.lcuuidGenerate),
+		}
+		datacenters = append(datacenters, datacenter)
+
+		// 页面指定区域时，资源均属于指定区域
+		if v.config.RegionLcuuid == "" {
+			regions = append(regions, model.Region{Lcuuid: datacenter.regionLcuuid, Name: dc.Name})
+		}
+	}
+	if len(datacenters) == 0 {
+		return nil, nil, errors.New("no datacenter found")
+	}
+	return datacenters, regions, nil
+}
+
+func (v *VSphere) getRegionLcuuid(name string) string {
+	if v.config.RegionLcuuid != "" {
+		return v.config.RegionLcuuid
+	}
+	return common.GenerateUUID(name + "_" + v.lcuuidGenerate)
+}
+
+func (v *VSphere) getVPC(dc Datacenter) model.VPC {
+	v.toolDataSet.regionLcuuidToResourceNum[dc.regionLcuuid]++
+	return model.VPC{
+		Lcuuid:       dc.vpcLcuuid,
+		Name:         dc.name,
+		Label:        dc.ref.Value,
+		RegionLcuuid: dc.regionLcuuid,
+	}
+}
+
+// 集群及独立宿主机对应的计算资源作为可用区
+func (v *VSphere) getAZs(ctx context.Context, client *govmomi.Client, dc Datacenter) ([]model.AZ, error) {
+	var crs []mo.ComputeResource
+	err := v.getRawData(ctx, client, dc.ref, "ComputeResource", []string{"name", "host"}, &crs)
+	if err != nil {
+		return nil, err
+	}
+
+	var azs []model.AZ
+	for _, cr := range crs {
+		lcuuid := common.GenerateUUID(cr.Self.Value + "_" + v.lcuuidGenerate)
+		azs = append(
+			azs,
+			model.AZ{
+				Lcuuid:       lcuuid,
+				Name:         cr.Name,
+				Label:        cr.Self.Value,
+				RegionLcuuid: dc.regionLcuuid,
+			},
+		)
+		for _, host := range cr.Host {
+			v.toolDataSet.hostRefToAZLcuuid[host.Value] = lcuuid
+		}
+	}
+	return azs, nil
+}
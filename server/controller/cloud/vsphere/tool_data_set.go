@@ -0,0 +1,51 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vsphere
+
+import (
+	"github.com/deepflowio/deepflow/server/controller/cloud/model"
+)
+
+type ToolDataSet struct {
+	hostRefToIP               map[string]string
+	hostRefToAZLcuuid         map[string]string
+	portGroupKeyToVlanID      map[PortGroupKey]int
+	networkRefToLcuuid        map[string]string
+	dvPortGroupKeyToRef       map[string]string
+	subnetLcuuidToSubnet      map[string]model.Subnet
+	regionLcuuidToResourceNum map[string]int
+	azLcuuidToResourceNum     map[string]int
+}
+
+func NewToolDataSet() *ToolDataSet {
+	return &ToolDataSet{
+		hostRefToIP:               make(map[string]string),
+		hostRefToAZLcuuid:         make(map[string]string),
+		portGroupKeyToVlanID:      make(map[PortGroupKey]int),
+		networkRefToLcuuid:        make(map[string]string),
+		dvPortGroupKeyToRef:       make(map[string]string),
+		subnetLcuuidToSubnet:      make(map[string]model.Subnet),
+		regionLcuuidToResourceNum: make(map[string]int),
+		azLcuuidToResourceNum:     make(map[string]int),
+	}
+}
+
+// 标准交换机端口组仅在宿主机配置中定义 VLAN，按数据中心及端口组名称关联网络
+type PortGroupKey struct {
+	DatacenterRef string
+	Name          string
+}
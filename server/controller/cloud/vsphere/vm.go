@@ -0,0 +1,224 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vsphere
+
+import (
+	"context"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+
+	cloudcommon "github.com/deepflowio/deepflow/server/controller/cloud/common"
+	"github.com/deepflowio/deepflow/server/controller/cloud/model"
+	"github.com/deepflowio/deepflow/server/controller/common"
+)
+
+var STATE_CONVERTION = map[types.VirtualMachinePowerState]int{
+	types.VirtualMachinePowerStatePoweredOn:  common.VM_STATE_RUNNING,
+	types.VirtualMachinePowerStatePoweredOff: common.VM_STATE_STOPPED,
+	types.VirtualMachinePowerStateSuspended:  common.VM_STATE_STOPPED,
+}
+
+func (v *VSphere) getVMs(ctx context.Context, client *govmomi.Client, dc Datacenter) (
+	[]model.VM, []model.VInterface, []model.IP, []model.Subnet, error,
+) {
+	var vms []model.VM
+	var vinterfaces []model.VInterface
+	var ips []model.IP
+	var subnets []model.Subnet
+
+	var vmObjs []mo.VirtualMachine
+	props := []string{
+		"name", "config.template", "config.uuid", "config.instanceUuid", "config.createDate", "config.hardware.device",
+		"runtime.powerState", "runtime.host", "guest.net",
+	}
+	err := v.getRawData(ctx, client, dc.ref, "VirtualMachine", props, &vmObjs)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+
+	for _, vmObj := range vmObjs {
+		// 无法访问的虚拟机没有配置信息，模板不是运行中的虚拟机
+		if vmObj.Config == nil || vmObj.Config.Template {
+			continue
+		}
+		lcuuid := vmObj.Config.InstanceUuid
+		if lcuuid == "" {
+			lcuuid = vmObj.Config.Uuid
+		}
+		if lcuuid == "" {
+			log.Infof("exclude vm: %s, missing uuid", vmObj.Name)
+			continue
+		}
+		if vmObj.Runtime.Host == nil {
+			log.Infof("exclude vm: %s, not found host", vmObj.Name)
+			continue
+		}
+		azLcuuid, ok := v.toolDataSet.hostRefToAZLcuuid[vmObj.Runtime.Host.Value]
+		if !ok {
+			log.Infof("exclude vm: %s, not found az", vmObj.Name)
+			continue
+		}
+
+		state, ok := STATE_CONVERTION[vmObj.Runtime.PowerState]
+		if !ok {
+			state = common.VM_STATE_EXCEPTION
+		}
+		var createdAt time.Time
+		if vmObj.Config.CreateDate != nil {
+			createdAt = *vmObj.Config.CreateDate
+		}
+		vms = append(
+			vms,
+			model.VM{
+				Lcuuid:       lcuuid,
+				Name:         vmObj.Name,
+				Label:        vmObj.Self.Value,
+				HType:        common.VM_HTYPE_VM_C,
+				State:        state,
+				LaunchServer: v.toolDataSet.hostRefToIP[vmObj.Runtime.Host.Value],
+				CreatedAt:    createdAt,
+				VPCLcuuid:    dc.vpcLcuuid,
+				AZLcuuid:     azLcuuid,
+				RegionLcuuid: dc.regionLcuuid,
+			},
+		)
+		v.toolDataSet.azLcuuidToResourceNum[azLcuuid]++
+		v.toolDataSet.regionLcuuidToResourceNum[dc.regionLcuuid]++
+
+		vmVInterfaces, vmIPs, vmSubnets := v.getVMVInterfacesAndIPs(dc, lcuuid, vmObj)
+		vinterfaces = append(vinterfaces, vmVInterfaces...)
+		ips = append(ips, vmIPs...)
+		subnets = append(subnets, vmSubnets...)
+	}
+	return vms, vinterfaces, ips, subnets, nil
+}
+
+// 网卡及所属网络来自虚拟机硬件配置，IP 来自 VMware Tools 上报的 guest 网络信息，通过 MAC 关联
+func (v *VSphere) getVMVInterfacesAndIPs(dc Datacenter, vmLcuuid string, vmObj mo.VirtualMachine) (
+	[]model.VInterface, []model.IP, []model.Subnet,
+) {
+	var vinterfaces []model.VInterface
+	var ips []model.IP
+	var subnets []model.Subnet
+
+	macToGuestNic := make(map[string]types.GuestNicInfo)
+	if vmObj.Guest != nil {
+		for _, nic := range vmObj.Guest.Net {
+			macToGuestNic[strings.ToLower(nic.MacAddress)] = nic
+		}
+	}
+
+	for _, device := range vmObj.Config.Hardware.Device {
+		card, ok := device.(types.BaseVirtualEthernetCard)
+		if !ok {
+			continue
+		}
+		ethernetCard := card.GetVirtualEthernetCard()
+		mac := strings.ToLower(ethernetCard.MacAddress)
+		if mac == "" {
+			continue
+		}
+		var networkRef string
+		switch backing := ethernetCard.Backing.(type) {
+		case *types.VirtualEthernetCardNetworkBackingInfo:
+			if backing.Network != nil {
+				networkRef = backing.Network.Value
+			}
+		case *types.VirtualEthernetCardDistributedVirtualPortBackingInfo:
+			networkRef = v.toolDataSet.dvPortGroupKeyToRef[backing.Port.PortgroupKey]
+		}
+		networkLcuuid, ok := v.toolDataSet.networkRefToLcuuid[networkRef]
+		if !ok {
+			log.Infof("exclude vm (%s) vinterface: %s, not found network", vmObj.Name, mac)
+			continue
+		}
+
+		vinterfaceLcuuid := common.GenerateUUID(vmLcuuid + "_" + mac)
+		vinterfaces = append(
+			vinterfaces,
+			model.VInterface{
+				Lcuuid:        vinterfaceLcuuid,
+				Type:          common.VIF_TYPE_LAN,
+				Mac:           mac,
+				DeviceLcuuid:  vmLcuuid,
+				DeviceType:    common.VIF_DEVICE_TYPE_VM,
+				NetworkLcuuid: networkLcuuid,
+				VPCLcuuid:     dc.vpcLcuuid,
+				RegionLcuuid:  dc.regionLcuuid,
+			},
+		)
+
+		nicIPs, ipToCIDR := getGuestNicIPs(macToGuestNic[mac])
+		for _, ip := range nicIPs {
+			subnetLcuuid, subnet := v.getSubnetLcuuid(dc, networkLcuuid, ipToCIDR[ip])
+			if subnet != nil {
+				subnets = append(subnets, *subnet)
+			}
+			ips = append(
+				ips,
+				model.IP{
+					Lcuuid:           common.GenerateUUID(vinterfaceLcuuid + ip),
+					VInterfaceLcuuid: vinterfaceLcuuid,
+					IP:               ip,
+					SubnetLcuuid:     subnetLcuuid,
+					RegionLcuuid:     dc.regionLcuuid,
+				},
+			)
+		}
+	}
+	return vinterfaces, ips, subnets
+}
+
+// 优先使用带前缀的 ipConfig，低版本 VMware Tools 仅上报 ipAddress；链路本地地址不同步
+func getGuestNicIPs(nic types.GuestNicInfo) ([]string, map[string]string) {
+	var ips []string
+	ipToCIDR := make(map[string]string)
+	if nic.IpConfig != nil {
+		for _, addr := range nic.IpConfig.IpAddress {
+			if isLinkLocal(addr.IpAddress) {
+				continue
+			}
+			cidr, err := cloudcommon.IPAndMaskToCIDR(addr.IpAddress, int(addr.PrefixLength))
+			if err != nil {
+				log.Debugf("ip (%s) prefix (%d) to cidr failed: %v", addr.IpAddress, addr.PrefixLength, err)
+				cidr = getDefaultCIDR(addr.IpAddress)
+			}
+			if _, ok := ipToCIDR[addr.IpAddress]; !ok {
+				ips = append(ips, addr.IpAddress)
+			}
+			ipToCIDR[addr.IpAddress] = cidr
+		}
+	}
+	for _, ip := range nic.IpAddress {
+		if _, ok := ipToCIDR[ip]; ok || isLinkLocal(ip) {
+			continue
+		}
+		ips = append(ips, ip)
+		ipToCIDR[ip] = getDefaultCIDR(ip)
+	}
+	return ips, ipToCIDR
+}
+
+func isLinkLocal(ip string) bool {
+	netIP := net.ParseIP(ip)
+	return netIP == nil || netIP.IsLinkLocalUnicast()
+}
@@ -0,0 +1,220 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vsphere
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"reflect"
+	"time"
+
+	"github.com/bitly/go-simplejson"
+	"github.com/op/go-logging"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/view"
+	"github.com/vmware/govmomi/vim25/soap"
+	"github.com/vmware/govmomi/vim25/types"
+
+	cloudcommon "github.com/deepflowio/deepflow/server/controller/cloud/common"
+	"github.com/deepflowio/deepflow/server/controller/cloud/config"
+	"github.com/deepflowio/deepflow/server/controller/cloud/model"
+	"github.com/deepflowio/deepflow/server/controller/common"
+	"github.com/deepflowio/deepflow/server/controller/db/mysql"
+	"github.com/deepflowio/deepflow/server/controller/statsd"
+)
+
+var log = logging.MustGetLogger("cloud.vsphere")
+
+type VSphere struct {
+	lcuuid         string
+	lcuuidGenerate string
+	name           string
+	httpTimeout    int
+	config         *Config
+	toolDataSet    *ToolDataSet       // 处理资源数据时，构建的需要提供给其他资源使用的工具数据
+	cloudStatsd    statsd.CloudStatsd // 性能监控
+	debugger       *cloudcommon.Debugger
+}
+
+func NewVSphere(domain mysql.Domain, globalCloudCfg config.CloudConfig) (*VSphere, error) {
+	conf := &Config{}
+	err := conf.LoadFromString(domain.Config)
+	if err != nil {
+		return nil, err
+	}
+	return &VSphere{
+		lcuuid: domain.Lcuuid,
+		// TODO: display_name后期需要修改为uuid_generate
+		lcuuidGenerate: domain.DisplayName,
+		name:           domain.Name,
+		httpTimeout:    globalCloudCfg.HTTPTimeout,
+		config:         conf,
+		debugger:       cloudcommon.NewDebugger(domain.Name),
+	}, nil
+}
+
+func (v *VSphere) ClearDebugLog() {
+	v.debugger.Clear()
+}
+
+func (v *VSphere) CheckAuth() error {
+	ctx := context.Background()
+	client, err := v.login(ctx)
+	if err != nil {
+		return err
+	}
+	return client.Logout(ctx)
+}
+
+func (v *VSphere) GetCloudData() (model.Resource, error) {
+	v.cloudStatsd = statsd.NewCloudStatsd()
+	v.toolDataSet = NewToolDataSet()
+	var resource model.Resource
+
+	ctx := context.Background()
+	client, err := v.login(ctx)
+	if err != nil {
+		return resource, err
+	}
+	defer client.Logout(ctx)
+
+	datacenters, regions, err := v.getDatacenters(ctx, client)
+	if err != nil {
+		return resource, err
+	}
+
+	var azs []model.AZ
+	for _, dc := range datacenters {
+		log.Infof("get datacenter (%s) resources", dc.name)
+		dcAZs, err := v.getAZs(ctx, client, dc)
+		if err != nil {
+			return resource, err
+		}
+		azs = append(azs, dcAZs...)
+
+		hosts, err := v.getHosts(ctx, client, dc)
+		if err != nil {
+			return resource, err
+		}
+		resource.Hosts = append(resource.Hosts, hosts...)
+
+		resource.VPCs = append(resource.VPCs, v.getVPC(dc))
+
+		networks, err := v.getNetworks(ctx, client, dc)
+		if err != nil {
+			return resource, err
+		}
+		resource.Networks = append(resource.Networks, networks...)
+
+		vms, vifs, ips, subnets, err := v.getVMs(ctx, client, dc)
+		if err != nil {
+			return resource, err
+		}
+		resource.VMs = append(resource.VMs, vms...)
+		resource.VInterfaces = append(resource.VInterfaces, vifs...)
+		resource.IPs = append(resource.IPs, ips...)
+		resource.Subnets = append(resource.Subnets, subnets...)
+	}
+
+	log.Debugf("region resource num info: %v", v.toolDataSet.regionLcuuidToResourceNum)
+	log.Debugf("az resource num info: %v", v.toolDataSet.azLcuuidToResourceNum)
+	resource.Regions = cloudcommon.EliminateEmptyRegions(regions, v.toolDataSet.regionLcuuidToResourceNum)
+	resource.AZs = cloudcommon.EliminateEmptyAZs(azs, v.toolDataSet.azLcuuidToResourceNum)
+
+	v.cloudStatsd.RefreshResCount(resource)
+	statsd.MetaStatsd.RegisterStatsdTable(v)
+
+	v.debugger.Refresh()
+	return resource, nil
+}
+
+func (v *VSphere) GetStatter() statsd.StatsdStatter {
+	globalTags := map[string]string{
+		"domain_name": v.name,
+		"domain":      v.lcuuid,
+		"platform":    common.VSPHERE_EN,
+	}
+
+	return statsd.StatsdStatter{
+		GlobalTags: globalTags,
+		Element:    statsd.GetCloudStatsd(v.cloudStatsd),
+	}
+}
+
+func (v *VSphere) login(ctx context.Context) (*govmomi.Client, error) {
+	u, err := soap.ParseURL(v.config.URL)
+	if err != nil {
+		return nil, err
+	}
+	u.User = url.UserPassword(v.config.UserName, v.config.Password)
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(v.httpTimeout)*time.Second)
+	defer cancel()
+	client, err := govmomi.NewClient(ctx, u, v.config.Insecure)
+	if err != nil {
+		log.Errorf("login vcenter (%s) failed: %v", v.config.URL, err)
+		return nil, err
+	}
+	return client, nil
+}
+
+// 通过容器视图获取 root 下指定类型的全部对象，仅获取 props 中的属性，dst 为对应 mo 类型的切片指针
+func (v *VSphere) getRawData(ctx context.Context, client *govmomi.Client, root types.ManagedObjectReference, kind string, props []string, dst interface{}) error {
+	statsdAPIStartTime := time.Now()
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(v.httpTimeout)*time.Second)
+	defer cancel()
+	cv, err := view.NewManager(client.Client).CreateContainerView(ctx, root, []string{kind}, true)
+	if err != nil {
+		log.Errorf("create %s view failed: %v", kind, err)
+		return err
+	}
+	defer cv.Destroy(ctx)
+
+	if err = cv.Retrieve(ctx, []string{kind}, props, dst); err != nil {
+		log.Errorf("retrieve %s failed: %v", kind, err)
+		return err
+	}
+
+	v.cloudStatsd.RefreshAPICost(kind, statsdAPIStartTime)
+	v.cloudStatsd.RefreshAPICount(kind, reflect.ValueOf(dst).Elem().Len())
+
+	v.writeDebugJson(kind, root.Value, dst)
+	return nil
+}
+
+func (v *VSphere) writeDebugJson(kind, dividingLine string, data interface{}) {
+	if !config.CONF.DebugEnabled {
+		return
+	}
+	bytes, err := json.Marshal(data)
+	if err != nil {
+		log.Debugf("marshal %s failed: %v", kind, err)
+		return
+	}
+	jData, err := simplejson.NewJson(bytes)
+	if err != nil {
+		log.Debugf("convert %s to json failed: %v", kind, err)
+		return
+	}
+	var jsonList []*simplejson.Json
+	for i := range jData.MustArray() {
+		jsonList = append(jsonList, jData.GetIndex(i))
+	}
+	v.debugger.WriteJson(kind, dividingLine, jsonList)
+}
@@ -0,0 +1,123 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vsphere
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/vmware/govmomi/simulator"
+	"github.com/vmware/govmomi/vim25/types"
+
+	cloudcommon "github.com/deepflowio/deepflow/server/controller/cloud/common"
+	"github.com/deepflowio/deepflow/server/controller/cloud/config"
+	"github.com/deepflowio/deepflow/server/controller/common"
+	"github.com/deepflowio/deepflow/server/controller/statsd"
+	statsdconfig "github.com/deepflowio/deepflow/server/controller/statsd/config"
+)
+
+func TestVSphere(t *testing.T) {
+	config.SetCloudGlobalConfig(config.CloudConfig{})
+	statsd.NewStatsdMonitor(statsdconfig.StatsdConfig{})
+
+	// 1 个数据中心，包含 1 个 3 宿主机的集群及 1 个独立宿主机，每个计算资源 2 个虚拟机
+	vpx := simulator.VPX()
+	defer vpx.Remove()
+	if err := vpx.Create(); err != nil {
+		t.Fatal(err)
+	}
+	server := vpx.Service.NewServer()
+	defer server.Close()
+
+	// vcsim 未标记分布式交换机的上联端口组
+	for _, obj := range simulator.Map.All("DistributedVirtualPortgroup") {
+		pg := obj.(*simulator.DistributedVirtualPortgroup)
+		if strings.Contains(pg.Name, "DVUplinks") {
+			pg.Tag = []types.Tag{{Key: DV_UPLINK_PORT_GROUP_TAG}}
+		} else {
+			pg.Config.DefaultPortConfig = &types.VMwareDVSPortSetting{
+				Vlan: &types.VmwareDistributedVirtualSwitchVlanIdSpec{VlanId: 100},
+			}
+		}
+	}
+
+	// 模拟 VMware Tools 上报的网卡地址
+	vmObj := simulator.Map.Any("VirtualMachine").(*simulator.VirtualMachine)
+	vmObj.Guest.Net[0].IpAddress = []string{"192.168.10.10", "fe80::250:56ff:fe00:1"}
+	vmObj.Guest.Net[0].IpConfig = &types.NetIpConfigInfo{
+		IpAddress: []types.NetIpConfigInfoIpAddress{{IpAddress: "192.168.10.10", PrefixLength: 24}},
+	}
+
+	password, _ := server.URL.User.Password()
+	newVSphere := func(password string) *VSphere {
+		return &VSphere{
+			lcuuid:         "vsphere-lcuuid",
+			lcuuidGenerate: "test_vsphere",
+			name:           "test_vsphere",
+			httpTimeout:    30,
+			config: &Config{
+				URL:      server.URL.Scheme + "://" + server.URL.Host + server.URL.Path,
+				UserName: server.URL.User.Username(),
+				Password: password,
+				Insecure: true,
+			},
+			debugger: cloudcommon.NewDebugger("test_vsphere"),
+		}
+	}
+
+	Convey("TestVSphereCheckAuth", t, func() {
+		So(newVSphere(password).CheckAuth(), ShouldBeNil)
+	})
+
+	Convey("TestVSphereGetCloudData", t, func() {
+		data, err := newVSphere(password).GetCloudData()
+		So(err, ShouldBeNil)
+
+		Convey("vsphereResource number should be equal", func() {
+			So(len(data.Regions), ShouldEqual, 1)
+			So(len(data.AZs), ShouldEqual, 2)
+			So(len(data.Hosts), ShouldEqual, 4)
+			So(len(data.VPCs), ShouldEqual, 1)
+			// 分布式交换机上联端口组不同步
+			So(len(data.Networks), ShouldEqual, 2)
+			So(len(data.VMs), ShouldEqual, 4)
+			So(len(data.VInterfaces), ShouldEqual, 4)
+			// 链路本地地址不同步
+			So(len(data.IPs), ShouldEqual, 1)
+			So(len(data.Subnets), ShouldEqual, 1)
+		})
+
+		Convey("vsphereResource relations should be correct", func() {
+			So(data.Subnets[0].CIDR, ShouldEqual, "192.168.10.0/24")
+			So(data.IPs[0].SubnetLcuuid, ShouldEqual, data.Subnets[0].Lcuuid)
+			vlanIDs := []int{}
+			for _, network := range data.Networks {
+				vlanIDs = append(vlanIDs, network.SegmentationID)
+			}
+			So(vlanIDs, ShouldContain, 100)
+			for _, vm := range data.VMs {
+				So(vm.VPCLcuuid, ShouldEqual, data.VPCs[0].Lcuuid)
+				So(vm.State, ShouldEqual, common.VM_STATE_RUNNING)
+				So(vm.LaunchServer, ShouldNotBeEmpty)
+			}
+			for _, host := range data.Hosts {
+				So(host.HType, ShouldEqual, common.HOST_HTYPE_ESXI)
+			}
+		})
+	})
+}
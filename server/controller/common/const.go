@@ -35,6 +35,11 @@ const (
 	MASTER_CONTROLLER_CHECK_PORT = 4040
 )
 
+const (
+	DEFAULT_ORG_ID = 1
+	MAX_ORG_ID     = 1024
+)
+
 const (
 	HEALTH_CHECK_INTERVAL = 60 * time.Second
 	HEALTH_CHECK_URL      = "http://%s:%d/v1/health/"
@@ -114,6 +119,29 @@ const (
 	VTAP_STATE_PENDING_STR       = "PENDING"
 )
 
+// policies of registering new agents
+const (
+	// register agents without token, the state depends on vtap-auto-register
+	VTAP_REGISTER_POLICY_OPEN = "open"
+	// agents without a valid enrollment token are not registered
+	VTAP_REGISTER_POLICY_TOKEN_REQUIRED = "token-required"
+	// agents without a valid enrollment token are registered as pending and wait for approval
+	VTAP_REGISTER_POLICY_MANUAL_APPROVAL = "manual-approval"
+)
+
+// sources of the vtap group of agents
+const (
+	// default vtap group, or specified by users, enrollment tokens or agent config
+	VTAP_GROUP_SOURCE_MANUAL = iota
+	// assigned by vtap group rules, only these agents are moved when rules change
+	VTAP_GROUP_SOURCE_RULE
+)
+
+const (
+	VTAP_ENROLLMENT_TOKEN_PREFIX      = "dfe_"
+	VTAP_ENROLLMENT_TOKEN_DISPLAY_LEN = 8
+)
+
 const (
 	VTAP_TYPE_KVM = 1 + iota
 	VTAP_TYPE_ESXI
@@ -239,7 +267,13 @@ const (
 )
 
 const (
-	ACL_STATE_ENABLE = 1
+	ACL_STATE_DISABLE = 0
+	ACL_STATE_ENABLE  = 1
+
+	ACL_TYPE_CUSTOM = 2
+
+	ACL_APPLICATION_PCAP = 4
+	ACL_APPLICATION_NPB  = 6
 )
 
 const (
@@ -247,6 +281,24 @@ const (
 	NPB_POLICY_FLOW_DISTRIBUTE = 1
 )
 
+const (
+	NPB_TUNNEL_TYPE_VXLAN  = 0
+	NPB_TUNNEL_TYPE_ERSPAN = 1
+
+	NPB_VXLAN_VNI_MAX         = 1<<24 - 1
+	NPB_ERSPAN_SESSION_ID_MAX = 1023
+
+	PAYLOAD_SLICE_MAX = 65535
+)
+
+const (
+	RESOURCE_GROUP_BUSINESS_ID_NPB  = 1
+	RESOURCE_GROUP_BUSINESS_ID_PCAP = -3
+
+	RESOURCE_GROUP_TYPE_NAMED_VM = 1
+	RESOURCE_GROUP_TYPE_NAMED_IP = 2
+)
+
 const (
 	DEFAULT_ENCRYPTION_PASSWORD = "******"
 	DEFAULT_PORT_NAME_REGEX     = "^(cni|flannel|cali|vxlan.calico|tunl|en[ospx])"
@@ -500,6 +552,9 @@ const (
 	POD_GROUP_DAEMON_SET            = 4
 	POD_GROUP_REPLICASET_CONTROLLER = 5
 	POD_GROUP_CLONESET              = 6
+	POD_GROUP_JOB                   = 7
+	POD_GROUP_CRONJOB               = 8
+	POD_GROUP_ROLLOUT               = 9
 )
 
 const (
@@ -58,6 +58,9 @@ func CURLPerform(method string, url string, body map[string]interface{}) (*simpl
 	req.Header.Set("Accept", "application/json, text/plain")
 	req.Header.Set("X-User-Id", "1")
 	req.Header.Set("X-User-Type", "1")
+	if GConfig != nil && GConfig.InternalToken != "" {
+		req.Header.Set("X-Internal-Token", GConfig.InternalToken)
+	}
 
 	resp, err := client.Do(req)
 	if err != nil {
@@ -16,6 +16,11 @@
 
 package common
 
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
 type Comparable interface {
 	~int | ~string
 }
@@ -28,3 +33,9 @@ func Contains[T Comparable](slice []T, val T) bool {
 	}
 	return false
 }
+
+// HashToken returns the sha256 of api keys and enrollment tokens, only the hash is stored.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
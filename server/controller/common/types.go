@@ -28,4 +28,6 @@ type GlobalConfig struct {
 	GRPCNodePort int
 
 	MySQLResultSetMax int
+
+	InternalToken string // authenticates the requests between controllers
 }
@@ -50,6 +50,7 @@ import (
 	_ "github.com/deepflowio/deepflow/server/controller/trisolaris/services/grpc/debug"
 	_ "github.com/deepflowio/deepflow/server/controller/trisolaris/services/grpc/healthcheck"
 	_ "github.com/deepflowio/deepflow/server/controller/trisolaris/services/http/cache"
+	_ "github.com/deepflowio/deepflow/server/controller/trisolaris/services/http/simulation"
 	_ "github.com/deepflowio/deepflow/server/controller/trisolaris/services/http/upgrade"
 )
 
@@ -151,7 +152,7 @@ func Start(ctx context.Context, configPath, serverLogFile string, shared *server
 	router.SetInitStageForHealthChecker("Master function init")
 	controllerCheck := monitor.NewControllerCheck(cfg, ctx)
 	analyzerCheck := monitor.NewAnalyzerCheck(cfg, ctx)
-	go checkAndStartMasterFunctions(cfg, ctx, tr, controllerCheck, analyzerCheck)
+	go checkAndStartMasterFunctions(cfg, ctx, tr, controllerCheck, analyzerCheck, shared.ResourceEventQueue)
 
 	router.SetInitStageForHealthChecker("Register routers init")
 	httpServer.SetControllerChecker(controllerCheck)
@@ -189,5 +190,7 @@ func setGlobalConfig(cfg *config.ControllerConfig) {
 		HTTPNodePort: cfg.ListenNodePort,
 		GRPCPort:     grpcPort,
 		GRPCNodePort: grpcNodePort,
+
+		InternalToken: cfg.HTTPCfg.Auth.InternalToken,
 	}
 }
@@ -26,6 +26,7 @@ import (
 	"github.com/deepflowio/deepflow/server/controller/db/mysql/migrator"
 	"github.com/deepflowio/deepflow/server/controller/election"
 	"github.com/deepflowio/deepflow/server/controller/http"
+	"github.com/deepflowio/deepflow/server/controller/http/audit"
 	resoureservice "github.com/deepflowio/deepflow/server/controller/http/service/resource"
 	"github.com/deepflowio/deepflow/server/controller/monitor"
 	"github.com/deepflowio/deepflow/server/controller/monitor/license"
@@ -33,6 +34,7 @@ import (
 	"github.com/deepflowio/deepflow/server/controller/prometheus"
 	"github.com/deepflowio/deepflow/server/controller/recorder"
 	"github.com/deepflowio/deepflow/server/controller/tagrecorder"
+	"github.com/deepflowio/deepflow/server/libs/queue"
 )
 
 func IsMasterRegion(cfg *config.ControllerConfig) bool {
@@ -73,7 +75,7 @@ func migrateMySQL(cfg *config.ControllerConfig) {
 
 func checkAndStartMasterFunctions(
 	cfg *config.ControllerConfig, ctx context.Context, tr *tagrecorder.TagRecorder,
-	controllerCheck *monitor.ControllerCheck, analyzerCheck *monitor.AnalyzerCheck,
+	controllerCheck *monitor.ControllerCheck, analyzerCheck *monitor.AnalyzerCheck, eventQueue *queue.OverwriteQueue,
 ) {
 
 	// 定时检查当前是否为master controller
@@ -91,9 +93,11 @@ func checkAndStartMasterFunctions(
 
 	vtapCheck := vtap.NewVTapCheck(cfg.MonitorCfg, ctx)
 	vtapRebalanceCheck := vtap.NewRebalanceCheck(cfg.MonitorCfg, ctx)
+	vtapGroupRuleCheck := vtap.NewVTapGroupRuleCheck(cfg.MonitorCfg, ctx, eventQueue)
 	vtapLicenseAllocation := license.NewVTapLicenseAllocation(cfg.MonitorCfg, ctx)
 	recorderResource := recorder.GetSingletonResource()
 	domainChecker := resoureservice.NewDomainCheck(ctx)
+	auditLogCleaner := audit.NewCleaner(&cfg.HTTPCfg.Audit, ctx)
 	prometheus := prometheus.GetSingleton()
 	httpService := http.GetSingleton()
 
@@ -134,6 +138,9 @@ func checkAndStartMasterFunctions(
 				// rebalance vtap check
 				vtapRebalanceCheck.Start()
 
+				// 采集器组规则检查
+				vtapGroupRuleCheck.Start()
+
 				// license分配和检查
 				if cfg.BillingMethod == common.BILLING_METHOD_LICENSE {
 					vtapLicenseAllocation.Start()
@@ -145,6 +152,9 @@ func checkAndStartMasterFunctions(
 				// domain检查及自愈
 				domainChecker.Start()
 
+				// 审计日志清理
+				auditLogCleaner.Start()
+
 				prometheus.Encoder.Start()
 
 				if cfg.DFWebService.Enabled {
@@ -166,6 +176,9 @@ func checkAndStartMasterFunctions(
 				// stop vtap check
 				vtapCheck.Stop()
 
+				// stop vtap group rule check
+				vtapGroupRuleCheck.Stop()
+
 				// stop vtap license allocation and check
 				vtapLicenseAllocation.Stop()
 
@@ -173,6 +186,8 @@ func checkAndStartMasterFunctions(
 
 				domainChecker.Stop()
 
+				auditLogCleaner.Stop()
+
 				recorderResource.IDManager.Stop()
 
 				prometheus.Encoder.Stop()
@@ -40,6 +40,15 @@ CREATE TABLE IF NOT EXISTS resource_event (
 )ENGINE=innodb AUTO_INCREMENT=1 DEFAULT CHARSET=utf8;
 TRUNCATE TABLE resource_event;
 
+CREATE TABLE IF NOT EXISTS k8s_event_position (
+    id                  INTEGER NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    domain              CHAR(64) DEFAULT '',
+    sub_domain          CHAR(64) DEFAULT '',
+    last_time           DATETIME NOT NULL COMMENT 'last time of kubernetes events enqueued',
+    UNIQUE INDEX domain_sub_domain (domain, sub_domain)
+)ENGINE=innodb AUTO_INCREMENT=1 DEFAULT CHARSET=utf8;
+TRUNCATE TABLE k8s_event_position;
+
 CREATE TABLE IF NOT EXISTS domain_additional_resource (
     id                  INTEGER NOT NULL AUTO_INCREMENT PRIMARY KEY,
     domain              CHAR(64) DEFAULT '',
@@ -433,6 +442,7 @@ INSERT INTO az (id, name, lcuuid, region, domain) values(1, '系统默认', 'fff
 CREATE TABLE IF NOT EXISTS domain (
     id                  INTEGER NOT NULL AUTO_INCREMENT PRIMARY KEY,
     name                VARCHAR(64),
+    org_id              INTEGER NOT NULL DEFAULT 1 COMMENT 'organization id',
     icon_id             INTEGER,
     display_name        VARCHAR(64) DEFAULT '',
     cluster_id          CHAR(64),
@@ -774,6 +784,7 @@ CREATE TABLE IF NOT EXISTS pod_node (
     ip                  CHAR(64) DEFAULT '',
     vcpu_num            INTEGER DEFAULT 0,
     mem_total           INTEGER DEFAULT 0 COMMENT 'unit: M',
+    label               TEXT COMMENT 'separated by ,',
     pod_cluster_id      INTEGER,
     region              CHAR(64) DEFAULT '',
     az                  CHAR(64) DEFAULT '',
@@ -1015,6 +1026,7 @@ CREATE TABLE IF NOT EXISTS vtap (
     exceptions              INTEGER UNSIGNED DEFAULT 0,
     vtap_lcuuid             CHAR(64) DEFAULT NULL,
     vtap_group_lcuuid       CHAR(64) DEFAULT NULL,
+    vtap_group_source       INTEGER DEFAULT 0 COMMENT '0: manual 1: rule',
     cpu_num                 INTEGER DEFAULT 0 COMMENT 'logical number of cpu',
     memory_size             BIGINT DEFAULT 0,
     arch                    VARCHAR(256),
@@ -1033,6 +1045,7 @@ TRUNCATE TABLE vtap;
 CREATE TABLE IF NOT EXISTS vtap_group (
     id                      INTEGER NOT NULL AUTO_INCREMENT PRIMARY KEY,
     name                    VARCHAR(64) NOT NULL,
+    org_id                  INTEGER NOT NULL DEFAULT 1 COMMENT 'organization id',
     created_at              DATETIME DEFAULT CURRENT_TIMESTAMP,
     updated_at              DATETIME NOT NULL ON UPDATE CURRENT_TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     lcuuid                  CHAR(64),
@@ -1052,6 +1065,7 @@ CREATE TABLE IF NOT EXISTS acl (
     id                     INTEGER NOT NULL AUTO_INCREMENT PRIMARY KEY,
     business_id            INTEGER NOT NULL,
     name                   CHAR(64),
+    org_id                 INTEGER NOT NULL DEFAULT 1 COMMENT 'organization id',
     type                   INTEGER DEFAULT 2 COMMENT '1-epc; 2-custom',
     tap_type               INTEGER DEFAULT 3 COMMENT '1-WAN; 3-LAN',
     state                  INTEGER DEFAULT 1 COMMENT '0-disable; 1-enable',
@@ -1074,6 +1088,7 @@ CREATE TABLE IF NOT EXISTS `resource_group` (
   `business_id`             INTEGER NOT NULL,
   `lcuuid`                  VARCHAR(64) NOT NULL,
   `name`                    VARCHAR(200) NOT NULL DEFAULT '',
+  `org_id`                  INTEGER NOT NULL DEFAULT 1 COMMENT 'organization id',
   `type`                    INTEGER NOT NULL COMMENT '3: anonymous vm, 4: anonymous ip, 5: anonymous pod, 6: anonymous pod_group, 8: anonymous pod_service, 81: anonymous pod_service as pod_group, 14: anonymous vl2',
   `ip_type`                 INTEGER COMMENT '1: single ip, 2: ip range, 3: cidr, 4.mix [1, 2, 3]',
   `ips`                     TEXT COMMENT 'ips separated by ,',
@@ -1103,6 +1118,7 @@ TRUNCATE TABLE resource_group_extra_info;
 CREATE TABLE IF NOT EXISTS npb_policy (
     id                     INTEGER NOT NULL AUTO_INCREMENT PRIMARY KEY,
     name                   CHAR(64),
+    org_id                 INTEGER NOT NULL DEFAULT 1 COMMENT 'organization id',
     state                  INTEGER DEFAULT 1 COMMENT '0-disable; 1-enable',
     business_id            INTEGER NOT NULL,
     vni                    INTEGER,
@@ -1121,6 +1137,7 @@ TRUNCATE TABLE npb_policy;
 CREATE TABLE IF NOT EXISTS pcap_policy (
     id                     INTEGER NOT NULL AUTO_INCREMENT PRIMARY KEY,
     name                   CHAR(64),
+    org_id                 INTEGER NOT NULL DEFAULT 1 COMMENT 'organization id',
     state                  INTEGER DEFAULT 1 COMMENT '0-disable; 1-enable',
     business_id            INTEGER NOT NULL,
     acl_id                 INTEGER,
@@ -1648,6 +1665,7 @@ TRUNCATE TABLE vtap_group_configuration;
 CREATE TABLE IF NOT EXISTS npb_tunnel (
     id                  INTEGER NOT NULL AUTO_INCREMENT PRIMARY KEY,
     name                CHAR(64) NOT NULL,
+    org_id              INTEGER NOT NULL DEFAULT 1 COMMENT 'organization id',
     ip                  CHAR(64),
     type                INTEGER COMMENT '(0-VXLAN；1-ERSPAN)',
     created_at          TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
@@ -2425,6 +2443,108 @@ CREATE TABLE IF NOT EXISTS mail_server (
 )ENGINE=InnoDB AUTO_INCREMENT=1 DEFAULT CHARSET=utf8;
 TRUNCATE TABLE mail_server;
 
+CREATE TABLE IF NOT EXISTS api_key (
+    id                      INTEGER NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    name                    VARCHAR(64) NOT NULL,
+    role                    VARCHAR(16) NOT NULL COMMENT 'viewer, operator or admin',
+    org_id                  INTEGER NOT NULL DEFAULT 1 COMMENT 'organization id',
+    key_hash                CHAR(64) NOT NULL COMMENT 'sha256 of the key',
+    key_prefix              CHAR(8) NOT NULL,
+    expired_at              DATETIME DEFAULT NULL,
+    created_at              DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at              DATETIME NOT NULL ON UPDATE CURRENT_TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    lcuuid                  CHAR(64) NOT NULL,
+    UNIQUE INDEX key_hash_index(key_hash),
+    UNIQUE INDEX name_index(name)
+)ENGINE=InnoDB AUTO_INCREMENT=1 DEFAULT CHARSET=utf8;
+TRUNCATE TABLE api_key;
+
+CREATE TABLE IF NOT EXISTS audit_log (
+    id                      INTEGER NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    actor                   VARCHAR(256) NOT NULL DEFAULT '' COMMENT 'api key name, jwt subject or user',
+    auth_method             VARCHAR(16) NOT NULL DEFAULT '' COMMENT 'api-key, jwt, internal or empty when auth is disabled',
+    org_id                  INTEGER NOT NULL DEFAULT 1 COMMENT 'organization id',
+    client_ip               VARCHAR(64) NOT NULL DEFAULT '',
+    method                  VARCHAR(16) NOT NULL,
+    route                   VARCHAR(256) NOT NULL COMMENT 'route pattern, such as /v1/domains/:lcuuid/',
+    path                    VARCHAR(512) NOT NULL,
+    resource_type           VARCHAR(64) NOT NULL DEFAULT '',
+    resource_lcuuid         VARCHAR(64) NOT NULL DEFAULT '' COMMENT 'lcuuid or name of the target resource',
+    request_body            TEXT COMMENT 'sensitive fields are masked',
+    diff                    TEXT COMMENT 'json, {field: {"old": x, "new": y}}',
+    status_code             INTEGER NOT NULL DEFAULT 0,
+    result                  VARCHAR(16) NOT NULL DEFAULT '' COMMENT 'SUCCESS or FAIL',
+    description             TEXT,
+    created_at              DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    INDEX created_at_index(created_at),
+    INDEX resource_lcuuid_index(resource_lcuuid)
+)ENGINE=InnoDB AUTO_INCREMENT=1 DEFAULT CHARSET=utf8;
+TRUNCATE TABLE audit_log;
+
+CREATE TABLE IF NOT EXISTS vtap_enrollment_token (
+    id                      INTEGER NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    name                    VARCHAR(64) NOT NULL,
+    vtap_group_lcuuid       CHAR(64) NOT NULL COMMENT 'agents registered by the token join the group',
+    token_hash              CHAR(64) NOT NULL COMMENT 'sha256 of the token',
+    token_prefix            CHAR(8) NOT NULL,
+    max_uses                INTEGER NOT NULL DEFAULT 0 COMMENT '0 means unlimited',
+    used_count              INTEGER NOT NULL DEFAULT 0,
+    expired_at              DATETIME DEFAULT NULL,
+    created_at              DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at              DATETIME NOT NULL ON UPDATE CURRENT_TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    lcuuid                  CHAR(64) NOT NULL,
+    UNIQUE INDEX token_hash_index(token_hash),
+    UNIQUE INDEX name_index(name)
+)ENGINE=InnoDB AUTO_INCREMENT=1 DEFAULT CHARSET=utf8;
+TRUNCATE TABLE vtap_enrollment_token;
+
+CREATE TABLE IF NOT EXISTS vtap_group_rule (
+    id                      INTEGER NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    name                    VARCHAR(64) NOT NULL,
+    priority                INTEGER NOT NULL DEFAULT 100 COMMENT 'rules are matched in ascending order',
+    enabled                 TINYINT(1) NOT NULL DEFAULT 1,
+    vtap_group_lcuuid       CHAR(64) NOT NULL COMMENT 'matched agents are assigned to the group',
+    kubernetes_cluster_ids  TEXT COMMENT 'separated by ,',
+    regions                 TEXT COMMENT 'region lcuuids, separated by ,',
+    azs                     TEXT COMMENT 'az lcuuids, separated by ,',
+    hostname_regex          VARCHAR(256) NOT NULL DEFAULT '',
+    ip_cidrs                TEXT COMMENT 'cidrs of ctrl_ip, separated by ,',
+    pod_node_labels         TEXT COMMENT 'key:value, separated by ,, all of them should match',
+    vtap_types              VARCHAR(256) NOT NULL DEFAULT '' COMMENT 'separated by ,',
+    created_at              DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at              DATETIME NOT NULL ON UPDATE CURRENT_TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    lcuuid                  CHAR(64) NOT NULL,
+    UNIQUE INDEX name_index(name)
+)ENGINE=InnoDB AUTO_INCREMENT=1 DEFAULT CHARSET=utf8;
+TRUNCATE TABLE vtap_group_rule;
+
+-- used by mysql election backend, the lease row may be written before init, do not truncate
+CREATE TABLE IF NOT EXISTS controller_election (
+    name            VARCHAR(64) NOT NULL PRIMARY KEY,
+    holder          VARCHAR(256) NOT NULL DEFAULT '' COMMENT 'node_name/node_ip/pod_name/pod_ip',
+    renew_time      DATETIME NOT NULL
+)ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+CREATE TABLE IF NOT EXISTS resource_history (
+    id                  INTEGER NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    resource_type       VARCHAR(64) NOT NULL COMMENT 'vm, pod_node, pod, pod_service, lan_ip, wan_ip',
+    resource_id         INTEGER NOT NULL DEFAULT 0,
+    lcuuid              CHAR(64) NOT NULL,
+    name                VARCHAR(256) NOT NULL DEFAULT '',
+    ip                  CHAR(64) NOT NULL DEFAULT '',
+    device_type         INTEGER NOT NULL DEFAULT 0 COMMENT 'device of ip',
+    device_id           INTEGER NOT NULL DEFAULT 0,
+    device_name         VARCHAR(256) NOT NULL DEFAULT '',
+    domain              CHAR(64) NOT NULL DEFAULT '',
+    sub_domain          CHAR(64) NOT NULL DEFAULT '',
+    valid_from          DATETIME NOT NULL,
+    valid_to            DATETIME DEFAULT NULL COMMENT 'null means the resource still exists',
+    INDEX ip_index(ip),
+    INDEX lcuuid_index(lcuuid),
+    INDEX valid_from_index(valid_from)
+)ENGINE=InnoDB AUTO_INCREMENT=1 DEFAULT CHARSET=utf8;
+TRUNCATE TABLE resource_history;
+
 
 CREATE TABLE IF NOT EXISTS ch_string_enum (
     tag_name                VARCHAR(256) NOT NULL ,
@@ -0,0 +1,3 @@
+ALTER TABLE domain ADD COLUMN org_id INTEGER NOT NULL DEFAULT 1 COMMENT 'organization id' AFTER name;
+
+UPDATE db_version SET version='6.3.1.48';
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS api_key (
+    id                      INTEGER NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    name                    VARCHAR(64) NOT NULL,
+    role                    VARCHAR(16) NOT NULL COMMENT 'viewer, operator or admin',
+    org_id                  INTEGER NOT NULL DEFAULT 1 COMMENT 'organization id',
+    key_hash                CHAR(64) NOT NULL COMMENT 'sha256 of the key',
+    key_prefix              CHAR(8) NOT NULL,
+    expired_at              DATETIME DEFAULT NULL,
+    created_at              DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at              DATETIME NOT NULL ON UPDATE CURRENT_TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    lcuuid                  CHAR(64) NOT NULL,
+    UNIQUE INDEX key_hash_index(key_hash),
+    UNIQUE INDEX name_index(name)
+)ENGINE=InnoDB AUTO_INCREMENT=1 DEFAULT CHARSET=utf8;
+
+UPDATE db_version SET version='6.3.1.49';
@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS audit_log (
+    id                      INTEGER NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    actor                   VARCHAR(256) NOT NULL DEFAULT '' COMMENT 'api key name, jwt subject or user',
+    auth_method             VARCHAR(16) NOT NULL DEFAULT '' COMMENT 'api-key, jwt, internal or empty when auth is disabled',
+    org_id                  INTEGER NOT NULL DEFAULT 1 COMMENT 'organization id',
+    client_ip               VARCHAR(64) NOT NULL DEFAULT '',
+    method                  VARCHAR(16) NOT NULL,
+    route                   VARCHAR(256) NOT NULL COMMENT 'route pattern, such as /v1/domains/:lcuuid/',
+    path                    VARCHAR(512) NOT NULL,
+    resource_type           VARCHAR(64) NOT NULL DEFAULT '',
+    resource_lcuuid         VARCHAR(64) NOT NULL DEFAULT '' COMMENT 'lcuuid or name of the target resource',
+    request_body            TEXT COMMENT 'sensitive fields are masked',
+    diff                    TEXT COMMENT 'json, {field: {"old": x, "new": y}}',
+    status_code             INTEGER NOT NULL DEFAULT 0,
+    result                  VARCHAR(16) NOT NULL DEFAULT '' COMMENT 'SUCCESS or FAIL',
+    description             TEXT,
+    created_at              DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    INDEX created_at_index(created_at),
+    INDEX resource_lcuuid_index(resource_lcuuid)
+)ENGINE=InnoDB AUTO_INCREMENT=1 DEFAULT CHARSET=utf8;
+
+UPDATE db_version SET version='6.3.1.50';
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS vtap_enrollment_token (
+    id                      INTEGER NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    name                    VARCHAR(64) NOT NULL,
+    vtap_group_lcuuid       CHAR(64) NOT NULL COMMENT 'agents registered by the token join the group',
+    token_hash              CHAR(64) NOT NULL COMMENT 'sha256 of the token',
+    token_prefix            CHAR(8) NOT NULL,
+    max_uses                INTEGER NOT NULL DEFAULT 0 COMMENT '0 means unlimited',
+    used_count              INTEGER NOT NULL DEFAULT 0,
+    expired_at              DATETIME DEFAULT NULL,
+    created_at              DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at              DATETIME NOT NULL ON UPDATE CURRENT_TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    lcuuid                  CHAR(64) NOT NULL,
+    UNIQUE INDEX token_hash_index(token_hash),
+    UNIQUE INDEX name_index(name)
+)ENGINE=InnoDB AUTO_INCREMENT=1 DEFAULT CHARSET=utf8;
+
+UPDATE db_version SET version='6.3.1.51';
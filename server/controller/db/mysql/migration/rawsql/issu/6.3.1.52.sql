@@ -0,0 +1,3 @@
+ALTER TABLE pod_node ADD COLUMN label TEXT COMMENT 'separated by ,' AFTER mem_total;
+
+UPDATE db_version SET version='6.3.1.52';
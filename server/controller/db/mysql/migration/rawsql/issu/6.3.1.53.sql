@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS vtap_group_rule (
+    id                      INTEGER NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    name                    VARCHAR(64) NOT NULL,
+    priority                INTEGER NOT NULL DEFAULT 100 COMMENT 'rules are matched in ascending order',
+    enabled                 TINYINT(1) NOT NULL DEFAULT 1,
+    vtap_group_lcuuid       CHAR(64) NOT NULL COMMENT 'matched agents are assigned to the group',
+    kubernetes_cluster_ids  TEXT COMMENT 'separated by ,',
+    regions                 TEXT COMMENT 'region lcuuids, separated by ,',
+    azs                     TEXT COMMENT 'az lcuuids, separated by ,',
+    hostname_regex          VARCHAR(256) NOT NULL DEFAULT '',
+    ip_cidrs                TEXT COMMENT 'cidrs of ctrl_ip, separated by ,',
+    pod_node_labels         TEXT COMMENT 'key:value, separated by ,, all of them should match',
+    vtap_types              VARCHAR(256) NOT NULL DEFAULT '' COMMENT 'separated by ,',
+    created_at              DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at              DATETIME NOT NULL ON UPDATE CURRENT_TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    lcuuid                  CHAR(64) NOT NULL,
+    UNIQUE INDEX name_index(name)
+)ENGINE=InnoDB AUTO_INCREMENT=1 DEFAULT CHARSET=utf8;
+
+UPDATE db_version SET version='6.3.1.53';
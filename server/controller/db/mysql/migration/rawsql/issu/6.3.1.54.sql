@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS controller_election (
+    name            VARCHAR(64) NOT NULL PRIMARY KEY,
+    holder          VARCHAR(256) NOT NULL DEFAULT '' COMMENT 'node_name/node_ip/pod_name/pod_ip',
+    renew_time      DATETIME NOT NULL
+)ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+UPDATE db_version SET version='6.3.1.54';
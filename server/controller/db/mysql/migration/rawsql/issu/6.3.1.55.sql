@@ -0,0 +1,36 @@
+CREATE TABLE IF NOT EXISTS resource_history (
+    id                  INTEGER NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    resource_type       VARCHAR(64) NOT NULL COMMENT 'vm, pod_node, pod, pod_service, lan_ip, wan_ip',
+    resource_id         INTEGER NOT NULL DEFAULT 0,
+    lcuuid              CHAR(64) NOT NULL,
+    name                VARCHAR(256) NOT NULL DEFAULT '',
+    ip                  CHAR(64) NOT NULL DEFAULT '',
+    device_type         INTEGER NOT NULL DEFAULT 0 COMMENT 'device of ip',
+    device_id           INTEGER NOT NULL DEFAULT 0,
+    device_name         VARCHAR(256) NOT NULL DEFAULT '',
+    domain              CHAR(64) NOT NULL DEFAULT '',
+    sub_domain          CHAR(64) NOT NULL DEFAULT '',
+    valid_from          DATETIME NOT NULL,
+    valid_to            DATETIME DEFAULT NULL COMMENT 'null means the resource still exists',
+    INDEX ip_index(ip),
+    INDEX lcuuid_index(lcuuid),
+    INDEX valid_from_index(valid_from)
+)ENGINE=InnoDB AUTO_INCREMENT=1 DEFAULT CHARSET=utf8;
+
+-- existing resources are valid since they were created, device names of ips are filled in by later changes
+INSERT INTO resource_history (resource_type, resource_id, lcuuid, name, domain, valid_from)
+    SELECT 'vm', id, lcuuid, name, domain, created_at FROM vm WHERE deleted_at IS NULL;
+INSERT INTO resource_history (resource_type, resource_id, lcuuid, name, ip, domain, sub_domain, valid_from)
+    SELECT 'pod_node', id, lcuuid, name, ip, domain, sub_domain, created_at FROM pod_node WHERE deleted_at IS NULL;
+INSERT INTO resource_history (resource_type, resource_id, lcuuid, name, domain, sub_domain, valid_from)
+    SELECT 'pod', id, lcuuid, name, domain, sub_domain, created_at FROM pod WHERE deleted_at IS NULL;
+INSERT INTO resource_history (resource_type, resource_id, lcuuid, name, ip, domain, sub_domain, valid_from)
+    SELECT 'pod_service', id, lcuuid, name, service_cluster_ip, domain, sub_domain, created_at FROM pod_service WHERE deleted_at IS NULL;
+INSERT INTO resource_history (resource_type, resource_id, lcuuid, ip, device_type, device_id, domain, sub_domain, valid_from)
+    SELECT 'lan_ip', vinterface_ip.id, vinterface_ip.lcuuid, vinterface_ip.ip, vinterface.devicetype, vinterface.deviceid, vinterface_ip.domain, vinterface_ip.sub_domain, vinterface_ip.created_at
+    FROM vinterface_ip JOIN vinterface ON vinterface_ip.vifid = vinterface.id;
+INSERT INTO resource_history (resource_type, resource_id, lcuuid, ip, device_type, device_id, domain, sub_domain, valid_from)
+    SELECT 'wan_ip', ip_resource.id, ip_resource.lcuuid, ip_resource.ip, vinterface.devicetype, vinterface.deviceid, ip_resource.domain, ip_resource.sub_domain, ip_resource.created_at
+    FROM ip_resource JOIN vinterface ON ip_resource.vifid = vinterface.id;
+
+UPDATE db_version SET version='6.3.1.55';
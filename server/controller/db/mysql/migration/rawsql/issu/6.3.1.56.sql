@@ -0,0 +1,7 @@
+ALTER TABLE resource_group ADD COLUMN org_id INTEGER NOT NULL DEFAULT 1 COMMENT 'organization id' AFTER name;
+ALTER TABLE acl ADD COLUMN org_id INTEGER NOT NULL DEFAULT 1 COMMENT 'organization id' AFTER name;
+ALTER TABLE npb_tunnel ADD COLUMN org_id INTEGER NOT NULL DEFAULT 1 COMMENT 'organization id' AFTER name;
+ALTER TABLE npb_policy ADD COLUMN org_id INTEGER NOT NULL DEFAULT 1 COMMENT 'organization id' AFTER name;
+ALTER TABLE pcap_policy ADD COLUMN org_id INTEGER NOT NULL DEFAULT 1 COMMENT 'organization id' AFTER name;
+
+UPDATE db_version SET version='6.3.1.56';
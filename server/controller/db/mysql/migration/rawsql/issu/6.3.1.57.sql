@@ -0,0 +1,3 @@
+ALTER TABLE vtap ADD COLUMN vtap_group_source INTEGER DEFAULT 0 COMMENT '0: manual 1: rule' AFTER vtap_group_lcuuid;
+
+UPDATE db_version SET version='6.3.1.57';
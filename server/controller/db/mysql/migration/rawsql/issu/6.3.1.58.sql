@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS k8s_event_position (
+    id                  INTEGER NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    domain              CHAR(64) DEFAULT '',
+    sub_domain          CHAR(64) DEFAULT '',
+    last_time           DATETIME NOT NULL COMMENT 'last time of kubernetes events enqueued',
+    UNIQUE INDEX domain_sub_domain (domain, sub_domain)
+)ENGINE=innodb AUTO_INCREMENT=1 DEFAULT CHARSET=utf8;
+
+UPDATE db_version SET version='6.3.1.58';
@@ -65,6 +65,18 @@ type ResourceEvent struct {
 	CreatedAt      time.Time `gorm:"autoCreateTime;column:created_at;type:datetime" json:"CREATED_AT"`
 }
 
+// K8sEventPosition is the last time of kubernetes events enqueued of a domain or sub_domain
+type K8sEventPosition struct {
+	ID        int       `gorm:"primaryKey;autoIncrement;unique;column:id;type:int;not null" json:"ID"`
+	Domain    string    `gorm:"uniqueIndex:domain_sub_domain;column:domain;type:char(64);default:''" json:"DOMAIN"`
+	SubDomain string    `gorm:"uniqueIndex:domain_sub_domain;column:sub_domain;type:char(64);default:''" json:"SUB_DOMAIN"`
+	LastTime  time.Time `gorm:"column:last_time;type:datetime" json:"LAST_TIME"`
+}
+
+func (K8sEventPosition) TableName() string {
+	return "k8s_event_position"
+}
+
 type DomainAdditionalResource struct {
 	ID                int             `gorm:"primaryKey;autoIncrement;unique;column:id;type:int;not null" json:"ID"`
 	Domain            string          `gorm:"column:domain;type:char(64);default:''" json:"DOMAIN"`
@@ -94,6 +106,7 @@ type Domain struct {
 	OperatedTime `gorm:"embedded"`
 	SyncedAt     *time.Time `gorm:"column:synced_at" json:"SYNCED_AT"`
 	Name         string     `gorm:"column:name;type:varchar(64)" json:"NAME"`
+	OrgID        int        `gorm:"column:org_id;type:int;not null;default:1" json:"ORG_ID"`
 	IconID       int        `gorm:"column:icon_id;type:int" json:"ICON_ID"`
 	DisplayName  string     `gorm:"column:display_name;type:varchar(64);default:''" json:"DISPLAY_NAME"`
 	ClusterID    string     `gorm:"column:cluster_id;type:char(64)" json:"CLUSTER_ID"`
@@ -674,6 +687,7 @@ type PodNode struct {
 	IP             string `gorm:"column:ip;type:char(64);default:''" json:"IP"`
 	VCPUNum        int    `gorm:"column:vcpu_num;type:int;default:0" json:"VCPU_NUM"`
 	MemTotal       int    `gorm:"column:mem_total;type:int;default:0" json:"MEM_TOTAL"` // unit: M
+	Label          string `gorm:"column:label;type:text;default:''" json:"LABEL"`       // separated by ,
 	PodClusterID   int    `gorm:"column:pod_cluster_id;type:int;default:null" json:"POD_CLUSTER_ID"`
 	Region         string `gorm:"column:region;type:char(64);default:''" json:"REGION"`
 	AZ             string `gorm:"column:az;type:char(64);default:''" json:"AZ"`
@@ -847,6 +861,7 @@ type ResourceGroup struct {
 	BusinessID    int       `gorm:"column:business_id;type:int;not null" json:"BUSINESS_ID"`
 	Lcuuid        string    `gorm:"column:lcuuid;type:varchar(64);not null" json:"LCUUID"`
 	Name          string    `gorm:"column:name;type:varchar(200);not null;default:''" json:"NAME"`
+	OrgID         int       `gorm:"column:org_id;type:int;not null;default:1" json:"ORG_ID"`
 	Type          int       `gorm:"column:type;type:int;not null" json:"TYPE"`            // 1:vm, 2:ip, 3: anonymous vm, 4: anonymous ip, 5: reserved for pod_group, 6: anonymous pod_group, 7: reserved for pod_service, 8: anonymous pod_service, 81: anonymous pod_service as pod_group, 9：lb_bk_rule, 10：reserved for anonymous lb_bk_rule, 11: tmp vm, 21: tmp ip, 13: reserve for vl2, 14: anonymous vl2
 	IPType        int       `gorm:"column:ip_type;type:int;default:null" json:"IP_TYPE"`  // 1: single ip, 2: ip range, 3: cidr, 4.mix [1, 2, 3]
 	IPs           string    `gorm:"column:ips;type:text;default:null" json:"IPS"`         // ips separated by ,
@@ -987,7 +1002,8 @@ type VTap struct {
 	Exceptions         int64     `gorm:"column:exceptions;type:int unsigned;default:0" json:"EXCEPTIONS"`
 	VTapLcuuid         string    `gorm:"column:vtap_lcuuid;type:char(64);default:null" json:"VTAP_LCUUID"`
 	VtapGroupLcuuid    string    `gorm:"column:vtap_group_lcuuid;type:char(64);default:null" json:"VTAP_GROUP_LCUUID"`
-	CPUNum             int       `gorm:"column:cpu_num;type:int;default:0" json:"CPU_NUM"` // logical number of cpu
+	VtapGroupSource    int       `gorm:"column:vtap_group_source;type:int;default:0" json:"VTAP_GROUP_SOURCE"` // 0: manual 1: rule
+	CPUNum             int       `gorm:"column:cpu_num;type:int;default:0" json:"CPU_NUM"`                     // logical number of cpu
 	MemorySize         int64     `gorm:"column:memory_size;type:bigint;default:0" json:"MEMORY_SIZE"`
 	Arch               string    `gorm:"column:arch;type:varchar(256);default:null" json:"ARCH"`
 	Os                 string    `gorm:"column:os;type:varchar(256);default:null" json:"OS"`
@@ -1008,6 +1024,7 @@ func (VTap) TableName() string {
 type VTapGroup struct {
 	ID        int       `gorm:"primaryKey;column:id;type:int;not null" json:"ID"`
 	Name      string    `gorm:"column:name;type:varchar(64);not null" json:"NAME"`
+	OrgID     int       `gorm:"column:org_id;type:int;not null;default:1" json:"ORG_ID"`
 	CreatedAt time.Time `gorm:"column:created_at;type:datetime;default:CURRENT_TIMESTAMP" json:"CREATED_AT"`
 	UpdatedAt time.Time `gorm:"column:updated_at;type:datetime;not null;default:CURRENT_TIMESTAMP" json:"UPDATED_AT"`
 	Lcuuid    string    `gorm:"column:lcuuid;type:char(64);not null" json:"LCUUID"`
@@ -1197,6 +1214,7 @@ type ACL struct {
 	ID           int       `gorm:"primaryKey;column:id;type:int;not null" json:"ID"`
 	BusinessID   int       `gorm:"column:business_id;type:int;not null" json:"BUSINESS_ID"`
 	Name         string    `gorm:"column:name;type:char(64);default:null" json:"NAME"`
+	OrgID        int       `gorm:"column:org_id;type:int;not null;default:1" json:"ORG_ID"`
 	Type         int       `gorm:"column:type;type:int;default:null;default:2" json:"TYPE"`         // 1-epc; 2-custom
 	TapType      int       `gorm:"column:tap_type;type:int;default:null;default:3" json:"TAP_TYPE"` // 1-WAN; 3-LAN
 	State        int       `gorm:"column:state;type:int;default:null;default:1" json:"STATE"`       // 0-disable; 1-enable
@@ -1233,6 +1251,7 @@ func (ResourceGroupExtraInfo) TableName() string {
 type NpbPolicy struct {
 	ID               int       `gorm:"primaryKey;column:id;type:int;not null" json:"ID"`
 	Name             string    `gorm:"column:name;type:char(64);default:null" json:"NAME"`
+	OrgID            int       `gorm:"column:org_id;type:int;not null;default:1" json:"ORG_ID"`
 	State            int       `gorm:"column:state;type:int;default:null;default:1" json:"STATE"` // 0-disable; 1-enable
 	BusinessID       int       `gorm:"column:business_id;type:int;not null" json:"BUSINESS_ID"`
 	Vni              int       `gorm:"column:vni;type:int;default:null" json:"VNI"`
@@ -1255,6 +1274,7 @@ func (NpbPolicy) TableName() string {
 type NpbTunnel struct {
 	ID        int       `gorm:"primaryKey;column:id;type:int;not null" json:"ID"`
 	Name      string    `gorm:"column:name;type:char(64);not null" json:"NAME"`
+	OrgID     int       `gorm:"column:org_id;type:int;not null;default:1" json:"ORG_ID"`
 	IP        string    `gorm:"column:ip;type:char(64);default:null" json:"IP"`
 	Type      int       `gorm:"column:type;type:int;default:null" json:"TYPE"` // (0-VXLAN；1-ERSPAN)
 	CreatedAt time.Time `gorm:"column:created_at;type:timestamp;not null;default:CURRENT_TIMESTAMP" json:"CREATED_AT"`
@@ -1270,6 +1290,7 @@ func (NpbTunnel) TableName() string {
 type PcapPolicy struct {
 	ID               int       `gorm:"primaryKey;column:id;type:int;not null" json:"ID"`
 	Name             string    `gorm:"column:name;type:char(64);default:null" json:"NAME"`
+	OrgID            int       `gorm:"column:org_id;type:int;not null;default:1" json:"ORG_ID"`
 	State            int       `gorm:"column:state;type:int;default:null;default:1" json:"STATE"` // 0-disable; 1-enable
 	BusinessID       int       `gorm:"column:business_id;type:int;not null" json:"BUSINESS_ID"`
 	ACLID            int       `gorm:"column:acl_id;type:int;default:null" json:"ACL_ID"`
@@ -1286,6 +1307,17 @@ func (PcapPolicy) TableName() string {
 	return "pcap_policy"
 }
 
+// PolicyACLGroup id is the acl_gid of flow logs hit by npb or pcap policies
+type PolicyACLGroup struct {
+	ID     int    `gorm:"primaryKey;column:id;type:int;not null" json:"ID"`
+	ACLIDs string `gorm:"column:acl_ids;type:text;not null" json:"ACL_IDS"` // separated by ,
+	Count  int    `gorm:"column:count;type:int;not null" json:"COUNT"`
+}
+
+func (PolicyACLGroup) TableName() string {
+	return "policy_acl_group"
+}
+
 type DialTestTask struct {
 	ID            int       `gorm:"primaryKey;column:id;type:int;not null" json:"ID"`
 	Name          string    `gorm:"column:name;type:varchar(256);not null" json:"NAME"`
@@ -1392,3 +1424,104 @@ type MailServer struct {
 func (MailServer) TableName() string {
 	return "mail_server"
 }
+
+type APIKey struct {
+	ID        int        `gorm:"primaryKey;column:id;type:int;not null" json:"ID"`
+	Name      string     `gorm:"column:name;type:varchar(64);not null" json:"NAME"`
+	Role      string     `gorm:"column:role;type:varchar(16);not null" json:"ROLE"` // viewer, operator or admin
+	OrgID     int        `gorm:"column:org_id;type:int;not null;default:1" json:"ORG_ID"`
+	KeyHash   string     `gorm:"column:key_hash;type:char(64);not null" json:"-"`
+	KeyPrefix string     `gorm:"column:key_prefix;type:char(8);not null" json:"KEY_PREFIX"`
+	ExpiredAt *time.Time `gorm:"column:expired_at;type:datetime;default:null" json:"EXPIRED_AT"`
+	CreatedAt time.Time  `gorm:"column:created_at;type:datetime;not null;default:CURRENT_TIMESTAMP" json:"CREATED_AT"`
+	UpdatedAt time.Time  `gorm:"column:updated_at;type:datetime;not null;default:CURRENT_TIMESTAMP" json:"UPDATED_AT"`
+	Lcuuid    string     `gorm:"unique;column:lcuuid;type:char(64)" json:"LCUUID"`
+}
+
+func (APIKey) TableName() string {
+	return "api_key"
+}
+
+type AuditLog struct {
+	ID             int       `gorm:"primaryKey;column:id;type:int;not null" json:"ID"`
+	Actor          string    `gorm:"column:actor;type:varchar(256);not null;default:''" json:"ACTOR"`
+	AuthMethod     string    `gorm:"column:auth_method;type:varchar(16);not null;default:''" json:"AUTH_METHOD"`
+	OrgID          int       `gorm:"column:org_id;type:int;not null;default:1" json:"ORG_ID"`
+	ClientIP       string    `gorm:"column:client_ip;type:varchar(64);not null;default:''" json:"CLIENT_IP"`
+	Method         string    `gorm:"column:method;type:varchar(16);not null" json:"METHOD"`
+	Route          string    `gorm:"column:route;type:varchar(256);not null" json:"ROUTE"`
+	Path           string    `gorm:"column:path;type:varchar(512);not null" json:"PATH"`
+	ResourceType   string    `gorm:"column:resource_type;type:varchar(64);not null;default:''" json:"RESOURCE_TYPE"`
+	ResourceLcuuid string    `gorm:"column:resource_lcuuid;type:varchar(64);not null;default:''" json:"RESOURCE_LCUUID"`
+	RequestBody    string    `gorm:"column:request_body;type:text" json:"REQUEST_BODY"`
+	Diff           string    `gorm:"column:diff;type:text" json:"DIFF"`
+	StatusCode     int       `gorm:"column:status_code;type:int;not null;default:0" json:"STATUS_CODE"`
+	Result         string    `gorm:"column:result;type:varchar(16);not null;default:''" json:"RESULT"`
+	Description    string    `gorm:"column:description;type:text" json:"DESCRIPTION"`
+	CreatedAt      time.Time `gorm:"column:created_at;type:datetime;not null;default:CURRENT_TIMESTAMP" json:"CREATED_AT"`
+}
+
+func (AuditLog) TableName() string {
+	return "audit_log"
+}
+
+type VTapEnrollmentToken struct {
+	ID              int        `gorm:"primaryKey;column:id;type:int;not null" json:"ID"`
+	Name            string     `gorm:"column:name;type:varchar(64);not null" json:"NAME"`
+	VTapGroupLcuuid string     `gorm:"column:vtap_group_lcuuid;type:char(64);not null" json:"VTAP_GROUP_LCUUID"`
+	TokenHash       string     `gorm:"column:token_hash;type:char(64);not null" json:"-"`
+	TokenPrefix     string     `gorm:"column:token_prefix;type:char(8);not null" json:"TOKEN_PREFIX"`
+	MaxUses         int        `gorm:"column:max_uses;type:int;not null;default:0" json:"MAX_USES"` // 0 means unlimited
+	UsedCount       int        `gorm:"column:used_count;type:int;not null;default:0" json:"USED_COUNT"`
+	ExpiredAt       *time.Time `gorm:"column:expired_at;type:datetime;default:null" json:"EXPIRED_AT"`
+	CreatedAt       time.Time  `gorm:"column:created_at;type:datetime;not null;default:CURRENT_TIMESTAMP" json:"CREATED_AT"`
+	UpdatedAt       time.Time  `gorm:"column:updated_at;type:datetime;not null;default:CURRENT_TIMESTAMP" json:"UPDATED_AT"`
+	Lcuuid          string     `gorm:"unique;column:lcuuid;type:char(64)" json:"LCUUID"`
+}
+
+func (VTapEnrollmentToken) TableName() string {
+	return "vtap_enrollment_token"
+}
+
+type VTapGroupRule struct {
+	ID                   int       `gorm:"primaryKey;column:id;type:int;not null" json:"ID"`
+	Name                 string    `gorm:"column:name;type:varchar(64);not null" json:"NAME"`
+	Priority             int       `gorm:"column:priority;type:int;not null;default:100" json:"PRIORITY"` // rules are matched in ascending order
+	Enabled              bool      `gorm:"column:enabled;type:tinyint(1);not null;default:1" json:"ENABLED"`
+	VTapGroupLcuuid      string    `gorm:"column:vtap_group_lcuuid;type:char(64);not null" json:"VTAP_GROUP_LCUUID"`
+	KubernetesClusterIDs string    `gorm:"column:kubernetes_cluster_ids;type:text" json:"KUBERNETES_CLUSTER_IDS"` // separated by ,
+	Regions              string    `gorm:"column:regions;type:text" json:"REGIONS"`                               // separated by ,
+	AZs                  string    `gorm:"column:azs;type:text" json:"AZS"`                                       // separated by ,
+	HostnameRegex        string    `gorm:"column:hostname_regex;type:varchar(256);not null;default:''" json:"HOSTNAME_REGEX"`
+	IPCIDRs              string    `gorm:"column:ip_cidrs;type:text" json:"IP_CIDRS"`               // separated by ,
+	PodNodeLabels        string    `gorm:"column:pod_node_labels;type:text" json:"POD_NODE_LABELS"` // key:value, separated by ,
+	VTapTypes            string    `gorm:"column:vtap_types;type:varchar(256);not null;default:''" json:"VTAP_TYPES"`
+	CreatedAt            time.Time `gorm:"column:created_at;type:datetime;not null;default:CURRENT_TIMESTAMP" json:"CREATED_AT"`
+	UpdatedAt            time.Time `gorm:"column:updated_at;type:datetime;not null;default:CURRENT_TIMESTAMP" json:"UPDATED_AT"`
+	Lcuuid               string    `gorm:"unique;column:lcuuid;type:char(64)" json:"LCUUID"`
+}
+
+func (VTapGroupRule) TableName() string {
+	return "vtap_group_rule"
+}
+
+// ResourceHistory records the valid interval of a resource, valid_to is null while the resource still exists.
+type ResourceHistory struct {
+	ID           int        `gorm:"primaryKey;autoIncrement;unique;column:id;type:int;not null" json:"ID"`
+	ResourceType string     `gorm:"column:resource_type;type:varchar(64);not null" json:"RESOURCE_TYPE"`
+	ResourceID   int        `gorm:"column:resource_id;type:int;not null;default:0" json:"RESOURCE_ID"`
+	Lcuuid       string     `gorm:"column:lcuuid;type:char(64);not null" json:"LCUUID"`
+	Name         string     `gorm:"column:name;type:varchar(256);not null;default:''" json:"NAME"`
+	IP           string     `gorm:"column:ip;type:char(64);not null;default:''" json:"IP"`
+	DeviceType   int        `gorm:"column:device_type;type:int;not null;default:0" json:"DEVICE_TYPE"`
+	DeviceID     int        `gorm:"column:device_id;type:int;not null;default:0" json:"DEVICE_ID"`
+	DeviceName   string     `gorm:"column:device_name;type:varchar(256);not null;default:''" json:"DEVICE_NAME"`
+	Domain       string     `gorm:"column:domain;type:char(64);not null;default:''" json:"DOMAIN"`
+	SubDomain    string     `gorm:"column:sub_domain;type:char(64);not null;default:''" json:"SUB_DOMAIN"`
+	ValidFrom    time.Time  `gorm:"column:valid_from;type:datetime;not null" json:"VALID_FROM"`
+	ValidTo      *time.Time `gorm:"column:valid_to;type:datetime;default:null" json:"VALID_TO"`
+}
+
+func (ResourceHistory) TableName() string {
+	return "resource_history"
+}
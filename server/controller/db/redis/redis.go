@@ -99,6 +99,11 @@ func createUniversalClient(cfg Config, database int) redis.UniversalClient {
 	}
 }
 
+// NewUniversalClient creates a client independent of the global one, which may not be initialized
+func NewUniversalClient(cfg Config, database int) redis.UniversalClient {
+	return createUniversalClient(cfg, database)
+}
+
 func Init(ctx context.Context, cfg Config) (err error) {
 	clientOnce.Do(func() {
 		client = &Client{
@@ -14,25 +14,15 @@
  * limitations under the License.
  */
 
-// Reference code: https://github.com/kubernetes/client-go/blob/master/examples/leader-election/main.go
-
 package election
 
 import (
 	"context"
 	"fmt"
-	"os"
 	"sync"
 	"time"
 
 	logging "github.com/op/go-logging"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/util/wait"
-	clientset "k8s.io/client-go/kubernetes"
-	"k8s.io/client-go/rest"
-	"k8s.io/client-go/tools/clientcmd"
-	"k8s.io/client-go/tools/leaderelection"
-	"k8s.io/client-go/tools/leaderelection/resourcelock"
 
 	"github.com/deepflowio/deepflow/server/controller/common"
 	"github.com/deepflowio/deepflow/server/controller/config"
@@ -41,6 +31,14 @@ import (
 
 const (
 	ID_ITEM_NUM = 4
+
+	BACKEND_KUBERNETES = "kubernetes"
+	BACKEND_MYSQL      = "mysql"
+	BACKEND_REDIS      = "redis"
+
+	LEASE_DURATION = 60 * time.Second
+	RENEW_DEADLINE = 15 * time.Second
+	RETRY_PERIOD   = 5 * time.Second
 )
 
 type LeaderData struct {
@@ -75,22 +73,6 @@ var leaderData = &LeaderData{
 	isValide: atomicbool.NewBool(false),
 }
 
-func buildConfig(kubeconfig string) (*rest.Config, error) {
-	if kubeconfig != "" {
-		cfg, err := clientcmd.BuildConfigFromFlags("", kubeconfig)
-		if err != nil {
-			return nil, err
-		}
-		return cfg, nil
-	}
-
-	cfg, err := rest.InClusterConfig()
-	if err != nil {
-		return nil, err
-	}
-	return cfg, nil
-}
-
 func getID() string {
 	return fmt.Sprintf("%s/%s/%s/%s",
 		common.GetNodeName(),
@@ -103,125 +85,18 @@ func GetLeader() string {
 	return leaderData.GetLeader()
 }
 
-func getCurrentLeader(ctx context.Context, lock *resourcelock.LeaseLock) string {
-	ctx, cancel := context.WithCancel(ctx)
-	defer cancel()
-	record, _, err := lock.Get(ctx)
-	if err != nil {
-		log.Error(err)
-		return ""
-	}
-
-	return record.HolderIdentity
-}
-
-func checkLeaderValid(ctx context.Context, lock *resourcelock.LeaseLock) {
-	ticker := time.NewTicker(5 * time.Second)
-	defer ticker.Stop()
-	ctx, cancel := context.WithCancel(ctx)
-	defer cancel()
-
-	var observedTime metav1.Time
-	for {
-		record, _, err := lock.Get(ctx)
-		if err == nil {
-			observedTime = record.RenewTime
-			break
-		} else {
-			log.Error(err)
-			time.Sleep(5 * time.Second)
-		}
-	}
-
-	for {
-		select {
-		case <-ticker.C:
-			record, _, err := lock.Get(ctx)
-			if err != nil {
-				log.Error(err)
-				continue
-			}
-			if !record.RenewTime.Equal(&observedTime) {
-				leaderData.setValide()
-				leaderData.SetLeader(record.HolderIdentity)
-				log.Infof("check leader finish, leader is %s", record.HolderIdentity)
-				return
-			} else {
-				log.Warningf("leader(%v) validity has expired", record)
-			}
-		}
-	}
-}
-
 func Start(ctx context.Context, cfg *config.ControllerConfig) {
-	kubeconfig := cfg.Kubeconfig
-	electionName := cfg.ElectionName
-	electionNamespace := common.GetNameSpace()
 	id := getID()
-	log.Infof("election id is %s", id)
-	// leader election uses the Kubernetes API by writing to a
-	// lock object, which can be a LeaseLock object (preferred),
-	// a ConfigMap, or an Endpoints (deprecated) object.
-	// Conflicting writes are detected and each client handles those actions
-	// independently.
-	config, err := buildConfig(kubeconfig)
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	client := clientset.NewForConfigOrDie(config)
-
-	lock := &resourcelock.LeaseLock{
-		LeaseMeta: metav1.ObjectMeta{
-			Name:      electionName,
-			Namespace: electionNamespace,
-		},
-		Client: client.CoordinationV1(),
-		LockConfig: resourcelock.ResourceLockConfig{
-			Identity: id,
-		},
-	}
-
-	go checkLeaderValid(ctx, lock)
-
-	// start the leader election code loop
-	le, err := leaderelection.NewLeaderElector(leaderelection.LeaderElectionConfig{
-		Lock: lock,
-		// IMPORTANT: you MUST ensure that any code you have that
-		// is protected by the lease must terminate **before**
-		// you call cancel. Otherwise, you could have a background
-		// loop still running and another process could
-		// get elected before your background loop finished, violating
-		// the stated goal of the lease.
-		ReleaseOnCancel: true,
-		LeaseDuration:   60 * time.Second,
-		RenewDeadline:   15 * time.Second,
-		RetryPeriod:     5 * time.Second,
-		Callbacks: leaderelection.LeaderCallbacks{
-			OnStartedLeading: func(ctx context.Context) {
-				// we're notified when we start - this is where you would
-				// usually put your code
-				log.Infof("%s is the leader", id)
-				leaderData.SetLeader(id)
-			},
-			OnStoppedLeading: func() {
-				// we can do cleanup here
-				log.Infof("leader lost: %s", id)
-				leaderData.SetLeader(getCurrentLeader(ctx, lock))
-			},
-			OnNewLeader: func(identity string) {
-				if leaderData.getValide() {
-					leaderData.SetLeader(identity)
-					// we're notified when new leader elected
-					log.Infof("new leader elected: %s", identity)
-				}
-			},
-		},
-	})
-	if err != nil {
-		log.Errorf("failed to create election: %v", err)
-		time.Sleep(1 * time.Second)
-		os.Exit(1)
+	log.Infof("election id is %s, backend is %s", id, cfg.ElectionBackend)
+	switch cfg.ElectionBackend {
+	case BACKEND_MYSQL:
+		runLeaseElection(ctx, newMySQLLeaseLock(cfg.MySqlCfg, cfg.ElectionName), id)
+	case BACKEND_REDIS:
+		if len(cfg.RedisCfg.Host) == 0 {
+			log.Fatal("redis host is required by redis election backend")
+		}
+		runLeaseElection(ctx, newRedisLeaseLock(cfg.RedisCfg, cfg.ElectionName), id)
+	default:
+		startKubernetes(ctx, cfg)
 	}
-	wait.UntilWithContext(ctx, le.Run, 0)
 }
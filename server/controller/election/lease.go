@@ -0,0 +1,95 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package election
+
+import (
+	"context"
+	"time"
+)
+
+// leaseLock is a lease shared by all controllers, used by the backends which are not kubernetes
+type leaseLock interface {
+	// acquire the lease if it is free or expired, renew it if it is held by id, return the current holder
+	tryAcquireOrRenew(ctx context.Context, id string) (string, error)
+	// release the lease if it is held by id
+	release(ctx context.Context, id string) error
+}
+
+type leaseElector struct {
+	lock      leaseLock
+	id        string
+	renewedAt time.Time
+}
+
+func runLeaseElection(ctx context.Context, lock leaseLock, id string) {
+	le := &leaseElector{lock: lock, id: id}
+	ticker := time.NewTicker(RETRY_PERIOD)
+	defer ticker.Stop()
+
+	le.tryAcquireOrRenew(ctx, time.Now())
+	for {
+		select {
+		case <-ctx.Done():
+			le.release()
+			return
+		case <-ticker.C:
+			le.tryAcquireOrRenew(ctx, time.Now())
+		}
+	}
+}
+
+func (le *leaseElector) tryAcquireOrRenew(ctx context.Context, now time.Time) {
+	ctx, cancel := context.WithTimeout(ctx, RENEW_DEADLINE)
+	defer cancel()
+	holder, err := le.lock.tryAcquireOrRenew(ctx, le.id)
+	if err != nil {
+		log.Errorf("acquire or renew lease failed: %v", err)
+		// same as kubernetes, stop leading if the lease can not be renewed before deadline
+		if leaderData.GetLeader() == le.id && now.Sub(le.renewedAt) > RENEW_DEADLINE {
+			log.Infof("leader lost: %s", le.id)
+			leaderData.SetLeader("")
+		}
+		return
+	}
+
+	if holder == le.id {
+		le.renewedAt = now
+	}
+	if holder != leaderData.GetLeader() {
+		if holder == le.id {
+			log.Infof("%s is the leader", le.id)
+		} else {
+			log.Infof("new leader elected: %s", holder)
+		}
+		leaderData.SetLeader(holder)
+	}
+	leaderData.setValide()
+}
+
+func (le *leaseElector) release() {
+	if leaderData.GetLeader() != le.id {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), RENEW_DEADLINE)
+	defer cancel()
+	if err := le.lock.release(ctx, le.id); err != nil {
+		log.Errorf("release lease failed: %v", err)
+		return
+	}
+	log.Infof("leader lost: %s", le.id)
+	leaderData.SetLeader("")
+}
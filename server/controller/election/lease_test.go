@@ -0,0 +1,89 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package election
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type fakeLeaseLock struct {
+	holder string
+	err    error
+}
+
+func (l *fakeLeaseLock) tryAcquireOrRenew(ctx context.Context, id string) (string, error) {
+	if l.err != nil {
+		return "", l.err
+	}
+	if l.holder == "" {
+		l.holder = id
+	}
+	return l.holder, nil
+}
+
+func (l *fakeLeaseLock) release(ctx context.Context, id string) error {
+	if l.holder == id {
+		l.holder = ""
+	}
+	return nil
+}
+
+func TestLeaseElector(t *testing.T) {
+	id := "node/10.1.1.1/pod/10.1.1.2"
+	other := "node/10.1.1.3/pod/10.1.1.4"
+	start := time.Now()
+	tests := []struct {
+		name   string
+		holder string
+		err    error
+		now    time.Time
+		leader string
+	}{
+		{"acquire free lease", "", nil, start, id},
+		{"renew failed before deadline", id, errors.New("timeout"), start.Add(RETRY_PERIOD), id},
+		{"renew failed after deadline", id, errors.New("timeout"), start.Add(RENEW_DEADLINE + time.Second), ""},
+		{"lease taken by other", other, nil, start.Add(LEASE_DURATION), other},
+		{"observe failed", other, errors.New("timeout"), start.Add(LEASE_DURATION * 2), other},
+	}
+
+	leaderData.SetLeader("")
+	lock := &fakeLeaseLock{}
+	le := &leaseElector{lock: lock, id: id}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lock.holder = tt.holder
+			lock.err = tt.err
+			le.tryAcquireOrRenew(context.Background(), tt.now)
+			if got := GetLeader(); got != tt.leader {
+				t.Errorf("leader = %s, want %s", got, tt.leader)
+			}
+		})
+	}
+
+	lock.holder, lock.err = "", nil
+	le.tryAcquireOrRenew(context.Background(), start)
+	le.release()
+	if GetLeader() != "" || lock.holder != "" {
+		t.Errorf("lease is not released, leader: %s, holder: %s", GetLeader(), lock.holder)
+	}
+	if isMaster, _ := IsMasterController(); isMaster {
+		t.Error("should not be master controller after releasing lease")
+	}
+}
@@ -0,0 +1,122 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package election
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+
+	"github.com/deepflowio/deepflow/server/controller/db/mysql"
+	mysqlcommon "github.com/deepflowio/deepflow/server/controller/db/mysql/common"
+	mysqlcfg "github.com/deepflowio/deepflow/server/controller/db/mysql/config"
+)
+
+const (
+	// same as the table in init.sql, election starts before mysql migration, so the table is created here
+	mysqlCreateLeaseTable = `CREATE TABLE IF NOT EXISTS controller_election (
+    name            VARCHAR(64) NOT NULL PRIMARY KEY,
+    holder          VARCHAR(256) NOT NULL DEFAULT '' COMMENT 'node_name/node_ip/pod_name/pod_ip',
+    renew_time      DATETIME NOT NULL
+)ENGINE=InnoDB DEFAULT CHARSET=utf8`
+	mysqlInsertLease = "INSERT IGNORE INTO controller_election (name, holder, renew_time) VALUES (?, ?, NOW())"
+	// time of mysql is used, the clocks of controllers may be different
+	mysqlAcquireOrRenewLease = "UPDATE controller_election SET holder=?, renew_time=NOW() " +
+		"WHERE name=? AND (holder=? OR holder='' OR renew_time < DATE_SUB(NOW(), INTERVAL ? SECOND))"
+	mysqlGetLeaseHolder = "SELECT holder FROM controller_election WHERE name=?"
+	mysqlReleaseLease   = "UPDATE controller_election SET holder='' WHERE name=? AND holder=?"
+)
+
+// mysqlLeaseLock holds the lease by a row of controller_election, the row is renewed by the leader and
+// taken over by others after it expired.
+type mysqlLeaseLock struct {
+	cfg  mysqlcfg.MySqlConfig
+	name string
+	db   *gorm.DB
+}
+
+func newMySQLLeaseLock(cfg mysqlcfg.MySqlConfig, name string) *mysqlLeaseLock {
+	return &mysqlLeaseLock{cfg: cfg, name: name}
+}
+
+// the database may not exist before the first master controller migrates it
+func (l *mysqlLeaseLock) connect() error {
+	db := mysqlcommon.GetConnectionWithoutDatabase(l.cfg)
+	if db == nil {
+		return errors.New("connect mysql failed")
+	}
+	_, err := mysqlcommon.CreateDatabaseIfNotExists(db, l.cfg.Database)
+	closeGormDB(db)
+	if err != nil {
+		return err
+	}
+
+	db = mysql.Gorm(l.cfg)
+	if db == nil {
+		return errors.New("connect mysql failed")
+	}
+	if err = db.Exec(mysqlCreateLeaseTable).Error; err != nil {
+		closeGormDB(db)
+		return err
+	}
+	l.db = db
+	return nil
+}
+
+func (l *mysqlLeaseLock) tryAcquireOrRenew(ctx context.Context, id string) (string, error) {
+	if l.db == nil {
+		if err := l.connect(); err != nil {
+			return "", err
+		}
+	}
+	holder, err := l.acquireOrRenew(l.db.WithContext(ctx), id)
+	if err != nil {
+		// reconnect next time, the database may have been recreated by migration
+		closeGormDB(l.db)
+		l.db = nil
+	}
+	return holder, err
+}
+
+func (l *mysqlLeaseLock) acquireOrRenew(db *gorm.DB, id string) (string, error) {
+	if err := db.Exec(mysqlInsertLease, l.name, id).Error; err != nil {
+		return "", err
+	}
+	err := db.Exec(mysqlAcquireOrRenewLease, id, l.name, id, int(LEASE_DURATION.Seconds())).Error
+	if err != nil {
+		return "", err
+	}
+	var holder string
+	if err = db.Raw(mysqlGetLeaseHolder, l.name).Scan(&holder).Error; err != nil {
+		return "", err
+	}
+	return holder, nil
+}
+
+func (l *mysqlLeaseLock) release(ctx context.Context, id string) error {
+	if l.db == nil {
+		return errors.New("mysql is not connected")
+	}
+	return l.db.WithContext(ctx).Exec(mysqlReleaseLease, l.name, id).Error
+}
+
+func closeGormDB(db *gorm.DB) {
+	if sqlDB, err := db.DB(); err == nil {
+		sqlDB.Close()
+	}
+}
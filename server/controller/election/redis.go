@@ -0,0 +1,65 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package election
+
+import (
+	"context"
+	"fmt"
+
+	goredis "github.com/go-redis/redis/v9"
+
+	"github.com/deepflowio/deepflow/server/controller/db/redis"
+)
+
+var (
+	// KEYS[1]: lease key, ARGV[1]: id, ARGV[2]: lease duration in milliseconds
+	redisAcquireOrRenewLease = goredis.NewScript(`
+local holder = redis.call("GET", KEYS[1])
+if not holder or holder == ARGV[1] then
+	redis.call("SET", KEYS[1], ARGV[1], "PX", ARGV[2])
+	return ARGV[1]
+end
+return holder
+`)
+	redisReleaseLease = goredis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+)
+
+// redisLeaseLock holds the lease by a key expired after lease duration, the key is renewed by the leader
+type redisLeaseLock struct {
+	client goredis.UniversalClient
+	key    string
+}
+
+func newRedisLeaseLock(cfg redis.Config, name string) *redisLeaseLock {
+	return &redisLeaseLock{
+		client: redis.NewUniversalClient(cfg, cfg.ResourceAPIDatabase),
+		key:    fmt.Sprintf("election:%s", name),
+	}
+}
+
+func (l *redisLeaseLock) tryAcquireOrRenew(ctx context.Context, id string) (string, error) {
+	return redisAcquireOrRenewLease.Run(ctx, l.client, []string{l.key}, id, LEASE_DURATION.Milliseconds()).Text()
+}
+
+func (l *redisLeaseLock) release(ctx context.Context, id string) error {
+	return redisReleaseLease.Run(ctx, l.client, []string{l.key}, id).Err()
+}
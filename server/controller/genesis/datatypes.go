@@ -53,12 +53,14 @@ type PrometheusMessage struct {
 }
 
 type KubernetesInfo struct {
-	ClusterID string
-	ErrorMSG  string
-	Version   uint64
-	VtapID    uint32
-	Epoch     time.Time
-	Entries   []*messagecommon.KubernetesAPIInfo
+	ClusterID   string
+	ErrorMSG    string
+	Version     uint64
+	VtapID      uint32
+	Epoch       time.Time
+	Incremental bool
+	BaseVersion uint64
+	Entries     []*messagecommon.KubernetesAPIInfo
 }
 
 type PrometheusInfo struct {
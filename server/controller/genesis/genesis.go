@@ -433,6 +433,12 @@ func (g *Genesis) GetKubernetesData() map[string]KubernetesInfo {
 }
 
 func (g *Genesis) GetKubernetesResponse(clusterID string) (map[string][]string, error) {
+	k8sResp, _, err := g.GetKubernetesResponseWithVersion(clusterID, 0)
+	return k8sResp, err
+}
+
+// 同时返回数据的version，version与lastVersion相同说明数据未变化，此时不再解压数据，返回空结果
+func (g *Genesis) GetKubernetesResponseWithVersion(clusterID string, lastVersion uint64) (map[string][]string, uint64, error) {
 	k8sResp := map[string][]string{}
 
 	localK8sDatas := g.GetKubernetesData()
@@ -440,7 +446,7 @@ func (g *Genesis) GetKubernetesResponse(clusterID string) (map[string][]string,
 
 	serverIPs, err := g.GetServerIPs()
 	if err != nil {
-		return k8sResp, err
+		return k8sResp, 0, err
 	}
 	retFlag := false
 	for _, serverIP := range serverIPs {
@@ -449,7 +455,7 @@ func (g *Genesis) GetKubernetesResponse(clusterID string) (map[string][]string,
 		if err != nil {
 			msg := "create grpc connection faild:" + err.Error()
 			log.Error(msg)
-			return k8sResp, errors.New(msg)
+			return k8sResp, 0, errors.New(msg)
 		}
 		defer conn.Close()
 
@@ -461,7 +467,7 @@ func (g *Genesis) GetKubernetesResponse(clusterID string) (map[string][]string,
 		if err != nil {
 			msg := fmt.Sprintf("get (%s) genesis sharing k8s failed (%s) ", serverIP, err.Error())
 			log.Error(msg)
-			return k8sResp, errors.New(msg)
+			return k8sResp, 0, errors.New(msg)
 		}
 		entries := ret.GetEntries()
 		if len(entries) == 0 {
@@ -472,7 +478,7 @@ func (g *Genesis) GetKubernetesResponse(clusterID string) (map[string][]string,
 		epoch, err := time.ParseInLocation(common.GO_BIRTHDAY, epochStr, time.Local)
 		if err != nil {
 			log.Error("genesis api sharing k8s format timestr faild:" + err.Error())
-			return k8sResp, err
+			return k8sResp, 0, err
 		}
 		if !epoch.After(k8sInfo.Epoch) {
 			continue
@@ -483,14 +489,15 @@ func (g *Genesis) GetKubernetesResponse(clusterID string) (map[string][]string,
 			Epoch:    epoch,
 			Entries:  entries,
 			ErrorMSG: ret.GetErrorMsg(),
+			Version:  ret.GetVersion(),
 		}
 	}
 	if !ok && !retFlag {
-		return k8sResp, errors.New("no vtap k8s report cluster id:" + clusterID)
+		return k8sResp, 0, errors.New("no vtap k8s report cluster id:" + clusterID)
 	}
 	if k8sInfo.ErrorMSG != "" {
 		log.Errorf("cluster id (%s) k8s info grpc Error: %s", clusterID, k8sInfo.ErrorMSG)
-		return k8sResp, errors.New(k8sInfo.ErrorMSG)
+		return k8sResp, 0, errors.New(k8sInfo.ErrorMSG)
 	}
 
 	g.mutex.Lock()
@@ -499,12 +506,15 @@ func (g *Genesis) GetKubernetesResponse(clusterID string) (map[string][]string,
 	statsd.MetaStatsd.RegisterStatsdTable(g)
 	g.mutex.Unlock()
 
+	if lastVersion != 0 && k8sInfo.Version == lastVersion {
+		return k8sResp, k8sInfo.Version, nil
+	}
 	for _, e := range k8sInfo.Entries {
 		eType := e.GetType()
 		out, err := genesiscommon.ParseCompressedInfo(e.GetCompressedInfo())
 		if err != nil {
 			log.Warningf("decode decompress error: %s", err.Error())
-			return k8sResp, err
+			return k8sResp, 0, err
 		}
 		if _, ok := k8sResp[eType]; ok {
 			k8sResp[eType] = append(k8sResp[eType], string(out.Bytes()))
@@ -512,7 +522,7 @@ func (g *Genesis) GetKubernetesResponse(clusterID string) (map[string][]string,
 			k8sResp[eType] = []string{string(out.Bytes())}
 		}
 	}
-	return k8sResp, nil
+	return k8sResp, k8sInfo.Version, nil
 }
 
 func (g *Genesis) receivePrometheusData(pChan chan map[string]PrometheusInfo) {
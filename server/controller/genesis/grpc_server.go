@@ -242,6 +242,7 @@ func (g *SynchronizerServer) KubernetesAPISync(ctx context.Context, request *tri
 	stats.K8sVersion = version
 	g.tridentStatsMap.Store(vtapID, stats)
 	now := time.Now()
+	incrementalSupported := true
 	if vtapID != 0 {
 		if lastTime, ok := g.clusterIDToLastSeen.Load(clusterID); ok {
 			if now.Sub(lastTime.(time.Time)).Seconds() >= g.cfg.AgingTime {
@@ -253,11 +254,17 @@ func (g *SynchronizerServer) KubernetesAPISync(ctx context.Context, request *tri
 		if ok {
 			localVersion = lVersion.(uint64)
 		}
-		log.Infof("kubernetes api sync received version %v -> %v from ip %s vtap_id %v len %v", localVersion, version, remote, vtapID, len(entries))
+		log.Infof("kubernetes api sync received version %v -> %v from ip %s vtap_id %v len %v incremental %v", localVersion, version, remote, vtapID, len(entries), request.GetIncremental())
 
-		// 如果version有更新，但消息中没有任何kubernetes数据，触发trident重新上报数据
-		if localVersion != version && len(entries) == 0 {
-			return &trident.KubernetesAPISyncResponse{Version: &localVersion}, nil
+		if request.GetIncremental() {
+			// 增量数据所基于的version与本地不一致，触发trident重新上报全量数据
+			if localVersion != request.GetBaseVersion() {
+				log.Infof("kubernetes api sync ignore incremental message based on version %v from ip %s vtap_id %v", request.GetBaseVersion(), remote, vtapID)
+				return &trident.KubernetesAPISyncResponse{Version: &localVersion, IncrementalSupported: &incrementalSupported}, nil
+			}
+		} else if localVersion != version && len(entries) == 0 {
+			// 如果version有更新，但消息中没有任何kubernetes数据，触发trident重新上报数据
+			return &trident.KubernetesAPISyncResponse{Version: &localVersion, IncrementalSupported: &incrementalSupported}, nil
 		}
 
 		// 正常推送消息到队列中
@@ -271,11 +278,11 @@ func (g *SynchronizerServer) KubernetesAPISync(ctx context.Context, request *tri
 		// 更新内存中的last_seen和version
 		g.clusterIDToLastSeen.Store(clusterID, now)
 		g.clusterIDToVersion.Store(clusterID, version)
-		return &trident.KubernetesAPISyncResponse{Version: &version}, nil
+		return &trident.KubernetesAPISyncResponse{Version: &version, IncrementalSupported: &incrementalSupported}, nil
 	} else {
 		log.Infof("kubernetes api sync received version %v from ip %s no vtap_id", version, remote)
-		//正常上报数据，才推送消息到队列中
-		if len(entries) > 0 {
+		//正常上报数据，才推送消息到队列中，增量数据无法校验所基于的version，忽略
+		if len(entries) > 0 && !request.GetIncremental() {
 			g.k8sQueue.Put(K8SRPCMessage{
 				peer:    remote,
 				vtapID:  vtapID,
@@ -383,6 +390,7 @@ func (g *SynchronizerServer) GenesisSharingK8S(ctx context.Context, request *con
 			Epoch:    &epochStr,
 			ErrorMsg: &k8sData.ErrorMSG,
 			Entries:  k8sData.Entries,
+			Version:  &k8sData.Version,
 		}, nil
 	}
 
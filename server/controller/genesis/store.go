@@ -19,9 +19,11 @@ package genesis
 import (
 	"context"
 	"os"
+	"strconv"
 	"sync"
 	"time"
 
+	messagecommon "github.com/deepflowio/deepflow/message/common"
 	"github.com/deepflowio/deepflow/server/controller/common"
 	"github.com/deepflowio/deepflow/server/controller/db/mysql"
 	"github.com/deepflowio/deepflow/server/controller/genesis/config"
@@ -301,24 +303,32 @@ func (s *SyncStorage) Stop() {
 	}
 }
 
+type kubernetesEntryKey struct {
+	eType string
+	uid   string
+}
+
 type KubernetesStorage struct {
 	cfg            config.GenesisConfig
 	kCtx           context.Context
 	kCancel        context.CancelFunc
 	channel        chan map[string]KubernetesInfo
 	kubernetesData map[string]KubernetesInfo
-	mutex          sync.Mutex
+	// 按类型和uid索引的各集群数据，用于应用增量数据
+	kubernetesEntries map[string]map[kubernetesEntryKey]*messagecommon.KubernetesAPIInfo
+	mutex             sync.Mutex
 }
 
 func NewKubernetesStorage(cfg config.GenesisConfig, kChan chan map[string]KubernetesInfo, ctx context.Context) *KubernetesStorage {
 	kCtx, kCancel := context.WithCancel(ctx)
 	return &KubernetesStorage{
-		cfg:            cfg,
-		kCtx:           kCtx,
-		kCancel:        kCancel,
-		channel:        kChan,
-		kubernetesData: map[string]KubernetesInfo{},
-		mutex:          sync.Mutex{},
+		cfg:               cfg,
+		kCtx:              kCtx,
+		kCancel:           kCancel,
+		channel:           kChan,
+		kubernetesData:    map[string]KubernetesInfo{},
+		kubernetesEntries: map[string]map[kubernetesEntryKey]*messagecommon.KubernetesAPIInfo{},
+		mutex:             sync.Mutex{},
 	}
 }
 
@@ -327,17 +337,29 @@ func (k *KubernetesStorage) Clear() {
 	defer k.mutex.Unlock()
 
 	k.kubernetesData = map[string]KubernetesInfo{}
+	k.kubernetesEntries = map[string]map[kubernetesEntryKey]*messagecommon.KubernetesAPIInfo{}
 }
 
 func (k *KubernetesStorage) Add(k8sInfo KubernetesInfo) {
 	k.mutex.Lock()
 	kInfo, ok := k.kubernetesData[k8sInfo.ClusterID]
-	// 上报消息中version未变化时，只更新epoch和error_msg
-	if ok && kInfo.Version == k8sInfo.Version {
+	if k8sInfo.Incremental {
+		// 增量数据只能应用在其所基于的版本上
+		if !ok || kInfo.Version != k8sInfo.BaseVersion {
+			log.Warningf("kubernetes storage ignore incremental data of cluster id (%s) based on version %d, local version %d", k8sInfo.ClusterID, k8sInfo.BaseVersion, kInfo.Version)
+			k.mutex.Unlock()
+			return
+		}
+		k8sInfo.Entries = k.applyEntries(k8sInfo.ClusterID, k8sInfo.Entries)
+		k.kubernetesData[k8sInfo.ClusterID] = k8sInfo
+	} else if ok && kInfo.Version == k8sInfo.Version {
+		// 上报消息中version未变化时，只更新epoch和error_msg
 		kInfo.Epoch = time.Now()
 		kInfo.ErrorMSG = k8sInfo.ErrorMSG
 		k.kubernetesData[k8sInfo.ClusterID] = kInfo
 	} else {
+		delete(k.kubernetesEntries, k8sInfo.ClusterID)
+		k8sInfo.Entries = k.applyEntries(k8sInfo.ClusterID, k8sInfo.Entries)
 		k.kubernetesData[k8sInfo.ClusterID] = k8sInfo
 	}
 	k.mutex.Unlock()
@@ -345,6 +367,35 @@ func (k *KubernetesStorage) Add(k8sInfo KubernetesInfo) {
 	k.channel <- k.fetch()
 }
 
+// 将上报的数据合并到集群已有数据中，返回合并后的全部数据
+func (k *KubernetesStorage) applyEntries(clusterID string, entries []*messagecommon.KubernetesAPIInfo) []*messagecommon.KubernetesAPIInfo {
+	clusterEntries, ok := k.kubernetesEntries[clusterID]
+	if !ok {
+		clusterEntries = map[kubernetesEntryKey]*messagecommon.KubernetesAPIInfo{}
+		k.kubernetesEntries[clusterID] = clusterEntries
+	}
+	noUIDCount := 0
+	for _, e := range entries {
+		key := kubernetesEntryKey{eType: e.GetType(), uid: e.GetUid()}
+		// 全量数据中没有uid的数据（如apiserver版本信息、旧版本采集器上报的数据）按顺序索引
+		if key.uid == "" {
+			key.uid = strconv.Itoa(noUIDCount)
+			noUIDCount++
+		}
+		if e.GetWatchType() == messagecommon.KubernetesWatchType_KW_DELETED {
+			delete(clusterEntries, key)
+			continue
+		}
+		clusterEntries[key] = e
+	}
+
+	result := make([]*messagecommon.KubernetesAPIInfo, 0, len(clusterEntries))
+	for _, e := range clusterEntries {
+		result = append(result, e)
+	}
+	return result
+}
+
 func (k *KubernetesStorage) fetch() map[string]KubernetesInfo {
 	return k.kubernetesData
 }
@@ -359,6 +410,7 @@ func (k *KubernetesStorage) run() {
 				continue
 			}
 			delete(k.kubernetesData, key)
+			delete(k.kubernetesEntries, key)
 		}
 		k.mutex.Unlock()
 
@@ -0,0 +1,108 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package genesis
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	messagecommon "github.com/deepflowio/deepflow/message/common"
+	"github.com/deepflowio/deepflow/server/controller/genesis/config"
+)
+
+func newTestKubernetesAPIInfo(eType, uid, info string, watchType messagecommon.KubernetesWatchType) *messagecommon.KubernetesAPIInfo {
+	return &messagecommon.KubernetesAPIInfo{
+		Type:      &eType,
+		Uid:       &uid,
+		Info:      &info,
+		WatchType: &watchType,
+	}
+}
+
+func kubernetesInfoEntries(k8sInfo KubernetesInfo) []string {
+	result := []string{}
+	for _, e := range k8sInfo.Entries {
+		result = append(result, e.GetType()+"/"+e.GetUid()+":"+e.GetInfo())
+	}
+	sort.Strings(result)
+	return result
+}
+
+func TestKubernetesStorageAdd(t *testing.T) {
+	kChan := make(chan map[string]KubernetesInfo, 10)
+	storage := NewKubernetesStorage(config.GenesisConfig{}, kChan, context.Background())
+
+	storage.Add(KubernetesInfo{
+		ClusterID: "cluster",
+		Version:   1,
+		Entries: []*messagecommon.KubernetesAPIInfo{
+			newTestKubernetesAPIInfo("*v1.Pod", "pod-1", "a", messagecommon.KubernetesWatchType_KW_ADDED),
+			newTestKubernetesAPIInfo("*v1.Pod", "pod-2", "b", messagecommon.KubernetesWatchType_KW_ADDED),
+		},
+	})
+	storage.Add(KubernetesInfo{
+		ClusterID:   "cluster",
+		Version:     2,
+		Incremental: true,
+		BaseVersion: 1,
+		Entries: []*messagecommon.KubernetesAPIInfo{
+			newTestKubernetesAPIInfo("*v1.Pod", "pod-1", "c", messagecommon.KubernetesWatchType_KW_MODIFIED),
+			newTestKubernetesAPIInfo("*v1.Pod", "pod-2", "", messagecommon.KubernetesWatchType_KW_DELETED),
+			newTestKubernetesAPIInfo("*v1.Node", "node-1", "d", messagecommon.KubernetesWatchType_KW_ADDED),
+		},
+	})
+	// based on a version which does not exist, ignored
+	storage.Add(KubernetesInfo{
+		ClusterID:   "cluster",
+		Version:     4,
+		Incremental: true,
+		BaseVersion: 3,
+		Entries: []*messagecommon.KubernetesAPIInfo{
+			newTestKubernetesAPIInfo("*v1.Pod", "pod-1", "", messagecommon.KubernetesWatchType_KW_DELETED),
+		},
+	})
+
+	k8sInfo := storage.fetch()["cluster"]
+	if k8sInfo.Version != 2 {
+		t.Errorf("expected version 2, got %d", k8sInfo.Version)
+	}
+	expected := []string{"*v1.Node/node-1:d", "*v1.Pod/pod-1:c"}
+	entries := kubernetesInfoEntries(k8sInfo)
+	if len(entries) != len(expected) {
+		t.Fatalf("expected entries %v, got %v", expected, entries)
+	}
+	for i := range expected {
+		if entries[i] != expected[i] {
+			t.Errorf("expected entries %v, got %v", expected, entries)
+			break
+		}
+	}
+
+	// full data replaces all entries
+	storage.Add(KubernetesInfo{
+		ClusterID: "cluster",
+		Version:   5,
+		Entries: []*messagecommon.KubernetesAPIInfo{
+			newTestKubernetesAPIInfo("*v1.Pod", "pod-3", "e", messagecommon.KubernetesWatchType_KW_ADDED),
+		},
+	})
+	entries = kubernetesInfoEntries(storage.fetch()["cluster"])
+	if len(entries) != 1 || entries[0] != "*v1.Pod/pod-3:e" {
+		t.Errorf("expected entries [*v1.Pod/pod-3:e], got %v", entries)
+	}
+}
@@ -916,12 +916,14 @@ func (k *KubernetesRpcUpdater) run() {
 		log.Debugf("k8s from %s vtap_id %v received cluster_id %s version %s", info.peer, info.vtapID, info.message.GetClusterId(), info.message.GetVersion())
 		// 更新和保存内存数据
 		k8sInfo := KubernetesInfo{
-			Epoch:     time.Now(),
-			ClusterID: info.message.GetClusterId(),
-			ErrorMSG:  info.message.GetErrorMsg(),
-			VtapID:    info.message.GetVtapId(),
-			Version:   info.message.GetVersion(),
-			Entries:   info.message.GetEntries(),
+			Epoch:       time.Now(),
+			ClusterID:   info.message.GetClusterId(),
+			ErrorMSG:    info.message.GetErrorMsg(),
+			VtapID:      info.message.GetVtapId(),
+			Version:     info.message.GetVersion(),
+			Incremental: info.message.GetIncremental(),
+			BaseVersion: info.message.GetBaseVersion(),
+			Entries:     info.message.GetEntries(),
 		}
 		k.storage.Add(k8sInfo)
 	}
@@ -0,0 +1,361 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	logging "github.com/op/go-logging"
+
+	"github.com/deepflowio/deepflow/server/controller/common"
+	"github.com/deepflowio/deepflow/server/controller/db/mysql"
+	"github.com/deepflowio/deepflow/server/controller/http/auth"
+	httpcommon "github.com/deepflowio/deepflow/server/controller/http/common"
+	routercommon "github.com/deepflowio/deepflow/server/controller/http/router/common"
+)
+
+var log = logging.MustGetLogger("audit")
+
+const (
+	RESULT_SUCCESS = "SUCCESS"
+	RESULT_FAIL    = "FAIL"
+
+	MAX_BODY_LENGTH = 16384
+	MASK            = "******"
+)
+
+type resourceTable struct {
+	prefix string
+	table  string
+}
+
+// routes whose lcuuid parameter identifies a row of the table, the row is loaded
+// before and after the request to record the changed fields
+var resourceTables = []resourceTable{
+	{"/v1/vtap-group-configuration/", "vtap_group_configuration"},
+	{"/v1/vtap-groups/", "vtap_group"},
+	{"/v1/vtaps/", "vtap"},
+	{"/v1/vtaps-license-type/", "vtap"},
+	{"/v1/domains/", "domain"},
+	{"/v2/sub-domains/", "sub_domain"},
+	{"/v1/controllers/", "controller"},
+	{"/v1/analyzers/", "analyzer"},
+	{"/v1/data-sources/", "data_source"},
+	{"/v1/mail-server/", "mail_server"},
+	{"/v1/api-keys/", "api_key"},
+	{"/v1/vtap-enrollment-tokens/", "vtap_enrollment_token"},
+	{"/v1/vtap-registrations/", "vtap"},
+	{"/v1/vtap-group-rules/", "vtap_group_rule"},
+	{"/v1/resource-groups/", "resource_group"},
+	{"/v1/acls/", "acl"},
+	{"/v1/npb-tunnels/", "npb_tunnel"},
+	{"/v1/npb-policies/", "npb_policy"},
+	{"/v1/pcap-policies/", "pcap_policy"},
+}
+
+// keys containing these words are masked in request bodies and diffs
+var sensitiveKeyWords = []string{"password", "secret", "token", "key_hash"}
+
+var ignoredDiffKeys = map[string]bool{"updated_at": true}
+
+type FieldDiff struct {
+	Old interface{} `json:"old"`
+	New interface{} `json:"new"`
+}
+
+type bodyWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *bodyWriter) Write(b []byte) (int, error) {
+	if w.body.Len() < MAX_BODY_LENGTH {
+		w.body.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *bodyWriter) WriteString(s string) (int, error) {
+	if w.body.Len() < MAX_BODY_LENGTH {
+		w.body.WriteString(s)
+	}
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Middleware records who changed what for every mutating request, it must be
+// registered after the auth middleware to get the identity of the caller.
+func Middleware(cfg *Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !cfg.Enabled || !auth.IsMutatingMethod(c.Request.Method) {
+			c.Next()
+			return
+		}
+		identity := auth.GetIdentity(c)
+		// requests forwarded between controllers have been recorded by the first one
+		if identity != nil && identity.Method == auth.AUTH_METHOD_INTERNAL {
+			c.Next()
+			return
+		}
+
+		var body []byte
+		if c.Request.Body != nil {
+			var err error
+			body, err = io.ReadAll(c.Request.Body)
+			if err != nil {
+				log.Warningf("read request body of %s %s failed: %s", c.Request.Method, c.Request.URL.Path, err)
+			}
+			c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		}
+
+		auditLog := &mysql.AuditLog{
+			Method:         c.Request.Method,
+			Route:          c.FullPath(),
+			Path:           c.Request.URL.Path,
+			ClientIP:       c.ClientIP(),
+			ResourceLcuuid: c.Param("lcuuid"),
+			RequestBody:    truncate(string(MaskBody(body))),
+		}
+		if auditLog.ResourceLcuuid == "" {
+			auditLog.ResourceLcuuid = c.Param("name")
+		}
+		auditLog.Actor, auditLog.AuthMethod = getActor(c, identity)
+		auditLog.OrgID, _ = routercommon.GetOrgID(c)
+		if auditLog.OrgID == 0 {
+			auditLog.OrgID = common.DEFAULT_ORG_ID
+		}
+
+		table := getResourceTable(c.Request.URL.Path)
+		var before map[string]interface{}
+		if table != "" && c.Param("lcuuid") != "" {
+			auditLog.ResourceType = table
+			before = loadResource(table, c.Param("lcuuid"))
+		}
+
+		writer := &bodyWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		c.Next()
+
+		auditLog.StatusCode = writer.Status()
+		var resp routercommon.Response
+		json.Unmarshal(writer.body.Bytes(), &resp)
+		if auditLog.StatusCode < 400 && (resp.OptStatus == "" || resp.OptStatus == httpcommon.SUCCESS) {
+			auditLog.Result = RESULT_SUCCESS
+		} else {
+			auditLog.Result = RESULT_FAIL
+			auditLog.Description = truncate(resp.Description)
+		}
+		// a created resource is identified by the lcuuid in the response
+		if auditLog.ResourceLcuuid == "" {
+			if data, ok := resp.Data.(map[string]interface{}); ok {
+				if lcuuid, ok := data["LCUUID"].(string); ok {
+					auditLog.ResourceLcuuid = lcuuid
+				}
+			}
+		}
+		if before != nil {
+			after := loadResource(table, c.Param("lcuuid"))
+			if diff := Diff(before, after); len(diff) > 0 {
+				if b, err := json.Marshal(diff); err == nil {
+					auditLog.Diff = truncate(string(b))
+				}
+			}
+		}
+
+		if err := mysql.Db.Create(auditLog).Error; err != nil {
+			log.Errorf("save audit log (%s %s by %s) failed: %s", auditLog.Method, auditLog.Path, auditLog.Actor, err)
+		}
+	}
+}
+
+func getActor(c *gin.Context, identity *auth.Identity) (string, string) {
+	if identity != nil {
+		return identity.Name, identity.Method
+	}
+	// requests from deepflow web carry the user in headers
+	userID := c.GetHeader(httpcommon.HEADER_KEY_X_USER_ID)
+	if userID != "" {
+		return fmt.Sprintf("user(type: %s, id: %s)", c.GetHeader(httpcommon.HEADER_KEY_X_USER_TYPE), userID), ""
+	}
+	return "anonymous", ""
+}
+
+func getResourceTable(path string) string {
+	for _, r := range resourceTables {
+		if strings.HasPrefix(path, r.prefix) {
+			return r.table
+		}
+	}
+	return ""
+}
+
+func loadResource(table, lcuuid string) map[string]interface{} {
+	var rows []map[string]interface{}
+	if err := mysql.Db.Table(table).Where("lcuuid = ?", lcuuid).Limit(1).Find(&rows).Error; err != nil {
+		log.Warningf("load %s (%s) failed: %s", table, lcuuid, err)
+		return nil
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	row := rows[0]
+	// soft deleted rows are regarded as not existing
+	if deletedAt, ok := row["deleted_at"]; ok && deletedAt != nil {
+		return nil
+	}
+	for k, v := range row {
+		if b, ok := v.([]byte); ok {
+			row[k] = string(b)
+		}
+	}
+	return row
+}
+
+// Diff returns the changed fields between the states of a resource, after is
+// nil if the resource is deleted. Fields stored as json objects are compared
+// field by field and reported as 'column.field'.
+func Diff(before, after map[string]interface{}) map[string]FieldDiff {
+	diff := make(map[string]FieldDiff)
+	diffInto(diff, "", before, after)
+	return diff
+}
+
+func diffInto(diff map[string]FieldDiff, prefix string, before, after map[string]interface{}) {
+	keys := make(map[string]bool)
+	for k := range before {
+		keys[k] = true
+	}
+	for k := range after {
+		keys[k] = true
+	}
+	for k := range keys {
+		if ignoredDiffKeys[k] {
+			continue
+		}
+		oldValue, newValue := before[k], after[k]
+		if fmt.Sprint(oldValue) == fmt.Sprint(newValue) {
+			continue
+		}
+		oldObject, oldOK := toObject(oldValue)
+		newObject, newOK := toObject(newValue)
+		if oldOK && newOK {
+			diffInto(diff, prefix+k+".", oldObject, newObject)
+			continue
+		}
+		if isSensitiveKey(k) {
+			diff[prefix+k] = FieldDiff{Old: maskValue(oldValue), New: maskValue(newValue)}
+		} else {
+			diff[prefix+k] = FieldDiff{Old: Mask(oldValue), New: Mask(newValue)}
+		}
+	}
+}
+
+func toObject(value interface{}) (map[string]interface{}, bool) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return v, true
+	case string:
+		if !strings.HasPrefix(strings.TrimSpace(v), "{") {
+			return nil, false
+		}
+		var object map[string]interface{}
+		if err := json.Unmarshal([]byte(v), &object); err != nil {
+			return nil, false
+		}
+		return object, true
+	}
+	return nil, false
+}
+
+func isSensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, word := range sensitiveKeyWords {
+		if strings.Contains(key, word) {
+			return true
+		}
+	}
+	return false
+}
+
+func maskValue(value interface{}) interface{} {
+	if value == nil || value == "" {
+		return value
+	}
+	return MASK
+}
+
+// Mask replaces the values of sensitive keys in objects and json strings.
+func Mask(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		masked := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			if isSensitiveKey(key) {
+				masked[key] = maskValue(value)
+			} else {
+				masked[key] = Mask(value)
+			}
+		}
+		return masked
+	case []interface{}:
+		masked := make([]interface{}, len(v))
+		for i := range v {
+			masked[i] = Mask(v[i])
+		}
+		return masked
+	case string:
+		if object, ok := toObject(v); ok {
+			if b, err := json.Marshal(Mask(object)); err == nil {
+				return string(b)
+			}
+		}
+	}
+	return value
+}
+
+// MaskBody masks a json request body, other bodies such as uploaded files are
+// not recorded.
+func MaskBody(body []byte) []byte {
+	if len(body) == 0 {
+		return nil
+	}
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		return nil
+	}
+	masked, err := json.Marshal(Mask(value))
+	if err != nil {
+		return nil
+	}
+	return masked
+}
+
+func truncate(s string) string {
+	if len(s) <= MAX_BODY_LENGTH {
+		return s
+	}
+	end := MAX_BODY_LENGTH
+	for end > 0 && !utf8.RuneStart(s[end]) {
+		end--
+	}
+	return s[:end]
+}
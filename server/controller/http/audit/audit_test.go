@@ -0,0 +1,76 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package audit
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	before := map[string]interface{}{
+		"name":       "domain-1",
+		"state":      int64(1),
+		"config":     `{"region_uuid": "r1", "secret_key": "old", "port": 443}`,
+		"updated_at": "2023-01-01 00:00:00",
+	}
+	after := map[string]interface{}{
+		"name":       "domain-1",
+		"state":      int64(2),
+		"config":     `{"region_uuid": "r2", "secret_key": "new", "port": 443}`,
+		"updated_at": "2023-01-02 00:00:00",
+	}
+	diff := Diff(before, after)
+	want := map[string]FieldDiff{
+		"state":              {Old: int64(1), New: int64(2)},
+		"config.region_uuid": {Old: "r1", New: "r2"},
+		"config.secret_key":  {Old: MASK, New: MASK},
+	}
+	if len(diff) != len(want) {
+		t.Fatalf("Diff() = %v, want %v", diff, want)
+	}
+	for k, v := range want {
+		if diff[k] != v {
+			t.Errorf("Diff()[%s] = %v, want %v", k, diff[k], v)
+		}
+	}
+
+	deleted := Diff(before, nil)
+	if deleted["config"].New != nil || deleted["name"].Old != "domain-1" {
+		t.Errorf("Diff(before, nil) = %v", deleted)
+	}
+	var config map[string]interface{}
+	json.Unmarshal([]byte(deleted["config"].Old.(string)), &config)
+	if config["secret_key"] != MASK {
+		t.Errorf("secret_key of deleted config is not masked: %v", config)
+	}
+}
+
+func TestMaskBody(t *testing.T) {
+	body := MaskBody([]byte(`{"NAME": "aliyun", "CONFIG": {"secret_id": "id", "SECRET_KEY": "key", "PASSWORD": ""}}`))
+	var object map[string]interface{}
+	if err := json.Unmarshal(body, &object); err != nil {
+		t.Fatal(err)
+	}
+	config := object["CONFIG"].(map[string]interface{})
+	if config["SECRET_KEY"] != MASK || config["secret_id"] != MASK || config["PASSWORD"] != "" {
+		t.Errorf("MaskBody() = %s", body)
+	}
+	if MaskBody([]byte("not json")) != nil {
+		t.Errorf("non json body should not be recorded")
+	}
+}
@@ -0,0 +1,81 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package audit
+
+import (
+	"context"
+	"time"
+
+	"github.com/deepflowio/deepflow/server/controller/common"
+	"github.com/deepflowio/deepflow/server/controller/db/mysql"
+)
+
+const CLEAN_INTERVAL = time.Hour
+
+// Cleaner deletes the audit logs older than the retention days, it runs on the
+// master controller only.
+type Cleaner struct {
+	cfg       *Config
+	parentCtx context.Context
+	ctx       context.Context
+	cancel    context.CancelFunc
+}
+
+func NewCleaner(cfg *Config, ctx context.Context) *Cleaner {
+	return &Cleaner{cfg: cfg, parentCtx: ctx}
+}
+
+func (c *Cleaner) Start() {
+	if !c.cfg.Enabled || c.cfg.RetentionDays <= 0 {
+		return
+	}
+	c.ctx, c.cancel = context.WithCancel(c.parentCtx)
+	log.Info("audit log cleaner started")
+	go func(ctx context.Context) {
+		c.clean()
+		ticker := time.NewTicker(CLEAN_INTERVAL)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				c.clean()
+			}
+		}
+	}(c.ctx)
+}
+
+func (c *Cleaner) Stop() {
+	if c.cancel != nil {
+		c.cancel()
+		c.cancel = nil
+		log.Info("audit log cleaner stopped")
+	}
+}
+
+func (c *Cleaner) clean() {
+	expiredAt := time.Now().AddDate(0, 0, -c.cfg.RetentionDays)
+	result := mysql.Db.Where("created_at < ?", expiredAt).Delete(&mysql.AuditLog{})
+	if result.Error != nil {
+		log.Errorf("delete audit logs before %s failed: %s", expiredAt.Format(common.GO_BIRTHDAY), result.Error)
+		return
+	}
+	if result.RowsAffected > 0 {
+		log.Infof("deleted %d audit logs before %s", result.RowsAffected, expiredAt.Format(common.GO_BIRTHDAY))
+	}
+}
@@ -0,0 +1,271 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/op/go-logging"
+
+	"github.com/deepflowio/deepflow/server/controller/common"
+	"github.com/deepflowio/deepflow/server/controller/db/mysql"
+	httpcommon "github.com/deepflowio/deepflow/server/controller/http/common"
+)
+
+var log = logging.MustGetLogger("http.auth")
+
+const (
+	HEADER_KEY_AUTHORIZATION    = "Authorization"
+	HEADER_KEY_X_API_KEY        = "X-Api-Key"
+	HEADER_KEY_X_INTERNAL_TOKEN = "X-Internal-Token"
+
+	CONTEXT_KEY_IDENTITY = "auth.identity"
+
+	API_KEY_PREFIX        = "dfk_"
+	API_KEY_DISPLAY_LEN   = 8
+	API_KEY_CACHE_TIMEOUT = time.Minute // deleted or expired keys take effect at most after the timeout
+)
+
+const (
+	AUTH_METHOD_API_KEY  = "api-key"
+	AUTH_METHOD_JWT      = "jwt"
+	AUTH_METHOD_INTERNAL = "internal"
+)
+
+type Identity struct {
+	Name   string
+	Role   Role
+	OrgID  int
+	Method string
+}
+
+func (i *Identity) String() string {
+	return fmt.Sprintf("%s(%s, %s)", i.Name, i.Method, i.Role)
+}
+
+// GetOrgID returns the org of the caller, callers without org belong to the default org.
+func (i *Identity) GetOrgID() int {
+	if i.OrgID == 0 {
+		return common.DEFAULT_ORG_ID
+	}
+	return i.OrgID
+}
+
+// IsDefaultOrgAdmin returns true if the caller is allowed to access all orgs.
+func (i *Identity) IsDefaultOrgAdmin() bool {
+	return i.GetOrgID() == common.DEFAULT_ORG_ID && i.Role == ROLE_ADMIN
+}
+
+// GetIdentity returns the authenticated caller of the request, nil if authentication is disabled.
+func GetIdentity(c *gin.Context) *Identity {
+	if value, ok := c.Get(CONTEXT_KEY_IDENTITY); ok {
+		return value.(*Identity)
+	}
+	return nil
+}
+
+// GenerateAPIKey returns a new key and its sha256, only the sha256 is stored.
+func GenerateAPIKey() (string, string, error) {
+	b := make([]byte, 20)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	key := API_KEY_PREFIX + hex.EncodeToString(b)
+	return key, HashAPIKey(key), nil
+}
+
+func HashAPIKey(key string) string {
+	return common.HashToken(key)
+}
+
+type cachedIdentity struct {
+	identity  *Identity
+	expiredAt time.Time
+}
+
+type Authenticator struct {
+	cfg          *Config
+	jwtValidator *JWTValidator
+	apiKeyCache  sync.Map // key hash -> *cachedIdentity
+}
+
+func NewAuthenticator(cfg *Config) *Authenticator {
+	return &Authenticator{cfg: cfg, jwtValidator: NewJWTValidator(&cfg.JWT)}
+}
+
+func (a *Authenticator) Authenticate(req *http.Request) (*Identity, error) {
+	if token := req.Header.Get(HEADER_KEY_X_INTERNAL_TOKEN); token != "" {
+		if a.cfg.InternalToken == "" || subtle.ConstantTimeCompare([]byte(token), []byte(a.cfg.InternalToken)) != 1 {
+			return nil, errors.New("invalid internal token")
+		}
+		return &Identity{Name: "deepflow-server", Role: ROLE_ADMIN, Method: AUTH_METHOD_INTERNAL}, nil
+	}
+	if key := req.Header.Get(HEADER_KEY_X_API_KEY); key != "" {
+		return a.authenticateAPIKey(key)
+	}
+	if authorization := req.Header.Get(HEADER_KEY_AUTHORIZATION); authorization != "" {
+		token := strings.TrimSpace(strings.TrimPrefix(authorization, "Bearer "))
+		if strings.HasPrefix(token, API_KEY_PREFIX) {
+			return a.authenticateAPIKey(token)
+		}
+		return a.authenticateJWT(token)
+	}
+	return nil, errors.New("credentials required")
+}
+
+func (a *Authenticator) authenticateAPIKey(key string) (*Identity, error) {
+	hash := HashAPIKey(key)
+	if value, ok := a.apiKeyCache.Load(hash); ok {
+		cached := value.(*cachedIdentity)
+		if time.Now().Before(cached.expiredAt) {
+			return cached.identity, nil
+		}
+		a.apiKeyCache.Delete(hash)
+	}
+
+	if mysql.Db == nil {
+		return nil, errors.New("api key storage is not ready")
+	}
+	var apiKey mysql.APIKey
+	if err := mysql.Db.Where("key_hash = ?", hash).First(&apiKey).Error; err != nil {
+		return nil, errors.New("invalid api key")
+	}
+	if apiKey.ExpiredAt != nil && time.Now().After(*apiKey.ExpiredAt) {
+		return nil, fmt.Errorf("api key %s is expired", apiKey.Name)
+	}
+	role, ok := ParseRole(apiKey.Role)
+	if !ok {
+		return nil, fmt.Errorf("api key %s has invalid role %s", apiKey.Name, apiKey.Role)
+	}
+	identity := &Identity{Name: apiKey.Name, Role: role, OrgID: apiKey.OrgID, Method: AUTH_METHOD_API_KEY}
+	cacheExpiredAt := time.Now().Add(API_KEY_CACHE_TIMEOUT)
+	if apiKey.ExpiredAt != nil && apiKey.ExpiredAt.Before(cacheExpiredAt) {
+		cacheExpiredAt = *apiKey.ExpiredAt
+	}
+	a.apiKeyCache.Store(hash, &cachedIdentity{identity: identity, expiredAt: cacheExpiredAt})
+	return identity, nil
+}
+
+func (a *Authenticator) authenticateJWT(token string) (*Identity, error) {
+	if !a.cfg.JWT.Enabled {
+		return nil, errors.New("jwt authentication is disabled")
+	}
+	claims, err := a.jwtValidator.Validate(token)
+	if err != nil {
+		return nil, err
+	}
+
+	identity := &Identity{Role: ROLE_NONE, Method: AUTH_METHOD_JWT}
+	for _, key := range []string{"preferred_username", "email", "sub"} {
+		if name, ok := claims[key].(string); ok && name != "" {
+			identity.Name = name
+			break
+		}
+	}
+	// the role claim may be a role or a list of roles, the highest one is used
+	var roles []interface{}
+	switch r := claims[a.cfg.JWT.RoleClaim].(type) {
+	case string:
+		roles = []interface{}{r}
+	case []interface{}:
+		roles = r
+	}
+	for _, r := range roles {
+		if name, ok := r.(string); ok {
+			if role, ok := ParseRole(name); ok && role > identity.Role {
+				identity.Role = role
+			}
+		}
+	}
+	if identity.Role == ROLE_NONE {
+		return nil, fmt.Errorf("jwt of %s has no valid role in claim %s", identity.Name, a.cfg.JWT.RoleClaim)
+	}
+	// the org claim is required, otherwise the caller is taken as an admin of all orgs
+	orgID := -1
+	switch org := claims[a.cfg.JWT.OrgClaim].(type) {
+	case float64:
+		if org == float64(int(org)) {
+			orgID = int(org)
+		}
+	case string:
+		if id, err := strconv.Atoi(org); err == nil {
+			orgID = id
+		}
+	}
+	if orgID <= 0 || orgID > common.MAX_ORG_ID {
+		return nil, fmt.Errorf("jwt of %s has no valid org in claim %s", identity.Name, a.cfg.JWT.OrgClaim)
+	}
+	identity.OrgID = orgID
+	return identity, nil
+}
+
+// bindOrg restricts the request to the org of the caller, only admins of the default org can
+// access other orgs by the X-Org-Id header.
+func bindOrg(c *gin.Context, identity *Identity) {
+	if identity.IsDefaultOrgAdmin() {
+		return
+	}
+	c.Request.Header.Set(httpcommon.HEADER_KEY_X_ORG_ID, strconv.Itoa(identity.GetOrgID()))
+}
+
+func abort(c *gin.Context, httpCode int, optStatus, description string) {
+	c.AbortWithStatusJSON(httpCode, gin.H{
+		"OPT_STATUS":  optStatus,
+		"DESCRIPTION": description,
+	})
+}
+
+// Middleware authenticates the caller of every request and checks its role against
+// the role required by the route, mutating calls are recorded in the log.
+func Middleware(cfg *Config, requiredRole func(method, path string) Role) gin.HandlerFunc {
+	authenticator := NewAuthenticator(cfg)
+	return func(c *gin.Context) {
+		if !cfg.Enabled {
+			c.Next()
+			return
+		}
+		required := requiredRole(c.Request.Method, c.Request.URL.Path)
+		if required == ROLE_NONE {
+			c.Next()
+			return
+		}
+
+		identity, err := authenticator.Authenticate(c.Request)
+		if err != nil {
+			abort(c, http.StatusUnauthorized, httpcommon.UNAUTHORIZED, err.Error())
+			return
+		}
+		if !identity.Role.Covers(required) {
+			abort(c, http.StatusForbidden, httpcommon.FORBIDDEN,
+				fmt.Sprintf("%s requires role %s, but %s is %s", c.Request.URL.Path, required, identity.Name, identity.Role))
+			return
+		}
+		bindOrg(c, identity)
+		c.Set(CONTEXT_KEY_IDENTITY, identity)
+		c.Next()
+	}
+}
@@ -0,0 +1,260 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	httpcommon "github.com/deepflowio/deepflow/server/controller/http/common"
+)
+
+func TestControllerRouteRole(t *testing.T) {
+	tests := []struct {
+		method string
+		path   string
+		want   Role
+	}{
+		{http.MethodGet, "/v1/health/", ROLE_NONE},
+		{http.MethodGet, "/v1/vtaps/", ROLE_VIEWER},
+		{http.MethodPatch, "/v1/vtap-group-configuration/abc/", ROLE_OPERATOR},
+		{http.MethodGet, "/v1/domains/", ROLE_VIEWER},
+		{http.MethodDelete, "/v1/domains/abc/", ROLE_ADMIN},
+		{http.MethodPost, "/v1/vtaps/batch/", ROLE_ADMIN},
+		{http.MethodGet, "/v1/api-keys/", ROLE_ADMIN},
+		{http.MethodPost, "/v1/vtaps-csv/", ROLE_VIEWER},
+		{http.MethodGet, "/v1/vtap-registrations/pending/", ROLE_VIEWER},
+		{http.MethodPost, "/v1/vtap-registrations/abc/approve/", ROLE_ADMIN},
+		{http.MethodGet, "/v1/vtap-enrollment-tokens/", ROLE_ADMIN},
+		{http.MethodPost, "/v1/vtap-group-rules/", ROLE_OPERATOR},
+		{http.MethodPost, "/v1/vtap-group-rules/preview/", ROLE_VIEWER},
+		{http.MethodPost, "/v1/policy-simulation/", ROLE_VIEWER},
+	}
+	for _, tt := range tests {
+		if got := ControllerRouteRole(tt.method, tt.path); got != tt.want {
+			t.Errorf("ControllerRouteRole(%s, %s) = %s, want %s", tt.method, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestQuerierRouteRole(t *testing.T) {
+	tests := []struct {
+		method string
+		path   string
+		want   Role
+	}{
+		{http.MethodPost, "/v1/query/", ROLE_VIEWER},
+		{http.MethodPost, "/v1/profile/ProfileTracing", ROLE_VIEWER},
+		{http.MethodPost, "/api/v1/prom/read", ROLE_VIEWER},
+		{http.MethodPost, "/prom/api/v1/query_range", ROLE_VIEWER},
+		{http.MethodGet, "/api/traces/abc", ROLE_VIEWER},
+		{http.MethodPost, "/v1/unknown/", ROLE_OPERATOR},
+		{http.MethodDelete, "/v1/query/", ROLE_OPERATOR},
+	}
+	for _, tt := range tests {
+		if got := QuerierRouteRole(tt.method, tt.path); got != tt.want {
+			t.Errorf("QuerierRouteRole(%s, %s) = %s, want %s", tt.method, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestBindOrg(t *testing.T) {
+	tests := []struct {
+		name     string
+		identity *Identity
+		header   string
+		want     string
+	}{
+		{"default org admin keeps header", &Identity{Role: ROLE_ADMIN, OrgID: 1}, "2", "2"},
+		{"no org admin keeps header", &Identity{Role: ROLE_ADMIN}, "2", "2"},
+		{"no org operator is bound to default org", &Identity{Role: ROLE_OPERATOR}, "2", "1"},
+		{"org admin is bound to its org", &Identity{Role: ROLE_ADMIN, OrgID: 3}, "2", "3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request, _ = http.NewRequest(http.MethodGet, "/v1/vtaps/", nil)
+			c.Request.Header.Set(httpcommon.HEADER_KEY_X_ORG_ID, tt.header)
+			bindOrg(c, tt.identity)
+			if got := c.Request.Header.Get(httpcommon.HEADER_KEY_X_ORG_ID); got != tt.want {
+				t.Errorf("bindOrg() org = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func signHS256(secret string, claims map[string]interface{}) string {
+	header, _ := json.Marshal(map[string]string{"alg": "HS256", "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(signed))
+	return signed + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func TestAuthenticateJWT(t *testing.T) {
+	cfg := &Config{
+		Enabled: true,
+		JWT: JWTConfig{
+			Enabled:    true,
+			Issuer:     "https://sso.example.com",
+			HMACSecret: "secret",
+			RoleClaim:  "role",
+			OrgClaim:   "org_id",
+		},
+	}
+	a := NewAuthenticator(cfg)
+	exp := float64(time.Now().Add(time.Hour).Unix())
+
+	tests := []struct {
+		name     string
+		token    string
+		wantErr  bool
+		wantRole Role
+		wantOrg  int
+	}{
+		{
+			name:     "valid",
+			token:    signHS256("secret", map[string]interface{}{"iss": "https://sso.example.com", "sub": "alice", "role": []string{"viewer", "operator"}, "org_id": 2, "exp": exp}),
+			wantRole: ROLE_OPERATOR,
+			wantOrg:  2,
+		},
+		{
+			name:    "bad signature",
+			token:   signHS256("other", map[string]interface{}{"iss": "https://sso.example.com", "sub": "alice", "role": "admin", "exp": exp}),
+			wantErr: true,
+		},
+		{
+			name:    "expired",
+			token:   signHS256("secret", map[string]interface{}{"iss": "https://sso.example.com", "sub": "alice", "role": "admin", "exp": exp - 7200}),
+			wantErr: true,
+		},
+		{
+			name:    "issuer mismatch",
+			token:   signHS256("secret", map[string]interface{}{"iss": "https://other.example.com", "sub": "alice", "role": "admin", "exp": exp}),
+			wantErr: true,
+		},
+		{
+			name:    "no role",
+			token:   signHS256("secret", map[string]interface{}{"iss": "https://sso.example.com", "sub": "alice", "exp": exp}),
+			wantErr: true,
+		},
+		{
+			name:     "org as string",
+			token:    signHS256("secret", map[string]interface{}{"iss": "https://sso.example.com", "sub": "alice", "role": "admin", "org_id": "3", "exp": exp}),
+			wantRole: ROLE_ADMIN,
+			wantOrg:  3,
+		},
+		{
+			name:    "no org",
+			token:   signHS256("secret", map[string]interface{}{"iss": "https://sso.example.com", "sub": "alice", "role": "admin", "exp": exp}),
+			wantErr: true,
+		},
+		{
+			name:    "org not a number",
+			token:   signHS256("secret", map[string]interface{}{"iss": "https://sso.example.com", "sub": "alice", "role": "admin", "org_id": "default", "exp": exp}),
+			wantErr: true,
+		},
+		{
+			name:    "org not an integer",
+			token:   signHS256("secret", map[string]interface{}{"iss": "https://sso.example.com", "sub": "alice", "role": "admin", "org_id": 1.5, "exp": exp}),
+			wantErr: true,
+		},
+		{
+			name:    "org zero",
+			token:   signHS256("secret", map[string]interface{}{"iss": "https://sso.example.com", "sub": "alice", "role": "admin", "org_id": 0, "exp": exp}),
+			wantErr: true,
+		},
+		{
+			name:    "org out of range",
+			token:   signHS256("secret", map[string]interface{}{"iss": "https://sso.example.com", "sub": "alice", "role": "admin", "org_id": 1025, "exp": exp}),
+			wantErr: true,
+		},
+		{
+			name:    "no exp",
+			token:   signHS256("secret", map[string]interface{}{"iss": "https://sso.example.com", "sub": "alice", "role": "admin", "org_id": 1}),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, "/v1/vtaps/", nil)
+			req.Header.Set(HEADER_KEY_AUTHORIZATION, "Bearer "+tt.token)
+			identity, err := a.Authenticate(req)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Authenticate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if identity.Role != tt.wantRole || identity.OrgID != tt.wantOrg || identity.Name != "alice" {
+				t.Errorf("Authenticate() = %+v, want role %s org %d", identity, tt.wantRole, tt.wantOrg)
+			}
+		})
+	}
+}
+
+func TestMiddlewareRejectsJWTWithoutOrg(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cfg := &Config{
+		Enabled: true,
+		JWT: JWTConfig{
+			Enabled:    true,
+			HMACSecret: "secret",
+			RoleClaim:  "role",
+			OrgClaim:   "org_id",
+		},
+	}
+	router := gin.New()
+	router.Use(Middleware(cfg, func(method, path string) Role { return ROLE_VIEWER }))
+	router.GET("/v1/vtaps/", func(c *gin.Context) { c.Status(http.StatusOK) })
+	exp := float64(time.Now().Add(time.Hour).Unix())
+
+	tests := []struct {
+		name string
+		org  interface{}
+		want int
+	}{
+		{"valid org", 2, http.StatusOK},
+		{"no org", nil, http.StatusUnauthorized},
+		{"org not a number", "abc", http.StatusUnauthorized},
+		{"org out of range", -1, http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := map[string]interface{}{"sub": "alice", "role": "admin", "exp": exp}
+			if tt.org != nil {
+				claims["org_id"] = tt.org
+			}
+			req, _ := http.NewRequest(http.MethodGet, "/v1/vtaps/", nil)
+			req.Header.Set(HEADER_KEY_AUTHORIZATION, "Bearer "+signHS256("secret", claims))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+}
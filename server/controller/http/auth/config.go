@@ -0,0 +1,36 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package auth
+
+type Config struct {
+	Enabled bool `default:"false" yaml:"enabled"`
+	// shared by all deepflow-server replicas, used by the requests between controllers
+	InternalToken string    `default:"" yaml:"internal-token"`
+	JWT           JWTConfig `yaml:"jwt"`
+}
+
+// JWTConfig validates bearer tokens issued by an OIDC provider (RS256 with jwks-url)
+// or signed with a shared secret (HS256 with hmac-secret).
+type JWTConfig struct {
+	Enabled    bool   `default:"false" yaml:"enabled"`
+	Issuer     string `default:"" yaml:"issuer"`
+	Audience   string `default:"" yaml:"audience"`
+	HMACSecret string `default:"" yaml:"hmac-secret"`
+	JWKSURL    string `default:"" yaml:"jwks-url"`
+	RoleClaim  string `default:"role" yaml:"role-claim"`
+	OrgClaim   string `default:"org_id" yaml:"org-claim"`
+}
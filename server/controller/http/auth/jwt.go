@@ -0,0 +1,225 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package auth
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	JWKS_REFRESH_INTERVAL     = 10 * time.Minute
+	JWKS_MIN_REFRESH_INTERVAL = time.Minute // limits refreshing caused by unknown kid
+	JWT_CLOCK_SKEW            = 30 * time.Second
+)
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+type JWTValidator struct {
+	cfg *JWTConfig
+
+	mutex       sync.Mutex
+	keys        map[string]*rsa.PublicKey
+	refreshedAt time.Time
+}
+
+func NewJWTValidator(cfg *JWTConfig) *JWTValidator {
+	return &JWTValidator{cfg: cfg, keys: make(map[string]*rsa.PublicKey)}
+}
+
+// Validate verifies the signature and registered claims of the token, and returns its claims.
+func (v *JWTValidator) Validate(token string) (map[string]interface{}, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("malformed jwt")
+	}
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("invalid jwt header: %s", err)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("invalid jwt signature: %s", err)
+	}
+	signed := []byte(parts[0] + "." + parts[1])
+
+	switch header.Alg {
+	case "HS256":
+		if v.cfg.HMACSecret == "" {
+			return nil, errors.New("jwt alg HS256 is not configured")
+		}
+		mac := hmac.New(sha256.New, []byte(v.cfg.HMACSecret))
+		mac.Write(signed)
+		if !hmac.Equal(signature, mac.Sum(nil)) {
+			return nil, errors.New("jwt signature mismatch")
+		}
+	case "RS256":
+		key, err := v.getKey(header.Kid)
+		if err != nil {
+			return nil, err
+		}
+		digest := sha256.Sum256(signed)
+		if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature); err != nil {
+			return nil, errors.New("jwt signature mismatch")
+		}
+	default:
+		return nil, fmt.Errorf("jwt alg %s is not supported", header.Alg)
+	}
+
+	claims := make(map[string]interface{})
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("invalid jwt claims: %s", err)
+	}
+	if err := v.validateClaims(claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+func (v *JWTValidator) validateClaims(claims map[string]interface{}) error {
+	now := time.Now()
+	exp, ok := claims["exp"].(float64)
+	if !ok {
+		return errors.New("jwt has no exp")
+	}
+	if now.After(time.Unix(int64(exp), 0).Add(JWT_CLOCK_SKEW)) {
+		return errors.New("jwt is expired")
+	}
+	if nbf, ok := claims["nbf"].(float64); ok && now.Add(JWT_CLOCK_SKEW).Before(time.Unix(int64(nbf), 0)) {
+		return errors.New("jwt is not valid yet")
+	}
+	if v.cfg.Issuer != "" && claims["iss"] != v.cfg.Issuer {
+		return fmt.Errorf("jwt issuer %v mismatch", claims["iss"])
+	}
+	if v.cfg.Audience != "" && !containsClaim(claims["aud"], v.cfg.Audience) {
+		return fmt.Errorf("jwt audience %v mismatch", claims["aud"])
+	}
+	return nil
+}
+
+func (v *JWTValidator) getKey(kid string) (*rsa.PublicKey, error) {
+	if v.cfg.JWKSURL == "" {
+		return nil, errors.New("jwt alg RS256 is not configured")
+	}
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	key, ok := v.keys[kid]
+	sinceRefresh := time.Since(v.refreshedAt)
+	if (ok && sinceRefresh < JWKS_REFRESH_INTERVAL) || (!ok && sinceRefresh < JWKS_MIN_REFRESH_INTERVAL) {
+		if !ok {
+			return nil, fmt.Errorf("jwt key %s not found", kid)
+		}
+		return key, nil
+	}
+
+	keys, err := fetchJWKS(v.cfg.JWKSURL)
+	if err != nil {
+		if ok {
+			log.Warningf("refresh jwks failed, use the cached keys: %s", err)
+			return key, nil
+		}
+		return nil, err
+	}
+	v.keys = keys
+	v.refreshedAt = time.Now()
+	if key, ok = v.keys[kid]; !ok {
+		return nil, fmt.Errorf("jwt key %s not found", kid)
+	}
+	return key, nil
+}
+
+func fetchJWKS(url string) (map[string]*rsa.PublicKey, error) {
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("get jwks (%s) failed: %s", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("get jwks (%s) failed: %s", url, resp.Status)
+	}
+	var jwks struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&jwks); err != nil {
+		return nil, fmt.Errorf("decode jwks (%s) failed: %s", url, err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey)
+	for _, k := range jwks.Keys {
+		if k.Kty != "RSA" {
+			continue
+		}
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			continue
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			continue
+		}
+		keys[k.Kid] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+	return keys, nil
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(segment, "="))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// containsClaim returns true if the claim, a string or a list of strings, contains the value.
+func containsClaim(claim interface{}, value string) bool {
+	switch c := claim.(type) {
+	case string:
+		return c == value
+	case []interface{}:
+		for _, item := range c {
+			if s, ok := item.(string); ok && s == value {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,126 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package auth
+
+import (
+	"net/http"
+	"strings"
+)
+
+type Role int
+
+const (
+	ROLE_NONE Role = iota // no authentication required
+	ROLE_VIEWER
+	ROLE_OPERATOR
+	ROLE_ADMIN
+)
+
+var roleNames = map[Role]string{
+	ROLE_NONE:     "none",
+	ROLE_VIEWER:   "viewer",
+	ROLE_OPERATOR: "operator",
+	ROLE_ADMIN:    "admin",
+}
+
+func (r Role) String() string {
+	if name, ok := roleNames[r]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+func ParseRole(name string) (Role, bool) {
+	for r, n := range roleNames {
+		if r != ROLE_NONE && n == strings.ToLower(name) {
+			return r, true
+		}
+	}
+	return ROLE_NONE, false
+}
+
+// Covers returns true if the role is allowed to access routes which require the other role.
+func (r Role) Covers(required Role) bool {
+	return r >= required
+}
+
+type routeRole struct {
+	method string // empty means all methods
+	prefix string
+	role   Role
+}
+
+// routes not listed here require viewer for read and operator for write
+var controllerRouteRoles = []routeRole{
+	{"", "/v1/health/", ROLE_NONE},
+	{"", "/v1/api-keys/", ROLE_ADMIN},
+	{"", "/v1/audit-logs/", ROLE_ADMIN},
+	{"", "/v1/vtap-enrollment-tokens/", ROLE_ADMIN},
+	{http.MethodPost, "/v1/vtap-registrations/", ROLE_ADMIN},
+	{"", "/v1/plugin/", ROLE_ADMIN},
+	{"", "/v1/mail-server/", ROLE_ADMIN},
+	{"", "/v1/vtaps/batch/", ROLE_ADMIN},
+	{http.MethodDelete, "/v1/domains/", ROLE_ADMIN},
+	{http.MethodDelete, "/v2/sub-domains/", ROLE_ADMIN},
+	{http.MethodPatch, "/v1/controllers/", ROLE_ADMIN},
+	{http.MethodDelete, "/v1/controllers/", ROLE_ADMIN},
+	{http.MethodPatch, "/v1/analyzers/", ROLE_ADMIN},
+	{http.MethodDelete, "/v1/analyzers/", ROLE_ADMIN},
+	{http.MethodPost, "/v1/rebalance-vtap/", ROLE_ADMIN},
+	{http.MethodPost, "/v1/vtaps-csv/", ROLE_VIEWER},                // export only
+	{http.MethodPost, "/v1/vtap-group-rules/preview/", ROLE_VIEWER}, // dry run only
+	{http.MethodPost, "/v1/policy-simulation/", ROLE_VIEWER},        // dry run only
+}
+
+func IsMutatingMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	}
+	return true
+}
+
+func matchRouteRole(routeRoles []routeRole, method, path string) Role {
+	for _, r := range routeRoles {
+		if (r.method == "" || r.method == method) && strings.HasPrefix(path, r.prefix) {
+			return r.role
+		}
+	}
+	if IsMutatingMethod(method) {
+		return ROLE_OPERATOR
+	}
+	return ROLE_VIEWER
+}
+
+// ControllerRouteRole returns the minimum role required by the controller route.
+func ControllerRouteRole(method, path string) Role {
+	return matchRouteRole(controllerRouteRoles, method, path)
+}
+
+// routes not listed here require viewer for read and operator for write,
+// the queries are sent by POST, but they are read only
+var querierRouteRoles = []routeRole{
+	{http.MethodPost, "/v1/query/", ROLE_VIEWER},
+	{http.MethodPost, "/v1/profile/", ROLE_VIEWER},
+	{http.MethodPost, "/api/v1/prom/read", ROLE_VIEWER},
+	{http.MethodPost, "/prom/api/v1/", ROLE_VIEWER},
+}
+
+// QuerierRouteRole returns the minimum role required by the querier route.
+func QuerierRouteRole(method, path string) Role {
+	return matchRouteRole(querierRouteRoles, method, path)
+}
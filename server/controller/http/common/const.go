@@ -30,6 +30,8 @@ const (
 	SELECTED_RESOURCES_NUM_EXCEEDED = "SELECTED_RESOURCES_NUM_EXCEEDED"
 	SERVICE_UNAVAILABLE             = "SERVICE_UNAVAILABLE"
 	K8S_SET_VTAP_FAIL               = "K8S_SET_VTAP_FAIL"
+	UNAUTHORIZED                    = "UNAUTHORIZED"
+	FORBIDDEN                       = "FORBIDDEN"
 )
 
 const (
@@ -73,4 +75,5 @@ const (
 const (
 	HEADER_KEY_X_USER_TYPE = "X-User-Type"
 	HEADER_KEY_X_USER_ID   = "X-User-Id"
+	HEADER_KEY_X_ORG_ID    = "X-Org-Id"
 )
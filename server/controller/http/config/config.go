@@ -16,6 +16,13 @@
 
 package config
 
+import (
+	"github.com/deepflowio/deepflow/server/controller/http/audit"
+	"github.com/deepflowio/deepflow/server/controller/http/auth"
+)
+
 type Config struct {
-	RedisRefreshInterval int `default:"3600" yaml:"redis_refresh_interval"`
+	RedisRefreshInterval int          `default:"3600" yaml:"redis_refresh_interval"`
+	Auth                 auth.Config  `yaml:"auth"`
+	Audit                audit.Config `yaml:"audit"`
 }
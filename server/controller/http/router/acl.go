@@ -0,0 +1,163 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+
+	httpcommon "github.com/deepflowio/deepflow/server/controller/http/common"
+	. "github.com/deepflowio/deepflow/server/controller/http/router/common"
+	"github.com/deepflowio/deepflow/server/controller/http/service"
+	"github.com/deepflowio/deepflow/server/controller/model"
+)
+
+type ACL struct{}
+
+func NewACL() *ACL {
+	return new(ACL)
+}
+
+func (a *ACL) RegisterTo(e *gin.Engine) {
+	e.GET("/v1/resource-groups/", getResourceGroups)
+	e.POST("/v1/resource-groups/", createResourceGroup)
+	e.PATCH("/v1/resource-groups/:lcuuid/", updateResourceGroup)
+	e.DELETE("/v1/resource-groups/:lcuuid/", deleteResourceGroup)
+
+	e.GET("/v1/acls/", getACLs)
+	e.POST("/v1/acls/", createACL)
+	e.PATCH("/v1/acls/:lcuuid/", updateACL)
+	e.DELETE("/v1/acls/:lcuuid/", deleteACL)
+}
+
+func getResourceGroups(c *gin.Context) {
+	orgID, err := GetOrgID(c)
+	if err != nil {
+		BadRequestResponse(c, httpcommon.INVALID_PARAMETERS, err.Error())
+		return
+	}
+	args := make(map[string]interface{})
+	for _, param := range []string{"lcuuid", "name", "business_id"} {
+		if value, ok := c.GetQuery(param); ok {
+			args[param] = value
+		}
+	}
+	args["org_id"] = orgID
+	data, err := service.GetResourceGroups(args)
+	JsonResponse(c, data, err)
+}
+
+func createResourceGroup(c *gin.Context) {
+	orgID, err := GetOrgID(c)
+	if err != nil {
+		BadRequestResponse(c, httpcommon.INVALID_PARAMETERS, err.Error())
+		return
+	}
+	var groupCreate model.ResourceGroupCreate
+	if err := c.ShouldBindBodyWith(&groupCreate, binding.JSON); err != nil {
+		BadRequestResponse(c, httpcommon.INVALID_POST_DATA, err.Error())
+		return
+	}
+	groupCreate.OrgID = orgID
+	data, err := service.CreateResourceGroup(groupCreate)
+	JsonResponse(c, data, err)
+}
+
+func updateResourceGroup(c *gin.Context) {
+	orgID, err := GetOrgID(c)
+	if err != nil {
+		BadRequestResponse(c, httpcommon.INVALID_PARAMETERS, err.Error())
+		return
+	}
+	// 避免struct会有默认值，这里转为map作为函数入参
+	patchMap := map[string]interface{}{}
+	if err := c.ShouldBindBodyWith(&patchMap, binding.JSON); err != nil {
+		BadRequestResponse(c, httpcommon.INVALID_POST_DATA, err.Error())
+		return
+	}
+	data, err := service.UpdateResourceGroup(orgID, c.Param("lcuuid"), patchMap)
+	JsonResponse(c, data, err)
+}
+
+func deleteResourceGroup(c *gin.Context) {
+	orgID, err := GetOrgID(c)
+	if err != nil {
+		BadRequestResponse(c, httpcommon.INVALID_PARAMETERS, err.Error())
+		return
+	}
+	data, err := service.DeleteResourceGroup(orgID, c.Param("lcuuid"))
+	JsonResponse(c, data, err)
+}
+
+func getACLs(c *gin.Context) {
+	orgID, err := GetOrgID(c)
+	if err != nil {
+		BadRequestResponse(c, httpcommon.INVALID_PARAMETERS, err.Error())
+		return
+	}
+	args := make(map[string]interface{})
+	for _, param := range []string{"lcuuid", "name", "application"} {
+		if value, ok := c.GetQuery(param); ok {
+			args[param] = value
+		}
+	}
+	args["org_id"] = orgID
+	data, err := service.GetACLs(args)
+	JsonResponse(c, data, err)
+}
+
+func createACL(c *gin.Context) {
+	orgID, err := GetOrgID(c)
+	if err != nil {
+		BadRequestResponse(c, httpcommon.INVALID_PARAMETERS, err.Error())
+		return
+	}
+	var aclCreate model.ACLCreate
+	if err := c.ShouldBindBodyWith(&aclCreate, binding.JSON); err != nil {
+		BadRequestResponse(c, httpcommon.INVALID_POST_DATA, err.Error())
+		return
+	}
+	aclCreate.OrgID = orgID
+	data, err := service.CreateACL(aclCreate)
+	JsonResponse(c, data, err)
+}
+
+func updateACL(c *gin.Context) {
+	orgID, err := GetOrgID(c)
+	if err != nil {
+		BadRequestResponse(c, httpcommon.INVALID_PARAMETERS, err.Error())
+		return
+	}
+	// 避免struct会有默认值，这里转为map作为函数入参
+	patchMap := map[string]interface{}{}
+	if err := c.ShouldBindBodyWith(&patchMap, binding.JSON); err != nil {
+		BadRequestResponse(c, httpcommon.INVALID_POST_DATA, err.Error())
+		return
+	}
+	data, err := service.UpdateACL(orgID, c.Param("lcuuid"), patchMap)
+	JsonResponse(c, data, err)
+}
+
+func deleteACL(c *gin.Context) {
+	orgID, err := GetOrgID(c)
+	if err != nil {
+		BadRequestResponse(c, httpcommon.INVALID_PARAMETERS, err.Error())
+		return
+	}
+	data, err := service.DeleteACL(orgID, c.Param("lcuuid"))
+	JsonResponse(c, data, err)
+}
@@ -0,0 +1,71 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+
+	"github.com/deepflowio/deepflow/server/controller/http/auth"
+	httpcommon "github.com/deepflowio/deepflow/server/controller/http/common"
+	. "github.com/deepflowio/deepflow/server/controller/http/router/common"
+	"github.com/deepflowio/deepflow/server/controller/http/service"
+	"github.com/deepflowio/deepflow/server/controller/model"
+)
+
+type APIKey struct{}
+
+func NewAPIKey() *APIKey {
+	return new(APIKey)
+}
+
+func (a *APIKey) RegisterTo(e *gin.Engine) {
+	e.GET("/v1/api-keys/", getAPIKeys)
+	e.POST("/v1/api-keys/", createAPIKey)
+	e.DELETE("/v1/api-keys/:lcuuid/", deleteAPIKey)
+}
+
+func getAPIKeys(c *gin.Context) {
+	args := make(map[string]interface{})
+	for _, param := range []string{"name", "role", "org_id"} {
+		if value, ok := c.GetQuery(param); ok {
+			args[param] = value
+		}
+	}
+	// callers can only see the keys of their own org, except the admins of the default org
+	if identity := auth.GetIdentity(c); identity != nil && !identity.IsDefaultOrgAdmin() {
+		args["org_id"] = identity.GetOrgID()
+	}
+	data, err := service.GetAPIKeys(args)
+	JsonResponse(c, data, err)
+}
+
+func createAPIKey(c *gin.Context) {
+	var apiKeyCreate model.APIKeyCreate
+	if err := c.ShouldBindBodyWith(&apiKeyCreate, binding.JSON); err != nil {
+		BadRequestResponse(c, httpcommon.INVALID_POST_DATA, err.Error())
+		return
+	}
+
+	data, err := service.CreateAPIKey(apiKeyCreate, auth.GetIdentity(c))
+	JsonResponse(c, data, err)
+}
+
+func deleteAPIKey(c *gin.Context) {
+	data, err := service.DeleteAPIKey(c.Param("lcuuid"), auth.GetIdentity(c))
+	JsonResponse(c, data, err)
+}
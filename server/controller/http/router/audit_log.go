@@ -0,0 +1,60 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/deepflowio/deepflow/server/controller/common"
+	httpcommon "github.com/deepflowio/deepflow/server/controller/http/common"
+	. "github.com/deepflowio/deepflow/server/controller/http/router/common"
+	"github.com/deepflowio/deepflow/server/controller/http/service"
+)
+
+type AuditLog struct{}
+
+func NewAuditLog() *AuditLog {
+	return new(AuditLog)
+}
+
+func (a *AuditLog) RegisterTo(e *gin.Engine) {
+	e.GET("/v1/audit-logs/", getAuditLogs)
+}
+
+func getAuditLogs(c *gin.Context) {
+	args := make(map[string]interface{})
+	for _, param := range []string{
+		"actor", "method", "route", "resource_type", "resource_lcuuid", "result", "start_time", "end_time", "limit",
+	} {
+		if value, ok := c.GetQuery(param); ok {
+			args[param] = value
+		}
+	}
+	// only the admins of the default org can see the logs of all orgs
+	orgID, err := GetOrgID(c)
+	if err != nil {
+		BadRequestResponse(c, httpcommon.INVALID_PARAMETERS, err.Error())
+		return
+	}
+	if orgID != common.DEFAULT_ORG_ID {
+		args["org_id"] = orgID
+	} else if value, ok := c.GetQuery("org_id"); ok {
+		args["org_id"] = value
+	}
+	data, err := service.GetAuditLogs(args)
+	JsonResponse(c, data, err)
+}
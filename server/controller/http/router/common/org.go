@@ -0,0 +1,41 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package common
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/deepflowio/deepflow/server/controller/common"
+	httpcommon "github.com/deepflowio/deepflow/server/controller/http/common"
+)
+
+// GetOrgID returns the organization ID carried by the X-Org-Id header,
+// requests without the header belong to the default organization.
+func GetOrgID(c *gin.Context) (int, error) {
+	value := c.GetHeader(httpcommon.HEADER_KEY_X_ORG_ID)
+	if value == "" {
+		return common.DEFAULT_ORG_ID, nil
+	}
+	orgID, err := strconv.Atoi(value)
+	if err != nil || orgID <= 0 || orgID > common.MAX_ORG_ID {
+		return 0, fmt.Errorf("invalid %s (%s)", httpcommon.HEADER_KEY_X_ORG_ID, value)
+	}
+	return orgID, nil
+}
@@ -0,0 +1,62 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package common
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/deepflowio/deepflow/server/controller/common"
+	httpcommon "github.com/deepflowio/deepflow/server/controller/http/common"
+)
+
+func TestGetOrgID(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  string
+		want    int
+		wantErr bool
+	}{
+		{name: "default org without header", header: "", want: common.DEFAULT_ORG_ID},
+		{name: "valid org", header: "2", want: 2},
+		{name: "max org", header: "1024", want: common.MAX_ORG_ID},
+		{name: "zero", header: "0", wantErr: true},
+		{name: "negative", header: "-1", wantErr: true},
+		{name: "out of range", header: "1025", wantErr: true},
+		{name: "not a number", header: "org2", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodGet, "/v2/domains/", nil)
+			if tt.header != "" {
+				c.Request.Header.Set(httpcommon.HEADER_KEY_X_ORG_ID, tt.header)
+			}
+			got, err := GetOrgID(c)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetOrgID() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("GetOrgID() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,227 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+
+	httpcommon "github.com/deepflowio/deepflow/server/controller/http/common"
+	. "github.com/deepflowio/deepflow/server/controller/http/router/common"
+	"github.com/deepflowio/deepflow/server/controller/http/service"
+	"github.com/deepflowio/deepflow/server/controller/model"
+)
+
+type Npb struct{}
+
+func NewNpb() *Npb {
+	return new(Npb)
+}
+
+func (n *Npb) RegisterTo(e *gin.Engine) {
+	e.GET("/v1/npb-tunnels/", getNpbTunnels)
+	e.POST("/v1/npb-tunnels/", createNpbTunnel)
+	e.PATCH("/v1/npb-tunnels/:lcuuid/", updateNpbTunnel)
+	e.DELETE("/v1/npb-tunnels/:lcuuid/", deleteNpbTunnel)
+
+	e.GET("/v1/npb-policies/", getNpbPolicies)
+	e.POST("/v1/npb-policies/", createNpbPolicy)
+	e.PATCH("/v1/npb-policies/:lcuuid/", updateNpbPolicy)
+	e.DELETE("/v1/npb-policies/:lcuuid/", deleteNpbPolicy)
+
+	e.GET("/v1/pcap-policies/", getPcapPolicies)
+	e.POST("/v1/pcap-policies/", createPcapPolicy)
+	e.PATCH("/v1/pcap-policies/:lcuuid/", updatePcapPolicy)
+	e.DELETE("/v1/pcap-policies/:lcuuid/", deletePcapPolicy)
+}
+
+func getNpbTunnels(c *gin.Context) {
+	orgID, err := GetOrgID(c)
+	if err != nil {
+		BadRequestResponse(c, httpcommon.INVALID_PARAMETERS, err.Error())
+		return
+	}
+	args := make(map[string]interface{})
+	for _, param := range []string{"lcuuid", "name"} {
+		if value, ok := c.GetQuery(param); ok {
+			args[param] = value
+		}
+	}
+	args["org_id"] = orgID
+	data, err := service.GetNpbTunnels(args)
+	JsonResponse(c, data, err)
+}
+
+func createNpbTunnel(c *gin.Context) {
+	orgID, err := GetOrgID(c)
+	if err != nil {
+		BadRequestResponse(c, httpcommon.INVALID_PARAMETERS, err.Error())
+		return
+	}
+	var tunnelCreate model.NpbTunnelCreate
+	if err := c.ShouldBindBodyWith(&tunnelCreate, binding.JSON); err != nil {
+		BadRequestResponse(c, httpcommon.INVALID_POST_DATA, err.Error())
+		return
+	}
+	tunnelCreate.OrgID = orgID
+	data, err := service.CreateNpbTunnel(tunnelCreate)
+	JsonResponse(c, data, err)
+}
+
+func updateNpbTunnel(c *gin.Context) {
+	orgID, err := GetOrgID(c)
+	if err != nil {
+		BadRequestResponse(c, httpcommon.INVALID_PARAMETERS, err.Error())
+		return
+	}
+	// 避免struct会有默认值，这里转为map作为函数入参
+	patchMap := map[string]interface{}{}
+	if err := c.ShouldBindBodyWith(&patchMap, binding.JSON); err != nil {
+		BadRequestResponse(c, httpcommon.INVALID_POST_DATA, err.Error())
+		return
+	}
+	data, err := service.UpdateNpbTunnel(orgID, c.Param("lcuuid"), patchMap)
+	JsonResponse(c, data, err)
+}
+
+func deleteNpbTunnel(c *gin.Context) {
+	orgID, err := GetOrgID(c)
+	if err != nil {
+		BadRequestResponse(c, httpcommon.INVALID_PARAMETERS, err.Error())
+		return
+	}
+	data, err := service.DeleteNpbTunnel(orgID, c.Param("lcuuid"))
+	JsonResponse(c, data, err)
+}
+
+func getNpbPolicies(c *gin.Context) {
+	orgID, err := GetOrgID(c)
+	if err != nil {
+		BadRequestResponse(c, httpcommon.INVALID_PARAMETERS, err.Error())
+		return
+	}
+	args := make(map[string]interface{})
+	for _, param := range []string{"lcuuid", "name", "acl_id", "npb_tunnel_id"} {
+		if value, ok := c.GetQuery(param); ok {
+			args[param] = value
+		}
+	}
+	args["org_id"] = orgID
+	data, err := service.GetNpbPolicies(args)
+	JsonResponse(c, data, err)
+}
+
+func createNpbPolicy(c *gin.Context) {
+	orgID, err := GetOrgID(c)
+	if err != nil {
+		BadRequestResponse(c, httpcommon.INVALID_PARAMETERS, err.Error())
+		return
+	}
+	var policyCreate model.NpbPolicyCreate
+	if err := c.ShouldBindBodyWith(&policyCreate, binding.JSON); err != nil {
+		BadRequestResponse(c, httpcommon.INVALID_POST_DATA, err.Error())
+		return
+	}
+	policyCreate.OrgID = orgID
+	data, err := service.CreateNpbPolicy(policyCreate)
+	JsonResponse(c, data, err)
+}
+
+func updateNpbPolicy(c *gin.Context) {
+	orgID, err := GetOrgID(c)
+	if err != nil {
+		BadRequestResponse(c, httpcommon.INVALID_PARAMETERS, err.Error())
+		return
+	}
+	// 避免struct会有默认值，这里转为map作为函数入参
+	patchMap := map[string]interface{}{}
+	if err := c.ShouldBindBodyWith(&patchMap, binding.JSON); err != nil {
+		BadRequestResponse(c, httpcommon.INVALID_POST_DATA, err.Error())
+		return
+	}
+	data, err := service.UpdateNpbPolicy(orgID, c.Param("lcuuid"), patchMap)
+	JsonResponse(c, data, err)
+}
+
+func deleteNpbPolicy(c *gin.Context) {
+	orgID, err := GetOrgID(c)
+	if err != nil {
+		BadRequestResponse(c, httpcommon.INVALID_PARAMETERS, err.Error())
+		return
+	}
+	data, err := service.DeleteNpbPolicy(orgID, c.Param("lcuuid"))
+	JsonResponse(c, data, err)
+}
+
+func getPcapPolicies(c *gin.Context) {
+	orgID, err := GetOrgID(c)
+	if err != nil {
+		BadRequestResponse(c, httpcommon.INVALID_PARAMETERS, err.Error())
+		return
+	}
+	args := make(map[string]interface{})
+	for _, param := range []string{"lcuuid", "name", "acl_id"} {
+		if value, ok := c.GetQuery(param); ok {
+			args[param] = value
+		}
+	}
+	args["org_id"] = orgID
+	data, err := service.GetPcapPolicies(args)
+	JsonResponse(c, data, err)
+}
+
+func createPcapPolicy(c *gin.Context) {
+	orgID, err := GetOrgID(c)
+	if err != nil {
+		BadRequestResponse(c, httpcommon.INVALID_PARAMETERS, err.Error())
+		return
+	}
+	var policyCreate model.PcapPolicyCreate
+	if err := c.ShouldBindBodyWith(&policyCreate, binding.JSON); err != nil {
+		BadRequestResponse(c, httpcommon.INVALID_POST_DATA, err.Error())
+		return
+	}
+	policyCreate.OrgID = orgID
+	data, err := service.CreatePcapPolicy(policyCreate)
+	JsonResponse(c, data, err)
+}
+
+func updatePcapPolicy(c *gin.Context) {
+	orgID, err := GetOrgID(c)
+	if err != nil {
+		BadRequestResponse(c, httpcommon.INVALID_PARAMETERS, err.Error())
+		return
+	}
+	// 避免struct会有默认值，这里转为map作为函数入参
+	patchMap := map[string]interface{}{}
+	if err := c.ShouldBindBodyWith(&patchMap, binding.JSON); err != nil {
+		BadRequestResponse(c, httpcommon.INVALID_POST_DATA, err.Error())
+		return
+	}
+	data, err := service.UpdatePcapPolicy(orgID, c.Param("lcuuid"), patchMap)
+	JsonResponse(c, data, err)
+}
+
+func deletePcapPolicy(c *gin.Context) {
+	orgID, err := GetOrgID(c)
+	if err != nil {
+		BadRequestResponse(c, httpcommon.INVALID_PARAMETERS, err.Error())
+		return
+	}
+	data, err := service.DeletePcapPolicy(orgID, c.Param("lcuuid"))
+	JsonResponse(c, data, err)
+}
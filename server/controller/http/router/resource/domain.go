@@ -57,6 +57,12 @@ func (d *Domain) RegisterTo(e *gin.Engine) {
 
 func getDomain(c *gin.Context) {
 	args := make(map[string]interface{})
+	orgID, err := common.GetOrgID(c)
+	if err != nil {
+		common.BadRequestResponse(c, httpcommon.INVALID_PARAMETERS, err.Error())
+		return
+	}
+	args["org_id"] = orgID
 	args["lcuuid"] = c.Param("lcuuid")
 	data, err := resource.GetDomains(args)
 	common.JsonResponse(c, data, err)
@@ -64,6 +70,12 @@ func getDomain(c *gin.Context) {
 
 func getDomains(c *gin.Context) {
 	args := make(map[string]interface{})
+	orgID, err := common.GetOrgID(c)
+	if err != nil {
+		common.BadRequestResponse(c, httpcommon.INVALID_PARAMETERS, err.Error())
+		return
+	}
+	args["org_id"] = orgID
 	if value, ok := c.GetQuery("name"); ok {
 		args["name"] = value
 	}
@@ -82,6 +94,11 @@ func createDomain(cfg *config.ControllerConfig) gin.HandlerFunc {
 			common.BadRequestResponse(c, httpcommon.INVALID_POST_DATA, err.Error())
 			return
 		}
+		domainCreate.OrgID, err = common.GetOrgID(c)
+		if err != nil {
+			common.BadRequestResponse(c, httpcommon.INVALID_PARAMETERS, err.Error())
+			return
+		}
 
 		data, err := resource.CreateDomain(domainCreate, cfg)
 		common.JsonResponse(c, data, err)
@@ -111,6 +128,15 @@ func updateDomain(cfg *config.ControllerConfig) gin.HandlerFunc {
 		c.ShouldBindBodyWith(&patchMap, binding.JSON)
 
 		lcuuid := c.Param("lcuuid")
+		orgID, err := common.GetOrgID(c)
+		if err != nil {
+			common.BadRequestResponse(c, httpcommon.INVALID_PARAMETERS, err.Error())
+			return
+		}
+		if err = resource.CheckDomainOrg(lcuuid, orgID); err != nil {
+			common.JsonResponse(c, nil, err)
+			return
+		}
 
 		// set vtap
 		err = resource.KubernetesSetVtap(lcuuid, vTapValue, false)
@@ -128,6 +154,15 @@ func deleteDomain(c *gin.Context) {
 	var err error
 
 	lcuuid := c.Param("lcuuid")
+	orgID, err := common.GetOrgID(c)
+	if err != nil {
+		common.BadRequestResponse(c, httpcommon.INVALID_PARAMETERS, err.Error())
+		return
+	}
+	if err = resource.CheckDomainOrg(lcuuid, orgID); err != nil {
+		common.JsonResponse(c, nil, err)
+		return
+	}
 	data, err := resource.DeleteDomain(lcuuid)
 	common.JsonResponse(c, data, err)
 }
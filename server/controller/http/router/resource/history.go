@@ -0,0 +1,52 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package resource
+
+import (
+	"github.com/gin-gonic/gin"
+
+	httpcommon "github.com/deepflowio/deepflow/server/controller/http/common"
+	"github.com/deepflowio/deepflow/server/controller/http/router/common"
+	"github.com/deepflowio/deepflow/server/controller/http/service/resource"
+)
+
+type ResourceHistory struct{}
+
+func NewResourceHistory() *ResourceHistory {
+	return new(ResourceHistory)
+}
+
+func (h *ResourceHistory) RegisterTo(e *gin.Engine) {
+	e.GET("/v1/resources/at", getResourcesAt)
+}
+
+func getResourcesAt(c *gin.Context) {
+	args := make(map[string]interface{})
+	orgID, err := common.GetOrgID(c)
+	if err != nil {
+		common.BadRequestResponse(c, httpcommon.INVALID_PARAMETERS, err.Error())
+		return
+	}
+	args["org_id"] = orgID
+	for _, key := range []string{"time", "ip", "resource_type", "lcuuid", "limit"} {
+		if value, ok := c.GetQuery(key); ok {
+			args[key] = value
+		}
+	}
+	data, err := resource.GetResourcesAt(args)
+	common.JsonResponse(c, data, err)
+}
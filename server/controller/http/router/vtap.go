@@ -66,6 +66,12 @@ func (v *Vtap) RegisterTo(e *gin.Engine) {
 
 func getVtap(c *gin.Context) {
 	args := make(map[string]interface{})
+	orgID, err := GetOrgID(c)
+	if err != nil {
+		BadRequestResponse(c, httpcommon.INVALID_PARAMETERS, err.Error())
+		return
+	}
+	args["org_id"] = orgID
 	args["lcuuid"] = c.Param("lcuuid")
 	data, err := service.GetVtaps(args)
 	JsonResponse(c, data, err)
@@ -73,6 +79,12 @@ func getVtap(c *gin.Context) {
 
 func getVtaps(c *gin.Context) {
 	args := make(map[string]interface{})
+	orgID, err := GetOrgID(c)
+	if err != nil {
+		BadRequestResponse(c, httpcommon.INVALID_PARAMETERS, err.Error())
+		return
+	}
+	args["org_id"] = orgID
 	args["names"] = c.QueryArray("name")
 	if value, ok := c.GetQuery("type"); ok {
 		args["type"] = value
@@ -0,0 +1,127 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+
+	httpcommon "github.com/deepflowio/deepflow/server/controller/http/common"
+	. "github.com/deepflowio/deepflow/server/controller/http/router/common"
+	"github.com/deepflowio/deepflow/server/controller/http/service"
+	"github.com/deepflowio/deepflow/server/controller/model"
+)
+
+type VTapEnrollment struct{}
+
+func NewVTapEnrollment() *VTapEnrollment {
+	return new(VTapEnrollment)
+}
+
+func (v *VTapEnrollment) RegisterTo(e *gin.Engine) {
+	e.GET("/v1/vtap-enrollment-tokens/", getVTapEnrollmentTokens)
+	e.POST("/v1/vtap-enrollment-tokens/", createVTapEnrollmentToken)
+	e.DELETE("/v1/vtap-enrollment-tokens/:lcuuid/", deleteVTapEnrollmentToken)
+
+	e.GET("/v1/vtap-registrations/pending/", getPendingVTaps)
+	e.POST("/v1/vtap-registrations/:lcuuid/approve/", approveVTapRegistration)
+	e.POST("/v1/vtap-registrations/:lcuuid/reject/", rejectVTapRegistration)
+}
+
+func getVTapEnrollmentTokens(c *gin.Context) {
+	args := make(map[string]interface{})
+	for _, param := range []string{"name", "vtap_group_lcuuid"} {
+		if value, ok := c.GetQuery(param); ok {
+			args[param] = value
+		}
+	}
+	orgID, err := GetOrgID(c)
+	if err != nil {
+		BadRequestResponse(c, httpcommon.INVALID_PARAMETERS, err.Error())
+		return
+	}
+	args["org_id"] = orgID
+	data, err := service.GetVTapEnrollmentTokens(args)
+	JsonResponse(c, data, err)
+}
+
+func createVTapEnrollmentToken(c *gin.Context) {
+	var tokenCreate model.VTapEnrollmentTokenCreate
+	if err := c.ShouldBindBodyWith(&tokenCreate, binding.JSON); err != nil {
+		BadRequestResponse(c, httpcommon.INVALID_POST_DATA, err.Error())
+		return
+	}
+	orgID, err := GetOrgID(c)
+	if err != nil {
+		BadRequestResponse(c, httpcommon.INVALID_PARAMETERS, err.Error())
+		return
+	}
+	tokenCreate.OrgID = orgID
+
+	data, err := service.CreateVTapEnrollmentToken(tokenCreate)
+	JsonResponse(c, data, err)
+}
+
+func deleteVTapEnrollmentToken(c *gin.Context) {
+	orgID, err := GetOrgID(c)
+	if err != nil {
+		BadRequestResponse(c, httpcommon.INVALID_PARAMETERS, err.Error())
+		return
+	}
+	data, err := service.DeleteVTapEnrollmentToken(c.Param("lcuuid"), orgID)
+	JsonResponse(c, data, err)
+}
+
+func getPendingVTaps(c *gin.Context) {
+	args := make(map[string]interface{})
+	orgID, err := GetOrgID(c)
+	if err != nil {
+		BadRequestResponse(c, httpcommon.INVALID_PARAMETERS, err.Error())
+		return
+	}
+	args["org_id"] = orgID
+	data, err := service.GetPendingVTaps(args)
+	JsonResponse(c, data, err)
+}
+
+func approveVTapRegistration(c *gin.Context) {
+	var approve model.VTapRegistrationApprove
+	// the body is optional
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindBodyWith(&approve, binding.JSON); err != nil {
+			BadRequestResponse(c, httpcommon.INVALID_POST_DATA, err.Error())
+			return
+		}
+	}
+	orgID, err := GetOrgID(c)
+	if err != nil {
+		BadRequestResponse(c, httpcommon.INVALID_PARAMETERS, err.Error())
+		return
+	}
+	data, err := service.ApproveVTapRegistration(c.Param("lcuuid"), orgID, approve)
+	JsonResponse(c, data, err)
+}
+
+func rejectVTapRegistration(c *gin.Context) {
+	orgID, err := GetOrgID(c)
+	if err != nil {
+		BadRequestResponse(c, httpcommon.INVALID_PARAMETERS, err.Error())
+		return
+	}
+	data, err := service.RejectVTapRegistration(c.Param("lcuuid"), orgID)
+	JsonResponse(c, data, err)
+}
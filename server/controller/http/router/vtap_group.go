@@ -45,6 +45,12 @@ func (v *VtapGroup) RegisterTo(e *gin.Engine) {
 
 func getVtapGroup(c *gin.Context) {
 	args := make(map[string]interface{})
+	orgID, err := GetOrgID(c)
+	if err != nil {
+		BadRequestResponse(c, httpcommon.INVALID_PARAMETERS, err.Error())
+		return
+	}
+	args["org_id"] = orgID
 	args["lcuuid"] = c.Param("lcuuid")
 	data, err := service.GetVtapGroups(args)
 	JsonResponse(c, data, err)
@@ -52,6 +58,12 @@ func getVtapGroup(c *gin.Context) {
 
 func getVtapGroups(c *gin.Context) {
 	args := make(map[string]interface{})
+	orgID, err := GetOrgID(c)
+	if err != nil {
+		BadRequestResponse(c, httpcommon.INVALID_PARAMETERS, err.Error())
+		return
+	}
+	args["org_id"] = orgID
 	if value, ok := c.GetQuery("name"); ok {
 		args["name"] = value
 	}
@@ -73,6 +85,11 @@ func createVtapGroup(cfg *config.ControllerConfig) gin.HandlerFunc {
 			BadRequestResponse(c, httpcommon.INVALID_POST_DATA, err.Error())
 			return
 		}
+		vtapGroupCreate.OrgID, err = GetOrgID(c)
+		if err != nil {
+			BadRequestResponse(c, httpcommon.INVALID_PARAMETERS, err.Error())
+			return
+		}
 
 		data, err := service.CreateVtapGroup(vtapGroupCreate, cfg)
 		JsonResponse(c, data, err)
@@ -0,0 +1,123 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+
+	httpcommon "github.com/deepflowio/deepflow/server/controller/http/common"
+	. "github.com/deepflowio/deepflow/server/controller/http/router/common"
+	"github.com/deepflowio/deepflow/server/controller/http/service"
+	"github.com/deepflowio/deepflow/server/controller/model"
+)
+
+type VTapGroupRule struct{}
+
+func NewVTapGroupRule() *VTapGroupRule {
+	return new(VTapGroupRule)
+}
+
+func (v *VTapGroupRule) RegisterTo(e *gin.Engine) {
+	e.GET("/v1/vtap-group-rules/", getVTapGroupRules)
+	e.POST("/v1/vtap-group-rules/", createVTapGroupRule)
+	e.PATCH("/v1/vtap-group-rules/:lcuuid/", updateVTapGroupRule)
+	e.DELETE("/v1/vtap-group-rules/:lcuuid/", deleteVTapGroupRule)
+
+	// dry run, nothing is changed
+	e.GET("/v1/vtap-group-rules/preview/", previewVTapGroupRules)
+	e.POST("/v1/vtap-group-rules/preview/", previewVTapGroupRules)
+}
+
+func getVTapGroupRules(c *gin.Context) {
+	args := make(map[string]interface{})
+	for _, param := range []string{"lcuuid", "name", "vtap_group_lcuuid"} {
+		if value, ok := c.GetQuery(param); ok {
+			args[param] = value
+		}
+	}
+	orgID, err := GetOrgID(c)
+	if err != nil {
+		BadRequestResponse(c, httpcommon.INVALID_PARAMETERS, err.Error())
+		return
+	}
+	args["org_id"] = orgID
+	data, err := service.GetVTapGroupRules(args)
+	JsonResponse(c, data, err)
+}
+
+func createVTapGroupRule(c *gin.Context) {
+	var ruleCreate model.VTapGroupRuleCreate
+	if err := c.ShouldBindBodyWith(&ruleCreate, binding.JSON); err != nil {
+		BadRequestResponse(c, httpcommon.INVALID_POST_DATA, err.Error())
+		return
+	}
+	orgID, err := GetOrgID(c)
+	if err != nil {
+		BadRequestResponse(c, httpcommon.INVALID_PARAMETERS, err.Error())
+		return
+	}
+	ruleCreate.OrgID = orgID
+
+	data, err := service.CreateVTapGroupRule(ruleCreate)
+	JsonResponse(c, data, err)
+}
+
+func updateVTapGroupRule(c *gin.Context) {
+	// 避免struct会有默认值，这里转为map作为函数入参
+	patchMap := map[string]interface{}{}
+	if err := c.ShouldBindBodyWith(&patchMap, binding.JSON); err != nil {
+		BadRequestResponse(c, httpcommon.INVALID_POST_DATA, err.Error())
+		return
+	}
+	orgID, err := GetOrgID(c)
+	if err != nil {
+		BadRequestResponse(c, httpcommon.INVALID_PARAMETERS, err.Error())
+		return
+	}
+	data, err := service.UpdateVTapGroupRule(c.Param("lcuuid"), orgID, patchMap)
+	JsonResponse(c, data, err)
+}
+
+func deleteVTapGroupRule(c *gin.Context) {
+	orgID, err := GetOrgID(c)
+	if err != nil {
+		BadRequestResponse(c, httpcommon.INVALID_PARAMETERS, err.Error())
+		return
+	}
+	data, err := service.DeleteVTapGroupRule(c.Param("lcuuid"), orgID)
+	JsonResponse(c, data, err)
+}
+
+// previewVTapGroupRules previews the current rules, or the candidate rule in the body of POST
+func previewVTapGroupRules(c *gin.Context) {
+	var candidate *model.VTapGroupRuleCreate
+	if c.Request.Method == "POST" {
+		candidate = &model.VTapGroupRuleCreate{}
+		if err := c.ShouldBindBodyWith(candidate, binding.JSON); err != nil {
+			BadRequestResponse(c, httpcommon.INVALID_POST_DATA, err.Error())
+			return
+		}
+	}
+	orgID, err := GetOrgID(c)
+	if err != nil {
+		BadRequestResponse(c, httpcommon.INVALID_PARAMETERS, err.Error())
+		return
+	}
+	data, err := service.PreviewVTapGroupRules(orgID, candidate)
+	JsonResponse(c, data, err)
+}
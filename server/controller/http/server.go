@@ -28,6 +28,8 @@ import (
 	"github.com/deepflowio/deepflow/server/controller/config"
 	"github.com/deepflowio/deepflow/server/controller/genesis"
 	"github.com/deepflowio/deepflow/server/controller/http/appender"
+	"github.com/deepflowio/deepflow/server/controller/http/audit"
+	"github.com/deepflowio/deepflow/server/controller/http/auth"
 	"github.com/deepflowio/deepflow/server/controller/http/common/registrant"
 	"github.com/deepflowio/deepflow/server/controller/http/router"
 	"github.com/deepflowio/deepflow/server/controller/http/router/resource"
@@ -58,6 +60,8 @@ func NewServer(logFile string, cfg *config.ControllerConfig) *Server {
 	g := gin.New()
 	g.Use(gin.Recovery())
 	g.Use(gin.LoggerWithFormatter(logger.GinLogFormat))
+	g.Use(auth.Middleware(&cfg.HTTPCfg.Auth, auth.ControllerRouteRole))
+	g.Use(audit.Middleware(&cfg.HTTPCfg.Audit))
 	s.engine = g
 	return s
 }
@@ -111,9 +115,16 @@ func (s *Server) appendRegistrant() []registrant.Registrant {
 		router.NewVtapRepo(),
 		router.NewPlugin(),
 		router.NewMail(),
+		router.NewAPIKey(),
+		router.NewAuditLog(),
+		router.NewVTapEnrollment(),
+		router.NewVTapGroupRule(),
+		router.NewACL(),
+		router.NewNpb(),
 
 		// resource
 		resource.NewDomain(s.controllerConfig),
+		resource.NewResourceHistory(),
 	}
 
 	// appends routers supported in CE or EE
@@ -0,0 +1,542 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/deepflowio/deepflow/server/controller/common"
+	"github.com/deepflowio/deepflow/server/controller/db/mysql"
+	httpcommon "github.com/deepflowio/deepflow/server/controller/http/common"
+	. "github.com/deepflowio/deepflow/server/controller/http/service/common"
+	"github.com/deepflowio/deepflow/server/controller/model"
+	"github.com/deepflowio/deepflow/server/controller/trisolaris/refresh"
+)
+
+const ACL_DEFAULT_TAP_TYPE = 3
+
+const (
+	RESOURCE_GROUP_IP_TYPE_SINGLE = 1
+	RESOURCE_GROUP_IP_TYPE_RANGE  = 2
+	RESOURCE_GROUP_IP_TYPE_CIDR   = 3
+	RESOURCE_GROUP_IP_TYPE_MIX    = 4
+)
+
+var aclApplicationToGroupBusinessID = map[int]int{
+	common.ACL_APPLICATION_NPB:  common.RESOURCE_GROUP_BUSINESS_ID_NPB,
+	common.ACL_APPLICATION_PCAP: common.RESOURCE_GROUP_BUSINESS_ID_PCAP,
+}
+
+func joinInts(values []int) string {
+	strs := make([]string, 0, len(values))
+	for _, v := range values {
+		strs = append(strs, strconv.Itoa(v))
+	}
+	return strings.Join(strs, ",")
+}
+
+func splitInts(s string) []int {
+	values := []int{}
+	for _, v := range splitRuleValues(s) {
+		if i, err := strconv.Atoi(v); err == nil {
+			values = append(values, i)
+		}
+	}
+	return values
+}
+
+// validatePorts checks ports such as 80,443,8000-8080, empty means any port
+func validatePorts(ports string) error {
+	for _, port := range splitRuleValues(ports) {
+		lower, upper, isRange := strings.Cut(port, "-")
+		min, err := strconv.Atoi(strings.TrimSpace(lower))
+		if err != nil || min < 0 || min > 65535 {
+			return fmt.Errorf("port (%s) invalid", port)
+		}
+		if !isRange {
+			continue
+		}
+		max, err := strconv.Atoi(strings.TrimSpace(upper))
+		if err != nil || max < min || max > 65535 {
+			return fmt.Errorf("port range (%s) invalid", port)
+		}
+	}
+	return nil
+}
+
+// validateIPs checks ips of ip group, and returns ip type of the group
+func validateIPs(ips []string) (int, error) {
+	if len(ips) == 0 {
+		return 0, errors.New("ips of ip group can not be empty")
+	}
+	ipType := 0
+	for _, ip := range ips {
+		t := RESOURCE_GROUP_IP_TYPE_SINGLE
+		if strings.Contains(ip, "/") {
+			if _, _, err := net.ParseCIDR(ip); err != nil {
+				return 0, fmt.Errorf("cidr (%s) invalid", ip)
+			}
+			t = RESOURCE_GROUP_IP_TYPE_CIDR
+		} else if start, end, isRange := strings.Cut(ip, "-"); isRange {
+			startIP, endIP := net.ParseIP(start), net.ParseIP(end)
+			if startIP == nil || endIP == nil || (startIP.To4() == nil) != (endIP.To4() == nil) {
+				return 0, fmt.Errorf("ip range (%s) invalid", ip)
+			}
+			if startIP.To4() != nil {
+				startIP, endIP = startIP.To4(), endIP.To4()
+			}
+			if string(startIP) > string(endIP) {
+				return 0, fmt.Errorf("ip range (%s) invalid", ip)
+			}
+			t = RESOURCE_GROUP_IP_TYPE_RANGE
+		} else if net.ParseIP(ip) == nil {
+			return 0, fmt.Errorf("ip (%s) invalid", ip)
+		}
+		if ipType != 0 && ipType != t {
+			ipType = RESOURCE_GROUP_IP_TYPE_MIX
+		} else {
+			ipType = t
+		}
+	}
+	return ipType, nil
+}
+
+// getOrgDomainLcuuids returns the subquery of domains of the org, vpcs and vms belong to the org of their domain
+func getOrgDomainLcuuids(orgID int) *gorm.DB {
+	return mysql.Db.Model(&mysql.Domain{}).Select("lcuuid").Where("org_id = ?", orgID)
+}
+
+func GetResourceGroups(filter map[string]interface{}) ([]model.ResourceGroup, error) {
+	var groups []mysql.ResourceGroup
+
+	Db := mysql.Db.Where(
+		"business_id IN (?) AND type IN (?)",
+		[]int{common.RESOURCE_GROUP_BUSINESS_ID_NPB, common.RESOURCE_GROUP_BUSINESS_ID_PCAP},
+		[]int{common.RESOURCE_GROUP_TYPE_NAMED_VM, common.RESOURCE_GROUP_TYPE_NAMED_IP},
+	)
+	for _, param := range []string{"lcuuid", "name", "business_id", "org_id"} {
+		if _, ok := filter[param]; ok {
+			Db = Db.Where(fmt.Sprintf("%s = ?", param), filter[param])
+		}
+	}
+	if err := Db.Order("id").Find(&groups).Error; err != nil {
+		return nil, NewError(httpcommon.SERVER_ERROR, fmt.Sprintf("get resource groups failed: %s", err))
+	}
+
+	response := make([]model.ResourceGroup, 0, len(groups))
+	for _, group := range groups {
+		response = append(response, model.ResourceGroup{
+			ID:         group.ID,
+			Name:       group.Name,
+			OrgID:      group.OrgID,
+			BusinessID: group.BusinessID,
+			Type:       group.Type,
+			VPCID:      group.VPCID,
+			IPs:        splitRuleValues(group.IPs),
+			VMIDs:      splitInts(group.VMIDs),
+			CreatedAt:  group.CreatedAt.Format(common.GO_BIRTHDAY),
+			UpdatedAt:  group.UpdatedAt.Format(common.GO_BIRTHDAY),
+			Lcuuid:     group.Lcuuid,
+		})
+	}
+	return response, nil
+}
+
+func getResourceGroup(orgID int, lcuuid string) (model.ResourceGroup, error) {
+	groups, err := GetResourceGroups(map[string]interface{}{"lcuuid": lcuuid, "org_id": orgID})
+	if err != nil {
+		return model.ResourceGroup{}, err
+	}
+	if len(groups) == 0 {
+		return model.ResourceGroup{}, NewError(httpcommon.RESOURCE_NOT_FOUND, fmt.Sprintf("resource group (%s) not found", lcuuid))
+	}
+	return groups[0], nil
+}
+
+// convertResourceGroup validates the group and converts it to the DB item, id and lcuuid are not set.
+func convertResourceGroup(groupCreate model.ResourceGroupCreate) (*mysql.ResourceGroup, error) {
+	if groupCreate.BusinessID == 0 {
+		groupCreate.BusinessID = common.RESOURCE_GROUP_BUSINESS_ID_NPB
+	}
+	if groupCreate.Type == 0 {
+		groupCreate.Type = common.RESOURCE_GROUP_TYPE_NAMED_IP
+	}
+	group := &mysql.ResourceGroup{
+		Name:       groupCreate.Name,
+		OrgID:      groupCreate.OrgID,
+		BusinessID: groupCreate.BusinessID,
+		Type:       groupCreate.Type,
+		VPCID:      groupCreate.VPCID,
+	}
+	if group.BusinessID != common.RESOURCE_GROUP_BUSINESS_ID_NPB && group.BusinessID != common.RESOURCE_GROUP_BUSINESS_ID_PCAP {
+		return nil, NewError(httpcommon.INVALID_PARAMETERS, fmt.Sprintf("business id (%d) invalid", group.BusinessID))
+	}
+
+	switch group.Type {
+	case common.RESOURCE_GROUP_TYPE_NAMED_IP:
+		ipType, err := validateIPs(groupCreate.IPs)
+		if err != nil {
+			return nil, NewError(httpcommon.INVALID_PARAMETERS, err.Error())
+		}
+		group.IPType = ipType
+		group.IPs = strings.Join(groupCreate.IPs, ",")
+	case common.RESOURCE_GROUP_TYPE_NAMED_VM:
+		var count int64
+		mysql.Db.Model(&mysql.VPC{}).Where("id = ? AND domain IN (?)", group.VPCID, getOrgDomainLcuuids(group.OrgID)).Count(&count)
+		if count == 0 {
+			return nil, NewError(httpcommon.RESOURCE_NOT_FOUND, fmt.Sprintf("vpc (%d) not found", group.VPCID))
+		}
+		if len(groupCreate.VMIDs) > 0 {
+			mysql.Db.Model(&mysql.VM{}).Where("id IN (?) AND epc_id = ?", groupCreate.VMIDs, group.VPCID).Count(&count)
+			if int(count) != len(groupCreate.VMIDs) {
+				return nil, NewError(httpcommon.RESOURCE_NOT_FOUND, fmt.Sprintf("vms (%v) not found in vpc (%d)", groupCreate.VMIDs, group.VPCID))
+			}
+		}
+		group.VMIDs = joinInts(groupCreate.VMIDs)
+	default:
+		return nil, NewError(httpcommon.INVALID_PARAMETERS, fmt.Sprintf("type (%d) invalid", group.Type))
+	}
+	return group, nil
+}
+
+func CreateResourceGroup(groupCreate model.ResourceGroupCreate) (model.ResourceGroup, error) {
+	var count int64
+	mysql.Db.Model(&mysql.ResourceGroup{}).Where("name = ?", groupCreate.Name).Count(&count)
+	if count > 0 {
+		return model.ResourceGroup{}, NewError(httpcommon.RESOURCE_ALREADY_EXIST, fmt.Sprintf("resource group (%s) already exist", groupCreate.Name))
+	}
+	group, err := convertResourceGroup(groupCreate)
+	if err != nil {
+		return model.ResourceGroup{}, err
+	}
+	group.Lcuuid = uuid.New().String()
+	if err := mysql.Db.Create(group).Error; err != nil {
+		return model.ResourceGroup{}, NewError(httpcommon.SERVER_ERROR, fmt.Sprintf("create resource group (%s) failed: %s", group.Name, err))
+	}
+	log.Infof("create resource group (%s)", group.Name)
+	refresh.RefreshCache([]common.DataChanged{common.DATA_CHANGED_GROUP})
+	return getResourceGroup(group.OrgID, group.Lcuuid)
+}
+
+// UpdateResourceGroup only updates the fields in the patch, business id of a group can not be changed.
+func UpdateResourceGroup(orgID int, lcuuid string, patch map[string]interface{}) (model.ResourceGroup, error) {
+	old, err := getResourceGroup(orgID, lcuuid)
+	if err != nil {
+		return model.ResourceGroup{}, err
+	}
+	groupUpdate := model.ResourceGroupCreate{
+		Name:       old.Name,
+		BusinessID: old.BusinessID,
+		Type:       old.Type,
+		VPCID:      old.VPCID,
+		IPs:        old.IPs,
+		VMIDs:      old.VMIDs,
+		OrgID:      orgID,
+	}
+	patchBytes, _ := json.Marshal(patch)
+	if err := json.Unmarshal(patchBytes, &groupUpdate); err != nil {
+		return model.ResourceGroup{}, NewError(httpcommon.INVALID_PARAMETERS, err.Error())
+	}
+	if groupUpdate.BusinessID != old.BusinessID {
+		return model.ResourceGroup{}, NewError(httpcommon.INVALID_PARAMETERS, "business id of resource group can not be changed")
+	}
+	if groupUpdate.Name != old.Name {
+		var count int64
+		mysql.Db.Model(&mysql.ResourceGroup{}).Where("name = ?", groupUpdate.Name).Count(&count)
+		if count > 0 {
+			return model.ResourceGroup{}, NewError(httpcommon.RESOURCE_ALREADY_EXIST, fmt.Sprintf("resource group (%s) already exist", groupUpdate.Name))
+		}
+	}
+	group, err := convertResourceGroup(groupUpdate)
+	if err != nil {
+		return model.ResourceGroup{}, err
+	}
+
+	log.Infof("update resource group (%s) config %v", old.Name, patch)
+	dbUpdateMap := map[string]interface{}{
+		"name":    group.Name,
+		"type":    group.Type,
+		"epc_id":  group.VPCID,
+		"ip_type": group.IPType,
+		"ips":     group.IPs,
+		"vm_ids":  group.VMIDs,
+	}
+	if err := mysql.Db.Model(&mysql.ResourceGroup{}).Where("lcuuid = ? AND org_id = ?", lcuuid, orgID).Updates(dbUpdateMap).Error; err != nil {
+		return model.ResourceGroup{}, NewError(httpcommon.SERVER_ERROR, fmt.Sprintf("update resource group (%s) failed: %s", old.Name, err))
+	}
+	refresh.RefreshCache([]common.DataChanged{common.DATA_CHANGED_GROUP, common.DATA_CHANGED_FLOW_ACL})
+	return getResourceGroup(orgID, lcuuid)
+}
+
+func DeleteResourceGroup(orgID int, lcuuid string) (map[string]string, error) {
+	group, err := getResourceGroup(orgID, lcuuid)
+	if err != nil {
+		return nil, err
+	}
+	var acls []mysql.ACL
+	mysql.Db.Select("name", "src_group_ids", "dst_group_ids").Find(&acls)
+	for _, acl := range acls {
+		for _, groupID := range append(splitInts(acl.SrcGroupIDs), splitInts(acl.DstGroupIDs)...) {
+			if groupID == group.ID {
+				return nil, NewError(httpcommon.INVALID_PARAMETERS, fmt.Sprintf("resource group (%s) is used by acl (%s)", group.Name, acl.Name))
+			}
+		}
+	}
+
+	log.Infof("delete resource group (%s)", group.Name)
+	if err := mysql.Db.Where("lcuuid = ? AND org_id = ?", lcuuid, orgID).Delete(&mysql.ResourceGroup{}).Error; err != nil {
+		return nil, NewError(httpcommon.SERVER_ERROR, fmt.Sprintf("delete resource group (%s) failed: %s", group.Name, err))
+	}
+	refresh.RefreshCache([]common.DataChanged{common.DATA_CHANGED_GROUP})
+	return map[string]string{"LCUUID": lcuuid}, nil
+}
+
+func GetACLs(filter map[string]interface{}) ([]model.ACL, error) {
+	var acls []mysql.ACL
+
+	Db := mysql.Db.Where("type = ?", common.ACL_TYPE_CUSTOM)
+	for _, param := range []string{"lcuuid", "name", "org_id"} {
+		if _, ok := filter[param]; ok {
+			Db = Db.Where(fmt.Sprintf("%s = ?", param), filter[param])
+		}
+	}
+	if _, ok := filter["application"]; ok {
+		Db = Db.Where("applications = ?", filter["application"])
+	}
+	if err := Db.Order("id").Find(&acls).Error; err != nil {
+		return nil, NewError(httpcommon.SERVER_ERROR, fmt.Sprintf("get acls failed: %s", err))
+	}
+
+	response := make([]model.ACL, 0, len(acls))
+	for _, acl := range acls {
+		application, _ := strconv.Atoi(acl.Applications)
+		response = append(response, model.ACL{
+			ID:          acl.ID,
+			Name:        acl.Name,
+			OrgID:       acl.OrgID,
+			State:       acl.State,
+			Application: application,
+			TapType:     acl.TapType,
+			SrcGroupIDs: splitInts(acl.SrcGroupIDs),
+			DstGroupIDs: splitInts(acl.DstGroupIDs),
+			Protocol:    acl.Protocol,
+			SrcPorts:    acl.SrcPorts,
+			DstPorts:    acl.DstPorts,
+			Vlan:        acl.Vlan,
+			CreatedAt:   acl.CreatedAt.Format(common.GO_BIRTHDAY),
+			UpdatedAt:   acl.UpdatedAt.Format(common.GO_BIRTHDAY),
+			Lcuuid:      acl.Lcuuid,
+		})
+	}
+	return response, nil
+}
+
+func getACL(orgID int, lcuuid string) (model.ACL, error) {
+	acls, err := GetACLs(map[string]interface{}{"lcuuid": lcuuid, "org_id": orgID})
+	if err != nil {
+		return model.ACL{}, err
+	}
+	if len(acls) == 0 {
+		return model.ACL{}, NewError(httpcommon.RESOURCE_NOT_FOUND, fmt.Sprintf("acl (%s) not found", lcuuid))
+	}
+	return acls[0], nil
+}
+
+// convertACL validates the acl and converts it to the DB item, id and lcuuid are not set.
+func convertACL(aclCreate model.ACLCreate) (*mysql.ACL, error) {
+	businessID, ok := aclApplicationToGroupBusinessID[aclCreate.Application]
+	if !ok {
+		return nil, NewError(httpcommon.INVALID_PARAMETERS, fmt.Sprintf("application (%d) invalid", aclCreate.Application))
+	}
+	if aclCreate.TapType == 0 {
+		aclCreate.TapType = ACL_DEFAULT_TAP_TYPE
+	}
+	var count int64
+	mysql.Db.Model(&mysql.TapType{}).Where("value = ?", aclCreate.TapType).Count(&count)
+	if count == 0 {
+		return nil, NewError(httpcommon.RESOURCE_NOT_FOUND, fmt.Sprintf("tap type (%d) not found", aclCreate.TapType))
+	}
+	for _, groupIDs := range [][]int{aclCreate.SrcGroupIDs, aclCreate.DstGroupIDs} {
+		if len(groupIDs) == 0 {
+			continue
+		}
+		var groups []mysql.ResourceGroup
+		mysql.Db.Select("id").Where("id IN (?) AND business_id = ? AND org_id = ?", groupIDs, businessID, aclCreate.OrgID).Find(&groups)
+		if len(groups) != len(groupIDs) {
+			return nil, NewError(
+				httpcommon.RESOURCE_NOT_FOUND,
+				fmt.Sprintf("resource groups (%v) not found in business (%d) of application (%d)", groupIDs, businessID, aclCreate.Application),
+			)
+		}
+	}
+	if aclCreate.Protocol != nil && (*aclCreate.Protocol < 0 || *aclCreate.Protocol > 255) {
+		return nil, NewError(httpcommon.INVALID_PARAMETERS, fmt.Sprintf("protocol (%d) invalid", *aclCreate.Protocol))
+	}
+	for _, ports := range []string{aclCreate.SrcPorts, aclCreate.DstPorts} {
+		if err := validatePorts(ports); err != nil {
+			return nil, NewError(httpcommon.INVALID_PARAMETERS, err.Error())
+		}
+	}
+	if aclCreate.Vlan < 0 || aclCreate.Vlan > 4095 {
+		return nil, NewError(httpcommon.INVALID_PARAMETERS, fmt.Sprintf("vlan (%d) invalid", aclCreate.Vlan))
+	}
+
+	acl := &mysql.ACL{
+		BusinessID:   businessID,
+		Name:         aclCreate.Name,
+		OrgID:        aclCreate.OrgID,
+		Type:         common.ACL_TYPE_CUSTOM,
+		TapType:      aclCreate.TapType,
+		State:        common.ACL_STATE_ENABLE,
+		Applications: strconv.Itoa(aclCreate.Application),
+		SrcGroupIDs:  joinInts(aclCreate.SrcGroupIDs),
+		DstGroupIDs:  joinInts(aclCreate.DstGroupIDs),
+		Protocol:     aclCreate.Protocol,
+		SrcPorts:     strings.Join(splitRuleValues(aclCreate.SrcPorts), ","),
+		DstPorts:     strings.Join(splitRuleValues(aclCreate.DstPorts), ","),
+		Vlan:         aclCreate.Vlan,
+	}
+	if aclCreate.State != nil {
+		if *aclCreate.State != common.ACL_STATE_DISABLE && *aclCreate.State != common.ACL_STATE_ENABLE {
+			return nil, NewError(httpcommon.INVALID_PARAMETERS, fmt.Sprintf("state (%d) invalid", *aclCreate.State))
+		}
+		acl.State = *aclCreate.State
+	}
+	return acl, nil
+}
+
+func CreateACL(aclCreate model.ACLCreate) (model.ACL, error) {
+	var count int64
+	mysql.Db.Model(&mysql.ACL{}).Where("name = ?", aclCreate.Name).Count(&count)
+	if count > 0 {
+		return model.ACL{}, NewError(httpcommon.RESOURCE_ALREADY_EXIST, fmt.Sprintf("acl (%s) already exist", aclCreate.Name))
+	}
+	acl, err := convertACL(aclCreate)
+	if err != nil {
+		return model.ACL{}, err
+	}
+	acl.Lcuuid = uuid.New().String()
+	if err := mysql.Db.Create(acl).Error; err != nil {
+		return model.ACL{}, NewError(httpcommon.SERVER_ERROR, fmt.Sprintf("create acl (%s) failed: %s", acl.Name, err))
+	}
+	// zero value of state is not inserted by gorm, as state has a default value in DB
+	if acl.State == common.ACL_STATE_DISABLE {
+		mysql.Db.Model(acl).Update("state", acl.State)
+	}
+	log.Infof("create acl (%s)", acl.Name)
+	refresh.RefreshCache([]common.DataChanged{common.DATA_CHANGED_FLOW_ACL})
+	return getACL(acl.OrgID, acl.Lcuuid)
+}
+
+// UpdateACL only updates the fields in the patch, application of an acl used by policies can not be changed.
+func UpdateACL(orgID int, lcuuid string, patch map[string]interface{}) (model.ACL, error) {
+	old, err := getACL(orgID, lcuuid)
+	if err != nil {
+		return model.ACL{}, err
+	}
+	aclUpdate := model.ACLCreate{
+		Name:        old.Name,
+		State:       &old.State,
+		Application: old.Application,
+		TapType:     old.TapType,
+		SrcGroupIDs: old.SrcGroupIDs,
+		DstGroupIDs: old.DstGroupIDs,
+		Protocol:    old.Protocol,
+		SrcPorts:    old.SrcPorts,
+		DstPorts:    old.DstPorts,
+		Vlan:        old.Vlan,
+		OrgID:       orgID,
+	}
+	patchBytes, _ := json.Marshal(patch)
+	if err := json.Unmarshal(patchBytes, &aclUpdate); err != nil {
+		return model.ACL{}, NewError(httpcommon.INVALID_PARAMETERS, err.Error())
+	}
+	if aclUpdate.Name != old.Name {
+		var count int64
+		mysql.Db.Model(&mysql.ACL{}).Where("name = ?", aclUpdate.Name).Count(&count)
+		if count > 0 {
+			return model.ACL{}, NewError(httpcommon.RESOURCE_ALREADY_EXIST, fmt.Sprintf("acl (%s) already exist", aclUpdate.Name))
+		}
+	}
+	if aclUpdate.Application != old.Application {
+		if policyName := getACLPolicyName(old.ID); policyName != "" {
+			return model.ACL{}, NewError(httpcommon.INVALID_PARAMETERS, fmt.Sprintf("application of acl (%s) used by policy (%s) can not be changed", old.Name, policyName))
+		}
+	}
+	acl, err := convertACL(aclUpdate)
+	if err != nil {
+		return model.ACL{}, err
+	}
+
+	log.Infof("update acl (%s) config %v", old.Name, patch)
+	dbUpdateMap := map[string]interface{}{
+		"name":          acl.Name,
+		"state":         acl.State,
+		"business_id":   acl.BusinessID,
+		"applications":  acl.Applications,
+		"tap_type":      acl.TapType,
+		"src_group_ids": acl.SrcGroupIDs,
+		"dst_group_ids": acl.DstGroupIDs,
+		"protocol":      acl.Protocol,
+		"src_ports":     acl.SrcPorts,
+		"dst_ports":     acl.DstPorts,
+		"vlan":          acl.Vlan,
+	}
+	if err := mysql.Db.Model(&mysql.ACL{}).Where("lcuuid = ? AND org_id = ?", lcuuid, orgID).Updates(dbUpdateMap).Error; err != nil {
+		return model.ACL{}, NewError(httpcommon.SERVER_ERROR, fmt.Sprintf("update acl (%s) failed: %s", old.Name, err))
+	}
+	refresh.RefreshCache([]common.DataChanged{common.DATA_CHANGED_FLOW_ACL})
+	return getACL(orgID, lcuuid)
+}
+
+// getACLPolicyName returns name of a npb or pcap policy using the acl, empty if not used
+func getACLPolicyName(aclID int) string {
+	var npbPolicy mysql.NpbPolicy
+	if mysql.Db.Select("name").Where("acl_id = ?", aclID).Limit(1).Find(&npbPolicy).RowsAffected > 0 {
+		return npbPolicy.Name
+	}
+	var pcapPolicy mysql.PcapPolicy
+	if mysql.Db.Select("name").Where("acl_id = ?", aclID).Limit(1).Find(&pcapPolicy).RowsAffected > 0 {
+		return pcapPolicy.Name
+	}
+	return ""
+}
+
+func DeleteACL(orgID int, lcuuid string) (map[string]string, error) {
+	acl, err := getACL(orgID, lcuuid)
+	if err != nil {
+		return nil, err
+	}
+	if policyName := getACLPolicyName(acl.ID); policyName != "" {
+		return nil, NewError(httpcommon.INVALID_PARAMETERS, fmt.Sprintf("acl (%s) is used by policy (%s)", acl.Name, policyName))
+	}
+
+	log.Infof("delete acl (%s)", acl.Name)
+	if err := mysql.Db.Where("lcuuid = ? AND org_id = ?", lcuuid, orgID).Delete(&mysql.ACL{}).Error; err != nil {
+		return nil, NewError(httpcommon.SERVER_ERROR, fmt.Sprintf("delete acl (%s) failed: %s", acl.Name, err))
+	}
+	refresh.RefreshCache([]common.DataChanged{common.DATA_CHANGED_FLOW_ACL})
+	return map[string]string{"LCUUID": lcuuid}, nil
+}
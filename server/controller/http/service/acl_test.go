@@ -0,0 +1,68 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import "testing"
+
+func TestValidatePorts(t *testing.T) {
+	tests := []struct {
+		name    string
+		ports   string
+		wantErr bool
+	}{
+		{"any", "", false},
+		{"single and range", "80, 443,8000-8080", false},
+		{"max port", "65535", false},
+		{"out of range", "65536", true},
+		{"reversed range", "8080-8000", true},
+		{"not a number", "http", true},
+	}
+	for _, tt := range tests {
+		if err := validatePorts(tt.ports); (err != nil) != tt.wantErr {
+			t.Errorf("%s: validatePorts() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestValidateIPs(t *testing.T) {
+	tests := []struct {
+		name       string
+		ips        []string
+		wantIPType int
+		wantErr    bool
+	}{
+		{"empty", nil, 0, true},
+		{"single", []string{"10.1.1.1", "fd00::1"}, RESOURCE_GROUP_IP_TYPE_SINGLE, false},
+		{"cidr", []string{"10.0.0.0/8"}, RESOURCE_GROUP_IP_TYPE_CIDR, false},
+		{"range", []string{"10.0.0.1-10.0.0.9"}, RESOURCE_GROUP_IP_TYPE_RANGE, false},
+		{"mix", []string{"10.0.0.1", "10.0.0.0/8"}, RESOURCE_GROUP_IP_TYPE_MIX, false},
+		{"bad cidr", []string{"10.0.0.0/33"}, 0, true},
+		{"reversed range", []string{"10.0.0.9-10.0.0.1"}, 0, true},
+		{"mixed family range", []string{"10.0.0.1-fd00::1"}, 0, true},
+		{"bad ip", []string{"10.0.0.256"}, 0, true},
+	}
+	for _, tt := range tests {
+		ipType, err := validateIPs(tt.ips)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: validateIPs() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if ipType != tt.wantIPType {
+			t.Errorf("%s: validateIPs() = %d, want %d", tt.name, ipType, tt.wantIPType)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/deepflowio/deepflow/server/controller/common"
+	"github.com/deepflowio/deepflow/server/controller/db/mysql"
+	"github.com/deepflowio/deepflow/server/controller/http/auth"
+	httpcommon "github.com/deepflowio/deepflow/server/controller/http/common"
+	. "github.com/deepflowio/deepflow/server/controller/http/service/common"
+	"github.com/deepflowio/deepflow/server/controller/model"
+)
+
+func GetAPIKeys(filter map[string]interface{}) (resp []model.APIKey, err error) {
+	var apiKeys []mysql.APIKey
+
+	Db := mysql.Db
+	for _, param := range []string{"lcuuid", "name", "role", "org_id"} {
+		if _, ok := filter[param]; ok {
+			Db = Db.Where(fmt.Sprintf("%s = ?", param), filter[param])
+		}
+	}
+	if err := Db.Order("created_at DESC").Find(&apiKeys).Error; err != nil {
+		return nil, NewError(httpcommon.SERVER_ERROR, fmt.Sprintf("get api keys failed: %s", err))
+	}
+
+	response := make([]model.APIKey, 0, len(apiKeys))
+	for _, apiKey := range apiKeys {
+		apiKeyResp := model.APIKey{
+			ID:        apiKey.ID,
+			Name:      apiKey.Name,
+			Role:      apiKey.Role,
+			OrgID:     apiKey.OrgID,
+			KeyPrefix: apiKey.KeyPrefix,
+			CreatedAt: apiKey.CreatedAt.Format(common.GO_BIRTHDAY),
+			Lcuuid:    apiKey.Lcuuid,
+		}
+		if apiKey.ExpiredAt != nil {
+			apiKeyResp.ExpiredAt = apiKey.ExpiredAt.Format(common.GO_BIRTHDAY)
+		}
+		response = append(response, apiKeyResp)
+	}
+	return response, nil
+}
+
+// CreateAPIKey returns the plaintext key in the response, it cannot be retrieved again.
+// The caller is nil if authentication is disabled, otherwise the key can not have a role higher
+// than the caller, and only admins of the default org can create keys of other orgs.
+func CreateAPIKey(apiKeyCreate model.APIKeyCreate, caller *auth.Identity) (model.APIKey, error) {
+	role, ok := auth.ParseRole(apiKeyCreate.Role)
+	if !ok {
+		return model.APIKey{}, NewError(httpcommon.INVALID_PARAMETERS, fmt.Sprintf("role (%s) invalid, should be viewer, operator or admin", apiKeyCreate.Role))
+	}
+	if apiKeyCreate.OrgID == 0 {
+		apiKeyCreate.OrgID = common.DEFAULT_ORG_ID
+		if caller != nil {
+			apiKeyCreate.OrgID = caller.GetOrgID()
+		}
+	}
+	if apiKeyCreate.OrgID < 0 || apiKeyCreate.OrgID > common.MAX_ORG_ID {
+		return model.APIKey{}, NewError(httpcommon.INVALID_PARAMETERS, fmt.Sprintf("org_id (%d) invalid", apiKeyCreate.OrgID))
+	}
+	if caller != nil {
+		if !caller.Role.Covers(role) {
+			return model.APIKey{}, NewError(httpcommon.PARAMETER_ILLEGAL, fmt.Sprintf("role (%s) is higher than the role (%s) of %s", role, caller.Role, caller.Name))
+		}
+		if apiKeyCreate.OrgID != caller.GetOrgID() && !caller.IsDefaultOrgAdmin() {
+			return model.APIKey{}, NewError(httpcommon.PARAMETER_ILLEGAL, fmt.Sprintf("org_id (%d) is not the org of %s", apiKeyCreate.OrgID, caller.Name))
+		}
+	}
+	var count int64
+	mysql.Db.Model(&mysql.APIKey{}).Where("name = ?", apiKeyCreate.Name).Count(&count)
+	if count > 0 {
+		return model.APIKey{}, NewError(httpcommon.RESOURCE_ALREADY_EXIST, fmt.Sprintf("api key (%s) already exist", apiKeyCreate.Name))
+	}
+
+	key, keyHash, err := auth.GenerateAPIKey()
+	if err != nil {
+		return model.APIKey{}, NewError(httpcommon.SERVER_ERROR, fmt.Sprintf("generate api key failed: %s", err))
+	}
+	apiKey := mysql.APIKey{
+		Name:      apiKeyCreate.Name,
+		Role:      apiKeyCreate.Role,
+		OrgID:     apiKeyCreate.OrgID,
+		KeyHash:   keyHash,
+		KeyPrefix: key[:auth.API_KEY_DISPLAY_LEN],
+		Lcuuid:    uuid.New().String(),
+	}
+	if apiKeyCreate.ExpiredAt != "" {
+		expiredAt, err := time.ParseInLocation(common.GO_BIRTHDAY, apiKeyCreate.ExpiredAt, time.Local)
+		if err != nil {
+			return model.APIKey{}, NewError(httpcommon.INVALID_PARAMETERS, fmt.Sprintf("expired_at (%s) invalid: %s", apiKeyCreate.ExpiredAt, err))
+		}
+		apiKey.ExpiredAt = &expiredAt
+	}
+	if err := mysql.Db.Create(&apiKey).Error; err != nil {
+		return model.APIKey{}, NewError(httpcommon.SERVER_ERROR, fmt.Sprintf("create api key (%s) failed: %s", apiKey.Name, err))
+	}
+	log.Infof("create api key (%s) with role (%s)", apiKey.Name, apiKey.Role)
+
+	response, err := GetAPIKeys(map[string]interface{}{"lcuuid": apiKey.Lcuuid})
+	if err != nil || len(response) == 0 {
+		return model.APIKey{}, NewError(httpcommon.SERVER_ERROR, fmt.Sprintf("get api key (%s) failed", apiKey.Name))
+	}
+	response[0].Key = key
+	return response[0], nil
+}
+
+// DeleteAPIKey only deletes keys of the org of the caller, unless the caller is an admin of the default org.
+func DeleteAPIKey(lcuuid string, caller *auth.Identity) (map[string]string, error) {
+	var apiKey mysql.APIKey
+	if ret := mysql.Db.Where("lcuuid = ?", lcuuid).First(&apiKey); ret.Error != nil {
+		return nil, NewError(httpcommon.RESOURCE_NOT_FOUND, fmt.Sprintf("api key (%s) not found", lcuuid))
+	}
+	if caller != nil && !caller.IsDefaultOrgAdmin() && apiKey.OrgID != caller.GetOrgID() {
+		return nil, NewError(httpcommon.RESOURCE_NOT_FOUND, fmt.Sprintf("api key (%s) not found", lcuuid))
+	}
+
+	log.Infof("delete api key (%s)", apiKey.Name)
+	if err := mysql.Db.Delete(&apiKey).Error; err != nil {
+		return nil, NewError(httpcommon.SERVER_ERROR, fmt.Sprintf("delete api key (%s) failed: %s", apiKey.Name, err))
+	}
+	return map[string]string{"LCUUID": lcuuid}, nil
+}
@@ -0,0 +1,96 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/deepflowio/deepflow/server/controller/common"
+	"github.com/deepflowio/deepflow/server/controller/db/mysql"
+	httpcommon "github.com/deepflowio/deepflow/server/controller/http/common"
+	. "github.com/deepflowio/deepflow/server/controller/http/service/common"
+	"github.com/deepflowio/deepflow/server/controller/model"
+)
+
+const (
+	DEFAULT_AUDIT_LOG_LIMIT = 100
+	MAX_AUDIT_LOG_LIMIT     = 10000
+)
+
+func GetAuditLogs(filter map[string]interface{}) (resp []model.AuditLog, err error) {
+	var auditLogs []mysql.AuditLog
+
+	Db := mysql.Db
+	for _, param := range []string{"actor", "method", "route", "resource_type", "resource_lcuuid", "result", "org_id"} {
+		if _, ok := filter[param]; ok {
+			Db = Db.Where(fmt.Sprintf("%s = ?", param), filter[param])
+		}
+	}
+	if _, ok := filter["start_time"]; ok {
+		Db = Db.Where("created_at >= ?", filter["start_time"])
+	}
+	if _, ok := filter["end_time"]; ok {
+		Db = Db.Where("created_at <= ?", filter["end_time"])
+	}
+	limit := DEFAULT_AUDIT_LOG_LIMIT
+	if value, ok := filter["limit"]; ok {
+		limit, err = strconv.Atoi(fmt.Sprint(value))
+		if err != nil || limit <= 0 || limit > MAX_AUDIT_LOG_LIMIT {
+			return nil, NewError(httpcommon.INVALID_PARAMETERS, fmt.Sprintf("limit (%v) invalid, should be in (0, %d]", value, MAX_AUDIT_LOG_LIMIT))
+		}
+	}
+	if err := Db.Order("id DESC").Limit(limit).Find(&auditLogs).Error; err != nil {
+		return nil, NewError(httpcommon.SERVER_ERROR, fmt.Sprintf("get audit logs failed: %s", err))
+	}
+
+	response := make([]model.AuditLog, 0, len(auditLogs))
+	for _, auditLog := range auditLogs {
+		response = append(response, model.AuditLog{
+			ID:             auditLog.ID,
+			Actor:          auditLog.Actor,
+			AuthMethod:     auditLog.AuthMethod,
+			OrgID:          auditLog.OrgID,
+			ClientIP:       auditLog.ClientIP,
+			Method:         auditLog.Method,
+			Route:          auditLog.Route,
+			Path:           auditLog.Path,
+			ResourceType:   auditLog.ResourceType,
+			ResourceLcuuid: auditLog.ResourceLcuuid,
+			RequestBody:    unmarshalAuditJSON(auditLog.RequestBody),
+			Diff:           unmarshalAuditJSON(auditLog.Diff),
+			StatusCode:     auditLog.StatusCode,
+			Result:         auditLog.Result,
+			Description:    auditLog.Description,
+			CreatedAt:      auditLog.CreatedAt.Format(common.GO_BIRTHDAY),
+		})
+	}
+	return response, nil
+}
+
+// unmarshalAuditJSON returns the json columns as objects, truncated ones are returned as strings.
+func unmarshalAuditJSON(value string) interface{} {
+	if value == "" {
+		return nil
+	}
+	var object interface{}
+	if err := json.Unmarshal([]byte(value), &object); err != nil {
+		return value
+	}
+	return object
+}
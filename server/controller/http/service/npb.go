@@ -0,0 +1,609 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"strconv"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/deepflowio/deepflow/server/controller/common"
+	"github.com/deepflowio/deepflow/server/controller/db/mysql"
+	httpcommon "github.com/deepflowio/deepflow/server/controller/http/common"
+	. "github.com/deepflowio/deepflow/server/controller/http/service/common"
+	"github.com/deepflowio/deepflow/server/controller/model"
+	"github.com/deepflowio/deepflow/server/controller/trisolaris/refresh"
+)
+
+func GetNpbTunnels(filter map[string]interface{}) ([]model.NpbTunnel, error) {
+	var tunnels []mysql.NpbTunnel
+
+	Db := mysql.Db
+	for _, param := range []string{"lcuuid", "name", "org_id"} {
+		if _, ok := filter[param]; ok {
+			Db = Db.Where(fmt.Sprintf("%s = ?", param), filter[param])
+		}
+	}
+	if err := Db.Order("id").Find(&tunnels).Error; err != nil {
+		return nil, NewError(httpcommon.SERVER_ERROR, fmt.Sprintf("get npb tunnels failed: %s", err))
+	}
+
+	response := make([]model.NpbTunnel, 0, len(tunnels))
+	for _, tunnel := range tunnels {
+		response = append(response, model.NpbTunnel{
+			ID:        tunnel.ID,
+			Name:      tunnel.Name,
+			OrgID:     tunnel.OrgID,
+			IP:        tunnel.IP,
+			Type:      tunnel.Type,
+			CreatedAt: tunnel.CreatedAt.Format(common.GO_BIRTHDAY),
+			UpdatedAt: tunnel.UpdatedAt.Format(common.GO_BIRTHDAY),
+			Lcuuid:    tunnel.Lcuuid,
+		})
+	}
+	return response, nil
+}
+
+func getNpbTunnel(orgID int, lcuuid string) (model.NpbTunnel, error) {
+	tunnels, err := GetNpbTunnels(map[string]interface{}{"lcuuid": lcuuid, "org_id": orgID})
+	if err != nil {
+		return model.NpbTunnel{}, err
+	}
+	if len(tunnels) == 0 {
+		return model.NpbTunnel{}, NewError(httpcommon.RESOURCE_NOT_FOUND, fmt.Sprintf("npb tunnel (%s) not found", lcuuid))
+	}
+	return tunnels[0], nil
+}
+
+func validateNpbTunnel(tunnelCreate model.NpbTunnelCreate) error {
+	if net.ParseIP(tunnelCreate.IP) == nil {
+		return NewError(httpcommon.INVALID_PARAMETERS, fmt.Sprintf("ip (%s) invalid", tunnelCreate.IP))
+	}
+	if tunnelCreate.Type != common.NPB_TUNNEL_TYPE_VXLAN && tunnelCreate.Type != common.NPB_TUNNEL_TYPE_ERSPAN {
+		return NewError(httpcommon.INVALID_PARAMETERS, fmt.Sprintf("type (%d) invalid", tunnelCreate.Type))
+	}
+	return nil
+}
+
+func CreateNpbTunnel(tunnelCreate model.NpbTunnelCreate) (model.NpbTunnel, error) {
+	var count int64
+	mysql.Db.Model(&mysql.NpbTunnel{}).Where("name = ?", tunnelCreate.Name).Count(&count)
+	if count > 0 {
+		return model.NpbTunnel{}, NewError(httpcommon.RESOURCE_ALREADY_EXIST, fmt.Sprintf("npb tunnel (%s) already exist", tunnelCreate.Name))
+	}
+	if err := validateNpbTunnel(tunnelCreate); err != nil {
+		return model.NpbTunnel{}, err
+	}
+	tunnel := mysql.NpbTunnel{
+		Name:   tunnelCreate.Name,
+		OrgID:  tunnelCreate.OrgID,
+		IP:     tunnelCreate.IP,
+		Type:   tunnelCreate.Type,
+		Lcuuid: uuid.New().String(),
+	}
+	if err := mysql.Db.Create(&tunnel).Error; err != nil {
+		return model.NpbTunnel{}, NewError(httpcommon.SERVER_ERROR, fmt.Sprintf("create npb tunnel (%s) failed: %s", tunnel.Name, err))
+	}
+	log.Infof("create npb tunnel (%s)", tunnel.Name)
+	return getNpbTunnel(tunnel.OrgID, tunnel.Lcuuid)
+}
+
+// UpdateNpbTunnel only updates the fields in the patch, vni of the policies using the tunnel
+// should be valid for the new tunnel type.
+func UpdateNpbTunnel(orgID int, lcuuid string, patch map[string]interface{}) (model.NpbTunnel, error) {
+	old, err := getNpbTunnel(orgID, lcuuid)
+	if err != nil {
+		return model.NpbTunnel{}, err
+	}
+	tunnelUpdate := model.NpbTunnelCreate{
+		Name: old.Name,
+		IP:   old.IP,
+		Type: old.Type,
+	}
+	patchBytes, _ := json.Marshal(patch)
+	if err := json.Unmarshal(patchBytes, &tunnelUpdate); err != nil {
+		return model.NpbTunnel{}, NewError(httpcommon.INVALID_PARAMETERS, err.Error())
+	}
+	if tunnelUpdate.Name != old.Name {
+		var count int64
+		mysql.Db.Model(&mysql.NpbTunnel{}).Where("name = ?", tunnelUpdate.Name).Count(&count)
+		if count > 0 {
+			return model.NpbTunnel{}, NewError(httpcommon.RESOURCE_ALREADY_EXIST, fmt.Sprintf("npb tunnel (%s) already exist", tunnelUpdate.Name))
+		}
+	}
+	if err := validateNpbTunnel(tunnelUpdate); err != nil {
+		return model.NpbTunnel{}, err
+	}
+	if tunnelUpdate.Type != old.Type {
+		var policies []mysql.NpbPolicy
+		mysql.Db.Select("name", "vni").Where("npb_tunnel_id = ?", old.ID).Find(&policies)
+		for _, policy := range policies {
+			if err := validateVni(tunnelUpdate.Type, policy.Vni); err != nil {
+				return model.NpbTunnel{}, NewError(httpcommon.INVALID_PARAMETERS, fmt.Sprintf("npb policy (%s) %s", policy.Name, err))
+			}
+		}
+	}
+
+	log.Infof("update npb tunnel (%s) config %v", old.Name, patch)
+	dbUpdateMap := map[string]interface{}{
+		"name": tunnelUpdate.Name,
+		"ip":   tunnelUpdate.IP,
+		"type": tunnelUpdate.Type,
+	}
+	if err := mysql.Db.Model(&mysql.NpbTunnel{}).Where("lcuuid = ? AND org_id = ?", lcuuid, orgID).Updates(dbUpdateMap).Error; err != nil {
+		return model.NpbTunnel{}, NewError(httpcommon.SERVER_ERROR, fmt.Sprintf("update npb tunnel (%s) failed: %s", old.Name, err))
+	}
+	refresh.RefreshCache([]common.DataChanged{common.DATA_CHANGED_FLOW_ACL})
+	return getNpbTunnel(orgID, lcuuid)
+}
+
+func DeleteNpbTunnel(orgID int, lcuuid string) (map[string]string, error) {
+	tunnel, err := getNpbTunnel(orgID, lcuuid)
+	if err != nil {
+		return nil, err
+	}
+	var policy mysql.NpbPolicy
+	if mysql.Db.Select("name").Where("npb_tunnel_id = ?", tunnel.ID).Limit(1).Find(&policy).RowsAffected > 0 {
+		return nil, NewError(httpcommon.INVALID_PARAMETERS, fmt.Sprintf("npb tunnel (%s) is used by npb policy (%s)", tunnel.Name, policy.Name))
+	}
+
+	log.Infof("delete npb tunnel (%s)", tunnel.Name)
+	if err := mysql.Db.Where("lcuuid = ? AND org_id = ?", lcuuid, orgID).Delete(&mysql.NpbTunnel{}).Error; err != nil {
+		return nil, NewError(httpcommon.SERVER_ERROR, fmt.Sprintf("delete npb tunnel (%s) failed: %s", tunnel.Name, err))
+	}
+	return map[string]string{"LCUUID": lcuuid}, nil
+}
+
+// validateVni checks vni of VXLAN tunnel or session id of ERSPAN tunnel
+func validateVni(tunnelType, vni int) error {
+	max := common.NPB_VXLAN_VNI_MAX
+	if tunnelType == common.NPB_TUNNEL_TYPE_ERSPAN {
+		max = common.NPB_ERSPAN_SESSION_ID_MAX
+	}
+	if vni < 0 || vni > max {
+		return fmt.Errorf("vni (%d) invalid, should be in [0, %d]", vni, max)
+	}
+	return nil
+}
+
+// validatePolicyCommon checks the attributes shared by npb and pcap policies, the acl and vtaps
+// should belong to the org of the policy.
+func validatePolicyCommon(orgID, aclID, application int, state, payloadSlice *int, vtapIDs []int) error {
+	var acl mysql.ACL
+	if mysql.Db.Where("id = ? AND type = ? AND org_id = ?", aclID, common.ACL_TYPE_CUSTOM, orgID).Limit(1).Find(&acl).RowsAffected == 0 {
+		return NewError(httpcommon.RESOURCE_NOT_FOUND, fmt.Sprintf("acl (%d) not found", aclID))
+	}
+	if acl.Applications != strconv.Itoa(application) {
+		return NewError(httpcommon.INVALID_PARAMETERS, fmt.Sprintf("application of acl (%s) should be %d", acl.Name, application))
+	}
+	if state != nil && *state != common.ACL_STATE_DISABLE && *state != common.ACL_STATE_ENABLE {
+		return NewError(httpcommon.INVALID_PARAMETERS, fmt.Sprintf("state (%d) invalid", *state))
+	}
+	if payloadSlice != nil && (*payloadSlice < 0 || *payloadSlice > common.PAYLOAD_SLICE_MAX) {
+		return NewError(httpcommon.INVALID_PARAMETERS, fmt.Sprintf("payload slice (%d) invalid", *payloadSlice))
+	}
+	// empty vtaps means all vtaps of all orgs
+	if len(vtapIDs) == 0 && orgID != common.DEFAULT_ORG_ID {
+		return NewError(httpcommon.INVALID_PARAMETERS, fmt.Sprintf("vtap ids are required by policies of org (%d)", orgID))
+	}
+	if len(vtapIDs) > 0 {
+		var count int64
+		mysql.Db.Model(&mysql.VTap{}).Where(
+			"id IN (?) AND vtap_group_lcuuid IN (?)",
+			vtapIDs, mysql.Db.Model(&mysql.VTapGroup{}).Select("lcuuid").Where("org_id = ?", orgID),
+		).Count(&count)
+		if int(count) != len(vtapIDs) {
+			return NewError(httpcommon.RESOURCE_NOT_FOUND, fmt.Sprintf("vtaps (%v) not found", vtapIDs))
+		}
+	}
+	return nil
+}
+
+// createPolicyACLGroup creates the policy acl group of a policy, which is tagged as acl_gid in flow logs
+func createPolicyACLGroup(tx *gorm.DB, aclID int) (int, error) {
+	aclGroup := mysql.PolicyACLGroup{ACLIDs: strconv.Itoa(aclID), Count: 1}
+	if err := tx.Create(&aclGroup).Error; err != nil {
+		return 0, err
+	}
+	return aclGroup.ID, nil
+}
+
+func getACLIDToName() map[int]string {
+	var acls []mysql.ACL
+	mysql.Db.Select("id", "name").Find(&acls)
+	idToName := make(map[int]string, len(acls))
+	for _, acl := range acls {
+		idToName[acl.ID] = acl.Name
+	}
+	return idToName
+}
+
+func getStateOrDefault(state *int) int {
+	if state == nil {
+		return common.ACL_STATE_ENABLE
+	}
+	return *state
+}
+
+func GetNpbPolicies(filter map[string]interface{}) ([]model.NpbPolicy, error) {
+	var policies []mysql.NpbPolicy
+
+	Db := mysql.Db
+	for _, param := range []string{"lcuuid", "name", "acl_id", "npb_tunnel_id", "org_id"} {
+		if _, ok := filter[param]; ok {
+			Db = Db.Where(fmt.Sprintf("%s = ?", param), filter[param])
+		}
+	}
+	if err := Db.Order("id").Find(&policies).Error; err != nil {
+		return nil, NewError(httpcommon.SERVER_ERROR, fmt.Sprintf("get npb policies failed: %s", err))
+	}
+	aclIDToName := getACLIDToName()
+	var tunnels []mysql.NpbTunnel
+	mysql.Db.Select("id", "name").Find(&tunnels)
+	tunnelIDToName := make(map[int]string, len(tunnels))
+	for _, tunnel := range tunnels {
+		tunnelIDToName[tunnel.ID] = tunnel.Name
+	}
+
+	response := make([]model.NpbPolicy, 0, len(policies))
+	for _, policy := range policies {
+		response = append(response, model.NpbPolicy{
+			ID:               policy.ID,
+			Name:             policy.Name,
+			OrgID:            policy.OrgID,
+			State:            policy.State,
+			ACLID:            policy.ACLID,
+			ACLName:          aclIDToName[policy.ACLID],
+			NpbTunnelID:      policy.NpbTunnelID,
+			NpbTunnelName:    tunnelIDToName[policy.NpbTunnelID],
+			Vni:              policy.Vni,
+			Distribute:       policy.Distribute,
+			PayloadSlice:     policy.PayloadSlice,
+			VTapIDs:          splitInts(policy.VtapIDs),
+			PolicyACLGroupID: policy.PolicyACLGroupID,
+			CreatedAt:        policy.CreatedAt.Format(common.GO_BIRTHDAY),
+			UpdatedAt:        policy.UpdatedAt.Format(common.GO_BIRTHDAY),
+			Lcuuid:           policy.Lcuuid,
+		})
+	}
+	return response, nil
+}
+
+func getNpbPolicy(orgID int, lcuuid string) (model.NpbPolicy, error) {
+	policies, err := GetNpbPolicies(map[string]interface{}{"lcuuid": lcuuid, "org_id": orgID})
+	if err != nil {
+		return model.NpbPolicy{}, err
+	}
+	if len(policies) == 0 {
+		return model.NpbPolicy{}, NewError(httpcommon.RESOURCE_NOT_FOUND, fmt.Sprintf("npb policy (%s) not found", lcuuid))
+	}
+	return policies[0], nil
+}
+
+// convertNpbPolicy validates the policy and converts it to the DB item, id, lcuuid and policy acl group are not set.
+func convertNpbPolicy(policyCreate model.NpbPolicyCreate) (*mysql.NpbPolicy, error) {
+	if err := validatePolicyCommon(
+		policyCreate.OrgID, policyCreate.ACLID, common.ACL_APPLICATION_NPB, policyCreate.State, policyCreate.PayloadSlice, policyCreate.VTapIDs,
+	); err != nil {
+		return nil, err
+	}
+	var tunnel mysql.NpbTunnel
+	if mysql.Db.Where("id = ? AND org_id = ?", policyCreate.NpbTunnelID, policyCreate.OrgID).Limit(1).Find(&tunnel).RowsAffected == 0 {
+		return nil, NewError(httpcommon.RESOURCE_NOT_FOUND, fmt.Sprintf("npb tunnel (%d) not found", policyCreate.NpbTunnelID))
+	}
+	if err := validateVni(tunnel.Type, policyCreate.Vni); err != nil {
+		return nil, NewError(httpcommon.INVALID_PARAMETERS, err.Error())
+	}
+	distribute := common.NPB_POLICY_FLOW_DISTRIBUTE
+	if policyCreate.Distribute != nil {
+		distribute = *policyCreate.Distribute
+	}
+	if distribute != common.NPB_POLICY_FLOW_DROP && distribute != common.NPB_POLICY_FLOW_DISTRIBUTE {
+		return nil, NewError(httpcommon.INVALID_PARAMETERS, fmt.Sprintf("distribute (%d) invalid", distribute))
+	}
+	return &mysql.NpbPolicy{
+		Name:         policyCreate.Name,
+		OrgID:        policyCreate.OrgID,
+		State:        getStateOrDefault(policyCreate.State),
+		BusinessID:   common.RESOURCE_GROUP_BUSINESS_ID_NPB,
+		Vni:          policyCreate.Vni,
+		NpbTunnelID:  policyCreate.NpbTunnelID,
+		Distribute:   distribute,
+		PayloadSlice: policyCreate.PayloadSlice,
+		ACLID:        policyCreate.ACLID,
+		VtapIDs:      joinInts(policyCreate.VTapIDs),
+	}, nil
+}
+
+func CreateNpbPolicy(policyCreate model.NpbPolicyCreate) (model.NpbPolicy, error) {
+	var count int64
+	mysql.Db.Model(&mysql.NpbPolicy{}).Where("name = ?", policyCreate.Name).Count(&count)
+	if count > 0 {
+		return model.NpbPolicy{}, NewError(httpcommon.RESOURCE_ALREADY_EXIST, fmt.Sprintf("npb policy (%s) already exist", policyCreate.Name))
+	}
+	policy, err := convertNpbPolicy(policyCreate)
+	if err != nil {
+		return model.NpbPolicy{}, err
+	}
+	policy.Lcuuid = uuid.New().String()
+	err = mysql.Db.Transaction(func(tx *gorm.DB) error {
+		if policy.PolicyACLGroupID, err = createPolicyACLGroup(tx, policy.ACLID); err != nil {
+			return err
+		}
+		if err := tx.Create(policy).Error; err != nil {
+			return err
+		}
+		// zero value of state is not inserted by gorm, as state has a default value in DB
+		if policy.State == common.ACL_STATE_DISABLE {
+			return tx.Model(policy).Update("state", policy.State).Error
+		}
+		return nil
+	})
+	if err != nil {
+		return model.NpbPolicy{}, NewError(httpcommon.SERVER_ERROR, fmt.Sprintf("create npb policy (%s) failed: %s", policy.Name, err))
+	}
+	log.Infof("create npb policy (%s)", policy.Name)
+	refresh.RefreshCache([]common.DataChanged{common.DATA_CHANGED_FLOW_ACL})
+	return getNpbPolicy(policy.OrgID, policy.Lcuuid)
+}
+
+// UpdateNpbPolicy only updates the fields in the patch.
+func UpdateNpbPolicy(orgID int, lcuuid string, patch map[string]interface{}) (model.NpbPolicy, error) {
+	old, err := getNpbPolicy(orgID, lcuuid)
+	if err != nil {
+		return model.NpbPolicy{}, err
+	}
+	policyUpdate := model.NpbPolicyCreate{
+		Name:         old.Name,
+		State:        &old.State,
+		ACLID:        old.ACLID,
+		NpbTunnelID:  old.NpbTunnelID,
+		Vni:          old.Vni,
+		Distribute:   &old.Distribute,
+		PayloadSlice: old.PayloadSlice,
+		VTapIDs:      old.VTapIDs,
+		OrgID:        orgID,
+	}
+	patchBytes, _ := json.Marshal(patch)
+	if err := json.Unmarshal(patchBytes, &policyUpdate); err != nil {
+		return model.NpbPolicy{}, NewError(httpcommon.INVALID_PARAMETERS, err.Error())
+	}
+	if policyUpdate.Name != old.Name {
+		var count int64
+		mysql.Db.Model(&mysql.NpbPolicy{}).Where("name = ?", policyUpdate.Name).Count(&count)
+		if count > 0 {
+			return model.NpbPolicy{}, NewError(httpcommon.RESOURCE_ALREADY_EXIST, fmt.Sprintf("npb policy (%s) already exist", policyUpdate.Name))
+		}
+	}
+	policy, err := convertNpbPolicy(policyUpdate)
+	if err != nil {
+		return model.NpbPolicy{}, err
+	}
+
+	log.Infof("update npb policy (%s) config %v", old.Name, patch)
+	dbUpdateMap := map[string]interface{}{
+		"name":          policy.Name,
+		"state":         policy.State,
+		"vni":           policy.Vni,
+		"npb_tunnel_id": policy.NpbTunnelID,
+		"distribute":    policy.Distribute,
+		"payload_slice": policy.PayloadSlice,
+		"acl_id":        policy.ACLID,
+		"vtap_ids":      policy.VtapIDs,
+	}
+	err = mysql.Db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&mysql.NpbPolicy{}).Where("lcuuid = ? AND org_id = ?", lcuuid, orgID).Updates(dbUpdateMap).Error; err != nil {
+			return err
+		}
+		return tx.Model(&mysql.PolicyACLGroup{}).Where("id = ?", old.PolicyACLGroupID).Update("acl_ids", strconv.Itoa(policy.ACLID)).Error
+	})
+	if err != nil {
+		return model.NpbPolicy{}, NewError(httpcommon.SERVER_ERROR, fmt.Sprintf("update npb policy (%s) failed: %s", old.Name, err))
+	}
+	refresh.RefreshCache([]common.DataChanged{common.DATA_CHANGED_FLOW_ACL})
+	return getNpbPolicy(orgID, lcuuid)
+}
+
+func DeleteNpbPolicy(orgID int, lcuuid string) (map[string]string, error) {
+	policy, err := getNpbPolicy(orgID, lcuuid)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Infof("delete npb policy (%s)", policy.Name)
+	err = mysql.Db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("lcuuid = ? AND org_id = ?", lcuuid, orgID).Delete(&mysql.NpbPolicy{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("id = ?", policy.PolicyACLGroupID).Delete(&mysql.PolicyACLGroup{}).Error
+	})
+	if err != nil {
+		return nil, NewError(httpcommon.SERVER_ERROR, fmt.Sprintf("delete npb policy (%s) failed: %s", policy.Name, err))
+	}
+	refresh.RefreshCache([]common.DataChanged{common.DATA_CHANGED_FLOW_ACL})
+	return map[string]string{"LCUUID": lcuuid}, nil
+}
+
+func GetPcapPolicies(filter map[string]interface{}) ([]model.PcapPolicy, error) {
+	var policies []mysql.PcapPolicy
+
+	Db := mysql.Db
+	for _, param := range []string{"lcuuid", "name", "acl_id", "org_id"} {
+		if _, ok := filter[param]; ok {
+			Db = Db.Where(fmt.Sprintf("%s = ?", param), filter[param])
+		}
+	}
+	if err := Db.Order("id").Find(&policies).Error; err != nil {
+		return nil, NewError(httpcommon.SERVER_ERROR, fmt.Sprintf("get pcap policies failed: %s", err))
+	}
+	aclIDToName := getACLIDToName()
+
+	response := make([]model.PcapPolicy, 0, len(policies))
+	for _, policy := range policies {
+		response = append(response, model.PcapPolicy{
+			ID:               policy.ID,
+			Name:             policy.Name,
+			OrgID:            policy.OrgID,
+			State:            policy.State,
+			ACLID:            policy.ACLID,
+			ACLName:          aclIDToName[policy.ACLID],
+			PayloadSlice:     policy.PayloadSlice,
+			VTapIDs:          splitInts(policy.VtapIDs),
+			PolicyACLGroupID: policy.PolicyACLGroupID,
+			CreatedAt:        policy.CreatedAt.Format(common.GO_BIRTHDAY),
+			UpdatedAt:        policy.UpdatedAt.Format(common.GO_BIRTHDAY),
+			Lcuuid:           policy.Lcuuid,
+		})
+	}
+	return response, nil
+}
+
+func getPcapPolicy(orgID int, lcuuid string) (model.PcapPolicy, error) {
+	policies, err := GetPcapPolicies(map[string]interface{}{"lcuuid": lcuuid, "org_id": orgID})
+	if err != nil {
+		return model.PcapPolicy{}, err
+	}
+	if len(policies) == 0 {
+		return model.PcapPolicy{}, NewError(httpcommon.RESOURCE_NOT_FOUND, fmt.Sprintf("pcap policy (%s) not found", lcuuid))
+	}
+	return policies[0], nil
+}
+
+// convertPcapPolicy validates the policy and converts it to the DB item, id, lcuuid and policy acl group are not set.
+func convertPcapPolicy(policyCreate model.PcapPolicyCreate) (*mysql.PcapPolicy, error) {
+	if err := validatePolicyCommon(
+		policyCreate.OrgID, policyCreate.ACLID, common.ACL_APPLICATION_PCAP, policyCreate.State, policyCreate.PayloadSlice, policyCreate.VTapIDs,
+	); err != nil {
+		return nil, err
+	}
+	return &mysql.PcapPolicy{
+		Name:         policyCreate.Name,
+		OrgID:        policyCreate.OrgID,
+		State:        getStateOrDefault(policyCreate.State),
+		BusinessID:   common.RESOURCE_GROUP_BUSINESS_ID_PCAP,
+		ACLID:        policyCreate.ACLID,
+		VtapIDs:      joinInts(policyCreate.VTapIDs),
+		PayloadSlice: policyCreate.PayloadSlice,
+	}, nil
+}
+
+func CreatePcapPolicy(policyCreate model.PcapPolicyCreate) (model.PcapPolicy, error) {
+	var count int64
+	mysql.Db.Model(&mysql.PcapPolicy{}).Where("name = ?", policyCreate.Name).Count(&count)
+	if count > 0 {
+		return model.PcapPolicy{}, NewError(httpcommon.RESOURCE_ALREADY_EXIST, fmt.Sprintf("pcap policy (%s) already exist", policyCreate.Name))
+	}
+	policy, err := convertPcapPolicy(policyCreate)
+	if err != nil {
+		return model.PcapPolicy{}, err
+	}
+	policy.Lcuuid = uuid.New().String()
+	err = mysql.Db.Transaction(func(tx *gorm.DB) error {
+		if policy.PolicyACLGroupID, err = createPolicyACLGroup(tx, policy.ACLID); err != nil {
+			return err
+		}
+		if err := tx.Create(policy).Error; err != nil {
+			return err
+		}
+		// zero value of state is not inserted by gorm, as state has a default value in DB
+		if policy.State == common.ACL_STATE_DISABLE {
+			return tx.Model(policy).Update("state", policy.State).Error
+		}
+		return nil
+	})
+	if err != nil {
+		return model.PcapPolicy{}, NewError(httpcommon.SERVER_ERROR, fmt.Sprintf("create pcap policy (%s) failed: %s", policy.Name, err))
+	}
+	log.Infof("create pcap policy (%s)", policy.Name)
+	refresh.RefreshCache([]common.DataChanged{common.DATA_CHANGED_FLOW_ACL})
+	return getPcapPolicy(policy.OrgID, policy.Lcuuid)
+}
+
+// UpdatePcapPolicy only updates the fields in the patch.
+func UpdatePcapPolicy(orgID int, lcuuid string, patch map[string]interface{}) (model.PcapPolicy, error) {
+	old, err := getPcapPolicy(orgID, lcuuid)
+	if err != nil {
+		return model.PcapPolicy{}, err
+	}
+	policyUpdate := model.PcapPolicyCreate{
+		Name:         old.Name,
+		State:        &old.State,
+		ACLID:        old.ACLID,
+		PayloadSlice: old.PayloadSlice,
+		VTapIDs:      old.VTapIDs,
+		OrgID:        orgID,
+	}
+	patchBytes, _ := json.Marshal(patch)
+	if err := json.Unmarshal(patchBytes, &policyUpdate); err != nil {
+		return model.PcapPolicy{}, NewError(httpcommon.INVALID_PARAMETERS, err.Error())
+	}
+	if policyUpdate.Name != old.Name {
+		var count int64
+		mysql.Db.Model(&mysql.PcapPolicy{}).Where("name = ?", policyUpdate.Name).Count(&count)
+		if count > 0 {
+			return model.PcapPolicy{}, NewError(httpcommon.RESOURCE_ALREADY_EXIST, fmt.Sprintf("pcap policy (%s) already exist", policyUpdate.Name))
+		}
+	}
+	policy, err := convertPcapPolicy(policyUpdate)
+	if err != nil {
+		return model.PcapPolicy{}, err
+	}
+
+	log.Infof("update pcap policy (%s) config %v", old.Name, patch)
+	dbUpdateMap := map[string]interface{}{
+		"name":          policy.Name,
+		"state":         policy.State,
+		"acl_id":        policy.ACLID,
+		"vtap_ids":      policy.VtapIDs,
+		"payload_slice": policy.PayloadSlice,
+	}
+	err = mysql.Db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&mysql.PcapPolicy{}).Where("lcuuid = ? AND org_id = ?", lcuuid, orgID).Updates(dbUpdateMap).Error; err != nil {
+			return err
+		}
+		return tx.Model(&mysql.PolicyACLGroup{}).Where("id = ?", old.PolicyACLGroupID).Update("acl_ids", strconv.Itoa(policy.ACLID)).Error
+	})
+	if err != nil {
+		return model.PcapPolicy{}, NewError(httpcommon.SERVER_ERROR, fmt.Sprintf("update pcap policy (%s) failed: %s", old.Name, err))
+	}
+	refresh.RefreshCache([]common.DataChanged{common.DATA_CHANGED_FLOW_ACL})
+	return getPcapPolicy(orgID, lcuuid)
+}
+
+func DeletePcapPolicy(orgID int, lcuuid string) (map[string]string, error) {
+	policy, err := getPcapPolicy(orgID, lcuuid)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Infof("delete pcap policy (%s)", policy.Name)
+	err = mysql.Db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("lcuuid = ? AND org_id = ?", lcuuid, orgID).Delete(&mysql.PcapPolicy{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("id = ?", policy.PolicyACLGroupID).Delete(&mysql.PolicyACLGroup{}).Error
+	})
+	if err != nil {
+		return nil, NewError(httpcommon.SERVER_ERROR, fmt.Sprintf("delete pcap policy (%s) failed: %s", policy.Name, err))
+	}
+	refresh.RefreshCache([]common.DataChanged{common.DATA_CHANGED_FLOW_ACL})
+	return map[string]string{"LCUUID": lcuuid}, nil
+}
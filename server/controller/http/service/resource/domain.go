@@ -100,6 +100,9 @@ func GetDomains(filter map[string]interface{}) (resp []model.Domain, err error)
 	if _, ok := filter["name"]; ok {
 		Db = Db.Where("name = ?", filter["name"])
 	}
+	if _, ok := filter["org_id"]; ok {
+		Db = Db.Where("org_id = ?", filter["org_id"])
+	}
 	Db.Order("created_at DESC").Find(&domains)
 
 	for _, domain := range domains {
@@ -142,6 +145,7 @@ func GetDomains(filter map[string]interface{}) (resp []model.Domain, err error)
 		domainResp := model.Domain{
 			ID:           domain.ClusterID,
 			Name:         domain.Name,
+			OrgID:        domain.OrgID,
 			DisplayName:  domain.DisplayName,
 			ClusterID:    domain.ClusterID,
 			Type:         domain.Type,
@@ -245,6 +249,10 @@ func CreateDomain(domainCreate model.DomainCreate, cfg *config.ControllerConfig)
 	lcuuid := common.GetUUID(displayName, uuid.Nil)
 	domain.Lcuuid = lcuuid
 	domain.Name = domainCreate.Name
+	domain.OrgID = domainCreate.OrgID
+	if domain.OrgID == 0 {
+		domain.OrgID = common.DEFAULT_ORG_ID
+	}
 	domain.DisplayName = displayName
 	domain.Type = domainCreate.Type
 	domain.IconID = domainCreate.IconID
@@ -351,6 +359,17 @@ func createKubernetesRelatedResources(domain mysql.Domain, regionLcuuid string)
 	return
 }
 
+// CheckDomainOrg returns error if the domain does not exist in the organization
+func CheckDomainOrg(lcuuid string, orgID int) error {
+	var domain mysql.Domain
+	if ret := mysql.Db.Where("lcuuid = ? AND org_id = ?", lcuuid, orgID).First(&domain); ret.Error != nil {
+		return servicecommon.NewError(
+			httpcommon.RESOURCE_NOT_FOUND, fmt.Sprintf("domain (%s) not found", lcuuid),
+		)
+	}
+	return nil
+}
+
 func UpdateDomain(
 	lcuuid string, domainUpdate map[string]interface{}, cfg *config.ControllerConfig,
 ) (*model.Domain, error) {
@@ -501,6 +520,7 @@ func DeleteDomain(lcuuid string) (map[string]string, error) { // TODO whether re
 	mysql.Db.Unscoped().Where("domain = ?", lcuuid).Delete(&mysql.Process{})
 	mysql.Db.Unscoped().Where("domain = ?", lcuuid).Delete(&mysql.PrometheusTarget{})
 	mysql.Db.Unscoped().Where("domain = ?", lcuuid).Delete(&mysql.VIP{})
+	mysql.Db.Where("domain = ?", lcuuid).Delete(&mysql.K8sEventPosition{})
 	var sgs []mysql.SecurityGroup
 	mysql.Db.Unscoped().Where("domain = ?", lcuuid).Find(&sgs)
 	sgIDs := make([]int, len(sgs))
@@ -823,6 +843,7 @@ func DeleteSubDomain(lcuuid string) (map[string]string, error) {
 		mysql.Db.Unscoped().Where("sub_domain = ?", lcuuid).Delete(&mysql.PodCluster{})
 		mysql.Db.Unscoped().Where("sub_domain = ?", lcuuid).Delete(&mysql.Process{})
 		mysql.Db.Unscoped().Where("sub_domain = ?", lcuuid).Delete(&mysql.PrometheusTarget{})
+		mysql.Db.Where("sub_domain = ?", lcuuid).Delete(&mysql.K8sEventPosition{})
 	}
 
 	mysql.Db.Delete(&subDomain)
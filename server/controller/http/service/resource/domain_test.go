@@ -93,6 +93,7 @@ func getMySQLModels() []interface{} {
 		&mysql.PodIngress{}, &mysql.PodIngressRule{}, &mysql.PodIngressRuleBackend{},
 		&mysql.PodService{}, &mysql.PodServicePort{}, &mysql.PodGroup{}, &mysql.PodGroupPort{},
 		&mysql.PodReplicaSet{}, &mysql.Pod{}, &mysql.AZControllerConnection{}, &mysql.Controller{},
+		&mysql.ResourceHistory{},
 	}
 }
 
@@ -449,3 +450,35 @@ func (t *SuiteTest) TestDeleteSoftDeletedResource() {
 // 	t.db.Where("lcuuid = ?", domainLcuuid).Find(&domain)
 // 	assert.Equal(t.T(), normalControllerIP, domain.ControllerIP)
 // }
+
+func (t *SuiteTest) TestGetDomainsOfOrg() {
+	domain := mysql.Domain{Base: mysql.Base{Lcuuid: uuid.NewString()}, Name: "org1-domain", OrgID: 1}
+	t.db.Create(&domain)
+	otherDomain := mysql.Domain{Base: mysql.Base{Lcuuid: uuid.NewString()}, Name: "org2-domain", OrgID: 2}
+	t.db.Create(&otherDomain)
+
+	domains, err := GetDomains(map[string]interface{}{"org_id": 2})
+	assert.Nil(t.T(), err)
+	assert.Equal(t.T(), 1, len(domains))
+	assert.Equal(t.T(), otherDomain.Lcuuid, domains[0].Lcuuid)
+	assert.Equal(t.T(), 2, domains[0].OrgID)
+
+	// 按 lcuuid 查询其他组织的云平台时查询不到
+	domains, err = GetDomains(map[string]interface{}{"org_id": 1, "lcuuid": otherDomain.Lcuuid})
+	assert.Nil(t.T(), err)
+	assert.Equal(t.T(), 0, len(domains))
+
+	t.db.Delete(&domain)
+	t.db.Delete(&otherDomain)
+}
+
+func (t *SuiteTest) TestCheckDomainOrg() {
+	domain := mysql.Domain{Base: mysql.Base{Lcuuid: uuid.NewString()}, Name: "org2-domain", OrgID: 2}
+	t.db.Create(&domain)
+
+	assert.Nil(t.T(), CheckDomainOrg(domain.Lcuuid, 2))
+	assert.NotNil(t.T(), CheckDomainOrg(domain.Lcuuid, 1))
+	assert.NotNil(t.T(), CheckDomainOrg(uuid.NewString(), 2))
+
+	t.db.Delete(&domain)
+}
@@ -0,0 +1,80 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package resource
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/deepflowio/deepflow/server/controller/common"
+	"github.com/deepflowio/deepflow/server/controller/db/mysql"
+	httpcommon "github.com/deepflowio/deepflow/server/controller/http/common"
+	servicecommon "github.com/deepflowio/deepflow/server/controller/http/service/common"
+)
+
+const defaultResourceHistoryLimit = 1000
+
+// GetResourcesAt 查询指定时刻有效的资源，可按 IP、资源类型过滤；指定 org_id 时只返回该组织的 domain 及 sub_domain 的资源
+func GetResourcesAt(filter map[string]interface{}) ([]*mysql.ResourceHistory, error) {
+	timeStr, _ := filter["time"].(string)
+	if timeStr == "" {
+		return nil, servicecommon.NewError(httpcommon.INVALID_PARAMETERS, "time is required")
+	}
+	at, err := parseHistoryTime(timeStr)
+	if err != nil {
+		return nil, servicecommon.NewError(httpcommon.INVALID_PARAMETERS, err.Error())
+	}
+	limit := defaultResourceHistoryLimit
+	if limitStr, ok := filter["limit"].(string); ok {
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit <= 0 {
+			return nil, servicecommon.NewError(httpcommon.INVALID_PARAMETERS, fmt.Sprintf("limit (%s) is invalid", limitStr))
+		}
+	}
+
+	db := mysql.Db.Where("valid_from <= ? AND (valid_to IS NULL OR valid_to > ?)", at, at)
+	for _, key := range []string{"ip", "resource_type", "lcuuid"} {
+		if value, ok := filter[key]; ok {
+			db = db.Where(key+" = ?", value)
+		}
+	}
+	if orgID, ok := filter["org_id"]; ok {
+		orgDomains := mysql.Db.Model(&mysql.Domain{}).Select("lcuuid").Where("org_id = ?", orgID)
+		orgSubDomains := mysql.Db.Model(&mysql.SubDomain{}).Select("lcuuid").Where("domain IN (?)", orgDomains)
+		db = db.Where("domain IN (?) OR sub_domain IN (?)", orgDomains, orgSubDomains)
+	}
+	var histories []*mysql.ResourceHistory
+	if err := db.Order("valid_from DESC").Limit(limit).Find(&histories).Error; err != nil {
+		return nil, err
+	}
+	return histories, nil
+}
+
+// 支持 unix 时间戳（秒）、RFC3339 及 "2006-01-02 15:04:05" 格式
+func parseHistoryTime(value string) (time.Time, error) {
+	if timestamp, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(timestamp, 0), nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation(common.GO_BIRTHDAY, value, time.Local); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("time (%s) is invalid, unix timestamp, RFC3339 or %s expected", value, common.GO_BIRTHDAY)
+}
@@ -0,0 +1,86 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package resource
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/deepflowio/deepflow/server/controller/db/mysql"
+)
+
+func TestParseHistoryTime(t *testing.T) {
+	expected := time.Unix(1682929800, 0)
+	for _, value := range []string{
+		"1682929800",
+		expected.Format(time.RFC3339),
+		expected.Format("2006-01-02 15:04:05"),
+	} {
+		got, err := parseHistoryTime(value)
+		if err != nil {
+			t.Fatalf("parseHistoryTime(%s) error: %v", value, err)
+		}
+		if !got.Equal(expected) {
+			t.Errorf("parseHistoryTime(%s) = %v, want %v", value, got, expected)
+		}
+	}
+	if _, err := parseHistoryTime("yesterday"); err == nil {
+		t.Error("parseHistoryTime(yesterday) expected error")
+	}
+}
+
+func (t *SuiteTest) TestGetResourcesAtOfOrg() {
+	domain := mysql.Domain{Base: mysql.Base{Lcuuid: uuid.NewString()}, Name: "org1", OrgID: 1}
+	t.db.Create(&domain)
+	subDomain := mysql.SubDomain{Base: mysql.Base{Lcuuid: uuid.NewString()}, Name: "org1-sub", Domain: domain.Lcuuid}
+	t.db.Create(&subDomain)
+	otherDomain := mysql.Domain{Base: mysql.Base{Lcuuid: uuid.NewString()}, Name: "org2", OrgID: 2}
+	t.db.Create(&otherDomain)
+
+	validFrom := time.Unix(1682929800, 0)
+	for _, item := range []*mysql.ResourceHistory{
+		{ResourceType: "vm", Lcuuid: uuid.NewString(), IP: "10.0.0.1", Domain: domain.Lcuuid, ValidFrom: validFrom},
+		{ResourceType: "pod", Lcuuid: uuid.NewString(), IP: "10.0.0.2", SubDomain: subDomain.Lcuuid, ValidFrom: validFrom},
+		{ResourceType: "vm", Lcuuid: uuid.NewString(), IP: "10.0.0.1", Domain: otherDomain.Lcuuid, ValidFrom: validFrom},
+	} {
+		t.db.Create(item)
+	}
+
+	histories, err := GetResourcesAt(map[string]interface{}{"time": "1682929900", "org_id": 1})
+	assert.Nil(t.T(), err)
+	assert.Equal(t.T(), 2, len(histories))
+	for _, history := range histories {
+		assert.NotEqual(t.T(), otherDomain.Lcuuid, history.Domain)
+	}
+
+	histories, err = GetResourcesAt(map[string]interface{}{"time": "1682929900", "ip": "10.0.0.1", "org_id": 2})
+	assert.Nil(t.T(), err)
+	assert.Equal(t.T(), 1, len(histories))
+	assert.Equal(t.T(), otherDomain.Lcuuid, histories[0].Domain)
+
+	histories, err = GetResourcesAt(map[string]interface{}{"time": "1682929900", "org_id": 3})
+	assert.Nil(t.T(), err)
+	assert.Equal(t.T(), 0, len(histories))
+
+	t.db.Where("1 = 1").Delete(&mysql.ResourceHistory{})
+	t.db.Delete(&subDomain)
+	t.db.Delete(&domain)
+	t.db.Delete(&otherDomain)
+}
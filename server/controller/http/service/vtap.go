@@ -50,7 +50,7 @@ func GetVtaps(filter map[string]interface{}) (resp []model.Vtap, err error) {
 
 	Db := mysql.Db
 	for _, param := range []string{
-		"lcuuid", "name", "type", "vtap_group_lcuuid", "controller_ip", "analyzer_ip",
+		"lcuuid", "name", "type", "state", "vtap_group_lcuuid", "controller_ip", "analyzer_ip",
 	} {
 		where := fmt.Sprintf("%s = ?", param)
 		if _, ok := filter[param]; ok {
@@ -62,6 +62,12 @@ func GetVtaps(filter map[string]interface{}) (resp []model.Vtap, err error) {
 			Db = Db.Where("name IN (?)", filter["names"].([]string))
 		}
 	}
+	if _, ok := filter["org_id"]; ok {
+		Db = Db.Where(
+			"vtap_group_lcuuid IN (?)",
+			mysql.Db.Model(&mysql.VTapGroup{}).Select("lcuuid").Where("org_id = ?", filter["org_id"]),
+		)
+	}
 	Db.Find(&vtaps)
 	mysql.Db.Find(&vtapGroups)
 	mysql.Db.Find(&regions)
@@ -0,0 +1,221 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/deepflowio/deepflow/server/controller/common"
+	"github.com/deepflowio/deepflow/server/controller/db/mysql"
+	httpcommon "github.com/deepflowio/deepflow/server/controller/http/common"
+	. "github.com/deepflowio/deepflow/server/controller/http/service/common"
+	"github.com/deepflowio/deepflow/server/controller/model"
+)
+
+func GetVTapEnrollmentTokens(filter map[string]interface{}) (resp []model.VTapEnrollmentToken, err error) {
+	var tokens []mysql.VTapEnrollmentToken
+	var vtapGroups []mysql.VTapGroup
+
+	Db := mysql.Db
+	for _, param := range []string{"lcuuid", "name", "vtap_group_lcuuid"} {
+		if _, ok := filter[param]; ok {
+			Db = Db.Where(fmt.Sprintf("%s = ?", param), filter[param])
+		}
+	}
+	if _, ok := filter["org_id"]; ok {
+		Db = Db.Where(
+			"vtap_group_lcuuid IN (?)",
+			mysql.Db.Model(&mysql.VTapGroup{}).Select("lcuuid").Where("org_id = ?", filter["org_id"]),
+		)
+	}
+	if err := Db.Order("created_at DESC").Find(&tokens).Error; err != nil {
+		return nil, NewError(httpcommon.SERVER_ERROR, fmt.Sprintf("get vtap enrollment tokens failed: %s", err))
+	}
+	mysql.Db.Find(&vtapGroups)
+	vtapGroupLcuuidToName := make(map[string]string)
+	for _, vtapGroup := range vtapGroups {
+		vtapGroupLcuuidToName[vtapGroup.Lcuuid] = vtapGroup.Name
+	}
+
+	response := make([]model.VTapEnrollmentToken, 0, len(tokens))
+	for _, token := range tokens {
+		tokenResp := model.VTapEnrollmentToken{
+			ID:              token.ID,
+			Name:            token.Name,
+			VTapGroupLcuuid: token.VTapGroupLcuuid,
+			VTapGroupName:   vtapGroupLcuuidToName[token.VTapGroupLcuuid],
+			TokenPrefix:     token.TokenPrefix,
+			MaxUses:         token.MaxUses,
+			UsedCount:       token.UsedCount,
+			CreatedAt:       token.CreatedAt.Format(common.GO_BIRTHDAY),
+			Lcuuid:          token.Lcuuid,
+		}
+		if token.ExpiredAt != nil {
+			tokenResp.ExpiredAt = token.ExpiredAt.Format(common.GO_BIRTHDAY)
+		}
+		response = append(response, tokenResp)
+	}
+	return response, nil
+}
+
+// CreateVTapEnrollmentToken returns the plaintext token in the response, it cannot be retrieved again.
+func CreateVTapEnrollmentToken(tokenCreate model.VTapEnrollmentTokenCreate) (model.VTapEnrollmentToken, error) {
+	var vtapGroup mysql.VTapGroup
+	Db := mysql.Db
+	if tokenCreate.VTapGroupLcuuid != "" {
+		Db = Db.Where("lcuuid = ?", tokenCreate.VTapGroupLcuuid)
+	} else if tokenCreate.VTapGroupID != "" {
+		Db = Db.Where("short_uuid = ?", tokenCreate.VTapGroupID)
+	} else {
+		return model.VTapEnrollmentToken{}, NewError(httpcommon.INVALID_PARAMETERS, "must specify VTAP_GROUP_LCUUID or VTAP_GROUP_ID")
+	}
+	if tokenCreate.OrgID != 0 {
+		Db = Db.Where("org_id = ?", tokenCreate.OrgID)
+	}
+	if ret := Db.First(&vtapGroup); ret.Error != nil {
+		return model.VTapEnrollmentToken{}, NewError(httpcommon.RESOURCE_NOT_FOUND,
+			fmt.Sprintf("vtap group (%s%s) not found", tokenCreate.VTapGroupLcuuid, tokenCreate.VTapGroupID))
+	}
+	if tokenCreate.MaxUses < 0 {
+		return model.VTapEnrollmentToken{}, NewError(httpcommon.INVALID_PARAMETERS, fmt.Sprintf("max_uses (%d) invalid", tokenCreate.MaxUses))
+	}
+	var count int64
+	mysql.Db.Model(&mysql.VTapEnrollmentToken{}).Where("name = ?", tokenCreate.Name).Count(&count)
+	if count > 0 {
+		return model.VTapEnrollmentToken{}, NewError(httpcommon.RESOURCE_ALREADY_EXIST, fmt.Sprintf("vtap enrollment token (%s) already exist", tokenCreate.Name))
+	}
+
+	b := make([]byte, 20)
+	if _, err := rand.Read(b); err != nil {
+		return model.VTapEnrollmentToken{}, NewError(httpcommon.SERVER_ERROR, fmt.Sprintf("generate vtap enrollment token failed: %s", err))
+	}
+	plaintext := common.VTAP_ENROLLMENT_TOKEN_PREFIX + hex.EncodeToString(b)
+	token := mysql.VTapEnrollmentToken{
+		Name:            tokenCreate.Name,
+		VTapGroupLcuuid: vtapGroup.Lcuuid,
+		TokenHash:       common.HashToken(plaintext),
+		TokenPrefix:     plaintext[:common.VTAP_ENROLLMENT_TOKEN_DISPLAY_LEN],
+		MaxUses:         tokenCreate.MaxUses,
+		Lcuuid:          uuid.New().String(),
+	}
+	if tokenCreate.ExpiredAt != "" {
+		expiredAt, err := time.ParseInLocation(common.GO_BIRTHDAY, tokenCreate.ExpiredAt, time.Local)
+		if err != nil {
+			return model.VTapEnrollmentToken{}, NewError(httpcommon.INVALID_PARAMETERS, fmt.Sprintf("expired_at (%s) invalid: %s", tokenCreate.ExpiredAt, err))
+		}
+		token.ExpiredAt = &expiredAt
+	}
+	if err := mysql.Db.Create(&token).Error; err != nil {
+		return model.VTapEnrollmentToken{}, NewError(httpcommon.SERVER_ERROR, fmt.Sprintf("create vtap enrollment token (%s) failed: %s", token.Name, err))
+	}
+	log.Infof("create vtap enrollment token (%s) for vtap group (%s)", token.Name, vtapGroup.Name)
+
+	response, err := GetVTapEnrollmentTokens(map[string]interface{}{"lcuuid": token.Lcuuid})
+	if err != nil || len(response) == 0 {
+		return model.VTapEnrollmentToken{}, NewError(httpcommon.SERVER_ERROR, fmt.Sprintf("get vtap enrollment token (%s) failed", token.Name))
+	}
+	response[0].Token = plaintext
+	return response[0], nil
+}
+
+func DeleteVTapEnrollmentToken(lcuuid string, orgID int) (map[string]string, error) {
+	tokens, err := GetVTapEnrollmentTokens(map[string]interface{}{"lcuuid": lcuuid, "org_id": orgID})
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, NewError(httpcommon.RESOURCE_NOT_FOUND, fmt.Sprintf("vtap enrollment token (%s) not found", lcuuid))
+	}
+
+	log.Infof("delete vtap enrollment token (%s)", tokens[0].Name)
+	if err := mysql.Db.Where("lcuuid = ?", lcuuid).Delete(&mysql.VTapEnrollmentToken{}).Error; err != nil {
+		return nil, NewError(httpcommon.SERVER_ERROR, fmt.Sprintf("delete vtap enrollment token (%s) failed: %s", tokens[0].Name, err))
+	}
+	return map[string]string{"LCUUID": lcuuid}, nil
+}
+
+// GetPendingVTaps returns the agents waiting for approval, they are registered
+// without a valid enrollment token when the register policy is manual-approval
+// or vtap-auto-register is disabled.
+func GetPendingVTaps(filter map[string]interface{}) ([]model.Vtap, error) {
+	filter["state"] = common.VTAP_STATE_PENDING
+	return GetVtaps(filter)
+}
+
+func getPendingVTap(lcuuid string, orgID int) (*mysql.VTap, error) {
+	vtaps, _ := GetPendingVTaps(map[string]interface{}{"lcuuid": lcuuid, "org_id": orgID})
+	if len(vtaps) == 0 {
+		return nil, NewError(httpcommon.RESOURCE_NOT_FOUND, fmt.Sprintf("pending vtap (%s) not found", lcuuid))
+	}
+	var vtap mysql.VTap
+	if err := mysql.Db.Where("lcuuid = ?", lcuuid).First(&vtap).Error; err != nil {
+		return nil, NewError(httpcommon.RESOURCE_NOT_FOUND, fmt.Sprintf("pending vtap (%s) not found", lcuuid))
+	}
+	return &vtap, nil
+}
+
+func ApproveVTapRegistration(lcuuid string, orgID int, approve model.VTapRegistrationApprove) (model.Vtap, error) {
+	vtap, err := getPendingVTap(lcuuid, orgID)
+	if err != nil {
+		return model.Vtap{}, err
+	}
+	updateMap := map[string]interface{}{"state": common.VTAP_STATE_NORMAL}
+	if approve.VTapGroupLcuuid != "" {
+		var count int64
+		mysql.Db.Model(&mysql.VTapGroup{}).Where("lcuuid = ? AND org_id = ?", approve.VTapGroupLcuuid, orgID).Count(&count)
+		if count == 0 {
+			return model.Vtap{}, NewError(httpcommon.RESOURCE_NOT_FOUND, fmt.Sprintf("vtap group (%s) not found", approve.VTapGroupLcuuid))
+		}
+		updateMap["vtap_group_lcuuid"] = approve.VTapGroupLcuuid
+		updateMap["vtap_group_source"] = common.VTAP_GROUP_SOURCE_MANUAL
+	}
+	log.Infof("approve registration of vtap (%s)", vtap.Name)
+	if err := mysql.Db.Model(vtap).Updates(updateMap).Error; err != nil {
+		return model.Vtap{}, NewError(httpcommon.SERVER_ERROR, fmt.Sprintf("approve vtap (%s) failed: %s", vtap.Name, err))
+	}
+	response, _ := GetVtaps(map[string]interface{}{"lcuuid": vtap.Lcuuid})
+	if len(response) == 0 {
+		return model.Vtap{}, NewError(httpcommon.RESOURCE_NOT_FOUND, fmt.Sprintf("vtap (%s) not found", lcuuid))
+	}
+	return response[0], nil
+}
+
+// RejectVTapRegistration disables the agent instead of deleting it, otherwise it
+// registers again at the next sync. Delete the agent to allow it to register again.
+// Rejected agents keep the disable state and get no config until they are deleted.
+func RejectVTapRegistration(lcuuid string, orgID int) (model.Vtap, error) {
+	vtap, err := getPendingVTap(lcuuid, orgID)
+	if err != nil {
+		return model.Vtap{}, err
+	}
+	log.Infof("reject registration of vtap (%s)", vtap.Name)
+	if err := mysql.Db.Model(vtap).Updates(map[string]interface{}{
+		"state": common.VTAP_STATE_DISABLE, "enable": common.VTAP_ENABLE_FALSE,
+	}).Error; err != nil {
+		return model.Vtap{}, NewError(httpcommon.SERVER_ERROR, fmt.Sprintf("reject vtap (%s) failed: %s", vtap.Name, err))
+	}
+	response, _ := GetVtaps(map[string]interface{}{"lcuuid": vtap.Lcuuid})
+	if len(response) == 0 {
+		return model.Vtap{}, NewError(httpcommon.RESOURCE_NOT_FOUND, fmt.Sprintf("vtap (%s) not found", lcuuid))
+	}
+	return response[0], nil
+}
@@ -55,6 +55,9 @@ func GetVtapGroups(filter map[string]interface{}) (resp []model.VtapGroup, err e
 	if _, ok := filter["short_uuid"]; ok {
 		Db = Db.Where("short_uuid = ?", filter["short_uuid"])
 	}
+	if _, ok := filter["org_id"]; ok {
+		Db = Db.Where("org_id = ?", filter["org_id"])
+	}
 	Db.Order("created_at DESC").Find(&vtapGroups)
 
 	for _, vtapGroup := range vtapGroups {
@@ -80,6 +83,7 @@ func GetVtapGroups(filter map[string]interface{}) (resp []model.VtapGroup, err e
 		vtapGroupResp := model.VtapGroup{
 			ID:                 vtapGroup.ID,
 			Name:               vtapGroup.Name,
+			OrgID:              vtapGroup.OrgID,
 			ShortUUID:          vtapGroup.ShortUUID,
 			Lcuuid:             vtapGroup.Lcuuid,
 			UpdatedAt:          vtapGroup.UpdatedAt.Format(common.GO_BIRTHDAY),
@@ -145,12 +149,17 @@ func CreateVtapGroup(vtapGroupCreate model.VtapGroupCreate, cfg *config.Controll
 	vtapGroup.Lcuuid = lcuuid
 	vtapGroup.ShortUUID = shortUUID
 	vtapGroup.Name = vtapGroupCreate.Name
+	vtapGroup.OrgID = vtapGroupCreate.OrgID
+	if vtapGroup.OrgID == 0 {
+		vtapGroup.OrgID = common.DEFAULT_ORG_ID
+	}
 	mysql.Db.Create(&vtapGroup)
 
 	var vtaps []mysql.VTap
 	mysql.Db.Where("lcuuid IN (?)", vtapGroupCreate.VtapLcuuids).Find(&vtaps)
 	for _, vtap := range vtaps {
-		mysql.Db.Model(&vtap).Update("vtap_group_lcuuid", lcuuid)
+		mysql.Db.Model(&vtap).Updates(map[string]interface{}{
+			"vtap_group_lcuuid": lcuuid, "vtap_group_source": common.VTAP_GROUP_SOURCE_MANUAL})
 	}
 
 	response, _ := GetVtapGroups(map[string]interface{}{"lcuuid": lcuuid})
@@ -249,13 +258,15 @@ func UpdateVtapGroup(lcuuid string, vtapGroupUpdate map[string]interface{}, cfg
 		for _, lcuuid := range delVtapLcuuids.ToSlice() {
 			vtap := lcuuidToOldVtap[lcuuid.(string)]
 			// TODO：记录操作日志
-			mysql.Db.Model(vtap).Update("vtap_group_lcuuid", defaultVtapGroup.Lcuuid)
+			mysql.Db.Model(vtap).Updates(map[string]interface{}{
+				"vtap_group_lcuuid": defaultVtapGroup.Lcuuid, "vtap_group_source": common.VTAP_GROUP_SOURCE_MANUAL})
 		}
 
 		for _, lcuuid := range addVtapLcuuids.ToSlice() {
 			vtap := lcuuidToNewVtap[lcuuid.(string)]
 			// TODO：记录操作日志
-			mysql.Db.Model(vtap).Update("vtap_group_lcuuid", vtapGroup.Lcuuid)
+			mysql.Db.Model(vtap).Updates(map[string]interface{}{
+				"vtap_group_lcuuid": vtapGroup.Lcuuid, "vtap_group_source": common.VTAP_GROUP_SOURCE_MANUAL})
 		}
 	}
 
@@ -281,7 +292,8 @@ func DeleteVtapGroup(lcuuid string) (resp map[string]string, err error) {
 
 	log.Infof("delete vtap_group (%s)", vtapGroup.Name)
 
-	mysql.Db.Model(&mysql.VTap{}).Where("vtap_group_lcuuid = ?", lcuuid).Update("vtap_group_lcuuid", defaultVtapGroup.Lcuuid)
+	mysql.Db.Model(&mysql.VTap{}).Where("vtap_group_lcuuid = ?", lcuuid).Updates(map[string]interface{}{
+		"vtap_group_lcuuid": defaultVtapGroup.Lcuuid, "vtap_group_source": common.VTAP_GROUP_SOURCE_MANUAL})
 	mysql.Db.Delete(&vtapGroup)
 	mysql.Db.Where("vtap_group_lcuuid = ?", lcuuid).Delete(&mysql.VTapGroupConfiguration{})
 	refresh.RefreshCache([]common.DataChanged{common.DATA_CHANGED_VTAP})
@@ -0,0 +1,313 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/deepflowio/deepflow/server/controller/common"
+	"github.com/deepflowio/deepflow/server/controller/db/mysql"
+	httpcommon "github.com/deepflowio/deepflow/server/controller/http/common"
+	. "github.com/deepflowio/deepflow/server/controller/http/service/common"
+	"github.com/deepflowio/deepflow/server/controller/model"
+	vtapop "github.com/deepflowio/deepflow/server/controller/trisolaris/vtap"
+)
+
+const VTAP_GROUP_RULE_DEFAULT_PRIORITY = 100
+
+func splitRuleValues(s string) []string {
+	values := []string{}
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+func getVTapGroupLcuuidToName() map[string]string {
+	var vtapGroups []mysql.VTapGroup
+	mysql.Db.Select("lcuuid", "name").Find(&vtapGroups)
+	lcuuidToName := make(map[string]string, len(vtapGroups))
+	for _, vtapGroup := range vtapGroups {
+		lcuuidToName[vtapGroup.Lcuuid] = vtapGroup.Name
+	}
+	return lcuuidToName
+}
+
+func GetVTapGroupRules(filter map[string]interface{}) ([]model.VTapGroupRule, error) {
+	var rules []mysql.VTapGroupRule
+
+	Db := mysql.Db
+	for _, param := range []string{"lcuuid", "name", "vtap_group_lcuuid"} {
+		if _, ok := filter[param]; ok {
+			Db = Db.Where(fmt.Sprintf("%s = ?", param), filter[param])
+		}
+	}
+	if _, ok := filter["org_id"]; ok {
+		Db = Db.Where(
+			"vtap_group_lcuuid IN (?)",
+			mysql.Db.Model(&mysql.VTapGroup{}).Select("lcuuid").Where("org_id = ?", filter["org_id"]),
+		)
+	}
+	if err := Db.Order("priority, id").Find(&rules).Error; err != nil {
+		return nil, NewError(httpcommon.SERVER_ERROR, fmt.Sprintf("get vtap group rules failed: %s", err))
+	}
+	vtapGroupLcuuidToName := getVTapGroupLcuuidToName()
+
+	response := make([]model.VTapGroupRule, 0, len(rules))
+	for _, rule := range rules {
+		vtapTypes := []int{}
+		for _, t := range splitRuleValues(rule.VTapTypes) {
+			if vtapType, err := strconv.Atoi(t); err == nil {
+				vtapTypes = append(vtapTypes, vtapType)
+			}
+		}
+		response = append(response, model.VTapGroupRule{
+			ID:                   rule.ID,
+			Name:                 rule.Name,
+			Priority:             rule.Priority,
+			Enabled:              rule.Enabled,
+			VTapGroupLcuuid:      rule.VTapGroupLcuuid,
+			VTapGroupName:        vtapGroupLcuuidToName[rule.VTapGroupLcuuid],
+			KubernetesClusterIDs: splitRuleValues(rule.KubernetesClusterIDs),
+			Regions:              splitRuleValues(rule.Regions),
+			AZs:                  splitRuleValues(rule.AZs),
+			HostnameRegex:        rule.HostnameRegex,
+			IPCIDRs:              splitRuleValues(rule.IPCIDRs),
+			PodNodeLabels:        splitRuleValues(rule.PodNodeLabels),
+			VTapTypes:            vtapTypes,
+			CreatedAt:            rule.CreatedAt.Format(common.GO_BIRTHDAY),
+			UpdatedAt:            rule.UpdatedAt.Format(common.GO_BIRTHDAY),
+			Lcuuid:               rule.Lcuuid,
+		})
+	}
+	return response, nil
+}
+
+// convertVTapGroupRule validates the rule and converts it to the DB item, id and lcuuid are not set.
+func convertVTapGroupRule(ruleCreate model.VTapGroupRuleCreate) (*mysql.VTapGroupRule, error) {
+	var count int64
+	Db := mysql.Db.Model(&mysql.VTapGroup{}).Where("lcuuid = ?", ruleCreate.VTapGroupLcuuid)
+	if ruleCreate.OrgID != 0 {
+		Db = Db.Where("org_id = ?", ruleCreate.OrgID)
+	}
+	Db.Count(&count)
+	if count == 0 {
+		return nil, NewError(httpcommon.RESOURCE_NOT_FOUND, fmt.Sprintf("vtap group (%s) not found", ruleCreate.VTapGroupLcuuid))
+	}
+	if ruleCreate.Priority < 0 {
+		return nil, NewError(httpcommon.INVALID_PARAMETERS, fmt.Sprintf("priority (%d) invalid", ruleCreate.Priority))
+	}
+
+	vtapTypes := make([]string, 0, len(ruleCreate.VTapTypes))
+	for _, vtapType := range ruleCreate.VTapTypes {
+		vtapTypes = append(vtapTypes, strconv.Itoa(vtapType))
+	}
+	rule := &mysql.VTapGroupRule{
+		Name:                 ruleCreate.Name,
+		Priority:             ruleCreate.Priority,
+		Enabled:              ruleCreate.Enabled == nil || *ruleCreate.Enabled,
+		VTapGroupLcuuid:      ruleCreate.VTapGroupLcuuid,
+		KubernetesClusterIDs: strings.Join(ruleCreate.KubernetesClusterIDs, ","),
+		Regions:              strings.Join(ruleCreate.Regions, ","),
+		AZs:                  strings.Join(ruleCreate.AZs, ","),
+		HostnameRegex:        ruleCreate.HostnameRegex,
+		IPCIDRs:              strings.Join(ruleCreate.IPCIDRs, ","),
+		PodNodeLabels:        strings.Join(ruleCreate.PodNodeLabels, ","),
+		VTapTypes:            strings.Join(vtapTypes, ","),
+	}
+	if rule.Priority == 0 {
+		rule.Priority = VTAP_GROUP_RULE_DEFAULT_PRIORITY
+	}
+	if _, err := vtapop.NewVTapGroupRule(rule); err != nil {
+		return nil, NewError(httpcommon.INVALID_PARAMETERS, fmt.Sprintf("vtap group rule (%s) invalid: %s", ruleCreate.Name, err))
+	}
+	return rule, nil
+}
+
+func CreateVTapGroupRule(ruleCreate model.VTapGroupRuleCreate) (model.VTapGroupRule, error) {
+	var count int64
+	mysql.Db.Model(&mysql.VTapGroupRule{}).Where("name = ?", ruleCreate.Name).Count(&count)
+	if count > 0 {
+		return model.VTapGroupRule{}, NewError(httpcommon.RESOURCE_ALREADY_EXIST, fmt.Sprintf("vtap group rule (%s) already exist", ruleCreate.Name))
+	}
+	rule, err := convertVTapGroupRule(ruleCreate)
+	if err != nil {
+		return model.VTapGroupRule{}, err
+	}
+	rule.Lcuuid = uuid.New().String()
+	if err := mysql.Db.Create(rule).Error; err != nil {
+		return model.VTapGroupRule{}, NewError(httpcommon.SERVER_ERROR, fmt.Sprintf("create vtap group rule (%s) failed: %s", rule.Name, err))
+	}
+	log.Infof("create vtap group rule (%s)", rule.Name)
+
+	response, err := GetVTapGroupRules(map[string]interface{}{"lcuuid": rule.Lcuuid})
+	if err != nil || len(response) == 0 {
+		return model.VTapGroupRule{}, NewError(httpcommon.SERVER_ERROR, fmt.Sprintf("get vtap group rule (%s) failed", rule.Name))
+	}
+	return response[0], nil
+}
+
+func getVTapGroupRule(lcuuid string, orgID int) (model.VTapGroupRule, error) {
+	rules, err := GetVTapGroupRules(map[string]interface{}{"lcuuid": lcuuid, "org_id": orgID})
+	if err != nil {
+		return model.VTapGroupRule{}, err
+	}
+	if len(rules) == 0 {
+		return model.VTapGroupRule{}, NewError(httpcommon.RESOURCE_NOT_FOUND, fmt.Sprintf("vtap group rule (%s) not found", lcuuid))
+	}
+	return rules[0], nil
+}
+
+// UpdateVTapGroupRule only updates the fields in the patch, the rule is validated as a whole.
+func UpdateVTapGroupRule(lcuuid string, orgID int, patch map[string]interface{}) (model.VTapGroupRule, error) {
+	old, err := getVTapGroupRule(lcuuid, orgID)
+	if err != nil {
+		return model.VTapGroupRule{}, err
+	}
+	ruleUpdate := model.VTapGroupRuleCreate{
+		Name:                 old.Name,
+		Priority:             old.Priority,
+		Enabled:              &old.Enabled,
+		VTapGroupLcuuid:      old.VTapGroupLcuuid,
+		KubernetesClusterIDs: old.KubernetesClusterIDs,
+		Regions:              old.Regions,
+		AZs:                  old.AZs,
+		HostnameRegex:        old.HostnameRegex,
+		IPCIDRs:              old.IPCIDRs,
+		PodNodeLabels:        old.PodNodeLabels,
+		VTapTypes:            old.VTapTypes,
+		OrgID:                orgID,
+	}
+	patchBytes, _ := json.Marshal(patch)
+	if err := json.Unmarshal(patchBytes, &ruleUpdate); err != nil {
+		return model.VTapGroupRule{}, NewError(httpcommon.INVALID_PARAMETERS, err.Error())
+	}
+	if ruleUpdate.Name != old.Name {
+		var count int64
+		mysql.Db.Model(&mysql.VTapGroupRule{}).Where("name = ?", ruleUpdate.Name).Count(&count)
+		if count > 0 {
+			return model.VTapGroupRule{}, NewError(httpcommon.RESOURCE_ALREADY_EXIST, fmt.Sprintf("vtap group rule (%s) already exist", ruleUpdate.Name))
+		}
+	}
+	rule, err := convertVTapGroupRule(ruleUpdate)
+	if err != nil {
+		return model.VTapGroupRule{}, err
+	}
+
+	log.Infof("update vtap group rule (%s) config %v", old.Name, patch)
+	dbUpdateMap := map[string]interface{}{
+		"name":                   rule.Name,
+		"priority":               rule.Priority,
+		"enabled":                rule.Enabled,
+		"vtap_group_lcuuid":      rule.VTapGroupLcuuid,
+		"kubernetes_cluster_ids": rule.KubernetesClusterIDs,
+		"regions":                rule.Regions,
+		"azs":                    rule.AZs,
+		"hostname_regex":         rule.HostnameRegex,
+		"ip_cidrs":               rule.IPCIDRs,
+		"pod_node_labels":        rule.PodNodeLabels,
+		"vtap_types":             rule.VTapTypes,
+	}
+	if err := mysql.Db.Model(&mysql.VTapGroupRule{}).Where("lcuuid = ?", lcuuid).Updates(dbUpdateMap).Error; err != nil {
+		return model.VTapGroupRule{}, NewError(httpcommon.SERVER_ERROR, fmt.Sprintf("update vtap group rule (%s) failed: %s", old.Name, err))
+	}
+	return getVTapGroupRule(lcuuid, orgID)
+}
+
+func DeleteVTapGroupRule(lcuuid string, orgID int) (map[string]string, error) {
+	rule, err := getVTapGroupRule(lcuuid, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Infof("delete vtap group rule (%s)", rule.Name)
+	if err := mysql.Db.Where("lcuuid = ?", lcuuid).Delete(&mysql.VTapGroupRule{}).Error; err != nil {
+		return nil, NewError(httpcommon.SERVER_ERROR, fmt.Sprintf("delete vtap group rule (%s) failed: %s", rule.Name, err))
+	}
+	return map[string]string{"LCUUID": lcuuid}, nil
+}
+
+// PreviewVTapGroupRules is a dry run of the rules, returns the agents which will be moved
+// at the next check. If candidate is not nil, it is evaluated together with the existing
+// rules and replaces the rule of the same name.
+func PreviewVTapGroupRules(orgID int, candidate *model.VTapGroupRuleCreate) ([]model.VTapGroupMove, error) {
+	var dbRules []*mysql.VTapGroupRule
+	if err := mysql.Db.Where("enabled = ?", true).Order("priority, id").Find(&dbRules).Error; err != nil {
+		return nil, NewError(httpcommon.SERVER_ERROR, fmt.Sprintf("get vtap group rules failed: %s", err))
+	}
+	if candidate != nil {
+		candidate.OrgID = orgID
+		candidateRule, err := convertVTapGroupRule(*candidate)
+		if err != nil {
+			return nil, err
+		}
+		rules := make([]*mysql.VTapGroupRule, 0, len(dbRules)+1)
+		for _, dbRule := range dbRules {
+			if dbRule.Name == candidate.Name {
+				candidateRule.ID = dbRule.ID
+				continue
+			}
+			rules = append(rules, dbRule)
+		}
+		if candidateRule.Enabled {
+			rules = append(rules, candidateRule)
+		}
+		// new rule is matched after existing rules of the same priority
+		sort.SliceStable(rules, func(i, j int) bool {
+			return rules[i].Priority < rules[j].Priority
+		})
+		dbRules = rules
+	}
+
+	moves, err := vtapop.PlanVTapGroupMoves(mysql.Db, vtapop.CompileVTapGroupRules(dbRules))
+	if err != nil {
+		return nil, NewError(httpcommon.SERVER_ERROR, fmt.Sprintf("preview vtap group rules failed: %s", err))
+	}
+	var orgVTapGroups []mysql.VTapGroup
+	mysql.Db.Select("lcuuid").Where("org_id = ?", orgID).Find(&orgVTapGroups)
+	orgVTapGroupLcuuids := make(map[string]struct{}, len(orgVTapGroups))
+	for _, vtapGroup := range orgVTapGroups {
+		orgVTapGroupLcuuids[vtapGroup.Lcuuid] = struct{}{}
+	}
+	vtapGroupLcuuidToName := getVTapGroupLcuuidToName()
+
+	response := []model.VTapGroupMove{}
+	for _, move := range moves {
+		if _, ok := orgVTapGroupLcuuids[move.ToVTapGroupLcuuid]; !ok {
+			continue
+		}
+		response = append(response, model.VTapGroupMove{
+			VTapLcuuid:          move.VTap.Lcuuid,
+			VTapName:            move.VTap.Name,
+			VTapType:            move.VTap.Type,
+			FromVTapGroupLcuuid: move.FromVTapGroupLcuuid,
+			FromVTapGroupName:   vtapGroupLcuuidToName[move.FromVTapGroupLcuuid],
+			ToVTapGroupLcuuid:   move.ToVTapGroupLcuuid,
+			ToVTapGroupName:     vtapGroupLcuuidToName[move.ToVTapGroupLcuuid],
+			RuleName:            move.Rule.Name,
+		})
+	}
+	return response, nil
+}
@@ -16,7 +16,76 @@
 
 package service
 
-import "testing"
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+
+	"github.com/deepflowio/deepflow/server/controller/db/mysql"
+)
+
+// 两个组织各有一个采集器组及采集器
+func newOrgTestDB(t *testing.T) {
+	db, err := gorm.Open(
+		sqlite.Open(filepath.Join(t.TempDir(), "org_test.db")),
+		&gorm.Config{NamingStrategy: schema.NamingStrategy{SingularTable: true}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&mysql.VTapGroup{}, &mysql.VTap{}, &mysql.Region{}, &mysql.AZ{}); err != nil {
+		t.Fatal(err)
+	}
+	for _, orgID := range []int{1, 2} {
+		groupLcuuid := fmt.Sprintf("vtap-group-%d", orgID)
+		db.Create(&mysql.VTapGroup{ID: orgID, Lcuuid: groupLcuuid, Name: groupLcuuid, ShortUUID: groupLcuuid, OrgID: orgID})
+		db.Create(&mysql.VTap{ID: orgID, Name: fmt.Sprintf("vtap-%d", orgID), VtapGroupLcuuid: groupLcuuid})
+	}
+
+	defaultDB := mysql.Db
+	mysql.Db = db
+	t.Cleanup(func() {
+		mysql.Db = defaultDB
+		sqlDB, _ := db.DB()
+		sqlDB.Close()
+	})
+}
+
+func TestGetVtapGroupsOfOrg(t *testing.T) {
+	newOrgTestDB(t)
+
+	vtapGroups, err := GetVtapGroups(map[string]interface{}{"org_id": 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vtapGroups) != 1 || vtapGroups[0].OrgID != 2 || len(vtapGroups[0].VtapLcuuids) != 1 {
+		t.Errorf("GetVtapGroups() of org 2 = %+v, want the vtap group of org 2", vtapGroups)
+	}
+	vtapGroups, _ = GetVtapGroups(map[string]interface{}{"org_id": 3})
+	if len(vtapGroups) != 0 {
+		t.Errorf("GetVtapGroups() of org 3 = %+v, want empty", vtapGroups)
+	}
+}
+
+func TestGetVtapsOfOrg(t *testing.T) {
+	newOrgTestDB(t)
+
+	vtaps, err := GetVtaps(map[string]interface{}{"org_id": 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vtaps) != 1 || vtaps[0].Name != "vtap-1" {
+		t.Errorf("GetVtaps() of org 1 = %+v, want the vtap of org 1", vtaps)
+	}
+	vtaps, _ = GetVtaps(map[string]interface{}{"org_id": 3})
+	if len(vtaps) != 0 {
+		t.Errorf("GetVtaps() of org 3 = %+v, want empty", vtaps)
+	}
+}
 
 func TestIsVtapGroupShortUUID(t *testing.T) {
 	type args struct {
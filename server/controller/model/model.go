@@ -207,6 +207,7 @@ type VTapRebalanceResult struct {
 type VtapGroup struct {
 	ID                 int      `json:"ID"`
 	Name               string   `json:"NAME"`
+	OrgID              int      `json:"ORG_ID"`
 	UpdatedAt          string   `json:"UPDATED_AT"`
 	ShortUUID          string   `json:"SHORT_UUID"`
 	Lcuuid             string   `json:"LCUUID"`
@@ -221,6 +222,7 @@ type VtapGroupCreate struct {
 	Enable      int      `json:"ENABLE"`
 	VtapLcuuids []string `json:"VTAP_LCUUIDS"`
 	GroupID     string   `json:"GROUP_ID"`
+	OrgID       int      `json:"-"` // set by X-Org-Id header
 }
 
 type VtapGroupUpdate struct {
@@ -285,6 +287,7 @@ type VTapLicenseConsumption struct {
 type Domain struct {
 	ID             string                 `json:"ID"`
 	Name           string                 `json:"NAME"`
+	OrgID          int                    `json:"ORG_ID"`
 	DisplayName    string                 `json:"DISPLAY_NAME"`
 	ClusterID      string                 `json:"CLUSTER_ID"`
 	Type           int                    `json:"TYPE"`
@@ -314,6 +317,7 @@ type DomainCreate struct {
 	IconID              int                    `json:"ICON_ID"`       // TODO: 修改为required
 	ControllerIP        string                 `json:"CONTROLLER_IP"` // TODO: 修改为required
 	Config              map[string]interface{} `json:"CONFIG"`
+	OrgID               int                    `json:"-"` // set by X-Org-Id header
 }
 
 type DomainUpdate struct {
@@ -864,3 +868,244 @@ type MailServer struct {
 	Security string `json:"SECURITY"`
 	Lcuuid   string `json:"LCUUID"`
 }
+
+type APIKeyCreate struct {
+	Name      string `json:"NAME" binding:"required"`
+	Role      string `json:"ROLE" binding:"required"` // viewer, operator or admin
+	OrgID     int    `json:"ORG_ID"`
+	ExpiredAt string `json:"EXPIRED_AT"` // format: 2006-01-02 15:04:05, empty means never expire
+}
+
+type APIKey struct {
+	ID        int    `json:"ID"`
+	Name      string `json:"NAME"`
+	Role      string `json:"ROLE"`
+	OrgID     int    `json:"ORG_ID"`
+	KeyPrefix string `json:"KEY_PREFIX"`
+	Key       string `json:"KEY,omitempty"` // only returned when created
+	ExpiredAt string `json:"EXPIRED_AT"`
+	CreatedAt string `json:"CREATED_AT"`
+	Lcuuid    string `json:"LCUUID"`
+}
+
+type AuditLog struct {
+	ID             int         `json:"ID"`
+	Actor          string      `json:"ACTOR"`
+	AuthMethod     string      `json:"AUTH_METHOD"`
+	OrgID          int         `json:"ORG_ID"`
+	ClientIP       string      `json:"CLIENT_IP"`
+	Method         string      `json:"METHOD"`
+	Route          string      `json:"ROUTE"`
+	Path           string      `json:"PATH"`
+	ResourceType   string      `json:"RESOURCE_TYPE"`
+	ResourceLcuuid string      `json:"RESOURCE_LCUUID"`
+	RequestBody    interface{} `json:"REQUEST_BODY"`
+	Diff           interface{} `json:"DIFF"`
+	StatusCode     int         `json:"STATUS_CODE"`
+	Result         string      `json:"RESULT"`
+	Description    string      `json:"DESCRIPTION"`
+	CreatedAt      string      `json:"CREATED_AT"`
+}
+
+type VTapEnrollmentTokenCreate struct {
+	Name            string `json:"NAME" binding:"required"`
+	VTapGroupLcuuid string `json:"VTAP_GROUP_LCUUID"`
+	VTapGroupID     string `json:"VTAP_GROUP_ID"` // short uuid of the vtap group, used if VTAP_GROUP_LCUUID is empty
+	MaxUses         int    `json:"MAX_USES"`      // 0 means unlimited
+	ExpiredAt       string `json:"EXPIRED_AT"`    // format: 2006-01-02 15:04:05, empty means never expire
+	OrgID           int    `json:"-"`
+}
+
+type VTapEnrollmentToken struct {
+	ID              int    `json:"ID"`
+	Name            string `json:"NAME"`
+	VTapGroupLcuuid string `json:"VTAP_GROUP_LCUUID"`
+	VTapGroupName   string `json:"VTAP_GROUP_NAME"`
+	TokenPrefix     string `json:"TOKEN_PREFIX"`
+	Token           string `json:"TOKEN,omitempty"` // only returned when created
+	MaxUses         int    `json:"MAX_USES"`
+	UsedCount       int    `json:"USED_COUNT"`
+	ExpiredAt       string `json:"EXPIRED_AT"`
+	CreatedAt       string `json:"CREATED_AT"`
+	Lcuuid          string `json:"LCUUID"`
+}
+
+type VTapRegistrationApprove struct {
+	VTapGroupLcuuid string `json:"VTAP_GROUP_LCUUID"` // move the agent to the group when approved, optional
+}
+
+// VTapGroupRuleCreate conditions are ANDed and values of the same condition are ORed,
+// at least one condition is required.
+type VTapGroupRuleCreate struct {
+	Name                 string   `json:"NAME" binding:"required"`
+	Priority             int      `json:"PRIORITY" binding:"min=0"` // rules are matched in ascending order, default: 100
+	Enabled              *bool    `json:"ENABLED"`                  // default: true
+	VTapGroupLcuuid      string   `json:"VTAP_GROUP_LCUUID" binding:"required"`
+	KubernetesClusterIDs []string `json:"KUBERNETES_CLUSTER_IDS"`
+	Regions              []string `json:"REGIONS"` // region lcuuids
+	AZs                  []string `json:"AZS"`     // az lcuuids
+	HostnameRegex        string   `json:"HOSTNAME_REGEX"`
+	IPCIDRs              []string `json:"IP_CIDRS"`        // cidrs of ctrl_ip
+	PodNodeLabels        []string `json:"POD_NODE_LABELS"` // key:value, all of them should match
+	VTapTypes            []int    `json:"VTAP_TYPES"`
+	OrgID                int      `json:"-"`
+}
+
+type VTapGroupRule struct {
+	ID                   int      `json:"ID"`
+	Name                 string   `json:"NAME"`
+	Priority             int      `json:"PRIORITY"`
+	Enabled              bool     `json:"ENABLED"`
+	VTapGroupLcuuid      string   `json:"VTAP_GROUP_LCUUID"`
+	VTapGroupName        string   `json:"VTAP_GROUP_NAME"`
+	KubernetesClusterIDs []string `json:"KUBERNETES_CLUSTER_IDS"`
+	Regions              []string `json:"REGIONS"`
+	AZs                  []string `json:"AZS"`
+	HostnameRegex        string   `json:"HOSTNAME_REGEX"`
+	IPCIDRs              []string `json:"IP_CIDRS"`
+	PodNodeLabels        []string `json:"POD_NODE_LABELS"`
+	VTapTypes            []int    `json:"VTAP_TYPES"`
+	CreatedAt            string   `json:"CREATED_AT"`
+	UpdatedAt            string   `json:"UPDATED_AT"`
+	Lcuuid               string   `json:"LCUUID"`
+}
+
+type VTapGroupMove struct {
+	VTapLcuuid          string `json:"VTAP_LCUUID"`
+	VTapName            string `json:"VTAP_NAME"`
+	VTapType            int    `json:"VTAP_TYPE"`
+	FromVTapGroupLcuuid string `json:"FROM_VTAP_GROUP_LCUUID"`
+	FromVTapGroupName   string `json:"FROM_VTAP_GROUP_NAME"`
+	ToVTapGroupLcuuid   string `json:"TO_VTAP_GROUP_LCUUID"`
+	ToVTapGroupName     string `json:"TO_VTAP_GROUP_NAME"`
+	RuleName            string `json:"RULE_NAME"`
+}
+
+type ResourceGroupCreate struct {
+	Name       string   `json:"NAME" binding:"required"`
+	BusinessID int      `json:"BUSINESS_ID"` // 1: npb, -3: pcap, default: 1
+	Type       int      `json:"TYPE"`        // 1: vm, 2: ip, default: 2
+	VPCID      int      `json:"VPC_ID"`      // required by vm groups
+	IPs        []string `json:"IPS"`         // ip, cidr or ip range such as 10.0.0.1-10.0.0.9
+	VMIDs      []int    `json:"VM_IDS"`      // empty means all vms of the vpc
+	OrgID      int      `json:"-"`           // set by X-Org-Id header
+}
+
+type ResourceGroup struct {
+	ID         int      `json:"ID"`
+	Name       string   `json:"NAME"`
+	OrgID      int      `json:"ORG_ID"`
+	BusinessID int      `json:"BUSINESS_ID"`
+	Type       int      `json:"TYPE"`
+	VPCID      int      `json:"VPC_ID"`
+	IPs        []string `json:"IPS"`
+	VMIDs      []int    `json:"VM_IDS"`
+	CreatedAt  string   `json:"CREATED_AT"`
+	UpdatedAt  string   `json:"UPDATED_AT"`
+	Lcuuid     string   `json:"LCUUID"`
+}
+
+type ACLCreate struct {
+	Name        string `json:"NAME" binding:"required"`
+	State       *int   `json:"STATE"`                                // 0: disable, 1: enable, default: 1
+	Application int    `json:"APPLICATION" binding:"oneof=4 6"`      // 4: pcap, 6: npb
+	TapType     int    `json:"TAP_TYPE"`                             // value of tap type, default: 3
+	SrcGroupIDs []int  `json:"SRC_GROUP_IDS"`                        // resource groups of the same business, empty means any
+	DstGroupIDs []int  `json:"DST_GROUP_IDS"`                        // resource groups of the same business, empty means any
+	Protocol    *int   `json:"PROTOCOL" binding:"omitempty,max=255"` // empty means any
+	SrcPorts    string `json:"SRC_PORTS"`                            // such as 80,443,8000-8080, empty means any
+	DstPorts    string `json:"DST_PORTS"`                            // such as 80,443,8000-8080, empty means any
+	Vlan        int    `json:"VLAN" binding:"min=0,max=4095"`        // 0 means any
+	OrgID       int    `json:"-"`                                    // set by X-Org-Id header
+}
+
+type ACL struct {
+	ID          int    `json:"ID"`
+	Name        string `json:"NAME"`
+	OrgID       int    `json:"ORG_ID"`
+	State       int    `json:"STATE"`
+	Application int    `json:"APPLICATION"`
+	TapType     int    `json:"TAP_TYPE"`
+	SrcGroupIDs []int  `json:"SRC_GROUP_IDS"`
+	DstGroupIDs []int  `json:"DST_GROUP_IDS"`
+	Protocol    *int   `json:"PROTOCOL"`
+	SrcPorts    string `json:"SRC_PORTS"`
+	DstPorts    string `json:"DST_PORTS"`
+	Vlan        int    `json:"VLAN"`
+	CreatedAt   string `json:"CREATED_AT"`
+	UpdatedAt   string `json:"UPDATED_AT"`
+	Lcuuid      string `json:"LCUUID"`
+}
+
+type NpbTunnelCreate struct {
+	Name  string `json:"NAME" binding:"required"`
+	IP    string `json:"IP" binding:"required"`
+	Type  int    `json:"TYPE" binding:"oneof=0 1"` // 0: VXLAN, 1: ERSPAN
+	OrgID int    `json:"-"`                        // set by X-Org-Id header
+}
+
+type NpbTunnel struct {
+	ID        int    `json:"ID"`
+	Name      string `json:"NAME"`
+	OrgID     int    `json:"ORG_ID"`
+	IP        string `json:"IP"`
+	Type      int    `json:"TYPE"`
+	CreatedAt string `json:"CREATED_AT"`
+	UpdatedAt string `json:"UPDATED_AT"`
+	Lcuuid    string `json:"LCUUID"`
+}
+
+type NpbPolicyCreate struct {
+	Name         string `json:"NAME" binding:"required"`
+	State        *int   `json:"STATE"` // 0: disable, 1: enable, default: 1
+	ACLID        int    `json:"ACL_ID" binding:"required"`
+	NpbTunnelID  int    `json:"NPB_TUNNEL_ID" binding:"required"`
+	Vni          int    `json:"VNI"`           // vni of VXLAN or session id of ERSPAN
+	Distribute   *int   `json:"DISTRIBUTE"`    // 0: drop, 1: distribute, default: 1
+	PayloadSlice *int   `json:"PAYLOAD_SLICE"` // empty means the whole packet
+	VTapIDs      []int  `json:"VTAP_IDS"`      // empty means all agents, required by non-default orgs
+	OrgID        int    `json:"-"`             // set by X-Org-Id header
+}
+
+type NpbPolicy struct {
+	ID               int    `json:"ID"`
+	Name             string `json:"NAME"`
+	OrgID            int    `json:"ORG_ID"`
+	State            int    `json:"STATE"`
+	ACLID            int    `json:"ACL_ID"`
+	ACLName          string `json:"ACL_NAME"`
+	NpbTunnelID      int    `json:"NPB_TUNNEL_ID"`
+	NpbTunnelName    string `json:"NPB_TUNNEL_NAME"`
+	Vni              int    `json:"VNI"`
+	Distribute       int    `json:"DISTRIBUTE"`
+	PayloadSlice     *int   `json:"PAYLOAD_SLICE"`
+	VTapIDs          []int  `json:"VTAP_IDS"`
+	PolicyACLGroupID int    `json:"POLICY_ACL_GROUP_ID"` // acl_gid of flow logs
+	CreatedAt        string `json:"CREATED_AT"`
+	UpdatedAt        string `json:"UPDATED_AT"`
+	Lcuuid           string `json:"LCUUID"`
+}
+
+type PcapPolicyCreate struct {
+	Name         string `json:"NAME" binding:"required"`
+	State        *int   `json:"STATE"` // 0: disable, 1: enable, default: 1
+	ACLID        int    `json:"ACL_ID" binding:"required"`
+	PayloadSlice *int   `json:"PAYLOAD_SLICE"` // empty means the whole packet
+	VTapIDs      []int  `json:"VTAP_IDS"`      // empty means all agents, required by non-default orgs
+	OrgID        int    `json:"-"`             // set by X-Org-Id header
+}
+
+type PcapPolicy struct {
+	ID               int    `json:"ID"`
+	Name             string `json:"NAME"`
+	OrgID            int    `json:"ORG_ID"`
+	State            int    `json:"STATE"`
+	ACLID            int    `json:"ACL_ID"`
+	ACLName          string `json:"ACL_NAME"`
+	PayloadSlice     *int   `json:"PAYLOAD_SLICE"`
+	VTapIDs          []int  `json:"VTAP_IDS"`
+	PolicyACLGroupID int    `json:"POLICY_ACL_GROUP_ID"` // acl_gid of flow logs
+	CreatedAt        string `json:"CREATED_AT"`
+	UpdatedAt        string `json:"UPDATED_AT"`
+	Lcuuid           string `json:"LCUUID"`
+}
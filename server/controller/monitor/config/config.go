@@ -33,4 +33,6 @@ type MonitorConfig struct {
 	RebalanceCheckInterval      int     `default:"300" yaml:"rebalance_check_interval"`   // unit: second
 	VTapAutoDeleteInterval      int     `default:"3600" yaml:"vtap_auto_delete_interval"` // uint: second
 	Warrant                     Warrant `yaml:"warrant"`
+
+	VTapGroupRuleCheckInterval int `default:"300" yaml:"vtap_group_rule_check_interval"` // unit: second, 0 means disabled
 }
@@ -0,0 +1,138 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vtap
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/deepflowio/deepflow/server/controller/common"
+	"github.com/deepflowio/deepflow/server/controller/db/mysql"
+	"github.com/deepflowio/deepflow/server/controller/monitor/config"
+	vtapop "github.com/deepflowio/deepflow/server/controller/trisolaris/vtap"
+	"github.com/deepflowio/deepflow/server/libs/eventapi"
+	"github.com/deepflowio/deepflow/server/libs/queue"
+)
+
+// VTapGroupRuleCheck periodically moves agents to the vtap group of the first matched rule
+type VTapGroupRuleCheck struct {
+	parentCtx  context.Context
+	vCtx       context.Context
+	vCancel    context.CancelFunc
+	cfg        config.MonitorConfig
+	eventQueue *queue.OverwriteQueue
+}
+
+func NewVTapGroupRuleCheck(cfg config.MonitorConfig, ctx context.Context, eventQueue *queue.OverwriteQueue) *VTapGroupRuleCheck {
+	return &VTapGroupRuleCheck{
+		parentCtx:  ctx,
+		cfg:        cfg,
+		eventQueue: eventQueue,
+	}
+}
+
+func (c *VTapGroupRuleCheck) Start() {
+	if c.cfg.VTapGroupRuleCheckInterval <= 0 {
+		log.Info("vtap group rule check disabled")
+		return
+	}
+	log.Info("vtap group rule check start")
+	// check may be restarted when this controller becomes master again
+	c.vCtx, c.vCancel = context.WithCancel(c.parentCtx)
+	ctx := c.vCtx
+	go func() {
+		ticker := time.NewTicker(time.Duration(c.cfg.VTapGroupRuleCheckInterval) * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				c.check()
+			}
+		}
+	}()
+}
+
+func (c *VTapGroupRuleCheck) Stop() {
+	if c.vCancel != nil {
+		c.vCancel()
+	}
+	log.Info("vtap group rule check stopped")
+}
+
+func (c *VTapGroupRuleCheck) check() {
+	rules, err := vtapop.LoadVTapGroupRules(mysql.Db)
+	if err != nil {
+		log.Errorf("load vtap group rules failed, (%v)", err)
+		return
+	}
+	moves, err := vtapop.PlanVTapGroupMoves(mysql.Db, rules)
+	if err != nil {
+		log.Errorf("plan vtap group moves failed, (%v)", err)
+		return
+	}
+	if len(moves) == 0 {
+		return
+	}
+
+	var vtapGroups []mysql.VTapGroup
+	mysql.Db.Select("lcuuid", "name").Find(&vtapGroups)
+	lcuuidToGroupName := make(map[string]string, len(vtapGroups))
+	for _, vtapGroup := range vtapGroups {
+		lcuuidToGroupName[vtapGroup.Lcuuid] = vtapGroup.Name
+	}
+
+	for _, move := range moves {
+		// only update agents which are not changed since planned
+		moved, err := vtapop.ApplyVTapGroupMove(mysql.Db, move)
+		if err != nil {
+			log.Errorf("move vtap (%s) to vtap group (%s) failed, (%v)", move.VTap.Name, move.ToVTapGroupLcuuid, err)
+			continue
+		}
+		if !moved {
+			continue
+		}
+		description := fmt.Sprintf("agent %s moved from vtap group %s to %s by rule %s", move.VTap.Name,
+			lcuuidToGroupName[move.FromVTapGroupLcuuid], lcuuidToGroupName[move.ToVTapGroupLcuuid], move.Rule.Name)
+		log.Info(description)
+		c.enqueueEvent(move.VTap, description)
+	}
+}
+
+func (c *VTapGroupRuleCheck) enqueueEvent(vtap *mysql.VTap, description string) {
+	if c.eventQueue == nil {
+		return
+	}
+	instanceType := common.VTAP_TYPE_TO_DEVICE_TYPE[vtap.Type]
+	if instanceType == 0 || vtap.LaunchServerID == 0 {
+		return
+	}
+	event := eventapi.AcquireResourceEvent()
+	event.Time = time.Now().Unix()
+	event.TimeMilli = time.Now().UnixMilli()
+	event.Type = eventapi.RESOURCE_EVENT_TYPE_UPDATE_VTAP_GROUP
+	event.InstanceType = uint32(instanceType)
+	event.InstanceID = uint32(vtap.LaunchServerID)
+	event.InstanceName = vtap.Name
+	event.Description = description
+	event.IfNeedTagged = true
+	if err := c.eventQueue.Put(event); err != nil {
+		log.Errorf("put vtap (%s) event into shared queue failed, (%v)", vtap.Name, err)
+	}
+}
@@ -618,6 +618,9 @@ func (c *Cache) AddVInterfaces(items []*mysql.VInterface) {
 
 func (c *Cache) UpdateVInterface(cloudItem *cloudmodel.VInterface) {
 	c.ToolDataSet.updateVInterface(cloudItem)
+	if diffBase, ok := c.DiffBaseDataSet.VInterfaces[cloudItem.Lcuuid]; ok {
+		diffBase.updateDevice(c.ToolDataSet.vinterfaceLcuuidToDeviceType[cloudItem.Lcuuid], c.ToolDataSet.vinterfaceLcuuidToDeviceID[cloudItem.Lcuuid])
+	}
 }
 
 func (c *Cache) DeleteVInterfaces(lcuuids []string) {
@@ -31,6 +31,7 @@ func (b *DiffBaseDataSet) addPodNode(dbItem *mysql.PodNode, seq int) {
 		State:           dbItem.State,
 		VCPUNum:         dbItem.VCPUNum,
 		MemTotal:        dbItem.MemTotal,
+		Label:           dbItem.Label,
 		RegionLcuuid:    dbItem.Region,
 		AZLcuuid:        dbItem.AZ,
 		SubDomainLcuuid: dbItem.SubDomain,
@@ -48,6 +49,7 @@ type PodNode struct {
 	State           int    `json:"state"`
 	VCPUNum         int    `json:"vcpu_num"`
 	MemTotal        int    `json:"mem_total"`
+	Label           string `json:"label"`
 	RegionLcuuid    string `json:"region_lcuuid"`
 	AZLcuuid        string `json:"az_lcuuid"`
 	SubDomainLcuuid string `json:"sub_domain_lcuuid"`
@@ -57,6 +59,7 @@ func (p *PodNode) Update(cloudItem *cloudmodel.PodNode) {
 	p.State = cloudItem.State
 	p.VCPUNum = cloudItem.VCPUNum
 	p.MemTotal = cloudItem.MemTotal
+	p.Label = cloudItem.Label
 	p.RegionLcuuid = cloudItem.RegionLcuuid
 	p.AZLcuuid = cloudItem.AZLcuuid
 	p.SubDomainLcuuid = cloudItem.SubDomainLcuuid
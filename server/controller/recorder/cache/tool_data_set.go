@@ -507,6 +507,10 @@ func (t *ToolDataSet) addVInterface(item *mysql.VInterface) {
 
 func (t *ToolDataSet) updateVInterface(cloudItem *cloudmodel.VInterface) {
 	t.vinterfaceLcuuidToType[cloudItem.Lcuuid] = cloudItem.Type
+	if deviceID, ok := t.GetDeviceIDByDeviceLcuuid(cloudItem.DeviceType, cloudItem.DeviceLcuuid); ok {
+		t.vinterfaceLcuuidToDeviceType[cloudItem.Lcuuid] = cloudItem.DeviceType
+		t.vinterfaceLcuuidToDeviceID[cloudItem.Lcuuid] = deviceID
+	}
 	log.Info(updateToolMap(RESOURCE_TYPE_VINTERFACE_EN, cloudItem.Lcuuid))
 }
 
@@ -35,6 +35,8 @@ func (b *DiffBaseDataSet) addVInterface(dbItem *mysql.VInterface, seq int, toolD
 		Name:            dbItem.Name,
 		Type:            dbItem.Type,
 		TapMac:          dbItem.TapMac,
+		DeviceType:      dbItem.DeviceType,
+		DeviceID:        dbItem.DeviceID,
 		NetworkLcuuid:   networkLcuuid,
 		RegionLcuuid:    dbItem.Region,
 		SubDomainLcuuid: dbItem.SubDomain,
@@ -52,6 +54,8 @@ type VInterface struct {
 	Name            string `json:"name"`
 	Type            int    `json:"type"`
 	TapMac          string `json:"tap_mac"`
+	DeviceType      int    `json:"device_type"`
+	DeviceID        int    `json:"device_id"`
 	NetnsID         uint32 `json:"netns_id"`
 	VtapID          uint32 `json:"vtap_id"`
 	NetworkLcuuid   string `json:"network_lcuuid"`
@@ -70,3 +74,9 @@ func (v *VInterface) Update(cloudItem *cloudmodel.VInterface) {
 	v.SubDomainLcuuid = cloudItem.SubDomainLcuuid
 	log.Info(updateDiffBase(RESOURCE_TYPE_VINTERFACE_EN, v))
 }
+
+// 设备 ID 需通过 ToolDataSet 获取，由 Cache.UpdateVInterface 更新
+func (v *VInterface) updateDevice(deviceType, deviceID int) {
+	v.DeviceType = deviceType
+	v.DeviceID = deviceID
+}
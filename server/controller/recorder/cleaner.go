@@ -125,6 +125,17 @@ func (c *Cleaner) cleanDeletedData(retentionInterval int) {
 	forceDelete[mysql.Process](expiredAt)
 	forceDelete[mysql.PrometheusTarget](expiredAt)
 	log.Info("clean soft deleted resources completed")
+	c.cleanResourceHistory(int(c.cfg.ResourceHistoryRetentionTime))
+}
+
+// 清理有效区间结束时间早于保留时间的资源历史数据
+// clean resource history whose valid interval ended before the retention time
+func (c *Cleaner) cleanResourceHistory(retentionInterval int) {
+	expiredAt := time.Now().Add(time.Duration(-retentionInterval) * time.Hour)
+	err := mysql.Db.Where("valid_to < ?", expiredAt).Delete(&mysql.ResourceHistory{}).Error
+	if err != nil {
+		log.Errorf("mysql delete resource history failed: %v", err)
+	}
 }
 
 func getIDs[MT constraint.MySQLModel]() (ids []int) {
@@ -66,6 +66,7 @@ const (
 	RESOURCE_TYPE_PROCESS_EN                  = "process"
 	RESOURCE_TYPE_PROMETHEUS_TARGET_EN        = "prometheus_target"
 	RESOURCE_TYPE_VIP_EN                      = "vip"
+	RESOURCE_TYPE_K8S_EVENT_EN                = "k8s_event"
 )
 
 const (
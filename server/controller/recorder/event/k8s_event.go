@@ -0,0 +1,198 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package event
+
+import (
+	"strconv"
+	"time"
+
+	"gorm.io/gorm/clause"
+
+	cloudmodel "github.com/deepflowio/deepflow/server/controller/cloud/model"
+	"github.com/deepflowio/deepflow/server/controller/common"
+	"github.com/deepflowio/deepflow/server/controller/db/mysql"
+	"github.com/deepflowio/deepflow/server/controller/recorder/cache"
+	. "github.com/deepflowio/deepflow/server/controller/recorder/common"
+	"github.com/deepflowio/deepflow/server/libs/eventapi"
+	"github.com/deepflowio/deepflow/server/libs/queue"
+)
+
+// kinds of involved objects which are synced as pod groups
+var k8sEventPodGroupKinds = []string{
+	"Deployment", "StatefulSet", "DaemonSet", "ReplicaSet", "ReplicationController", "CloneSet",
+	"Job", "CronJob", "Rollout",
+}
+
+type k8sEventState struct {
+	count    int
+	lastTime time.Time
+}
+
+// Kubernetes events are synced as a snapshot every time, K8sEvent remembers the
+// events already enqueued and only enqueues new events or events occurred again.
+// The last time of enqueued events is persisted in k8s_event_position, so that events
+// occurred while the controller restarts or the master changes are not lost.
+type K8sEvent struct {
+	EventManagerBase
+	domainLcuuid    string
+	subDomainLcuuid string
+	lastTime        time.Time
+	states          map[string]k8sEventState
+}
+
+func NewK8sEvent(domainLcuuid, subDomainLcuuid string, toolDS *cache.ToolDataSet, eq *queue.OverwriteQueue) *K8sEvent {
+	k := &K8sEvent{
+		EventManagerBase: EventManagerBase{
+			resourceType: RESOURCE_TYPE_K8S_EVENT_EN,
+			ToolDataSet:  toolDS,
+			Queue:        eq,
+		},
+		domainLcuuid:    domainLcuuid,
+		subDomainLcuuid: subDomainLcuuid,
+		states:          make(map[string]k8sEventState),
+	}
+	var position mysql.K8sEventPosition
+	result := mysql.Db.Where("domain = ? AND sub_domain = ?", domainLcuuid, subDomainLcuuid).Limit(1).Find(&position)
+	if result.Error != nil {
+		log.Errorf("db query k8s_event_position failed: %s", result.Error.Error())
+	} else if result.RowsAffected > 0 {
+		k.lastTime = position.LastTime
+	}
+	return k
+}
+
+func (k *K8sEvent) ProduceFromCloud(items []cloudmodel.K8sEvent) {
+	states := make(map[string]k8sEventState, len(items))
+	lastTime := k.lastTime
+	for _, item := range items {
+		state := k8sEventState{count: item.Count, lastTime: item.LastTime}
+		states[item.Lcuuid] = state
+		if !k.isNew(item.Lcuuid, state) {
+			continue
+		}
+		k.enqueueK8sEvent(item)
+		if item.LastTime.After(lastTime) {
+			lastTime = item.LastTime
+		}
+	}
+	// events expired in kubernetes are removed
+	k.states = states
+	if lastTime.After(k.lastTime) {
+		k.savePosition(lastTime)
+	}
+}
+
+// Events not remembered are new only if they occurred after the persisted position, the
+// precision of the time is second, events occurred in the same second as the position
+// after restart are dropped to avoid enqueuing duplicated events
+func (k *K8sEvent) isNew(lcuuid string, state k8sEventState) bool {
+	old, ok := k.states[lcuuid]
+	if !ok {
+		return state.lastTime.After(k.lastTime)
+	}
+	return state.count > old.count || state.lastTime.After(old.lastTime)
+}
+
+func (k *K8sEvent) savePosition(lastTime time.Time) {
+	position := mysql.K8sEventPosition{Domain: k.domainLcuuid, SubDomain: k.subDomainLcuuid, LastTime: lastTime}
+	err := mysql.Db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "domain"}, {Name: "sub_domain"}},
+		DoUpdates: clause.AssignmentColumns([]string{"last_time"}),
+	}).Create(&position).Error
+	if err != nil {
+		log.Errorf("save k8s_event_position (domain: %s, sub_domain: %s) failed: %s", k.domainLcuuid, k.subDomainLcuuid, err.Error())
+		return
+	}
+	k.lastTime = lastTime
+}
+
+func (k *K8sEvent) enqueueK8sEvent(item cloudmodel.K8sEvent) {
+	instanceType, instanceID, opts := k.getInstanceOptions(item)
+	opts = append(opts, []eventapi.TagFieldOption{
+		eventapi.TagK8sEventType(item.Type),
+		eventapi.TagDescription(item.Message),
+		eventapi.TagAttributes(
+			[]string{"k8s_event_type", "involved_object_kind", "involved_object_name", "count"},
+			[]string{item.Type, item.InvolvedObjectKind, item.InvolvedObjectName, strconv.Itoa(item.Count)},
+		),
+	}...)
+
+	event := eventapi.AcquireResourceEvent()
+	k.fillEvent(event, item.Reason, item.InvolvedObjectName, instanceType, instanceID, opts...)
+	event.Time = item.LastTime.Unix()
+	event.TimeMilli = item.LastTime.UnixMilli()
+	// tags are filled by recorder, because the involved object may have been deleted
+	event.IfNeedTagged = false
+	k.enqueue(item.Lcuuid, event)
+}
+
+// Resolve the involved object to pod, pod node or pod group, other objects only have namespace level tags
+func (k *K8sEvent) getInstanceOptions(item cloudmodel.K8sEvent) (int, int, []eventapi.TagFieldOption) {
+	switch {
+	case item.InvolvedObjectKind == "Pod":
+		if id, ok := k.ToolDataSet.GetPodIDByLcuuid(item.InvolvedObjectLcuuid); ok {
+			opts, err := getPodOptionsByID(k.ToolDataSet, id)
+			if err == nil {
+				if info, err := k.ToolDataSet.GetPodInfoByID(id); err == nil {
+					l3DeviceOpts, _ := getL3DeviceOptionsByPodNodeID(k.ToolDataSet, info.PodNodeID)
+					opts = append(opts, l3DeviceOpts...)
+				}
+				return common.VIF_DEVICE_TYPE_POD, id, opts
+			}
+			log.Error(err)
+		}
+	case item.InvolvedObjectKind == "Node":
+		if id := k.ToolDataSet.GetPodNodeIDByLcuuid(item.InvolvedObjectLcuuid); id != 0 {
+			opts, err := getPodNodeOptionsByID(k.ToolDataSet, id)
+			if err == nil {
+				l3DeviceOpts, _ := getL3DeviceOptionsByPodNodeID(k.ToolDataSet, id)
+				opts = append(opts, l3DeviceOpts...)
+				return common.VIF_DEVICE_TYPE_POD_NODE, id, opts
+			}
+			log.Error(err)
+		}
+	case common.Contains(k8sEventPodGroupKinds, item.InvolvedObjectKind):
+		if id, ok := k.ToolDataSet.GetPodGroupIDByLcuuid(item.InvolvedObjectLcuuid); ok {
+			opts := append(k.getNamespaceOptions(item), eventapi.TagPodGroupID(id))
+			return common.VIF_DEVICE_TYPE_POD_GROUP, id, opts
+		}
+	}
+	log.Debugf("%s (lcuuid: %s) involved object %s %s not found", k.resourceType, item.Lcuuid, item.InvolvedObjectKind, item.InvolvedObjectName)
+	return 0, 0, k.getNamespaceOptions(item)
+}
+
+func (k *K8sEvent) getNamespaceOptions(item cloudmodel.K8sEvent) []eventapi.TagFieldOption {
+	var opts []eventapi.TagFieldOption
+	if id, ok := k.ToolDataSet.GetRegionIDByLcuuid(item.RegionLcuuid); ok {
+		opts = append(opts, eventapi.TagRegionID(id))
+	}
+	if id, ok := k.ToolDataSet.GetAZIDByLcuuid(item.AZLcuuid); ok {
+		opts = append(opts, eventapi.TagAZID(id))
+	}
+	if id, ok := k.ToolDataSet.GetVPCIDByLcuuid(item.VPCLcuuid); ok {
+		opts = append(opts, eventapi.TagVPCID(id))
+	}
+	if id, ok := k.ToolDataSet.GetPodClusterIDByLcuuid(item.PodClusterLcuuid); ok {
+		opts = append(opts, eventapi.TagPodClusterID(id))
+	}
+	if item.PodNamespaceLcuuid != "" {
+		if id, ok := k.ToolDataSet.GetPodNamespaceIDByLcuuid(item.PodNamespaceLcuuid); ok {
+			opts = append(opts, eventapi.TagPodNSID(id))
+		}
+	}
+	return opts
+}
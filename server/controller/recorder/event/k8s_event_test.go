@@ -0,0 +1,162 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package event
+
+import (
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/agiledragon/gomonkey/v2"
+	"github.com/stretchr/testify/assert"
+
+	cloudmodel "github.com/deepflowio/deepflow/server/controller/cloud/model"
+	"github.com/deepflowio/deepflow/server/controller/common"
+	"github.com/deepflowio/deepflow/server/controller/db/mysql"
+	"github.com/deepflowio/deepflow/server/controller/recorder/cache"
+	"github.com/deepflowio/deepflow/server/controller/recorder/test"
+	"github.com/deepflowio/deepflow/server/libs/eventapi"
+)
+
+const K8S_EVENT_TEST_DB_FILE = "./k8s_event_test.db"
+
+func setupK8sEventDB(t *testing.T) {
+	os.Remove(K8S_EVENT_TEST_DB_FILE)
+	mysql.Db = test.GetDB(K8S_EVENT_TEST_DB_FILE)
+	for _, val := range test.GetModels() {
+		mysql.Db.AutoMigrate(val)
+	}
+	t.Cleanup(func() {
+		sqlDB, _ := mysql.Db.DB()
+		sqlDB.Close()
+		os.Remove(K8S_EVENT_TEST_DB_FILE)
+	})
+}
+
+func newCloudK8sEvent(kind string, count int, lastTime time.Time) cloudmodel.K8sEvent {
+	return cloudmodel.K8sEvent{
+		Lcuuid:               RandLcuuid(),
+		Type:                 "Warning",
+		Reason:               "BackOff",
+		Count:                count,
+		InvolvedObjectKind:   kind,
+		InvolvedObjectName:   RandName(),
+		InvolvedObjectLcuuid: RandLcuuid(),
+		LastTime:             lastTime,
+	}
+}
+
+func TestK8sEventDedup(t *testing.T) {
+	setupK8sEventDB(t)
+	ds := cache.NewToolDataSet()
+	eq := NewEventQueue()
+	k8sEvent := NewK8sEvent(RandLcuuid(), "", &ds, eq)
+
+	now := time.Now().Truncate(time.Second)
+	item := newCloudK8sEvent("Service", 1, now)
+	k8sEvent.ProduceFromCloud([]cloudmodel.K8sEvent{item})
+	assert.Equal(t, 1, eq.Len())
+	eq.Get()
+
+	// the same snapshot is synced again
+	k8sEvent.ProduceFromCloud([]cloudmodel.K8sEvent{item})
+	assert.Equal(t, 0, eq.Len())
+
+	// the event occurred again in the same second
+	item.Count = 2
+	k8sEvent.ProduceFromCloud([]cloudmodel.K8sEvent{item})
+	assert.Equal(t, 1, eq.Len())
+	e := eq.Get().(*eventapi.ResourceEvent)
+	assert.Equal(t, item.LastTime.Unix(), e.Time)
+	assert.Equal(t, "BackOff", e.Type)
+
+	// a new event
+	another := newCloudK8sEvent("Service", 1, now.Add(time.Second))
+	k8sEvent.ProduceFromCloud([]cloudmodel.K8sEvent{item, another})
+	assert.Equal(t, 1, eq.Len())
+}
+
+func TestK8sEventPosition(t *testing.T) {
+	setupK8sEventDB(t)
+	ds := cache.NewToolDataSet()
+	eq := NewEventQueue()
+	domainLcuuid := RandLcuuid()
+	subDomainLcuuid := RandLcuuid()
+
+	now := time.Now().Truncate(time.Second)
+	// events occurred before the first sync are enqueued
+	old := newCloudK8sEvent("Service", 1, now.Add(-time.Hour))
+	NewK8sEvent(domainLcuuid, subDomainLcuuid, &ds, eq).ProduceFromCloud([]cloudmodel.K8sEvent{old})
+	assert.Equal(t, 1, eq.Len())
+	eq.Get()
+
+	var position mysql.K8sEventPosition
+	mysql.Db.Where("domain = ? AND sub_domain = ?", domainLcuuid, subDomainLcuuid).First(&position)
+	assert.True(t, old.LastTime.Equal(position.LastTime))
+
+	// after restart, only events occurred after the persisted position are enqueued
+	occurredWhileRestarting := newCloudK8sEvent("Service", 1, now.Add(-time.Minute))
+	k8sEvent := NewK8sEvent(domainLcuuid, subDomainLcuuid, &ds, eq)
+	assert.True(t, old.LastTime.Equal(k8sEvent.lastTime))
+	k8sEvent.ProduceFromCloud([]cloudmodel.K8sEvent{old, occurredWhileRestarting})
+	assert.Equal(t, 1, eq.Len())
+	e := eq.Get().(*eventapi.ResourceEvent)
+	assert.Equal(t, occurredWhileRestarting.LastTime.Unix(), e.Time)
+
+	// positions of other sub_domains are not affected
+	assert.True(t, NewK8sEvent(domainLcuuid, "", &ds, eq).lastTime.IsZero())
+}
+
+func TestK8sEventInvolvedObject(t *testing.T) {
+	setupK8sEventDB(t)
+	ds := cache.NewToolDataSet()
+	podGroupID := RandID()
+	monkey := gomonkey.ApplyPrivateMethod(reflect.TypeOf(&ds), "GetPodGroupIDByLcuuid", func(_ *cache.ToolDataSet, _ string) (int, bool) {
+		return podGroupID, true
+	})
+	defer monkey.Reset()
+	podNSID := RandID()
+	monkey1 := gomonkey.ApplyPrivateMethod(reflect.TypeOf(&ds), "GetPodNamespaceIDByLcuuid", func(_ *cache.ToolDataSet, _ string) (int, bool) {
+		return podNSID, true
+	})
+	defer monkey1.Reset()
+
+	eq := NewEventQueue()
+	k8sEvent := NewK8sEvent(RandLcuuid(), "", &ds, eq)
+	now := time.Now()
+	deployment := newCloudK8sEvent("Deployment", 1, now)
+	deployment.PodNamespaceLcuuid = RandLcuuid()
+	service := newCloudK8sEvent("Service", 1, now)
+	service.PodNamespaceLcuuid = RandLcuuid()
+	k8sEvent.ProduceFromCloud([]cloudmodel.K8sEvent{deployment, service})
+	assert.Equal(t, 2, eq.Len())
+
+	e := eq.Get().(*eventapi.ResourceEvent)
+	assert.Equal(t, uint32(common.VIF_DEVICE_TYPE_POD_GROUP), e.InstanceType)
+	assert.Equal(t, uint32(podGroupID), e.InstanceID)
+	assert.Equal(t, uint32(podGroupID), e.PodGroupID)
+	assert.Equal(t, uint32(podNSID), e.PodNSID)
+	assert.Equal(t, deployment.InvolvedObjectName, e.InstanceName)
+
+	// objects other than pods, nodes and pod groups only have namespace level tags
+	e = eq.Get().(*eventapi.ResourceEvent)
+	assert.Equal(t, uint32(0), e.InstanceType)
+	assert.Equal(t, uint32(0), e.InstanceID)
+	assert.Equal(t, uint32(podNSID), e.PodNSID)
+	assert.Equal(t, service.InvolvedObjectName, e.InstanceName)
+}
@@ -0,0 +1,72 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// 记录关键资源的有效时间区间，用于查询历史时刻存在的资源及 IP 归属
+// records the valid intervals of key resources, used to look up the resources
+// existed and the owners of ips at a past time
+package history
+
+import (
+	"time"
+
+	"github.com/op/go-logging"
+
+	"github.com/deepflowio/deepflow/server/controller/db/mysql"
+	"github.com/deepflowio/deepflow/server/controller/recorder/cache"
+)
+
+var log = logging.MustGetLogger("recorder.history")
+
+type RecorderBase struct {
+	resourceType string
+	ToolDataSet  *cache.ToolDataSet
+}
+
+// 资源新增时打开有效区间
+func (r *RecorderBase) open(items []*mysql.ResourceHistory) {
+	openHistories(r.resourceType, items)
+}
+
+// 资源删除时关闭有效区间
+func (r *RecorderBase) close(lcuuids []string) {
+	closeHistories(r.resourceType, lcuuids)
+}
+
+func openHistories(resourceType string, items []*mysql.ResourceHistory) {
+	if len(items) == 0 {
+		return
+	}
+	now := time.Now()
+	for _, item := range items {
+		item.ResourceType = resourceType
+		item.ValidFrom = now
+	}
+	if err := mysql.Db.Create(&items).Error; err != nil {
+		log.Errorf("add %s history failed: %v", resourceType, err)
+	}
+}
+
+func closeHistories(resourceType string, lcuuids []string) {
+	if len(lcuuids) == 0 {
+		return
+	}
+	err := mysql.Db.Model(&mysql.ResourceHistory{}).
+		Where("resource_type = ? AND lcuuid IN ? AND valid_to IS NULL", resourceType, lcuuids).
+		Update("valid_to", time.Now()).Error
+	if err != nil {
+		log.Errorf("close %s history failed: %v", resourceType, err)
+	}
+}
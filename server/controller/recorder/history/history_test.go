@@ -0,0 +1,173 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package history
+
+import (
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	cloudmodel "github.com/deepflowio/deepflow/server/controller/cloud/model"
+	"github.com/deepflowio/deepflow/server/controller/common"
+	"github.com/deepflowio/deepflow/server/controller/db/mysql"
+	"github.com/deepflowio/deepflow/server/controller/http/service/resource"
+	"github.com/deepflowio/deepflow/server/controller/recorder/cache"
+	. "github.com/deepflowio/deepflow/server/controller/recorder/common"
+	"github.com/deepflowio/deepflow/server/controller/recorder/test"
+)
+
+const TEST_DB_FILE = "./history_test.db"
+
+func TestMain(m *testing.M) {
+	os.Remove(TEST_DB_FILE)
+	mysql.Db = test.GetDB(TEST_DB_FILE)
+	for _, val := range test.GetModels() {
+		mysql.Db.AutoMigrate(val)
+	}
+	exitCode := m.Run()
+
+	sqlDB, _ := mysql.Db.DB()
+	sqlDB.Close()
+	os.Remove(TEST_DB_FILE)
+	os.Exit(exitCode)
+}
+
+func getHistories(resourceType, lcuuid string) []*mysql.ResourceHistory {
+	var histories []*mysql.ResourceHistory
+	mysql.Db.Where("resource_type = ? AND lcuuid = ?", resourceType, lcuuid).Order("id").Find(&histories)
+	return histories
+}
+
+func newVM(id int, name, domain string) *mysql.VM {
+	vm := &mysql.VM{Base: mysql.Base{ID: id, Lcuuid: uuid.NewString()}, Name: name, Domain: domain}
+	mysql.Db.Create(vm)
+	return vm
+}
+
+func TestVMHistory(t *testing.T) {
+	domain := uuid.NewString()
+	c := cache.NewCache(domain)
+	vm := newVM(10, "vm-old", domain)
+	c.AddVMs([]*mysql.VM{vm})
+	recorder := NewVM(&c.ToolDataSet)
+
+	recorder.RecordByAdd([]*mysql.VM{vm})
+	histories := getHistories(RESOURCE_TYPE_VM_EN, vm.Lcuuid)
+	assert.Equal(t, 1, len(histories))
+	assert.Equal(t, "vm-old", histories[0].Name)
+	assert.Equal(t, domain, histories[0].Domain)
+	assert.Nil(t, histories[0].ValidTo)
+
+	// updates other than renaming do not change the interval
+	recorder.RecordByUpdate(&cloudmodel.VM{Lcuuid: vm.Lcuuid, Name: "vm-old"}, &cache.VM{Name: "vm-old"})
+	assert.Equal(t, 1, len(getHistories(RESOURCE_TYPE_VM_EN, vm.Lcuuid)))
+
+	recorder.RecordByUpdate(&cloudmodel.VM{Lcuuid: vm.Lcuuid, Name: "vm-new"}, &cache.VM{Name: "vm-old"})
+	histories = getHistories(RESOURCE_TYPE_VM_EN, vm.Lcuuid)
+	assert.Equal(t, 2, len(histories))
+	assert.NotNil(t, histories[0].ValidTo)
+	assert.Equal(t, "vm-new", histories[1].Name)
+	assert.Equal(t, vm.ID, histories[1].ResourceID)
+	assert.Equal(t, domain, histories[1].Domain)
+	assert.Nil(t, histories[1].ValidTo)
+
+	recorder.RecordByDelete([]string{vm.Lcuuid})
+	for _, history := range getHistories(RESOURCE_TYPE_VM_EN, vm.Lcuuid) {
+		assert.NotNil(t, history.ValidTo)
+	}
+}
+
+// 设备变化时关闭 IP 的区间并按新设备重新打开
+func TestIPHistoryDeviceChange(t *testing.T) {
+	domain := uuid.NewString()
+	c := cache.NewCache(domain)
+	vm20 := newVM(20, "vm-20", domain)
+	vm21 := newVM(21, "vm-21", domain)
+	c.AddVMs([]*mysql.VM{vm20, vm21})
+	vif := &mysql.VInterface{Base: mysql.Base{ID: 20, Lcuuid: uuid.NewString()},
+		DeviceType: common.VIF_DEVICE_TYPE_VM, DeviceID: vm20.ID, Domain: domain}
+	mysql.Db.Create(vif)
+	c.AddVInterfaces([]*mysql.VInterface{vif})
+	lanIP := &mysql.LANIP{Base: mysql.Base{ID: 20, Lcuuid: uuid.NewString()}, IP: "10.0.0.20", VInterfaceID: vif.ID, Domain: domain}
+	mysql.Db.Create(lanIP)
+
+	NewLANIP(&c.ToolDataSet).RecordByAdd([]*mysql.LANIP{lanIP})
+	histories := getHistories(RESOURCE_TYPE_LAN_IP_EN, lanIP.Lcuuid)
+	assert.Equal(t, 1, len(histories))
+	assert.Equal(t, vm20.ID, histories[0].DeviceID)
+	assert.Equal(t, "vm-20", histories[0].DeviceName)
+
+	recorder := NewVInterface(&c.ToolDataSet)
+	diffBase := c.DiffBaseDataSet.VInterfaces[vif.Lcuuid]
+	// the device is not changed
+	recorder.RecordByUpdate(&cloudmodel.VInterface{Lcuuid: vif.Lcuuid, DeviceType: common.VIF_DEVICE_TYPE_VM, DeviceLcuuid: vm20.Lcuuid}, diffBase)
+	assert.Equal(t, 1, len(getHistories(RESOURCE_TYPE_LAN_IP_EN, lanIP.Lcuuid)))
+
+	cloudItem := &cloudmodel.VInterface{Lcuuid: vif.Lcuuid, DeviceType: common.VIF_DEVICE_TYPE_VM, DeviceLcuuid: vm21.Lcuuid}
+	recorder.RecordByUpdate(cloudItem, diffBase)
+	c.UpdateVInterface(cloudItem)
+	histories = getHistories(RESOURCE_TYPE_LAN_IP_EN, lanIP.Lcuuid)
+	assert.Equal(t, 2, len(histories))
+	assert.NotNil(t, histories[0].ValidTo)
+	assert.Equal(t, vm21.ID, histories[1].DeviceID)
+	assert.Equal(t, "vm-21", histories[1].DeviceName)
+	assert.Equal(t, "10.0.0.20", histories[1].IP)
+	assert.Nil(t, histories[1].ValidTo)
+	assert.Equal(t, vm21.ID, diffBase.DeviceID)
+}
+
+func TestGetResourcesAt(t *testing.T) {
+	domain := uuid.NewString()
+	c := cache.NewCache(domain)
+	vm := newVM(30, "vm-before", domain)
+	c.AddVMs([]*mysql.VM{vm})
+	recorder := NewVM(&c.ToolDataSet)
+	recorder.RecordByAdd([]*mysql.VM{vm})
+	recorder.RecordByUpdate(&cloudmodel.VM{Lcuuid: vm.Lcuuid, Name: "vm-after"}, &cache.VM{Name: "vm-before"})
+
+	// 将首个区间移到一小时前，以便按时刻区分两个区间
+	now := time.Now()
+	histories := getHistories(RESOURCE_TYPE_VM_EN, vm.Lcuuid)
+	assert.Equal(t, 2, len(histories))
+	mysql.Db.Model(histories[0]).Updates(map[string]interface{}{
+		"valid_from": now.Add(-time.Hour), "valid_to": now.Add(-30 * time.Minute)})
+	mysql.Db.Model(histories[1]).Update("valid_from", now.Add(-30*time.Minute))
+
+	at := func(tm time.Time) string { return strconv.FormatInt(tm.Unix(), 10) }
+	filter := map[string]interface{}{"resource_type": RESOURCE_TYPE_VM_EN, "lcuuid": vm.Lcuuid}
+
+	filter["time"] = at(now.Add(-45 * time.Minute))
+	result, err := resource.GetResourcesAt(filter)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(result))
+	assert.Equal(t, "vm-before", result[0].Name)
+
+	filter["time"] = at(now)
+	result, err = resource.GetResourcesAt(filter)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(result))
+	assert.Equal(t, "vm-after", result[0].Name)
+
+	filter["time"] = at(now.Add(-2 * time.Hour))
+	result, err = resource.GetResourcesAt(filter)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(result))
+}
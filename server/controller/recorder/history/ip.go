@@ -0,0 +1,175 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package history
+
+import (
+	cloudmodel "github.com/deepflowio/deepflow/server/controller/cloud/model"
+	"github.com/deepflowio/deepflow/server/controller/db/mysql"
+	"github.com/deepflowio/deepflow/server/controller/recorder/cache"
+	. "github.com/deepflowio/deepflow/server/controller/recorder/common"
+)
+
+type LANIP struct {
+	RecorderBase
+}
+
+func NewLANIP(toolDS *cache.ToolDataSet) *LANIP {
+	return &LANIP{
+		RecorderBase{
+			resourceType: RESOURCE_TYPE_LAN_IP_EN,
+			ToolDataSet:  toolDS,
+		},
+	}
+}
+
+func (i *LANIP) RecordByAdd(items []*mysql.LANIP) {
+	var histories []*mysql.ResourceHistory
+	for _, item := range items {
+		history := newIPHistory(i.ToolDataSet, item.VInterfaceID)
+		history.ResourceID = item.ID
+		history.Lcuuid = item.Lcuuid
+		history.IP = item.IP
+		history.Domain = item.Domain
+		history.SubDomain = item.SubDomain
+		histories = append(histories, history)
+	}
+	i.open(histories)
+}
+
+func (i *LANIP) RecordByDelete(lcuuids []string) {
+	i.close(lcuuids)
+}
+
+type WANIP struct {
+	RecorderBase
+}
+
+func NewWANIP(toolDS *cache.ToolDataSet) *WANIP {
+	return &WANIP{
+		RecorderBase{
+			resourceType: RESOURCE_TYPE_WAN_IP_EN,
+			ToolDataSet:  toolDS,
+		},
+	}
+}
+
+func (i *WANIP) RecordByAdd(items []*mysql.WANIP) {
+	var histories []*mysql.ResourceHistory
+	for _, item := range items {
+		history := newIPHistory(i.ToolDataSet, item.VInterfaceID)
+		history.ResourceID = item.ID
+		history.Lcuuid = item.Lcuuid
+		history.IP = item.IP
+		history.Domain = item.Domain
+		history.SubDomain = item.SubDomain
+		histories = append(histories, history)
+	}
+	i.open(histories)
+}
+
+func (i *WANIP) RecordByDelete(lcuuids []string) {
+	i.close(lcuuids)
+}
+
+// VInterface 所属设备变化时，关闭接口上 IP 的有效区间并按新设备重新打开
+type VInterface struct {
+	RecorderBase
+}
+
+func NewVInterface(toolDS *cache.ToolDataSet) *VInterface {
+	return &VInterface{
+		RecorderBase{
+			resourceType: RESOURCE_TYPE_VINTERFACE_EN,
+			ToolDataSet:  toolDS,
+		},
+	}
+}
+
+// 需在缓存更新前调用，diffBase 中为变化前的设备
+func (v *VInterface) RecordByUpdate(cloudItem *cloudmodel.VInterface, diffBase *cache.VInterface) {
+	deviceID, ok := v.ToolDataSet.GetDeviceIDByDeviceLcuuid(cloudItem.DeviceType, cloudItem.DeviceLcuuid)
+	if !ok || (diffBase.DeviceType == cloudItem.DeviceType && diffBase.DeviceID == deviceID) {
+		return
+	}
+	vifID, ok := v.ToolDataSet.GetVInterfaceIDByLcuuid(cloudItem.Lcuuid)
+	if !ok {
+		log.Errorf("%s (lcuuid: %s) id not found", RESOURCE_TYPE_VINTERFACE_EN, cloudItem.Lcuuid)
+		return
+	}
+	deviceName, err := v.ToolDataSet.GetDeviceNameByDeviceID(cloudItem.DeviceType, deviceID)
+	if err != nil {
+		log.Errorf("device name for %s (lcuuid: %s) not found, %v", RESOURCE_TYPE_VINTERFACE_EN, cloudItem.Lcuuid, err)
+	}
+	newHistory := func(id int, lcuuid, ip, domain, subDomain string) *mysql.ResourceHistory {
+		return &mysql.ResourceHistory{
+			ResourceID: id,
+			Lcuuid:     lcuuid,
+			Name:       deviceName,
+			IP:         ip,
+			DeviceType: cloudItem.DeviceType,
+			DeviceID:   deviceID,
+			DeviceName: deviceName,
+			Domain:     domain,
+			SubDomain:  subDomain,
+		}
+	}
+
+	var lanIPs []*mysql.LANIP
+	if err := mysql.Db.Where("vifid = ?", vifID).Find(&lanIPs).Error; err != nil {
+		log.Errorf("get %s of %s (id: %d) failed: %v", RESOURCE_TYPE_LAN_IP_EN, RESOURCE_TYPE_VINTERFACE_EN, vifID, err)
+	}
+	var lanLcuuids []string
+	var lanHistories []*mysql.ResourceHistory
+	for _, item := range lanIPs {
+		lanLcuuids = append(lanLcuuids, item.Lcuuid)
+		lanHistories = append(lanHistories, newHistory(item.ID, item.Lcuuid, item.IP, item.Domain, item.SubDomain))
+	}
+	closeHistories(RESOURCE_TYPE_LAN_IP_EN, lanLcuuids)
+	openHistories(RESOURCE_TYPE_LAN_IP_EN, lanHistories)
+
+	var wanIPs []*mysql.WANIP
+	if err := mysql.Db.Where("vifid = ?", vifID).Find(&wanIPs).Error; err != nil {
+		log.Errorf("get %s of %s (id: %d) failed: %v", RESOURCE_TYPE_WAN_IP_EN, RESOURCE_TYPE_VINTERFACE_EN, vifID, err)
+	}
+	var wanLcuuids []string
+	var wanHistories []*mysql.ResourceHistory
+	for _, item := range wanIPs {
+		wanLcuuids = append(wanLcuuids, item.Lcuuid)
+		wanHistories = append(wanHistories, newHistory(item.ID, item.Lcuuid, item.IP, item.Domain, item.SubDomain))
+	}
+	closeHistories(RESOURCE_TYPE_WAN_IP_EN, wanLcuuids)
+	openHistories(RESOURCE_TYPE_WAN_IP_EN, wanHistories)
+}
+
+// IP 所属设备通过接口获取，接口在 IP 之前更新，此时缓存中已存在
+func newIPHistory(toolDS *cache.ToolDataSet, vifID int) *mysql.ResourceHistory {
+	history := new(mysql.ResourceHistory)
+	vifLcuuid, ok := toolDS.GetVInterfaceLcuuidByID(vifID)
+	if !ok {
+		log.Errorf("%s lcuuid (id: %d) not found", RESOURCE_TYPE_VINTERFACE_EN, vifID)
+		return history
+	}
+	history.DeviceType, _ = toolDS.GetDeviceTypeByVInterfaceLcuuid(vifLcuuid)
+	history.DeviceID, _ = toolDS.GetDeviceIDByVInterfaceLcuuid(vifLcuuid)
+	deviceName, err := toolDS.GetDeviceNameByDeviceID(history.DeviceType, history.DeviceID)
+	if err != nil {
+		log.Errorf("device name for %s (lcuuid: %s) not found, %v", RESOURCE_TYPE_VINTERFACE_EN, vifLcuuid, err)
+	}
+	history.DeviceName = deviceName
+	history.Name = deviceName
+	return history
+}
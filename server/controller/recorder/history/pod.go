@@ -0,0 +1,54 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package history
+
+import (
+	"github.com/deepflowio/deepflow/server/controller/db/mysql"
+	"github.com/deepflowio/deepflow/server/controller/recorder/cache"
+	. "github.com/deepflowio/deepflow/server/controller/recorder/common"
+)
+
+type Pod struct {
+	RecorderBase
+}
+
+func NewPod(toolDS *cache.ToolDataSet) *Pod {
+	return &Pod{
+		RecorderBase{
+			resourceType: RESOURCE_TYPE_POD_EN,
+			ToolDataSet:  toolDS,
+		},
+	}
+}
+
+func (p *Pod) RecordByAdd(items []*mysql.Pod) {
+	var histories []*mysql.ResourceHistory
+	for _, item := range items {
+		histories = append(histories, &mysql.ResourceHistory{
+			ResourceID: item.ID,
+			Lcuuid:     item.Lcuuid,
+			Name:       item.Name,
+			Domain:     item.Domain,
+			SubDomain:  item.SubDomain,
+		})
+	}
+	p.open(histories)
+}
+
+func (p *Pod) RecordByDelete(lcuuids []string) {
+	p.close(lcuuids)
+}
@@ -0,0 +1,78 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package history
+
+import (
+	cloudmodel "github.com/deepflowio/deepflow/server/controller/cloud/model"
+	"github.com/deepflowio/deepflow/server/controller/db/mysql"
+	"github.com/deepflowio/deepflow/server/controller/recorder/cache"
+	. "github.com/deepflowio/deepflow/server/controller/recorder/common"
+)
+
+type VM struct {
+	RecorderBase
+}
+
+func NewVM(toolDS *cache.ToolDataSet) *VM {
+	return &VM{
+		RecorderBase{
+			resourceType: RESOURCE_TYPE_VM_EN,
+			ToolDataSet:  toolDS,
+		},
+	}
+}
+
+func (v *VM) RecordByAdd(items []*mysql.VM) {
+	var histories []*mysql.ResourceHistory
+	for _, item := range items {
+		histories = append(histories, &mysql.ResourceHistory{
+			ResourceID: item.ID,
+			Lcuuid:     item.Lcuuid,
+			Name:       item.Name,
+			Domain:     item.Domain,
+		})
+	}
+	v.open(histories)
+}
+
+// 名称变化时关闭旧区间并打开新区间
+func (v *VM) RecordByUpdate(cloudItem *cloudmodel.VM, diffBase *cache.VM) {
+	if diffBase.Name == cloudItem.Name {
+		return
+	}
+	id, ok := v.ToolDataSet.GetVMIDByLcuuid(cloudItem.Lcuuid)
+	if !ok {
+		log.Errorf("%s (lcuuid: %s) id not found", RESOURCE_TYPE_VM_EN, cloudItem.Lcuuid)
+		return
+	}
+	var dbItem mysql.VM
+	if err := mysql.Db.Select("domain").Where("id = ?", id).First(&dbItem).Error; err != nil {
+		log.Errorf("%s (id: %d) not found in db: %v", RESOURCE_TYPE_VM_EN, id, err)
+		return
+	}
+	v.close([]string{cloudItem.Lcuuid})
+	v.open([]*mysql.ResourceHistory{{
+		ResourceID: id,
+		Lcuuid:     cloudItem.Lcuuid,
+		Name:       cloudItem.Name,
+		Domain:     dbItem.Domain,
+	}})
+}
+
+func (v *VM) RecordByDelete(lcuuids []string) {
+	v.close(lcuuids)
+}
@@ -21,24 +21,28 @@ import (
 	"github.com/deepflowio/deepflow/server/controller/db/mysql"
 	"github.com/deepflowio/deepflow/server/controller/recorder/cache"
 	"github.com/deepflowio/deepflow/server/controller/recorder/event"
+	"github.com/deepflowio/deepflow/server/controller/recorder/history"
 	"github.com/deepflowio/deepflow/server/libs/queue"
 )
 
 type LANIP struct {
-	cache         *cache.Cache
-	eventProducer *event.LANIP
+	cache           *cache.Cache
+	eventProducer   *event.LANIP
+	historyRecorder *history.LANIP
 }
 
 func NewLANIP(c *cache.Cache, eq *queue.OverwriteQueue) *LANIP {
 	listener := &LANIP{
-		cache:         c,
-		eventProducer: event.NewLANIP(&c.ToolDataSet, eq),
+		cache:           c,
+		eventProducer:   event.NewLANIP(&c.ToolDataSet, eq),
+		historyRecorder: history.NewLANIP(&c.ToolDataSet),
 	}
 	return listener
 }
 
 func (i *LANIP) OnUpdaterAdded(addedDBItems []*mysql.LANIP) {
 	i.eventProducer.ProduceByAdd(addedDBItems)
+	i.historyRecorder.RecordByAdd(addedDBItems)
 	i.cache.AddLANIPs(addedDBItems)
 }
 
@@ -49,5 +53,6 @@ func (i *LANIP) OnUpdaterUpdated(cloudItem *cloudmodel.IP, diffBase *cache.LANIP
 
 func (i *LANIP) OnUpdaterDeleted(lcuuids []string) {
 	i.eventProducer.ProduceByDelete(lcuuids)
+	i.historyRecorder.RecordByDelete(lcuuids)
 	i.cache.DeleteLANIPs(lcuuids)
 }
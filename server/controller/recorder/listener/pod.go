@@ -21,24 +21,28 @@ import (
 	"github.com/deepflowio/deepflow/server/controller/db/mysql"
 	"github.com/deepflowio/deepflow/server/controller/recorder/cache"
 	"github.com/deepflowio/deepflow/server/controller/recorder/event"
+	"github.com/deepflowio/deepflow/server/controller/recorder/history"
 	"github.com/deepflowio/deepflow/server/libs/queue"
 )
 
 type Pod struct {
-	cache         *cache.Cache
-	eventProducer *event.Pod
+	cache           *cache.Cache
+	eventProducer   *event.Pod
+	historyRecorder *history.Pod
 }
 
 func NewPod(c *cache.Cache, eq *queue.OverwriteQueue) *Pod {
 	listener := &Pod{
-		cache:         c,
-		eventProducer: event.NewPod(&c.ToolDataSet, eq),
+		cache:           c,
+		eventProducer:   event.NewPod(&c.ToolDataSet, eq),
+		historyRecorder: history.NewPod(&c.ToolDataSet),
 	}
 	return listener
 }
 
 func (p *Pod) OnUpdaterAdded(addedDBItems []*mysql.Pod) {
 	p.eventProducer.ProduceByAdd(addedDBItems)
+	p.historyRecorder.RecordByAdd(addedDBItems)
 	p.cache.AddPods(addedDBItems)
 }
 
@@ -50,5 +54,6 @@ func (p *Pod) OnUpdaterUpdated(cloudItem *cloudmodel.Pod, diffBase *cache.Pod) {
 
 func (p *Pod) OnUpdaterDeleted(lcuuids []string) {
 	p.eventProducer.ProduceByDelete(lcuuids)
+	p.historyRecorder.RecordByDelete(lcuuids)
 	p.cache.DeletePods(lcuuids)
 }
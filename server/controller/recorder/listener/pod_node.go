@@ -21,24 +21,28 @@ import (
 	"github.com/deepflowio/deepflow/server/controller/db/mysql"
 	"github.com/deepflowio/deepflow/server/controller/recorder/cache"
 	"github.com/deepflowio/deepflow/server/controller/recorder/event"
+	"github.com/deepflowio/deepflow/server/controller/recorder/history"
 	"github.com/deepflowio/deepflow/server/libs/queue"
 )
 
 type PodNode struct {
-	cache         *cache.Cache
-	eventProducer *event.PodNode
+	cache           *cache.Cache
+	eventProducer   *event.PodNode
+	historyRecorder *history.PodNode
 }
 
 func NewPodNode(c *cache.Cache, eq *queue.OverwriteQueue) *PodNode {
 	listener := &PodNode{
-		cache:         c,
-		eventProducer: event.NewPodNode(&c.ToolDataSet, eq),
+		cache:           c,
+		eventProducer:   event.NewPodNode(&c.ToolDataSet, eq),
+		historyRecorder: history.NewPodNode(&c.ToolDataSet),
 	}
 	return listener
 }
 
 func (n *PodNode) OnUpdaterAdded(addedDBItems []*mysql.PodNode) {
 	n.eventProducer.ProduceByAdd(addedDBItems)
+	n.historyRecorder.RecordByAdd(addedDBItems)
 	n.cache.AddPodNodes(addedDBItems)
 }
 
@@ -50,5 +54,6 @@ func (n *PodNode) OnUpdaterUpdated(cloudItem *cloudmodel.PodNode, diffBase *cach
 
 func (n *PodNode) OnUpdaterDeleted(lcuuids []string) {
 	n.eventProducer.ProduceByDelete(lcuuids)
+	n.historyRecorder.RecordByDelete(lcuuids)
 	n.cache.DeletePodNodes(lcuuids)
 }
@@ -21,24 +21,28 @@ import (
 	"github.com/deepflowio/deepflow/server/controller/db/mysql"
 	"github.com/deepflowio/deepflow/server/controller/recorder/cache"
 	"github.com/deepflowio/deepflow/server/controller/recorder/event"
+	"github.com/deepflowio/deepflow/server/controller/recorder/history"
 	"github.com/deepflowio/deepflow/server/libs/queue"
 )
 
 type PodService struct {
-	cache         *cache.Cache
-	eventProducer *event.PodService
+	cache           *cache.Cache
+	eventProducer   *event.PodService
+	historyRecorder *history.PodService
 }
 
 func NewPodService(c *cache.Cache, eq *queue.OverwriteQueue) *PodService {
 	listener := &PodService{
-		cache:         c,
-		eventProducer: event.NewPodService(&c.ToolDataSet, eq),
+		cache:           c,
+		eventProducer:   event.NewPodService(&c.ToolDataSet, eq),
+		historyRecorder: history.NewPodService(&c.ToolDataSet),
 	}
 	return listener
 }
 
 func (ps *PodService) OnUpdaterAdded(addedDBItems []*mysql.PodService) {
 	ps.eventProducer.ProduceByAdd(addedDBItems)
+	ps.historyRecorder.RecordByAdd(addedDBItems)
 	ps.cache.AddPodServices(addedDBItems)
 }
 
@@ -50,5 +54,6 @@ func (ps *PodService) OnUpdaterUpdated(cloudItem *cloudmodel.PodService, diffBas
 
 func (ps *PodService) OnUpdaterDeleted(lcuuids []string) {
 	ps.eventProducer.ProduceByDelete(lcuuids)
+	ps.historyRecorder.RecordByDelete(lcuuids)
 	ps.cache.DeletePodServices(lcuuids)
 }
@@ -20,15 +20,18 @@ import (
 	cloudmodel "github.com/deepflowio/deepflow/server/controller/cloud/model"
 	"github.com/deepflowio/deepflow/server/controller/db/mysql"
 	"github.com/deepflowio/deepflow/server/controller/recorder/cache"
+	"github.com/deepflowio/deepflow/server/controller/recorder/history"
 )
 
 type VInterface struct {
-	cache *cache.Cache
+	cache           *cache.Cache
+	historyRecorder *history.VInterface
 }
 
 func NewVInterface(c *cache.Cache) *VInterface {
 	return &VInterface{
-		cache: c,
+		cache:           c,
+		historyRecorder: history.NewVInterface(&c.ToolDataSet),
 	}
 }
 
@@ -37,6 +40,7 @@ func (i *VInterface) OnUpdaterAdded(addedDBItems []*mysql.VInterface) {
 }
 
 func (i *VInterface) OnUpdaterUpdated(cloudItem *cloudmodel.VInterface, diffBase *cache.VInterface) {
+	i.historyRecorder.RecordByUpdate(cloudItem, diffBase)
 	diffBase.Update(cloudItem)
 	i.cache.UpdateVInterface(cloudItem)
 }
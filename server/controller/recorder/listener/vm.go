@@ -21,34 +21,40 @@ import (
 	"github.com/deepflowio/deepflow/server/controller/db/mysql"
 	"github.com/deepflowio/deepflow/server/controller/recorder/cache"
 	"github.com/deepflowio/deepflow/server/controller/recorder/event"
+	"github.com/deepflowio/deepflow/server/controller/recorder/history"
 	"github.com/deepflowio/deepflow/server/libs/queue"
 )
 
 type VM struct {
-	cache         *cache.Cache
-	eventProducer *event.VM
+	cache           *cache.Cache
+	eventProducer   *event.VM
+	historyRecorder *history.VM
 }
 
 func NewVM(c *cache.Cache, eq *queue.OverwriteQueue) *VM {
 	listener := &VM{
-		cache:         c,
-		eventProducer: event.NewVM(&c.ToolDataSet, eq),
+		cache:           c,
+		eventProducer:   event.NewVM(&c.ToolDataSet, eq),
+		historyRecorder: history.NewVM(&c.ToolDataSet),
 	}
 	return listener
 }
 
 func (vm *VM) OnUpdaterAdded(addedDBItems []*mysql.VM) {
 	vm.eventProducer.ProduceByAdd(addedDBItems)
+	vm.historyRecorder.RecordByAdd(addedDBItems)
 	vm.cache.AddVMs(addedDBItems)
 }
 
 func (vm *VM) OnUpdaterUpdated(cloudItem *cloudmodel.VM, diffBase *cache.VM) {
 	vm.eventProducer.ProduceByUpdate(cloudItem, diffBase)
+	vm.historyRecorder.RecordByUpdate(cloudItem, diffBase)
 	diffBase.Update(cloudItem)
 	vm.cache.UpdateVM(cloudItem)
 }
 
 func (vm *VM) OnUpdaterDeleted(lcuuids []string) {
 	vm.eventProducer.ProduceByDelete(lcuuids)
+	vm.historyRecorder.RecordByDelete(lcuuids)
 	vm.cache.DeleteVMs(lcuuids)
 }
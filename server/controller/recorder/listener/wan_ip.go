@@ -21,24 +21,28 @@ import (
 	"github.com/deepflowio/deepflow/server/controller/db/mysql"
 	"github.com/deepflowio/deepflow/server/controller/recorder/cache"
 	"github.com/deepflowio/deepflow/server/controller/recorder/event"
+	"github.com/deepflowio/deepflow/server/controller/recorder/history"
 	"github.com/deepflowio/deepflow/server/libs/queue"
 )
 
 type WANIP struct {
-	cache         *cache.Cache
-	eventProducer *event.WANIP
+	cache           *cache.Cache
+	eventProducer   *event.WANIP
+	historyRecorder *history.WANIP
 }
 
 func NewWANIP(c *cache.Cache, eq *queue.OverwriteQueue) *WANIP {
 	listener := &WANIP{
-		cache:         c,
-		eventProducer: event.NewWANIP(&c.ToolDataSet, eq),
+		cache:           c,
+		eventProducer:   event.NewWANIP(&c.ToolDataSet, eq),
+		historyRecorder: history.NewWANIP(&c.ToolDataSet),
 	}
 	return listener
 }
 
 func (i *WANIP) OnUpdaterAdded(addedDBItems []*mysql.WANIP) {
 	i.eventProducer.ProduceByAdd(addedDBItems)
+	i.historyRecorder.RecordByAdd(addedDBItems)
 	i.cache.AddWANIPs(addedDBItems)
 }
 
@@ -49,5 +53,6 @@ func (i *WANIP) OnUpdaterUpdated(cloudItem *cloudmodel.IP, diffBase *cache.WANIP
 
 func (i *WANIP) OnUpdaterDeleted(lcuuids []string) {
 	i.eventProducer.ProduceByDelete(lcuuids)
+	i.historyRecorder.RecordByDelete(lcuuids)
 	i.cache.DeleteWANIPs(lcuuids)
 }
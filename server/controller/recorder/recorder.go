@@ -29,6 +29,7 @@ import (
 	"github.com/deepflowio/deepflow/server/controller/db/mysql"
 	"github.com/deepflowio/deepflow/server/controller/recorder/cache"
 	"github.com/deepflowio/deepflow/server/controller/recorder/config"
+	"github.com/deepflowio/deepflow/server/controller/recorder/event"
 	"github.com/deepflowio/deepflow/server/controller/recorder/listener"
 	"github.com/deepflowio/deepflow/server/controller/recorder/updater"
 	"github.com/deepflowio/deepflow/server/controller/trisolaris/refresh"
@@ -45,6 +46,9 @@ type Recorder struct {
 	cacheMng     *cache.CacheManager
 	canRefresh   chan bool // 一个 recorder 中需要保证，同一时间只有一个 goroutine 在操作 cache
 	eventQueue   *queue.OverwriteQueue
+	k8sEvents    map[string]*event.K8sEvent // key: domain or sub_domain lcuuid
+	// 已完成更新的附属容器集群数据版本，版本未变化时无需更新，数据变化基于该版本时只处理变化的资源
+	subDomainRevisions map[string]uint64
 }
 
 func NewRecorder(domainLcuuid string, cfg config.RecorderConfig, ctx context.Context, eventQueue *queue.OverwriteQueue) *Recorder {
@@ -55,6 +59,9 @@ func NewRecorder(domainLcuuid string, cfg config.RecorderConfig, ctx context.Con
 		cacheMng:     cache.NewCacheManager(domainLcuuid),
 		canRefresh:   make(chan bool, 1),
 		eventQueue:   eventQueue,
+		k8sEvents:    make(map[string]*event.K8sEvent),
+
+		subDomainRevisions: make(map[string]uint64),
 	}
 }
 
@@ -100,6 +107,8 @@ LOOP:
 		case <-r.canRefresh:
 			log.Infof("recorder (domain lcuuid: %s) cache refresh started", r.domainLcuuid)
 			r.cacheMng.Refresh()
+			// cache 刷新后需重新对比全部数据
+			r.subDomainRevisions = make(map[string]uint64)
 			log.Infof("recorder (domain lcuuid: %s) cache refresh completed", r.domainLcuuid)
 
 			r.canRefresh <- true
@@ -169,6 +178,7 @@ func (r *Recorder) refreshDomain(cloudData cloudmodel.Resource) {
 	r.executeUpdaters(domainUpdatersInUpdateOrder)
 	r.notifyOnResourceChanged(domainUpdatersInUpdateOrder)
 	listener.OnUpdatersCompleted()
+	r.produceK8sEvents("", &r.cacheMng.DomainCache.ToolDataSet, cloudData.K8sEvents)
 
 	r.updateDomainSyncedAt(cloudData.SyncAt)
 
@@ -290,15 +300,31 @@ func (r *Recorder) refreshSubDomains(cloudSubDomainResourceMap map[string]cloudm
 		if !r.shouldRefreshSubDomain(subDomainLcuuid, subDomainResource) {
 			continue
 		}
-		log.Infof("sub_domain (lcuuid: %s) sync refresh started", subDomainLcuuid)
+		lastRevision := r.subDomainRevisions[subDomainLcuuid]
+		if subDomainResource.Revision != 0 && lastRevision == subDomainResource.Revision {
+			log.Infof("sub_domain (lcuuid: %s) revision (%d) not changed, does nothing", subDomainLcuuid, subDomainResource.Revision)
+			r.updateSubDomainSyncedAt(subDomainLcuuid, subDomainResource.SyncAt)
+			continue
+		}
+
+		// 数据变化是基于已完成更新的版本计算的，只需处理变化的资源，否则全量对比
+		cloudData, deletedData := subDomainResource, (*cloudmodel.SubDomainResource)(nil)
+		if delta := subDomainResource.Delta; delta != nil && lastRevision != 0 && delta.BaseRevision == lastRevision {
+			log.Infof("sub_domain (lcuuid: %s) incremental refresh started, revision: %d -> %d", subDomainLcuuid, lastRevision, subDomainResource.Revision)
+			cloudData, deletedData = delta.Changed, &delta.Deleted
+		} else {
+			log.Infof("sub_domain (lcuuid: %s) sync refresh started", subDomainLcuuid)
+		}
 
 		listener := listener.NewWholeSubDomain(r.domainLcuuid, subDomainLcuuid, r.cacheMng.DomainCache, r.eventQueue)
-		subDomainUpdatersInUpdateOrder := r.getSubDomainUpdatersInOrder(subDomainLcuuid, subDomainResource, nil, nil)
+		subDomainUpdatersInUpdateOrder := r.getSubDomainUpdatersInOrder(subDomainLcuuid, cloudData, deletedData, nil, nil)
 		r.executeUpdaters(subDomainUpdatersInUpdateOrder)
 		r.notifyOnResourceChanged(subDomainUpdatersInUpdateOrder)
 		listener.OnUpdatersCompleted()
+		r.produceK8sEvents(subDomainLcuuid, &r.cacheMng.SubDomainCacheMap[subDomainLcuuid].ToolDataSet, subDomainResource.K8sEvents)
 
 		r.updateSubDomainSyncedAt(subDomainLcuuid, subDomainResource.SyncAt)
+		r.subDomainRevisions[subDomainLcuuid] = subDomainResource.Revision
 
 		log.Infof("sub_domain (lcuuid: %s) sync refresh completed", subDomainLcuuid)
 	}
@@ -308,60 +334,83 @@ func (r *Recorder) refreshSubDomains(cloudSubDomainResourceMap map[string]cloudm
 		_, ok := cloudSubDomainResourceMap[subDomainLcuuid]
 		if !ok {
 			log.Infof("sub_domain (lcuuid: %s) clean refresh started", subDomainLcuuid)
-			subDomainUpdatersInUpdateOrder := r.getSubDomainUpdatersInOrder(subDomainLcuuid, cloudmodel.SubDomainResource{}, subDomainCache, &r.cacheMng.DomainCache.ToolDataSet)
+			subDomainUpdatersInUpdateOrder := r.getSubDomainUpdatersInOrder(subDomainLcuuid, cloudmodel.SubDomainResource{}, nil, subDomainCache, &r.cacheMng.DomainCache.ToolDataSet)
 			r.executeUpdaters(subDomainUpdatersInUpdateOrder)
+			delete(r.k8sEvents, subDomainLcuuid)
+			mysql.Db.Where("domain = ? AND sub_domain = ?", r.domainLcuuid, subDomainLcuuid).Delete(&mysql.K8sEventPosition{})
+			delete(r.subDomainRevisions, subDomainLcuuid)
 			log.Infof("sub_domain (lcuuid: %s) clean refresh completed", subDomainLcuuid)
 		}
 	}
 }
 
-func (r *Recorder) getSubDomainUpdatersInOrder(subDomainLcuuid string, cloudData cloudmodel.SubDomainResource,
+// kubernetes 事件在资源更新完成后生成，以便关联到最新的资源；subDomainLcuuid 为空表示 domain 的事件
+func (r *Recorder) produceK8sEvents(subDomainLcuuid string, toolDataSet *cache.ToolDataSet, items []cloudmodel.K8sEvent) {
+	lcuuid := subDomainLcuuid
+	if lcuuid == "" {
+		lcuuid = r.domainLcuuid
+	}
+	k8sEvent, ok := r.k8sEvents[lcuuid]
+	if !ok {
+		k8sEvent = event.NewK8sEvent(r.domainLcuuid, subDomainLcuuid, toolDataSet, r.eventQueue)
+		r.k8sEvents[lcuuid] = k8sEvent
+	}
+	k8sEvent.ProduceFromCloud(items)
+}
+
+// deletedData 不为空时为增量更新，cloudData 只包含新增或变化的资源，deletedData 为删除的资源
+func (r *Recorder) getSubDomainUpdatersInOrder(subDomainLcuuid string, cloudData cloudmodel.SubDomainResource, deletedData *cloudmodel.SubDomainResource,
 	subDomainCache *cache.Cache, domainToolDataSet *cache.ToolDataSet) []updater.ResourceUpdater {
 	if subDomainCache == nil {
 		subDomainCache = r.cacheMng.CreateSubDomainCacheIfNotExists(subDomainLcuuid)
 	}
 
-	ip := updater.NewIP(subDomainCache, cloudData.IPs, domainToolDataSet)
+	incremental := deletedData != nil
+	if deletedData == nil {
+		deletedData = &cloudmodel.SubDomainResource{}
+	}
+
+	ip := updater.NewIP(subDomainCache, cloudData.IPs, domainToolDataSet).SetIncremental(incremental, deletedData.IPs)
 	ip.GetLANIP().RegisterListener(listener.NewLANIP(subDomainCache, r.eventQueue))
 	ip.GetWANIP().RegisterListener(listener.NewWANIP(subDomainCache, r.eventQueue))
 
 	return []updater.ResourceUpdater{
-		updater.NewPodCluster(subDomainCache, cloudData.PodClusters).RegisterListener(
+		updater.NewPodCluster(subDomainCache, cloudData.PodClusters).SetIncremental(incremental, deletedData.PodClusters).RegisterListener(
 			listener.NewPodCluster(subDomainCache)),
-		updater.NewPodNode(subDomainCache, cloudData.PodNodes).RegisterListener(
+		updater.NewPodNode(subDomainCache, cloudData.PodNodes).SetIncremental(incremental, deletedData.PodNodes).RegisterListener(
 			listener.NewPodNode(subDomainCache, r.eventQueue)),
-		updater.NewPodNamespace(subDomainCache, cloudData.PodNamespaces).RegisterListener(
+		updater.NewPodNamespace(subDomainCache, cloudData.PodNamespaces).SetIncremental(incremental, deletedData.PodNamespaces).RegisterListener(
 			listener.NewPodNamespace(subDomainCache)),
-		updater.NewPodIngress(subDomainCache, cloudData.PodIngresses).RegisterListener(
+		updater.NewPodIngress(subDomainCache, cloudData.PodIngresses).SetIncremental(incremental, deletedData.PodIngresses).RegisterListener(
 			listener.NewPodIngress(subDomainCache)),
-		updater.NewPodIngressRule(subDomainCache, cloudData.PodIngressRules).RegisterListener(
+		updater.NewPodIngressRule(subDomainCache, cloudData.PodIngressRules).SetIncremental(incremental, deletedData.PodIngressRules).RegisterListener(
 			listener.NewPodIngressRule(subDomainCache)),
-		updater.NewPodService(subDomainCache, cloudData.PodServices).RegisterListener(
+		updater.NewPodService(subDomainCache, cloudData.PodServices).SetIncremental(incremental, deletedData.PodServices).RegisterListener(
 			listener.NewPodService(subDomainCache, r.eventQueue)),
-		updater.NewPodIngressRuleBackend(subDomainCache, cloudData.PodIngressRuleBackends).RegisterListener(
+		updater.NewPodIngressRuleBackend(subDomainCache, cloudData.PodIngressRuleBackends).SetIncremental(incremental, deletedData.PodIngressRuleBackends).RegisterListener(
 			listener.NewPodIngressRuleBackend(subDomainCache)),
-		updater.NewPodServicePort(subDomainCache, cloudData.PodServicePorts).RegisterListener(
+		updater.NewPodServicePort(subDomainCache, cloudData.PodServicePorts).SetIncremental(incremental, deletedData.PodServicePorts).RegisterListener(
 			listener.NewPodServicePort(subDomainCache)),
-		updater.NewPodGroup(subDomainCache, cloudData.PodGroups).RegisterListener(
+		updater.NewPodGroup(subDomainCache, cloudData.PodGroups).SetIncremental(incremental, deletedData.PodGroups).RegisterListener(
 			listener.NewPodGroup(subDomainCache)),
-		updater.NewPodGroupPort(subDomainCache, cloudData.PodGroupPorts).RegisterListener(
+		updater.NewPodGroupPort(subDomainCache, cloudData.PodGroupPorts).SetIncremental(incremental, deletedData.PodGroupPorts).RegisterListener(
 			listener.NewPodGroupPort(subDomainCache)),
-		updater.NewPodReplicaSet(subDomainCache, cloudData.PodReplicaSets).RegisterListener(
+		updater.NewPodReplicaSet(subDomainCache, cloudData.PodReplicaSets).SetIncremental(incremental, deletedData.PodReplicaSets).RegisterListener(
 			listener.NewPodReplicaSet(subDomainCache)),
-		updater.NewPod(subDomainCache, cloudData.Pods).RegisterListener(
+		updater.NewPod(subDomainCache, cloudData.Pods).SetIncremental(incremental, deletedData.Pods).RegisterListener(
 			listener.NewPod(subDomainCache, r.eventQueue)),
-		updater.NewNetwork(subDomainCache, cloudData.Networks).RegisterListener(
+		updater.NewNetwork(subDomainCache, cloudData.Networks).SetIncremental(incremental, deletedData.Networks).RegisterListener(
 			listener.NewNetwork(subDomainCache)),
-		updater.NewSubnet(subDomainCache, cloudData.Subnets).RegisterListener(
+		updater.NewSubnet(subDomainCache, cloudData.Subnets).SetIncremental(incremental, deletedData.Subnets).RegisterListener(
 			listener.NewSubnet(subDomainCache)),
-		updater.NewPrometheusTarget(subDomainCache, cloudData.PrometheusTargets).RegisterListener(
+		updater.NewPrometheusTarget(subDomainCache, cloudData.PrometheusTargets).SetIncremental(incremental, deletedData.PrometheusTargets).RegisterListener(
 			listener.NewPrometheusTarget(subDomainCache)),
-		updater.NewVInterface(subDomainCache, cloudData.VInterfaces, domainToolDataSet).RegisterListener(
+		updater.NewVInterface(subDomainCache, cloudData.VInterfaces, domainToolDataSet).SetIncremental(incremental, deletedData.VInterfaces).RegisterListener(
 			listener.NewVInterface(subDomainCache)),
 		ip,
-		updater.NewVMPodNodeConnection(subDomainCache, cloudData.VMPodNodeConnections).RegisterListener( // VMPodNodeConnection需放在最后
+		updater.NewVMPodNodeConnection(subDomainCache, cloudData.VMPodNodeConnections).SetIncremental(incremental, deletedData.VMPodNodeConnections).RegisterListener( // VMPodNodeConnection需放在最后
 			listener.NewVMPodNodeConnection(subDomainCache)),
-		updater.NewProcess(subDomainCache, cloudData.Processes).RegisterListener(
+		updater.NewProcess(subDomainCache, cloudData.Processes).SetIncremental(incremental, deletedData.Processes).RegisterListener(
 			listener.NewProcess(subDomainCache, r.eventQueue)),
 	}
 }
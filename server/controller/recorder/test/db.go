@@ -59,6 +59,7 @@ func GetModels() []interface{} {
 		&mysql.PodIngress{}, &mysql.PodIngressRule{}, &mysql.PodIngressRuleBackend{},
 		&mysql.PodService{}, &mysql.PodServicePort{}, &mysql.PodGroup{}, &mysql.PodGroupPort{},
 		&mysql.PodReplicaSet{}, &mysql.Pod{},
+		&mysql.ResourceHistory{}, &mysql.K8sEventPosition{},
 	}
 }
 
@@ -47,6 +47,13 @@ func (i *IP) GetLANIP() *LANIP {
 	return i.lanIPUpdater
 }
 
+// 删除的 IP 无法区分 wan 及 lan，wan 及 lan updater 各自只删除自身 diff base 中存在的 IP
+func (i *IP) SetIncremental(incremental bool, deletedCloudData []cloudmodel.IP) *IP {
+	i.wanIPUpdater.SetIncremental(incremental, deletedCloudData)
+	i.lanIPUpdater.SetIncremental(incremental, deletedCloudData)
+	return i
+}
+
 func (i *IP) HandleAddAndUpdate() {
 	// Because the cloud IP data is mixed with wan and lan, and the split is based on vinterface data which has been handled addition and update,
 	// so cloudData is not setted when wanIPUpdater or lanIPUpdater was initialized, but is setted now.
@@ -67,6 +67,7 @@ func (n *PodNode) generateDBItemToAdd(cloudItem *cloudmodel.PodNode) (*mysql.Pod
 		Name:         cloudItem.Name,
 		Type:         cloudItem.Type,
 		MemTotal:     cloudItem.MemTotal,
+		Label:        cloudItem.Label,
 		VCPUNum:      cloudItem.VCPUNum,
 		ServerType:   cloudItem.ServerType,
 		State:        cloudItem.State,
@@ -99,6 +100,9 @@ func (n *PodNode) generateUpdateInfo(diffBase *cache.PodNode, cloudItem *cloudmo
 	if diffBase.MemTotal != cloudItem.MemTotal {
 		updateInfo["mem_total"] = cloudItem.MemTotal
 	}
+	if diffBase.Label != cloudItem.Label {
+		updateInfo["label"] = cloudItem.Label
+	}
 
 	if len(updateInfo) > 0 {
 		return updateInfo, true
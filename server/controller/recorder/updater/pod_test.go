@@ -110,3 +110,33 @@ func (t *SuiteTest) TestHandleUpdatePodSucess() {
 
 	test.ClearDBData[mysql.Pod](t.db)
 }
+
+func (t *SuiteTest) TestHandleIncrementalPodSucess() {
+	c, changedItem := t.getPodMock(true)
+	unchangedItem := newCloudPod()
+	t.db.Create(&mysql.Pod{Name: unchangedItem.Name, Base: mysql.Base{Lcuuid: unchangedItem.Lcuuid}, Domain: c.DomainLcuuid, Label: unchangedItem.Label})
+	c.Pods[unchangedItem.Lcuuid] = &cache.Pod{DiffBase: cache.DiffBase{Lcuuid: unchangedItem.Lcuuid}, Name: unchangedItem.Name, Label: unchangedItem.Label}
+	changedItem.Label = changedItem.Label + "new"
+
+	// 增量模式下未变化的资源不在 cloud 数据中，不应被删除
+	updater := NewPod(c, []cloudmodel.Pod{changedItem}).SetIncremental(true, nil)
+	updater.HandleAddAndUpdate()
+	updater.HandleDelete()
+
+	var items []*mysql.Pod
+	t.db.Where("lcuuid IN ?", []string{changedItem.Lcuuid, unchangedItem.Lcuuid}).Order("id").Find(&items)
+	assert.Equal(t.T(), 2, len(items))
+	assert.Equal(t.T(), changedItem.Label, items[0].Label)
+	assert.Equal(t.T(), 2, len(c.Pods))
+
+	updater = NewPod(c, []cloudmodel.Pod{}).SetIncremental(true, []cloudmodel.Pod{unchangedItem})
+	updater.HandleAddAndUpdate()
+	updater.HandleDelete()
+
+	result := t.db.Where("lcuuid = ?", unchangedItem.Lcuuid).Find(&items)
+	assert.Equal(t.T(), int64(0), result.RowsAffected)
+	result = t.db.Where("lcuuid = ?", changedItem.Lcuuid).Find(&items)
+	assert.Equal(t.T(), int64(1), result.RowsAffected)
+
+	test.ClearDBData[mysql.Pod](t.db)
+}
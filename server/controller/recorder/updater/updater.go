@@ -56,6 +56,10 @@ type UpdaterBase[CT constraint.CloudModel, MT constraint.MySQLModel, BT constrai
 	dataGenerator     DataGenerator[CT, MT, BT]       // 提供各类数据生成的方法
 	listeners         []listener.Listener[CT, MT, BT] // 关注 Updater 的增删改操作行为及详情的监听器
 
+	// 增量模式下 cloudData 只包含新增或变化的资源，只删除 deletedCloudData 中的资源
+	incremental      bool
+	deletedCloudData []CT
+
 	// Set Changed to true if the resource database and cache are updated,
 	// used for cache update notifications to trisolaris module.
 	Changed bool
@@ -66,6 +70,13 @@ func (u *UpdaterBase[CT, MT, BT]) RegisterListener(listener listener.Listener[CT
 	return u
 }
 
+// 设置是否为增量模式，增量模式下 deletedCloudData 为相对上次更新删除的资源
+func (u *UpdaterBase[CT, MT, BT]) SetIncremental(incremental bool, deletedCloudData []CT) *UpdaterBase[CT, MT, BT] {
+	u.incremental = incremental
+	u.deletedCloudData = deletedCloudData
+	return u
+}
+
 func (u *UpdaterBase[CT, MT, BT]) HandleAddAndUpdate() {
 	dbItemsToAdd := []*MT{}
 	for _, cloudItem := range u.cloudData {
@@ -91,6 +102,10 @@ func (u *UpdaterBase[CT, MT, BT]) HandleAddAndUpdate() {
 }
 
 func (u *UpdaterBase[CT, MT, BT]) HandleDelete() {
+	if u.incremental {
+		u.handleIncrementalDelete()
+		return
+	}
 	lcuuidsOfBatchToDelete := []string{}
 	for lcuuid, diffBase := range u.diffBaseData {
 		if diffBase.GetSequence() != u.cache.GetSequence() {
@@ -103,6 +118,21 @@ func (u *UpdaterBase[CT, MT, BT]) HandleDelete() {
 	}
 }
 
+func (u *UpdaterBase[CT, MT, BT]) handleIncrementalDelete() {
+	lcuuidsOfBatchToDelete := []string{}
+	for _, cloudItem := range u.deletedCloudData {
+		diffBase, exists := u.dataGenerator.getDiffBaseByCloudItem(&cloudItem)
+		if !exists {
+			continue
+		}
+		log.Infof("to delete (diff base item: %#v)", diffBase)
+		lcuuidsOfBatchToDelete = append(lcuuidsOfBatchToDelete, diffBase.GetLcuuid())
+	}
+	if len(lcuuidsOfBatchToDelete) > 0 {
+		u.delete(lcuuidsOfBatchToDelete)
+	}
+}
+
 func (u *UpdaterBase[CT, MT, BT]) GetChanged() bool {
 	return u.Changed
 }
@@ -122,6 +122,11 @@ func (i *VInterface) generateUpdateInfo(diffBase *cache.VInterface, cloudItem *c
 	if diffBase.Name != cloudItem.Name {
 		updateInfo["name"] = cloudItem.Name
 	}
+	if deviceID, exists := i.cache.ToolDataSet.GetDeviceIDByDeviceLcuuid(cloudItem.DeviceType, cloudItem.DeviceLcuuid); exists &&
+		(diffBase.DeviceType != cloudItem.DeviceType || diffBase.DeviceID != deviceID) {
+		updateInfo["devicetype"] = cloudItem.DeviceType
+		updateInfo["deviceid"] = deviceID
+	}
 	if diffBase.TapMac != cloudItem.TapMac {
 		updateInfo["tap_mac"] = cloudItem.TapMac
 	}
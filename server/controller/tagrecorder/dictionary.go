@@ -27,6 +27,7 @@ import (
 	"time"
 
 	mapset "github.com/deckarep/golang-set"
+	"github.com/jmoiron/sqlx"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
@@ -35,6 +36,7 @@ import (
 	"github.com/deepflowio/deepflow/server/controller/common"
 	"github.com/deepflowio/deepflow/server/controller/db/clickhouse"
 	"github.com/deepflowio/deepflow/server/controller/db/mysql"
+	"github.com/deepflowio/deepflow/server/libs/ckdb"
 )
 
 var CHANGE_VIEW_TIME = time.Time{}
@@ -72,6 +74,11 @@ func (c *TagRecorder) UpdateChDictionary() {
 	if len(endpoints.Items) == 0 {
 		log.Warningf("no endpoints in %s", namespace)
 	}
+	databases, err := c.getOrgDatabases()
+	if err != nil {
+		log.Error(err)
+		return
+	}
 	endpointName := c.cfg.ClickHouseCfg.Host
 	findEndpoint := false
 	for _, endpoint := range endpoints.Items {
@@ -99,300 +106,275 @@ func (c *TagRecorder) UpdateChDictionary() {
 							continue
 						}
 						log.Infof("refresh clickhouse dictionary in (%s: %d)", address.IP, clickHouseCfg.Port)
-						var databases []string
-
-						// 检查并创建数据库
-						// Check and create the database
-						if err := connect.Select(&databases, "SHOW DATABASES"); err != nil {
+						if err := c.updateChDictionaryIn(connect, replicaSQL, databases); err != nil {
 							log.Error(err)
-							connect.Close()
-							continue
-						}
-						// 删除deepflow数据库
-						// Drop database deepflow
-						if slices.Contains(databases, "deepflow") {
-							dropSql := "DROP DATABASE IF EXISTS deepflow"
-							_, err = connect.Exec(dropSql)
-							if err != nil {
-								log.Error(err)
-								connect.Close()
-								continue
-							}
 						}
+					}
+				}
+			}
+		}
+	}
+	if !findEndpoint {
+		log.Warningf("%s endpoint not found!", endpointName)
+	}
+	return
+}
 
-						sort.Strings(databases)
-						databaseIndex := sort.SearchStrings(databases, c.cfg.ClickHouseCfg.Database)
-						if len(databases) == 0 || databaseIndex == len(databases) || databases[databaseIndex] != c.cfg.ClickHouseCfg.Database {
-							log.Infof("create database %s", c.cfg.ClickHouseCfg.Database)
-							sql := fmt.Sprintf("CREATE DATABASE %s", c.cfg.ClickHouseCfg.Database)
-							_, err = connect.Exec(sql)
-							if err != nil {
-								log.Error(err)
-								connect.Close()
-								continue
-							}
-						}
+// 每个组织的标签字典在各自的数据库中，如 org0002_flow_tag，默认组织使用 flow_tag
+// The dictionaries of each org are in its own database, such as org0002_flow_tag, the default org uses flow_tag
+func (c *TagRecorder) getOrgDatabases() ([]string, error) {
+	var orgIDs []int
+	if err := mysql.Db.Model(&mysql.VTapGroup{}).Distinct("org_id").Pluck("org_id", &orgIDs).Error; err != nil {
+		return nil, err
+	}
+	var domainOrgIDs []int
+	if err := mysql.Db.Model(&mysql.Domain{}).Distinct("org_id").Pluck("org_id", &domainOrgIDs).Error; err != nil {
+		return nil, err
+	}
+	orgIDs = append(orgIDs, domainOrgIDs...)
+	sort.Ints(orgIDs)
+	databases := []string{c.cfg.ClickHouseCfg.Database}
+	for i, orgID := range orgIDs {
+		if (i > 0 && orgID == orgIDs[i-1]) || orgID == common.DEFAULT_ORG_ID || orgID <= 0 || orgID > common.MAX_ORG_ID {
+			continue
+		}
+		databases = append(databases, ckdb.OrgDatabase(uint16(orgID), c.cfg.ClickHouseCfg.Database))
+	}
+	return databases, nil
+}
 
-						// 获取数据库中当前的字典
-						// Get the current dictionary in the database
-						dictionaries := []string{}
-						if err := connect.Select(&dictionaries, fmt.Sprintf("SHOW DICTIONARIES IN %s", c.cfg.ClickHouseCfg.Database)); err != nil {
-							log.Error(err)
-							connect.Close()
-							continue
-						}
-						wantedDicts := mapset.NewSet(
-							CH_DICTIONARY_IP_RESOURCE,
-							CH_DICTIONARY_IP_RELATION,
-							CH_DICTIONARY_POD_K8S_LABEL,
-							CH_DICTIONARY_POD_K8S_LABELS,
-							CH_DICTIONARY_REGION,
-							CH_DICTIONARY_AZ,
-							CH_DICTIONARY_VPC,
-							CH_DICTIONARY_VL2,
-							CH_DICTIONARY_POD_CLUSTER,
-							CH_DICTIONARY_POD_NAMESPACE,
-							CH_DICTIONARY_POD_NODE,
-							CH_DICTIONARY_POD_GROUP,
-							CH_DICTIONARY_POD,
-							CH_DICTIONARY_DEVICE,
-							CH_DICTIONARY_VTAP_PORT,
-							CH_DICTIONARY_TAP_TYPE,
-							CH_DICTIONARY_VTAP,
-							CH_DICTIONARY_VTAP_PORT,
-							CH_DICTIONARY_POD_NODE_PORT,
-							CH_DICTIONARY_POD_GROUP_PORT,
-							CH_DICTIONARY_POD_PORT,
-							CH_DICTIONARY_DEVICE_PORT,
-							CH_DICTIONARY_IP_PORT,
-							CH_DICTIONARY_SERVER_PORT,
-							CH_DICTIONARY_LB_LISTENER,
-							CH_DICTIONARY_POD_INGRESS,
-							CH_DICTIONARY_NODE_TYPE,
-							CH_STRING_DICTIONARY_ENUM,
-							CH_INT_DICTIONARY_ENUM,
-							CH_DICTIONARY_CHOST_CLOUD_TAG,
-							CH_DICTIONARY_POD_NS_CLOUD_TAG,
-							CH_DICTIONARY_CHOST_CLOUD_TAGS,
-							CH_DICTIONARY_POD_NS_CLOUD_TAGS,
-							CH_DICTIONARY_OS_APP_TAG,
-							CH_DICTIONARY_OS_APP_TAGS,
-							CH_DICTIONARY_GPROCESS,
-							CH_DICTIONARY_POD_SERVICE_K8S_LABEL,
-							CH_DICTIONARY_POD_SERVICE_K8S_LABELS,
+// 在一个数据节点上更新所有组织数据库中的字典和视图
+// Update the dictionaries and views in the databases of all orgs at a data node
+func (c *TagRecorder) updateChDictionaryIn(connect *sqlx.DB, replicaSQL string, orgDatabases []string) error {
+	defer connect.Close()
 
-							CH_DICTIONARY_POD_K8S_ANNOTATION,
-							CH_DICTIONARY_POD_K8S_ANNOTATIONS,
-							CH_DICTIONARY_POD_SERVICE_K8S_ANNOTATION,
-							CH_DICTIONARY_POD_SERVICE_K8S_ANNOTATIONS,
-							CH_DICTIONARY_POD_K8S_ENV,
-							CH_DICTIONARY_POD_K8S_ENVS,
-							CH_TARGET_LABEL,
-							CH_APP_LABEL,
-							CH_PROMETHEUS_LABEL_NAME,
-							CH_PROMETHEUS_METRIC_NAME,
-							CH_PROMETHEUS_METRIC_APP_LABEL_LAYOUT,
-							CH_PROMETHEUS_TARGET_LABEL_LAYOUT,
-						)
-						chDicts := mapset.NewSet()
-						for _, dictionary := range dictionaries {
-							chDicts.Add(dictionary)
-						}
+	// 检查并创建数据库
+	// Check and create the database
+	var databases []string
+	if err := connect.Select(&databases, "SHOW DATABASES"); err != nil {
+		return err
+	}
+	// 删除deepflow数据库
+	// Drop database deepflow
+	if slices.Contains(databases, "deepflow") {
+		dropSql := "DROP DATABASE IF EXISTS deepflow"
+		if _, err := connect.Exec(dropSql); err != nil {
+			return err
+		}
+	}
 
-						// 删除不存在的字典
-						// Delete a dictionary that does not exist
-						delDicts := chDicts.Difference(wantedDicts)
-						var delDictError error
-						for _, dict := range delDicts.ToSlice() {
-							dropSQL := fmt.Sprintf("DROP DICTIONARY %s.%s", c.cfg.ClickHouseCfg.Database, dict)
-							_, err = connect.Exec(dropSQL)
-							if err != nil {
-								delDictError = err
-								log.Error(err)
-								break
-							}
-						}
-						if delDictError != nil {
-							connect.Close()
-							continue
-						}
+	for _, database := range orgDatabases {
+		if !slices.Contains(databases, database) {
+			log.Infof("create database %s", database)
+			sql := fmt.Sprintf("CREATE DATABASE %s", database)
+			if _, err := connect.Exec(sql); err != nil {
+				return err
+			}
+		}
+		if err := c.updateDictionaries(connect, database, replicaSQL); err != nil {
+			return err
+		}
+		if err := updateViews(connect, database); err != nil {
+			return err
+		}
+	}
 
-						// 创建期望的字典
-						// Creating the desired dictionary
-						addDicts := wantedDicts.Difference(chDicts)
-						var addDictError error
-						for _, dict := range addDicts.ToSlice() {
-							dictName := dict.(string)
-							chTable := "ch_" + strings.TrimSuffix(dictName, "_map")
-							createSQL := CREATE_SQL_MAP[dictName]
-							mysqlPortStr := strconv.Itoa(int(c.cfg.MySqlCfg.Port))
-							createSQL = fmt.Sprintf(createSQL, c.cfg.ClickHouseCfg.Database, dictName, mysqlPortStr, c.cfg.MySqlCfg.UserName, c.cfg.MySqlCfg.UserPassword, replicaSQL, c.cfg.MySqlCfg.Database, chTable, chTable, c.cfg.TagRecorderCfg.DictionaryRefreshInterval)
-							log.Infof("create dictionary %s", dictName)
-							log.Info(createSQL)
-							_, err = connect.Exec(createSQL)
-							if err != nil {
-								addDictError = err
-								log.Error(err)
-								break
-							}
-						}
-						if addDictError != nil {
-							connect.Close()
-							continue
-						}
+	// refresh live view
+	var chViewChange mysql.ChViewChange
+	if err := mysql.Db.Unscoped().First(&chViewChange).Error; err != nil {
+		log.Errorf(dbQueryResourceFailed("ch_view_change", err))
+		return nil
+	}
+	updatedAt := chViewChange.UpdatedAt
+	if updatedAt.Equal(CHANGE_VIEW_TIME) {
+		return nil
+	}
+	for _, database := range orgDatabases {
+		for _, viewName := range []string{CH_APP_LABEL_LIVE_VIEW, CH_TARGET_LABEL_LIVE_VIEW} {
+			log.Infof("refresh live view %s.%s", database, viewName)
+			if _, err := connect.Exec(fmt.Sprintf("ALTER LIVE VIEW %s.%s REFRESH", database, viewName)); err != nil {
+				return err
+			}
+		}
+	}
+	CHANGE_VIEW_TIME = time.Now()
+	return nil
+}
 
-						// 检查并更新已存在字典
-						// Check and update existing dictionaries
-						checkDicts := chDicts.Intersect(wantedDicts)
-						var updateDictError error
-						for _, dict := range checkDicts.ToSlice() {
-							dictName := dict.(string)
-							chTable := "ch_" + strings.TrimSuffix(dictName, "_map")
-							showSQL := fmt.Sprintf("SHOW CREATE DICTIONARY %s.%s", c.cfg.ClickHouseCfg.Database, dictName)
-							dictSQL := make([]string, 0)
-							if err := connect.Select(&dictSQL, showSQL); err != nil {
-								updateDictError = err
-								log.Error(err)
-								break
-							}
-							createSQL := CREATE_SQL_MAP[dictName]
-							mysqlPortStr := strconv.Itoa(int(c.cfg.MySqlCfg.Port))
-							createSQL = fmt.Sprintf(createSQL, c.cfg.ClickHouseCfg.Database, dictName, mysqlPortStr, c.cfg.MySqlCfg.UserName, c.cfg.MySqlCfg.UserPassword, replicaSQL, c.cfg.MySqlCfg.Database, chTable, chTable, c.cfg.TagRecorderCfg.DictionaryRefreshInterval)
-							if createSQL == dictSQL[0] {
-								continue
-							}
-							log.Infof("update dictionary %s", dictName)
-							log.Infof("exist dictionary %s", dictSQL[0])
-							log.Infof("wanted dictionary %s", createSQL)
-							dropSQL := fmt.Sprintf("DROP DICTIONARY %s.%s", c.cfg.ClickHouseCfg.Database, dictName)
-							_, err = connect.Exec(dropSQL)
-							if err != nil {
-								updateDictError = err
-								log.Error(err)
-								break
-							}
-							_, err = connect.Exec(createSQL)
-							if err != nil {
-								updateDictError = err
-								log.Error(err)
-								break
-							}
-						}
-						if updateDictError != nil {
-							connect.Close()
-							continue
-						}
+func (c *TagRecorder) updateDictionaries(connect *sqlx.DB, database, replicaSQL string) error {
+	// 获取数据库中当前的字典
+	// Get the current dictionary in the database
+	dictionaries := []string{}
+	if err := connect.Select(&dictionaries, fmt.Sprintf("SHOW DICTIONARIES IN %s", database)); err != nil {
+		return err
+	}
+	wantedDicts := mapset.NewSet(
+		CH_DICTIONARY_IP_RESOURCE,
+		CH_DICTIONARY_IP_RELATION,
+		CH_DICTIONARY_POD_K8S_LABEL,
+		CH_DICTIONARY_POD_K8S_LABELS,
+		CH_DICTIONARY_REGION,
+		CH_DICTIONARY_AZ,
+		CH_DICTIONARY_VPC,
+		CH_DICTIONARY_VL2,
+		CH_DICTIONARY_POD_CLUSTER,
+		CH_DICTIONARY_POD_NAMESPACE,
+		CH_DICTIONARY_POD_NODE,
+		CH_DICTIONARY_POD_GROUP,
+		CH_DICTIONARY_POD,
+		CH_DICTIONARY_DEVICE,
+		CH_DICTIONARY_VTAP_PORT,
+		CH_DICTIONARY_TAP_TYPE,
+		CH_DICTIONARY_VTAP,
+		CH_DICTIONARY_VTAP_PORT,
+		CH_DICTIONARY_POD_NODE_PORT,
+		CH_DICTIONARY_POD_GROUP_PORT,
+		CH_DICTIONARY_POD_PORT,
+		CH_DICTIONARY_DEVICE_PORT,
+		CH_DICTIONARY_IP_PORT,
+		CH_DICTIONARY_SERVER_PORT,
+		CH_DICTIONARY_LB_LISTENER,
+		CH_DICTIONARY_POD_INGRESS,
+		CH_DICTIONARY_NODE_TYPE,
+		CH_STRING_DICTIONARY_ENUM,
+		CH_INT_DICTIONARY_ENUM,
+		CH_DICTIONARY_CHOST_CLOUD_TAG,
+		CH_DICTIONARY_POD_NS_CLOUD_TAG,
+		CH_DICTIONARY_CHOST_CLOUD_TAGS,
+		CH_DICTIONARY_POD_NS_CLOUD_TAGS,
+		CH_DICTIONARY_OS_APP_TAG,
+		CH_DICTIONARY_OS_APP_TAGS,
+		CH_DICTIONARY_GPROCESS,
+		CH_DICTIONARY_POD_SERVICE_K8S_LABEL,
+		CH_DICTIONARY_POD_SERVICE_K8S_LABELS,
 
-						// Get the current view in the database
-						views := []string{}
-						log.Infof(fmt.Sprintf("SHOW TABLES FROM %s LIKE '%%view'", c.cfg.ClickHouseCfg.Database))
-						if err := connect.Select(&views, fmt.Sprintf("SHOW TABLES FROM %s LIKE '%%view'", c.cfg.ClickHouseCfg.Database)); err != nil {
-							log.Error(err)
-							connect.Close()
-							continue
-						}
+		CH_DICTIONARY_POD_K8S_ANNOTATION,
+		CH_DICTIONARY_POD_K8S_ANNOTATIONS,
+		CH_DICTIONARY_POD_SERVICE_K8S_ANNOTATION,
+		CH_DICTIONARY_POD_SERVICE_K8S_ANNOTATIONS,
+		CH_DICTIONARY_POD_K8S_ENV,
+		CH_DICTIONARY_POD_K8S_ENVS,
+		CH_TARGET_LABEL,
+		CH_APP_LABEL,
+		CH_PROMETHEUS_LABEL_NAME,
+		CH_PROMETHEUS_METRIC_NAME,
+		CH_PROMETHEUS_METRIC_APP_LABEL_LAYOUT,
+		CH_PROMETHEUS_TARGET_LABEL_LAYOUT,
+	)
+	chDicts := mapset.NewSet()
+	for _, dictionary := range dictionaries {
+		chDicts.Add(dictionary)
+	}
 
-						// Create the desired view
-						wantedViews := mapset.NewSet(CH_APP_LABEL_LIVE_VIEW, CH_TARGET_LABEL_LIVE_VIEW)
-						chViews := mapset.NewSet()
-						for _, view := range views {
-							chViews.Add(view)
-						}
-						addViews := wantedViews.Difference(chViews)
-						var addViewError error
-						for _, view := range addViews.ToSlice() {
-							viewName := view.(string)
-							createSQL := CREATE_SQL_MAP[viewName]
-							_, err = connect.Exec(createSQL)
-							if err != nil {
-								addViewError = err
-								log.Error(err)
-								break
-							}
-						}
-						if addViewError != nil {
-							connect.Close()
-							continue
-						}
+	// 删除不存在的字典
+	// Delete a dictionary that does not exist
+	delDicts := chDicts.Difference(wantedDicts)
+	for _, dict := range delDicts.ToSlice() {
+		dropSQL := fmt.Sprintf("DROP DICTIONARY %s.%s", database, dict)
+		if _, err := connect.Exec(dropSQL); err != nil {
+			return err
+		}
+	}
 
-						// Check and update existing views
-						checkViews := chViews.Intersect(wantedViews)
-						var updateViewError error
-						for _, view := range checkViews.ToSlice() {
-							viewName := view.(string)
-							log.Info(viewName)
-							showSQL := fmt.Sprintf("SHOW CREATE TABLE %s.%s", c.cfg.ClickHouseCfg.Database, viewName)
-							viewSQL := make([]string, 0)
-							if err := connect.Select(&viewSQL, showSQL); err != nil {
-								updateViewError = err
-								log.Error(err)
-								break
-							}
-							createSQL := CREATE_SQL_MAP[viewName]
-							if createSQL == viewSQL[0] {
-								continue
-							}
-							log.Infof("update view %s", viewName)
-							log.Infof("exist view %s", viewSQL[0])
-							log.Infof("wanted view %s", createSQL)
-							dropSQL := fmt.Sprintf("DROP TABLE %s.%s", c.cfg.ClickHouseCfg.Database, viewName)
-							_, err = connect.Exec(dropSQL)
-							if err != nil {
-								updateViewError = err
-								log.Error(err)
-								break
-							}
-							_, err = connect.Exec(createSQL)
-							if err != nil {
-								updateViewError = err
-								log.Error(err)
-								break
-							}
-						}
-						if updateViewError != nil {
-							connect.Close()
-							continue
-						}
+	// 创建期望的字典
+	// Creating the desired dictionary
+	addDicts := wantedDicts.Difference(chDicts)
+	for _, dict := range addDicts.ToSlice() {
+		dictName := dict.(string)
+		createSQL := c.getDictionaryCreateSQL(database, dictName, replicaSQL)
+		log.Infof("create dictionary %s.%s", database, dictName)
+		log.Info(createSQL)
+		if _, err := connect.Exec(createSQL); err != nil {
+			return err
+		}
+	}
 
-						// refresh live view
-						var chViewChange mysql.ChViewChange
-						err = mysql.Db.Unscoped().First(&chViewChange).Error
-						if err != nil {
-							log.Errorf(dbQueryResourceFailed("ch_view_change", err))
-						} else {
-							updatedAt := chViewChange.UpdatedAt
-							if !updatedAt.Equal(CHANGE_VIEW_TIME) {
-								log.Infof("refresh live view app_label_live_view in (%s: %d)", address.IP, clickHouseCfg.Port)
-								appLabelSql := "ALTER LIVE VIEW flow_tag.app_label_live_view REFRESH"
-								_, err = connect.Exec(appLabelSql)
-								if err != nil {
-									log.Error(err)
-									connect.Close()
-									continue
-								}
+	// 检查并更新已存在字典
+	// Check and update existing dictionaries
+	checkDicts := chDicts.Intersect(wantedDicts)
+	for _, dict := range checkDicts.ToSlice() {
+		dictName := dict.(string)
+		showSQL := fmt.Sprintf("SHOW CREATE DICTIONARY %s.%s", database, dictName)
+		dictSQL := make([]string, 0)
+		if err := connect.Select(&dictSQL, showSQL); err != nil {
+			return err
+		}
+		createSQL := c.getDictionaryCreateSQL(database, dictName, replicaSQL)
+		if createSQL == dictSQL[0] {
+			continue
+		}
+		log.Infof("update dictionary %s.%s", database, dictName)
+		log.Infof("exist dictionary %s", dictSQL[0])
+		log.Infof("wanted dictionary %s", createSQL)
+		dropSQL := fmt.Sprintf("DROP DICTIONARY %s.%s", database, dictName)
+		if _, err := connect.Exec(dropSQL); err != nil {
+			return err
+		}
+		if _, err := connect.Exec(createSQL); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-								log.Infof("refresh live view target_label_live_view in (%s: %d)", address.IP, clickHouseCfg.Port)
-								targetLabelSql := "ALTER LIVE VIEW flow_tag.target_label_live_view REFRESH"
-								_, err = connect.Exec(targetLabelSql)
-								if err != nil {
-									log.Error(err)
-									connect.Close()
-									continue
-								}
-								CHANGE_VIEW_TIME = time.Now()
-							}
-						}
+func (c *TagRecorder) getDictionaryCreateSQL(database, dictName, replicaSQL string) string {
+	chTable := "ch_" + strings.TrimSuffix(dictName, "_map")
+	mysqlPortStr := strconv.Itoa(int(c.cfg.MySqlCfg.Port))
+	return fmt.Sprintf(CREATE_SQL_MAP[dictName], database, dictName, mysqlPortStr, c.cfg.MySqlCfg.UserName, c.cfg.MySqlCfg.UserPassword, replicaSQL, c.cfg.MySqlCfg.Database, chTable, chTable, c.cfg.TagRecorderCfg.DictionaryRefreshInterval)
+}
 
-						connect.Close()
-					}
-				}
-			}
+func updateViews(connect *sqlx.DB, database string) error {
+	// Get the current view in the database
+	views := []string{}
+	if err := connect.Select(&views, fmt.Sprintf("SHOW TABLES FROM %s LIKE '%%view'", database)); err != nil {
+		return err
+	}
+
+	// Create the desired view
+	wantedViews := mapset.NewSet(CH_APP_LABEL_LIVE_VIEW, CH_TARGET_LABEL_LIVE_VIEW)
+	chViews := mapset.NewSet()
+	for _, view := range views {
+		chViews.Add(view)
+	}
+	addViews := wantedViews.Difference(chViews)
+	for _, view := range addViews.ToSlice() {
+		createSQL := getViewCreateSQL(database, view.(string))
+		if _, err := connect.Exec(createSQL); err != nil {
+			return err
 		}
 	}
-	if !findEndpoint {
-		log.Warningf("%s endpoint not found!", endpointName)
+
+	// Check and update existing views
+	checkViews := chViews.Intersect(wantedViews)
+	for _, view := range checkViews.ToSlice() {
+		viewName := view.(string)
+		showSQL := fmt.Sprintf("SHOW CREATE TABLE %s.%s", database, viewName)
+		viewSQL := make([]string, 0)
+		if err := connect.Select(&viewSQL, showSQL); err != nil {
+			return err
+		}
+		createSQL := getViewCreateSQL(database, viewName)
+		if createSQL == viewSQL[0] {
+			continue
+		}
+		log.Infof("update view %s.%s", database, viewName)
+		log.Infof("exist view %s", viewSQL[0])
+		log.Infof("wanted view %s", createSQL)
+		dropSQL := fmt.Sprintf("DROP TABLE %s.%s", database, viewName)
+		if _, err := connect.Exec(dropSQL); err != nil {
+			return err
+		}
+		if _, err := connect.Exec(createSQL); err != nil {
+			return err
+		}
 	}
-	return
+	return nil
+}
+
+// 视图的建表语句使用 flow_tag 数据库，组织的视图替换为组织的数据库
+// The create sql of views uses the flow_tag database, replace it with the database of the org
+func getViewCreateSQL(database, viewName string) string {
+	return strings.ReplaceAll(CREATE_SQL_MAP[viewName], "flow_tag.", database+".")
 }
 
 func UpdateChangeView() {
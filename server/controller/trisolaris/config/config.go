@@ -58,6 +58,9 @@ type Config struct {
 	IngesterPort                   int
 	PodClusterInternalIPToIngester int
 	GrpcMaxMessageLength           int
+
+	// open, token-required or manual-approval
+	VTapRegisterPolicy string `default:"open" yaml:"vtap-register-policy"`
 }
 
 func (c *Config) Convert() {
@@ -67,6 +70,12 @@ func (c *Config) Convert() {
 		}
 		log.Infof("%+v", c.Chrony)
 	}
+	switch c.VTapRegisterPolicy {
+	case common.VTAP_REGISTER_POLICY_OPEN, common.VTAP_REGISTER_POLICY_TOKEN_REQUIRED, common.VTAP_REGISTER_POLICY_MANUAL_APPROVAL:
+	default:
+		log.Warningf("vtap-register-policy(%s) is invalid, use %s", c.VTapRegisterPolicy, common.VTAP_REGISTER_POLICY_OPEN)
+		c.VTapRegisterPolicy = common.VTAP_REGISTER_POLICY_OPEN
+	}
 	nodeIP := common.GetNodeIP()
 	if nodeIP == "" {
 		log.Errorf("get env(%s) data failed", common.NODE_IP_KEY)
@@ -292,7 +292,8 @@ func (e *VTapEvent) Sync(ctx context.Context, in *api.SyncRequest) (*api.SyncRes
 				in.GetCtrlMac(),
 				in.GetHostIps(),
 				in.GetHost(),
-				in.GetVtapGroupIdRequest())
+				in.GetVtapGroupIdRequest(),
+				in.GetEnrollmentToken())
 		}
 		return e.noVTapResponse(in), nil
 	}
@@ -352,6 +353,10 @@ func (e *VTapEvent) Sync(ctx context.Context, in *api.SyncRequest) (*api.SyncRes
 
 	vtapCache.UpdateCtrlMacFromGrpc(in.GetCtrlMac())
 	vtapCache.SetControllerSyncFlag()
+	if resp := e.unapprovedVTapResponse(vtapCache); resp != nil {
+		log.Debugf("vtap(ctrl_ip: %s, ctrl_mac: %s) is pending for approval or rejected", ctrlIP, ctrlMac)
+		return resp, nil
+	}
 	// 记录采集器版本号， push接口用
 	if in.GetVersionPlatformData() != 0 {
 		vtapCache.UpdatePushVersionPlatformData(in.GetVersionPlatformData())
@@ -516,7 +521,36 @@ func (e *VTapEvent) generateNoVTapCacheConfig(groupID string) *api.Config {
 	return configure
 }
 
+// pendingVTapResponse is returned to the vtaps waiting for approval, they are disabled and get
+// no config of any vtap group, platform data or policies until they are approved.
+func (e *VTapEvent) pendingVTapResponse() *api.SyncResponse {
+	return &api.SyncResponse{
+		Status: &STATUS_SUCCESS,
+		Config: &api.Config{
+			Enabled:              proto.Bool(false),
+			KubernetesApiEnabled: proto.Bool(false),
+			PlatformEnabled:      proto.Bool(false),
+			MaxEscapeSeconds:     proto.Uint32(uint32(DefaultMaxEscapeSeconds)),
+			MaxMemory:            proto.Uint32(uint32(DefaultMaxMemory)),
+		},
+	}
+}
+
+// unapprovedVTapResponse returns the pending response for the vtaps pending for approval or
+// rejected, and nil for the approved vtaps.
+func (e *VTapEvent) unapprovedVTapResponse(c *vtap.VTapCache) *api.SyncResponse {
+	if c.IsUnapproved() {
+		return e.pendingVTapResponse()
+	}
+	return nil
+}
+
 func (e *VTapEvent) noVTapResponse(in *api.SyncRequest) *api.SyncResponse {
+	// vtaps not registered yet are pending unless any vtap is allowed to register
+	if trisolaris.GetConfig().VTapRegisterPolicy != VTAP_REGISTER_POLICY_OPEN {
+		return e.pendingVTapResponse()
+	}
+
 	ctrlIP := in.GetCtrlIp()
 	ctrlMac := in.GetCtrlMac()
 	vtapCacheKey := ctrlIP + "-" + ctrlMac
@@ -620,6 +654,9 @@ func (e *VTapEvent) pushResponse(in *api.SyncRequest) (*api.SyncResponse, error)
 	if vtapCache == nil {
 		return e.noVTapResponse(in), fmt.Errorf("no find vtap(%s %s) cache", ctrlIP, ctrlMac)
 	}
+	if resp := e.unapprovedVTapResponse(vtapCache); resp != nil {
+		return resp, nil
+	}
 	vtapID := int(vtapCache.GetVTapID())
 	functions := vtapCache.GetFunctions()
 	versionPlatformData := vtapCache.GetSimplePlatformDataVersion()
@@ -0,0 +1,61 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package synchronize
+
+import (
+	"testing"
+
+	. "github.com/deepflowio/deepflow/server/controller/common"
+	models "github.com/deepflowio/deepflow/server/controller/db/mysql"
+	"github.com/deepflowio/deepflow/server/controller/trisolaris/vtap"
+)
+
+func TestUnapprovedVTapResponse(t *testing.T) {
+	e := NewVTapEvent()
+	tests := []struct {
+		name     string
+		state    int
+		noConfig bool
+	}{
+		{"pending", VTAP_STATE_PENDING, true},
+		{"rejected", VTAP_STATE_DISABLE, true},
+		{"normal", VTAP_STATE_NORMAL, false},
+		{"not connected", VTAP_STATE_NOT_CONNECTED, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := vtap.NewVTapCache(&models.VTap{State: tt.state, Enable: VTAP_ENABLE_TRUE})
+			resp := e.unapprovedVTapResponse(c)
+			if !tt.noConfig {
+				if resp != nil {
+					t.Fatalf("approved vtap should get its config, got %v", resp)
+				}
+				return
+			}
+			if resp == nil {
+				t.Fatal("unapproved vtap should get no config")
+			}
+			config := resp.GetConfig()
+			if config.GetEnabled() || config.GetPlatformEnabled() || config.GetKubernetesApiEnabled() {
+				t.Errorf("unapproved vtap should be disabled, got %v", config)
+			}
+			if len(resp.GetGroups()) != 0 || len(resp.GetPlatformData()) != 0 || len(resp.GetFlowAcls()) != 0 {
+				t.Errorf("unapproved vtap should get no groups, platform data or policies, got %v", resp)
+			}
+		})
+	}
+}
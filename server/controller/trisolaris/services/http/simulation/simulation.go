@@ -0,0 +1,457 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package simulation
+
+import (
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/gopacket/layers"
+	"github.com/op/go-logging"
+	"gorm.io/gorm"
+
+	"github.com/deepflowio/deepflow/message/trident"
+	models "github.com/deepflowio/deepflow/server/controller/db/mysql"
+	routercommon "github.com/deepflowio/deepflow/server/controller/http/router/common"
+	"github.com/deepflowio/deepflow/server/controller/trisolaris"
+	"github.com/deepflowio/deepflow/server/controller/trisolaris/server/http"
+	"github.com/deepflowio/deepflow/server/controller/trisolaris/server/http/common"
+	"github.com/deepflowio/deepflow/server/libs/datatype"
+	"github.com/deepflowio/deepflow/server/libs/dropletpb"
+	"github.com/deepflowio/deepflow/server/libs/policy"
+	"github.com/deepflowio/deepflow/server/libs/utils"
+)
+
+var log = logging.MustGetLogger("trisolaris/simulation")
+
+const (
+	SIMULATION_QUEUE_COUNT = 1
+	SIMULATION_LEVEL       = 8
+	SIMULATION_MAP_SIZE    = 1024
+
+	SOURCE_ANALYZER = "analyzer"
+	SOURCE_AGENT    = "agent"
+
+	DIRECTION_FORWARD  = "forward"
+	DIRECTION_BACKWARD = "backward"
+)
+
+var tunnelTypeNames = map[uint8]string{
+	datatype.NPB_TUNNEL_TYPE_VXLAN:      "vxlan",
+	datatype.NPB_TUNNEL_TYPE_GRE_ERSPAN: "erspan",
+	datatype.NPB_TUNNEL_TYPE_PCAP:       "pcap",
+	datatype.NPB_TUNNEL_TYPE_NPB_DROP:   "npb_drop",
+}
+
+var tapSideNames = map[int]string{
+	datatype.TAPSIDE_SRC: "src",
+	datatype.TAPSIDE_DST: "dst",
+	datatype.TAPSIDE_ALL: "all",
+}
+
+func init() {
+	http.Register(NewSimulationService())
+}
+
+type SimulationService struct{}
+
+func NewSimulationService() *SimulationService {
+	return &SimulationService{}
+}
+
+type SimulateRequest struct {
+	VTapID   int    `json:"VTAP_ID"` // 0表示按数据节点下发的策略模拟
+	SrcIP    string `json:"SRC_IP" binding:"required"`
+	DstIP    string `json:"DST_IP" binding:"required"`
+	SrcPort  uint16 `json:"SRC_PORT"`
+	DstPort  uint16 `json:"DST_PORT"`
+	Protocol uint8  `json:"PROTOCOL"`
+	TapType  *uint8 `json:"TAP_TYPE"` // 默认为3（云网络）
+}
+
+type ResourceGroup struct {
+	ID   uint32 `json:"ID"`
+	Name string `json:"NAME"`
+}
+
+type Endpoint struct {
+	IP             string          `json:"IP"`
+	L2EpcID        int32           `json:"L2_EPC_ID"`
+	L3EpcID        int32           `json:"L3_EPC_ID"`
+	L2End          bool            `json:"L2_END"`
+	L3End          bool            `json:"L3_END"`
+	IsDevice       bool            `json:"IS_DEVICE"`
+	IsVIP          bool            `json:"IS_VIP"`
+	ResourceGroups []ResourceGroup `json:"RESOURCE_GROUPS"`
+}
+
+type Action struct {
+	TunnelType   string   `json:"TUNNEL_TYPE"`
+	TunnelIP     string   `json:"TUNNEL_IP"`
+	TunnelIPID   uint32   `json:"TUNNEL_IP_ID"`
+	TunnelID     uint32   `json:"TUNNEL_ID"`
+	TapSide      string   `json:"TAP_SIDE"`
+	PayloadSlice uint16   `json:"PAYLOAD_SLICE"`
+	ACLGIDs      []uint16 `json:"ACL_GIDS"`
+}
+
+type ACLExplanation struct {
+	ID        uint32   `json:"ID"`
+	Name      string   `json:"NAME"`
+	Protocol  uint32   `json:"PROTOCOL"`
+	Matched   bool     `json:"MATCHED"`
+	Direction string   `json:"DIRECTION"`
+	Reasons   []string `json:"REASONS"`
+}
+
+type SimulateResult struct {
+	Source      string           `json:"SOURCE"`
+	Src         Endpoint         `json:"SRC"`
+	Dst         Endpoint         `json:"DST"`
+	ACLID       uint32           `json:"ACL_ID"`
+	ActionFlags string           `json:"ACTION_FLAGS"`
+	Actions     []Action         `json:"ACTIONS"`
+	ACLs        []ACLExplanation `json:"ACLS"`
+}
+
+type tunnel struct {
+	ip   string
+	ipID uint32
+}
+
+// 与采集器/数据节点使用相同的平台数据、资源组及策略数据构建策略表，
+// 在不影响实际匹配的前提下对五元组进行一次查询；资源组及策略只使用 orgID 所属组织的数据
+func Simulate(req *SimulateRequest, orgID int) (*SimulateResult, error) {
+	srcIP, dstIP := net.ParseIP(req.SrcIP), net.ParseIP(req.DstIP)
+	if srcIP == nil || dstIP == nil {
+		return nil, fmt.Errorf("invalid ip src(%s) dst(%s)", req.SrcIP, req.DstIP)
+	}
+	if (srcIP.To4() == nil) != (dstIP.To4() == nil) {
+		return nil, fmt.Errorf("ip version of src(%s) and dst(%s) mismatch", req.SrcIP, req.DstIP)
+	}
+	tapType := datatype.TAP_CLOUD
+	if req.TapType != nil {
+		tapType = datatype.TapType(*req.TapType)
+	}
+	if !tapType.CheckTapType(tapType) {
+		return nil, fmt.Errorf("invalid tap type(%d)", tapType)
+	}
+
+	db := trisolaris.GetDB()
+	result := &SimulateResult{Source: SOURCE_ANALYZER}
+	var platformStr, groupStr, aclStr []byte
+	if req.VTapID != 0 {
+		vtapCache := trisolaris.GetGVTapInfo().GetVTapCacheByID(req.VTapID)
+		if vtapCache == nil || !isVTapGroupInOrg(db, vtapCache.GetVTapGroupLcuuid(), orgID) {
+			return nil, fmt.Errorf("vtap(%d) not found in cache", req.VTapID)
+		}
+		result.Source = fmt.Sprintf("%s(%d)", SOURCE_AGENT, req.VTapID)
+		platformStr = vtapCache.GetSimplePlatformDataStr()
+		groupStr = trisolaris.GetGVTapInfo().GetGroupData()
+		aclStr = trisolaris.GetGVTapInfo().GetVTapPolicyData(req.VTapID, vtapCache.GetFunctions())
+	} else {
+		nodeInfo := trisolaris.GetGNodeInfo()
+		platformStr = nodeInfo.GetPlatformDataStr()
+		groupStr = nodeInfo.GetGroups()
+		aclStr = nodeInfo.GetPolicy()
+	}
+	if platformStr == nil || groupStr == nil {
+		return nil, errors.New("platform data or groups are not ready")
+	}
+
+	platformData, groups, acls := trident.PlatformData{}, trident.Groups{}, trident.FlowAcls{}
+	if err := platformData.Unmarshal(platformStr); err != nil {
+		return nil, fmt.Errorf("unmarshal platform data failed: %s", err)
+	}
+	if err := groups.Unmarshal(groupStr); err != nil {
+		return nil, fmt.Errorf("unmarshal groups failed: %s", err)
+	}
+	if err := acls.Unmarshal(aclStr); err != nil {
+		return nil, fmt.Errorf("unmarshal flow acls failed: %s", err)
+	}
+	if err := filterByOrg(db, orgID, &groups, &acls); err != nil {
+		return nil, fmt.Errorf("filter groups and acls of org(%d) failed: %s", orgID, err)
+	}
+
+	table := policy.NewPolicyTable(SIMULATION_QUEUE_COUNT, SIMULATION_LEVEL, SIMULATION_MAP_SIZE, true)
+	defer table.Close()
+	table.UpdateInterfaceData(dropletpb.Convert2PlatformData(platformData.GetInterfaces()))
+	ipGroups := dropletpb.Convert2IpGroupData(groups.GetGroups())
+	if req.VTapID == 0 {
+		// 与数据节点保持一致
+		for _, g := range ipGroups {
+			g.Type = policy.ANONYMOUS
+		}
+	}
+	table.UpdateIpGroupData(ipGroups)
+	table.UpdatePeerConnection(dropletpb.Convert2PeerConnections(platformData.GetPeerConnections()))
+	table.UpdateCidrs(dropletpb.Convert2Cidrs(platformData.GetCidrs()))
+	if err := table.UpdateAclData(dropletpb.Convert2AclDataWithoutTunnel(acls.GetFlowAcl())); err != nil {
+		return nil, fmt.Errorf("update acl data failed: %s", err)
+	}
+	table.EnableAclData()
+
+	key := &datatype.LookupKey{
+		SrcPort:     req.SrcPort,
+		DstPort:     req.DstPort,
+		Proto:       req.Protocol,
+		TapType:     tapType,
+		FeatureFlag: datatype.NPM,
+		L2End0:      true,
+		L2End1:      true,
+		L3End0:      true,
+		L3End1:      true,
+	}
+	if srcIP.To4() != nil {
+		key.SrcIp, key.DstIp = utils.IpToUint32(srcIP.To4()), utils.IpToUint32(dstIP.To4())
+		key.EthType = layers.EthernetTypeIPv4
+		srcIP, dstIP = srcIP.To4(), dstIP.To4()
+	} else {
+		key.Src6Ip, key.Dst6Ip = srcIP, dstIP
+		key.EthType = layers.EthernetTypeIPv6
+	}
+	policyData, endpointData := &datatype.PolicyData{}, &datatype.EndpointData{}
+	table.LookupAllByKey(key, policyData, endpointData)
+
+	srcGroupIDs := table.GetGroupIds(srcIP, endpointData.SrcInfo.GetL3Epc())
+	dstGroupIDs := table.GetGroupIds(dstIP, endpointData.DstInfo.GetL3Epc())
+	groupIDToName, aclIDToName := getNames(db, orgID, append(srcGroupIDs, dstGroupIDs...), acls.GetFlowAcl())
+	result.Src = newEndpoint(srcIP, endpointData.SrcInfo, srcGroupIDs, groupIDToName)
+	result.Dst = newEndpoint(dstIP, endpointData.DstInfo, dstGroupIDs, groupIDToName)
+
+	tunnels := make(map[uint16]tunnel)
+	for _, acl := range acls.GetFlowAcl() {
+		for _, npb := range acl.GetNpbActions() {
+			tunnels[uint16(npb.GetNpbAclGroupId())] = tunnel{npb.GetTunnelIp(), npb.GetTunnelIpId()}
+		}
+	}
+	result.ACLID = policyData.AclId
+	result.ActionFlags = policyData.ActionFlags.String()
+	result.Actions = make([]Action, 0, len(policyData.NpbActions))
+	for _, npb := range policyData.NpbActions {
+		action := Action{
+			TunnelType:   tunnelTypeNames[npb.TunnelType()],
+			TunnelID:     npb.TunnelId(),
+			TapSide:      tapSideNames[npb.TapSide()],
+			PayloadSlice: npb.PayloadSlice(),
+			ACLGIDs:      npb.GetAclGid(),
+		}
+		if t, ok := tunnels[npb.TunnelGid()]; ok && npb.TunnelType() != datatype.NPB_TUNNEL_TYPE_PCAP {
+			action.TunnelIP, action.TunnelIPID = t.ip, t.ipID
+		}
+		result.Actions = append(result.Actions, action)
+	}
+
+	result.ACLs = make([]ACLExplanation, 0, len(acls.GetFlowAcl()))
+	for _, acl := range acls.GetFlowAcl() {
+		direction, reasons := explainACL(acl, uint8(tapType), req.Protocol, req.SrcPort, req.DstPort, srcGroupIDs, dstGroupIDs)
+		result.ACLs = append(result.ACLs, ACLExplanation{
+			ID:        acl.GetId(),
+			Name:      aclIDToName[acl.GetId()],
+			Protocol:  acl.GetProtocol(),
+			Matched:   direction != "",
+			Direction: direction,
+			Reasons:   reasons,
+		})
+	}
+	return result, nil
+}
+
+func newEndpoint(ip net.IP, info *datatype.EndpointInfo, groupIDs []uint32, groupIDToName map[uint32]string) Endpoint {
+	endpoint := Endpoint{
+		IP:             ip.String(),
+		ResourceGroups: make([]ResourceGroup, 0, len(groupIDs)),
+	}
+	if info != nil {
+		endpoint.L2EpcID, endpoint.L3EpcID = info.L2EpcId, info.L3EpcId
+		endpoint.L2End, endpoint.L3End = info.L2End, info.L3End
+		endpoint.IsDevice, endpoint.IsVIP = info.IsDevice, info.IsVIP
+	}
+	for _, id := range groupIDs {
+		endpoint.ResourceGroups = append(endpoint.ResourceGroups, ResourceGroup{ID: id, Name: groupIDToName[id]})
+	}
+	return endpoint
+}
+
+func isVTapGroupInOrg(db *gorm.DB, vtapGroupLcuuid string, orgID int) bool {
+	var count int64
+	if err := db.Model(&models.VTapGroup{}).Where("lcuuid = ? AND org_id = ?", vtapGroupLcuuid, orgID).Count(&count).Error; err != nil {
+		log.Error(err)
+		return false
+	}
+	return count > 0
+}
+
+// 资源组及策略数据由所有组织共用，去掉其他组织的资源组，只保留本组织的策略；
+// 不在 resource_group 表中的内置资源组（如 Internet）保留
+func filterByOrg(db *gorm.DB, orgID int, groups *trident.Groups, acls *trident.FlowAcls) error {
+	var otherOrgGroupIDs, orgACLIDs []uint32
+	if err := db.Model(&models.ResourceGroup{}).Where("org_id <> ?", orgID).Pluck("id", &otherOrgGroupIDs).Error; err != nil {
+		return err
+	}
+	if err := db.Model(&models.ACL{}).Where("org_id = ?", orgID).Pluck("id", &orgACLIDs).Error; err != nil {
+		return err
+	}
+
+	otherOrgGroupIDSet := make(map[uint32]struct{}, len(otherOrgGroupIDs))
+	for _, id := range otherOrgGroupIDs {
+		otherOrgGroupIDSet[id] = struct{}{}
+	}
+	orgGroups := make([]*trident.Group, 0, len(groups.GetGroups()))
+	for _, group := range groups.GetGroups() {
+		if _, ok := otherOrgGroupIDSet[group.GetId()]; !ok {
+			orgGroups = append(orgGroups, group)
+		}
+	}
+	groups.Groups = orgGroups
+
+	orgACLIDSet := make(map[uint32]struct{}, len(orgACLIDs))
+	for _, id := range orgACLIDs {
+		orgACLIDSet[id] = struct{}{}
+	}
+	orgFlowACLs := make([]*trident.FlowAcl, 0, len(acls.GetFlowAcl()))
+	for _, acl := range acls.GetFlowAcl() {
+		if _, ok := orgACLIDSet[acl.GetId()]; ok {
+			orgFlowACLs = append(orgFlowACLs, acl)
+		}
+	}
+	acls.FlowAcl = orgFlowACLs
+	return nil
+}
+
+func getNames(db *gorm.DB, orgID int, groupIDs []uint32, flowACLs []*trident.FlowAcl) (map[uint32]string, map[uint32]string) {
+	groupIDToName, aclIDToName := make(map[uint32]string), make(map[uint32]string)
+	if len(groupIDs) > 0 {
+		var groups []*models.ResourceGroup
+		if err := db.Select("id", "name").Where("id IN ? AND org_id = ?", groupIDs, orgID).Find(&groups).Error; err != nil {
+			log.Error(err)
+		}
+		for _, group := range groups {
+			groupIDToName[uint32(group.ID)] = group.Name
+		}
+	}
+	if len(flowACLs) > 0 {
+		aclIDs := make([]uint32, 0, len(flowACLs))
+		for _, acl := range flowACLs {
+			aclIDs = append(aclIDs, acl.GetId())
+		}
+		var aclList []*models.ACL
+		if err := db.Select("id", "name").Where("id IN ? AND org_id = ?", aclIDs, orgID).Find(&aclList).Error; err != nil {
+			log.Error(err)
+		}
+		for _, acl := range aclList {
+			aclIDToName[uint32(acl.ID)] = acl.Name
+		}
+	}
+	return groupIDToName, aclIDToName
+}
+
+// 按策略匹配的语义逐项检查，与LookupAllByKey一致：TapType为0、资源组为空、端口为空时匹配任意，
+// 正向不匹配时再检查反向。返回匹配的方向，不匹配时方向为空并返回正向不匹配的原因
+func explainACL(acl *trident.FlowAcl, tapType, protocol uint8, srcPort, dstPort uint16, srcGroupIDs, dstGroupIDs []uint32) (string, []string) {
+	reasons := []string{}
+	if aclTapType := acl.GetTapType() & 0xff; aclTapType != uint32(datatype.TAP_ANY) && aclTapType != uint32(tapType) {
+		reasons = append(reasons, fmt.Sprintf("tap type %d not match acl tap type %d", tapType, aclTapType))
+	}
+	if aclProtocol := acl.GetProtocol() & 0xffff; aclProtocol != policy.PROTO_ALL && aclProtocol != uint32(protocol) {
+		reasons = append(reasons, fmt.Sprintf("protocol %d not match acl protocol %d", protocol, aclProtocol))
+	}
+	if len(reasons) > 0 {
+		return "", reasons
+	}
+
+	aclSrcGroups := datatype.SplitGroup2Int(acl.GetSrcGroupIds())
+	aclDstGroups := datatype.SplitGroup2Int(acl.GetDstGroupIds())
+	aclSrcPorts := datatype.SplitPort2Int(acl.GetSrcPorts())
+	aclDstPorts := datatype.SplitPort2Int(acl.GetDstPorts())
+	check := func(srcGroupIDs, dstGroupIDs []uint32, srcPort, dstPort uint16) []string {
+		reasons := []string{}
+		if !groupsContain(aclSrcGroups, srcGroupIDs) {
+			reasons = append(reasons, fmt.Sprintf("src resource groups %v not in acl src groups %v", srcGroupIDs, aclSrcGroups))
+		}
+		if !groupsContain(aclDstGroups, dstGroupIDs) {
+			reasons = append(reasons, fmt.Sprintf("dst resource groups %v not in acl dst groups %v", dstGroupIDs, aclDstGroups))
+		}
+		if !portsContain(aclSrcPorts, srcPort) {
+			reasons = append(reasons, fmt.Sprintf("src port %d not in acl src ports %s", srcPort, acl.GetSrcPorts()))
+		}
+		if !portsContain(aclDstPorts, dstPort) {
+			reasons = append(reasons, fmt.Sprintf("dst port %d not in acl dst ports %s", dstPort, acl.GetDstPorts()))
+		}
+		return reasons
+	}
+	forward := check(srcGroupIDs, dstGroupIDs, srcPort, dstPort)
+	if len(forward) == 0 {
+		return DIRECTION_FORWARD, forward
+	}
+	if backward := check(dstGroupIDs, srcGroupIDs, dstPort, srcPort); len(backward) == 0 {
+		return DIRECTION_BACKWARD, backward
+	}
+	return "", forward
+}
+
+func groupsContain(aclGroups, groupIDs []uint32) bool {
+	if len(aclGroups) == 0 {
+		return true
+	}
+	for _, aclGroup := range aclGroups {
+		for _, id := range groupIDs {
+			if aclGroup == id {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func portsContain(aclPorts []datatype.PortRange, port uint16) bool {
+	if len(aclPorts) == 0 {
+		return true
+	}
+	for _, ports := range aclPorts {
+		if port >= ports.Min() && port <= ports.Max() {
+			return true
+		}
+	}
+	return false
+}
+
+func PolicySimulate(c *gin.Context) {
+	req := &SimulateRequest{}
+	if err := c.ShouldBindJSON(req); err != nil {
+		common.Response(c, nil, common.NewReponse("FAILED", err.Error(), nil, err.Error()))
+		return
+	}
+	orgID, err := routercommon.GetOrgID(c)
+	if err != nil {
+		common.Response(c, nil, common.NewReponse("FAILED", err.Error(), nil, err.Error()))
+		return
+	}
+	result, err := Simulate(req, orgID)
+	if err != nil {
+		log.Error(err)
+		common.Response(c, nil, common.NewReponse("FAILED", err.Error(), nil, err.Error()))
+		return
+	}
+	common.Response(c, nil, common.NewReponse("SUCCESS", "", result, ""))
+}
+
+func (*SimulationService) Register(mux *gin.Engine) {
+	mux.POST("v1/policy-simulation/", PolicySimulate)
+}
@@ -0,0 +1,116 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package simulation
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+
+	"github.com/deepflowio/deepflow/message/trident"
+	models "github.com/deepflowio/deepflow/server/controller/db/mysql"
+)
+
+func TestExplainACL(t *testing.T) {
+	acl := &trident.FlowAcl{
+		Id:          proto.Uint32(1),
+		TapType:     proto.Uint32(3),
+		Protocol:    proto.Uint32(6),
+		DstPorts:    proto.String("80,8000-8080"),
+		SrcGroupIds: []int32{10},
+		DstGroupIds: []int32{20},
+	}
+	tests := []struct {
+		name       string
+		tapType    uint8
+		protocol   uint8
+		srcPort    uint16
+		dstPort    uint16
+		srcGroups  []uint32
+		dstGroups  []uint32
+		direction  string
+		reasonsLen int
+	}{
+		{"forward", 3, 6, 12345, 8001, []uint32{10}, []uint32{20}, DIRECTION_FORWARD, 0},
+		{"backward", 3, 6, 80, 12345, []uint32{20}, []uint32{10}, DIRECTION_BACKWARD, 0},
+		{"tap type", 1, 6, 12345, 80, []uint32{10}, []uint32{20}, "", 1},
+		{"protocol", 3, 17, 12345, 80, []uint32{10}, []uint32{20}, "", 1},
+		{"group and port", 3, 6, 12345, 443, []uint32{11}, []uint32{20}, "", 2},
+	}
+	for _, tt := range tests {
+		direction, reasons := explainACL(acl, tt.tapType, tt.protocol, tt.srcPort, tt.dstPort, tt.srcGroups, tt.dstGroups)
+		if direction != tt.direction || len(reasons) != tt.reasonsLen {
+			t.Errorf("%s: explainACL() = %s %v, want %s with %d reasons", tt.name, direction, reasons, tt.direction, tt.reasonsLen)
+		}
+	}
+
+	anyACL := &trident.FlowAcl{Id: proto.Uint32(2), Protocol: proto.Uint32(256)}
+	if direction, _ := explainACL(anyACL, 1, 17, 1, 2, nil, nil); direction != DIRECTION_FORWARD {
+		t.Errorf("acl without conditions should match any flow, got %s", direction)
+	}
+}
+
+func TestFilterByOrg(t *testing.T) {
+	db, err := gorm.Open(
+		sqlite.Open(filepath.Join(t.TempDir(), "simulation_test.db")),
+		&gorm.Config{NamingStrategy: schema.NamingStrategy{SingularTable: true}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&models.ResourceGroup{}, &models.ACL{}, &models.VTapGroup{}); err != nil {
+		t.Fatal(err)
+	}
+	db.Create(&models.ResourceGroup{ID: 10, Name: "org1-group", OrgID: 1})
+	db.Create(&models.ResourceGroup{ID: 20, Name: "org2-group", OrgID: 2})
+	db.Create(&models.ACL{ID: 1, Name: "org1-acl", OrgID: 1})
+	db.Create(&models.ACL{ID: 2, Name: "org2-acl", OrgID: 2})
+	db.Create(&models.VTapGroup{ID: 1, Lcuuid: "org1-vtap-group", OrgID: 1})
+
+	groups := &trident.Groups{Groups: []*trident.Group{
+		{Id: proto.Uint32(1)}, // 内置资源组，不在 resource_group 表中
+		{Id: proto.Uint32(10)},
+		{Id: proto.Uint32(20)},
+	}}
+	acls := &trident.FlowAcls{FlowAcl: []*trident.FlowAcl{{Id: proto.Uint32(1)}, {Id: proto.Uint32(2)}}}
+	if err := filterByOrg(db, 1, groups, acls); err != nil {
+		t.Fatal(err)
+	}
+	if len(groups.Groups) != 2 || groups.Groups[0].GetId() != 1 || groups.Groups[1].GetId() != 10 {
+		t.Errorf("groups of org 1 = %v, want [1 10]", groups.Groups)
+	}
+	if len(acls.FlowAcl) != 1 || acls.FlowAcl[0].GetId() != 1 {
+		t.Errorf("acls of org 1 = %v, want [1]", acls.FlowAcl)
+	}
+
+	allACLs := []*trident.FlowAcl{{Id: proto.Uint32(1)}, {Id: proto.Uint32(2)}}
+	groupIDToName, aclIDToName := getNames(db, 2, []uint32{10, 20}, allACLs)
+	if len(groupIDToName) != 1 || groupIDToName[20] != "org2-group" {
+		t.Errorf("group names of org 2 = %v", groupIDToName)
+	}
+	if len(aclIDToName) != 1 || aclIDToName[2] != "org2-acl" {
+		t.Errorf("acl names of org 2 = %v", aclIDToName)
+	}
+
+	if !isVTapGroupInOrg(db, "org1-vtap-group", 1) || isVTapGroupInOrg(db, "org1-vtap-group", 2) {
+		t.Error("vtap group org check failed")
+	}
+}
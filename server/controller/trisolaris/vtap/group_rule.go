@@ -0,0 +1,428 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vtap
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+
+	. "github.com/deepflowio/deepflow/server/controller/common"
+	models "github.com/deepflowio/deepflow/server/controller/db/mysql"
+)
+
+// VTapGroupRule is a compiled vtap_group_rule, conditions are ANDed and values
+// of the same condition are ORed.
+type VTapGroupRule struct {
+	*models.VTapGroupRule
+	clusterIDs    map[string]struct{}
+	regions       map[string]struct{}
+	azs           map[string]struct{}
+	hostnameRegex *regexp.Regexp
+	ipNets        []*net.IPNet
+	podNodeLabels map[string]string
+	vtapTypes     map[int]struct{}
+}
+
+// VTapGroupRuleAttrs contains the attributes of an agent which rules match on.
+type VTapGroupRuleAttrs struct {
+	Name          string
+	Host          string
+	CtrlIP        string
+	Type          int
+	Region        string
+	AZ            string
+	ClusterID     string
+	PodNodeLabels map[string]string
+}
+
+// VTapGroupMove describes an agent which should be moved to another vtap group.
+type VTapGroupMove struct {
+	VTap                *models.VTap
+	FromVTapGroupLcuuid string
+	ToVTapGroupLcuuid   string
+	Rule                *VTapGroupRule
+}
+
+func splitRuleValues(s string) []string {
+	values := []string{}
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+func toSet(values []string) map[string]struct{} {
+	if len(values) == 0 {
+		return nil
+	}
+	set := make(map[string]struct{}, len(values))
+	for _, v := range values {
+		set[v] = struct{}{}
+	}
+	return set
+}
+
+// ParseLabels converts labels like "k1:v1, k2:v2" to a map.
+func ParseLabels(s string) map[string]string {
+	labels := make(map[string]string)
+	for _, kv := range splitRuleValues(s) {
+		k, v, _ := strings.Cut(kv, ":")
+		labels[strings.TrimSpace(k)] = strings.TrimSpace(v)
+	}
+	return labels
+}
+
+// NewVTapGroupRule validates and compiles the rule.
+func NewVTapGroupRule(dbRule *models.VTapGroupRule) (*VTapGroupRule, error) {
+	r := &VTapGroupRule{
+		VTapGroupRule: dbRule,
+		clusterIDs:    toSet(splitRuleValues(dbRule.KubernetesClusterIDs)),
+		regions:       toSet(splitRuleValues(dbRule.Regions)),
+		azs:           toSet(splitRuleValues(dbRule.AZs)),
+	}
+	if dbRule.VTapGroupLcuuid == "" {
+		return nil, errors.New("vtap group is required")
+	}
+	if dbRule.HostnameRegex != "" {
+		re, err := regexp.Compile(dbRule.HostnameRegex)
+		if err != nil {
+			return nil, fmt.Errorf("invalid hostname regex(%s): %s", dbRule.HostnameRegex, err)
+		}
+		r.hostnameRegex = re
+	}
+	for _, cidr := range splitRuleValues(dbRule.IPCIDRs) {
+		if !strings.Contains(cidr, "/") {
+			if ip := net.ParseIP(cidr); ip != nil && ip.To4() != nil {
+				cidr += "/32"
+			} else {
+				cidr += "/128"
+			}
+		}
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ip cidr(%s): %s", cidr, err)
+		}
+		r.ipNets = append(r.ipNets, ipNet)
+	}
+	for _, kv := range splitRuleValues(dbRule.PodNodeLabels) {
+		if k, _, ok := strings.Cut(kv, ":"); !ok || strings.TrimSpace(k) == "" {
+			return nil, fmt.Errorf("invalid pod node label(%s), should be key:value", kv)
+		}
+	}
+	if dbRule.PodNodeLabels != "" {
+		r.podNodeLabels = ParseLabels(dbRule.PodNodeLabels)
+	}
+	for _, t := range splitRuleValues(dbRule.VTapTypes) {
+		vtapType, err := strconv.Atoi(t)
+		if err != nil {
+			return nil, fmt.Errorf("invalid vtap type(%s)", t)
+		}
+		if _, ok := VTapTypeName[vtapType]; !ok {
+			return nil, fmt.Errorf("unknown vtap type(%d)", vtapType)
+		}
+		if r.vtapTypes == nil {
+			r.vtapTypes = make(map[int]struct{})
+		}
+		r.vtapTypes[vtapType] = struct{}{}
+	}
+	if r.clusterIDs == nil && r.regions == nil && r.azs == nil && r.hostnameRegex == nil &&
+		len(r.ipNets) == 0 && len(r.podNodeLabels) == 0 && r.vtapTypes == nil {
+		return nil, errors.New("at least one condition is required")
+	}
+	return r, nil
+}
+
+func (r *VTapGroupRule) Match(attrs *VTapGroupRuleAttrs) bool {
+	if r.clusterIDs != nil {
+		if _, ok := r.clusterIDs[attrs.ClusterID]; !ok {
+			return false
+		}
+	}
+	if r.regions != nil {
+		if _, ok := r.regions[attrs.Region]; !ok {
+			return false
+		}
+	}
+	if r.azs != nil {
+		if _, ok := r.azs[attrs.AZ]; !ok {
+			return false
+		}
+	}
+	if r.hostnameRegex != nil {
+		if !r.hostnameRegex.MatchString(attrs.Name) &&
+			(attrs.Host == "" || !r.hostnameRegex.MatchString(attrs.Host)) {
+			return false
+		}
+	}
+	if len(r.ipNets) > 0 {
+		ip := net.ParseIP(attrs.CtrlIP)
+		if ip == nil {
+			return false
+		}
+		matched := false
+		for _, ipNet := range r.ipNets {
+			if ipNet.Contains(ip) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	for k, v := range r.podNodeLabels {
+		if value, ok := attrs.PodNodeLabels[k]; !ok || value != v {
+			return false
+		}
+	}
+	if r.vtapTypes != nil {
+		if _, ok := r.vtapTypes[attrs.Type]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// MatchVTapGroupRules returns the first matched rule, rules should be sorted by priority.
+func MatchVTapGroupRules(rules []*VTapGroupRule, attrs *VTapGroupRuleAttrs) *VTapGroupRule {
+	for _, rule := range rules {
+		if rule.Match(attrs) {
+			return rule
+		}
+	}
+	return nil
+}
+
+// LoadVTapGroupRules loads enabled rules sorted by priority, invalid rules are skipped.
+func LoadVTapGroupRules(db *gorm.DB) ([]*VTapGroupRule, error) {
+	var dbRules []*models.VTapGroupRule
+	if err := db.Where("enabled = ?", true).Order("priority, id").Find(&dbRules).Error; err != nil {
+		return nil, err
+	}
+	return CompileVTapGroupRules(dbRules), nil
+}
+
+func CompileVTapGroupRules(dbRules []*models.VTapGroupRule) []*VTapGroupRule {
+	rules := make([]*VTapGroupRule, 0, len(dbRules))
+	for _, dbRule := range dbRules {
+		rule, err := NewVTapGroupRule(dbRule)
+		if err != nil {
+			log.Warningf("skip vtap group rule(%s): %s", dbRule.Name, err)
+			continue
+		}
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// GetVTapGroupRuleAttrs gets the attributes of agents, the key of the result is lcuuid of the agent.
+func GetVTapGroupRuleAttrs(db *gorm.DB, vtaps []*models.VTap) (map[string]*VTapGroupRuleAttrs, error) {
+	podNodeIDs := []int{}
+	for _, vtap := range vtaps {
+		if vtap.Type == VTAP_TYPE_POD_HOST || vtap.Type == VTAP_TYPE_POD_VM {
+			podNodeIDs = append(podNodeIDs, vtap.LaunchServerID)
+		}
+	}
+	idToPodNode := make(map[int]*models.PodNode)
+	domainToClusterID := make(map[string]string)
+	if len(podNodeIDs) > 0 {
+		var podNodes []*models.PodNode
+		if err := db.Where("id IN ?", podNodeIDs).Find(&podNodes).Error; err != nil {
+			return nil, err
+		}
+		for _, podNode := range podNodes {
+			idToPodNode[podNode.ID] = podNode
+		}
+		var domains []*models.Domain
+		if err := db.Select("lcuuid", "cluster_id").Find(&domains).Error; err != nil {
+			return nil, err
+		}
+		for _, domain := range domains {
+			domainToClusterID[domain.Lcuuid] = domain.ClusterID
+		}
+		var subDomains []*models.SubDomain
+		if err := db.Select("lcuuid", "cluster_id").Find(&subDomains).Error; err != nil {
+			return nil, err
+		}
+		for _, subDomain := range subDomains {
+			domainToClusterID[subDomain.Lcuuid] = subDomain.ClusterID
+		}
+	}
+
+	result := make(map[string]*VTapGroupRuleAttrs, len(vtaps))
+	for _, vtap := range vtaps {
+		attrs := &VTapGroupRuleAttrs{
+			Name:   vtap.Name,
+			CtrlIP: vtap.CtrlIP,
+			Type:   vtap.Type,
+			Region: vtap.Region,
+			AZ:     vtap.AZ,
+		}
+		if podNode, ok := idToPodNode[vtap.LaunchServerID]; ok &&
+			(vtap.Type == VTAP_TYPE_POD_HOST || vtap.Type == VTAP_TYPE_POD_VM) {
+			attrs.Host = podNode.Name
+			attrs.PodNodeLabels = ParseLabels(podNode.Label)
+			if podNode.SubDomain != "" {
+				attrs.ClusterID = domainToClusterID[podNode.SubDomain]
+			} else {
+				attrs.ClusterID = domainToClusterID[podNode.Domain]
+			}
+		}
+		result[vtap.Lcuuid] = attrs
+	}
+	return result, nil
+}
+
+// getVTapGroupOrgIDs returns org id of each vtap group, agents are only moved between
+// vtap groups of the same organization.
+func getVTapGroupOrgIDs(db *gorm.DB) (map[string]int, error) {
+	var vtapGroups []*models.VTapGroup
+	if err := db.Select("lcuuid", "org_id").Find(&vtapGroups).Error; err != nil {
+		return nil, err
+	}
+	groupToOrgID := make(map[string]int, len(vtapGroups))
+	for _, vtapGroup := range vtapGroups {
+		groupToOrgID[vtapGroup.Lcuuid] = vtapGroup.OrgID
+	}
+	return groupToOrgID, nil
+}
+
+// PlanVTapGroupMoves returns agents whose vtap group is different from the group of
+// the first matched rule, nothing is written to DB. Only agents whose vtap group was
+// assigned by rules or agents still in the default vtap group are moved, groups
+// specified by users are kept.
+func PlanVTapGroupMoves(db *gorm.DB, rules []*VTapGroupRule) ([]*VTapGroupMove, error) {
+	if len(rules) == 0 {
+		return []*VTapGroupMove{}, nil
+	}
+	groupToOrgID, err := getVTapGroupOrgIDs(db)
+	if err != nil {
+		return nil, err
+	}
+	var defaultVTapGroup models.VTapGroup
+	if err := db.Select("lcuuid").Where("id = ?", DEFAULT_VTAP_GROUP_ID).Find(&defaultVTapGroup).Error; err != nil {
+		return nil, err
+	}
+	var vtaps []*models.VTap
+	if err := db.Where("type <> ? AND (vtap_group_source = ? OR vtap_group_lcuuid = ?)",
+		VTAP_TYPE_TUNNEL_DECAPSULATION, VTAP_GROUP_SOURCE_RULE, defaultVTapGroup.Lcuuid).
+		Find(&vtaps).Error; err != nil {
+		return nil, err
+	}
+	vtapToAttrs, err := GetVTapGroupRuleAttrs(db, vtaps)
+	if err != nil {
+		return nil, err
+	}
+	return planVTapGroupMoves(rules, vtaps, vtapToAttrs, groupToOrgID, defaultVTapGroup.Lcuuid), nil
+}
+
+func planVTapGroupMoves(rules []*VTapGroupRule, vtaps []*models.VTap, vtapToAttrs map[string]*VTapGroupRuleAttrs,
+	groupToOrgID map[string]int, defaultVTapGroupLcuuid string) []*VTapGroupMove {
+	moves := []*VTapGroupMove{}
+	for _, vtap := range vtaps {
+		// agents in the default vtap group have no group specified by users
+		if vtap.VtapGroupSource != VTAP_GROUP_SOURCE_RULE && vtap.VtapGroupLcuuid != defaultVTapGroupLcuuid {
+			continue
+		}
+		rule := matchVTapGroupRulesInOrg(rules, vtapToAttrs[vtap.Lcuuid], vtap.VtapGroupLcuuid, groupToOrgID)
+		if rule == nil || rule.VTapGroupLcuuid == vtap.VtapGroupLcuuid {
+			continue
+		}
+		moves = append(moves, &VTapGroupMove{
+			VTap:                vtap,
+			FromVTapGroupLcuuid: vtap.VtapGroupLcuuid,
+			ToVTapGroupLcuuid:   rule.VTapGroupLcuuid,
+			Rule:                rule,
+		})
+	}
+	return moves
+}
+
+// ApplyVTapGroupMove moves the agent to the vtap group of the rule, returns false if the
+// agent is changed since planned. The moved agent is managed by rules from now on.
+func ApplyVTapGroupMove(db *gorm.DB, move *VTapGroupMove) (bool, error) {
+	result := db.Model(&models.VTap{}).
+		Where("lcuuid = ? AND vtap_group_lcuuid = ? AND vtap_group_source = ?",
+			move.VTap.Lcuuid, move.FromVTapGroupLcuuid, move.VTap.VtapGroupSource).
+		Updates(map[string]interface{}{
+			"vtap_group_lcuuid": move.ToVTapGroupLcuuid, "vtap_group_source": VTAP_GROUP_SOURCE_RULE})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func matchVTapGroupRulesInOrg(rules []*VTapGroupRule, attrs *VTapGroupRuleAttrs, vtapGroupLcuuid string,
+	groupToOrgID map[string]int) *VTapGroupRule {
+	orgID, ok := groupToOrgID[vtapGroupLcuuid]
+	if !ok {
+		orgID = DEFAULT_ORG_ID
+	}
+	for _, rule := range rules {
+		if ruleOrgID, ok := groupToOrgID[rule.VTapGroupLcuuid]; !ok || ruleOrgID != orgID {
+			continue
+		}
+		if rule.Match(attrs) {
+			return rule
+		}
+	}
+	return nil
+}
+
+// matchVTapGroupRulesOnRegister returns the vtap group lcuuid decided by rules for
+// a new agent, returns empty string if no rule is matched.
+func (r *VTapRegister) matchVTapGroupRulesOnRegister(dbVTap *models.VTap, db *gorm.DB) string {
+	rules, err := LoadVTapGroupRules(db)
+	if err != nil {
+		log.Errorf("load vtap group rules failed, err: %s", err)
+		return ""
+	}
+	if len(rules) == 0 {
+		return ""
+	}
+	groupToOrgID, err := getVTapGroupOrgIDs(db)
+	if err != nil {
+		log.Errorf("load vtap groups failed, err: %s", err)
+		return ""
+	}
+	vtapToAttrs, err := GetVTapGroupRuleAttrs(db, []*models.VTap{dbVTap})
+	if err != nil {
+		log.Errorf("get attributes of agent(%s) failed, err: %s", r.getKey(), err)
+		return ""
+	}
+	attrs, ok := vtapToAttrs[dbVTap.Lcuuid]
+	if !ok {
+		return ""
+	}
+	if r.host != "" {
+		attrs.Host = r.host
+	}
+	rule := matchVTapGroupRulesInOrg(rules, attrs, dbVTap.VtapGroupLcuuid, groupToOrgID)
+	if rule == nil {
+		return ""
+	}
+	log.Infof("agent(%s) matches vtap group rule(%s), vtap group: %s", r.getKey(), rule.Name, rule.VTapGroupLcuuid)
+	return rule.VTapGroupLcuuid
+}
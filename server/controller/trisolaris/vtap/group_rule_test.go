@@ -0,0 +1,171 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vtap
+
+import (
+	"path/filepath"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+
+	. "github.com/deepflowio/deepflow/server/controller/common"
+	models "github.com/deepflowio/deepflow/server/controller/db/mysql"
+)
+
+func TestNewVTapGroupRule(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    models.VTapGroupRule
+		wantErr bool
+	}{
+		{"no condition", models.VTapGroupRule{VTapGroupLcuuid: "g1"}, true},
+		{"no group", models.VTapGroupRule{Regions: "r1"}, true},
+		{"bad regex", models.VTapGroupRule{VTapGroupLcuuid: "g1", HostnameRegex: "("}, true},
+		{"bad cidr", models.VTapGroupRule{VTapGroupLcuuid: "g1", IPCIDRs: "10.0.0.0/33"}, true},
+		{"bad label", models.VTapGroupRule{VTapGroupLcuuid: "g1", PodNodeLabels: "env"}, true},
+		{"bad type", models.VTapGroupRule{VTapGroupLcuuid: "g1", VTapTypes: "100"}, true},
+		{"ok", models.VTapGroupRule{VTapGroupLcuuid: "g1", IPCIDRs: "10.0.0.0/8, 192.168.1.1", VTapTypes: "7,8"}, false},
+	}
+	for _, tt := range tests {
+		if _, err := NewVTapGroupRule(&tt.rule); (err != nil) != tt.wantErr {
+			t.Errorf("%s: NewVTapGroupRule() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestVTapGroupRuleMatch(t *testing.T) {
+	attrs := &VTapGroupRuleAttrs{
+		Name:          "node-1-P1",
+		Host:          "node-1",
+		CtrlIP:        "10.1.2.3",
+		Type:          VTAP_TYPE_POD_HOST,
+		Region:        "r1",
+		AZ:            "az1",
+		ClusterID:     "d-abc",
+		PodNodeLabels: ParseLabels("env:prod, zone:a"),
+	}
+	tests := []struct {
+		name string
+		rule models.VTapGroupRule
+		want bool
+	}{
+		{"cluster", models.VTapGroupRule{KubernetesClusterIDs: "d-xyz,d-abc"}, true},
+		{"cluster mismatch", models.VTapGroupRule{KubernetesClusterIDs: "d-xyz"}, false},
+		{"hostname", models.VTapGroupRule{HostnameRegex: "^node-[0-9]+$"}, true},
+		{"cidr", models.VTapGroupRule{IPCIDRs: "10.1.0.0/16"}, true},
+		{"single ip", models.VTapGroupRule{IPCIDRs: "10.1.2.4"}, false},
+		{"labels", models.VTapGroupRule{PodNodeLabels: "env:prod"}, true},
+		{"labels all match", models.VTapGroupRule{PodNodeLabels: "env:prod,zone:b"}, false},
+		{"and", models.VTapGroupRule{Regions: "r1", AZs: "az1", VTapTypes: "7"}, true},
+		{"and mismatch", models.VTapGroupRule{Regions: "r1", VTapTypes: "1"}, false},
+	}
+	for _, tt := range tests {
+		tt.rule.VTapGroupLcuuid = "g1"
+		rule, err := NewVTapGroupRule(&tt.rule)
+		if err != nil {
+			t.Fatalf("%s: NewVTapGroupRule() error = %v", tt.name, err)
+		}
+		if got := rule.Match(attrs); got != tt.want {
+			t.Errorf("%s: Match() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestMatchVTapGroupRulesInOrg(t *testing.T) {
+	rules := CompileVTapGroupRules([]*models.VTapGroupRule{
+		{Name: "other-org", VTapGroupLcuuid: "g2", Regions: "r1"},
+		{Name: "first", VTapGroupLcuuid: "g3", Regions: "r1"},
+		{Name: "second", VTapGroupLcuuid: "g4", Regions: "r1"},
+	})
+	groupToOrgID := map[string]int{"g1": 1, "g2": 2, "g3": 1, "g4": 1}
+	rule := matchVTapGroupRulesInOrg(rules, &VTapGroupRuleAttrs{Region: "r1"}, "g1", groupToOrgID)
+	if rule == nil || rule.Name != "first" {
+		t.Errorf("matchVTapGroupRulesInOrg() = %v, want rule first", rule)
+	}
+}
+
+func TestPlanVTapGroupMoves(t *testing.T) {
+	rules := CompileVTapGroupRules([]*models.VTapGroupRule{
+		{Name: "r1", VTapGroupLcuuid: "g2", Regions: "r1"},
+	})
+	vtaps := []*models.VTap{
+		{Name: "manual", Lcuuid: "v1", VtapGroupLcuuid: "g1", VtapGroupSource: VTAP_GROUP_SOURCE_MANUAL},
+		{Name: "rule", Lcuuid: "v2", VtapGroupLcuuid: "g1", VtapGroupSource: VTAP_GROUP_SOURCE_RULE},
+		{Name: "unchanged", Lcuuid: "v3", VtapGroupLcuuid: "g2", VtapGroupSource: VTAP_GROUP_SOURCE_RULE},
+	}
+	vtapToAttrs := map[string]*VTapGroupRuleAttrs{
+		"v1": {Region: "r1"},
+		"v2": {Region: "r1"},
+		"v3": {Region: "r1"},
+	}
+	groupToOrgID := map[string]int{"g1": 1, "g2": 1}
+	moves := planVTapGroupMoves(rules, vtaps, vtapToAttrs, groupToOrgID, "g0")
+	if len(moves) != 1 || moves[0].VTap.Name != "rule" || moves[0].ToVTapGroupLcuuid != "g2" {
+		t.Errorf("planVTapGroupMoves() = %v, want only agent rule moved to g2", moves)
+	}
+}
+
+func TestVTapGroupRuleMovesExistingAgents(t *testing.T) {
+	db, err := gorm.Open(
+		sqlite.Open(filepath.Join(t.TempDir(), "vtap_group_rule.db")),
+		&gorm.Config{NamingStrategy: schema.NamingStrategy{SingularTable: true}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&models.VTapGroup{}, &models.VTap{}, &models.VTapGroupRule{}); err != nil {
+		t.Fatal(err)
+	}
+	db.Create(&models.VTapGroup{ID: DEFAULT_VTAP_GROUP_ID, Name: "default", Lcuuid: "g0", OrgID: DEFAULT_ORG_ID})
+	db.Create(&models.VTapGroup{ID: 2, Name: "manual", Lcuuid: "g1", OrgID: DEFAULT_ORG_ID})
+	db.Create(&models.VTapGroup{ID: 3, Name: "rule", Lcuuid: "g2", OrgID: DEFAULT_ORG_ID})
+	// agents registered before rules existed, all of them are marked as manual
+	db.Create(&models.VTap{ID: 1, Name: "in-default", Lcuuid: "v1", Region: "r1", VtapGroupLcuuid: "g0"})
+	db.Create(&models.VTap{ID: 2, Name: "in-manual", Lcuuid: "v2", Region: "r1", VtapGroupLcuuid: "g1"})
+	db.Create(&models.VTap{ID: 3, Name: "no-match", Lcuuid: "v3", Region: "r2", VtapGroupLcuuid: "g0"})
+
+	rules, _ := LoadVTapGroupRules(db)
+	if moves, _ := PlanVTapGroupMoves(db, rules); len(moves) != 0 {
+		t.Fatalf("PlanVTapGroupMoves() = %v without rules, want no move", moves)
+	}
+
+	db.Create(&models.VTapGroupRule{Name: "r1", VTapGroupLcuuid: "g2", Regions: "r1", Enabled: true})
+	rules, _ = LoadVTapGroupRules(db)
+	moves, err := PlanVTapGroupMoves(db, rules)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(moves) != 1 || moves[0].VTap.Lcuuid != "v1" || moves[0].FromVTapGroupLcuuid != "g0" || moves[0].ToVTapGroupLcuuid != "g2" {
+		t.Fatalf("PlanVTapGroupMoves() = %v, want only agent in-default moved from g0 to g2", moves)
+	}
+	if moved, err := ApplyVTapGroupMove(db, moves[0]); err != nil || !moved {
+		t.Fatalf("ApplyVTapGroupMove() = %t, %v, want moved", moved, err)
+	}
+	var vtap models.VTap
+	db.Where("lcuuid = ?", "v1").First(&vtap)
+	if vtap.VtapGroupLcuuid != "g2" || vtap.VtapGroupSource != VTAP_GROUP_SOURCE_RULE {
+		t.Errorf("agent in-default is in group %s source %d, want g2 by rule", vtap.VtapGroupLcuuid, vtap.VtapGroupSource)
+	}
+	if moved, _ := ApplyVTapGroupMove(db, moves[0]); moved {
+		t.Error("ApplyVTapGroupMove() moved agent changed since planned")
+	}
+	if moves, _ := PlanVTapGroupMoves(db, rules); len(moves) != 0 {
+		t.Errorf("PlanVTapGroupMoves() = %v after moved, want no move", moves)
+	}
+}
@@ -68,6 +68,7 @@ type VTapInfo struct {
 	hostIDToVPCID                  map[int]int
 	hypervNetworkHostIds           mapset.Set
 	vtapGroupShortIDToLcuuid       map[string]string
+	vtapGroupLcuuidToOrgID         map[string]int
 	vtapGroupLcuuidToConfiguration map[string]*VTapConfig
 	vtapGroupLcuuidToLocalConfig   map[string]string
 	vtapGroupLcuuidToEAHPEnabled   map[string]*int
@@ -127,6 +128,7 @@ func NewVTapInfo(db *gorm.DB, metaData *metadata.MetaData, cfg *config.Config) *
 		hostIDToVPCID:                  make(map[int]int),
 		hypervNetworkHostIds:           mapset.NewSet(),
 		vtapGroupShortIDToLcuuid:       make(map[string]string),
+		vtapGroupLcuuidToOrgID:         make(map[string]int),
 		vtapGroupLcuuidToConfiguration: make(map[string]*VTapConfig),
 		vtapGroupLcuuidToLocalConfig:   make(map[string]string),
 		vtapGroupLcuuidToEAHPEnabled:   make(map[string]*int),
@@ -168,6 +170,10 @@ func (v *VTapInfo) GetVTapCache(key string) *VTapCache {
 	return v.vTapCaches.Get(key)
 }
 
+func (v *VTapInfo) GetVTapCacheByID(id int) *VTapCache {
+	return v.vtapIDCaches.Get(id)
+}
+
 func (v *VTapInfo) DeleteVTapCache(key string) {
 	vTapCache := v.vTapCaches.Get(key)
 	if vTapCache != nil {
@@ -225,11 +231,14 @@ func (v *VTapInfo) loadVTapGroup() {
 	}
 
 	vtapGroupShortIDToLcuuid := make(map[string]string)
+	vtapGroupLcuuidToOrgID := make(map[string]int)
 	for _, vtapGroup := range vtapGroups {
 		vtapGroupShortIDToLcuuid[vtapGroup.ShortUUID] = vtapGroup.Lcuuid
+		vtapGroupLcuuidToOrgID[vtapGroup.Lcuuid] = vtapGroup.OrgID
 	}
 
 	v.vtapGroupShortIDToLcuuid = vtapGroupShortIDToLcuuid
+	v.vtapGroupLcuuidToOrgID = vtapGroupLcuuidToOrgID
 }
 
 func vtapPortToStr(port int64) string {
@@ -673,6 +682,7 @@ func (v *VTapInfo) generateVTapIP() {
 			EpcId:        proto.Uint32(uint32(cacheVTap.GetVPCID())),
 			Ip:           proto.String(cacheVTap.GetLaunchServer()),
 			PodClusterId: proto.Uint32(uint32(cacheVTap.GetPodClusterID())),
+			OrgId:        proto.Uint32(uint32(v.getVTapOrgID(cacheVTap.GetVTapGroupLcuuid()))),
 		}
 		vTapIPs = append(vTapIPs, data)
 	}
@@ -680,6 +690,14 @@ func (v *VTapInfo) generateVTapIP() {
 	v.updateVTapIPs(vTapIPs)
 }
 
+// 采集器所属组织由采集器组决定, 未知的组属于默认组织
+func (v *VTapInfo) getVTapOrgID(vtapGroupLcuuid string) int {
+	if orgID, ok := v.vtapGroupLcuuidToOrgID[vtapGroupLcuuid]; ok && orgID != 0 {
+		return orgID
+	}
+	return common.DEFAULT_ORG_ID
+}
+
 func (v *VTapInfo) GetProcessInfo() *ProcessInfo {
 	return v.processInfo
 }
@@ -1005,7 +1023,8 @@ func (v *VTapInfo) updateCacheToDB() {
 
 		cacheVTap.ResetControllerSyncFlag()
 		cacheVTap.ResetTSDBSyncFlag()
-		if (dbVTap.State != VTAP_STATE_PENDING && controller.IP == dbVTap.ControllerIP) || (dbVTap.Type == VTAP_TYPE_TUNNEL_DECAPSULATION && controller.NodeType == CONTROLLER_NODE_TYPE_MASTER) {
+		// 待审批和已拒绝的采集器不参与状态更新
+		if (dbVTap.State != VTAP_STATE_PENDING && dbVTap.State != VTAP_STATE_DISABLE && controller.IP == dbVTap.ControllerIP) || (dbVTap.Type == VTAP_TYPE_TUNNEL_DECAPSULATION && controller.NodeType == CONTROLLER_NODE_TYPE_MASTER) {
 			now := time.Now()
 			if now.Sub(cacheVTap.GetCachedAt()).Seconds() < float64(cacheVTap.GetConfigSyncInterval()*2) {
 				// 如果时间差小于同步时间间隔，则认为刚启动,
@@ -1080,8 +1099,12 @@ func (v *VTapInfo) getVTapAutoRegister() bool {
 	return v.config.VTapAutoRegister
 }
 
-func (v *VTapInfo) Register(tapMode int, ctrlIP string, ctrlMac string, hostIPs []string, host string, vTapGroupID string) {
-	vTapRegister := newVTapRegister(tapMode, ctrlIP, ctrlMac, hostIPs, host, vTapGroupID)
+func (v *VTapInfo) getVTapRegisterPolicy() string {
+	return v.config.VTapRegisterPolicy
+}
+
+func (v *VTapInfo) Register(tapMode int, ctrlIP string, ctrlMac string, hostIPs []string, host string, vTapGroupID string, enrollmentToken string) {
+	vTapRegister := newVTapRegister(tapMode, ctrlIP, ctrlMac, hostIPs, host, vTapGroupID, enrollmentToken)
 	v.registerMU.Lock()
 	v.register[vTapRegister.getKey()] = vTapRegister
 	v.registerMU.Unlock()
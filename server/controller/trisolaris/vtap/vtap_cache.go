@@ -483,6 +483,15 @@ func (c *VTapCache) GetVTapEnabled() int {
 	return c.enable
 }
 
+func (c *VTapCache) GetVTapState() int {
+	return c.state
+}
+
+// IsUnapproved returns true if the vtap is pending for approval or its registration is rejected
+func (c *VTapCache) IsUnapproved() bool {
+	return c.state == VTAP_STATE_PENDING || c.state == VTAP_STATE_DISABLE
+}
+
 func (c *VTapCache) GetVTapHost() string {
 	if c.name != nil {
 		return *c.name
@@ -858,7 +867,7 @@ func (c *VTapCache) init(v *VTapInfo) {
 }
 
 func (c *VTapCache) modifyVTapCache(v *VTapInfo) {
-	if c.state == VTAP_STATE_PENDING {
+	if c.IsUnapproved() {
 		c.enable = 0
 	}
 	var ok bool
@@ -20,6 +20,7 @@ import (
 	"errors"
 	"fmt"
 	"regexp"
+	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -32,10 +33,13 @@ import (
 )
 
 type VTapRegister struct {
-	tapMode          int
-	vTapGroupID      string
-	defaultVTapGroup string
-	vTapAutoRegister bool
+	tapMode            int
+	vTapGroupID        string
+	defaultVTapGroup   string
+	vTapAutoRegister   bool
+	enrollmentToken    string
+	enrollmentTokenID  int
+	vTapRegisterPolicy string
 	VTapLKData
 }
 
@@ -82,7 +86,7 @@ func FilterSlice(s []string, filter func(x string) bool) []string {
 }
 
 func newVTapRegister(tapMode int, ctrlIP string, ctrlMac string, hostIPs []string,
-	host string, vTapGroupID string) *VTapRegister {
+	host string, vTapGroupID string, enrollmentToken string) *VTapRegister {
 	hIPs := FilterSlice(hostIPs, func(x string) bool {
 		if x == "127.0.0.1" {
 			return true
@@ -97,12 +101,63 @@ func newVTapRegister(tapMode int, ctrlIP string, ctrlMac string, hostIPs []strin
 			ctrlMac: ctrlMac,
 			hostIPs: hIPs,
 			host:    host},
-		vTapGroupID: vTapGroupID,
+		vTapGroupID:     vTapGroupID,
+		enrollmentToken: enrollmentToken,
 	}
 }
 
 func (r *VTapRegister) String() string {
-	return fmt.Sprintf("%+v", *r)
+	return fmt.Sprintf("{tapMode:%d vTapGroupID:%s defaultVTapGroup:%s vTapAutoRegister:%t enrollmentTokenID:%d "+
+		"vTapRegisterPolicy:%s VTapLKData:%+v}", r.tapMode, r.vTapGroupID, r.defaultVTapGroup, r.vTapAutoRegister,
+		r.enrollmentTokenID, r.vTapRegisterPolicy, r.VTapLKData)
+}
+
+func (r *VTapRegister) getEnrollmentToken(db *gorm.DB) (*models.VTapEnrollmentToken, *models.VTapGroup, error) {
+	if r.enrollmentToken == "" {
+		return nil, nil, nil
+	}
+	token := &models.VTapEnrollmentToken{}
+	if err := db.Where("token_hash = ?", HashToken(r.enrollmentToken)).First(token).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, errors.New("token not found")
+		}
+		return nil, nil, err
+	}
+	if token.ExpiredAt != nil && token.ExpiredAt.Before(time.Now()) {
+		return nil, nil, fmt.Errorf("token(%s) expired at %s", token.Name, token.ExpiredAt.Format(GO_BIRTHDAY))
+	}
+	if token.MaxUses > 0 && token.UsedCount >= token.MaxUses {
+		return nil, nil, fmt.Errorf("token(%s) is used up, max uses: %d", token.Name, token.MaxUses)
+	}
+	vtapGroup := &models.VTapGroup{}
+	if err := db.Where("lcuuid = ?", token.VTapGroupLcuuid).First(vtapGroup).Error; err != nil {
+		return nil, nil, fmt.Errorf("vtap group(%s) of token(%s) not found", token.VTapGroupLcuuid, token.Name)
+	}
+	return token, vtapGroup, nil
+}
+
+// checkEnrollment decides the vtap group and state of the agent by its enrollment token and
+// the register policy, returns false if the agent is not allowed to register.
+func (r *VTapRegister) checkEnrollment(db *gorm.DB) bool {
+	token, vtapGroup, err := r.getEnrollmentToken(db)
+	if err != nil {
+		log.Warningf("enrollment token of agent(%s) is invalid: %s", r.getKey(), err)
+	}
+	if token == nil {
+		switch r.vTapRegisterPolicy {
+		case VTAP_REGISTER_POLICY_TOKEN_REQUIRED:
+			log.Warningf("refuse to register agent(%s), a valid enrollment token is required", r.getKey())
+			return false
+		case VTAP_REGISTER_POLICY_MANUAL_APPROVAL:
+			r.vTapAutoRegister = false
+		}
+		return true
+	}
+	// agents with a valid token join the group of the token and need no approval
+	r.enrollmentTokenID = token.ID
+	r.vTapGroupID = vtapGroup.ShortUUID
+	r.vTapAutoRegister = true
+	return true
 }
 
 func (r *VTapRegister) getVTapGroupLcuuid(db *gorm.DB) string {
@@ -161,7 +216,25 @@ func (r *VTapRegister) insertToDB(dbVTap *models.VTap, db *gorm.DB) bool {
 	if r.vTapAutoRegister {
 		dbVTap.State = VTAP_STATE_NORMAL
 	}
+	// vtap group specified by enrollment token or agent config takes precedence over rules
+	if r.vTapGroupID == "" {
+		if vtapGroupLcuuid := r.matchVTapGroupRulesOnRegister(dbVTap, db); vtapGroupLcuuid != "" {
+			dbVTap.VtapGroupLcuuid = vtapGroupLcuuid
+			dbVTap.VtapGroupSource = VTAP_GROUP_SOURCE_RULE
+		}
+	}
 	err = db.Transaction(func(tx *gorm.DB) error {
+		if r.enrollmentTokenID != 0 {
+			result := tx.Model(&models.VTapEnrollmentToken{}).
+				Where("id = ? AND (max_uses = 0 OR used_count < max_uses)", r.enrollmentTokenID).
+				Update("used_count", gorm.Expr("used_count + 1"))
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return fmt.Errorf("enrollment token(id=%d) of agent(%s) is used up", r.enrollmentTokenID, r.getKey())
+			}
+		}
 		if err := tx.Create(dbVTap).Error; err != nil {
 			log.Errorf("insert agent(%s) to DB faild, err: %s", r, err)
 			return err
@@ -756,6 +829,11 @@ func (r *VTapRegister) registerVTap(v *VTapInfo, done func()) {
 		log.Error(err)
 		return
 	}
+	r.vTapAutoRegister = v.getVTapAutoRegister()
+	r.vTapRegisterPolicy = v.getVTapRegisterPolicy()
+	if !r.checkEnrollment(v.db) {
+		return
+	}
 	vtapConfig := v.GetVTapConfigFromShortID(r.vTapGroupID)
 	if vtapConfig != nil {
 		r.tapMode = vtapConfig.TapMode
@@ -764,7 +842,6 @@ func (r *VTapRegister) registerVTap(v *VTapInfo, done func()) {
 	}
 	r.region = v.getRegion()
 	r.defaultVTapGroup = v.getDefaultVTapGroup()
-	r.vTapAutoRegister = v.getVTapAutoRegister()
 	log.Infof("register vtap: %s", r)
 	var vtap *models.VTap
 	ok := false
@@ -39,6 +39,7 @@ const (
 	SIGNAL_SOURCE_UNKNOWN SignalSource = iota
 	SIGNAL_SOURCE_RESOURCE
 	SIGNAL_SOURCE_IO
+	SIGNAL_SOURCE_K8S_EVENT
 )
 
 type EventStore struct {
@@ -269,11 +269,19 @@ func (d *Decoder) handleResourceEvent(event *eventapi.ResourceEvent) {
 	s.EndTime = s.StartTime
 
 	s.SignalSource = uint8(dbwriter.SIGNAL_SOURCE_RESOURCE)
+	if event.K8sEventType != "" {
+		s.SignalSource = uint8(dbwriter.SIGNAL_SOURCE_K8S_EVENT)
+	}
 	s.EventType = event.Type
 	s.EventDescription = event.Description
 
 	s.GProcessID = event.GProcessID
 
+	if len(event.AttributeNames) > 0 && len(event.AttributeNames) == len(event.AttributeValues) {
+		s.AttributeNames = append(s.AttributeNames, event.AttributeNames...)
+		s.AttributeValues = append(s.AttributeValues, event.AttributeValues...)
+	}
+
 	if len(event.AttributeSubnetIDs) > 0 {
 		s.AttributeNames = append(s.AttributeNames, "subnet_ids")
 		s.AttributeValues = append(s.AttributeValues,
@@ -32,14 +32,27 @@ const (
 	DefaultDecoderQueueCount = 2
 	DefaultDecoderQueueSize  = 1 << 17
 	DefaultExtMetricsTTL     = 168 // hour
+
+	DefaultInfluxDBWritePort        = 20044
+	DefaultInfluxDBWriteMaxBodySize = 32 << 20 // 32M
 )
 
+// 兼容 InfluxDB v1 `/write` 及 v2 `/api/v2/write` 的写入接口
+// write endpoint compatible with InfluxDB v1 `/write` and v2 `/api/v2/write`
+type InfluxDBWriteConfig struct {
+	Enabled     bool   `yaml:"enabled"`
+	ListenPort  int    `yaml:"listen-port"`
+	Token       string `yaml:"token"`
+	MaxBodySize int    `yaml:"max-body-size"`
+}
+
 type Config struct {
 	Base              *config.Config
 	CKWriterConfig    config.CKWriterConfig `yaml:"ext-metrics-ck-writer"`
 	DecoderQueueCount int                   `yaml:"ext-metrics-decoder-queue-count"`
 	DecoderQueueSize  int                   `yaml:"ext-metrics-decoder-queue-size"`
 	TTL               int                   `yaml:"ext-metrics-ttl-hour"`
+	InfluxDBWrite     InfluxDBWriteConfig   `yaml:"ext-metrics-influxdb-write"`
 }
 
 type ExtMetricsConfig struct {
@@ -53,6 +66,12 @@ func (c *Config) Validate() error {
 	if c.TTL <= 0 {
 		c.TTL = DefaultExtMetricsTTL
 	}
+	if c.InfluxDBWrite.ListenPort <= 0 {
+		c.InfluxDBWrite.ListenPort = DefaultInfluxDBWritePort
+	}
+	if c.InfluxDBWrite.MaxBodySize <= 0 {
+		c.InfluxDBWrite.MaxBodySize = DefaultInfluxDBWriteMaxBodySize
+	}
 
 	return nil
 }
@@ -65,6 +84,10 @@ func Load(base *config.Config, path string) *Config {
 			DecoderQueueSize:  DefaultDecoderQueueSize,
 			CKWriterConfig:    config.CKWriterConfig{QueueCount: 1, QueueSize: 100000, BatchSize: 51200, FlushTimeout: 10},
 			TTL:               DefaultExtMetricsTTL,
+			InfluxDBWrite: InfluxDBWriteConfig{
+				ListenPort:  DefaultInfluxDBWritePort,
+				MaxBodySize: DefaultInfluxDBWriteMaxBodySize,
+			},
 		},
 	}
 	if _, err := os.Stat(path); os.IsNotExist(err) {
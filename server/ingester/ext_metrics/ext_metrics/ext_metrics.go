@@ -27,6 +27,7 @@ import (
 	"github.com/deepflowio/deepflow/server/ingester/ext_metrics/config"
 	"github.com/deepflowio/deepflow/server/ingester/ext_metrics/dbwriter"
 	"github.com/deepflowio/deepflow/server/ingester/ext_metrics/decoder"
+	"github.com/deepflowio/deepflow/server/ingester/ext_metrics/influxdb"
 	"github.com/deepflowio/deepflow/server/ingester/ingesterctl"
 	"github.com/deepflowio/deepflow/server/libs/datatype"
 	"github.com/deepflowio/deepflow/server/libs/debug"
@@ -41,9 +42,10 @@ const (
 )
 
 type ExtMetrics struct {
-	Config        *config.Config
-	Telegraf      *Metricsor
-	MetaflowStats *Metricsor
+	Config         *config.Config
+	Telegraf       *Metricsor
+	MetaflowStats  *Metricsor
+	InfluxDBServer *influxdb.Server
 }
 
 type Metricsor struct {
@@ -52,6 +54,8 @@ type Metricsor struct {
 	PlatformDataEnabled bool
 	PlatformDatas       []*grpc.PlatformInfoTable
 	Writer              *dbwriter.ExtMetricsWriter
+	DecodeQueues        queue.MultiQueueWriter
+	DecodeQueueCount    int
 }
 
 func NewExtMetrics(config *config.Config, recv *receiver.Receiver, platformDataManager *grpc.PlatformDataManager) (*ExtMetrics, error) {
@@ -65,10 +69,17 @@ func NewExtMetrics(config *config.Config, recv *receiver.Receiver, platformDataM
 	if err != nil {
 		return nil, err
 	}
+	var influxDBServer *influxdb.Server
+	if config.InfluxDBWrite.Enabled {
+		// InfluxDB 写入的数据与 telegraf 数据使用相同的 decoder
+		// data written by InfluxDB protocol shares the decoders with telegraf
+		influxDBServer = influxdb.NewServer(&config.InfluxDBWrite, telegraf.DecodeQueues, telegraf.DecodeQueueCount)
+	}
 	return &ExtMetrics{
-		Config:        config,
-		Telegraf:      telegraf,
-		MetaflowStats: deepflowStats,
+		Config:         config,
+		Telegraf:       telegraf,
+		MetaflowStats:  deepflowStats,
+		InfluxDBServer: influxDBServer,
 	}, nil
 }
 
@@ -122,6 +133,8 @@ func NewMetricsor(msgType datatype.MessageType, db string, config *config.Config
 		Decoders:            decoders,
 		PlatformDataEnabled: platformDataEnabled,
 		PlatformDatas:       platformDatas,
+		DecodeQueues:        decodeQueues,
+		DecodeQueueCount:    queueCount,
 	}, nil
 }
 
@@ -148,9 +161,15 @@ func (m *Metricsor) Close() {
 func (s *ExtMetrics) Start() {
 	s.Telegraf.Start()
 	s.MetaflowStats.Start()
+	if s.InfluxDBServer != nil {
+		s.InfluxDBServer.Start()
+	}
 }
 
 func (s *ExtMetrics) Close() error {
+	if s.InfluxDBServer != nil {
+		s.InfluxDBServer.Close()
+	}
 	s.Telegraf.Close()
 	s.MetaflowStats.Close()
 	return nil
@@ -0,0 +1,350 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package influxdb
+
+import (
+	"compress/gzip"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/influxdata/influxdb/models"
+	logging "github.com/op/go-logging"
+
+	"github.com/deepflowio/deepflow/server/ingester/common"
+	"github.com/deepflowio/deepflow/server/ingester/ext_metrics/config"
+	"github.com/deepflowio/deepflow/server/libs/ckdb"
+	"github.com/deepflowio/deepflow/server/libs/codec"
+	"github.com/deepflowio/deepflow/server/libs/queue"
+	"github.com/deepflowio/deepflow/server/libs/receiver"
+	"github.com/deepflowio/deepflow/server/libs/stats"
+	"github.com/deepflowio/deepflow/server/libs/utils"
+)
+
+var log = logging.MustGetLogger("ext_metrics.influxdb")
+
+const (
+	// 每个 RecvBuffer 承载的最大数据量，超过后拆分为多个 RecvBuffer 发送到 decoder
+	// max size of data in one RecvBuffer, split into multiple RecvBuffers when exceeded
+	MAX_CHUNK_SIZE = receiver.RECV_BUFSIZE_512K
+)
+
+var errTooLarge = errors.New("request body too large")
+
+type Counter struct {
+	RequestCount    int64 `statsd:"request-count"`
+	PointCount      int64 `statsd:"point-count"`
+	ErrorCount      int64 `statsd:"err-count"`
+	AuthFailedCount int64 `statsd:"auth-failed-count"`
+}
+
+// Server 接收 InfluxDB 行协议数据，转换为 telegraf 消息格式后写入 ext_metrics decoder 队列，
+// 由 decoder 调用 PointToExtMetrics 写入 ext_metrics
+type Server struct {
+	config   *config.InfluxDBWriteConfig
+	queues   queue.MultiQueueWriter
+	nQueues  int
+	putIndex uint64
+
+	counter *Counter
+	server  *http.Server
+	utils.Closable
+}
+
+func NewServer(cfg *config.InfluxDBWriteConfig, queues queue.MultiQueueWriter, nQueues int) *Server {
+	s := &Server{
+		config:  cfg,
+		queues:  queues,
+		nQueues: nQueues,
+		counter: &Counter{},
+	}
+	router := mux.NewRouter()
+	router.HandleFunc("/write", s.writeV1).Methods("POST")
+	router.HandleFunc("/api/v2/write", s.writeV2).Methods("POST")
+	router.HandleFunc("/ping", s.ping).Methods("GET", "HEAD")
+	router.HandleFunc("/health", s.health).Methods("GET")
+	s.server = &http.Server{
+		Addr:    ":" + strconv.Itoa(cfg.ListenPort),
+		Handler: router,
+	}
+	return s
+}
+
+func (s *Server) GetCounter() interface{} {
+	counter := &Counter{}
+	counter, s.counter = s.counter, counter
+	return counter
+}
+
+func (s *Server) Start() {
+	common.RegisterCountableForIngester("influxdb_write", s, stats.OptionStatTags{})
+	go func() {
+		if err := s.server.ListenAndServe(); err != http.ErrServerClosed {
+			log.Errorf("influxdb write server ListenAndServe() failed: %v", err)
+		}
+	}()
+	log.Infof("influxdb write server started, listen port %d", s.config.ListenPort)
+}
+
+func (s *Server) Close() error {
+	s.Closable.Close()
+	ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
+	defer cancel()
+	if err := s.server.Shutdown(ctx); err != nil {
+		log.Errorf("influxdb write server Shutdown() failed: %v", err)
+		return err
+	}
+	log.Info("influxdb write server stopped")
+	return nil
+}
+
+func (s *Server) ping(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("X-Influxdb-Version", "deepflow")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) health(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(`{"name":"deepflow","status":"pass"}`))
+}
+
+// v1: POST /write?db=<db>&precision=<n|ns|u|us|ms|s|m|h>
+func (s *Server) writeV1(w http.ResponseWriter, r *http.Request) {
+	atomic.AddInt64(&s.counter.RequestCount, 1)
+	query := r.URL.Query()
+	if !s.authorizedV1(r) {
+		atomic.AddInt64(&s.counter.AuthFailedCount, 1)
+		respV1Error(w, http.StatusUnauthorized, "authorization failed")
+		return
+	}
+	status, err := s.write(r, query.Get("db"), query.Get("precision"))
+	if err != nil {
+		atomic.AddInt64(&s.counter.ErrorCount, 1)
+		respV1Error(w, status, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// v2: POST /api/v2/write?org=<org>&bucket=<bucket>&precision=<ns|us|ms|s>
+func (s *Server) writeV2(w http.ResponseWriter, r *http.Request) {
+	atomic.AddInt64(&s.counter.RequestCount, 1)
+	query := r.URL.Query()
+	if !s.authorizedV2(r) {
+		atomic.AddInt64(&s.counter.AuthFailedCount, 1)
+		respV2Error(w, http.StatusUnauthorized, "unauthorized", "authorization failed")
+		return
+	}
+	if org := orgOfQuery(query); !isDefaultOrg(org) {
+		atomic.AddInt64(&s.counter.ErrorCount, 1)
+		respV2Error(w, http.StatusNotFound, "not found", fmt.Sprintf("organization (%s) not found, only the default organization (%d) is supported", org, ckdb.DEFAULT_ORG_ID))
+		return
+	}
+	bucket := query.Get("bucket")
+	if bucket == "" {
+		atomic.AddInt64(&s.counter.ErrorCount, 1)
+		respV2Error(w, http.StatusBadRequest, "invalid", "bucket is required")
+		return
+	}
+	status, err := s.write(r, bucket, query.Get("precision"))
+	if err != nil {
+		atomic.AddInt64(&s.counter.ErrorCount, 1)
+		respV2Error(w, status, "invalid", err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// v2 客户端通过 org 或 orgID 参数指定组织
+// v2 clients specify the organization by the org or orgID parameter
+func orgOfQuery(query url.Values) string {
+	if org := query.Get("org"); org != "" {
+		return org
+	}
+	return query.Get("orgID")
+}
+
+// ext_metrics 数据均写入默认组织的数据库，仅接受未指定组织或指定默认组织 id 的请求，避免其他组织的数据写入默认组织
+// ext_metrics are stored in the databases of the default organization, only requests without organization or
+// with the default organization id are accepted, to avoid writing the data of other organizations to the default organization
+func isDefaultOrg(org string) bool {
+	if org == "" {
+		return true
+	}
+	orgID, err := strconv.ParseUint(org, 10, 16)
+	return err == nil && orgID == ckdb.DEFAULT_ORG_ID
+}
+
+func (s *Server) write(r *http.Request, db, precision string) (int, error) {
+	modelsPrecision, ok := parsePrecision(precision)
+	if !ok {
+		return http.StatusBadRequest, fmt.Errorf("precision (%s) is invalid", precision)
+	}
+	body, err := s.readBody(r)
+	if err != nil {
+		if err == errTooLarge {
+			return http.StatusRequestEntityTooLarge, err
+		}
+		return http.StatusBadRequest, err
+	}
+	points, err := models.ParsePointsWithPrecision(body, time.Now().UTC(), modelsPrecision)
+	if err != nil && len(points) == 0 {
+		return http.StatusBadRequest, err
+	}
+	// 与 InfluxDB 行为一致，部分行解析失败时写入其余数据并返回错误
+	// same as InfluxDB, write the valid points and return error when some lines are invalid
+	if writeErr := s.sendPoints(points, db); writeErr != nil {
+		return http.StatusInternalServerError, writeErr
+	}
+	atomic.AddInt64(&s.counter.PointCount, int64(len(points)))
+	if err != nil {
+		return http.StatusBadRequest, fmt.Errorf("partial write: %s", err)
+	}
+	return http.StatusNoContent, nil
+}
+
+func (s *Server) readBody(r *http.Request) ([]byte, error) {
+	var reader io.Reader = r.Body
+	if r.Header.Get("Content-Encoding") == "gzip" {
+		gzipReader, err := gzip.NewReader(r.Body)
+		if err != nil {
+			return nil, fmt.Errorf("invalid gzip body: %s", err)
+		}
+		defer gzipReader.Close()
+		reader = gzipReader
+	}
+	// 多读一个字节用于判断是否超过限制
+	// read one more byte to check whether the limit is exceeded
+	body, err := io.ReadAll(io.LimitReader(reader, int64(s.config.MaxBodySize)+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(body) > s.config.MaxBodySize {
+		return nil, errTooLarge
+	}
+	return body, nil
+}
+
+// db/bucket 作为 measurement 的前缀，decoder 生成的虚拟表名为 influxdb.<db>.<measurement>
+// use db/bucket as the prefix of measurement, the virtual table name is influxdb.<db>.<measurement>
+func (s *Server) sendPoints(points []models.Point, db string) error {
+	encoder := &codec.SimpleEncoder{}
+	var chunk []byte
+	for _, point := range points {
+		if db != "" {
+			fields, err := point.Fields()
+			if err != nil {
+				return err
+			}
+			point, err = models.NewPoint(db+"."+string(point.Name()), point.Tags(), fields, point.Time())
+			if err != nil {
+				return err
+			}
+		}
+		line := point.String()
+		if len(chunk) > 0 && len(chunk)+len(line)+1 > MAX_CHUNK_SIZE {
+			encoder.WriteBytes(chunk)
+			s.putQueue(encoder.Bytes())
+			encoder.Reset()
+			chunk = chunk[:0]
+		}
+		chunk = append(chunk, line...)
+		chunk = append(chunk, '\n')
+	}
+	if len(chunk) > 0 {
+		encoder.WriteBytes(chunk)
+		s.putQueue(encoder.Bytes())
+	}
+	return nil
+}
+
+func (s *Server) putQueue(data []byte) {
+	recvBuffer, _ := receiver.AcquireRecvBuffer(len(data))
+	recvBuffer.Begin = 0
+	recvBuffer.End = copy(recvBuffer.Buffer, data)
+	index := atomic.AddUint64(&s.putIndex, 1)
+	s.queues.Put(queue.HashKey(index%uint64(s.nQueues)), recvBuffer)
+}
+
+func (s *Server) authorizedV1(r *http.Request) bool {
+	if s.config.Token == "" {
+		return true
+	}
+	if password := r.URL.Query().Get("p"); password != "" {
+		return s.tokenEqual(password)
+	}
+	if _, password, ok := r.BasicAuth(); ok {
+		return s.tokenEqual(password)
+	}
+	return s.authorizedV2(r)
+}
+
+func (s *Server) authorizedV2(r *http.Request) bool {
+	if s.config.Token == "" {
+		return true
+	}
+	token := r.Header.Get("Authorization")
+	if !strings.HasPrefix(token, "Token ") {
+		return false
+	}
+	return s.tokenEqual(strings.TrimPrefix(token, "Token "))
+}
+
+func (s *Server) tokenEqual(token string) bool {
+	return subtle.ConstantTimeCompare([]byte(token), []byte(s.config.Token)) == 1
+}
+
+// 转换为 models 支持的精度，v1 支持 n/u/ms/s/m/h，v2 支持 ns/us/ms/s
+// convert to the precision supported by models, v1 supports n/u/ms/s/m/h, v2 supports ns/us/ms/s
+func parsePrecision(precision string) (string, bool) {
+	switch precision {
+	case "", "n", "ns":
+		return "n", true
+	case "u", "us":
+		return "u", true
+	case "ms", "s", "m", "h":
+		return precision, true
+	}
+	return "", false
+}
+
+func respV1Error(w http.ResponseWriter, status int, message string) {
+	resp, _ := json.Marshal(map[string]string{"error": message})
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Influxdb-Error", message)
+	w.WriteHeader(status)
+	w.Write(resp)
+	log.Debugf("influxdb v1 write failed: %s", message)
+}
+
+func respV2Error(w http.ResponseWriter, status int, code, message string) {
+	resp, _ := json.Marshal(map[string]string{"code": code, "message": message})
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(resp)
+	log.Debugf("influxdb v2 write failed: %s", message)
+}
@@ -0,0 +1,181 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package influxdb
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/models"
+
+	"github.com/deepflowio/deepflow/server/ingester/ext_metrics/config"
+	"github.com/deepflowio/deepflow/server/libs/codec"
+	"github.com/deepflowio/deepflow/server/libs/queue"
+	"github.com/deepflowio/deepflow/server/libs/receiver"
+)
+
+type testQueues struct {
+	items []interface{}
+}
+
+func (q *testQueues) Put(key queue.HashKey, items ...interface{}) error {
+	q.items = append(q.items, items...)
+	return nil
+}
+
+func (q *testQueues) Puts(keys []queue.HashKey, items []interface{}) error {
+	q.items = append(q.items, items...)
+	return nil
+}
+
+func (q *testQueues) Len(queue.HashKey) int { return len(q.items) }
+
+func (q *testQueues) Close() error { return nil }
+
+// 按 decoder.handleTelegraf 的方式解析队列中的数据
+func (q *testQueues) points(t *testing.T) []models.Point {
+	var points []models.Point
+	for _, item := range q.items {
+		recvBuffer := item.(*receiver.RecvBuffer)
+		decoder := &codec.SimpleDecoder{}
+		decoder.Init(recvBuffer.Buffer[recvBuffer.Begin:recvBuffer.End])
+		for !decoder.IsEnd() {
+			data := decoder.ReadBytes()
+			if decoder.Failed() {
+				t.Fatal("decode recv buffer failed")
+			}
+			ps, err := models.ParsePoints(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			points = append(points, ps...)
+		}
+	}
+	return points
+}
+
+func newTestServer(token string) (*Server, *testQueues) {
+	queues := &testQueues{}
+	cfg := &config.InfluxDBWriteConfig{
+		ListenPort:  config.DefaultInfluxDBWritePort,
+		MaxBodySize: 1024,
+		Token:       token,
+	}
+	return NewServer(cfg, queues, 2), queues
+}
+
+func TestWriteV1(t *testing.T) {
+	s, queues := newTestServer("")
+	body := "cpu,host=a usage_user=1.5,usage_system=2i 1680000000\nmem,host=a used=100i 1680000001\n"
+	req := httptest.NewRequest("POST", "/write?db=telegraf&precision=s", bytes.NewBufferString(body))
+	w := httptest.NewRecorder()
+	s.server.Handler.ServeHTTP(w, req)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("got status %d, body %s", w.Code, w.Body.String())
+	}
+
+	points := queues.points(t)
+	if len(points) != 2 {
+		t.Fatalf("got %d points, want 2", len(points))
+	}
+	if name := string(points[0].Name()); name != "telegraf.cpu" {
+		t.Errorf("got measurement %s, want telegraf.cpu", name)
+	}
+	if !points[0].Time().Equal(time.Unix(1680000000, 0)) {
+		t.Errorf("got time %v", points[0].Time())
+	}
+	if host := points[1].Tags().GetString("host"); host != "a" {
+		t.Errorf("got host %s, want a", host)
+	}
+}
+
+func TestWriteV2Gzip(t *testing.T) {
+	s, queues := newTestServer("secret")
+	var buf bytes.Buffer
+	gzipWriter := gzip.NewWriter(&buf)
+	gzipWriter.Write([]byte("cpu,host=b usage_idle=90 1680000000000"))
+	gzipWriter.Close()
+
+	req := httptest.NewRequest("POST", "/api/v2/write?org=1&bucket=iot&precision=ms", bytes.NewReader(buf.Bytes()))
+	req.Header.Set("Content-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	s.server.Handler.ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("got status %d without token, want 401", w.Code)
+	}
+
+	req = httptest.NewRequest("POST", "/api/v2/write?org=1&bucket=iot&precision=ms", bytes.NewReader(buf.Bytes()))
+	req.Header.Set("Content-Encoding", "gzip")
+	req.Header.Set("Authorization", "Token secret")
+	w = httptest.NewRecorder()
+	s.server.Handler.ServeHTTP(w, req)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("got status %d, body %s", w.Code, w.Body.String())
+	}
+	points := queues.points(t)
+	if len(points) != 1 || string(points[0].Name()) != "iot.cpu" || !points[0].Time().Equal(time.UnixMilli(1680000000000)) {
+		t.Errorf("unexpected points %v", points)
+	}
+}
+
+func TestWriteV2DefaultOrg(t *testing.T) {
+	s, queues := newTestServer("")
+	for _, url := range []string{"/api/v2/write?bucket=iot", "/api/v2/write?orgID=1&bucket=iot"} {
+		req := httptest.NewRequest("POST", url, bytes.NewBufferString("cpu v=1 1680000000000000000"))
+		w := httptest.NewRecorder()
+		s.server.Handler.ServeHTTP(w, req)
+		if w.Code != http.StatusNoContent {
+			t.Errorf("%s: got status %d, body %s", url, w.Code, w.Body.String())
+		}
+	}
+	if points := queues.points(t); len(points) != 2 {
+		t.Errorf("got %d points, want 2", len(points))
+	}
+}
+
+func TestWriteInvalid(t *testing.T) {
+	s, queues := newTestServer("secret")
+	for _, c := range []struct {
+		url    string
+		body   string
+		status int
+	}{
+		{"/write?p=secret&precision=x", "cpu v=1", http.StatusBadRequest},
+		{"/write?p=secret", "cpu", http.StatusBadRequest},
+		{"/write?p=wrong", "cpu v=1", http.StatusUnauthorized},
+		{"/write?p=secret", string(bytes.Repeat([]byte("a"), 2048)), http.StatusRequestEntityTooLarge},
+		{"/api/v2/write", "cpu v=1", http.StatusBadRequest},
+		{"/api/v2/write?org=o&bucket=iot", "cpu v=1", http.StatusNotFound},
+		{"/api/v2/write?org=2&bucket=iot", "cpu v=1", http.StatusNotFound},
+		{"/api/v2/write?orgID=2&bucket=iot", "cpu v=1", http.StatusNotFound},
+	} {
+		req := httptest.NewRequest("POST", c.url, bytes.NewBufferString(c.body))
+		req.Header.Set("Authorization", "Token secret")
+		w := httptest.NewRecorder()
+		s.server.Handler.ServeHTTP(w, req)
+		if w.Code != c.status {
+			t.Errorf("%s: got status %d, want %d", c.url, w.Code, c.status)
+		}
+	}
+	if len(queues.items) != 0 {
+		t.Errorf("got %d queue items, want 0", len(queues.items))
+	}
+}
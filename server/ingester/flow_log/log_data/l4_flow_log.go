@@ -386,6 +386,8 @@ type FlowInfo struct {
 	NatRealPort1 uint16
 
 	DirectionScore uint8
+
+	OrgId uint16 // not stored, used to select the database of the org
 }
 
 var FlowInfoColumns = []*ckdb.Column{
@@ -973,6 +975,10 @@ func (f *L4FlowLog) EndTime() time.Duration {
 	return time.Duration(f.FlowInfo.EndTime) * time.Microsecond
 }
 
+func (f *L4FlowLog) OrgID() uint16 {
+	return f.FlowInfo.OrgId
+}
+
 func (f *L4FlowLog) String() string {
 	return fmt.Sprintf("flow: %+v\n", *f)
 }
@@ -1024,6 +1030,7 @@ func TaggedFlowToL4FlowLog(f *pb.TaggedFlow, platformData *grpc.PlatformInfoTabl
 	s.Internet.Fill(f.Flow)
 	s.KnowledgeGraph.FillL4(f.Flow, isIPV6, platformData)
 	s.FlowInfo.Fill(f.Flow)
+	s.FlowInfo.OrgId = platformData.QueryVtapOrgId(f.Flow.FlowKey.VtapId)
 	s.Metrics.Fill(f.Flow)
 
 	return s
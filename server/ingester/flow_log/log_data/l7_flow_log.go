@@ -64,6 +64,7 @@ type L7Base struct {
 	TapPort      uint32 `json:"tap_port"`
 	TapSide      string `json:"tap_side"`
 	VtapID       uint16 `json:"vtap_id"`
+	OrgId        uint16 `json:"-"` // not stored, used to select the database of the org
 	ReqTcpSeq    uint32 `json:"req_tcp_seq"`
 	RespTcpSeq   uint32 `json:"resp_tcp_seq"`
 	StartTime    int64  `json:"start_time"` // us
@@ -470,6 +471,10 @@ func (h *L7FlowLog) EndTime() time.Duration {
 	return time.Duration(h.L7Base.EndTime) * time.Microsecond
 }
 
+func (h *L7FlowLog) OrgID() uint16 {
+	return h.L7Base.OrgId
+}
+
 func (h *L7FlowLog) String() string {
 	return fmt.Sprintf("L7FlowLog: %+v\n", *h)
 }
@@ -518,6 +523,7 @@ func (b *L7Base) Fill(log *pb.AppProtoLogsData, platformData *grpc.PlatformInfoT
 	b.TunnelType = uint8(tunnelType)
 	b.TapSide = zerodoc.TAPSideEnum(l.TapSide).String()
 	b.VtapID = uint16(l.VtapId)
+	b.OrgId = platformData.QueryVtapOrgId(l.VtapId)
 	b.ReqTcpSeq = l.ReqTcpSeq
 	b.RespTcpSeq = l.RespTcpSeq
 	b.StartTime = int64(l.StartTime) / int64(time.Microsecond)
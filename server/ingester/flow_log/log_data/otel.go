@@ -55,6 +55,7 @@ func spanToL7FlowLog(vtapID uint16, span *v1.Span, resAttributes []*v11.KeyValue
 	h := AcquireL7FlowLog()
 	h._id = genID(uint32(span.EndTimeUnixNano/uint64(time.Second)), &L7FlowLogCounter, platformData.QueryAnalyzerID())
 	h.VtapID = vtapID
+	h.OrgId = platformData.QueryVtapOrgId(uint32(vtapID))
 	h.FillOTel(span, resAttributes, platformData)
 	return h
 }
@@ -112,6 +112,7 @@ func getPlatformInfos(t *zerodoc.Tag, platformData *grpc.PlatformInfoTable) (*gr
 func DocumentExpand(doc *app.Document, platformData *grpc.PlatformInfoTable) error {
 	t := doc.Tagger.(*zerodoc.Tag)
 	t.SetID("") // 由于需要修改Tag增删Field，清空ID避免字段脏
+	doc.OrgId = platformData.QueryVtapOrgId(uint32(t.VTAPID))
 
 	// vtap_acl 分钟级数据不用填充
 	if doc.Meter.ID() == zerodoc.ACL_ID &&
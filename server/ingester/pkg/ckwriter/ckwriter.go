@@ -53,17 +53,20 @@ type CKWriter struct {
 	batchSize    int    // 累积多少行数据，一起写入
 	flushTimeout int    // 超时写入： 单位秒
 	counterName  string // 写入成功失败的统计数据表名称，若写入失败，会根据该数据上报告警
+	timeZone     string
 
-	name         string // 数据库名-表名 用作 queue名字和counter名字
-	prepare      string // 写入数据时，先执行prepare
+	name         string    // 数据库名-表名 用作 queue名字和counter名字
+	defaultTable *orgTable // table of the default org
 	conns        []clickhouse.Conn
-	batchs       []driver.Batch
 	connCount    uint64
 	dataQueues   queue.FixedMultiQueue
 	counters     []Counter
 	putCounter   int
 	writeCounter uint64
 
+	orgTablesLock sync.Mutex
+	orgTables     map[uint16]*orgTable
+
 	wg   sync.WaitGroup
 	exit bool
 }
@@ -73,6 +76,26 @@ type CKItem interface {
 	Release()
 }
 
+// OrgItem is implemented by the items which belong to an organization,
+// items of the non-default organization are written to the organization's own database.
+type OrgItem interface {
+	OrgID() uint16
+}
+
+type orgTable struct {
+	table   *ckdb.Table
+	prepare string // 写入数据时，先执行prepare
+	batchs  []driver.Batch
+}
+
+func newOrgTable(table *ckdb.Table, batchCount int) *orgTable {
+	return &orgTable{
+		table:   table,
+		prepare: table.MakePrepareTableInsertSQL(),
+		batchs:  make([]driver.Batch, batchCount),
+	}
+}
+
 func ExecSQL(conn clickhouse.Conn, query string) error {
 	if len(query) > SQL_LOG_LENGTH {
 		log.Infof("Exec SQL: %s ...", query[:SQL_LOG_LENGTH])
@@ -142,7 +165,6 @@ func NewCKWriter(addrs []string, user, password, counterName, timeZone string, t
 
 	addrCount := len(addrs)
 	conns := make([]clickhouse.Conn, addrCount)
-	batchs := make([]driver.Batch, queueCount*addrCount)
 	for i := 0; i < addrCount; i++ {
 		if conns[i], err = clickhouse.Open(&clickhouse.Options{
 			Addr: []string{addrs[i]},
@@ -174,14 +196,15 @@ func NewCKWriter(addrs []string, user, password, counterName, timeZone string, t
 		batchSize:    batchSize,
 		flushTimeout: flushTimeout,
 		counterName:  counterName,
-
-		name:       name,
-		prepare:    table.MakePrepareTableInsertSQL(),
-		conns:      conns,
-		batchs:     batchs,
-		connCount:  uint64(len(conns)),
-		dataQueues: dataQueues,
-		counters:   make([]Counter, queueCount),
+		timeZone:     timeZone,
+
+		name:         name,
+		defaultTable: newOrgTable(table, queueCount*addrCount),
+		conns:        conns,
+		connCount:    uint64(len(conns)),
+		dataQueues:   dataQueues,
+		counters:     make([]Counter, queueCount),
+		orgTables:    make(map[uint16]*orgTable),
 	}, nil
 }
 
@@ -269,32 +292,102 @@ func (w *CKWriter) ResetConnection(connID int) error {
 	return err
 }
 
+// getOrgTable returns the table of the org, the table will be created in ClickHouse when first used.
+func (w *CKWriter) getOrgTable(orgID uint16) (*orgTable, error) {
+	w.orgTablesLock.Lock()
+	defer w.orgTablesLock.Unlock()
+	if t, ok := w.orgTables[orgID]; ok {
+		return t, nil
+	}
+
+	table := w.table.OrgTable(orgID)
+	for _, addr := range w.addrs {
+		if err := InitTable(addr, w.user, w.password, w.timeZone, table); err != nil {
+			return nil, err
+		}
+	}
+	t := newOrgTable(table, len(w.defaultTable.batchs))
+	w.orgTables[orgID] = t
+	return t, nil
+}
+
+// splitByOrg returns the items of the default org, and the items of other orgs grouped by org id.
+func splitByOrg(items []CKItem) ([]CKItem, map[uint16][]CKItem) {
+	var defaultItems []CKItem
+	var orgItems map[uint16][]CKItem
+	for i, item := range items {
+		orgID := uint16(ckdb.DEFAULT_ORG_ID)
+		if orgItem, ok := item.(OrgItem); ok {
+			orgID = orgItem.OrgID()
+		}
+		if ckdb.IsDefaultOrgID(orgID) {
+			if orgItems != nil {
+				defaultItems = append(defaultItems, item)
+			}
+			continue
+		}
+		// most items belong to the default org, only split when necessary
+		if orgItems == nil {
+			orgItems = make(map[uint16][]CKItem)
+			defaultItems = append(make([]CKItem, 0, len(items)), items[:i]...)
+		}
+		orgItems[orgID] = append(orgItems[orgID], item)
+	}
+	if orgItems == nil {
+		return items, nil
+	}
+	return defaultItems, orgItems
+}
+
 func (w *CKWriter) Write(queueID int, items []CKItem) {
+	defaultItems, orgItems := splitByOrg(items)
+	w.write(queueID, w.defaultTable, defaultItems)
+	for orgID, orgItems := range orgItems {
+		t, err := w.getOrgTable(orgID)
+		if err != nil {
+			if w.counters[queueID].WriteFailedCount == 0 {
+				log.Warningf("init table(%s.%s) of org(%d) failed, drop(%d) items: %s", w.table.Database, w.table.LocalName, orgID, len(orgItems), err)
+			}
+			w.counters[queueID].WriteFailedCount += int64(len(orgItems))
+			continue
+		}
+		w.write(queueID, t, orgItems)
+	}
+
+	for _, item := range items {
+		item.Release()
+	}
+}
+
+func (w *CKWriter) write(queueID int, t *orgTable, items []CKItem) {
+	if len(items) == 0 {
+		return
+	}
 	connID := int(atomic.AddUint64(&w.writeCounter, 1) % w.connCount)
-	if err := w.writeItems(queueID, connID, items); err != nil {
+	if err := w.writeItems(queueID, connID, t, items); err != nil {
 		// Prevent frequent log writing
 		logEnabled := w.counters[queueID].WriteFailedCount == 0
 		if logEnabled {
-			log.Warningf("write table(%s.%s) failed, will retry write(%d) items: %s", w.table.Database, w.table.LocalName, len(items), err)
+			log.Warningf("write table(%s.%s) failed, will retry write(%d) items: %s", t.table.Database, t.table.LocalName, len(items), err)
 		}
 		if err := w.ResetConnection(connID); err != nil {
 			log.Warningf("reconnect clickhouse failed: %s", err)
 			time.Sleep(time.Second * 10)
 		} else {
 			if logEnabled {
-				log.Infof("reconnect clickhouse success: %s %s", w.table.Database, w.table.LocalName)
+				log.Infof("reconnect clickhouse success: %s %s", t.table.Database, t.table.LocalName)
 			}
 		}
 
 		w.counters[queueID].RetryCount++
 		// 写失败重连后重试一次, 规避偶尔写失败问题
-		err = w.writeItems(queueID, connID, items)
+		err = w.writeItems(queueID, connID, t, items)
 		if logEnabled {
 			if err != nil {
 				w.counters[queueID].RetryFailedCount++
-				log.Warningf("retry write table(%s.%s) failed, drop(%d) items: %s", w.table.Database, w.table.LocalName, len(items), err)
+				log.Warningf("retry write table(%s.%s) failed, drop(%d) items: %s", t.table.Database, t.table.LocalName, len(items), err)
 			} else {
-				log.Infof("retry write table(%s.%s) success, write(%d) items", w.table.Database, w.table.LocalName, len(items))
+				log.Infof("retry write table(%s.%s) success, write(%d) items", t.table.Database, t.table.LocalName, len(items))
 			}
 		}
 		if err != nil {
@@ -305,10 +398,6 @@ func (w *CKWriter) Write(queueID int, items []CKItem) {
 	} else {
 		w.counters[queueID].WriteSuccessCount += int64(len(items))
 	}
-
-	for _, item := range items {
-		item.Release()
-	}
 }
 
 func IsNil(i interface{}) bool {
@@ -322,7 +411,7 @@ func IsNil(i interface{}) bool {
 	return false
 }
 
-func (w *CKWriter) writeItems(queueID, connID int, items []CKItem) error {
+func (w *CKWriter) writeItems(queueID, connID int, t *orgTable, items []CKItem) error {
 	if len(items) == 0 {
 		return nil
 	}
@@ -336,19 +425,19 @@ func (w *CKWriter) writeItems(queueID, connID int, items []CKItem) error {
 	}
 	var err error
 	batchID := queueID*int(w.connCount) + connID
-	batch := w.batchs[batchID]
+	batch := t.batchs[batchID]
 	if IsNil(batch) {
-		w.batchs[batchID], err = ck.PrepareBatch(context.Background(), w.prepare)
+		t.batchs[batchID], err = ck.PrepareBatch(context.Background(), t.prepare)
 		if err != nil {
 			return err
 		}
-		batch = w.batchs[batchID]
+		batch = t.batchs[batchID]
 	} else {
-		batch, err = ck.PrepareReuseBatch(context.Background(), w.prepare, batch)
+		batch, err = ck.PrepareReuseBatch(context.Background(), t.prepare, batch)
 		if err != nil {
 			return err
 		}
-		w.batchs[batchID] = batch
+		t.batchs[batchID] = batch
 	}
 
 	ckdbBlock := ckdb.NewBlock(batch)
@@ -361,7 +450,7 @@ func (w *CKWriter) writeItems(queueID, connID int, items []CKItem) error {
 	if err = ckdbBlock.Send(); err != nil {
 		return fmt.Errorf("send write block failed: %s", err)
 	} else {
-		log.Debugf("batch write success, table (%s.%s) commit %d items", w.table.Database, w.table.LocalName, len(items))
+		log.Debugf("batch write success, table (%s.%s) commit %d items", t.table.Database, t.table.LocalName, len(items))
 	}
 	return nil
 }
@@ -18,7 +18,9 @@ package decoder
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"math/big"
 	"net/url"
 	"strconv"
 	"strings"
@@ -40,7 +42,9 @@ import (
 	"github.com/pyroscope-io/pyroscope/pkg/convert/jfr"
 	"github.com/pyroscope-io/pyroscope/pkg/convert/pprof"
 	pprofile "github.com/pyroscope-io/pyroscope/pkg/convert/profile"
+	"github.com/pyroscope-io/pyroscope/pkg/convert/speedscope"
 	"github.com/pyroscope-io/pyroscope/pkg/ingestion"
+	"github.com/pyroscope-io/pyroscope/pkg/storage"
 	"github.com/pyroscope-io/pyroscope/pkg/storage/metadata"
 	"github.com/pyroscope-io/pyroscope/pkg/storage/segment"
 )
@@ -59,6 +63,12 @@ type Counter struct {
 	GolangProfileCount int64 `statsd:"golang-profile-count"`
 	EBPFProfileCount   int64 `statsd:"EBPF-profile-count"`
 
+	SpeedscopeProfileCount int64 `statsd:"speedscope-profile-count"`
+	CollapsedProfileCount  int64 `statsd:"collapsed-profile-count"`
+	LinesProfileCount      int64 `statsd:"lines-profile-count"`
+	TreeProfileCount       int64 `statsd:"tree-profile-count"`
+	TrieProfileCount       int64 `statsd:"trie-profile-count"`
+
 	UncompressSize int64 `statsd:"uncompress-size"`
 	CompressedSize int64 `statsd:"compressed-size"`
 
@@ -225,11 +235,132 @@ func (d *Decoder) handleProfileData(vtapID uint16, decoder *codec.SimpleDecoder)
 					return
 				}
 			}
-		case "speedscope", "tree", "trie", "lines":
-			// not implemented
-			continue
+		case "speedscope":
+			// speedscope JSON, e.g.: py-spy --format speedscope, rbspy --format speedscope
+			atomic.AddInt64(&d.counter.SpeedscopeProfileCount, 1)
+			metadata := d.buildMetaData(profile)
+			parser.profileName = metadata.Key.AppName()
+			err := d.sendProfileData(&speedscopeRawProfile{
+				RawProfile: speedscope.RawProfile{RawData: profile.Data},
+			}, profile.Format, parser, metadata)
+			if err != nil {
+				log.Errorf("decode speedscope profile data failed, offset=%d len=%d, err: %s", decoder.Offset(), len(decoder.Bytes()), err)
+				return
+			}
+		case "collapsed", "groups", "lines", "tree", "trie":
+			// 调用栈格式，collapsed/groups 为每行 `a;b;c count`，lines 为每行一个调用栈，tree/trie 为 pyroscope 的二进制格式
+			// stack formats, collapsed/groups is `a;b;c count` per line, lines is one stack per line, tree/trie is pyroscope binary format
+			d.countStackProfile(profile.Format)
+			metadata := d.buildMetaData(profile)
+			parser.profileName = metadata.Key.AppName()
+			err := d.sendProfileData(newStackRawProfile(profile.Format, profile.Data), profile.Format, parser, metadata)
+			if err != nil {
+				log.Errorf("decode %s profile data failed, offset=%d len=%d, err: %s", profile.Format, decoder.Offset(), len(decoder.Bytes()), err)
+				return
+			}
+		}
+	}
+}
+
+func (d *Decoder) countStackProfile(format string) {
+	switch format {
+	case "collapsed", "groups":
+		atomic.AddInt64(&d.counter.CollapsedProfileCount, 1)
+	case "lines":
+		atomic.AddInt64(&d.counter.LinesProfileCount, 1)
+	case "tree":
+		atomic.AddInt64(&d.counter.TreeProfileCount, 1)
+	case "trie":
+		atomic.AddInt64(&d.counter.TrieProfileCount, 1)
+	}
+}
+
+// collapsed 即 pyroscope 的 groups 格式（FlameGraph stackcollapse 输出）
+// collapsed is the groups format of pyroscope (output of FlameGraph stackcollapse)
+func newStackRawProfile(format string, data []byte) *pprofile.RawProfile {
+	ingestionFormat := ingestion.Format(format)
+	if format == "collapsed" {
+		ingestionFormat = ingestion.FormatGroups
+	}
+	return &pprofile.RawProfile{
+		Format:  ingestionFormat,
+		RawData: data,
+	}
+}
+
+// speedscope 解析遇到未知的 unit 会 panic，转换为 error 避免 decoder 退出
+// speedscope parser panics on unknown unit, recover it as error to keep the decoder running
+type speedscopeRawProfile struct {
+	speedscope.RawProfile
+}
+
+// 只解析各 profile 的 unit，解析器按 profile 的顺序写入
+// only the unit of each profile is decoded, the parser puts profiles in order
+type speedscopeUnits struct {
+	Profiles []struct {
+		Unit string `json:"unit"`
+	} `json:"profiles"`
+}
+
+func (p *speedscopeRawProfile) Parse(ctx context.Context, putter storage.Putter, exporter storage.MetricsExporter, md ingestion.Metadata) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("parse speedscope profile failed: %v", r)
+		}
+	}()
+	units := speedscopeUnits{}
+	// 格式错误由解析器返回
+	// format errors are returned by the parser
+	json.Unmarshal(p.RawData, &units)
+	sPutter := &speedscopePutter{Putter: putter, sampleRate: md.SampleRate}
+	for _, profile := range units.Profiles {
+		sPutter.units = append(sPutter.units, profile.Unit)
+	}
+	return p.RawProfile.Parse(ctx, sPutter, exporter, md)
+}
+
+// speedscope 解析时非 bytes 单位的权重会乘以精度系数 100，写入前还原为原始值
+// speedscope parser multiplies weights of units except bytes by a precision multiplier 100, restore them before put
+const SPEEDSCOPE_PRECISION_MULTIPLIER = 100
+
+// 解析器只在采样率为默认值 100 时替换为 unit 对应的采样率
+// the parser replaces the sample rate with the one of the unit only when it is the default 100
+const SPEEDSCOPE_DEFAULT_SAMPLE_RATE = 100
+
+// 解析器替换的采样率乘以了精度系数，nanoseconds 还会溢出 uint32，按 unit 使用未乘精度系数的采样率
+// sample rates replaced by the parser are multiplied by the precision multiplier and nanoseconds overflows uint32,
+// use the sample rates of units without the multiplier instead
+var speedscopeUnitSampleRates = map[string]uint32{
+	"nanoseconds":  1000 * 1000 * 1000,
+	"microseconds": 1000 * 1000,
+	"milliseconds": 1000,
+	"seconds":      1,
+	"none":         SPEEDSCOPE_DEFAULT_SAMPLE_RATE,
+}
+
+type speedscopePutter struct {
+	storage.Putter
+	sampleRate uint32   // 客户端上报的采样率 sample rate reported by the client
+	units      []string // 各 profile 的 unit the unit of each profile
+	index      int
+}
+
+func (p *speedscopePutter) Put(ctx context.Context, input *storage.PutInput) error {
+	unit := ""
+	if p.index < len(p.units) {
+		unit = p.units[p.index]
+	}
+	p.index++
+
+	if input.Units != metadata.BytesUnits && input.Val != nil {
+		input.Val = input.Val.Clone(big.NewRat(1, SPEEDSCOPE_PRECISION_MULTIPLIER))
+	}
+	if p.sampleRate == SPEEDSCOPE_DEFAULT_SAMPLE_RATE && input.SampleRate != p.sampleRate {
+		if sampleRate, ok := speedscopeUnitSampleRates[unit]; ok {
+			input.SampleRate = sampleRate
 		}
 	}
+	return p.Putter.Put(ctx, input)
 }
 
 func (d *Decoder) filleBPFData(profile *pb.Profile) *pb.Profile {
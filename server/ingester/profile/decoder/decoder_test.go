@@ -0,0 +1,214 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package decoder
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/pyroscope-io/pyroscope/pkg/convert/speedscope"
+	"github.com/pyroscope-io/pyroscope/pkg/ingestion"
+	"github.com/pyroscope-io/pyroscope/pkg/storage"
+	"github.com/pyroscope-io/pyroscope/pkg/storage/tree"
+	"github.com/pyroscope-io/pyroscope/pkg/structs/transporttrie"
+
+	"github.com/deepflowio/deepflow/server/ingester/profile/dbwriter"
+	"github.com/deepflowio/deepflow/server/libs/grpc"
+	"github.com/deepflowio/deepflow/server/libs/zerodoc/pb"
+)
+
+var testPlatformData = grpc.NewPlatformInfoTable(nil, 0, 0, 0, "", "", nil, true, nil)
+
+func newTestProfile(format string, data []byte) *pb.Profile {
+	now := uint32(time.Now().Unix())
+	return &pb.Profile{
+		Name:            "python-app.cpu{}",
+		Format:          format,
+		From:            now - 10,
+		Until:           now,
+		SpyName:         "pyspy",
+		SampleRate:      100,
+		Units:           "samples",
+		AggregationType: "sum",
+		Data:            data,
+	}
+}
+
+// 解析 profile，返回按调用栈排序的 `stack value` 列表
+func parseStacks(t *testing.T, profile *pb.Profile, rawProfile ingestion.RawProfile) []string {
+	d := &Decoder{counter: &Counter{}}
+	var stacks []string
+	parser := &Parser{
+		inTimestamp:  time.Now(),
+		platformData: testPlatformData,
+		IP:           []byte{10, 1, 2, 3},
+		observer:     &observer{},
+		Counter:      d.counter,
+		callBack: func(item interface{}) {
+			p := item.(*dbwriter.InProcessProfile)
+			location := p.ProfileLocationStr
+			if p.CompressionAlgo == "zstd" {
+				location = deCompress([]byte(location))
+			}
+			stacks = append(stacks, fmt.Sprintf("%s %d", location, p.ProfileValue))
+		},
+	}
+	metadata := d.buildMetaData(profile)
+	parser.profileName = metadata.Key.AppName()
+	if err := d.sendProfileData(rawProfile, profile.Format, parser, metadata); err != nil {
+		t.Fatalf("parse %s profile failed: %v", profile.Format, err)
+	}
+	sort.Strings(stacks)
+	return stacks
+}
+
+func TestCollapsedProfile(t *testing.T) {
+	data, err := os.ReadFile("testdata/collapsed.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		"<module>;main (app.py:12);handle (app.py:30);query (db.py:8) 7",
+		"<module>;main (app.py:12);handle (app.py:30);render (view.py:21) 3",
+		"<module>;main (app.py:12);sleep (time.py:1) 2",
+	}
+	for _, format := range []string{"collapsed", "groups"} {
+		profile := newTestProfile(format, data)
+		got := parseStacks(t, profile, newStackRawProfile(profile.Format, profile.Data))
+		if !reflect.DeepEqual(got, expected) {
+			t.Errorf("format %s: got %v, want %v", format, got, expected)
+		}
+	}
+}
+
+func TestLinesProfile(t *testing.T) {
+	profile := newTestProfile("lines", []byte("a;b;c\na;b;c\na;d\n"))
+	got := parseStacks(t, profile, newStackRawProfile(profile.Format, profile.Data))
+	expected := []string{"a;b;c 2", "a;d 1"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("got %v, want %v", got, expected)
+	}
+}
+
+func TestTreeProfile(t *testing.T) {
+	tr := tree.New()
+	tr.Insert([]byte("a;b;c"), 5)
+	tr.Insert([]byte("a;d"), 2)
+	var buf bytes.Buffer
+	if err := tr.SerializeTruncateNoDict(1024, &buf); err != nil {
+		t.Fatal(err)
+	}
+	profile := newTestProfile("tree", buf.Bytes())
+	got := parseStacks(t, profile, newStackRawProfile(profile.Format, profile.Data))
+	expected := []string{"a;b;c 5", "a;d 2"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("got %v, want %v", got, expected)
+	}
+}
+
+func TestTrieProfile(t *testing.T) {
+	tr := transporttrie.New()
+	tr.Insert([]byte("a;b;c"), 4)
+	tr.Insert([]byte("a;d"), 1)
+	profile := newTestProfile("trie", tr.Bytes())
+	got := parseStacks(t, profile, newStackRawProfile(profile.Format, profile.Data))
+	expected := []string{"a;b;c 4", "a;d 1"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("got %v, want %v", got, expected)
+	}
+}
+
+func TestSpeedscopeProfile(t *testing.T) {
+	data, err := os.ReadFile("testdata/speedscope.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	profile := newTestProfile("speedscope", data)
+	got := parseStacks(t, profile, &speedscopeRawProfile{RawProfile: speedscope.RawProfile{RawData: profile.Data}})
+	expected := []string{"<main>;run;query 3", "<main>;run;render 1"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("got %v, want %v", got, expected)
+	}
+
+	data = bytes.Replace(data, []byte(`"unit": "none"`), []byte(`"unit": "unknown"`), 1)
+	d := &Decoder{counter: &Counter{}}
+	parser := &Parser{observer: &observer{}, Counter: d.counter}
+	err = d.sendProfileData(&speedscopeRawProfile{RawProfile: speedscope.RawProfile{RawData: data}}, profile.Format, parser, d.buildMetaData(profile))
+	if err == nil {
+		t.Error("expected error for unknown speedscope unit")
+	}
+}
+
+type testPutter struct {
+	inputs []*storage.PutInput
+}
+
+func (p *testPutter) Put(_ context.Context, input *storage.PutInput) error {
+	p.inputs = append(p.inputs, input)
+	return nil
+}
+
+func TestSpeedscopeSampleRate(t *testing.T) {
+	data, err := os.ReadFile("testdata/speedscope.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		unit       string
+		sampleRate uint32
+		expected   uint32
+	}{
+		{"none", 100, 100},
+		{"none", 250, 250},
+		{"milliseconds", 100, 1000},
+		{"milliseconds", 250, 250},
+		{"nanoseconds", 100, 1000 * 1000 * 1000},
+		{"bytes", 100, 0},
+	} {
+		profile := newTestProfile("speedscope", bytes.Replace(data, []byte(`"unit": "none"`), []byte(fmt.Sprintf(`"unit": "%s"`, tt.unit)), 1))
+		profile.SampleRate = tt.sampleRate
+		d := &Decoder{counter: &Counter{}}
+		putter := &testPutter{}
+		rawProfile := &speedscopeRawProfile{RawProfile: speedscope.RawProfile{RawData: profile.Data}}
+		if err := rawProfile.Parse(context.Background(), putter, nil, d.buildMetaData(profile)); err != nil {
+			t.Fatalf("unit %s: parse failed: %v", tt.unit, err)
+		}
+		if len(putter.inputs) != 1 {
+			t.Fatalf("unit %s: got %d inputs, want 1", tt.unit, len(putter.inputs))
+		}
+		if putter.inputs[0].SampleRate != tt.expected {
+			t.Errorf("unit %s, sample rate %d: got %d, want %d", tt.unit, tt.sampleRate, putter.inputs[0].SampleRate, tt.expected)
+		}
+	}
+}
+
+func TestCountStackProfile(t *testing.T) {
+	d := &Decoder{counter: &Counter{}}
+	for _, format := range []string{"collapsed", "groups", "lines", "tree", "trie", "trie"} {
+		d.countStackProfile(format)
+	}
+	c := d.counter
+	if c.CollapsedProfileCount != 2 || c.LinesProfileCount != 1 || c.TreeProfileCount != 1 || c.TrieProfileCount != 2 {
+		t.Errorf("unexpected counter %+v", *c)
+	}
+}
@@ -41,6 +41,7 @@ type Document struct {
 	zerodoc.Tagger
 	zerodoc.Meter
 	Flags DocumentFlag
+	OrgId uint16 // not encoded, used to select the database of the org
 }
 
 const (
@@ -83,6 +84,7 @@ func CloneDocument(doc *Document) *Document {
 	newDoc.Tagger = doc.Tagger.Clone()
 	newDoc.Meter = doc.Meter.Clone()
 	newDoc.Flags = doc.Flags
+	newDoc.OrgId = doc.OrgId
 	return newDoc
 }
 
@@ -164,6 +166,10 @@ func (d *Document) WriteBlock(block *ckdb.Block) {
 	d.Meter.WriteBlock(block)
 }
 
+func (d *Document) OrgID() uint16 {
+	return d.OrgId
+}
+
 func (d *Document) TableID() (uint8, error) {
 	tag, _ := d.Tagger.(*zerodoc.Tag)
 	return tag.TableID((d.Flags & FLAG_PER_SECOND_METRICS) == 1)
@@ -0,0 +1,60 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ckdb
+
+import (
+	"fmt"
+)
+
+const (
+	INVALID_ORG_ID = 0
+	DEFAULT_ORG_ID = 1
+	MAX_ORG_ID     = 1024
+)
+
+// IsDefaultOrgID returns true if the org uses the databases without prefix.
+// Data without org information (INVALID_ORG_ID) belongs to the default org.
+func IsDefaultOrgID(orgID uint16) bool {
+	return orgID == DEFAULT_ORG_ID || orgID == INVALID_ORG_ID
+}
+
+func IsValidOrgID(orgID uint16) bool {
+	return orgID != INVALID_ORG_ID && orgID <= MAX_ORG_ID
+}
+
+// OrgDatabasePrefix returns the database prefix of the org, such as 'org0002_',
+// the default org has no prefix.
+func OrgDatabasePrefix(orgID uint16) string {
+	if IsDefaultOrgID(orgID) {
+		return ""
+	}
+	return fmt.Sprintf("org%04d_", orgID)
+}
+
+func OrgDatabase(orgID uint16, database string) string {
+	return OrgDatabasePrefix(orgID) + database
+}
+
+// OrgTable returns a copy of the table which is stored in the database of the org.
+func (t *Table) OrgTable(orgID uint16) *Table {
+	if IsDefaultOrgID(orgID) {
+		return t
+	}
+	orgTable := *t
+	orgTable.Database = OrgDatabase(orgID, t.Database)
+	return &orgTable
+}
@@ -0,0 +1,62 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ckdb
+
+import (
+	"testing"
+)
+
+func TestOrgDatabase(t *testing.T) {
+	tests := []struct {
+		orgID uint16
+		want  string
+	}{
+		{INVALID_ORG_ID, "flow_log"},
+		{DEFAULT_ORG_ID, "flow_log"},
+		{2, "org0002_flow_log"},
+		{MAX_ORG_ID, "org1024_flow_log"},
+	}
+	for _, tt := range tests {
+		if got := OrgDatabase(tt.orgID, "flow_log"); got != tt.want {
+			t.Errorf("OrgDatabase(%d) = %s, want %s", tt.orgID, got, tt.want)
+		}
+	}
+}
+
+func TestIsValidOrgID(t *testing.T) {
+	for orgID, want := range map[uint16]bool{INVALID_ORG_ID: false, DEFAULT_ORG_ID: true, MAX_ORG_ID: true, MAX_ORG_ID + 1: false} {
+		if got := IsValidOrgID(orgID); got != want {
+			t.Errorf("IsValidOrgID(%d) = %v, want %v", orgID, got, want)
+		}
+	}
+}
+
+func TestOrgTable(t *testing.T) {
+	table := &Table{Database: "flow_log", LocalName: "l4_flow_log_local", GlobalName: "l4_flow_log"}
+
+	if orgTable := table.OrgTable(DEFAULT_ORG_ID); orgTable != table {
+		t.Errorf("OrgTable of default org should be the table itself")
+	}
+
+	orgTable := table.OrgTable(2)
+	if orgTable.Database != "org0002_flow_log" || orgTable.LocalName != table.LocalName || orgTable.GlobalName != table.GlobalName {
+		t.Errorf("OrgTable(2) = %+v, want table in database org0002_flow_log", orgTable)
+	}
+	if table.Database != "flow_log" {
+		t.Errorf("OrgTable should not modify the original table, database is %s", table.Database)
+	}
+}
@@ -187,6 +187,17 @@ func (a NpbActions) isTunnelType(tunnelType uint8) bool {
 	return a.TunnelType() == tunnelType
 }
 
+func (a NpbActions) actionFlag() ActionFlag {
+	if a.isTunnelType(NPB_TUNNEL_TYPE_PCAP) {
+		return ACTION_PCAP
+	} else if a.isTunnelType(NPB_TUNNEL_TYPE_VXLAN) || a.isTunnelType(NPB_TUNNEL_TYPE_GRE_ERSPAN) {
+		return ACTION_NPB
+	} else if a.isTunnelType(NPB_TUNNEL_TYPE_NPB_DROP) {
+		return ACTION_NPB_DROP
+	}
+	return 0
+}
+
 func (a NpbActions) doTridentPcap() bool {
 	return a.isTunnelType(NPB_TUNNEL_TYPE_PCAP)
 }
@@ -311,10 +322,15 @@ func (d *PolicyData) Dedup(packet *LookupKey) {
 		return
 	}
 	validActions := d.dedupNpbAction(packet)
-	if len(validActions) == 0 && d.ActionFlags == 0 {
+	if len(validActions) == 0 {
 		*d = *INVALID_POLICY_DATA
 		return
 	}
+	// ActionFlags 由 NpbActions 生成，去掉的 NpbActions 对应的 ActionFlags 也需去掉
+	d.ActionFlags = 0
+	for _, action := range validActions {
+		d.ActionFlags |= action.actionFlag()
+	}
 	d.NpbActions = validActions
 }
 
@@ -367,13 +383,7 @@ func (d *PolicyData) MergeNpbAction(actions []NpbActions, aclID uint32, directio
 			if len(directions) > 0 {
 				npbAction.SetTapSide(int(directions[0]))
 			}
-			if npbAction.isTunnelType(NPB_TUNNEL_TYPE_PCAP) {
-				d.ActionFlags |= ACTION_PCAP
-			} else if npbAction.isTunnelType(NPB_TUNNEL_TYPE_VXLAN) || npbAction.isTunnelType(NPB_TUNNEL_TYPE_GRE_ERSPAN) {
-				d.ActionFlags |= ACTION_NPB
-			} else if npbAction.isTunnelType(NPB_TUNNEL_TYPE_NPB_DROP) {
-				d.ActionFlags |= ACTION_NPB_DROP
-			}
+			d.ActionFlags |= npbAction.actionFlag()
 			d.NpbActions = append(d.NpbActions, npbAction)
 		}
 	}
@@ -227,7 +227,11 @@ func newPolicyData(acl *trident.FlowAcl) *policy.Acl {
 // response.GetFlowAcls()
 func Convert2AclData(flowAcls []*trident.FlowAcl) []*policy.Acl {
 	updateTunnelIpMap(flowAcls)
+	return Convert2AclDataWithoutTunnel(flowAcls)
+}
 
+// 不更新全局隧道IP表，用于策略模拟等不影响实际转发的场景
+func Convert2AclDataWithoutTunnel(flowAcls []*trident.FlowAcl) []*policy.Acl {
 	policies := make([]*policy.Acl, 0, len(flowAcls))
 	for _, acl := range flowAcls {
 		if newData := newPolicyData(acl); newData != nil {
@@ -26,6 +26,14 @@ const (
 	RESOURCE_EVENT_TYPE_RECREATE     = "recreate"
 	RESOURCE_EVENT_TYPE_ADD_IP       = "add-ip"
 	RESOURCE_EVENT_TYPE_REMOVE_IP    = "remove-ip"
+
+	RESOURCE_EVENT_TYPE_UPDATE_VTAP_GROUP = "update-vtap-group"
+)
+
+// Type of kubernetes events, the reason of the kubernetes event is used as the event type
+const (
+	K8S_EVENT_TYPE_NORMAL  = "Normal"
+	K8S_EVENT_TYPE_WARNING = "Warning"
 )
 
 type ResourceEvent struct {
@@ -56,6 +64,10 @@ type ResourceEvent struct {
 	PodID        uint32
 	SubnetID     uint32
 	IP           string
+
+	K8sEventType    string // if this value is set, the event is a kubernetes event
+	AttributeNames  []string
+	AttributeValues []string
 }
 
 type TagFieldOption func(opts *ResourceEvent)
@@ -72,6 +84,19 @@ func TagAttributeIPs(ips []string) TagFieldOption {
 	}
 }
 
+func TagAttributes(names, values []string) TagFieldOption {
+	return func(r *ResourceEvent) {
+		r.AttributeNames = names
+		r.AttributeValues = values
+	}
+}
+
+func TagK8sEventType(eventType string) TagFieldOption {
+	return func(r *ResourceEvent) {
+		r.K8sEventType = eventType
+	}
+}
+
 func TagDescription(description string) TagFieldOption {
 	return func(r *ResourceEvent) {
 		r.Description = description
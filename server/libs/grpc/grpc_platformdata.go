@@ -35,6 +35,7 @@ import (
 	"golang.org/x/net/context"
 
 	"github.com/deepflowio/deepflow/message/trident"
+	"github.com/deepflowio/deepflow/server/libs/ckdb"
 	"github.com/deepflowio/deepflow/server/libs/datatype"
 	"github.com/deepflowio/deepflow/server/libs/hmap/lru"
 	"github.com/deepflowio/deepflow/server/libs/receiver"
@@ -107,6 +108,7 @@ type VtapInfo struct {
 	EpcId        int32
 	Ip           string
 	PodClusterId uint32
+	OrgId        uint16
 }
 
 type Counter struct {
@@ -1373,6 +1375,14 @@ func (t *PlatformInfoTable) QueryVtapInfo(vtapId uint32) *VtapInfo {
 	return nil
 }
 
+// QueryVtapOrgId returns the org which the vtap belongs to, if the vtap is unknown, return the default org
+func (t *PlatformInfoTable) QueryVtapOrgId(vtapId uint32) uint16 {
+	if vtapInfo, ok := t.vtapIdInfos[vtapId]; ok && vtapInfo.OrgId != ckdb.INVALID_ORG_ID {
+		return vtapInfo.OrgId
+	}
+	return ckdb.DEFAULT_ORG_ID
+}
+
 func (t *PlatformInfoTable) inPlatformData(epcID int32, isIPv4 bool, ip4 uint32, ip6 net.IP) bool {
 	if isIPv4 {
 		if t.queryIPV4Infos(epcID, ip4) != nil {
@@ -1439,6 +1449,7 @@ func (t *PlatformInfoTable) updateVtapIps(vtapIps []*trident.VtapIp) {
 			EpcId:        epcId,
 			Ip:           vtapIp.GetIp(),
 			PodClusterId: vtapIp.GetPodClusterId(),
+			OrgId:        uint16(vtapIp.GetOrgId()),
 		}
 	}
 	t.vtapIdInfos = vtapIdInfos
@@ -20,6 +20,7 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"net"
 	"os"
 	"sort"
 	"time"
@@ -731,6 +732,24 @@ func (d *Ddbs) GetAcl() []*Acl {
 	return d.RawAcls
 }
 
+// 仅用于策略模拟和命令行，与策略匹配相同，资源组EPC为0时匹配任意EPC
+func (d *Ddbs) GetGroupIds(ip net.IP, l3Epc uint16) []uint32 {
+	ids := make([]uint32, 0, 4)
+	for id, segments := range d.groupIpMap {
+		for i := range segments {
+			if epc := segments[i].getEpcId(); epc != 0 && epc != l3Epc {
+				continue
+			}
+			if segments[i].contains(ip) {
+				ids = append(ids, uint32(id))
+				break
+			}
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
 func (d *Ddbs) SetCloudPlatform(cloudPlatformLabeler *CloudPlatformLabeler) {
 	d.cloudPlatformLabeler = cloudPlatformLabeler
 }
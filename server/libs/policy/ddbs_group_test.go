@@ -0,0 +1,47 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package policy
+
+import (
+	"net"
+	"reflect"
+	"testing"
+)
+
+func TestDdbsGetGroupIds(t *testing.T) {
+	table := NewPolicyTable(1, 8, 1024, false, DDBS)
+	table.UpdateIpGroupData([]*IpGroupData{
+		generateIpGroup(10, 0, "10.0.0.0/8"),
+		generateIpGroup(11, 5, "10.1.0.0/16"),
+		generateIpGroup(12, 0, "fd00::/64"),
+	})
+	tests := []struct {
+		ip   string
+		epc  uint16
+		want []uint32
+	}{
+		{"10.1.1.1", 5, []uint32{10, 11}},
+		{"10.1.1.1", 6, []uint32{10}},
+		{"fd00::1", 5, []uint32{12}},
+		{"192.168.0.1", 5, []uint32{}},
+	}
+	for _, tt := range tests {
+		if got := table.GetGroupIds(net.ParseIP(tt.ip), tt.epc); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("GetGroupIds(%s, %d) = %v, want %v", tt.ip, tt.epc, got, tt.want)
+		}
+	}
+}
@@ -87,3 +87,13 @@ func (s *ipSegment) getMask() uint32 {
 func (s *ipSegment) getMask6() (uint64, uint64) {
 	return s.mask0, s.mask1
 }
+
+func (s *ipSegment) contains(ip net.IP) bool {
+	if ip4 := ip.To4(); ip4 != nil {
+		return !s.ipv6 && IpToUint32(ip4)&s.mask == s.ip
+	}
+	if !s.ipv6 || len(ip) != net.IPv6len {
+		return false
+	}
+	return binary.BigEndian.Uint64(ip)&s.mask0 == s.ip0 && binary.BigEndian.Uint64(ip[8:])&s.mask1 == s.ip1
+}
@@ -97,6 +97,7 @@ type TableOperator interface {
 	UpdateIpGroupData(data []*IpGroupData)
 	UpdateCidr(data []*Cidr)
 	UpdateMemoryLimit(memoryLimit uint64)
+	GetGroupIds(ip net.IP, l3Epc uint16) []uint32
 
 	SetCloudPlatform(cloudPlatformLabeler *CloudPlatformLabeler)
 
@@ -283,6 +284,11 @@ func (t *PolicyTable) GetEndpointInfo(mac uint64, ip net.IP, inPort uint32) *End
 	return endpointInfo
 }
 
+// 该函数仅用于策略模拟或命令行使用
+func (t *PolicyTable) GetGroupIds(ip net.IP, l3Epc uint16) []uint32 {
+	return t.operator.GetGroupIds(ip, l3Epc)
+}
+
 // 测试使用
 func (t *PolicyTable) GetPolicyByFastPath(key *LookupKey) (*EndpointData, *PolicyData) {
 	policy := new(PolicyData)
@@ -820,6 +820,14 @@ func generateLookupKey(srcMac, dstMac uint64, srcIp, dstIp uint32,
 	return key
 }
 
+// 分光镜像流量的 inPort 为 PACKET_SOURCE_ISP + tapType
+func GetTapType(inPort uint32) TapType {
+	if PACKET_SOURCE_TOR == inPort&PACKET_SOURCE_TOR {
+		return TAP_CLOUD
+	}
+	return TapType(inPort & 0xffff)
+}
+
 func toPcapAction(aclGid, id uint32, tunnelType, tapSide uint8, slice uint16) NpbActions {
 	return ToNpbActions(aclGid, id, tunnelType, tapSide, slice)
 }
@@ -204,7 +204,7 @@ func NewRemoteReadQueryCache() *RemoteReadQueryCache {
 	return s
 }
 
-func (s *RemoteReadQueryCache) AddOrMerge(req *prompb.ReadRequest, data *common.Result, item *CacheItem) *common.Result {
+func (s *RemoteReadQueryCache) AddOrMerge(orgID uint16, req *prompb.ReadRequest, data *common.Result, item *CacheItem) *common.Result {
 	if req == nil || len(req.Queries) == 0 {
 		return nil
 	}
@@ -213,7 +213,7 @@ func (s *RemoteReadQueryCache) AddOrMerge(req *prompb.ReadRequest, data *common.
 		return data
 	}
 
-	key, _, start, end := promRequestToCacheKey(q)
+	key, _, start, end := promRequestToCacheKey(orgID, q)
 	start, end = timeAlign(start, end)
 	if item == nil {
 		// cache miss
@@ -238,7 +238,7 @@ func (s *RemoteReadQueryCache) AddOrMerge(req *prompb.ReadRequest, data *common.
 	}
 }
 
-func (s *RemoteReadQueryCache) Get(req *prompb.ReadRequest) (*CacheItem, CacheHit, string, int64, int64) {
+func (s *RemoteReadQueryCache) Get(orgID uint16, req *prompb.ReadRequest) (*CacheItem, CacheHit, string, int64, int64) {
 	if req == nil || len(req.Queries) == 0 {
 		return nil, CacheMiss, "", 0, 0
 	}
@@ -254,7 +254,7 @@ func (s *RemoteReadQueryCache) Get(req *prompb.ReadRequest) (*CacheItem, CacheHi
 	}
 
 	// for query api, cache query samples
-	key, metric, start, end := promRequestToCacheKey(q)
+	key, metric, start, end := promRequestToCacheKey(orgID, q)
 	start, end = timeAlign(start, end)
 	item, ok := s.cache.Get(key)
 	if !ok {
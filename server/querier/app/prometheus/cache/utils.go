@@ -18,6 +18,7 @@ package cache
 
 import (
 	"reflect"
+	"strconv"
 	"strings"
 	"unsafe"
 
@@ -39,8 +40,11 @@ func timeAlign(startMs int64, endMs int64) (int64, int64) {
 	return startMs - startMs%60000, endMs + (60000 - endMs%60000)
 }
 
-func promRequestToCacheKey(q *prompb.Query) (string, string, int64, int64) {
+// results of different orgs are cached separately
+func promRequestToCacheKey(orgID uint16, q *prompb.Query) (string, string, int64, int64) {
 	matcher := &strings.Builder{}
+	matcher.WriteString(strconv.Itoa(int(orgID)))
+	matcher.WriteByte('-')
 	var metric string
 	for i := 0; i < len(q.Matchers); i++ {
 		matcher.WriteString(q.Matchers[i].GetName() + q.Matchers[i].Type.String() + q.Matchers[i].GetValue())
@@ -52,7 +52,8 @@ func (p *prometheusReader) promReaderExecute(ctx context.Context, req *prompb.Re
 	var metricName string
 	var result *common.Result
 
-	item, hit, metricName, start, end := cache.RemoteReadCache().Get(req)
+	orgID := common.GetOrgID(ctx)
+	item, hit, metricName, start, end := cache.RemoteReadCache().Get(orgID, req)
 	if hit == cache.CacheHitFull {
 		result = item.Data()
 		if strings.Contains(metricName, "__") {
@@ -145,7 +146,7 @@ func (p *prometheusReader) promReaderExecute(ctx context.Context, req *prompb.Re
 
 	if config.Cfg.Prometheus.Cache.Enabled {
 		// add or merge query result
-		result = cache.RemoteReadCache().AddOrMerge(req, result, item)
+		result = cache.RemoteReadCache().AddOrMerge(orgID, req, result, item)
 	}
 
 	// response trans to prom resp
@@ -0,0 +1,56 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package common
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/deepflowio/deepflow/server/libs/ckdb"
+)
+
+const HEADER_KEY_X_ORG_ID = "X-Org-Id"
+
+type orgIDKey struct{}
+
+// ParseOrgID parses the value of the X-Org-Id header, an empty value means the default org.
+func ParseOrgID(value string) (uint16, error) {
+	if value == "" {
+		return ckdb.DEFAULT_ORG_ID, nil
+	}
+	orgID, err := strconv.ParseUint(value, 10, 16)
+	if err != nil || !ckdb.IsValidOrgID(uint16(orgID)) {
+		return 0, fmt.Errorf("invalid %s (%s)", HEADER_KEY_X_ORG_ID, value)
+	}
+	return uint16(orgID), nil
+}
+
+func ContextWithOrgID(ctx context.Context, orgID uint16) context.Context {
+	return context.WithValue(ctx, orgIDKey{}, orgID)
+}
+
+// GetOrgID returns the org of the query, queries without org belong to the default org.
+func GetOrgID(ctx context.Context) uint16 {
+	if ctx == nil {
+		return ckdb.DEFAULT_ORG_ID
+	}
+	if orgID, ok := ctx.Value(orgIDKey{}).(uint16); ok {
+		return orgID
+	}
+	return ckdb.DEFAULT_ORG_ID
+}
@@ -0,0 +1,52 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package common
+
+import (
+	"context"
+	"testing"
+)
+
+func TestParseOrgID(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    uint16
+		wantErr bool
+	}{
+		{"", 1, false},
+		{"2", 2, false},
+		{"1024", 1024, false},
+		{"0", 0, true},
+		{"1025", 0, true},
+		{"org2", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseOrgID(tt.value)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseOrgID(%q) = %d, %v, want %d, wantErr %v", tt.value, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestGetOrgID(t *testing.T) {
+	if orgID := GetOrgID(context.Background()); orgID != 1 {
+		t.Errorf("GetOrgID() without org = %d, want 1", orgID)
+	}
+	if orgID := GetOrgID(ContextWithOrgID(context.Background(), 3)); orgID != 3 {
+		t.Errorf("GetOrgID() = %d, want 3", orgID)
+	}
+}
@@ -30,6 +30,9 @@ type QuerierParams struct {
 	DataSource string
 	Context    context.Context
 	NoPreWhere bool
+	Explain    bool         // Translate only, return the clickhouse sql without executing
+	NoLimit    bool         // Do not add the default limit
+	Writer     ResultWriter // Stream the result to Writer if not nil
 }
 
 type TempoParams struct {
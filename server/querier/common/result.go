@@ -71,3 +71,10 @@ func (c *ColumnSchemas) ToArray() []interface{} {
 	}
 	return schemas
 }
+
+// ResultWriter receives the query result row by row instead of building a Result in memory
+type ResultWriter interface {
+	WriteHeader(columns []interface{}, schemas ColumnSchemas) error
+	WriteRow(row []interface{}) error
+	Flush() error
+}
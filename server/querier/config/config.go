@@ -26,6 +26,7 @@ import (
 	"github.com/op/go-logging"
 	"gopkg.in/yaml.v2"
 
+	"github.com/deepflowio/deepflow/server/controller/http/auth"
 	prometheus "github.com/deepflowio/deepflow/server/querier/app/prometheus/config"
 	tracing_adapter "github.com/deepflowio/deepflow/server/querier/app/tracing-adapter/config"
 	profile "github.com/deepflowio/deepflow/server/querier/profile/config"
@@ -55,6 +56,7 @@ type QuerierConfig struct {
 	MaxCacheableEntrySize           int                           `default:"1000" yaml:"max-cacheable-entry-size"`
 	MaxPrometheusIdSubqueryLruEntry int                           `default:"8000" yaml:"max-prometheus-id-subquery-lru-entry"`
 	PrometheusIdSubqueryLruTimeout  int                           `default:"60" yaml:"prometheus-id-subquery-lru-timeout"`
+	Auth                            auth.Config                   `yaml:"auth"`
 }
 
 type DeepflowApp struct {
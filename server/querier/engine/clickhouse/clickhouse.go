@@ -20,6 +20,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
@@ -28,6 +29,7 @@ import (
 	"github.com/xwb1989/sqlparser"
 	"golang.org/x/exp/slices"
 
+	"github.com/deepflowio/deepflow/server/libs/ckdb"
 	"github.com/deepflowio/deepflow/server/querier/common"
 	"github.com/deepflowio/deepflow/server/querier/config"
 	"github.com/deepflowio/deepflow/server/querier/engine/clickhouse/client"
@@ -60,6 +62,7 @@ type CHEngine struct {
 	Context            context.Context
 	TargetLabelFilters []TargetLabelFilter
 	NoPreWhere         bool
+	NoLimit            bool
 }
 
 func (e *CHEngine) ExecuteQuery(args *common.QuerierParams) (*common.Result, map[string]interface{}, error) {
@@ -70,8 +73,17 @@ func (e *CHEngine) ExecuteQuery(args *common.QuerierParams) (*common.Result, map
 	sql := args.Sql
 	e.Context = args.Context
 	e.NoPreWhere = args.NoPreWhere
+	e.NoLimit = args.NoLimit
 	query_uuid := args.QueryUUID // FIXME: should be queryUUID
 	log.Debugf("query_uuid: %s | raw sql: %s", query_uuid, sql)
+	// EXPLAIN <sql> is the same as explain=true
+	if explainSql, ok := TrimExplain(sql); ok {
+		sql = explainSql
+		args.Explain = true
+	}
+	if args.Explain && (strings.Contains(sql, "SLIMIT") || strings.Contains(sql, "slimit")) {
+		return nil, nil, errors.New("explain is not supported for slimit sql")
+	}
 	// Parse slimitSql
 	slimitResult, slimitDebug, err := e.ParseSlimitSql(sql, args)
 	if err != nil {
@@ -83,6 +95,9 @@ func (e *CHEngine) ExecuteQuery(args *common.QuerierParams) (*common.Result, map
 	// Parse showSql
 	result, sqlList, isShow, err := e.ParseShowSql(sql)
 	if isShow {
+		if args.Explain {
+			return nil, nil, errors.New("explain is not supported for show sql")
+		}
 		if err != nil {
 			return nil, nil, err
 		}
@@ -145,6 +160,22 @@ func (e *CHEngine) ExecuteQuery(args *common.QuerierParams) (*common.Result, map
 		}
 		return results, debug.Get(), nil
 	}
+	// Parse union all and derived table sql
+	compositeResult, compositeDebug, err := e.ParseCompositeSql(sql, args)
+	if err != nil {
+		return nil, compositeDebug, err
+	}
+	if compositeResult != nil {
+		return compositeResult, compositeDebug, nil
+	}
+	// Parse TimeShift and TimeShiftRatio sql
+	timeShiftResult, timeShiftDebug, err := e.ParseTimeShiftSql(sql, args)
+	if err != nil {
+		return nil, timeShiftDebug, err
+	}
+	if timeShiftResult != nil {
+		return timeShiftResult, timeShiftDebug, nil
+	}
 	err = parser.ParseSQL(sql)
 	if err != nil {
 		log.Error(err)
@@ -153,13 +184,21 @@ func (e *CHEngine) ExecuteQuery(args *common.QuerierParams) (*common.Result, map
 	for _, stmt := range e.Statements {
 		stmt.Format(e.Model)
 	}
-	FormatModel(e.Model)
+	hasLimit := e.Model.Limit.Limit != ""
+	if e.NoLimit {
+		FormatInnerTime(e.Model)
+	} else {
+		FormatModel(e.Model)
+	}
 	// 使用Model生成View
 	e.View = view.NewView(e.Model)
 	e.View.NoPreWhere = e.NoPreWhere
 	chSql := e.ToSQLString()
 	callbacks := e.View.GetCallbacks()
 	debug.Sql = chSql
+	if args.Explain {
+		return NewExplainResult(e.GetExplain(chSql, hasLimit)), debug.Get(), nil
+	}
 	chClient := client.Client{
 		Host:     config.Cfg.Clickhouse.Host,
 		Port:     config.Cfg.Clickhouse.Port,
@@ -179,6 +218,10 @@ func (e *CHEngine) ExecuteQuery(args *common.QuerierParams) (*common.Result, map
 		QueryUUID:       query_uuid,
 		ColumnSchemaMap: ColumnSchemaMap,
 	}
+	if args.Writer != nil {
+		err = chClient.DoQueryStream(params, args.Writer)
+		return nil, debug.Get(), err
+	}
 	rst, err := chClient.DoQuery(params)
 	if err != nil {
 		return nil, debug.Get(), err
@@ -223,7 +266,7 @@ func (e *CHEngine) ParseShowSql(sql string) (*common.Result, []string, bool, err
 			return nil, []string{}, true, errors.New(fmt.Sprintf("parse show sql error, sql: '%s' not support", sql))
 		}
 		if strings.ToLower(sqlSplit[3]) == "values" {
-			result, sqlList, err := tagdescription.GetTagValues(e.DB, table, sql)
+			result, sqlList, err := tagdescription.GetTagValues(e.DB, table, sql, e.Context)
 			return result, sqlList, true, err
 		}
 		return nil, []string{}, true, errors.New(fmt.Sprintf("parse show sql error, sql: '%s' not support", sql))
@@ -616,7 +659,45 @@ func (e *CHEngine) TransSelect(tags sqlparser.SelectExprs) error {
 			}
 		}
 	}
-	return nil
+	return e.checkWindowFunctions(tags)
+}
+
+// 窗口算子按time()的时间桶排序，参数须为聚合后的指标量
+func (e *CHEngine) checkWindowFunctions(tags sqlparser.SelectExprs) error {
+	return sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		function, ok := node.(*sqlparser.FuncExpr)
+		if !ok {
+			return true, nil
+		}
+		name := strings.Trim(sqlparser.String(function.Name), "`")
+		if !common.IsValueInSliceString(name, view.WINDOW_FUNCTIONS) {
+			return true, nil
+		}
+		if e.Model.Time.Alias == "" || e.Model.Time.Interval <= 0 {
+			return false, fmt.Errorf("function %s requires time(time, interval) in select", name)
+		}
+		argCount := 1
+		if name == view.FUNCTION_MOVING_AVG {
+			argCount = 2
+		}
+		if len(function.Exprs) != argCount {
+			return false, fmt.Errorf("function %s requires %d arguments", name, argCount)
+		}
+		arg, ok := function.Exprs[0].(*sqlparser.AliasedExpr)
+		if !ok {
+			return false, fmt.Errorf("function %s not support argument %s", name, sqlparser.String(function.Exprs[0]))
+		}
+		if _, ok := arg.Expr.(*sqlparser.ColName); ok {
+			return false, fmt.Errorf("function %s requires an aggregated metric, such as %s(Sum(%s))", name, name, sqlparser.String(arg.Expr))
+		}
+		if name == view.FUNCTION_MOVING_AVG {
+			rows, err := strconv.Atoi(strings.Trim(sqlparser.String(function.Exprs[1]), "'"))
+			if err != nil || rows < 1 {
+				return false, fmt.Errorf("function %s requires a positive window size, got %s", name, sqlparser.String(function.Exprs[1]))
+			}
+		}
+		return true, nil
+	}, tags)
 }
 
 func (e *CHEngine) TransPrometheusTargetIDFilter(expr view.Node) (view.Node, error) {
@@ -663,6 +744,7 @@ func (e *CHEngine) TransPrometheusTargetIDFilter(expr view.Node) (view.Node, err
 			UserName: config.Cfg.Clickhouse.User,
 			Password: config.Cfg.Clickhouse.Password,
 			DB:       "flow_tag",
+			Context:  e.Context,
 		}
 		targetLabelRst, err := chClient.DoQuery(&client.QueryParams{Sql: sql})
 		if err != nil {
@@ -738,6 +820,10 @@ func (e *CHEngine) TransFrom(froms sqlparser.TableExprs) error {
 	for _, from := range froms {
 		switch from := from.(type) {
 		case *sqlparser.AliasedTableExpr:
+			// 派生表由ParseCompositeSql处理，此处仅支持单表
+			if _, ok := from.Expr.(*sqlparser.Subquery); ok {
+				return errors.New("only one level of derived table is supported")
+			}
 			// 解析Table类型
 			table := strings.Trim(sqlparser.String(from), "`")
 			e.Table = table
@@ -755,8 +841,9 @@ func (e *CHEngine) TransFrom(froms sqlparser.TableExprs) error {
 				e.Statements = append(e.Statements, &whereStmt)
 				table = "samples"
 			}
+			database := ckdb.OrgDatabase(common.GetOrgID(e.Context), e.DB)
 			if e.DataSource != "" {
-				e.AddTable(fmt.Sprintf("%s.`%s.%s`", e.DB, table, e.DataSource))
+				e.AddTable(fmt.Sprintf("%s.`%s.%s`", database, table, e.DataSource))
 				interval, err := chCommon.GetDatasourceInterval(e.DB, e.Table, e.DataSource)
 				if err != nil {
 					log.Error(err)
@@ -766,9 +853,9 @@ func (e *CHEngine) TransFrom(froms sqlparser.TableExprs) error {
 			} else if e.DB == "deepflow_system" {
 				// when DB is deepflow_system, DatasourceInterval is set to 10
 				e.Model.Time.DatasourceInterval = chCommon.DB_DEEPFLOW_SYSTEM_INTERVAL
-				e.AddTable(fmt.Sprintf("%s.`%s`", e.DB, table))
+				e.AddTable(fmt.Sprintf("%s.`%s`", database, table))
 			} else {
-				e.AddTable(fmt.Sprintf("%s.`%s`", e.DB, table))
+				e.AddTable(fmt.Sprintf("%s.`%s`", database, table))
 			}
 			virtualTableFilter, ok := GetVirtualTableFilter(e.DB, e.Table)
 			if ok {
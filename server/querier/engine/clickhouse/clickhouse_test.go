@@ -18,15 +18,19 @@ package clickhouse
 
 import (
 	"context"
+	"reflect"
 	"strconv"
 
 	//"github.com/k0kubun/pp"
 	"github.com/deepflowio/deepflow/server/querier/common"
+	"github.com/deepflowio/deepflow/server/querier/engine/clickhouse/view"
 	"github.com/deepflowio/deepflow/server/querier/parse"
 
 	//"github.com/deepflowio/deepflow/server/querier/querier"
 	"testing"
 
+	"github.com/xwb1989/sqlparser"
+
 	"github.com/deepflowio/deepflow/server/querier/config"
 )
 
@@ -270,7 +274,84 @@ var (
 		index:  "count_3",
 		input:  "select Avg(`byte_tx`) AS `Avg(byte_tx)`,icon_id(chost_0) as `xx`, Count(row) as `c`, region_0 from vtap_flow_edge_port group by region_0 having `c` > 0 limit 1",
 		output: "SELECT `xx`, region_0, AVG(`_sum_byte_tx`) AS `Avg(byte_tx)`, SUM(`_count_1`) AS `c` FROM (WITH if(l3_device_type_0=1, dictGet(flow_tag.device_map, 'icon_id', (toUInt64(1),toUInt64(l3_device_id_0))), 0) AS `xx` SELECT `xx`, dictGet(flow_tag.region_map, 'name', (toUInt64(region_id_0))) AS `region_0`, SUM(byte_tx) AS `_sum_byte_tx`, COUNT(1) AS `_count_1` FROM flow_metrics.`vtap_flow_edge_port` WHERE (region_id_0!=0) GROUP BY `xx`, dictGet(flow_tag.region_map, 'name', (toUInt64(region_id_0))) AS `region_0`) GROUP BY `xx`, `region_0` HAVING c > 0 LIMIT 1",
-		db:     "flow_metrics"}}
+		db:     "flow_metrics"}, {
+		index:  "window_delta",
+		input:  "select Delta(Sum(byte)) as d, time(time, 60) as time_60, ip_0 from l4_flow_log where time>=60 and time<=6000 group by time_60, ip_0",
+		output: "WITH toStartOfInterval(time, toIntervalSecond(60)) + toIntervalSecond(arrayJoin([0]) * 60) AS `_time_60` SELECT if(is_ipv4=1, IPv4NumToString(ip4_0), IPv6NumToString(ip6_0)) AS `ip_0`, toUnixTimestamp(`_time_60`) AS `time_60`, (SUM(byte_tx+byte_rx) - lagInFrame(toNullable(SUM(byte_tx+byte_rx)), 1) OVER (PARTITION BY `ip_0` ORDER BY `time_60` ASC ROWS BETWEEN 1 PRECEDING AND CURRENT ROW)) AS `d` FROM flow_log.`l4_flow_log` PREWHERE `time` >= 60 AND `time` <= 6000 GROUP BY `time_60`, if(is_ipv4=1, IPv4NumToString(ip4_0), IPv6NumToString(ip6_0)) AS `ip_0` LIMIT 10000",
+	}, {
+		index:  "window_moving_average",
+		input:  "select MovingAverage(Avg(rtt), 3) as ma, CumulativeSum(Sum(byte)) as cs, time(time, 60) as time_60, region_0 from vtap_flow_port where time>=60 and time<=6000 group by time_60, region_0",
+		output: "WITH toStartOfInterval(time, toIntervalSecond(60)) + toIntervalSecond(arrayJoin([0]) * 60) AS `_time_60` SELECT dictGet(flow_tag.region_map, 'name', (toUInt64(region_id_0))) AS `region_0`, toUnixTimestamp(`_time_60`) AS `time_60`, avg(AVGIf(rtt_sum/rtt_count, rtt_sum/rtt_count != 0)) OVER (PARTITION BY `region_0` ORDER BY `time_60` ASC ROWS BETWEEN 2 PRECEDING AND CURRENT ROW) AS `ma`, sum(SUM(byte)) OVER (PARTITION BY `region_0` ORDER BY `time_60` ASC ROWS BETWEEN UNBOUNDED PRECEDING AND CURRENT ROW) AS `cs` FROM flow_metrics.`vtap_flow_port` WHERE `time` >= 60 AND `time` <= 6000 AND (region_id_0!=0) GROUP BY `time_60`, dictGet(flow_tag.region_map, 'name', (toUInt64(region_id_0))) AS `region_0` LIMIT 10000",
+		db:     "flow_metrics",
+	}}
+	parseCompositeSQL = []struct {
+		index  string
+		input  string
+		output string
+		err    string
+	}{{
+		index:  "union_all",
+		input:  "(select Sum(byte) as b from l4_flow_log) union all (select Sum(byte) as b from l4_flow_log) order by b desc limit 5",
+		output: "SELECT * FROM (SELECT SUM(byte_tx+byte_rx) AS `b` FROM flow_log.`l4_flow_log` LIMIT 10000 UNION ALL SELECT SUM(byte_tx+byte_rx) AS `b` FROM flow_log.`l4_flow_log` LIMIT 10000) ORDER BY b desc LIMIT 5",
+	}, {
+		index:  "derived_table",
+		input:  "select t.b, b+1 as c from (select Sum(byte) as b, ip_0 from l4_flow_log group by ip_0) as t where b > 0 order by c desc limit 5 offset 1",
+		output: "SELECT t.b, b + 1 as c FROM (SELECT if(is_ipv4=1, IPv4NumToString(ip4_0), IPv6NumToString(ip6_0)) AS `ip_0`, SUM(byte_tx+byte_rx) AS `b` FROM flow_log.`l4_flow_log` GROUP BY if(is_ipv4=1, IPv4NumToString(ip4_0), IPv6NumToString(ip6_0)) AS `ip_0` LIMIT 10000) AS `t` WHERE b > 0 ORDER BY c desc LIMIT 1, 5",
+	}, {
+		index:  "derived_table_aggregate",
+		input:  "select Max(b) as m from (select Sum(byte) as b, ip_0 from l4_flow_log group by ip_0) as t",
+		output: "SELECT max(b) as m FROM (SELECT if(is_ipv4=1, IPv4NumToString(ip4_0), IPv6NumToString(ip6_0)) AS `ip_0`, SUM(byte_tx+byte_rx) AS `b` FROM flow_log.`l4_flow_log` GROUP BY if(is_ipv4=1, IPv4NumToString(ip4_0), IPv6NumToString(ip6_0)) AS `ip_0` LIMIT 10000) AS `t` LIMIT 10000",
+	}, {
+		index: "union_distinct",
+		input: "select byte from l4_flow_log union select byte from l4_flow_log",
+		err:   "union is not supported, use union all instead",
+	}, {
+		index: "union_columns",
+		input: "select byte from l4_flow_log union all select byte, packet from l4_flow_log",
+		err:   "each select of union all must have the same number of columns, got 1 and 2",
+	}, {
+		index: "unknown_column",
+		input: "select x from (select byte from l4_flow_log) as t",
+		err:   "x is not a column of the inner query",
+	}, {
+		index: "unknown_function",
+		input: "select url(byte) from (select byte from l4_flow_log) as t",
+		err:   "function url is not supported in the outer query",
+	}, {
+		index: "nested_derived_table",
+		input: "select byte from (select byte from (select byte from l4_flow_log) as a) as b",
+		err:   "only one level of derived table is supported",
+	}}
+	parseTimeShiftSQL = []struct {
+		index   string
+		input   string
+		base    string
+		shifted map[int64]string
+		err     string
+	}{{
+		index: "time_shift",
+		input: "select Avg(`rtt`) as rtt_avg, TimeShift(Avg(`rtt`), '1d') as rtt_1d, TimeShiftRatio(Avg(`rtt`), '1d') as rtt_ratio, time(time, 60) as time_60 from l4_flow_log where time>=1680000000 and time<=1680003600 group by time_60 order by time_60 limit 10",
+		base:  "select Avg(rtt) as rtt_avg, Avg(rtt) as __time_shift_current_1, time(`time`, 60) as time_60 from l4_flow_log where `time` >= 1680000000 and `time` <= 1680003600 group by time_60 order by time_60 asc limit 10",
+		shifted: map[int64]string{
+			86400: "select time(`time`, 60) as time_60, Avg(rtt) as __time_shift_previous_0, Avg(rtt) as __time_shift_previous_1 from l4_flow_log where `time` >= 1679913600 and `time` <= 1679917200 group by time_60",
+		},
+	}, {
+		index: "time_shift_between",
+		input: "select Sum(byte) as s, TimeShift(Sum(byte), '1h') as s_1h, TimeShift(Sum(byte), '1w') as s_1w, ip_0 from l4_flow_log where ip_0='1.1.1.1' and time between 1680000000 and 1680003600 group by ip_0",
+		base:  "select Sum(byte) as s, ip_0 from l4_flow_log where ip_0 = '1.1.1.1' and `time` between 1680000000 and 1680003600 group by ip_0",
+		shifted: map[int64]string{
+			3600:   "select ip_0, Sum(byte) as __time_shift_previous_0 from l4_flow_log where ip_0 = '1.1.1.1' and `time` between 1679996400 and 1680000000 group by ip_0",
+			604800: "select ip_0, Sum(byte) as __time_shift_previous_1 from l4_flow_log where ip_0 = '1.1.1.1' and `time` between 1679395200 and 1679398800 group by ip_0",
+		},
+	}, {
+		index: "time_shift_offset",
+		input: "select TimeShift(Sum(byte), '1x') from l4_flow_log where time>1",
+		err:   "time shift offset '1x' is invalid, unit should be one of s, m, h, d and w",
+	}, {
+		index: "time_shift_without_time",
+		input: "select TimeShift(Sum(byte), '1d') as a from l4_flow_log where ip_0='1.1.1.1'",
+		err:   "time shift requires a time range in where",
+	}}
 )
 
 func TestGetSql(t *testing.T) {
@@ -302,6 +383,70 @@ func TestGetSql(t *testing.T) {
 	}
 }
 
+func TestTransFromOfOrg(t *testing.T) {
+	Load()
+	tests := []struct {
+		db     string
+		input  string
+		output string
+	}{
+		{
+			db:     "flow_log",
+			input:  "select byte from l4_flow_log limit 1",
+			output: "SELECT byte_tx+byte_rx AS `byte` FROM org0002_flow_log.`l4_flow_log` LIMIT 1",
+		},
+		{
+			db:     "deepflow_system",
+			input:  "select Sum(value) as sum_value from deepflow_system limit 1",
+			output: "SELECT SUM(value) AS `sum_value` FROM org0002_deepflow_system.`deepflow_system` LIMIT 1",
+		},
+	}
+	for _, tt := range tests {
+		e := CHEngine{DB: tt.db}
+		e.Context = common.ContextWithOrgID(context.Background(), 2)
+		e.Init()
+		parser := parse.Parser{Engine: &e}
+		if err := parser.ParseSQL(tt.input); err != nil {
+			t.Errorf("Parse %q failed: %v", tt.input, err)
+			continue
+		}
+		if out := parser.Engine.ToSQLString(); out != tt.output {
+			t.Errorf("\nParse %q\n get: \n\t%q \n want: \n\t%q", tt.input, out, tt.output)
+		}
+	}
+}
+
+func TestGetCompositeSql(t *testing.T) {
+	Load()
+	for i, pcase := range parseCompositeSQL {
+		caseIndex := pcase.index
+		if pcase.index == "" {
+			caseIndex = strconv.Itoa(i)
+		}
+		stmt, err := sqlparser.Parse(pcase.input)
+		if err != nil || !IsCompositeStmt(stmt) {
+			t.Errorf("\nParse [%s]\n\t%q is not a composite sql, error %v", caseIndex, pcase.input, err)
+			continue
+		}
+		e := CHEngine{DB: "flow_log"}
+		e.Context = context.Background()
+		e.Init()
+		out, err := e.TransCompositeSql(stmt.(sqlparser.SelectStatement))
+		if pcase.err != "" {
+			if err == nil || err.Error() != pcase.err {
+				t.Errorf("\nParse [%s]\n\t%q \n get error: \n\t%v \n want: \n\t%s", caseIndex, pcase.input, err, pcase.err)
+			}
+			continue
+		}
+		if out != pcase.output {
+			t.Errorf("\nParse [%s]\n\t%q \n get: \n\t%q \n want: \n\t%q", caseIndex, pcase.input, out, pcase.output)
+			if err != nil {
+				t.Errorf("\nerror %v", err)
+			}
+		}
+	}
+}
+
 /* func TestGetSqltest(t *testing.T) {
 	for _, pcase := range parsetest {
 		e := CHEngine{DB: "flow_log"}
@@ -327,3 +472,101 @@ func Load() error {
 	}
 	return nil
 }
+
+func TestTransTimeShiftSql(t *testing.T) {
+	for i, pcase := range parseTimeShiftSQL {
+		caseIndex := pcase.index
+		if pcase.index == "" {
+			caseIndex = strconv.Itoa(i)
+		}
+		query, err := TransTimeShiftSql(pcase.input)
+		if pcase.err != "" {
+			if err == nil || err.Error() != pcase.err {
+				t.Errorf("\nParse [%s]\n\t%q \n get error: \n\t%v \n want: \n\t%s", caseIndex, pcase.input, err, pcase.err)
+			}
+			continue
+		}
+		if err != nil || query == nil {
+			t.Errorf("\nParse [%s]\n\t%q \n get error: \n\t%v", caseIndex, pcase.input, err)
+			continue
+		}
+		if query.BaseSql != pcase.base {
+			t.Errorf("\nParse [%s]\n\t%q \n get: \n\t%q \n want: \n\t%q", caseIndex, pcase.input, query.BaseSql, pcase.base)
+		}
+		if !reflect.DeepEqual(query.ShiftedSqls, pcase.shifted) {
+			t.Errorf("\nParse [%s]\n\t%q \n get: \n\t%q \n want: \n\t%q", caseIndex, pcase.input, query.ShiftedSqls, pcase.shifted)
+		}
+	}
+}
+
+func TestTimeShiftMerge(t *testing.T) {
+	query, err := TransTimeShiftSql("select Sum(byte) as s, TimeShift(Sum(byte), '1m') as s_1m, TimeShiftRatio(Sum(byte), '1m') as s_ratio, time(time, 60) as t from l4_flow_log where time>=120 and time<240 group by t")
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := &common.Result{
+		Columns: []interface{}{"s", "__time_shift_current_1", "t"},
+		Values:  []interface{}{[]interface{}{20, 20, 120}, []interface{}{30, 30, 180}},
+		Schemas: common.ColumnSchemas{&common.ColumnSchema{Name: "s"}, &common.ColumnSchema{Name: "__time_shift_current_1"}, &common.ColumnSchema{Name: "t"}},
+	}
+	shifted := map[int64]*common.Result{60: {
+		Columns: []interface{}{"t", "__time_shift_previous_0", "__time_shift_previous_1"},
+		Values:  []interface{}{[]interface{}{60, 10, 10}, []interface{}{120, 0, 0}},
+	}}
+	if err := query.Merge(result, shifted); err != nil {
+		t.Fatal(err)
+	}
+	want := []interface{}{[]interface{}{20, 10, float64(1), 120}, []interface{}{30, 0, nil, 180}}
+	if !reflect.DeepEqual(result.Columns, []interface{}{"s", "s_1m", "s_ratio", "t"}) || !reflect.DeepEqual(result.Values, want) {
+		t.Errorf("Merge() = %v %v, want %v", result.Columns, result.Values, want)
+	}
+	if len(result.Schemas) != 4 || result.Schemas[2].Type != common.COLUMN_SCHEMA_TYPE_METRICS || result.Schemas[3].Name != "t" {
+		t.Errorf("Merge() schemas = %v", result.Schemas)
+	}
+}
+
+func TestTrimExplain(t *testing.T) {
+	tests := []struct {
+		input   string
+		sql     string
+		explain bool
+	}{
+		{"EXPLAIN select byte from l4_flow_log", "select byte from l4_flow_log", true},
+		{" explain\n select byte from l4_flow_log", "select byte from l4_flow_log", true},
+		{"select explain from l4_flow_log", "select explain from l4_flow_log", false},
+		{"explainselect", "explainselect", false},
+	}
+	for _, tt := range tests {
+		sql, explain := TrimExplain(tt.input)
+		if sql != tt.sql || explain != tt.explain {
+			t.Errorf("TrimExplain(%q) = %q %t, want %q %t", tt.input, sql, explain, tt.sql, tt.explain)
+		}
+	}
+}
+
+func TestGetExplain(t *testing.T) {
+	Load()
+	e := CHEngine{DB: "flow_log"}
+	e.Context = context.Background()
+	e.Init()
+	parser := parse.Parser{Engine: &e}
+	if err := parser.ParseSQL("select Sum(byte) as s, region_0, time(time, 60) as time_60 from l4_flow_log where time>=60 and time<=120 group by region_0, time_60"); err != nil {
+		t.Fatal(err)
+	}
+	for _, stmt := range e.Statements {
+		stmt.Format(e.Model)
+	}
+	hasLimit := e.Model.Limit.Limit != ""
+	FormatModel(e.Model)
+	e.View = view.NewView(e.Model)
+	explain := e.GetExplain(e.ToSQLString(), hasLimit)
+	if explain.Table != "flow_log.`l4_flow_log`" || explain.DatasourceInterval != 1 || !explain.PreWhere {
+		t.Errorf("GetExplain() table %s, datasource interval %d, prewhere %t", explain.Table, explain.DatasourceInterval, explain.PreWhere)
+	}
+	if !reflect.DeepEqual(explain.Dictionaries, []string{"flow_tag.region_map"}) {
+		t.Errorf("GetExplain() dictionaries = %v", explain.Dictionaries)
+	}
+	if !reflect.DeepEqual(explain.Callbacks, []string{}) || len(explain.Warnings) != 1 {
+		t.Errorf("GetExplain() callbacks = %v, warnings = %v, want the limit warning only", explain.Callbacks, explain.Warnings)
+	}
+}
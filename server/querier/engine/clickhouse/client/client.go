@@ -22,12 +22,14 @@ import (
 
 	//"database/sql"
 	"fmt"
+	"regexp"
 	"time"
 	"unsafe"
 
 	clickhouse "github.com/ClickHouse/clickhouse-go/v2"
 	//"github.com/k0kubun/pp"
 
+	"github.com/deepflowio/deepflow/server/libs/ckdb"
 	"github.com/deepflowio/deepflow/server/querier/common"
 	"github.com/deepflowio/deepflow/server/querier/config"
 	"github.com/deepflowio/deepflow/server/querier/statsd"
@@ -37,6 +39,19 @@ import (
 
 var log = logging.MustGetLogger("clickhouse.client")
 
+// Matches the references of flow_tag database, such as dictGet('flow_tag.ip_resource_map', ...)
+// or flow_tag.pod_map, but not xxx_flow_tag. or `.flow_tag.`
+var flowTagDatabaseRegexp = regexp.MustCompile("(^|[^\\w.`])flow_tag\\.")
+
+// OrgSQL rewrites the references of flow_tag database to the flow_tag database of the org in the context
+func OrgSQL(ctx context.Context, sql string) string {
+	orgID := common.GetOrgID(ctx)
+	if ckdb.IsDefaultOrgID(orgID) {
+		return sql
+	}
+	return flowTagDatabaseRegexp.ReplaceAllString(sql, "${1}"+ckdb.OrgDatabase(orgID, "flow_tag")+".")
+}
+
 type QueryParams struct {
 	Sql             string
 	Callbacks       map[string]func(result *common.Result) error
@@ -115,6 +130,7 @@ func (c *Client) DoQuery(params *QueryParams) (result *common.Result, err error)
 	if c.Context == nil {
 		ctx = context.Background()
 	}
+	sqlstr = OrgSQL(ctx, sqlstr)
 	rows, err := c.connection.Query(ctx, sqlstr)
 	c.Debug.Sql = sqlstr
 	if err != nil {
@@ -0,0 +1,46 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/deepflowio/deepflow/server/querier/common"
+)
+
+func TestOrgSQL(t *testing.T) {
+	sql := "SELECT dictGet('flow_tag.ip_resource_map', 'name', (toUInt64(0), ip4)) AS `name` FROM flow_log.`l4_flow_log` " +
+		"WHERE pod_id IN (SELECT id FROM flow_tag.pod_map) AND `flow_tag.name` != '' AND app_flow_tag.x = 1"
+	orgSQL := "SELECT dictGet('org0002_flow_tag.ip_resource_map', 'name', (toUInt64(0), ip4)) AS `name` FROM flow_log.`l4_flow_log` " +
+		"WHERE pod_id IN (SELECT id FROM org0002_flow_tag.pod_map) AND `flow_tag.name` != '' AND app_flow_tag.x = 1"
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want string
+	}{
+		{"without org", context.Background(), sql},
+		{"default org", common.ContextWithOrgID(context.Background(), 1), sql},
+		{"org 2", common.ContextWithOrgID(context.Background(), 2), orgSQL},
+	}
+	for _, tt := range tests {
+		if got := OrgSQL(tt.ctx, sql); got != tt.want {
+			t.Errorf("%s: OrgSQL() = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
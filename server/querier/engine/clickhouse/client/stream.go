@@ -0,0 +1,145 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/deepflowio/deepflow/server/querier/common"
+	"github.com/deepflowio/deepflow/server/querier/statsd"
+)
+
+// Callbacks that need all rows of the result, such as filling the missing time points
+var STREAM_UNSUPPORTED_CALLBACKS = []string{"time"}
+
+// Value type of a clickhouse column, used before any row is read
+func GetValueType(databaseTypeName string) string {
+	typeName := strings.TrimSuffix(strings.TrimPrefix(databaseTypeName, "Nullable("), ")")
+	typeName = strings.TrimSuffix(strings.TrimPrefix(typeName, "LowCardinality("), ")")
+	switch {
+	case strings.HasPrefix(typeName, "Int"), strings.HasPrefix(typeName, "UInt"):
+		return VALUE_TYPE_INT
+	case strings.HasPrefix(typeName, "Float"):
+		return VALUE_TYPE_FLOAT64
+	case strings.HasPrefix(typeName, "Tuple"):
+		return VALUE_TYPE_TUPLE
+	}
+	return VALUE_TYPE_STRING
+}
+
+// DoQueryStream writes each row to writer as soon as it is read from clickhouse, callbacks
+// are applied to every single row except those in STREAM_UNSUPPORTED_CALLBACKS
+func (c *Client) DoQueryStream(params *QueryParams, writer common.ResultWriter) error {
+	sqlstr, callbacks, query_uuid, columnSchemaMap := params.Sql, params.Callbacks, params.QueryUUID, params.ColumnSchemaMap
+	err := c.init(query_uuid)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	start := time.Now()
+	ctx := c.Context
+	if c.Context == nil {
+		ctx = context.Background()
+	}
+	sqlstr = OrgSQL(ctx, sqlstr)
+	rows, err := c.connection.Query(ctx, sqlstr)
+	c.Debug.Sql = sqlstr
+	if err != nil {
+		log.Errorf("query clickhouse Error: %s, sql: %s, query_uuid: %s", err, sqlstr, c.Debug.QueryUUID)
+		c.Debug.Error = fmt.Sprintf("%s", err)
+		return err
+	}
+	defer rows.Close()
+	columns := rows.ColumnTypes()
+	columnNames := make([]interface{}, 0, len(columns))
+	columnSchemas := make(common.ColumnSchemas, 0, len(columns))
+	for _, column := range columns {
+		columnNames = append(columnNames, column.Name())
+		schema, ok := columnSchemaMap[column.Name()]
+		if !ok {
+			schema = common.NewColumnSchema(column.Name(), "", "")
+		}
+		schema.ValueType = GetValueType(column.DatabaseTypeName())
+		columnSchemas = append(columnSchemas, schema)
+	}
+	rowCallbacks := []func(result *common.Result) error{}
+	for name, callback := range callbacks {
+		if !common.IsValueInSliceString(name, STREAM_UNSUPPORTED_CALLBACKS) {
+			rowCallbacks = append(rowCallbacks, callback)
+		}
+	}
+	if err := writer.WriteHeader(columnNames, columnSchemas); err != nil {
+		return err
+	}
+
+	columnValues := make([]interface{}, len(columns))
+	for i := range columns {
+		columnValues[i] = reflect.New(columns[i].ScanType()).Interface()
+	}
+	resRows := 0
+	rowResult := &common.Result{Columns: columnNames, Schemas: columnSchemas}
+	for rows.Next() {
+		if err := rows.Scan(columnValues...); err != nil {
+			c.Debug.Error = fmt.Sprintf("%s", err)
+			return err
+		}
+		record := make([]interface{}, 0, len(columns))
+		for i, rawValue := range columnValues {
+			value, _, err := TransType(rawValue, columns[i].Name(), columns[i].DatabaseTypeName())
+			if err != nil {
+				c.Debug.Error = fmt.Sprintf("%s", err)
+				return err
+			}
+			record = append(record, value)
+		}
+		if len(rowCallbacks) > 0 {
+			rowResult.Values = []interface{}{record}
+			for _, callback := range rowCallbacks {
+				if err := callback(rowResult); err != nil {
+					log.Error("Execute Callback %v Error: %v", callback, err)
+				}
+			}
+			record = rowResult.Values[0].([]interface{})
+		}
+		if err := writer.WriteRow(record); err != nil {
+			c.Debug.Error = fmt.Sprintf("%s", err)
+			return err
+		}
+		resRows++
+	}
+	if err := rows.Err(); err != nil {
+		log.Errorf("query clickhouse Error: %s, sql: %s, query_uuid: %s", err, sqlstr, c.Debug.QueryUUID)
+		c.Debug.Error = fmt.Sprintf("%s", err)
+		return err
+	}
+	queryTime := time.Since(start)
+	statsd.QuerierCounter.WriteCk(
+		&statsd.ClickhouseCounter{
+			RowCount:    uint64(resRows),
+			ColumnCount: uint64(len(columns)),
+			QueryTime:   uint64(queryTime),
+		},
+	)
+	c.Debug.QueryTime = int64(queryTime)
+	log.Infof("query_uuid: %s. query stream statistics: %d rows, %d columns, cost %f ms", c.Debug.QueryUUID, resRows, len(columns), float64(queryTime.Milliseconds()))
+	return nil
+}
@@ -27,6 +27,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/deepflowio/deepflow/server/controller/http/auth"
 	"github.com/deepflowio/deepflow/server/querier/config"
 	"github.com/deepflowio/deepflow/server/querier/engine/clickhouse/client"
 	logging "github.com/op/go-logging"
@@ -88,6 +89,19 @@ func ParseResponse(response *http.Response) (map[string]interface{}, error) {
 	return result, err
 }
 
+// newControllerRequest returns a request to the controller, which is authenticated by the
+// internal token when the authentication of the controller is enabled
+func newControllerRequest(url string) (*http.Request, error) {
+	request, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if config.Cfg != nil && config.Cfg.Auth.InternalToken != "" {
+		request.Header.Set(auth.HEADER_KEY_X_INTERNAL_TOKEN, config.Cfg.Auth.InternalToken)
+	}
+	return request, nil
+}
+
 func GetDatasources(db string, table string) ([]string, error) {
 	var datasources []string
 	switch db {
@@ -100,7 +114,7 @@ func GetDatasources(db string, table string) ([]string, error) {
 		}
 		client := &http.Client{}
 		url := fmt.Sprintf("http://localhost:20417/v1/data-sources/?type=%s", tsdbType)
-		reqest, err := http.NewRequest("GET", url, nil)
+		reqest, err := newControllerRequest(url)
 		if err != nil {
 			return datasources, err
 		}
@@ -146,7 +160,7 @@ func GetDatasourceInterval(db string, table string, name string) (int, error) {
 	}
 	client := &http.Client{}
 	url := fmt.Sprintf("http://localhost:20417/v1/data-sources/?name=%s&type=%s", name, tsdbType)
-	reqest, err := http.NewRequest("GET", url, nil)
+	reqest, err := newControllerRequest(url)
 	if err != nil {
 		return 1, err
 	}
@@ -0,0 +1,338 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package clickhouse
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/xwb1989/sqlparser"
+
+	"github.com/deepflowio/deepflow/server/querier/common"
+	"github.com/deepflowio/deepflow/server/querier/config"
+	"github.com/deepflowio/deepflow/server/querier/engine/clickhouse/client"
+	"github.com/deepflowio/deepflow/server/querier/engine/clickhouse/view"
+	"github.com/deepflowio/deepflow/server/querier/parse"
+)
+
+// Functions that can be used by the outer query of a derived table or a union,
+// the outer query operates on the aliases of the inner query and is not translated
+var outerFunctions = map[string]string{
+	"sum":   "sum",
+	"avg":   "avg",
+	"max":   "max",
+	"min":   "min",
+	"count": "count",
+	"uniq":  "uniqExact",
+	"any":   "any",
+	"round": "round",
+	"abs":   "abs",
+	"if":    "if",
+}
+
+// A composite sql is a UNION ALL of selects or a select from one level of derived table
+func IsCompositeStmt(stmt sqlparser.Statement) bool {
+	switch stmt := stmt.(type) {
+	case *sqlparser.Union, *sqlparser.ParenSelect:
+		return true
+	case *sqlparser.Select:
+		_, ok := getDerivedTable(stmt)
+		return ok
+	}
+	return false
+}
+
+func getDerivedTable(stmt *sqlparser.Select) (*sqlparser.AliasedTableExpr, bool) {
+	if len(stmt.From) != 1 {
+		return nil, false
+	}
+	from, ok := stmt.From[0].(*sqlparser.AliasedTableExpr)
+	if !ok {
+		return nil, false
+	}
+	if _, ok := from.Expr.(*sqlparser.Subquery); !ok {
+		return nil, false
+	}
+	return from, true
+}
+
+func (e *CHEngine) ParseCompositeSql(sql string, args *common.QuerierParams) (*common.Result, map[string]interface{}, error) {
+	stmt, err := sqlparser.Parse(sql)
+	if err != nil || !IsCompositeStmt(stmt) {
+		// Not a composite sql, parsing errors are reported by the normal process
+		return nil, nil, nil
+	}
+	chSql, err := e.TransCompositeSql(stmt.(sqlparser.SelectStatement))
+	if err != nil {
+		log.Error(err)
+		return nil, nil, err
+	}
+	debug := &client.Debug{
+		IP:        config.Cfg.Clickhouse.Host,
+		QueryUUID: args.QueryUUID,
+		Sql:       chSql,
+	}
+	if args.Explain {
+		explain := &Explain{
+			Sql:          chSql,
+			DB:           e.DB,
+			DataSource:   e.DataSource,
+			PreWhere:     strings.Contains(chSql, " PREWHERE "),
+			Dictionaries: GetDictionaries(chSql),
+			Callbacks:    []string{},
+			Warnings:     []string{"callbacks are not applied to union all and derived table"},
+		}
+		return NewExplainResult(explain), debug.Get(), nil
+	}
+	chClient := client.Client{
+		Host:     config.Cfg.Clickhouse.Host,
+		Port:     config.Cfg.Clickhouse.Port,
+		UserName: config.Cfg.Clickhouse.User,
+		Password: config.Cfg.Clickhouse.Password,
+		DB:       e.DB,
+		Debug:    debug,
+		Context:  e.Context,
+	}
+	ColumnSchemaMap := make(map[string]*common.ColumnSchema)
+	for _, ColumnSchema := range e.ColumnSchemas {
+		ColumnSchemaMap[ColumnSchema.Name] = ColumnSchema
+	}
+	// Callbacks such as time filling work on the columns of a single select,
+	// the results of composite sql are returned as they are
+	params := &client.QueryParams{
+		Sql:             chSql,
+		QueryUUID:       args.QueryUUID,
+		ColumnSchemaMap: ColumnSchemaMap,
+	}
+	rst, err := chClient.DoQuery(params)
+	if err != nil {
+		log.Error(err)
+		return nil, debug.Get(), err
+	}
+	return rst, debug.Get(), nil
+}
+
+// Translate UNION ALL and derived table to clickhouse sql, tag translation is applied to
+// the innermost selects, and the outer query can only use the columns of the inner query
+func (e *CHEngine) TransCompositeSql(stmt sqlparser.SelectStatement) (string, error) {
+	chSql, columns, err := e.transSelectStatement(stmt, true)
+	if err != nil {
+		return "", err
+	}
+	e.ColumnSchemas = columns
+	return chSql, nil
+}
+
+func (e *CHEngine) transSelectStatement(stmt sqlparser.SelectStatement, allowDerived bool) (string, []*common.ColumnSchema, error) {
+	switch stmt := stmt.(type) {
+	case *sqlparser.ParenSelect:
+		return e.transSelectStatement(stmt.Select, allowDerived)
+	case *sqlparser.Union:
+		return e.transUnion(stmt)
+	case *sqlparser.Select:
+		from, ok := getDerivedTable(stmt)
+		if !ok {
+			return e.transSimpleSelect(stmt)
+		}
+		if !allowDerived {
+			return "", nil, errors.New("only one level of derived table is supported")
+		}
+		return e.transDerivedTable(stmt, from)
+	}
+	return "", nil, fmt.Errorf("sql: '%s' not support", sqlparser.String(stmt))
+}
+
+func (e *CHEngine) transSimpleSelect(stmt *sqlparser.Select) (string, []*common.ColumnSchema, error) {
+	engine := &CHEngine{DB: e.DB, DataSource: e.DataSource, Context: e.Context, NoPreWhere: e.NoPreWhere}
+	engine.Init()
+	parser := parse.Parser{Engine: engine}
+	if err := parser.ParseSQL(sqlparser.String(stmt)); err != nil {
+		return "", nil, err
+	}
+	for _, stmt := range engine.Statements {
+		stmt.Format(engine.Model)
+	}
+	FormatModel(engine.Model)
+	// 使用Model生成View
+	engine.View = view.NewView(engine.Model)
+	engine.View.NoPreWhere = engine.NoPreWhere
+	return engine.ToSQLString(), engine.ColumnSchemas, nil
+}
+
+func (e *CHEngine) transUnion(stmt *sqlparser.Union) (string, []*common.ColumnSchema, error) {
+	if strings.ToLower(stmt.Type) != sqlparser.UnionAllStr {
+		return "", nil, fmt.Errorf("%s is not supported, use union all instead", stmt.Type)
+	}
+	leftSql, leftColumns, err := e.transSelectStatement(stmt.Left, true)
+	if err != nil {
+		return "", nil, err
+	}
+	rightSql, rightColumns, err := e.transSelectStatement(stmt.Right, true)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(leftColumns) != len(rightColumns) {
+		return "", nil, fmt.Errorf("each select of union all must have the same number of columns, got %d and %d", len(leftColumns), len(rightColumns))
+	}
+	chSql := leftSql + " UNION ALL " + rightSql
+	if stmt.OrderBy == nil && stmt.Limit == nil {
+		return chSql, leftColumns, nil
+	}
+
+	// ORDER BY and LIMIT apply to the whole union
+	if err := checkOuterExpr(stmt.OrderBy, getColumnNames(leftColumns), ""); err != nil {
+		return "", nil, err
+	}
+	sqlSlice := []string{"SELECT * FROM (" + chSql + ")"}
+	sqlSlice = append(sqlSlice, formatOuterClauses(nil, nil, nil, stmt.OrderBy, stmt.Limit)...)
+	return strings.Join(sqlSlice, " "), leftColumns, nil
+}
+
+func (e *CHEngine) transDerivedTable(stmt *sqlparser.Select, from *sqlparser.AliasedTableExpr) (string, []*common.ColumnSchema, error) {
+	innerSql, innerColumns, err := e.transSelectStatement(from.Expr.(*sqlparser.Subquery).Select, false)
+	if err != nil {
+		return "", nil, err
+	}
+	columns := getColumnNames(innerColumns)
+	alias := from.As.String()
+	if err := checkOuterExpr(stmt.SelectExprs, columns, alias); err != nil {
+		return "", nil, err
+	}
+	// Aliases of the outer select can also be used by the other clauses
+	for _, expr := range stmt.SelectExprs {
+		if expr, ok := expr.(*sqlparser.AliasedExpr); ok && !expr.As.IsEmpty() {
+			columns[expr.As.String()] = true
+		}
+	}
+	for _, node := range []sqlparser.SQLNode{stmt.Where, stmt.GroupBy, stmt.Having, stmt.OrderBy} {
+		if err := checkOuterExpr(node, columns, alias); err != nil {
+			return "", nil, err
+		}
+	}
+
+	sqlSlice := []string{"SELECT " + sqlparser.String(stmt.SelectExprs), "FROM (" + innerSql + ")"}
+	if alias != "" {
+		sqlSlice = append(sqlSlice, "AS `"+alias+"`")
+	}
+	limit := stmt.Limit
+	if limit == nil {
+		defaultLimit := DEFAULT_LIMIT
+		if config.Cfg != nil {
+			defaultLimit = config.Cfg.Limit
+		}
+		limit = &sqlparser.Limit{Rowcount: sqlparser.NewIntVal([]byte(defaultLimit))}
+	}
+	var where, having sqlparser.Expr
+	if stmt.Where != nil {
+		where = stmt.Where.Expr
+	}
+	if stmt.Having != nil {
+		having = stmt.Having.Expr
+	}
+	sqlSlice = append(sqlSlice, formatOuterClauses(where, stmt.GroupBy, having, stmt.OrderBy, limit)...)
+
+	outerColumns := make([]*common.ColumnSchema, 0, len(stmt.SelectExprs))
+	for _, expr := range stmt.SelectExprs {
+		switch expr := expr.(type) {
+		case *sqlparser.StarExpr:
+			outerColumns = append(outerColumns, innerColumns...)
+		case *sqlparser.AliasedExpr:
+			name := strings.Trim(sqlparser.String(expr.Expr), "`")
+			if !expr.As.IsEmpty() {
+				name = expr.As.String()
+			}
+			outerColumns = append(outerColumns, common.NewColumnSchema(name, "", ""))
+		}
+	}
+	return strings.Join(sqlSlice, " "), outerColumns, nil
+}
+
+func formatOuterClauses(where sqlparser.Expr, groupBy sqlparser.GroupBy, having sqlparser.Expr, orderBy sqlparser.OrderBy, limit *sqlparser.Limit) []string {
+	clauses := []string{}
+	if where != nil {
+		clauses = append(clauses, "WHERE "+sqlparser.String(where))
+	}
+	if len(groupBy) > 0 {
+		groups := make([]string, 0, len(groupBy))
+		for _, group := range groupBy {
+			groups = append(groups, sqlparser.String(group))
+		}
+		clauses = append(clauses, "GROUP BY "+strings.Join(groups, ", "))
+	}
+	if having != nil {
+		clauses = append(clauses, "HAVING "+sqlparser.String(having))
+	}
+	if len(orderBy) > 0 {
+		orders := make([]string, 0, len(orderBy))
+		for _, order := range orderBy {
+			orders = append(orders, sqlparser.String(order))
+		}
+		clauses = append(clauses, "ORDER BY "+strings.Join(orders, ", "))
+	}
+	if limit != nil {
+		if limit.Offset != nil {
+			clauses = append(clauses, fmt.Sprintf("LIMIT %s, %s", sqlparser.String(limit.Offset), sqlparser.String(limit.Rowcount)))
+		} else {
+			clauses = append(clauses, "LIMIT "+sqlparser.String(limit.Rowcount))
+		}
+	}
+	return clauses
+}
+
+func getColumnNames(columns []*common.ColumnSchema) map[string]bool {
+	names := make(map[string]bool, len(columns))
+	for _, column := range columns {
+		names[column.Name] = true
+	}
+	return names
+}
+
+// Outer queries can only reference the columns of the inner query and use outerFunctions,
+// the function names are replaced with the clickhouse ones in place
+func checkOuterExpr(node sqlparser.SQLNode, columns map[string]bool, alias string) error {
+	if node == nil {
+		return nil
+	}
+	return sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		switch node := node.(type) {
+		case *sqlparser.ColName:
+			if !node.Qualifier.IsEmpty() && node.Qualifier.Name.String() != alias {
+				return false, fmt.Errorf("unknown table %s", sqlparser.String(node.Qualifier))
+			}
+			if !columns[node.Name.String()] {
+				return false, fmt.Errorf("%s is not a column of the inner query", node.Name.String())
+			}
+			return false, nil
+		case *sqlparser.FuncExpr:
+			chName, ok := outerFunctions[node.Name.Lowered()]
+			if !ok || !node.Qualifier.IsEmpty() {
+				return false, fmt.Errorf("function %s is not supported in the outer query", sqlparser.String(node.Name))
+			}
+			node.Name = sqlparser.NewColIdent(chName)
+			return true, nil
+		case *sqlparser.AliasedExpr, *sqlparser.StarExpr, *sqlparser.SQLVal, *sqlparser.NullVal, sqlparser.BoolVal,
+			*sqlparser.BinaryExpr, *sqlparser.UnaryExpr, *sqlparser.ParenExpr, *sqlparser.ComparisonExpr,
+			*sqlparser.AndExpr, *sqlparser.OrExpr, *sqlparser.NotExpr, *sqlparser.RangeCond, *sqlparser.IsExpr,
+			*sqlparser.CaseExpr, *sqlparser.When, sqlparser.ValTuple, sqlparser.SelectExprs, sqlparser.Exprs,
+			*sqlparser.Where, sqlparser.GroupBy, sqlparser.OrderBy, *sqlparser.Order,
+			sqlparser.ColIdent, sqlparser.TableIdent, sqlparser.TableName:
+			return true, nil
+		}
+		return false, fmt.Errorf("%s is not supported in the outer query", sqlparser.String(node))
+	}, node)
+}
@@ -0,0 +1,124 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package clickhouse
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/deepflowio/deepflow/server/querier/common"
+	chCommon "github.com/deepflowio/deepflow/server/querier/engine/clickhouse/common"
+)
+
+const EXPLAIN_KEYWORD = "explain"
+
+// Time ranges longer than this on 1s data are reported as a warning
+const EXPLAIN_MAX_1S_TIME_RANGE = 3600
+
+var dictGetRegexp = regexp.MustCompile("dictGet(?:OrDefault)?\\(\\s*([\\w.]+)")
+
+// Explain describes how a DeepFlow SQL is translated without executing it
+type Explain struct {
+	Sql                string
+	DB                 string
+	Table              string
+	DataSource         string
+	DatasourceInterval int
+	PreWhere           bool
+	Dictionaries       []string
+	Callbacks          []string
+	Warnings           []string
+}
+
+// Strip the leading EXPLAIN keyword, returns whether the sql is an explain sql
+func TrimExplain(sql string) (string, bool) {
+	trimmed := strings.TrimSpace(sql)
+	if len(trimmed) <= len(EXPLAIN_KEYWORD) || !strings.EqualFold(trimmed[:len(EXPLAIN_KEYWORD)], EXPLAIN_KEYWORD) {
+		return sql, false
+	}
+	rest := trimmed[len(EXPLAIN_KEYWORD):]
+	if rest[0] != ' ' && rest[0] != '\n' && rest[0] != '\t' {
+		return sql, false
+	}
+	return strings.TrimSpace(rest), true
+}
+
+// Tag dictionaries joined by dictGet in the clickhouse sql
+func GetDictionaries(chSql string) []string {
+	dictionaries := []string{}
+	for _, match := range dictGetRegexp.FindAllStringSubmatch(chSql, -1) {
+		if !common.IsValueInSliceString(match[1], dictionaries) {
+			dictionaries = append(dictionaries, match[1])
+		}
+	}
+	sort.Strings(dictionaries)
+	return dictionaries
+}
+
+// Build the explain of a translated single select, should be called after ToSQLString
+func (e *CHEngine) GetExplain(chSql string, hasLimit bool) *Explain {
+	explain := &Explain{
+		Sql:                chSql,
+		DB:                 e.DB,
+		Table:              e.Model.From.ToString(),
+		DataSource:         e.DataSource,
+		DatasourceInterval: e.Model.Time.DatasourceInterval,
+		PreWhere:           e.View.UsePreWhere(),
+		Dictionaries:       GetDictionaries(chSql),
+		Callbacks:          []string{},
+		Warnings:           []string{},
+	}
+	for name := range e.View.GetCallbacks() {
+		explain.Callbacks = append(explain.Callbacks, name)
+	}
+	sort.Strings(explain.Callbacks)
+
+	if e.DB != chCommon.DB_NAME_FLOW_TAG && e.Model.Time.TimeStart <= 0 {
+		explain.Warnings = append(explain.Warnings, "no time range in where, all data of the table will be scanned")
+	}
+	if e.Model.Time.DatasourceInterval == 1 && e.Model.Time.TimeStart > 0 && e.Model.Time.TimeEnd-e.Model.Time.TimeStart > EXPLAIN_MAX_1S_TIME_RANGE && strings.HasSuffix(explain.Table, ".1s`") {
+		explain.Warnings = append(explain.Warnings, fmt.Sprintf(
+			"time range %ds is queried on 1s data, consider a coarser data_precision", e.Model.Time.TimeEnd-e.Model.Time.TimeStart,
+		))
+	}
+	if !hasLimit {
+		explain.Warnings = append(explain.Warnings, fmt.Sprintf("no limit in sql, default limit %s is used", e.Model.Limit.Limit))
+	}
+	if e.NoPreWhere {
+		explain.Warnings = append(explain.Warnings, "prewhere is disabled by no_prewhere")
+	}
+	return explain
+}
+
+func NewExplainResult(explains ...*Explain) *common.Result {
+	columns := []interface{}{
+		"sql", "db", "table", "data_source", "datasource_interval", "prewhere", "dictionaries", "callbacks", "warnings",
+	}
+	values := []interface{}{}
+	for _, explain := range explains {
+		values = append(values, []interface{}{
+			explain.Sql, explain.DB, explain.Table, explain.DataSource, explain.DatasourceInterval,
+			explain.PreWhere, explain.Dictionaries, explain.Callbacks, explain.Warnings,
+		})
+	}
+	return &common.Result{
+		Columns: columns,
+		Values:  values,
+	}
+}
@@ -875,6 +875,7 @@ func GetRemoteReadFilter(promTag, table, op, value, originFilter string, e *CHEn
 					UserName: config.Cfg.Clickhouse.User,
 					Password: config.Cfg.Clickhouse.Password,
 					DB:       "flow_tag",
+					Context:  e.Context,
 				}
 				appLabelRst, err := chClient.DoQuery(&client.QueryParams{Sql: sql})
 				if err != nil {
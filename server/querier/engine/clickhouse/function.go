@@ -178,6 +178,9 @@ func (f *BinaryFunction) Trans(m *view.Model) view.Node {
 	function.SetFields(fields)
 	function.SetFlag(view.METRICS_FLAG_OUTER)
 	function.SetTime(m.Time)
+	if window, ok := function.(*view.WindowFunction); ok {
+		window.Groups = m.Groups
+	}
 	function.Init()
 	return function
 }
@@ -45,6 +45,8 @@ var METRICS_FUNCTIONS = []string{
 	view.FUNCTION_RSPREAD, view.FUNCTION_STDDEV, view.FUNCTION_APDEX,
 	view.FUNCTION_UNIQ, view.FUNCTION_UNIQ_EXACT, view.FUNCTION_PERCENTAG,
 	view.FUNCTION_PERSECOND, view.FUNCTION_HISTOGRAM, view.FUNCTION_LAST, view.FUNCTION_COUNT,
+	view.FUNCTION_DELTA, view.FUNCTION_RATE, view.FUNCTION_DERIVATIVE, view.FUNCTION_MOVING_AVG,
+	view.FUNCTION_CUMSUM,
 }
 
 var METRICS_FUNCTIONS_MAP = map[string]*Function{
@@ -65,6 +67,11 @@ var METRICS_FUNCTIONS_MAP = map[string]*Function{
 	view.FUNCTION_PERSECOND:  NewFunction(view.FUNCTION_PERSECOND, FUNCTION_TYPE_MATH, nil, "$unit/s", 0),
 	view.FUNCTION_HISTOGRAM:  NewFunction(view.FUNCTION_HISTOGRAM, FUNCTION_TYPE_MATH, nil, "", 1),
 	view.FUNCTION_LAST:       NewFunction(view.FUNCTION_LAST, FUNCTION_TYPE_AGG, []int{METRICS_TYPE_COUNTER, METRICS_TYPE_GAUGE, METRICS_TYPE_DELAY, METRICS_TYPE_PERCENTAGE, METRICS_TYPE_QUOTIENT}, "", 0),
+	view.FUNCTION_DELTA:      NewFunction(view.FUNCTION_DELTA, FUNCTION_TYPE_WINDOW, nil, "$unit", 0),
+	view.FUNCTION_RATE:       NewFunction(view.FUNCTION_RATE, FUNCTION_TYPE_WINDOW, nil, "$unit/s", 0),
+	view.FUNCTION_DERIVATIVE: NewFunction(view.FUNCTION_DERIVATIVE, FUNCTION_TYPE_WINDOW, nil, "$unit/s", 0),
+	view.FUNCTION_MOVING_AVG: NewFunction(view.FUNCTION_MOVING_AVG, FUNCTION_TYPE_WINDOW, nil, "$unit", 1),
+	view.FUNCTION_CUMSUM:     NewFunction(view.FUNCTION_CUMSUM, FUNCTION_TYPE_WINDOW, nil, "$unit", 0),
 }
 
 func GetFunctionDescriptions() (*common.Result, error) {
@@ -507,7 +507,7 @@ func GetEnumTagValues(db, table, sql string) (map[string][]interface{}, error) {
 	return response, nil
 }
 
-func GetTagValues(db, table, sql string) (*common.Result, []string, error) {
+func GetTagValues(db, table, sql string, ctx context.Context) (*common.Result, []string, error) {
 	var sqlList []string
 	// 把`1m`的反引号去掉
 	table = strings.Trim(table, "`")
@@ -542,7 +542,7 @@ func GetTagValues(db, table, sql string) (*common.Result, []string, error) {
 	}
 	// K8s Labels是动态的,不需要去tag_description里确认
 	if strings.HasPrefix(tag, "k8s.label.") || strings.HasPrefix(tag, "k8s.annotation.") || strings.HasPrefix(tag, "k8s.env.") || strings.HasPrefix(tag, "cloud.tag.") || strings.HasPrefix(tag, "os.app.") {
-		return GetTagResourceValues(db, table, sql)
+		return GetTagResourceValues(db, table, sql, ctx)
 	}
 	// 外部字段是动态的,不需要去tag_description里确认
 	if strings.HasPrefix(tag, "tag.") || strings.HasPrefix(tag, "attribute.") {
@@ -564,7 +564,7 @@ func GetTagValues(db, table, sql string) (*common.Result, []string, error) {
 	// 根据tagEnumFile获取values
 	_, isEnumOK := TAG_ENUMS[tagDescription.EnumFile]
 	if !isEnumOK {
-		return GetTagResourceValues(db, table, sql)
+		return GetTagResourceValues(db, table, sql, ctx)
 	}
 
 	_, isStringEnumOK := TAG_STRING_ENUMS[tagDescription.EnumFile]
@@ -614,13 +614,14 @@ func GetTagValues(db, table, sql string) (*common.Result, []string, error) {
 
 }
 
-func GetTagResourceValues(db, table, rawSql string) (*common.Result, []string, error) {
+func GetTagResourceValues(db, table, rawSql string, ctx context.Context) (*common.Result, []string, error) {
 	chClient := client.Client{
 		Host:     config.Cfg.Clickhouse.Host,
 		Port:     config.Cfg.Clickhouse.Port,
 		UserName: config.Cfg.Clickhouse.User,
 		Password: config.Cfg.Clickhouse.Password,
 		DB:       "flow_tag",
+		Context:  ctx,
 	}
 	sqlSplit := strings.Split(rawSql, " ")
 	tag := sqlSplit[2]
@@ -0,0 +1,496 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package clickhouse
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/xwb1989/sqlparser"
+
+	"github.com/deepflowio/deepflow/server/querier/common"
+	chCommon "github.com/deepflowio/deepflow/server/querier/engine/clickhouse/common"
+)
+
+const (
+	FUNCTION_TIME_SHIFT       = "TimeShift"
+	FUNCTION_TIME_SHIFT_RATIO = "TimeShiftRatio"
+
+	TIME_SHIFT_CURRENT_PREFIX  = "__time_shift_current_"
+	TIME_SHIFT_PREVIOUS_PREFIX = "__time_shift_previous_"
+)
+
+var timeShiftUnits = map[byte]int64{
+	's': 1,
+	'm': 60,
+	'h': 3600,
+	'd': 86400,
+	'w': 604800,
+}
+
+// TimeShift(metric, '1d') returns the metric of the same group one offset earlier,
+// TimeShiftRatio(metric, '1d') returns (current - previous) / previous
+type TimeShiftColumn struct {
+	Name     string
+	Metric   string
+	Offset   int64
+	IsRatio  bool
+	Previous string // alias of the metric in the shifted query
+	Current  string // alias of the metric in the base query, only for ratio
+}
+
+// The base query removes the TimeShift columns, each offset runs a shifted query whose time range in WHERE
+// is moved back by the offset, then the shifted results are joined to the base result by the GROUP BY columns
+// with the timestamps of time() aligned by the offset
+type TimeShiftQuery struct {
+	BaseSql       string
+	ShiftedSqls   map[int64]string
+	Columns       []*TimeShiftColumn
+	Projection    []*TimeShiftColumn // columns of SELECT in order, nil for the columns of the base query
+	KeyColumns    []string
+	TimeColumns   map[string]bool
+	HiddenColumns map[string]bool
+}
+
+func ParseTimeShiftOffset(offset string) (int64, error) {
+	offset = strings.TrimSpace(offset)
+	if len(offset) < 2 {
+		return 0, fmt.Errorf("time shift offset '%s' is invalid, should be like 1h, 1d or 1w", offset)
+	}
+	unit, ok := timeShiftUnits[offset[len(offset)-1]]
+	if !ok {
+		return 0, fmt.Errorf("time shift offset '%s' is invalid, unit should be one of s, m, h, d and w", offset)
+	}
+	value, err := strconv.ParseInt(offset[:len(offset)-1], 10, 64)
+	if err != nil || value <= 0 {
+		return 0, fmt.Errorf("time shift offset '%s' is invalid, should be like 1h, 1d or 1w", offset)
+	}
+	return value * unit, nil
+}
+
+func isTimeShiftFunction(expr sqlparser.Expr) (*sqlparser.FuncExpr, bool) {
+	function, ok := expr.(*sqlparser.FuncExpr)
+	if !ok {
+		return nil, false
+	}
+	name := function.Name.String()
+	return function, name == FUNCTION_TIME_SHIFT || name == FUNCTION_TIME_SHIFT_RATIO
+}
+
+func getSelectColumnName(item *sqlparser.AliasedExpr) string {
+	if as := chCommon.ParseAlias(item.As); as != "" {
+		return strings.Trim(as, "`")
+	}
+	return strings.Trim(sqlparser.String(item.Expr), "`")
+}
+
+func TransTimeShiftSql(sql string) (*TimeShiftQuery, error) {
+	stmt, err := sqlparser.Parse(sql)
+	if err != nil {
+		return nil, nil
+	}
+	pStmt, ok := stmt.(*sqlparser.Select)
+	if !ok {
+		return nil, nil
+	}
+	hasTimeShift := false
+	for _, expr := range pStmt.SelectExprs {
+		if item, ok := expr.(*sqlparser.AliasedExpr); ok {
+			if _, ok := isTimeShiftFunction(item.Expr); ok {
+				hasTimeShift = true
+				break
+			}
+		}
+	}
+	if !hasTimeShift {
+		return nil, nil
+	}
+
+	query := &TimeShiftQuery{
+		ShiftedSqls:   make(map[int64]string),
+		TimeColumns:   make(map[string]bool),
+		HiddenColumns: make(map[string]bool),
+	}
+	groups := make(map[string]bool)
+	for _, group := range pStmt.GroupBy {
+		groups[strings.Trim(sqlparser.String(group), "`")] = true
+	}
+	var baseExprs, keyExprs sqlparser.SelectExprs
+	shiftedExprs := make(map[int64]sqlparser.SelectExprs)
+	offsets := []int64{}
+	for _, expr := range pStmt.SelectExprs {
+		item, ok := expr.(*sqlparser.AliasedExpr)
+		if !ok {
+			return nil, errors.New("select * is not supported with time shift")
+		}
+		function, ok := isTimeShiftFunction(item.Expr)
+		if !ok {
+			baseExprs = append(baseExprs, item)
+			query.Projection = append(query.Projection, nil)
+			name := getSelectColumnName(item)
+			if groups[name] || groups[strings.Trim(sqlparser.String(item.Expr), "`")] {
+				keyExprs = append(keyExprs, item)
+				query.KeyColumns = append(query.KeyColumns, name)
+				if f, ok := item.Expr.(*sqlparser.FuncExpr); ok && f.Name.Lowered() == "time" {
+					query.TimeColumns[name] = true
+				}
+			}
+			continue
+		}
+
+		if len(function.Exprs) != 2 {
+			return nil, fmt.Errorf("%s should have 2 arguments: metric and offset", function.Name.String())
+		}
+		metric, ok := function.Exprs[0].(*sqlparser.AliasedExpr)
+		if !ok {
+			return nil, fmt.Errorf("%s: metric %s is invalid", function.Name.String(), sqlparser.String(function.Exprs[0]))
+		}
+		offsetVal, ok := function.Exprs[1].(*sqlparser.AliasedExpr)
+		if !ok {
+			return nil, fmt.Errorf("%s: offset %s is invalid", function.Name.String(), sqlparser.String(function.Exprs[1]))
+		}
+		offset, err := ParseTimeShiftOffset(strings.Trim(sqlparser.String(offsetVal.Expr), "'"))
+		if err != nil {
+			return nil, err
+		}
+		index := len(query.Columns)
+		column := &TimeShiftColumn{
+			Name:     getSelectColumnName(item),
+			Metric:   sqlparser.String(metric.Expr),
+			Offset:   offset,
+			IsRatio:  function.Name.String() == FUNCTION_TIME_SHIFT_RATIO,
+			Previous: fmt.Sprintf("%s%d", TIME_SHIFT_PREVIOUS_PREFIX, index),
+		}
+		if _, ok := shiftedExprs[offset]; !ok {
+			offsets = append(offsets, offset)
+		}
+		shiftedExprs[offset] = append(shiftedExprs[offset], &sqlparser.AliasedExpr{
+			Expr: metric.Expr, As: sqlparser.NewColIdent(column.Previous),
+		})
+		if column.IsRatio {
+			column.Current = fmt.Sprintf("%s%d", TIME_SHIFT_CURRENT_PREFIX, index)
+			query.HiddenColumns[column.Current] = true
+			baseExprs = append(baseExprs, &sqlparser.AliasedExpr{Expr: metric.Expr, As: sqlparser.NewColIdent(column.Current)})
+		}
+		query.Columns = append(query.Columns, column)
+		query.Projection = append(query.Projection, column)
+	}
+	if pStmt.Where == nil {
+		return nil, errors.New("time shift requires a time range in where")
+	}
+
+	baseStmt := *pStmt
+	baseStmt.SelectExprs = baseExprs
+	query.BaseSql = sqlparser.String(&baseStmt)
+	for _, offset := range offsets {
+		where, err := shiftTimeRange(pStmt.Where.Expr, offset)
+		if err != nil {
+			return nil, err
+		}
+		shiftedStmt := *pStmt
+		shiftedStmt.SelectExprs = append(append(sqlparser.SelectExprs{}, keyExprs...), shiftedExprs[offset]...)
+		shiftedStmt.Where = sqlparser.NewWhere(sqlparser.WhereStr, where)
+		// the shifted query should cover all groups of the base query
+		shiftedStmt.Having, shiftedStmt.OrderBy, shiftedStmt.Limit = nil, nil, nil
+		query.ShiftedSqls[offset] = sqlparser.String(&shiftedStmt)
+	}
+	return query, nil
+}
+
+// Move the time filters of WHERE back by offset, only unix timestamps are supported
+func shiftTimeRange(expr sqlparser.Expr, offset int64) (sqlparser.Expr, error) {
+	shifted := 0
+	shift := func(val sqlparser.Expr) (sqlparser.Expr, error) {
+		sqlVal, ok := val.(*sqlparser.SQLVal)
+		if !ok {
+			return nil, fmt.Errorf("time filter %s should be a unix timestamp", sqlparser.String(val))
+		}
+		timestamp, err := strconv.ParseInt(string(sqlVal.Val), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("time filter %s should be a unix timestamp", sqlparser.String(val))
+		}
+		shifted++
+		return sqlparser.NewIntVal([]byte(strconv.FormatInt(timestamp-offset, 10))), nil
+	}
+	isTime := func(expr sqlparser.Expr) bool {
+		colName, ok := expr.(*sqlparser.ColName)
+		return ok && colName.Name.Lowered() == "time"
+	}
+
+	var trans func(expr sqlparser.Expr) (sqlparser.Expr, error)
+	trans = func(expr sqlparser.Expr) (sqlparser.Expr, error) {
+		var err error
+		switch expr := expr.(type) {
+		case *sqlparser.AndExpr:
+			node := *expr
+			if node.Left, err = trans(expr.Left); err != nil {
+				return nil, err
+			}
+			node.Right, err = trans(expr.Right)
+			return &node, err
+		case *sqlparser.OrExpr:
+			node := *expr
+			if node.Left, err = trans(expr.Left); err != nil {
+				return nil, err
+			}
+			node.Right, err = trans(expr.Right)
+			return &node, err
+		case *sqlparser.ParenExpr:
+			node := *expr
+			node.Expr, err = trans(expr.Expr)
+			return &node, err
+		case *sqlparser.ComparisonExpr:
+			node := *expr
+			if isTime(expr.Left) {
+				node.Right, err = shift(expr.Right)
+			} else if isTime(expr.Right) {
+				node.Left, err = shift(expr.Left)
+			}
+			return &node, err
+		case *sqlparser.RangeCond:
+			node := *expr
+			if isTime(expr.Left) {
+				if node.From, err = shift(expr.From); err != nil {
+					return nil, err
+				}
+				node.To, err = shift(expr.To)
+			}
+			return &node, err
+		}
+		return expr, nil
+	}
+	where, err := trans(expr)
+	if err != nil {
+		return nil, err
+	}
+	if shifted == 0 {
+		return nil, errors.New("time shift requires a time range in where")
+	}
+	return where, nil
+}
+
+func (e *CHEngine) ParseTimeShiftSql(sql string, args *common.QuerierParams) (*common.Result, map[string]interface{}, error) {
+	query, err := TransTimeShiftSql(sql)
+	if err != nil {
+		log.Error(err)
+		return nil, nil, err
+	}
+	if query == nil {
+		return nil, nil, nil
+	}
+
+	execute := func(sql string, noLimit bool) (*common.Result, map[string]interface{}, error) {
+		engine := &CHEngine{DB: e.DB, DataSource: e.DataSource}
+		engine.Init()
+		queryArgs := *args
+		queryArgs.Sql = sql
+		queryArgs.NoLimit = noLimit
+		// results need to be merged before writing
+		queryArgs.Writer = nil
+		return engine.ExecuteQuery(&queryArgs)
+	}
+	// the base query keeps the limit of the user, or the default limit
+	result, debug, err := execute(query.BaseSql, args.NoLimit)
+	if err != nil {
+		return nil, debug, err
+	}
+	offsets := make([]int64, 0, len(query.ShiftedSqls))
+	for offset := range query.ShiftedSqls {
+		offsets = append(offsets, offset)
+	}
+	sort.Slice(offsets, func(i, j int) bool { return offsets[i] < offsets[j] })
+	shiftedDebugs := []map[string]interface{}{}
+	shiftedResults := make(map[int64]*common.Result)
+	for _, offset := range offsets {
+		// the default limit is not added, otherwise the groups beyond it are missing in the shifted results
+		shiftedResult, shiftedDebug, err := execute(query.ShiftedSqls[offset], true)
+		shiftedDebugs = append(shiftedDebugs, shiftedDebug)
+		if err != nil {
+			return nil, debug, err
+		}
+		shiftedResults[offset] = shiftedResult
+	}
+	if debug != nil {
+		debug["time_shift"] = shiftedDebugs
+	}
+	if args.Explain {
+		// one row for the base query and one for each offset
+		for _, offset := range offsets {
+			result.Values = append(result.Values, shiftedResults[offset].Values...)
+		}
+		return result, debug, nil
+	}
+	if err := query.Merge(result, shiftedResults); err != nil {
+		return nil, debug, err
+	}
+	return result, debug, nil
+}
+
+func timeShiftKey(row []interface{}, indexes []int, timeColumns []bool, offset int64) string {
+	keys := make([]string, 0, len(indexes))
+	for i, index := range indexes {
+		value := row[index]
+		if timeColumns[i] {
+			if timestamp, ok := value.(int); ok {
+				value = timestamp + int(offset)
+			}
+		}
+		keys = append(keys, fmt.Sprint(value))
+	}
+	return strings.Join(keys, "\x00")
+}
+
+func toFloat64(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	}
+	return 0, false
+}
+
+func getColumnIndexes(result *common.Result, names []string) ([]int, error) {
+	indexes := make([]int, 0, len(names))
+	for _, name := range names {
+		index := -1
+		for i, column := range result.Columns {
+			if fmt.Sprint(column) == name {
+				index = i
+				break
+			}
+		}
+		if index < 0 {
+			return nil, fmt.Errorf("column %s not found in result", name)
+		}
+		indexes = append(indexes, index)
+	}
+	return indexes, nil
+}
+
+// Insert the TimeShift columns into the base result in the order of SELECT and remove the hidden ones
+func (q *TimeShiftQuery) Merge(result *common.Result, shiftedResults map[int64]*common.Result) error {
+	keyIndexes, err := getColumnIndexes(result, q.KeyColumns)
+	if err != nil {
+		return err
+	}
+	timeColumns := make([]bool, len(q.KeyColumns))
+	for i, name := range q.KeyColumns {
+		timeColumns[i] = q.TimeColumns[name]
+	}
+	// offset -> key -> row
+	shiftedRows := make(map[int64]map[string][]interface{})
+	shiftedIndexes := make(map[string]int)
+	for offset, shiftedResult := range shiftedResults {
+		indexes, err := getColumnIndexes(shiftedResult, q.KeyColumns)
+		if err != nil {
+			return err
+		}
+		rows := make(map[string][]interface{}, len(shiftedResult.Values))
+		for _, value := range shiftedResult.Values {
+			row := value.([]interface{})
+			rows[timeShiftKey(row, indexes, timeColumns, offset)] = row
+		}
+		shiftedRows[offset] = rows
+		for i, column := range shiftedResult.Columns {
+			shiftedIndexes[fmt.Sprint(column)] = i
+		}
+	}
+
+	// the base result has the columns of the base query in the order of SELECT
+	keptIndexes := []int{}
+	currentIndexes := make(map[string]int)
+	for i, column := range result.Columns {
+		if q.HiddenColumns[fmt.Sprint(column)] {
+			currentIndexes[fmt.Sprint(column)] = i
+			continue
+		}
+		keptIndexes = append(keptIndexes, i)
+	}
+	baseColumnCount := 0
+	for _, column := range q.Projection {
+		if column == nil {
+			baseColumnCount++
+		}
+	}
+	if baseColumnCount != len(keptIndexes) {
+		return fmt.Errorf("base result has %d columns, %d expected", len(keptIndexes), baseColumnCount)
+	}
+
+	columns := make([]interface{}, 0, len(q.Projection))
+	var schemas common.ColumnSchemas
+	baseIndex := 0
+	for _, column := range q.Projection {
+		if column == nil {
+			i := keptIndexes[baseIndex]
+			baseIndex++
+			columns = append(columns, result.Columns[i])
+			if i < len(result.Schemas) {
+				schemas = append(schemas, result.Schemas[i])
+			}
+			continue
+		}
+		columns = append(columns, column.Name)
+		schema := common.NewColumnSchema(column.Name, column.Metric, "")
+		schema.Type = common.COLUMN_SCHEMA_TYPE_METRICS
+		schemas = append(schemas, schema)
+	}
+
+	values := make([]interface{}, 0, len(result.Values))
+	for _, value := range result.Values {
+		row := value.([]interface{})
+		key := timeShiftKey(row, keyIndexes, timeColumns, 0)
+		newRow := make([]interface{}, 0, len(columns))
+		baseIndex = 0
+		for _, column := range q.Projection {
+			if column == nil {
+				newRow = append(newRow, row[keptIndexes[baseIndex]])
+				baseIndex++
+				continue
+			}
+			var previous interface{}
+			if shiftedRow, ok := shiftedRows[column.Offset][key]; ok {
+				previous = shiftedRow[shiftedIndexes[column.Previous]]
+			}
+			if !column.IsRatio {
+				newRow = append(newRow, previous)
+				continue
+			}
+			current, currentOk := toFloat64(row[currentIndexes[column.Current]])
+			prev, prevOk := toFloat64(previous)
+			if currentOk && prevOk && prev != 0 {
+				newRow = append(newRow, (current-prev)/prev)
+			} else {
+				newRow = append(newRow, nil)
+			}
+		}
+		values = append(values, newRow)
+	}
+	result.Columns = columns
+	result.Values = values
+	result.Schemas = schemas
+	return nil
+}
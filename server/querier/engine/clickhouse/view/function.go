@@ -48,6 +48,11 @@ const (
 	FUNCTION_PERCENTAG   = "Percentage"
 	FUNCTION_HISTOGRAM   = "Histogram"
 	FUNCTION_LAST        = "Last"
+	FUNCTION_DELTA       = "Delta"
+	FUNCTION_RATE        = "Rate"
+	FUNCTION_DERIVATIVE  = "Derivative"
+	FUNCTION_MOVING_AVG  = "MovingAverage"
+	FUNCTION_CUMSUM      = "CumulativeSum"
 )
 
 // 对外提供的算子与数据库实际算子转换
@@ -73,6 +78,12 @@ var FUNC_NAME_MAP map[string]string = map[string]string{
 var MATH_FUNCTIONS = []string{
 	FUNCTION_DIV, FUNCTION_PLUS, FUNCTION_MINUS, FUNCTION_MULTIPLY,
 	FUNCTION_PERCENTAG, FUNCTION_PERSECOND, FUNCTION_HISTOGRAM,
+	FUNCTION_DELTA, FUNCTION_RATE, FUNCTION_DERIVATIVE, FUNCTION_MOVING_AVG, FUNCTION_CUMSUM,
+}
+
+// 跨时间桶计算的窗口算子，需要与time()一起使用
+var WINDOW_FUNCTIONS = []string{
+	FUNCTION_DELTA, FUNCTION_RATE, FUNCTION_DERIVATIVE, FUNCTION_MOVING_AVG, FUNCTION_CUMSUM,
 }
 
 func GetFunc(name string) Function {
@@ -93,6 +104,8 @@ func GetFunc(name string) Function {
 		return &PerSecondFunction{DefaultFunction: DefaultFunction{Name: name}}
 	case FUNCTION_HISTOGRAM:
 		return &HistogramFunction{DefaultFunction: DefaultFunction{Name: name}}
+	case FUNCTION_DELTA, FUNCTION_RATE, FUNCTION_DERIVATIVE, FUNCTION_MOVING_AVG, FUNCTION_CUMSUM:
+		return &WindowFunction{DefaultFunction: DefaultFunction{Name: name}}
 	default:
 		return &DefaultFunction{Name: name}
 	}
@@ -813,3 +826,106 @@ func (f *MinFunction) GetWiths() []Node {
 		return f.Withs
 	}
 }
+
+// 窗口算子，在同一tag分组内按time()的时间桶排序计算
+// Delta(m):         m - lagInFrame(m) OVER w
+// Derivative(m):    (m - lagInFrame(m)) / (time - lagInFrame(time)) OVER w
+// Rate(m):          与Derivative相同，但计数器回绕（m变小）时以m作为增量
+// MovingAverage(m, k): avg(m) OVER (... ROWS BETWEEN k-1 PRECEDING AND CURRENT ROW)
+// CumulativeSum(m): sum(m) OVER (... ROWS BETWEEN UNBOUNDED PRECEDING AND CURRENT ROW)
+type WindowFunction struct {
+	DefaultFunction
+	Groups     *Groups // 用于生成PARTITION BY，除时间外的所有外层group
+	WindowRows int     // MovingAverage的窗口行数
+}
+
+func (f *WindowFunction) Init() {
+	f.DefaultFunction.Init()
+	if f.Name == FUNCTION_MOVING_AVG && len(f.Fields) > 1 {
+		f.WindowRows, _ = strconv.Atoi(strings.Trim(f.Fields[1].ToString(), "'"))
+		f.Fields = f.Fields[:1]
+	}
+	if f.WindowRows < 1 {
+		f.WindowRows = 1
+	}
+}
+
+func (f *WindowFunction) getTimeAlias() string {
+	if f.Time == nil {
+		return ""
+	}
+	return strings.Trim(f.Time.Alias, "`")
+}
+
+func (f *WindowFunction) getPartitions() []string {
+	partitions := []string{}
+	if f.Groups == nil {
+		return partitions
+	}
+	timeAlias := f.getTimeAlias()
+	for _, node := range f.Groups.groups {
+		group := node.(*Group)
+		if group.Flag == GROUP_FLAG_METRICS_INNTER {
+			continue
+		}
+		value := group.Alias
+		if value == "" {
+			value = group.Value
+		}
+		value = strings.Trim(value, "`")
+		if value == timeAlias || common.IsValueInSliceString("`"+value+"`", partitions) {
+			continue
+		}
+		partitions = append(partitions, "`"+value+"`")
+	}
+	return partitions
+}
+
+func (f *WindowFunction) getWindow(frameStart string) string {
+	buf := bytes.Buffer{}
+	buf.WriteString(" OVER (")
+	if partitions := f.getPartitions(); len(partitions) > 0 {
+		buf.WriteString("PARTITION BY ")
+		buf.WriteString(strings.Join(partitions, ", "))
+		buf.WriteString(" ")
+	}
+	buf.WriteString("ORDER BY `")
+	buf.WriteString(f.getTimeAlias())
+	buf.WriteString("` ASC ROWS BETWEEN ")
+	buf.WriteString(frameStart)
+	buf.WriteString(" AND CURRENT ROW)")
+	return buf.String()
+}
+
+func (f *WindowFunction) WriteTo(buf *bytes.Buffer) {
+	field := f.Fields[0].ToString()
+	timeField := "`" + f.getTimeAlias() + "`"
+	previous := func(value string) string {
+		// 第一个时间桶没有前值，结果为null
+		return fmt.Sprintf("lagInFrame(toNullable(%s), 1)%s", value, f.getWindow("1 PRECEDING"))
+	}
+	switch f.Name {
+	case FUNCTION_DELTA:
+		buf.WriteString(fmt.Sprintf("(%s - %s)", field, previous(field)))
+	case FUNCTION_DERIVATIVE:
+		buf.WriteString(fmt.Sprintf(
+			"divide(%s - %s, %s - %s)", field, previous(field), timeField, previous(timeField),
+		))
+	case FUNCTION_RATE:
+		buf.WriteString(fmt.Sprintf(
+			"divide(if(%s > %s, %s, %s - %s), %s - %s)",
+			previous(field), field, field, field, previous(field), timeField, previous(timeField),
+		))
+	case FUNCTION_MOVING_AVG:
+		buf.WriteString(fmt.Sprintf("avg(%s)%s", field, f.getWindow(fmt.Sprintf("%d PRECEDING", f.WindowRows-1))))
+	case FUNCTION_CUMSUM:
+		buf.WriteString(fmt.Sprintf("sum(%s)%s", field, f.getWindow("UNBOUNDED PRECEDING")))
+	}
+	buf.WriteString(f.Math)
+	if !f.Nest && f.Alias != "" {
+		buf.WriteString(" AS ")
+		buf.WriteString("`")
+		buf.WriteString(strings.Trim(f.Alias, "`"))
+		buf.WriteString("`")
+	}
+}
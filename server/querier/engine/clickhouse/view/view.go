@@ -18,6 +18,7 @@ package view
 
 import (
 	"bytes"
+	"regexp"
 	"strings"
 	"time"
 
@@ -168,6 +169,14 @@ func (v *View) ToString() string {
 	return buf.String()
 }
 
+// 最内层查询的过滤条件是否使用PREWHERE，需在ToString之后调用
+func (v *View) UsePreWhere() bool {
+	if len(v.SubViewLevels) == 0 || v.SubViewLevels[0].Filters.IsNull() {
+		return false
+	}
+	return v.SubViewLevels[0].UsePreWhere()
+}
+
 func (v *View) GetCallbacks() (callbacks map[string]func(*common.Result) error) {
 	return v.Model.Callbacks
 }
@@ -357,6 +366,20 @@ func (sv *SubView) removeDup(ns NodeSet) []Node {
 	return targetList
 }
 
+// 非默认组织的数据库带有组织前缀，如org0002_flow_metrics
+var orgDatabasePrefixRegexp = regexp.MustCompile(`^org\d{4}_`)
+
+// flow_tag及flow_metrics的视图表不支持PREWHERE
+func (sv *SubView) UsePreWhere() bool {
+	from := orgDatabasePrefixRegexp.ReplaceAllString(sv.From.ToString(), "")
+	if strings.HasPrefix(from, "flow_tag") {
+		return false
+	} else if strings.HasPrefix(from, "flow_metrics") && !strings.HasSuffix(from, ".1m`") && !strings.HasSuffix(from, ".1s`") {
+		return false
+	}
+	return !sv.NoPreWhere
+}
+
 func (sv *SubView) WriteTo(buf *bytes.Buffer) {
 	if nodeWiths := sv.GetWiths(); nodeWiths != nil {
 		withs := Withs{Withs: nodeWiths}
@@ -375,12 +398,7 @@ func (sv *SubView) WriteTo(buf *bytes.Buffer) {
 		sv.From.WriteTo(buf)
 	}
 	if !sv.Filters.IsNull() {
-		from := sv.From.ToString()
-		if strings.HasPrefix(from, "flow_tag") {
-			buf.WriteString(" WHERE ")
-		} else if strings.HasPrefix(from, "flow_metrics") && !strings.HasSuffix(from, ".1m`") && !strings.HasSuffix(from, ".1s`") {
-			buf.WriteString(" WHERE ")
-		} else if !sv.NoPreWhere {
+		if sv.UsePreWhere() {
 			buf.WriteString(" PREWHERE ")
 		} else {
 			buf.WriteString(" WHERE ")
@@ -17,6 +17,8 @@
 package parse
 
 import (
+	"fmt"
+
 	"github.com/xwb1989/sqlparser"
 
 	"github.com/deepflowio/deepflow/server/querier/engine"
@@ -38,7 +40,11 @@ func (p *Parser) ParseSQL(sql string) error {
 		return err
 	}
 
-	pStmt := stmt.(*sqlparser.Select)
+	// UNION ALL及派生表由引擎单独处理，此处仅解析单个Select
+	pStmt, ok := stmt.(*sqlparser.Select)
+	if !ok {
+		return fmt.Errorf("sql: '%s' not support", sql)
+	}
 	// From解析
 	if pStmt.From != nil {
 		fromErr := p.Engine.TransFrom(pStmt.From)
@@ -38,6 +38,27 @@ type ProfileTreeNode struct {
 	TotalValue         int    `json:"total_value"`
 }
 
+// 对比两个时间段或两组标签过滤条件的火焰图，baseline 未指定的条件与 ProfileTracing 保持一致
+// compare flame graphs of two time ranges or tag filters, unspecified baseline args are the same as ProfileTracing
+type ProfileDiffTracing struct {
+	ProfileTracing
+	BaselineTagFilter string `json:"baseline_tag_filter"`
+	BaselineTimeStart int    `json:"baseline_time_start"`
+	BaselineTimeEnd   int    `json:"baseline_time_end"`
+}
+
+type ProfileDiffTreeNode struct {
+	ProfileLocationStr string `json:"profile_location_str"`
+	NodeID             string `json:"node_id"`
+	ParentNodeID       string `json:"parent_node_id"`
+	BaselineSelfValue  int    `json:"baseline_self_value"`
+	BaselineTotalValue int    `json:"baseline_total_value"`
+	SelfValue          int    `json:"self_value"`
+	TotalValue         int    `json:"total_value"`
+	SelfDelta          int    `json:"self_delta"`
+	TotalDelta         int    `json:"total_delta"`
+}
+
 type Debug struct {
 	IP         string
 	Sql        string `json:"sql"`
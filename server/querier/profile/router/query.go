@@ -17,6 +17,8 @@
 package router
 
 import (
+	"net/http"
+
 	"github.com/gin-gonic/gin"
 	"github.com/gin-gonic/gin/binding"
 
@@ -29,7 +31,8 @@ import (
 
 func ProfileRouter(e *gin.Engine, cfg *config.QuerierConfig) {
 	e.POST("/v1/profile/ProfileTracing", profileTracing(cfg))
-
+	e.POST("/v1/profile/ProfileTracingDiff", profileTracingDiff(cfg))
+	e.POST("/v1/profile/ProfileTracingPprof", profileTracingPprof(cfg))
 }
 
 func profileTracing(cfg *config.QuerierConfig) gin.HandlerFunc {
@@ -50,3 +53,52 @@ func profileTracing(cfg *config.QuerierConfig) gin.HandlerFunc {
 		router.JsonResponse(c, result, debug, err)
 	})
 }
+
+func profileTracingDiff(cfg *config.QuerierConfig) gin.HandlerFunc {
+	return gin.HandlerFunc(func(c *gin.Context) {
+		var profileTracingDiff model.ProfileDiffTracing
+
+		// 参数校验
+		err := c.ShouldBindBodyWith(&profileTracingDiff, binding.JSON)
+		if err != nil {
+			router.BadRequestResponse(c, common.INVALID_POST_DATA, err.Error())
+			return
+		}
+		profileTracingDiff.Context = c.Request.Context()
+		result, debug, err := service.DiffTracing(profileTracingDiff, cfg)
+		if serviceErr, ok := err.(*service.ServiceError); ok {
+			router.BadRequestResponse(c, serviceErr.Status, serviceErr.Message)
+			return
+		}
+		if err == nil && !profileTracingDiff.Debug {
+			debug = nil
+		}
+		router.JsonResponse(c, result, debug, err)
+	})
+}
+
+// 返回 gzip 压缩的 pprof protobuf，出错时返回 json
+// respond gzipped pprof protobuf, or json when failed
+func profileTracingPprof(cfg *config.QuerierConfig) gin.HandlerFunc {
+	return gin.HandlerFunc(func(c *gin.Context) {
+		var profileTracing model.ProfileTracing
+
+		// 参数校验
+		err := c.ShouldBindBodyWith(&profileTracing, binding.JSON)
+		if err != nil {
+			router.BadRequestResponse(c, common.INVALID_POST_DATA, err.Error())
+			return
+		}
+		profileTracing.Context = c.Request.Context()
+		result, debug, err := service.ExportPprof(profileTracing, cfg)
+		if err != nil {
+			if !profileTracing.Debug {
+				debug = nil
+			}
+			router.JsonResponse(c, nil, debug, err)
+			return
+		}
+		c.Header("Content-Disposition", "attachment; filename=profile.pb.gz")
+		c.Data(http.StatusOK, "application/octet-stream", result)
+	})
+}
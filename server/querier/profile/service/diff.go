@@ -0,0 +1,114 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/deepflowio/deepflow/server/querier/config"
+	"github.com/deepflowio/deepflow/server/querier/profile/common"
+	"github.com/deepflowio/deepflow/server/querier/profile/model"
+)
+
+func DiffTracing(args model.ProfileDiffTracing, cfg *config.QuerierConfig) (result []*model.ProfileDiffTreeNode, debug interface{}, err error) {
+	// baseline 与当前查询完全相同时差分结果没有意义
+	// the baseline must differ from the current query by tag filter or time range
+	if args.BaselineTagFilter == "" && args.BaselineTimeStart == 0 && args.BaselineTimeEnd == 0 {
+		err = NewError(common.INVALID_POST_DATA, "one of baseline_tag_filter and baseline time range is required")
+		return
+	}
+	baselineArgs := args.ProfileTracing
+	if args.BaselineTagFilter != "" {
+		baselineArgs.TagFilter = args.BaselineTagFilter
+	}
+	if args.BaselineTimeStart != 0 || args.BaselineTimeEnd != 0 {
+		baselineArgs.TimeStart = args.BaselineTimeStart
+		baselineArgs.TimeEnd = args.BaselineTimeEnd
+	}
+	if baselineArgs.TimeStart > baselineArgs.TimeEnd {
+		err = NewError(common.INVALID_POST_DATA, fmt.Sprintf("baseline_time_start (%d) is greater than baseline_time_end (%d)", baselineArgs.TimeStart, baselineArgs.TimeEnd))
+		return
+	}
+
+	baselineStacks, _, baselineDebug, err := queryProfileStacks(baselineArgs, cfg)
+	if err != nil {
+		return
+	}
+	stacks, _, profileDebug, err := queryProfileStacks(args.ProfileTracing, cfg)
+	if err != nil {
+		return
+	}
+	formatStartTime := time.Now()
+	result = diffProfileTree(baselineStacks, stacks)
+	formatEndTime := int64(time.Since(formatStartTime))
+	profileDebug.FormatTime = fmt.Sprintf("%.9fs", float64(formatEndTime)/1e9)
+	debug = []model.Debug{baselineDebug, profileDebug}
+	return
+}
+
+// 合并 baseline 与当前的火焰图节点，节点 ID 由调用栈生成，两侧相同调用栈的节点 ID 一致
+// merge nodes of baseline and current flame graphs, node ids are generated from stacks and equal on both sides
+func diffProfileTree(baselineStacks, stacks []profileStack) []*model.ProfileDiffTreeNode {
+	baselineTree, baselineRootTotalValue := buildProfileTree(baselineStacks)
+	tree, rootTotalValue := buildProfileTree(stacks)
+	if len(baselineTree) == 0 && len(tree) == 0 {
+		return nil
+	}
+
+	rootNode := &model.ProfileDiffTreeNode{
+		ProfileLocationStr: "root",
+		ParentNodeID:       "-1",
+		BaselineTotalValue: baselineRootTotalValue,
+		TotalValue:         rootTotalValue,
+		TotalDelta:         rootTotalValue - baselineRootTotalValue,
+	}
+	result := []*model.ProfileDiffTreeNode{rootNode}
+	for nodeID, node := range tree {
+		diffNode := &model.ProfileDiffTreeNode{
+			ProfileLocationStr: node.ProfileLocationStr,
+			NodeID:             nodeID,
+			ParentNodeID:       node.ParentNodeID,
+			SelfValue:          node.SelfValue,
+			TotalValue:         node.TotalValue,
+		}
+		if baselineNode, ok := baselineTree[nodeID]; ok {
+			diffNode.BaselineSelfValue = baselineNode.SelfValue
+			diffNode.BaselineTotalValue = baselineNode.TotalValue
+		}
+		diffNode.SelfDelta = diffNode.SelfValue - diffNode.BaselineSelfValue
+		diffNode.TotalDelta = diffNode.TotalValue - diffNode.BaselineTotalValue
+		result = append(result, diffNode)
+	}
+	// 仅在 baseline 中出现的节点
+	// nodes only exist in baseline
+	for nodeID, baselineNode := range baselineTree {
+		if _, ok := tree[nodeID]; ok {
+			continue
+		}
+		result = append(result, &model.ProfileDiffTreeNode{
+			ProfileLocationStr: baselineNode.ProfileLocationStr,
+			NodeID:             nodeID,
+			ParentNodeID:       baselineNode.ParentNodeID,
+			BaselineSelfValue:  baselineNode.SelfValue,
+			BaselineTotalValue: baselineNode.TotalValue,
+			SelfDelta:          -baselineNode.SelfValue,
+			TotalDelta:         -baselineNode.TotalValue,
+		})
+	}
+	return result
+}
@@ -0,0 +1,149 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pyroscope-io/pyroscope/pkg/storage/tree"
+
+	"github.com/deepflowio/deepflow/server/querier/config"
+	"github.com/deepflowio/deepflow/server/querier/profile/model"
+)
+
+// profile_value_unit 到 pprof 单位的映射，未列出的单位原样使用
+// profile_value_unit to pprof unit, units not listed are used as is
+var pprofUnits = map[string]string{
+	"samples":          "count",
+	"objects":          "count",
+	"goroutines":       "count",
+	"lock_samples":     "count",
+	"lock_nanoseconds": "nanoseconds",
+	"bytes":            "bytes",
+}
+
+// ExportPprof 导出聚合后的 profile，格式为 gzip 压缩的 pprof protobuf，可直接用于 `go tool pprof`
+func ExportPprof(args model.ProfileTracing, cfg *config.QuerierConfig) (result []byte, debug interface{}, err error) {
+	stacks, valueUnit, profileDebug, err := queryProfileStacks(args, cfg)
+	if err != nil {
+		return
+	}
+	formatStartTime := time.Now()
+	profile := buildPprof(stacks, args.ProfileEventType, valueUnit, args.TimeStart, args.TimeEnd)
+	result, err = marshalPprof(profile)
+	if err != nil {
+		return
+	}
+	formatEndTime := int64(time.Since(formatStartTime))
+	profileDebug.FormatTime = fmt.Sprintf("%.9fs", float64(formatEndTime)/1e9)
+	debug = profileDebug
+	return
+}
+
+func buildPprof(stacks []profileStack, eventType, valueUnit string, timeStart, timeEnd int) *tree.Profile {
+	unit, ok := pprofUnits[valueUnit]
+	if !ok {
+		unit = valueUnit
+	}
+	if unit == "" {
+		unit = "count"
+	}
+	b := &pprofBuilder{
+		profile: &tree.Profile{
+			StringTable:   []string{""},
+			TimeNanos:     int64(timeStart) * int64(time.Second),
+			DurationNanos: int64(timeEnd-timeStart) * int64(time.Second),
+		},
+		strings:   map[string]int64{"": 0},
+		functions: map[string]uint64{},
+		samples:   map[string]*tree.Sample{},
+	}
+	b.profile.SampleType = []*tree.ValueType{{Type: b.stringIndex(eventType), Unit: b.stringIndex(unit)}}
+
+	for _, stack := range stacks {
+		if stack.value == 0 || len(stack.locations) == 0 {
+			continue
+		}
+		// 相同调用栈合并为一个 sample
+		key := strings.Join(stack.locations, ";")
+		if sample, ok := b.samples[key]; ok {
+			sample.Value[0] += int64(stack.value)
+			continue
+		}
+		// pprof 中 location_id[0] 为叶子节点
+		// location_id[0] is the leaf in pprof
+		locationIDs := make([]uint64, 0, len(stack.locations))
+		for i := len(stack.locations) - 1; i >= 0; i-- {
+			locationIDs = append(locationIDs, b.locationID(stack.locations[i]))
+		}
+		sample := &tree.Sample{LocationId: locationIDs, Value: []int64{int64(stack.value)}}
+		b.samples[key] = sample
+		b.profile.Sample = append(b.profile.Sample, sample)
+	}
+	return b.profile
+}
+
+func marshalPprof(profile *tree.Profile) ([]byte, error) {
+	data, err := profile.MarshalVT()
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	gzipWriter := gzip.NewWriter(&buf)
+	if _, err := gzipWriter.Write(data); err != nil {
+		return nil, err
+	}
+	if err := gzipWriter.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+type pprofBuilder struct {
+	profile   *tree.Profile
+	strings   map[string]int64
+	functions map[string]uint64
+	samples   map[string]*tree.Sample
+}
+
+func (b *pprofBuilder) stringIndex(s string) int64 {
+	if index, ok := b.strings[s]; ok {
+		return index
+	}
+	index := int64(len(b.profile.StringTable))
+	b.profile.StringTable = append(b.profile.StringTable, s)
+	b.strings[s] = index
+	return index
+}
+
+// 每个函数对应一个 location，ID 从 1 开始
+// one location per function, ids start from 1
+func (b *pprofBuilder) locationID(name string) uint64 {
+	if id, ok := b.functions[name]; ok {
+		return id
+	}
+	id := uint64(len(b.profile.Function) + 1)
+	nameIndex := b.stringIndex(name)
+	b.profile.Function = append(b.profile.Function, &tree.Function{Id: id, Name: nameIndex, SystemName: nameIndex})
+	b.profile.Location = append(b.profile.Location, &tree.Location{Id: id, Line: []*tree.Line{{FunctionId: id}}})
+	b.functions[name] = id
+	return id
+}
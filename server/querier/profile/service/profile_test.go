@@ -0,0 +1,140 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"testing"
+
+	"github.com/pyroscope-io/pyroscope/pkg/storage/tree"
+
+	"github.com/deepflowio/deepflow/server/querier/profile/common"
+	"github.com/deepflowio/deepflow/server/querier/profile/model"
+)
+
+func TestDiffTracingWithoutBaseline(t *testing.T) {
+	args := model.ProfileDiffTracing{}
+	args.TimeStart = 1
+	args.TimeEnd = 2
+	_, _, err := DiffTracing(args, nil)
+	serviceErr, ok := err.(*ServiceError)
+	if !ok || serviceErr.Status != common.INVALID_POST_DATA {
+		t.Errorf("expected %s error, got %v", common.INVALID_POST_DATA, err)
+	}
+}
+
+func TestDiffProfileTree(t *testing.T) {
+	baseline := []profileStack{
+		{locations: []string{"main", "handle", "query"}, value: 5},
+		{locations: []string{"main", "sleep"}, value: 2},
+	}
+	current := []profileStack{
+		{locations: []string{"main", "handle", "query"}, value: 8},
+		{locations: []string{"main", "handle", "render"}, value: 1},
+	}
+	nodes := map[string]*model.ProfileDiffTreeNode{}
+	for _, node := range diffProfileTree(baseline, current) {
+		nodes[node.ProfileLocationStr] = node
+	}
+	expected := map[string][4]int{
+		// baseline self, baseline total, self, total
+		"root":   {0, 7, 0, 9},
+		"main":   {0, 7, 0, 9},
+		"handle": {0, 5, 0, 9},
+		"query":  {5, 5, 8, 8},
+		"render": {0, 0, 1, 1},
+		"sleep":  {2, 2, 0, 0},
+	}
+	if len(nodes) != len(expected) {
+		t.Fatalf("got %d nodes, want %d", len(nodes), len(expected))
+	}
+	for name, values := range expected {
+		node, ok := nodes[name]
+		if !ok {
+			t.Fatalf("node %s not found", name)
+		}
+		got := [4]int{node.BaselineSelfValue, node.BaselineTotalValue, node.SelfValue, node.TotalValue}
+		if got != values {
+			t.Errorf("node %s: got %v, want %v", name, got, values)
+		}
+		if node.SelfDelta != values[2]-values[0] || node.TotalDelta != values[3]-values[1] {
+			t.Errorf("node %s: wrong delta %d/%d", name, node.SelfDelta, node.TotalDelta)
+		}
+	}
+	if nodes["query"].ParentNodeID != nodes["handle"].NodeID || nodes["sleep"].ParentNodeID != nodes["main"].NodeID {
+		t.Error("wrong parent node id")
+	}
+}
+
+func TestExportPprof(t *testing.T) {
+	stacks := []profileStack{
+		{locations: []string{"main", "handle", "query"}, value: 5},
+		{locations: []string{"main", "handle", "query"}, value: 3},
+		{locations: []string{"main", "sleep"}, value: 2},
+	}
+	data, err := marshalPprof(buildPprof(stacks, "alloc_space", "bytes", 1680000000, 1680000060))
+	if err != nil {
+		t.Fatal(err)
+	}
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	profile := &tree.Profile{}
+	if err := profile.UnmarshalVT(raw); err != nil {
+		t.Fatal(err)
+	}
+
+	st := profile.StringTable
+	if len(profile.SampleType) != 1 || st[profile.SampleType[0].Type] != "alloc_space" || st[profile.SampleType[0].Unit] != "bytes" {
+		t.Errorf("wrong sample type %v", profile.SampleType)
+	}
+	if profile.DurationNanos != 60e9 {
+		t.Errorf("wrong duration %d", profile.DurationNanos)
+	}
+	if len(profile.Function) != 4 || len(profile.Location) != 4 {
+		t.Errorf("got %d functions and %d locations, want 4", len(profile.Function), len(profile.Location))
+	}
+	functionNames := map[uint64]string{}
+	for _, function := range profile.Function {
+		functionNames[function.Id] = st[function.Name]
+	}
+	samples := map[string]int64{}
+	for _, sample := range profile.Sample {
+		key := ""
+		for _, id := range sample.LocationId {
+			key += functionNames[profile.Location[id-1].Line[0].FunctionId] + ";"
+		}
+		samples[key] = sample.Value[0]
+	}
+	// location_id[0] 为叶子节点
+	expected := map[string]int64{"query;handle;main;": 8, "sleep;main;": 2}
+	if len(samples) != len(expected) {
+		t.Fatalf("got samples %v, want %v", samples, expected)
+	}
+	for key, value := range expected {
+		if samples[key] != value {
+			t.Errorf("sample %s: got %d, want %d", key, samples[key], value)
+		}
+	}
+}
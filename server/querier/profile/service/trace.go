@@ -39,7 +39,40 @@ import (
 var log = logging.MustGetLogger("profile")
 var InstanceProfileEventType = []string{"inuse_objects", "alloc_objects", "inuse_space", "alloc_space", "goroutines"}
 
+type profileStack struct {
+	locations []string
+	value     int
+}
+
 func Tracing(args model.ProfileTracing, cfg *config.QuerierConfig) (result []*model.ProfileTreeNode, debug interface{}, err error) {
+	stacks, _, profileDebug, err := queryProfileStacks(args, cfg)
+	if err != nil {
+		return
+	}
+	formatStartTime := time.Now()
+	NodeIDToProfileTree, rootTotalValue := buildProfileTree(stacks)
+	if len(NodeIDToProfileTree) == 0 {
+		return
+	}
+	// format root node
+	rootNode := NewProfileTreeNode("root", "", 0)
+	rootNode.ParentNodeID = "-1"
+	rootNode.TotalValue = rootTotalValue
+
+	result = append(result, rootNode)
+	for _, node := range NodeIDToProfileTree {
+		result = append(result, node)
+	}
+	formatEndTime := int64(time.Since(formatStartTime))
+	formatTime := fmt.Sprintf("%.9fs", float64(formatEndTime)/1e9)
+	profileDebug.FormatTime = formatTime
+	debug = profileDebug
+	return
+}
+
+// 查询调用栈及其值，同时返回 profile_value_unit
+// query stacks with values, and return the profile_value_unit as well
+func queryProfileStacks(args model.ProfileTracing, cfg *config.QuerierConfig) (stacks []profileStack, valueUnit string, profileDebug model.Debug, err error) {
 	whereSlice := []string{}
 	whereSlice = append(whereSlice, fmt.Sprintf(" time>=%d", args.TimeStart))
 	whereSlice = append(whereSlice, fmt.Sprintf(" time<=%d", args.TimeEnd))
@@ -52,8 +85,8 @@ func Tracing(args model.ProfileTracing, cfg *config.QuerierConfig) (result []*mo
 	whereSql := strings.Join(whereSlice, " AND")
 	limitSql := cfg.Profile.FlameQueryLimit
 	sql := fmt.Sprintf(
-		"SELECT %s, %s FROM %s WHERE %s LIMIT %d",
-		common.PROFILE_LOCATION_STR, common.PROFILE_VALUE, common.TABLE_PROFILE, whereSql, limitSql,
+		"SELECT %s, %s, %s FROM %s WHERE %s LIMIT %d",
+		common.PROFILE_LOCATION_STR, common.PROFILE_VALUE, common.PROFILE_VALUE_UNIT, common.TABLE_PROFILE, whereSql, limitSql,
 	)
 
 	if slices.Contains[string](InstanceProfileEventType, args.ProfileEventType) {
@@ -72,6 +105,7 @@ func Tracing(args model.ProfileTracing, cfg *config.QuerierConfig) (result []*mo
 		timeResult, timeDebug, timeError := timeEngine.ExecuteQuery(&timeArgs)
 		if timeError != nil {
 			log.Errorf("ExecuteQuery failed: %v", timeDebug, timeError)
+			err = timeError
 			return
 		}
 		var timeValue int64
@@ -87,8 +121,8 @@ func Tracing(args model.ProfileTracing, cfg *config.QuerierConfig) (result []*mo
 		}
 		if timeValue > 0 {
 			sql = fmt.Sprintf(
-				"SELECT %s, %s FROM %s WHERE %s AND time=%d LIMIT %d",
-				common.PROFILE_LOCATION_STR, common.PROFILE_VALUE, common.TABLE_PROFILE, whereSql, timeValue, limitSql,
+				"SELECT %s, %s, %s FROM %s WHERE %s AND time=%d LIMIT %d",
+				common.PROFILE_LOCATION_STR, common.PROFILE_VALUE, common.PROFILE_VALUE_UNIT, common.TABLE_PROFILE, whereSql, timeValue, limitSql,
 			)
 		}
 
@@ -106,38 +140,36 @@ func Tracing(args model.ProfileTracing, cfg *config.QuerierConfig) (result []*mo
 		log.Errorf("ExecuteQuery failed: %v", querierDebug, err)
 		return
 	}
-	profileDebug := model.Debug{}
 	profileDebug.Sql = sql
 	profileDebug.IP = querierDebug["ip"].(string)
 	profileDebug.QueryUUID = querierDebug["query_uuid"].(string)
 	profileDebug.SqlCH = querierDebug["sql"].(string)
 	profileDebug.Error = querierDebug["error"].(string)
 	profileDebug.QueryTime = querierDebug["query_time"].(string)
-	formatStartTime := time.Now()
 	profileLocationStrIndex := -1
 	profileValueIndex := -1
-	NodeIDToProfileTree := map[string]*model.ProfileTreeNode{}
+	profileValueUnitIndex := -1
 	columns := querierResult.Columns
 	values := querierResult.Values
 	for columnIndex, col := range columns {
 		switch column := col.(type) {
 		case string:
 			switch column {
-			case "profile_location_str":
+			case common.PROFILE_LOCATION_STR:
 				profileLocationStrIndex = columnIndex
-			case "profile_value":
+			case common.PROFILE_VALUE:
 				profileValueIndex = columnIndex
+			case common.PROFILE_VALUE_UNIT:
+				profileValueUnitIndex = columnIndex
 			}
 		}
 	}
-	indexOK := slices.Contains[int]([]int{profileLocationStrIndex, profileValueIndex}, -1)
+	indexOK := slices.Contains[int]([]int{profileLocationStrIndex, profileValueIndex, profileValueUnitIndex}, -1)
 	if indexOK {
 		log.Error("Not all fields found")
 		err = errors.New("Not all fields found")
 		return
 	}
-	// merge profile_node_ids, profile_parent_node_ids, self_value
-	rootTotalValue := 0
 	for _, value := range values {
 		switch valueSlice := value.(type) {
 		case []interface{}:
@@ -149,36 +181,47 @@ func Tracing(args model.ProfileTracing, cfg *config.QuerierConfig) (result []*mo
 			if profileValueInt, ok := valueSlice[profileValueIndex].(int); ok {
 				profileValue = profileValueInt
 			}
+			if unit, ok := valueSlice[profileValueUnitIndex].(string); ok && valueUnit == "" {
+				valueUnit = unit
+			}
 			dst := make([]byte, 0, len(profileLocationStr))
 			profileLocationStrByte, _ := ingester_common.ZstdDecompress(dst, []byte(profileLocationStr))
-			profileLocationStrSlice := strings.Split(string(profileLocationStrByte), ";")
-			for profileLocationIndex := range profileLocationStrSlice {
-				nodeProfileValue := 0
-				if profileLocationIndex == len(profileLocationStrSlice)-1 {
-					nodeProfileValue = profileValue
-					rootTotalValue += profileValue
-				}
-				profileLocationStrs := strings.Join(profileLocationStrSlice[:profileLocationIndex+1], ";")
-				nodeID := controller_common.GenerateUUID(profileLocationStrs)
-				existNode, ok := NodeIDToProfileTree[nodeID]
-				if ok {
-					existNode.SelfValue += nodeProfileValue
-					existNode.TotalValue = existNode.SelfValue
-				} else {
-					nodeProfileLocationStr := profileLocationStrSlice[profileLocationIndex]
-					node := NewProfileTreeNode(nodeProfileLocationStr, nodeID, nodeProfileValue)
-					if profileLocationIndex != 0 {
-						parentProfileLocationStrs := strings.Join(profileLocationStrSlice[:profileLocationIndex], ";")
-						node.ParentNodeID = controller_common.GenerateUUID(parentProfileLocationStrs)
-					}
-					NodeIDToProfileTree[nodeID] = node
-				}
-			}
+			stacks = append(stacks, profileStack{
+				locations: strings.Split(string(profileLocationStrByte), ";"),
+				value:     profileValue,
+			})
 		}
 	}
+	return
+}
 
-	if len(NodeIDToProfileTree) == 0 {
-		return
+// merge profile_node_ids, profile_parent_node_ids, self_value
+func buildProfileTree(stacks []profileStack) (NodeIDToProfileTree map[string]*model.ProfileTreeNode, rootTotalValue int) {
+	NodeIDToProfileTree = map[string]*model.ProfileTreeNode{}
+	for _, stack := range stacks {
+		profileLocationStrSlice := stack.locations
+		for profileLocationIndex := range profileLocationStrSlice {
+			nodeProfileValue := 0
+			if profileLocationIndex == len(profileLocationStrSlice)-1 {
+				nodeProfileValue = stack.value
+				rootTotalValue += stack.value
+			}
+			profileLocationStrs := strings.Join(profileLocationStrSlice[:profileLocationIndex+1], ";")
+			nodeID := controller_common.GenerateUUID(profileLocationStrs)
+			existNode, ok := NodeIDToProfileTree[nodeID]
+			if ok {
+				existNode.SelfValue += nodeProfileValue
+				existNode.TotalValue = existNode.SelfValue
+			} else {
+				nodeProfileLocationStr := profileLocationStrSlice[profileLocationIndex]
+				node := NewProfileTreeNode(nodeProfileLocationStr, nodeID, nodeProfileValue)
+				if profileLocationIndex != 0 {
+					parentProfileLocationStrs := strings.Join(profileLocationStrSlice[:profileLocationIndex], ";")
+					node.ParentNodeID = controller_common.GenerateUUID(parentProfileLocationStrs)
+				}
+				NodeIDToProfileTree[nodeID] = node
+			}
+		}
 	}
 
 	// update total_value
@@ -192,19 +235,6 @@ func Tracing(args model.ProfileTracing, cfg *config.QuerierConfig) (result []*mo
 		}
 
 	}
-	// format root node
-	rootNode := NewProfileTreeNode("root", "", 0)
-	rootNode.ParentNodeID = "-1"
-	rootNode.TotalValue = rootTotalValue
-
-	result = append(result, rootNode)
-	for _, node := range NodeIDToProfileTree {
-		result = append(result, node)
-	}
-	formatEndTime := int64(time.Since(formatStartTime))
-	formatTime := fmt.Sprintf("%.9fs", float64(formatEndTime)/1e9)
-	profileDebug.FormatTime = formatTime
-	debug = profileDebug
 	return
 }
 
@@ -27,6 +27,7 @@ import (
 	logging "github.com/op/go-logging"
 	yaml "gopkg.in/yaml.v2"
 
+	"github.com/deepflowio/deepflow/server/controller/http/auth"
 	"github.com/deepflowio/deepflow/server/libs/logger"
 	"github.com/deepflowio/deepflow/server/libs/stats"
 	prometheus_router "github.com/deepflowio/deepflow/server/querier/app/prometheus/router"
@@ -81,6 +82,8 @@ func Start(configPath, serverLogFile string) {
 	r.Use(gin.LoggerWithFormatter(logger.GinLogFormat))
 	r.Use(StatdHandle())
 	r.Use(ErrHandle())
+	r.Use(auth.Middleware(&cfg.Auth, auth.QuerierRouteRole))
+	r.Use(OrgHandle())
 	router.QueryRouter(r)
 	profile_router.ProfileRouter(r, &cfg)
 	prometheus_router.PrometheusRouter(r)
@@ -141,6 +144,21 @@ func ErrHandle() gin.HandlerFunc {
 	}
 }
 
+// OrgHandle binds the org of X-Org-Id header to the request context,
+// all queries of the request are restricted to the databases of the org.
+func OrgHandle() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		orgID, err := common.ParseOrgID(c.GetHeader(common.HEADER_KEY_X_ORG_ID))
+		if err != nil {
+			router.BadRequestResponse(c, common.INVALID_PARAMETERS, err.Error())
+			c.Abort()
+			return
+		}
+		c.Request = c.Request.WithContext(common.ContextWithOrgID(c.Request.Context(), orgID))
+		c.Next()
+	}
+}
+
 func StatdHandle() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		startTime := time.Now()
@@ -17,15 +17,20 @@
 package router
 
 import (
+	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	logging "github.com/op/go-logging"
 
 	"github.com/deepflowio/deepflow/server/querier/common"
 	"github.com/deepflowio/deepflow/server/querier/service"
+	"github.com/deepflowio/deepflow/server/querier/stream"
 )
 
+var log = logging.MustGetLogger("querier.router")
+
 func QueryRouter(e *gin.Engine) {
 	e.POST("/v1/query/", executeQuery())
 
@@ -44,6 +49,7 @@ func executeQuery() gin.HandlerFunc {
 		args.Debug = c.Query("debug")
 		args.QueryUUID = c.Query("query_uuid")
 		args.NoPreWhere, _ = strconv.ParseBool(c.DefaultQuery("no_prewhere", "false"))
+		args.Explain, _ = strconv.ParseBool(c.DefaultQuery("explain", "false"))
 		if args.QueryUUID == "" {
 			query_uuid := uuid.New()
 			args.QueryUUID = query_uuid.String()
@@ -57,10 +63,66 @@ func executeQuery() gin.HandlerFunc {
 			args.DB, _ = json["db"].(string)
 			args.Sql, _ = json["sql"].(string)
 		}
+		format := c.DefaultQuery("format", stream.FORMAT_JSON)
+		var output *streamOutput
+		if format != stream.FORMAT_JSON {
+			output = &streamOutput{ctx: c, contentType: stream.CONTENT_TYPES[format]}
+			writer, err := stream.NewWriter(format, output)
+			if err != nil {
+				BadRequestResponse(c, common.INVALID_PARAMETERS, err.Error())
+				return
+			}
+			args.Writer = writer
+			args.NoLimit, _ = strconv.ParseBool(c.DefaultQuery("no_limit", "false"))
+		}
 		result, debug, err := service.Execute(&args)
+		if output != nil && output.started {
+			// the status code has been sent, the connection is closed without
+			// ending the response so that the client sees a truncated body
+			if err != nil {
+				log.Errorf("query_uuid: %s, stream result failed: %s", args.QueryUUID, err)
+				output.Abort()
+			}
+			return
+		}
 		if err == nil && args.Debug != "true" {
 			debug = nil
 		}
 		JsonResponse(c, result, debug, err)
 	})
 }
+
+// streamOutput sends the headers on the first write, so that an error before
+// any row is read can still be returned as a json response
+type streamOutput struct {
+	ctx         *gin.Context
+	contentType string
+	started     bool
+}
+
+func (o *streamOutput) Write(data []byte) (int, error) {
+	if !o.started {
+		o.started = true
+		o.ctx.Header("Content-Type", o.contentType)
+		o.ctx.Status(http.StatusOK)
+	}
+	return o.ctx.Writer.Write(data)
+}
+
+func (o *streamOutput) Flush() {
+	if o.started {
+		o.ctx.Writer.Flush()
+	}
+}
+
+// Abort closes the underlying connection, the terminating chunk of the
+// response is not sent, so the client gets an unexpected EOF instead of a
+// result which looks complete
+func (o *streamOutput) Abort() {
+	conn, _, err := o.ctx.Writer.Hijack()
+	if err != nil {
+		log.Errorf("abort stream result failed: %s", err)
+		return
+	}
+	conn.Close()
+}
@@ -20,6 +20,7 @@ import (
 	"github.com/deepflowio/deepflow/server/querier/common"
 	"github.com/deepflowio/deepflow/server/querier/engine"
 	"github.com/deepflowio/deepflow/server/querier/engine/clickhouse"
+	"github.com/deepflowio/deepflow/server/querier/stream"
 )
 
 func Execute(args *common.QuerierParams) (jsonData map[string]interface{}, debug map[string]interface{}, err error) {
@@ -31,6 +32,16 @@ func Execute(args *common.QuerierParams) (jsonData map[string]interface{}, debug
 		engine.Init()
 	}
 	result, debug, err := engine.ExecuteQuery(args)
+	if args.Writer != nil {
+		// Results that can not be streamed, such as show and slimit sql, are written after being built
+		if err == nil && result != nil {
+			err = stream.WriteResult(args.Writer, result)
+		}
+		if err == nil {
+			err = args.Writer.Flush()
+		}
+		return nil, debug, err
+	}
 	if result != nil {
 		jsonData = result.ToJson()
 	}
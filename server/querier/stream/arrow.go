@@ -0,0 +1,181 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package stream
+
+import (
+	"github.com/apache/arrow/go/v11/arrow"
+	"github.com/apache/arrow/go/v11/arrow/array"
+	"github.com/apache/arrow/go/v11/arrow/ipc"
+	"github.com/apache/arrow/go/v11/arrow/memory"
+
+	"github.com/deepflowio/deepflow/server/querier/common"
+	"github.com/deepflowio/deepflow/server/querier/engine/clickhouse/client"
+)
+
+// Arrow IPC streaming format, see https://arrow.apache.org/docs/format/Columnar.html#ipc-streaming-format
+// Only Int64, Float64 and Utf8 columns are written, other values are written as Utf8
+type ArrowWriter struct {
+	output
+	columns []string
+	types   []arrow.DataType // arrow type of each column, decided by the first batch
+	batch   [][]interface{}
+	schema  *arrow.Schema
+	writer  *ipc.Writer // created with the first batch, the schema is written by it
+}
+
+func (w *ArrowWriter) WriteHeader(columns []interface{}, schemas common.ColumnSchemas) error {
+	w.columns = make([]string, 0, len(columns))
+	w.types = make([]arrow.DataType, len(columns))
+	for i, column := range columns {
+		w.columns = append(w.columns, FormatValue(column))
+		if i >= len(schemas) {
+			continue
+		}
+		switch schemas[i].ValueType {
+		case client.VALUE_TYPE_INT:
+			w.types[i] = arrow.PrimitiveTypes.Int64
+		case client.VALUE_TYPE_FLOAT64:
+			w.types[i] = arrow.PrimitiveTypes.Float64
+		}
+	}
+	return nil
+}
+
+func (w *ArrowWriter) WriteRow(row []interface{}) error {
+	w.batch = append(w.batch, row)
+	if len(w.batch) < FLUSH_ROWS {
+		return nil
+	}
+	if err := w.writeBatch(); err != nil {
+		return err
+	}
+	return w.flush()
+}
+
+func (w *ArrowWriter) Flush() error {
+	if err := w.writeBatch(); err != nil {
+		return err
+	}
+	// end of stream
+	if err := w.writer.Close(); err != nil {
+		return err
+	}
+	return w.flush()
+}
+
+// The schema is written with the first batch, so that the column types can be
+// decided by the values after callbacks such as mac translation
+func (w *ArrowWriter) writeBatch() error {
+	// the schema is always written, even if there is no row
+	if w.writer == nil {
+		w.decideTypes()
+		fields := make([]arrow.Field, 0, len(w.columns))
+		for i, name := range w.columns {
+			fields = append(fields, arrow.Field{Name: name, Type: w.types[i], Nullable: true})
+		}
+		w.schema = arrow.NewSchema(fields, nil)
+		w.writer = ipc.NewWriter(w.output.Writer, ipc.WithSchema(w.schema))
+	}
+	if len(w.batch) == 0 {
+		return nil
+	}
+	record := w.buildRecord()
+	defer record.Release()
+	if err := w.writer.Write(record); err != nil {
+		return err
+	}
+	w.rows += len(w.batch)
+	w.batch = w.batch[:0]
+	return nil
+}
+
+// The type of a column without value type in schemas is decided by its non-nil values in
+// the first batch: Int64 if all values are int, Float64 if all values are numbers, otherwise
+// Utf8, so that no value of the first batch is lost by the conversion
+func (w *ArrowWriter) decideTypes() {
+	for i := range w.types {
+		if w.types[i] != nil {
+			continue
+		}
+		w.types[i] = columnType(w.batch, i)
+	}
+}
+
+func columnType(rows [][]interface{}, column int) arrow.DataType {
+	var dataType arrow.DataType
+	for _, row := range rows {
+		switch row[column].(type) {
+		case nil:
+			continue
+		case int:
+			if dataType == nil {
+				dataType = arrow.PrimitiveTypes.Int64
+			}
+		case float64:
+			if dataType == nil || dataType == arrow.PrimitiveTypes.Int64 {
+				dataType = arrow.PrimitiveTypes.Float64
+			}
+		default:
+			return arrow.BinaryTypes.String
+		}
+	}
+	if dataType == nil {
+		return arrow.BinaryTypes.String
+	}
+	return dataType
+}
+
+func (w *ArrowWriter) buildRecord() arrow.Record {
+	builder := array.NewRecordBuilder(memory.DefaultAllocator, w.schema)
+	defer builder.Release()
+	for i, fieldBuilder := range builder.Fields() {
+		for _, row := range w.batch {
+			appendValue(fieldBuilder, row[i])
+		}
+	}
+	return builder.NewRecord()
+}
+
+// Values which can not be converted to the column type are written as null
+func appendValue(builder array.Builder, value interface{}) {
+	if value == nil {
+		builder.AppendNull()
+		return
+	}
+	switch b := builder.(type) {
+	case *array.Int64Builder:
+		switch v := value.(type) {
+		case int:
+			b.Append(int64(v))
+		case int64:
+			b.Append(v)
+		default:
+			b.AppendNull()
+		}
+	case *array.Float64Builder:
+		switch v := value.(type) {
+		case float64:
+			b.Append(v)
+		case int:
+			b.Append(float64(v))
+		default:
+			b.AppendNull()
+		}
+	case *array.StringBuilder:
+		b.Append(FormatValue(value))
+	}
+}
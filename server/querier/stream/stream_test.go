@@ -0,0 +1,159 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package stream
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/apache/arrow/go/v11/arrow"
+	"github.com/apache/arrow/go/v11/arrow/array"
+	"github.com/apache/arrow/go/v11/arrow/ipc"
+
+	"github.com/deepflowio/deepflow/server/querier/common"
+)
+
+var testColumns = []interface{}{"ip", "byte", "rtt"}
+var testRows = [][]interface{}{
+	{"1.1.1.1", 100, 1.5},
+	{"2.2.2.2, 3.3.3.3", nil, 2.0},
+}
+
+func writeTestResult(t *testing.T, format string) []byte {
+	buf := &bytes.Buffer{}
+	writer, err := NewWriter(format, buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	values := []interface{}{}
+	for _, row := range testRows {
+		values = append(values, row)
+	}
+	result := &common.Result{Columns: testColumns, Values: values}
+	if err := WriteResult(writer, result); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestCSVWriter(t *testing.T) {
+	expected := "ip,byte,rtt\n1.1.1.1,100,1.5\n\"2.2.2.2, 3.3.3.3\",,2\n"
+	if out := string(writeTestResult(t, FORMAT_CSV)); out != expected {
+		t.Errorf("expected %q, got %q", expected, out)
+	}
+}
+
+func TestNDJSONWriter(t *testing.T) {
+	expected := "{\"ip\":\"1.1.1.1\",\"byte\":100,\"rtt\":1.5}\n{\"ip\":\"2.2.2.2, 3.3.3.3\",\"byte\":null,\"rtt\":2}\n"
+	if out := string(writeTestResult(t, FORMAT_NDJSON)); out != expected {
+		t.Errorf("expected %q, got %q", expected, out)
+	}
+}
+
+func TestUnsupportedFormat(t *testing.T) {
+	if _, err := NewWriter("xml", &bytes.Buffer{}); err == nil {
+		t.Error("expected error for unsupported format")
+	}
+}
+
+func readArrow(t *testing.T, out []byte) (*arrow.Schema, []arrow.Record) {
+	reader, err := ipc.NewReader(bytes.NewReader(out))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Release()
+	records := []arrow.Record{}
+	for reader.Next() {
+		record := reader.Record()
+		record.Retain()
+		records = append(records, record)
+	}
+	if err := reader.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return reader.Schema(), records
+}
+
+func TestArrowWriter(t *testing.T) {
+	schema, records := readArrow(t, writeTestResult(t, FORMAT_ARROW))
+	expectedTypes := []arrow.DataType{arrow.BinaryTypes.String, arrow.PrimitiveTypes.Int64, arrow.PrimitiveTypes.Float64}
+	if len(schema.Fields()) != len(testColumns) {
+		t.Fatalf("expected %d fields, got %d", len(testColumns), len(schema.Fields()))
+	}
+	for i, field := range schema.Fields() {
+		if field.Name != testColumns[i] {
+			t.Errorf("expected field name %s, got %s", testColumns[i], field.Name)
+		}
+		if !arrow.TypeEqual(field.Type, expectedTypes[i]) {
+			t.Errorf("expected type %s of field %s, got %s", expectedTypes[i], field.Name, field.Type)
+		}
+	}
+	if len(records) != 1 || records[0].NumRows() != int64(len(testRows)) {
+		t.Fatalf("expected 1 record with %d rows, got %d records", len(testRows), len(records))
+	}
+	record := records[0]
+	defer record.Release()
+	ips := record.Column(0).(*array.String)
+	if ips.Value(0) != "1.1.1.1" || ips.Value(1) != "2.2.2.2, 3.3.3.3" {
+		t.Errorf("unexpected ip column %v", ips)
+	}
+	bytes := record.Column(1).(*array.Int64)
+	if bytes.Value(0) != 100 || !bytes.IsNull(1) {
+		t.Errorf("unexpected byte column %v", bytes)
+	}
+	rtts := record.Column(2).(*array.Float64)
+	if rtts.Value(0) != 1.5 || rtts.Value(1) != 2.0 {
+		t.Errorf("unexpected rtt column %v", rtts)
+	}
+}
+
+// Columns with values of different types are written as Utf8 instead of dropping values
+func TestArrowWriterMixedTypes(t *testing.T) {
+	buf := &bytes.Buffer{}
+	writer, _ := NewWriter(FORMAT_ARROW, buf)
+	result := &common.Result{
+		Columns: []interface{}{"mixed", "number"},
+		Values: []interface{}{
+			[]interface{}{nil, 1},
+			[]interface{}{1, 2.5},
+			[]interface{}{"a", nil},
+		},
+	}
+	if err := WriteResult(writer, result); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	schema, records := readArrow(t, buf.Bytes())
+	if !arrow.TypeEqual(schema.Field(0).Type, arrow.BinaryTypes.String) || !arrow.TypeEqual(schema.Field(1).Type, arrow.PrimitiveTypes.Float64) {
+		t.Fatalf("unexpected schema %s", schema)
+	}
+	record := records[0]
+	defer record.Release()
+	mixed := record.Column(0).(*array.String)
+	if !mixed.IsNull(0) || mixed.Value(1) != "1" || mixed.Value(2) != "a" {
+		t.Errorf("unexpected mixed column %v", mixed)
+	}
+	numbers := record.Column(1).(*array.Float64)
+	if numbers.Value(0) != 1 || numbers.Value(1) != 2.5 || !numbers.IsNull(2) {
+		t.Errorf("unexpected number column %v", numbers)
+	}
+}
@@ -0,0 +1,209 @@
+/*
+ * Copyright (c) 2023 Yunshan Networks
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package stream
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/deepflowio/deepflow/server/querier/common"
+)
+
+const (
+	FORMAT_JSON   = "json"
+	FORMAT_CSV    = "csv"
+	FORMAT_NDJSON = "ndjson"
+	FORMAT_ARROW  = "arrow"
+)
+
+var CONTENT_TYPES = map[string]string{
+	FORMAT_CSV:    "text/csv; charset=utf-8",
+	FORMAT_NDJSON: "application/x-ndjson",
+	FORMAT_ARROW:  "application/vnd.apache.arrow.stream",
+}
+
+// Rows are buffered and flushed to the client every FLUSH_ROWS rows
+const FLUSH_ROWS = 4096
+
+func NewWriter(format string, w io.Writer) (common.ResultWriter, error) {
+	switch format {
+	case FORMAT_CSV:
+		return &CSVWriter{output: newOutput(w)}, nil
+	case FORMAT_NDJSON:
+		return &NDJSONWriter{output: newOutput(w)}, nil
+	case FORMAT_ARROW:
+		return &ArrowWriter{output: newOutput(w)}, nil
+	}
+	return nil, fmt.Errorf("format %s is not supported, should be one of csv, ndjson and arrow", format)
+}
+
+// WriteResult writes a result that has been built in memory, used when the query can not be streamed
+func WriteResult(writer common.ResultWriter, result *common.Result) error {
+	if err := writer.WriteHeader(result.Columns, result.Schemas); err != nil {
+		return err
+	}
+	for _, value := range result.Values {
+		row, ok := value.([]interface{})
+		if !ok {
+			return fmt.Errorf("unknown row type %T", value)
+		}
+		if err := writer.WriteRow(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type output struct {
+	*bufio.Writer
+	w    io.Writer
+	rows int
+}
+
+func newOutput(w io.Writer) output {
+	return output{Writer: bufio.NewWriter(w), w: w}
+}
+
+// Flush the buffered rows to the client every FLUSH_ROWS rows
+func (o *output) rowWritten() error {
+	o.rows++
+	if o.rows%FLUSH_ROWS != 0 {
+		return nil
+	}
+	return o.flush()
+}
+
+func (o *output) flush() error {
+	if err := o.Writer.Flush(); err != nil {
+		return err
+	}
+	if flusher, ok := o.w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+	return nil
+}
+
+// FormatValue formats a value of the result as a string, values are formatted the same as the json response
+func FormatValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case int:
+		return strconv.Itoa(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case net.IP:
+		return v.String()
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
+
+type CSVWriter struct {
+	output
+	csv    *csv.Writer
+	record []string
+}
+
+func (w *CSVWriter) WriteHeader(columns []interface{}, schemas common.ColumnSchemas) error {
+	w.csv = csv.NewWriter(w.output.Writer)
+	header := make([]string, 0, len(columns))
+	for _, column := range columns {
+		header = append(header, FormatValue(column))
+	}
+	w.record = make([]string, len(columns))
+	return w.csv.Write(header)
+}
+
+func (w *CSVWriter) WriteRow(row []interface{}) error {
+	for i, value := range row {
+		w.record[i] = FormatValue(value)
+	}
+	if err := w.csv.Write(w.record); err != nil {
+		return err
+	}
+	if w.rows%FLUSH_ROWS == FLUSH_ROWS-1 {
+		w.csv.Flush()
+	}
+	return w.rowWritten()
+}
+
+func (w *CSVWriter) Flush() error {
+	if w.csv != nil {
+		w.csv.Flush()
+		if err := w.csv.Error(); err != nil {
+			return err
+		}
+	}
+	return w.flush()
+}
+
+// One json object per line, keys are in the order of the columns
+type NDJSONWriter struct {
+	output
+	keys [][]byte
+}
+
+func (w *NDJSONWriter) WriteHeader(columns []interface{}, schemas common.ColumnSchemas) error {
+	w.keys = make([][]byte, 0, len(columns))
+	for _, column := range columns {
+		key, err := json.Marshal(FormatValue(column))
+		if err != nil {
+			return err
+		}
+		w.keys = append(w.keys, key)
+	}
+	return nil
+}
+
+func (w *NDJSONWriter) WriteRow(row []interface{}) error {
+	w.WriteByte('{')
+	for i, value := range row {
+		if i > 0 {
+			w.WriteByte(',')
+		}
+		w.Write(w.keys[i])
+		w.WriteByte(':')
+		data, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		w.Write(data)
+	}
+	if _, err := w.WriteString("}\n"); err != nil {
+		return err
+	}
+	return w.rowWritten()
+}
+
+func (w *NDJSONWriter) Flush() error {
+	return w.flush()
+}